@@ -0,0 +1,171 @@
+// Command concord-export writes the history of a channel or direct conversation
+// to a JSON, HTML or Markdown file, reading straight from the database.
+//
+// Usage:
+//
+//	concord-export -channel <id> [-format html] [-o file]
+//	concord-export -user <id> -with <id> [-format markdown] [-o -]
+//
+// By default it reads the server's PostgreSQL database from config.json (and the
+// usual CONCORD_* environment variables); -store sqlite reads a desktop database.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/concord-chat/concord/internal/chat"
+	"github.com/concord-chat/concord/internal/config"
+	"github.com/concord-chat/concord/internal/export"
+	"github.com/concord-chat/concord/internal/friends"
+	"github.com/concord-chat/concord/internal/server"
+	"github.com/concord-chat/concord/internal/store/postgres"
+	"github.com/concord-chat/concord/internal/store/sqlite"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "concord-export: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	var (
+		configPath = flag.String("config", "config.json", "configuration file")
+		store      = flag.String("store", "postgres", "database to read: postgres or sqlite")
+		sqlitePath = flag.String("sqlite", "", "SQLite database path (overrides the configuration)")
+		channelID  = flag.String("channel", "", "channel ID to export")
+		userID     = flag.String("user", "", "user ID whose direct messages to export")
+		withID     = flag.String("with", "", "the other user of the direct conversation")
+		formatName = flag.String("format", "json", "output format: json, html or markdown")
+		output     = flag.String("o", "", `output file ("-" for stdout, default: a generated name)`)
+		verbose    = flag.Bool("v", false, "log progress to stderr")
+	)
+	flag.Parse()
+
+	if (*channelID == "") == (*userID == "" || *withID == "") {
+		flag.Usage()
+		return fmt.Errorf("pass either -channel, or -user and -with")
+	}
+	format, err := export.ParseFormat(*formatName)
+	if err != nil {
+		return err
+	}
+
+	level := zerolog.WarnLevel
+	if *verbose {
+		level = zerolog.InfoLevel
+	}
+	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: time.RFC3339}).Level(level).With().Timestamp().Logger()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	db, closeDB, err := openStore(*store, *sqlitePath, cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	svc := export.NewService(chat.NewRepository(db, logger), friends.NewRepository(db, nil, logger), logger)
+
+	var meta export.Meta
+	if *channelID != "" {
+		meta, err = channelMeta(ctx, server.NewRepository(db, nil, logger), *channelID)
+		if err != nil {
+			return err
+		}
+	} else {
+		meta = export.Meta{ID: *withID}
+	}
+	meta.ExportedAt = time.Now().UTC().Format(time.RFC3339)
+
+	path := *output
+	if path == "" {
+		path = meta.Filename(format)
+	}
+	var w io.Writer = os.Stdout
+	if path != "-" {
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	var n int
+	if *channelID != "" {
+		n, err = svc.ExportChannel(ctx, w, format, meta)
+	} else {
+		n, err = svc.ExportDM(ctx, w, format, *userID, meta)
+	}
+	if err != nil {
+		return err
+	}
+	if path != "-" {
+		fmt.Fprintf(os.Stderr, "exported %d messages to %s\n", n, path)
+	}
+	return nil
+}
+
+// openStore opens the database without running migrations: exports only read.
+func openStore(kind, sqlitePath string, cfg *config.Config, logger zerolog.Logger) (friends.Querier, func(), error) {
+	switch kind {
+	case "postgres":
+		pgDB, err := postgres.New(cfg.Database.Postgres, logger)
+		if err != nil {
+			return nil, nil, err
+		}
+		return postgres.NewAdapter(pgDB.StdlibDB()), func() { pgDB.Close() }, nil
+	case "sqlite":
+		path := sqlitePath
+		if path == "" {
+			path = cfg.Database.SQLite.Path
+		}
+		if _, err := os.Stat(path); err != nil {
+			return nil, nil, fmt.Errorf("sqlite database: %w", err)
+		}
+		db, err := sqlite.New(sqlite.Config{
+			Path:         path,
+			MaxOpenConns: 1,
+			ForeignKeys:  true,
+			BusyTimeout:  5 * time.Second,
+		}, logger)
+		if err != nil {
+			return nil, nil, err
+		}
+		return db, func() { db.Close() }, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown store %q (use postgres or sqlite)", kind)
+	}
+}
+
+func channelMeta(ctx context.Context, repo *server.Repository, channelID string) (export.Meta, error) {
+	ch, err := repo.GetChannel(ctx, channelID)
+	if err != nil {
+		return export.Meta{}, err
+	}
+	if ch == nil {
+		return export.Meta{}, fmt.Errorf("channel %s not found", channelID)
+	}
+	meta := export.Meta{ID: ch.ID, Name: ch.Name, ServerID: ch.ServerID}
+	if srv, err := repo.GetServer(ctx, ch.ServerID); err == nil && srv != nil {
+		meta.ServerName = srv.Name
+	}
+	return meta, nil
+}
@@ -0,0 +1,171 @@
+// Command concord-import brings channel history exported from Discord into Concord.
+// It reads the JSON files written by DiscordChatExporter (one per channel) and creates
+// the server, channels and placeholder authors, keeping the original timestamps.
+//
+// Usage:
+//
+//	concord-import -owner <user-id> export.json [more.json | export-dir ...]
+//
+// Export with media download (--media) to bring attachments along; otherwise their
+// Discord links are kept in the message text. Running the same files again only adds
+// what is missing. By default it writes to the server's PostgreSQL database from
+// config.json; -store sqlite writes to a desktop database.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/concord-chat/concord/internal/auth"
+	"github.com/concord-chat/concord/internal/chat"
+	"github.com/concord-chat/concord/internal/config"
+	"github.com/concord-chat/concord/internal/files"
+	"github.com/concord-chat/concord/internal/friends"
+	"github.com/concord-chat/concord/internal/importer"
+	"github.com/concord-chat/concord/internal/server"
+	"github.com/concord-chat/concord/internal/store/postgres"
+	"github.com/concord-chat/concord/internal/store/sqlite"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "concord-import: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	var (
+		configPath = flag.String("config", "config.json", "configuration file")
+		store      = flag.String("store", "postgres", "database to write: postgres or sqlite")
+		sqlitePath = flag.String("sqlite", "", "SQLite database path (overrides the configuration)")
+		filesDir   = flag.String("files", "", "attachment storage directory (default: next to the database)")
+		ownerID    = flag.String("owner", "", "Concord user ID that owns the imported server")
+		verbose    = flag.Bool("v", false, "log progress to stderr")
+	)
+	flag.Parse()
+
+	if *ownerID == "" || flag.NArg() == 0 {
+		flag.Usage()
+		return fmt.Errorf("pass -owner and at least one export file or directory")
+	}
+	paths, err := exportFiles(flag.Args())
+	if err != nil {
+		return err
+	}
+
+	level := zerolog.WarnLevel
+	if *verbose {
+		level = zerolog.InfoLevel
+	}
+	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: time.RFC3339}).Level(level).With().Timestamp().Logger()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	db, storageDir, closeDB, err := openStore(ctx, *store, *sqlitePath, cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+	if *filesDir != "" {
+		storageDir = *filesDir
+	}
+	storage, err := files.NewLocalStorage(storageDir, logger)
+	if err != nil {
+		return err
+	}
+
+	im := importer.NewImporter(server.NewRepository(db, nil, logger), chat.NewRepository(db, logger), auth.NewRepository(db, logger), storage, logger)
+	for _, path := range paths {
+		res, err := im.ImportDiscordFile(ctx, path, importer.Options{OwnerID: *ownerID})
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		fmt.Fprintf(os.Stderr, "%s: %d messages imported, %d already present, %d attachments (%d missing), %d skipped\n",
+			path, res.Imported, res.AlreadyImported, res.Attachments, res.MissingAttachments, res.Unsupported)
+	}
+	return nil
+}
+
+// exportFiles expands directories to the JSON files they contain.
+func exportFiles(args []string) ([]string, error) {
+	var paths []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			paths = append(paths, arg)
+			continue
+		}
+		entries, err := os.ReadDir(arg)
+		if err != nil {
+			return nil, err
+		}
+		var found []string
+		for _, e := range entries {
+			if !e.IsDir() && strings.EqualFold(filepath.Ext(e.Name()), ".json") {
+				found = append(found, filepath.Join(arg, e.Name()))
+			}
+		}
+		sort.Strings(found)
+		paths = append(paths, found...)
+	}
+	return paths, nil
+}
+
+// openStore opens the database and applies pending migrations, since the import writes.
+// It also returns the default attachment directory for that store.
+func openStore(ctx context.Context, kind, sqlitePath string, cfg *config.Config, logger zerolog.Logger) (friends.Querier, string, func(), error) {
+	switch kind {
+	case "postgres":
+		pgDB, err := postgres.New(cfg.Database.Postgres, logger)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		if err := postgres.NewMigrator(pgDB, logger).Run(ctx); err != nil {
+			pgDB.Close()
+			return nil, "", nil, err
+		}
+		return postgres.NewAdapter(pgDB.StdlibDB()), filepath.Join(cfg.App.DataDir, "files"), func() { pgDB.Close() }, nil
+	case "sqlite":
+		path := sqlitePath
+		if path == "" {
+			path = cfg.Database.SQLite.Path
+		}
+		db, err := sqlite.New(sqlite.Config{
+			Path:         path,
+			MaxOpenConns: 1,
+			ForeignKeys:  true,
+			BusyTimeout:  5 * time.Second,
+		}, logger)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		if err := sqlite.NewMigrator(db, logger).Migrate(ctx); err != nil {
+			db.Close()
+			return nil, "", nil, err
+		}
+		// The desktop app keeps attachments in "files" next to its database.
+		return db, filepath.Join(filepath.Dir(path), "files"), func() { db.Close() }, nil
+	default:
+		return nil, "", nil, fmt.Errorf("unknown store %q (use postgres or sqlite)", kind)
+	}
+}
@@ -8,23 +8,33 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 
 	"github.com/concord-chat/concord/internal/api"
 	"github.com/concord-chat/concord/internal/auth"
+	"github.com/concord-chat/concord/internal/bookmarks"
 	"github.com/concord-chat/concord/internal/cache"
 	"github.com/concord-chat/concord/internal/chat"
+	"github.com/concord-chat/concord/internal/commands"
 	"github.com/concord-chat/concord/internal/config"
+	"github.com/concord-chat/concord/internal/emoji"
+	"github.com/concord-chat/concord/internal/export"
+	"github.com/concord-chat/concord/internal/files"
 	"github.com/concord-chat/concord/internal/friends"
+	"github.com/concord-chat/concord/internal/linkpreview"
 	"github.com/concord-chat/concord/internal/network/signaling"
 	"github.com/concord-chat/concord/internal/observability"
+	"github.com/concord-chat/concord/internal/preferences"
 	"github.com/concord-chat/concord/internal/presence"
 	"github.com/concord-chat/concord/internal/security"
 	"github.com/concord-chat/concord/internal/server"
 	"github.com/concord-chat/concord/internal/store/postgres"
 	"github.com/concord-chat/concord/internal/store/redis"
+	"github.com/concord-chat/concord/internal/typing"
 	"github.com/concord-chat/concord/internal/voice"
+	"github.com/concord-chat/concord/internal/webhooks"
 	"github.com/concord-chat/concord/pkg/version"
 )
 
@@ -120,13 +130,30 @@ func main() {
 	authSvc := auth.NewService(githubOAuth, jwtManager, authRepo, cryptoMgr, encryptKey, logger)
 
 	// Server service
-	serverRepo := server.NewRepository(pgAdapter, logger)
+	serverTx := server.NewStdlibTransactorWithWrapper(stdlibDB, func(q server.Querier) server.Querier {
+		return postgres.NewQuerierAdapter(q)
+	})
+	serverRepo := server.NewRepository(pgAdapter, serverTx, logger)
 	serverCache := cache.NewLRU(1000)
 	serverSvc := server.NewService(serverRepo, serverCache, logger)
 
 	// Chat service
 	chatRepo := chat.NewRepository(pgAdapter, logger)
 	chatSvc := chat.NewService(chatRepo, logger)
+	// Per-user message limit (when enabled) plus per-channel slow mode
+	messageLimit := 0
+	if cfg.Security.RateLimitEnabled {
+		messageLimit = cfg.Security.RateLimitMessages
+	}
+	chatSvc.SetSendLimiter(chat.NewSendLimiter(messageLimit, serverSvc))
+	chatSvc.SetChannelAccess(serverSvc)
+	chatSvc.SetSendPolicy(serverSvc)
+	chatSvc.SetModeration(serverSvc)
+	chatSvc.SetSearcher(postgres.NewChatSearcher(pgDB, logger))
+
+	// User preferences + link previews (fetched server-side, honoring each author's opt-out)
+	prefsSvc := preferences.NewService(preferences.NewRepository(pgAdapter, logger), cache.NewLRU(1000), logger)
+	chatSvc.SetUnfurler(linkpreview.NewFetcher(1000, logger), prefsSvc)
 
 	// Friends service — wrap transactions with pgAdapter-style placeholder translation
 	friendTx := friends.NewStdlibTransactorWithWrapper(stdlibDB, func(q friends.Querier) friends.Querier {
@@ -137,11 +164,40 @@ func main() {
 	// 15s avoids stale "online" while still tolerating short jitter.
 	presenceTracker := presence.NewTracker(15 * time.Second)
 	friendsSvc := friends.NewService(friendRepo, presenceTracker, logger)
+	friendsSvc.SetReceiptPolicy(prefsSvc)
+
+	// Outgoing webhooks: signed event deliveries retried in the background
+	hooksSvc := webhooks.NewService(webhooks.NewRepository(pgAdapter, logger), serverSvc, logger)
+	chatSvc.SetEventSink(hooksSvc)
+	serverSvc.SetEventSink(hooksSvc)
+	hooksCtx, stopHooks := context.WithCancel(context.Background())
+	defer stopHooks()
+	go hooksSvc.Run(hooksCtx, 15*time.Second)
+
+	// Slash commands: registered by bots, answered through callbacks or polling
+	commandsSvc := commands.NewService(commands.NewRepository(pgAdapter, logger), serverSvc, chatSvc, logger)
+	go commandsSvc.Run(hooksCtx, time.Hour)
+
+	// Custom emoji: images stored on disk, :name: tokens resolved when messages are sent
+	emojiStorage, err := files.NewLocalStorage(filepath.Join(cfg.App.DataDir, "emoji"), logger)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("failed to create emoji storage")
+	}
+	emojiSvc := emoji.NewService(emoji.NewRepository(pgAdapter, logger), emojiStorage, serverSvc, logger)
+	chatSvc.SetEmojiResolver(emojiSvc)
+
+	// Personal bookmarks of channel and direct messages
+	bookmarksSvc := bookmarks.NewService(bookmarks.NewRepository(pgAdapter, logger), chatSvc, serverSvc, friendsSvc, logger)
 
 	logger.Info().Msg("all services initialized with postgresql backend")
 
 	// --- Signaling Server (voice WebRTC coordination) ---
 	sigServer := signaling.NewServer(logger)
+	sigServer.OnChannelOccupied(func(serverID, channelID, userID string) {
+		hooksSvc.ChannelOccupied(hooksCtx, serverID, channelID, userID)
+	})
+	sigServer.SetJoinPolicy(serverSvc)
+	serverSvc.SetVoiceSessions(sigServer)
 	logger.Info().Msg("signaling server initialized")
 
 	// --- API Server ---
@@ -159,6 +215,19 @@ func main() {
 		logger,
 	)
 
+	apiServer.SetPreferences(prefsSvc)
+
+	// Typing state is polled by clients (GET .../typing); the server has no push
+	// channel, so no listener is registered. Listeners serve the desktop P2P path.
+	typingTracker := typing.NewTracker(typing.DefaultTTL, typing.DefaultThrottle)
+	defer typingTracker.Close()
+	apiServer.SetTyping(typingTracker)
+	apiServer.SetExport(export.NewService(chatRepo, friendRepo, logger))
+	apiServer.SetOutgoingWebhooks(hooksSvc)
+	apiServer.SetCommands(commandsSvc)
+	apiServer.SetEmoji(emojiSvc)
+	apiServer.SetBookmarks(bookmarksSvc)
+
 	iceProvider := voice.NewICECredentialsProvider(
 		cfg.Voice.TURNHost,
 		cfg.Voice.TURNPort,
@@ -35,4 +35,40 @@ export const apiChat = {
     apiClient.get<unknown[]>(
       `/api/v1/channels/${encodeURIComponent(channelId)}/messages/search?q=${encodeURIComponent(query)}&limit=${limit}`
     ),
+
+  createPoll: (channelId: string, poll: {
+    question: string
+    options: string[]
+    multiple_choice?: boolean
+    anonymous?: boolean
+    closes_at?: string
+  }) =>
+    apiClient.post(`/api/v1/channels/${encodeURIComponent(channelId)}/polls`, poll),
+
+  // An empty optionIds list retracts the vote.
+  votePoll: (messageId: string, optionIds: string[]) =>
+    apiClient.put(`/api/v1/messages/${encodeURIComponent(messageId)}/votes`, { option_ids: optionIds }),
+
+  closePoll: (messageId: string, isManager: boolean) =>
+    apiClient.post(
+      `/api/v1/messages/${encodeURIComponent(messageId)}/poll/close${isManager ? '?is_manager=true' : ''}`
+    ),
+
+  // format: 'json' | 'html' | 'markdown'
+  exportHistory: (serverId: string, channelId: string, format: string) =>
+    apiClient.download(
+      `/api/v1/servers/${encodeURIComponent(serverId)}/channels/${encodeURIComponent(channelId)}/export?format=${encodeURIComponent(format)}`
+    ),
+
+  // Typing indicators expire server-side; refresh startTyping every few seconds while typing.
+  startTyping: (serverId: string, channelId: string) =>
+    apiClient.post(`/api/v1/servers/${encodeURIComponent(serverId)}/channels/${encodeURIComponent(channelId)}/typing`),
+
+  stopTyping: (serverId: string, channelId: string) =>
+    apiClient.del(`/api/v1/servers/${encodeURIComponent(serverId)}/channels/${encodeURIComponent(channelId)}/typing`),
+
+  getTyping: (serverId: string, channelId: string) =>
+    apiClient.get<{ user_id: string; expires_at: string }[]>(
+      `/api/v1/servers/${encodeURIComponent(serverId)}/channels/${encodeURIComponent(channelId)}/typing`
+    ),
 }
@@ -4,6 +4,7 @@
 const API_STORAGE_KEY = 'concord-api-tokens'
 const DEFAULT_REQUEST_TIMEOUT_MS = 10000
 const DISCOVERY_TIMEOUT_MS = 5000
+const DOWNLOAD_TIMEOUT_MS = 5 * 60 * 1000
 const API_UNAVAILABLE_MESSAGE = 'Servidor indisponível. Tente novamente.'
 
 interface ApiTokens {
@@ -131,6 +132,24 @@ class ApiClient {
     return res.json()
   }
 
+  // download fetches an authenticated file (e.g. a history export) as a Blob,
+  // with the filename suggested by Content-Disposition.
+  async download(path: string): Promise<{ blob: Blob; filename: string }> {
+    const token = await this.ensureToken()
+    const res = await fetchWithTimeout(`${this.baseURL}${path}`, {
+      headers: { 'Authorization': `Bearer ${token}` },
+    }, DOWNLOAD_TIMEOUT_MS)
+
+    if (!res.ok) {
+      const err = await res.json().catch(() => ({ error: { message: res.statusText } }))
+      throw new Error(err.error?.message ?? `HTTP ${res.status}`)
+    }
+
+    const disposition = res.headers.get('Content-Disposition') ?? ''
+    const filename = /filename="([^"]+)"/.exec(disposition)?.[1] ?? 'export'
+    return { blob: await res.blob(), filename }
+  }
+
   get<T>(path: string) { return this.request<T>('GET', path) }
   post<T>(path: string, body?: unknown) { return this.request<T>('POST', path, body) }
   put<T>(path: string, body?: unknown) { return this.request<T>('PUT', path, body) }
@@ -224,6 +243,11 @@ async function chooseFallbackURL(preferred: string): Promise<string> {
   return candidate
 }
 
+/** True when err means the central server could not be reached (network error or timeout). */
+export function isServerUnavailable(err: unknown): boolean {
+  return err instanceof Error && err.message === API_UNAVAILABLE_MESSAGE
+}
+
 // Singleton — initialized with the build-time server URL
 export const apiClient = new ApiClient(SERVER_URL)
 
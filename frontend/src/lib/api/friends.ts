@@ -30,6 +30,9 @@ export interface DirectMessageView {
   receiver_id: string
   content: string
   created_at: string
+  delivered_at?: string
+  read_at?: string
+  status: 'sent' | 'delivered' | 'read'
 }
 
 export const apiFriends = {
@@ -68,4 +71,20 @@ export const apiFriends = {
 
   sendDirectMessage: (friendId: string, content: string) =>
     apiClient.request<DirectMessageView>('POST', `/api/v1/friends/${encodeURIComponent(friendId)}/messages`, { content }),
+
+  startTyping: (friendId: string) =>
+    apiClient.post(`/api/v1/friends/${encodeURIComponent(friendId)}/typing`),
+
+  stopTyping: (friendId: string) =>
+    apiClient.del(`/api/v1/friends/${encodeURIComponent(friendId)}/typing`),
+
+  getTyping: (friendId: string) =>
+    apiClient.get<{ user_id: string; expires_at: string }[]>(`/api/v1/friends/${encodeURIComponent(friendId)}/typing`),
+
+  // format: 'json' | 'html' | 'markdown'
+  exportDirectMessages: (friendId: string, format: string) =>
+    apiClient.download(`/api/v1/friends/${encodeURIComponent(friendId)}/export?format=${encodeURIComponent(format)}`),
+
+  ackDirectMessages: (friendId: string, messageId: string, status: 'delivered' | 'read') =>
+    apiClient.request<{ updated: number }>('POST', `/api/v1/friends/${encodeURIComponent(friendId)}/messages/ack`, { message_id: messageId, status }),
 }
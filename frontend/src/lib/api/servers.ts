@@ -22,6 +22,22 @@ export const apiServers = {
   createChannel: (serverId: string, name: string, type: string) =>
     apiClient.post(`/api/v1/servers/${encodeURIComponent(serverId)}/channels`, { name, type }),
 
+  // Incoming webhooks (the token is only returned on create and regenerate)
+  listWebhooks: (serverId: string) =>
+    apiClient.get<unknown[]>(`/api/v1/servers/${encodeURIComponent(serverId)}/webhooks`),
+
+  createWebhook: (serverId: string, channelId: string, name: string, avatarUrl: string) =>
+    apiClient.post(`/api/v1/servers/${encodeURIComponent(serverId)}/webhooks`, { channel_id: channelId, name, avatar_url: avatarUrl }),
+
+  updateWebhook: (serverId: string, webhookId: string, channelId: string, name: string, avatarUrl: string) =>
+    apiClient.put(`/api/v1/servers/${encodeURIComponent(serverId)}/webhooks/${encodeURIComponent(webhookId)}`, { channel_id: channelId, name, avatar_url: avatarUrl }),
+
+  regenerateWebhookToken: (serverId: string, webhookId: string) =>
+    apiClient.post(`/api/v1/servers/${encodeURIComponent(serverId)}/webhooks/${encodeURIComponent(webhookId)}/token`),
+
+  deleteWebhook: (serverId: string, webhookId: string) =>
+    apiClient.del(`/api/v1/servers/${encodeURIComponent(serverId)}/webhooks/${encodeURIComponent(webhookId)}`),
+
   // Members
   listMembers: (serverId: string) =>
     apiClient.get<unknown[]>(`/api/v1/servers/${encodeURIComponent(serverId)}/members`),
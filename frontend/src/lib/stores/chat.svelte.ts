@@ -2,9 +2,13 @@
 // Manages messages for the active channel — Wails bindings (P2P) or HTTP API (Server)
 
 import * as App from '../../../wailsjs/go/main/App'
+import { EventsOn } from '../../../wailsjs/runtime/runtime'
 import { ensureValidToken } from './auth.svelte'
 import { isServerMode } from '../api/mode'
 import { apiChat } from '../api/chat'
+import { isServerUnavailable } from '../api/client'
+
+export type DeliveryState = 'queued' | 'sent' | 'delivered' | 'failed'
 
 export interface MessageData {
   id: string
@@ -16,6 +20,8 @@ export interface MessageData {
   created_at: string
   author_name: string
   author_avatar: string
+  // Set only on local placeholders for messages waiting in the offline outbox
+  delivery?: DeliveryState
 }
 
 export interface AttachmentData {
@@ -30,6 +36,7 @@ export interface SearchResultData extends MessageData {
 }
 
 const MESSAGE_POLL_INTERVAL = 5_000 // 5s polling for new messages
+const OUTBOX_CLAIM_LIMIT = 20
 const WAILS_STORE_TIMEOUT_MS = 10_000
 
 let messages = $state<MessageData[]>([])
@@ -42,6 +49,8 @@ let searchQuery = $state('')
 let error = $state<string | null>(null)
 let attachmentsByMessage = $state<Record<string, AttachmentData[]>>({})
 let messagePollTimer: ReturnType<typeof setInterval> | null = null
+let outboxListening = false
+let flushingOutbox = false
 
 async function withTimeout<T>(promise: Promise<T>, timeoutMs: number, errorMessage: string): Promise<T> {
   let handle: ReturnType<typeof setTimeout> | null = null
@@ -94,11 +103,11 @@ export async function loadMessages(channelID: string): Promise<void> {
     }
     // API returns newest first, reverse for display (oldest at top)
     const msgs = (result ?? []) as unknown as MessageData[]
-    messages = msgs.reverse()
+    messages = [...msgs.reverse(), ...await queuedPlaceholders(channelID)]
     hasMore = msgs.length >= 50
   } catch (e) {
     error = e instanceof Error ? e.message : 'Failed to load messages'
-    messages = []
+    messages = isServerMode() ? await queuedPlaceholders(channelID) : []
   } finally {
     loading = false
   }
@@ -154,6 +163,16 @@ export async function sendMessage(channelID: string, authorID: string, content:
     messages = [...messages, data]
     return data
   } catch (e) {
+    // Server unreachable: keep the message in the durable outbox and deliver it later
+    if (isServerMode() && isServerUnavailable(e)) {
+      try {
+        const entry = await App.QueueServerMessage(channelID, content)
+        const placeholder = toPlaceholder(entry, authorID)
+        messages = [...messages, placeholder]
+        listenOutbox()
+        return placeholder
+      } catch { /* fall through to the original error */ }
+    }
     error = e instanceof Error ? e.message : 'Failed to send message'
     return null
   } finally {
@@ -284,9 +303,14 @@ export async function deleteAttachment(attachmentID: string): Promise<void> {
 async function pollNewMessages(channelID: string) {
   if (!channelID || activeChannelId !== channelID) return
 
+  if (isServerMode()) await flushServerOutbox()
+
   try {
     await ensureValidToken()
-    const lastMsg = messages[messages.length - 1]
+    // Placeholders are not on the server, so they can't be used as the cursor
+    const confirmed = messages.filter(m => !m.delivery)
+    const pending = messages.filter(m => m.delivery)
+    const lastMsg = confirmed[confirmed.length - 1]
     const after = lastMsg?.id ?? ''
     let result
     if (isServerMode()) {
@@ -306,7 +330,7 @@ async function pollNewMessages(channelID: string) {
       const existingIds = new Set(messages.map(m => m.id))
       const fresh = reversed.filter(m => !existingIds.has(m.id))
       if (fresh.length > 0) {
-        messages = [...messages, ...fresh]
+        messages = [...confirmed, ...fresh, ...pending]
       }
     }
   } catch {
@@ -314,6 +338,82 @@ async function pollNewMessages(channelID: string) {
   }
 }
 
+// --- Offline Outbox (server mode) ---
+
+interface OutboxEntry {
+  id: string
+  target: string
+  recipient: string
+  content: string
+  state: string
+  created_at: string
+}
+
+function toPlaceholder(entry: OutboxEntry, authorID: string): MessageData {
+  return {
+    id: entry.id,
+    channel_id: entry.recipient,
+    author_id: authorID,
+    content: entry.content,
+    type: 'text',
+    created_at: entry.created_at,
+    author_name: '',
+    author_avatar: '',
+    delivery: entry.state as DeliveryState,
+  }
+}
+
+async function queuedPlaceholders(channelID: string): Promise<MessageData[]> {
+  if (!isServerMode()) return []
+  try {
+    const entries = (await App.GetOutbox('server', channelID)) ?? []
+    if (entries.length > 0) listenOutbox()
+    const authorID = messages.find(m => !m.delivery)?.author_id ?? ''
+    return entries
+      .filter(e => e.state !== 'delivered')
+      .map(e => toPlaceholder(e, authorID))
+  } catch {
+    return [] // outside the Wails runtime
+  }
+}
+
+function listenOutbox() {
+  if (outboxListening) return
+  outboxListening = true
+  try {
+    EventsOn('outbox:update', (entry: OutboxEntry) => {
+      if (entry.target !== 'server') return
+      messages = messages.map(m =>
+        m.id === entry.id && m.delivery ? { ...m, delivery: entry.state as DeliveryState } : m,
+      )
+    })
+  } catch { /* outside the Wails runtime */ }
+}
+
+/** Delivers queued server messages whose retry backoff has elapsed. */
+async function flushServerOutbox(): Promise<void> {
+  if (flushingOutbox) return
+  flushingOutbox = true
+  try {
+    const entries = (await App.ClaimServerOutbox(OUTBOX_CLAIM_LIMIT)) ?? []
+    for (const entry of entries) {
+      try {
+        await ensureValidToken()
+        const sent = (await apiChat.sendMessage(entry.recipient, entry.content)) as unknown as MessageData
+        await App.ReportServerOutbox(entry.id, '')
+        messages = messages.map(m => (m.id === entry.id ? sent : m))
+      } catch (e) {
+        const reason = e instanceof Error ? e.message : 'Failed to send message'
+        await App.ReportServerOutbox(entry.id, reason).catch(() => {})
+      }
+    }
+  } catch {
+    // outside the Wails runtime
+  } finally {
+    flushingOutbox = false
+  }
+}
+
 function startMessagePolling(channelID: string) {
   stopMessagePolling()
   messagePollTimer = setInterval(() => pollNewMessages(channelID), MESSAGE_POLL_INTERVAL)
@@ -10,18 +10,22 @@ export interface P2PPeer {
   source: 'lan' | 'room'
 }
 
+export type DeliveryStatus = 'queued' | 'sent' | 'delivered' | 'failed'
+
 export interface P2PMessage {
   id: string
   peerID: string
   direction: 'sent' | 'received'
   content: string
   sentAt: string
+  status?: DeliveryStatus
 }
 
 // Estado reativo (module-level $state para SSR safety)
 let peers = $state<P2PPeer[]>([])
 let activePeerID = $state<string | null>(null)
 let messages = $state<Record<string, P2PMessage[]>>({})
+let typingPeers = $state<Record<string, boolean>>({})
 let roomCode = $state('')
 let joining = $state(false)
 let sending = $state(false)
@@ -35,6 +39,7 @@ export function getP2P() {
     get peers() { return peers },
     get activePeerID() { return activePeerID },
     get messages() { return messages },
+    get typingPeers() { return typingPeers },
     get roomCode() { return roomCode },
     get joining() { return joining },
     get sending() { return sending },
@@ -87,11 +92,29 @@ function listenMessages() {
         content: msg.content,
         sentAt: msg.sent_at,
       }
+      typingPeers = { ...typingPeers, [m.peerID]: false }
+      // reenvios do mesmo ID não duplicam a conversa
+      if (messages[m.peerID]?.some(existing => existing.id === m.id)) return
       messages = {
         ...messages,
         [m.peerID]: [...(messages[m.peerID] ?? []), m],
       }
     })
+    // Indicador de digitação: o backend expira entradas sem renovação
+    EventsOn('typing:update', (ev: { scope: string; user_id: string; typing: boolean }) => {
+      if (!ev.scope.startsWith('peer:')) return
+      typingPeers = { ...typingPeers, [ev.user_id]: ev.typing }
+    })
+    // Atualizações de estado do outbox (queued → sent → delivered | failed)
+    EventsOn('outbox:update', (entry: { id: string; target: string; recipient: string; state: DeliveryStatus }) => {
+      if (entry.target !== 'p2p' || !messages[entry.recipient]) return
+      messages = {
+        ...messages,
+        [entry.recipient]: messages[entry.recipient].map(m =>
+          m.id === entry.id ? { ...m, status: entry.state } : m,
+        ),
+      }
+    })
   } catch { /* fora do Wails */ }
 }
 
@@ -113,21 +136,31 @@ export async function loadMessages(peerID: string) {
         direction: m.direction as 'sent' | 'received',
         content: m.content,
         sentAt: m.sent_at,
+        status: (m.status || undefined) as DeliveryStatus | undefined,
       })),
     }
   } catch { /* silencioso */ }
 }
 
+// Notifica o peer enquanto o usuário digita; o backend limita a frequência de envio
+export async function notifyTyping(peerID: string, typing: boolean) {
+  try {
+    await App.SendP2PTyping(peerID, typing)
+  } catch { /* silencioso */ }
+}
+
 export async function sendMessage(peerID: string, content: string) {
   sending = true
   try {
-    await App.SendP2PMessage(peerID, content)
+    // Mensagens para peers offline ficam no outbox e são reenviadas automaticamente
+    const saved = await App.SendP2PMessage(peerID, content)
     const msg: P2PMessage = {
-      id: `local-${Date.now()}`,
+      id: saved.id,
       peerID,
       direction: 'sent',
       content,
-      sentAt: new Date().toISOString(),
+      sentAt: saved.sent_at,
+      status: saved.status as DeliveryStatus,
     }
     messages = {
       ...messages,
@@ -2,37 +2,84 @@
 // This file is automatically generated. DO NOT EDIT
 import {auth} from '../models';
 import {server} from '../models';
+import {bookmarks} from '../models';
+import {emoji} from '../models';
 import {chat} from '../models';
 import {files} from '../models';
 import {friends} from '../models';
 import {observability} from '../models';
 import {sqlite} from '../models';
 import {p2p} from '../models';
+import {outbox} from '../models';
+import {preferences} from '../models';
 import {translation} from '../models';
+import {typing} from '../models';
 import {version} from '../models';
 import {signaling} from '../models';
 import {voice} from '../models';
 
 export function AcceptFriendRequest(arg1:string,arg2:string):Promise<void>;
 
+export function AcceptOwnershipTransfer(arg1:string,arg2:string):Promise<server.Server>;
+
+export function AddBookmark(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string):Promise<bookmarks.Bookmark>;
+
+export function AddMemberRole(arg1:string,arg2:string,arg3:string,arg4:string):Promise<void>;
+
+export function AddReaction(arg1:string,arg2:string,arg3:string):Promise<chat.Message>;
+
 export function ApplyAutoUpdate(arg1:string,arg2:string,arg3:string):Promise<void>;
 
+export function BanMember(arg1:string,arg2:string,arg3:string,arg4:string,arg5:number):Promise<server.Ban>;
+
 export function BlockUser(arg1:string,arg2:string):Promise<void>;
 
+export function CancelOwnershipTransfer(arg1:string,arg2:string):Promise<void>;
+
+export function ClaimServerOutbox(arg1:number):Promise<Array<outbox.Entry>>;
+
+export function ClosePoll(arg1:string,arg2:string,arg3:boolean):Promise<chat.Message>;
+
 export function CompleteLogin(arg1:string,arg2:number):Promise<auth.AuthState>;
 
+export function CreateCategory(arg1:string,arg2:string,arg3:string):Promise<server.Category>;
+
 export function CreateChannel(arg1:string,arg2:string,arg3:string,arg4:string):Promise<server.Channel>;
 
+export function CreateInvite(arg1:string,arg2:string,arg3:server.InviteOptions):Promise<server.Invite>;
+
+export function CreatePoll(arg1:string,arg2:string,arg3:chat.PollInput):Promise<chat.Message>;
+
+export function CreateRole(arg1:string,arg2:string,arg3:string,arg4:number,arg5:string):Promise<server.Role>;
+
 export function CreateServer(arg1:string,arg2:string):Promise<server.Server>;
 
+export function CreateServerFromSnapshot(arg1:server.TemplateSnapshot,arg2:string,arg3:string):Promise<server.Server>;
+
+export function CreateServerFromTemplate(arg1:string,arg2:string,arg3:string):Promise<server.Server>;
+
+export function CreateTemplate(arg1:string,arg2:string,arg3:string,arg4:string):Promise<server.Template>;
+
 export function DeleteAttachment(arg1:string):Promise<void>;
 
+export function DeleteBookmark(arg1:string,arg2:string):Promise<void>;
+
+export function DeleteCategory(arg1:string,arg2:string,arg3:string):Promise<void>;
+
 export function DeleteChannel(arg1:string,arg2:string,arg3:string):Promise<void>;
 
+export function DeleteChannelOverwrite(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string):Promise<void>;
+
+export function DeleteEmoji(arg1:string,arg2:string,arg3:string):Promise<void>;
+
 export function DeleteMessage(arg1:string,arg2:string,arg3:boolean):Promise<void>;
 
+export function DeleteRole(arg1:string,arg2:string,arg3:string):Promise<void>;
+
 export function DeleteServer(arg1:string,arg2:string):Promise<void>;
 
+export function DeleteTemplate(arg1:string,arg2:string,arg3:string):Promise<void>;
+
 export function DisableTranslation():Promise<void>;
 
 export function DisableVoiceTranslation():Promise<void>;
@@ -45,10 +92,16 @@ export function EnableTranslation(arg1:string,arg2:string):Promise<void>;
 
 export function EnableVoiceTranslation(arg1:string,arg2:string):Promise<void>;
 
+export function ExportChannelHistory(arg1:string,arg2:string,arg3:string,arg4:string):Promise<string>;
+
+export function ExportDirectMessages(arg1:string,arg2:string,arg3:string):Promise<string>;
+
 export function GenerateInvite(arg1:string,arg2:string):Promise<string>;
 
 export function GetAttachments(arg1:string):Promise<Array<files.Attachment>>;
 
+export function GetEmojiImage(arg1:string):Promise<Array<number>>;
+
 export function GetFriends(arg1:string):Promise<Array<friends.FriendView>>;
 
 export function GetHealth():Promise<observability.Health>;
@@ -57,6 +110,8 @@ export function GetInviteInfo(arg1:string):Promise<server.InviteInfo>;
 
 export function GetMessages(arg1:string,arg2:string,arg3:string,arg4:number):Promise<Array<chat.Message>>;
 
+export function GetOutbox(arg1:string,arg2:string):Promise<Array<outbox.Entry>>;
+
 export function GetP2PMessages(arg1:string,arg2:number):Promise<Array<sqlite.P2PMessage>>;
 
 export function GetP2PPeerName(arg1:string):Promise<string>;
@@ -65,12 +120,18 @@ export function GetP2PPeers():Promise<Array<p2p.PeerInfo>>;
 
 export function GetP2PRoomCode():Promise<string>;
 
+export function GetP2PTyping(arg1:string):Promise<Array<typing.Typer>>;
+
 export function GetPendingRequests(arg1:string):Promise<Array<friends.FriendRequestView>>;
 
+export function GetPreferences(arg1:string):Promise<preferences.Preferences>;
+
 export function GetPublicURL():Promise<string>;
 
 export function GetServer(arg1:string):Promise<server.Server>;
 
+export function GetTemplate(arg1:string):Promise<server.Template>;
+
 export function GetTranslationStatus():Promise<translation.Status>;
 
 export function GetVersion():Promise<version.Info>;
@@ -95,46 +156,120 @@ export function KickMember(arg1:string,arg2:string,arg3:string):Promise<void>;
 
 export function LeaveVoice():Promise<void>;
 
+export function ListAuditLog(arg1:string,arg2:string,arg3:server.AuditQuery):Promise<Array<server.AuditEntry>>;
+
+export function ListBans(arg1:string,arg2:string):Promise<Array<server.Ban>>;
+
+export function ListBookmarkFolders(arg1:string):Promise<Array<bookmarks.Folder>>;
+
+export function ListBookmarks(arg1:string,arg2:boolean,arg3:string,arg4:string,arg5:string,arg6:number):Promise<Array<bookmarks.Bookmark>>;
+
+export function ListCategories(arg1:string):Promise<Array<server.Category>>;
+
+export function ListChannelOverwrites(arg1:string,arg2:string,arg3:string):Promise<Array<server.Overwrite>>;
+
 export function ListChannels(arg1:string):Promise<Array<server.Channel>>;
 
+export function ListInvites(arg1:string,arg2:string):Promise<Array<server.Invite>>;
+
 export function ListMembers(arg1:string):Promise<Array<server.Member>>;
 
+export function ListRoles(arg1:string):Promise<Array<server.Role>>;
+
+export function ListServerEmoji(arg1:string,arg2:string):Promise<Array<emoji.Emoji>>;
+
+export function ListTemplates(arg1:string,arg2:string):Promise<Array<server.Template>>;
+
+export function ListTimeouts(arg1:string,arg2:string):Promise<Array<server.Member>>;
+
 export function ListUserServers(arg1:string):Promise<Array<server.Server>>;
 
 export function Logout(arg1:string):Promise<void>;
 
+export function PendingOwnershipTransfer(arg1:string,arg2:string):Promise<server.OwnershipTransfer>;
+
+export function QueueServerMessage(arg1:string,arg2:string):Promise<outbox.Entry>;
+
 export function RedeemInvite(arg1:string,arg2:string):Promise<server.Server>;
 
 export function RejectFriendRequest(arg1:string,arg2:string):Promise<void>;
 
 export function RemoveFriend(arg1:string,arg2:string):Promise<void>;
 
+export function RemoveMemberRole(arg1:string,arg2:string,arg3:string,arg4:string):Promise<void>;
+
+export function RemoveReaction(arg1:string,arg2:string,arg3:string):Promise<chat.Message>;
+
+export function RemoveTimeout(arg1:string,arg2:string,arg3:string):Promise<void>;
+
+export function RenameCategory(arg1:string,arg2:string,arg3:string,arg4:string):Promise<server.Category>;
+
+export function RenameEmoji(arg1:string,arg2:string,arg3:string,arg4:string):Promise<emoji.Emoji>;
+
+export function ReorderChannels(arg1:string,arg2:string,arg3:server.ChannelLayout):Promise<void>;
+
+export function ReorderRoles(arg1:string,arg2:string,arg3:Array<string>):Promise<void>;
+
+export function ReportServerOutbox(arg1:string,arg2:string):Promise<void>;
+
+export function RequestOwnershipTransfer(arg1:string,arg2:string,arg3:string):Promise<server.OwnershipTransfer>;
+
 export function RestoreSession(arg1:string):Promise<auth.AuthState>;
 
+export function RevokeInvite(arg1:string,arg2:string,arg3:string):Promise<void>;
+
 export function SearchMessages(arg1:string,arg2:string,arg3:number):Promise<Array<chat.SearchResult>>;
 
+export function SearchMessagesScoped(arg1:string,arg2:string,arg3:string,arg4:string,arg5:number):Promise<Array<chat.SearchResult>>;
+
 export function SelectAvatarFile():Promise<string>;
 
 export function SendFriendRequest(arg1:string,arg2:string):Promise<void>;
 
 export function SendMessage(arg1:string,arg2:string,arg3:string):Promise<chat.Message>;
 
-export function SendP2PMessage(arg1:string,arg2:string):Promise<void>;
+export function SendP2PMessage(arg1:string,arg2:string):Promise<sqlite.P2PMessage>;
 
 export function SendP2PProfile(arg1:string,arg2:string):Promise<void>;
 
+export function SendP2PTyping(arg1:string,arg2:boolean):Promise<void>;
+
+export function SetChannelOverwrite(arg1:string,arg2:string,arg3:server.Overwrite):Promise<server.Overwrite>;
+
+export function SetChannelSlowMode(arg1:string,arg2:string,arg3:string,arg4:number):Promise<void>;
+
+export function SetLinkPreviews(arg1:string,arg2:boolean):Promise<preferences.Preferences>;
+
+export function SetReadReceipts(arg1:string,arg2:boolean):Promise<preferences.Preferences>;
+
 export function StartLogin():Promise<auth.DeviceCodeResponse>;
 
+export function SyncTemplate(arg1:string,arg2:string,arg3:string):Promise<server.Template>;
+
+export function TimeoutMember(arg1:string,arg2:string,arg3:string,arg4:number):Promise<server.Member>;
+
 export function ToggleDeafen():Promise<boolean>;
 
 export function ToggleMute():Promise<boolean>;
 
 export function TranslateText(arg1:string,arg2:string,arg3:string):Promise<string>;
 
+export function UnbanMember(arg1:string,arg2:string,arg3:string):Promise<void>;
+
 export function UnblockUser(arg1:string,arg2:string):Promise<void>;
 
+export function UpdateBookmark(arg1:string,arg2:string,arg3:string,arg4:string):Promise<bookmarks.Bookmark>;
+
+export function UpdateChannel(arg1:string,arg2:string,arg3:string,arg4:server.ChannelUpdate):Promise<server.Channel>;
+
 export function UpdateMemberRole(arg1:string,arg2:string,arg3:string,arg4:string):Promise<void>;
 
+export function UpdateRole(arg1:string,arg2:string,arg3:string,arg4:server.RoleUpdate):Promise<server.Role>;
+
 export function UpdateServer(arg1:string,arg2:string,arg3:string,arg4:string):Promise<void>;
 
+export function UploadEmoji(arg1:string,arg2:string,arg3:string,arg4:string,arg5:Array<number>):Promise<emoji.Emoji>;
+
 export function UploadFile(arg1:string,arg2:string,arg3:Array<number>):Promise<files.Attachment>;
+
+export function VotePoll(arg1:string,arg2:string,arg3:Array<string>):Promise<chat.Message>;
@@ -6,42 +6,126 @@ export function AcceptFriendRequest(arg1, arg2) {
   return window['go']['main']['App']['AcceptFriendRequest'](arg1, arg2);
 }
 
+export function AcceptOwnershipTransfer(arg1, arg2) {
+  return window['go']['main']['App']['AcceptOwnershipTransfer'](arg1, arg2);
+}
+
+export function AddBookmark(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['AddBookmark'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function AddMemberRole(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['AddMemberRole'](arg1, arg2, arg3, arg4);
+}
+
+export function AddReaction(arg1, arg2, arg3) {
+  return window['go']['main']['App']['AddReaction'](arg1, arg2, arg3);
+}
+
 export function ApplyAutoUpdate(arg1, arg2, arg3) {
   return window['go']['main']['App']['ApplyAutoUpdate'](arg1, arg2, arg3);
 }
 
+export function BanMember(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['BanMember'](arg1, arg2, arg3, arg4, arg5);
+}
+
 export function BlockUser(arg1, arg2) {
   return window['go']['main']['App']['BlockUser'](arg1, arg2);
 }
 
+export function CancelOwnershipTransfer(arg1, arg2) {
+  return window['go']['main']['App']['CancelOwnershipTransfer'](arg1, arg2);
+}
+
+export function ClaimServerOutbox(arg1) {
+  return window['go']['main']['App']['ClaimServerOutbox'](arg1);
+}
+
+export function ClosePoll(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ClosePoll'](arg1, arg2, arg3);
+}
+
 export function CompleteLogin(arg1, arg2) {
   return window['go']['main']['App']['CompleteLogin'](arg1, arg2);
 }
 
+export function CreateCategory(arg1, arg2, arg3) {
+  return window['go']['main']['App']['CreateCategory'](arg1, arg2, arg3);
+}
+
 export function CreateChannel(arg1, arg2, arg3, arg4) {
   return window['go']['main']['App']['CreateChannel'](arg1, arg2, arg3, arg4);
 }
 
+export function CreateInvite(arg1, arg2, arg3) {
+  return window['go']['main']['App']['CreateInvite'](arg1, arg2, arg3);
+}
+
+export function CreatePoll(arg1, arg2, arg3) {
+  return window['go']['main']['App']['CreatePoll'](arg1, arg2, arg3);
+}
+
+export function CreateRole(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['CreateRole'](arg1, arg2, arg3, arg4, arg5);
+}
+
 export function CreateServer(arg1, arg2) {
   return window['go']['main']['App']['CreateServer'](arg1, arg2);
 }
 
+export function CreateServerFromSnapshot(arg1, arg2, arg3) {
+  return window['go']['main']['App']['CreateServerFromSnapshot'](arg1, arg2, arg3);
+}
+
+export function CreateServerFromTemplate(arg1, arg2, arg3) {
+  return window['go']['main']['App']['CreateServerFromTemplate'](arg1, arg2, arg3);
+}
+
+export function CreateTemplate(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['CreateTemplate'](arg1, arg2, arg3, arg4);
+}
+
 export function DeleteAttachment(arg1) {
   return window['go']['main']['App']['DeleteAttachment'](arg1);
 }
 
+export function DeleteBookmark(arg1, arg2) {
+  return window['go']['main']['App']['DeleteBookmark'](arg1, arg2);
+}
+
+export function DeleteCategory(arg1, arg2, arg3) {
+  return window['go']['main']['App']['DeleteCategory'](arg1, arg2, arg3);
+}
+
 export function DeleteChannel(arg1, arg2, arg3) {
   return window['go']['main']['App']['DeleteChannel'](arg1, arg2, arg3);
 }
 
+export function DeleteChannelOverwrite(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['DeleteChannelOverwrite'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function DeleteEmoji(arg1, arg2, arg3) {
+  return window['go']['main']['App']['DeleteEmoji'](arg1, arg2, arg3);
+}
+
 export function DeleteMessage(arg1, arg2, arg3) {
   return window['go']['main']['App']['DeleteMessage'](arg1, arg2, arg3);
 }
 
+export function DeleteRole(arg1, arg2, arg3) {
+  return window['go']['main']['App']['DeleteRole'](arg1, arg2, arg3);
+}
+
 export function DeleteServer(arg1, arg2) {
   return window['go']['main']['App']['DeleteServer'](arg1, arg2);
 }
 
+export function DeleteTemplate(arg1, arg2, arg3) {
+  return window['go']['main']['App']['DeleteTemplate'](arg1, arg2, arg3);
+}
+
 export function DisableTranslation() {
   return window['go']['main']['App']['DisableTranslation']();
 }
@@ -66,6 +150,14 @@ export function EnableVoiceTranslation(arg1, arg2) {
   return window['go']['main']['App']['EnableVoiceTranslation'](arg1, arg2);
 }
 
+export function ExportChannelHistory(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['ExportChannelHistory'](arg1, arg2, arg3, arg4);
+}
+
+export function ExportDirectMessages(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ExportDirectMessages'](arg1, arg2, arg3);
+}
+
 export function GenerateInvite(arg1, arg2) {
   return window['go']['main']['App']['GenerateInvite'](arg1, arg2);
 }
@@ -74,6 +166,10 @@ export function GetAttachments(arg1) {
   return window['go']['main']['App']['GetAttachments'](arg1);
 }
 
+export function GetEmojiImage(arg1) {
+  return window['go']['main']['App']['GetEmojiImage'](arg1);
+}
+
 export function GetFriends(arg1) {
   return window['go']['main']['App']['GetFriends'](arg1);
 }
@@ -90,6 +186,10 @@ export function GetMessages(arg1, arg2, arg3, arg4) {
   return window['go']['main']['App']['GetMessages'](arg1, arg2, arg3, arg4);
 }
 
+export function GetOutbox(arg1, arg2) {
+  return window['go']['main']['App']['GetOutbox'](arg1, arg2);
+}
+
 export function GetP2PMessages(arg1, arg2) {
   return window['go']['main']['App']['GetP2PMessages'](arg1, arg2);
 }
@@ -106,10 +206,18 @@ export function GetP2PRoomCode() {
   return window['go']['main']['App']['GetP2PRoomCode']();
 }
 
+export function GetP2PTyping(arg1) {
+  return window['go']['main']['App']['GetP2PTyping'](arg1);
+}
+
 export function GetPendingRequests(arg1) {
   return window['go']['main']['App']['GetPendingRequests'](arg1);
 }
 
+export function GetPreferences(arg1) {
+  return window['go']['main']['App']['GetPreferences'](arg1);
+}
+
 export function GetPublicURL() {
   return window['go']['main']['App']['GetPublicURL']();
 }
@@ -118,6 +226,10 @@ export function GetServer(arg1) {
   return window['go']['main']['App']['GetServer'](arg1);
 }
 
+export function GetTemplate(arg1) {
+  return window['go']['main']['App']['GetTemplate'](arg1);
+}
+
 export function GetTranslationStatus() {
   return window['go']['main']['App']['GetTranslationStatus']();
 }
@@ -166,14 +278,58 @@ export function LeaveVoice() {
   return window['go']['main']['App']['LeaveVoice']();
 }
 
+export function ListAuditLog(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ListAuditLog'](arg1, arg2, arg3);
+}
+
+export function ListBans(arg1, arg2) {
+  return window['go']['main']['App']['ListBans'](arg1, arg2);
+}
+
+export function ListBookmarkFolders(arg1) {
+  return window['go']['main']['App']['ListBookmarkFolders'](arg1);
+}
+
+export function ListBookmarks(arg1, arg2, arg3, arg4, arg5, arg6) {
+  return window['go']['main']['App']['ListBookmarks'](arg1, arg2, arg3, arg4, arg5, arg6);
+}
+
+export function ListCategories(arg1) {
+  return window['go']['main']['App']['ListCategories'](arg1);
+}
+
+export function ListChannelOverwrites(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ListChannelOverwrites'](arg1, arg2, arg3);
+}
+
 export function ListChannels(arg1) {
   return window['go']['main']['App']['ListChannels'](arg1);
 }
 
+export function ListInvites(arg1, arg2) {
+  return window['go']['main']['App']['ListInvites'](arg1, arg2);
+}
+
 export function ListMembers(arg1) {
   return window['go']['main']['App']['ListMembers'](arg1);
 }
 
+export function ListRoles(arg1) {
+  return window['go']['main']['App']['ListRoles'](arg1);
+}
+
+export function ListServerEmoji(arg1, arg2) {
+  return window['go']['main']['App']['ListServerEmoji'](arg1, arg2);
+}
+
+export function ListTemplates(arg1, arg2) {
+  return window['go']['main']['App']['ListTemplates'](arg1, arg2);
+}
+
+export function ListTimeouts(arg1, arg2) {
+  return window['go']['main']['App']['ListTimeouts'](arg1, arg2);
+}
+
 export function ListUserServers(arg1) {
   return window['go']['main']['App']['ListUserServers'](arg1);
 }
@@ -182,6 +338,14 @@ export function Logout(arg1) {
   return window['go']['main']['App']['Logout'](arg1);
 }
 
+export function PendingOwnershipTransfer(arg1, arg2) {
+  return window['go']['main']['App']['PendingOwnershipTransfer'](arg1, arg2);
+}
+
+export function QueueServerMessage(arg1, arg2) {
+  return window['go']['main']['App']['QueueServerMessage'](arg1, arg2);
+}
+
 export function RedeemInvite(arg1, arg2) {
   return window['go']['main']['App']['RedeemInvite'](arg1, arg2);
 }
@@ -194,14 +358,58 @@ export function RemoveFriend(arg1, arg2) {
   return window['go']['main']['App']['RemoveFriend'](arg1, arg2);
 }
 
+export function RemoveMemberRole(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['RemoveMemberRole'](arg1, arg2, arg3, arg4);
+}
+
+export function RemoveReaction(arg1, arg2, arg3) {
+  return window['go']['main']['App']['RemoveReaction'](arg1, arg2, arg3);
+}
+
+export function RemoveTimeout(arg1, arg2, arg3) {
+  return window['go']['main']['App']['RemoveTimeout'](arg1, arg2, arg3);
+}
+
+export function RenameCategory(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['RenameCategory'](arg1, arg2, arg3, arg4);
+}
+
+export function RenameEmoji(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['RenameEmoji'](arg1, arg2, arg3, arg4);
+}
+
+export function ReorderChannels(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ReorderChannels'](arg1, arg2, arg3);
+}
+
+export function ReorderRoles(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ReorderRoles'](arg1, arg2, arg3);
+}
+
+export function ReportServerOutbox(arg1, arg2) {
+  return window['go']['main']['App']['ReportServerOutbox'](arg1, arg2);
+}
+
+export function RequestOwnershipTransfer(arg1, arg2, arg3) {
+  return window['go']['main']['App']['RequestOwnershipTransfer'](arg1, arg2, arg3);
+}
+
 export function RestoreSession(arg1) {
   return window['go']['main']['App']['RestoreSession'](arg1);
 }
 
+export function RevokeInvite(arg1, arg2, arg3) {
+  return window['go']['main']['App']['RevokeInvite'](arg1, arg2, arg3);
+}
+
 export function SearchMessages(arg1, arg2, arg3) {
   return window['go']['main']['App']['SearchMessages'](arg1, arg2, arg3);
 }
 
+export function SearchMessagesScoped(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['SearchMessagesScoped'](arg1, arg2, arg3, arg4, arg5);
+}
+
 export function SelectAvatarFile() {
   return window['go']['main']['App']['SelectAvatarFile']();
 }
@@ -222,10 +430,38 @@ export function SendP2PProfile(arg1, arg2) {
   return window['go']['main']['App']['SendP2PProfile'](arg1, arg2);
 }
 
+export function SendP2PTyping(arg1, arg2) {
+  return window['go']['main']['App']['SendP2PTyping'](arg1, arg2);
+}
+
+export function SetChannelOverwrite(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SetChannelOverwrite'](arg1, arg2, arg3);
+}
+
+export function SetChannelSlowMode(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['SetChannelSlowMode'](arg1, arg2, arg3, arg4);
+}
+
+export function SetLinkPreviews(arg1, arg2) {
+  return window['go']['main']['App']['SetLinkPreviews'](arg1, arg2);
+}
+
+export function SetReadReceipts(arg1, arg2) {
+  return window['go']['main']['App']['SetReadReceipts'](arg1, arg2);
+}
+
 export function StartLogin() {
   return window['go']['main']['App']['StartLogin']();
 }
 
+export function SyncTemplate(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SyncTemplate'](arg1, arg2, arg3);
+}
+
+export function TimeoutMember(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['TimeoutMember'](arg1, arg2, arg3, arg4);
+}
+
 export function ToggleDeafen() {
   return window['go']['main']['App']['ToggleDeafen']();
 }
@@ -238,18 +474,42 @@ export function TranslateText(arg1, arg2, arg3) {
   return window['go']['main']['App']['TranslateText'](arg1, arg2, arg3);
 }
 
+export function UnbanMember(arg1, arg2, arg3) {
+  return window['go']['main']['App']['UnbanMember'](arg1, arg2, arg3);
+}
+
 export function UnblockUser(arg1, arg2) {
   return window['go']['main']['App']['UnblockUser'](arg1, arg2);
 }
 
+export function UpdateBookmark(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['UpdateBookmark'](arg1, arg2, arg3, arg4);
+}
+
+export function UpdateChannel(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['UpdateChannel'](arg1, arg2, arg3, arg4);
+}
+
 export function UpdateMemberRole(arg1, arg2, arg3, arg4) {
   return window['go']['main']['App']['UpdateMemberRole'](arg1, arg2, arg3, arg4);
 }
 
+export function UpdateRole(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['UpdateRole'](arg1, arg2, arg3, arg4);
+}
+
 export function UpdateServer(arg1, arg2, arg3, arg4) {
   return window['go']['main']['App']['UpdateServer'](arg1, arg2, arg3, arg4);
 }
 
+export function UploadEmoji(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['UploadEmoji'](arg1, arg2, arg3, arg4, arg5);
+}
+
 export function UploadFile(arg1, arg2, arg3) {
   return window['go']['main']['App']['UploadFile'](arg1, arg2, arg3);
 }
+
+export function VotePoll(arg1, arg2, arg3) {
+  return window['go']['main']['App']['VotePoll'](arg1, arg2, arg3);
+}
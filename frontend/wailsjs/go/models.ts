@@ -6,6 +6,8 @@ export namespace auth {
 	    username: string;
 	    display_name: string;
 	    avatar_url: string;
+	    bot: boolean;
+	    bot_owner_id?: string;
 	    // Go type: time
 	    created_at: any;
 	    // Go type: time
@@ -22,6 +24,8 @@ export namespace auth {
 	        this.username = source["username"];
 	        this.display_name = source["display_name"];
 	        this.avatar_url = source["avatar_url"];
+	        this.bot = source["bot"];
+	        this.bot_owner_id = source["bot_owner_id"];
 	        this.created_at = this.convertValues(source["created_at"], null);
 	        this.updated_at = this.convertValues(source["updated_at"], null);
 	    }
@@ -103,8 +107,163 @@ export namespace auth {
 
 }
 
+export namespace bookmarks {
+	
+	export class Bookmark {
+	    id: string;
+	    kind: string;
+	    message_id: string;
+	    channel_id?: string;
+	    peer_id?: string;
+	    author_id: string;
+	    author_name: string;
+	    content: string;
+	    message_created_at: string;
+	    note: string;
+	    folder: string;
+	    status: string;
+	    // Go type: time
+	    created_at: any;
+	    // Go type: time
+	    updated_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Bookmark(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.kind = source["kind"];
+	        this.message_id = source["message_id"];
+	        this.channel_id = source["channel_id"];
+	        this.peer_id = source["peer_id"];
+	        this.author_id = source["author_id"];
+	        this.author_name = source["author_name"];
+	        this.content = source["content"];
+	        this.message_created_at = source["message_created_at"];
+	        this.note = source["note"];
+	        this.folder = source["folder"];
+	        this.status = source["status"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.updated_at = this.convertValues(source["updated_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Folder {
+	    name: string;
+	    count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Folder(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.count = source["count"];
+	    }
+	}
+
+}
+
 export namespace chat {
 	
+	export class PollOption {
+	    id: string;
+	    label: string;
+	    votes: number;
+	    voters?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new PollOption(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.label = source["label"];
+	        this.votes = source["votes"];
+	        this.voters = source["voters"];
+	    }
+	}
+	export class Poll {
+	    options: PollOption[];
+	    multiple_choice: boolean;
+	    anonymous: boolean;
+	    closes_at?: string;
+	    closed: boolean;
+	    total_voters: number;
+	    my_votes: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Poll(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.options = this.convertValues(source["options"], PollOption);
+	        this.multiple_choice = source["multiple_choice"];
+	        this.anonymous = source["anonymous"];
+	        this.closes_at = source["closes_at"];
+	        this.closed = source["closed"];
+	        this.total_voters = source["total_voters"];
+	        this.my_votes = source["my_votes"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Reaction {
+	    emoji: string;
+	    emoji_id?: string;
+	    count: number;
+	    me: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Reaction(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.emoji = source["emoji"];
+	        this.emoji_id = source["emoji_id"];
+	        this.count = source["count"];
+	        this.me = source["me"];
+	    }
+	}
 	export class Message {
 	    id: string;
 	    channel_id: string;
@@ -115,6 +274,10 @@ export namespace chat {
 	    created_at: string;
 	    author_name?: string;
 	    author_avatar?: string;
+	    author_type: string;
+	    webhook_id?: string;
+	    poll?: Poll;
+	    reactions?: Reaction[];
 	
 	    static createFrom(source: any = {}) {
 	        return new Message(source);
@@ -131,6 +294,48 @@ export namespace chat {
 	        this.created_at = source["created_at"];
 	        this.author_name = source["author_name"];
 	        this.author_avatar = source["author_avatar"];
+	        this.author_type = source["author_type"];
+	        this.webhook_id = source["webhook_id"];
+	        this.poll = this.convertValues(source["poll"], Poll);
+	        this.reactions = this.convertValues(source["reactions"], Reaction);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PollInput {
+	    question: string;
+	    options: string[];
+	    multiple_choice: boolean;
+	    anonymous: boolean;
+	    closes_at?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new PollInput(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.question = source["question"];
+	        this.options = source["options"];
+	        this.multiple_choice = source["multiple_choice"];
+	        this.anonymous = source["anonymous"];
+	        this.closes_at = source["closes_at"];
 	    }
 	}
 	export class SearchResult {
@@ -143,6 +348,7 @@ export namespace chat {
 	    created_at: string;
 	    author_name?: string;
 	    author_avatar?: string;
+	    server_id: string;
 	    snippet: string;
 	
 	    static createFrom(source: any = {}) {
@@ -160,12 +366,63 @@ export namespace chat {
 	        this.created_at = source["created_at"];
 	        this.author_name = source["author_name"];
 	        this.author_avatar = source["author_avatar"];
+	        this.server_id = source["server_id"];
 	        this.snippet = source["snippet"];
 	    }
 	}
 
 }
 
+export namespace emoji {
+	
+	export class Emoji {
+	    id: string;
+	    server_id: string;
+	    name: string;
+	    mime_type: string;
+	    size_bytes: number;
+	    animated: boolean;
+	    created_by: string;
+	    // Go type: time
+	    created_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Emoji(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.server_id = source["server_id"];
+	        this.name = source["name"];
+	        this.mime_type = source["mime_type"];
+	        this.size_bytes = source["size_bytes"];
+	        this.animated = source["animated"];
+	        this.created_by = source["created_by"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
 export namespace files {
 	
 	export class Attachment {
@@ -309,6 +566,41 @@ export namespace observability {
 
 }
 
+export namespace outbox {
+	
+	export class Entry {
+	    id: string;
+	    target: string;
+	    recipient: string;
+	    content: string;
+	    state: string;
+	    attempts: number;
+	    last_error?: string;
+	    next_attempt_at: string;
+	    created_at: string;
+	    updated_at: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Entry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.target = source["target"];
+	        this.recipient = source["recipient"];
+	        this.content = source["content"];
+	        this.state = source["state"];
+	        this.attempts = source["attempts"];
+	        this.last_error = source["last_error"];
+	        this.next_attempt_at = source["next_attempt_at"];
+	        this.created_at = source["created_at"];
+	        this.updated_at = source["updated_at"];
+	    }
+	}
+
+}
+
 export namespace p2p {
 	
 	export class PeerInfo {
@@ -330,58 +622,93 @@ export namespace p2p {
 
 }
 
+export namespace preferences {
+	
+	export class Preferences {
+	    user_id: string;
+	    link_previews: boolean;
+	    read_receipts: boolean;
+	    updated_at?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Preferences(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.user_id = source["user_id"];
+	        this.link_previews = source["link_previews"];
+	        this.read_receipts = source["read_receipts"];
+	        this.updated_at = source["updated_at"];
+	    }
+	}
+
+}
+
 export namespace server {
 	
-	export class Channel {
-	    id: string;
+	export class AuditEntry {
+	    id: number;
 	    server_id: string;
-	    name: string;
-	    type: string;
-	    position: number;
+	    actor_id: string;
+	    actor_username: string;
+	    action: string;
+	    target_type: string;
+	    target_id: string;
+	    details?: any;
 	    created_at: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new Channel(source);
+	        return new AuditEntry(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.id = source["id"];
 	        this.server_id = source["server_id"];
-	        this.name = source["name"];
-	        this.type = source["type"];
-	        this.position = source["position"];
+	        this.actor_id = source["actor_id"];
+	        this.actor_username = source["actor_username"];
+	        this.action = source["action"];
+	        this.target_type = source["target_type"];
+	        this.target_id = source["target_id"];
+	        this.details = source["details"];
 	        this.created_at = source["created_at"];
 	    }
 	}
-	export class InviteInfo {
-	    server_id: string;
-	    server_name: string;
-	    invite_code: string;
-	    member_count: number;
+	export class AuditQuery {
+	    actor_id?: string;
+	    action?: string;
+	    target_type?: string;
+	    target_id?: string;
+	    before?: number;
+	    limit?: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new InviteInfo(source);
+	        return new AuditQuery(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.server_id = source["server_id"];
-	        this.server_name = source["server_name"];
-	        this.invite_code = source["invite_code"];
-	        this.member_count = source["member_count"];
+	        this.actor_id = source["actor_id"];
+	        this.action = source["action"];
+	        this.target_type = source["target_type"];
+	        this.target_id = source["target_id"];
+	        this.before = source["before"];
+	        this.limit = source["limit"];
 	    }
 	}
-	export class Member {
+	export class Ban {
 	    server_id: string;
 	    user_id: string;
 	    username: string;
-	    avatar_url: string;
-	    role: string;
-	    joined_at: string;
+	    actor_id: string;
+	    reason: string;
+	    // Go type: time
+	    expires_at?: any;
+	    created_at: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new Member(source);
+	        return new Ban(source);
 	    }
 	
 	    constructor(source: any = {}) {
@@ -389,9 +716,382 @@ export namespace server {
 	        this.server_id = source["server_id"];
 	        this.user_id = source["user_id"];
 	        this.username = source["username"];
-	        this.avatar_url = source["avatar_url"];
-	        this.role = source["role"];
-	        this.joined_at = source["joined_at"];
+	        this.actor_id = source["actor_id"];
+	        this.reason = source["reason"];
+	        this.expires_at = this.convertValues(source["expires_at"], null);
+	        this.created_at = source["created_at"];
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Category {
+	    id: string;
+	    server_id: string;
+	    name: string;
+	    position: number;
+	    created_at: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Category(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.server_id = source["server_id"];
+	        this.name = source["name"];
+	        this.position = source["position"];
+	        this.created_at = source["created_at"];
+	    }
+	}
+	export class CategoryLayout {
+	    id: string;
+	    channels: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new CategoryLayout(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.channels = source["channels"];
+	    }
+	}
+	export class Channel {
+	    id: string;
+	    server_id: string;
+	    name: string;
+	    type: string;
+	    position: number;
+	    slow_mode_seconds: number;
+	    created_at: string;
+	    category_id?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Channel(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.server_id = source["server_id"];
+	        this.name = source["name"];
+	        this.type = source["type"];
+	        this.position = source["position"];
+	        this.slow_mode_seconds = source["slow_mode_seconds"];
+	        this.created_at = source["created_at"];
+	        this.category_id = source["category_id"];
+	    }
+	}
+	export class ChannelLayout {
+	    uncategorized: string[];
+	    categories: CategoryLayout[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ChannelLayout(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.uncategorized = source["uncategorized"];
+	        this.categories = this.convertValues(source["categories"], CategoryLayout);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ChannelUpdate {
+	    name?: string;
+	    type?: string;
+	    category_id?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ChannelUpdate(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.type = source["type"];
+	        this.category_id = source["category_id"];
+	    }
+	}
+	export class Invite {
+	    code: string;
+	    server_id: string;
+	    creator_id: string;
+	    max_uses: number;
+	    uses: number;
+	    // Go type: time
+	    expires_at?: any;
+	    vanity: boolean;
+	    created_at: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Invite(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.code = source["code"];
+	        this.server_id = source["server_id"];
+	        this.creator_id = source["creator_id"];
+	        this.max_uses = source["max_uses"];
+	        this.uses = source["uses"];
+	        this.expires_at = this.convertValues(source["expires_at"], null);
+	        this.vanity = source["vanity"];
+	        this.created_at = source["created_at"];
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class InviteInfo {
+	    server_id: string;
+	    server_name: string;
+	    invite_code: string;
+	    member_count: number;
+	    // Go type: time
+	    expires_at?: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new InviteInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.server_id = source["server_id"];
+	        this.server_name = source["server_name"];
+	        this.invite_code = source["invite_code"];
+	        this.member_count = source["member_count"];
+	        this.expires_at = this.convertValues(source["expires_at"], null);
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class InviteOptions {
+	    max_uses?: number;
+	    max_age?: number;
+	    code?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new InviteOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.max_uses = source["max_uses"];
+	        this.max_age = source["max_age"];
+	        this.code = source["code"];
+	    }
+	}
+	export class Member {
+	    server_id: string;
+	    user_id: string;
+	    username: string;
+	    avatar_url: string;
+	    role: string;
+	    roles: string[];
+	    joined_at: string;
+	    // Go type: time
+	    timeout_until?: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Member(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.server_id = source["server_id"];
+	        this.user_id = source["user_id"];
+	        this.username = source["username"];
+	        this.avatar_url = source["avatar_url"];
+	        this.role = source["role"];
+	        this.roles = source["roles"];
+	        this.joined_at = source["joined_at"];
+	        this.timeout_until = this.convertValues(source["timeout_until"], null);
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Overwrite {
+	    channel_id: string;
+	    target_type: string;
+	    target_id: string;
+	    allow: string;
+	    deny: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Overwrite(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.channel_id = source["channel_id"];
+	        this.target_type = source["target_type"];
+	        this.target_id = source["target_id"];
+	        this.allow = source["allow"];
+	        this.deny = source["deny"];
+	    }
+	}
+	export class OwnershipTransfer {
+	    server_id: string;
+	    from_id: string;
+	    to_id: string;
+	    // Go type: time
+	    expires_at: any;
+	    created_at: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new OwnershipTransfer(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.server_id = source["server_id"];
+	        this.from_id = source["from_id"];
+	        this.to_id = source["to_id"];
+	        this.expires_at = this.convertValues(source["expires_at"], null);
+	        this.created_at = source["created_at"];
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Role {
+	    id: string;
+	    server_id: string;
+	    name: string;
+	    color: number;
+	    position: number;
+	    permissions: string;
+	    default: boolean;
+	    created_at: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Role(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.server_id = source["server_id"];
+	        this.name = source["name"];
+	        this.color = source["color"];
+	        this.position = source["position"];
+	        this.permissions = source["permissions"];
+	        this.default = source["default"];
+	        this.created_at = source["created_at"];
+	    }
+	}
+	export class RoleUpdate {
+	    name?: string;
+	    color?: number;
+	    permissions?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RoleUpdate(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.color = source["color"];
+	        this.permissions = source["permissions"];
 	    }
 	}
 	export class Server {
@@ -416,6 +1116,180 @@ export namespace server {
 	        this.created_at = source["created_at"];
 	    }
 	}
+	export class TemplateRole {
+	    name: string;
+	    color: number;
+	    permissions: string;
+	    preset?: string;
+	    default?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new TemplateRole(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.color = source["color"];
+	        this.permissions = source["permissions"];
+	        this.preset = source["preset"];
+	        this.default = source["default"];
+	    }
+	}
+	export class TemplateCategory {
+	    name: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TemplateCategory(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	    }
+	}
+	export class TemplateOverwrite {
+	    role: number;
+	    allow: string;
+	    deny: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TemplateOverwrite(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.role = source["role"];
+	        this.allow = source["allow"];
+	        this.deny = source["deny"];
+	    }
+	}
+	export class TemplateChannel {
+	    name: string;
+	    type: string;
+	    slow_mode_seconds: number;
+	    category?: number;
+	    overwrites?: TemplateOverwrite[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TemplateChannel(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.type = source["type"];
+	        this.slow_mode_seconds = source["slow_mode_seconds"];
+	        this.category = source["category"];
+	        this.overwrites = this.convertValues(source["overwrites"], TemplateOverwrite);
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TemplateSnapshot {
+	    format: number;
+	    name: string;
+	    icon_url: string;
+	    roles: TemplateRole[];
+	    categories: TemplateCategory[];
+	    channels: TemplateChannel[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TemplateSnapshot(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.format = source["format"];
+	        this.name = source["name"];
+	        this.icon_url = source["icon_url"];
+	        this.roles = this.convertValues(source["roles"], TemplateRole);
+	        this.categories = this.convertValues(source["categories"], TemplateCategory);
+	        this.channels = this.convertValues(source["channels"], TemplateChannel);
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Template {
+	    code: string;
+	    server_id: string;
+	    creator_id: string;
+	    name: string;
+	    description: string;
+	    version: number;
+	    snapshot: TemplateSnapshot;
+	    dirty: boolean;
+	    created_at: string;
+	    updated_at: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Template(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.code = source["code"];
+	        this.server_id = source["server_id"];
+	        this.creator_id = source["creator_id"];
+	        this.name = source["name"];
+	        this.description = source["description"];
+	        this.version = source["version"];
+	        this.snapshot = this.convertValues(source["snapshot"], TemplateSnapshot);
+	        this.dirty = source["dirty"];
+	        this.created_at = source["created_at"];
+	        this.updated_at = source["updated_at"];
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 
 }
 
@@ -458,6 +1332,7 @@ export namespace sqlite {
 	    direction: string;
 	    content: string;
 	    sent_at: string;
+	    status?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new P2PMessage(source);
@@ -470,6 +1345,7 @@ export namespace sqlite {
 	        this.direction = source["direction"];
 	        this.content = source["content"];
 	        this.sent_at = source["sent_at"];
+	        this.status = source["status"];
 	    }
 	}
 
@@ -500,6 +1376,25 @@ export namespace translation {
 
 }
 
+export namespace typing {
+	
+	export class Typer {
+	    user_id: string;
+	    expires_at: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Typer(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.user_id = source["user_id"];
+	        this.expires_at = source["expires_at"];
+	    }
+	}
+
+}
+
 export namespace version {
 	
 	export class Info {
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/concord-chat/concord/internal/server"
+)
+
+// handleListAuditLog returns the audit log of a server, newest first.
+// GET /api/v1/servers/{serverID}/audit-log
+// Query params: actor_id, action, target_type, target_id (filters), before (entry ID), limit
+// Requires PermViewAuditLog.
+// Complexity: O(log n + l) where l = limit
+func (s *Server) handleListAuditLog(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	if serverID == "" {
+		writeError(w, http.StatusBadRequest, "server ID is required")
+		return
+	}
+
+	query := r.URL.Query()
+	q := server.AuditQuery{
+		ActorID:    query.Get("actor_id"),
+		Action:     query.Get("action"),
+		TargetType: query.Get("target_type"),
+		TargetID:   query.Get("target_id"),
+	}
+	if beforeStr := query.Get("before"); beforeStr != "" {
+		before, err := strconv.ParseInt(beforeStr, 10, 64)
+		if err != nil || before < 1 {
+			writeError(w, http.StatusBadRequest, "before must be a positive entry ID")
+			return
+		}
+		q.Before = before
+	}
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 {
+			writeError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		q.Limit = limit
+	}
+
+	entries, err := s.servers.AuditLog(r.Context(), serverID, userID, q)
+	if err != nil {
+		writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if entries == nil {
+		entries = []*server.AuditEntry{}
+	}
+	writeJSON(w, http.StatusOK, entries)
+}
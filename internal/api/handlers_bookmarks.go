@@ -0,0 +1,187 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/concord-chat/concord/internal/bookmarks"
+)
+
+// SetBookmarks enables the bookmark endpoints.
+func (s *Server) SetBookmarks(svc *bookmarks.Service) {
+	s.bookmarks = svc
+}
+
+// createBookmarkRequest is the body for bookmarking a message.
+type createBookmarkRequest struct {
+	Kind      string `json:"kind"` // "channel" (default) or "dm"
+	MessageID string `json:"message_id"`
+	Note      string `json:"note"`
+	Folder    string `json:"folder"`
+}
+
+// updateBookmarkRequest is the body for editing a bookmark; omitted fields are unchanged.
+type updateBookmarkRequest struct {
+	Note   *string `json:"note"`
+	Folder *string `json:"folder"`
+}
+
+// handleListBookmarks returns the caller's bookmarks, newest first.
+// GET /api/v1/bookmarks
+// Query params: folder (exact folder; empty for unfiled), q (search), before (bookmark ID), limit
+// Complexity: O(k + c) where k = bookmarks returned, c = channels readable by the user
+func (s *Server) handleListBookmarks(w http.ResponseWriter, r *http.Request) {
+	if s.bookmarks == nil {
+		writeError(w, http.StatusServiceUnavailable, "bookmarks not available")
+		return
+	}
+
+	query := r.URL.Query()
+	opts := bookmarks.ListOpts{
+		Query:  query.Get("q"),
+		Before: query.Get("before"),
+	}
+	if query.Has("folder") {
+		folder := query.Get("folder")
+		opts.Folder = &folder
+	}
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 {
+			writeError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		opts.Limit = limit
+	}
+
+	list, err := s.bookmarks.List(r.Context(), UserIDFromContext(r.Context()), opts)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("failed to list bookmarks")
+		writeError(w, http.StatusInternalServerError, "failed to list bookmarks")
+		return
+	}
+	if list == nil {
+		list = []*bookmarks.Bookmark{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// handleListBookmarkFolders returns the caller's bookmark folders with their sizes.
+// GET /api/v1/bookmarks/folders
+// Complexity: O(n) where n = number of bookmarks of the user
+func (s *Server) handleListBookmarkFolders(w http.ResponseWriter, r *http.Request) {
+	if s.bookmarks == nil {
+		writeError(w, http.StatusServiceUnavailable, "bookmarks not available")
+		return
+	}
+
+	folders, err := s.bookmarks.Folders(r.Context(), UserIDFromContext(r.Context()))
+	if err != nil {
+		s.logger.Error().Err(err).Msg("failed to list bookmark folders")
+		writeError(w, http.StatusInternalServerError, "failed to list bookmark folders")
+		return
+	}
+	if folders == nil {
+		folders = []*bookmarks.Folder{}
+	}
+	writeJSON(w, http.StatusOK, folders)
+}
+
+// handleCreateBookmark bookmarks a channel message or direct message the caller can read.
+// POST /api/v1/bookmarks
+// Body: { "kind": "channel", "message_id": "...", "note": "...", "folder": "..." }
+// Complexity: O(c) where c = channels of the message's server
+func (s *Server) handleCreateBookmark(w http.ResponseWriter, r *http.Request) {
+	if s.bookmarks == nil {
+		writeError(w, http.StatusServiceUnavailable, "bookmarks not available")
+		return
+	}
+
+	var req createBookmarkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.MessageID == "" {
+		writeError(w, http.StatusBadRequest, "message ID is required")
+		return
+	}
+	if req.Kind == "" {
+		req.Kind = bookmarks.KindChannel
+	}
+
+	b, err := s.bookmarks.Add(r.Context(), UserIDFromContext(r.Context()), req.Kind, req.MessageID, req.Note, req.Folder)
+	if err != nil {
+		writeBookmarkError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, b)
+}
+
+// handleUpdateBookmark changes the note and/or folder of one of the caller's bookmarks.
+// PATCH /api/v1/bookmarks/{bookmarkID}
+// Body: { "note": "...", "folder": "..." }
+// Complexity: O(c) where c = channels readable by the user
+func (s *Server) handleUpdateBookmark(w http.ResponseWriter, r *http.Request) {
+	if s.bookmarks == nil {
+		writeError(w, http.StatusServiceUnavailable, "bookmarks not available")
+		return
+	}
+
+	bookmarkID := chi.URLParam(r, "bookmarkID")
+	if bookmarkID == "" {
+		writeError(w, http.StatusBadRequest, "bookmark ID is required")
+		return
+	}
+
+	var req updateBookmarkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	b, err := s.bookmarks.Update(r.Context(), UserIDFromContext(r.Context()), bookmarkID, req.Note, req.Folder)
+	if err != nil {
+		writeBookmarkError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, b)
+}
+
+// handleDeleteBookmark removes one of the caller's bookmarks.
+// DELETE /api/v1/bookmarks/{bookmarkID}
+// Complexity: O(1)
+func (s *Server) handleDeleteBookmark(w http.ResponseWriter, r *http.Request) {
+	if s.bookmarks == nil {
+		writeError(w, http.StatusServiceUnavailable, "bookmarks not available")
+		return
+	}
+
+	bookmarkID := chi.URLParam(r, "bookmarkID")
+	if bookmarkID == "" {
+		writeError(w, http.StatusBadRequest, "bookmark ID is required")
+		return
+	}
+
+	if err := s.bookmarks.Delete(r.Context(), UserIDFromContext(r.Context()), bookmarkID); err != nil {
+		writeBookmarkError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeBookmarkError maps bookmark service errors to HTTP statuses.
+func writeBookmarkError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, bookmarks.ErrNotFound), errors.Is(err, bookmarks.ErrMessageNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, bookmarks.ErrAlreadyBookmarked):
+		writeError(w, http.StatusConflict, err.Error())
+	default:
+		writeError(w, http.StatusBadRequest, err.Error())
+	}
+}
@@ -0,0 +1,200 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/concord-chat/concord/internal/auth"
+	"github.com/concord-chat/concord/internal/server"
+)
+
+// createBotRequest is the body for creating a bot.
+type createBotRequest struct {
+	Username    string `json:"username"`
+	DisplayName string `json:"display_name"`
+}
+
+// createTokenRequest is the body for issuing an API token.
+type createTokenRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+// addBotRequest is the body for adding a bot to a server.
+type addBotRequest struct {
+	InviteCode string `json:"invite_code"`
+	Role       string `json:"role"`
+}
+
+// handleListBots returns the bots owned by the authenticated user.
+// GET /api/v1/bots
+// Complexity: O(n) where n = number of bots owned
+func (s *Server) handleListBots(w http.ResponseWriter, r *http.Request) {
+	if s.auth == nil {
+		writeError(w, http.StatusServiceUnavailable, "auth service not available")
+		return
+	}
+
+	bots, err := s.auth.ListBots(r.Context(), UserIDFromContext(r.Context()))
+	if err != nil {
+		s.logger.Error().Err(err).Msg("failed to list bots")
+		writeError(w, http.StatusInternalServerError, "failed to list bots")
+		return
+	}
+	if bots == nil {
+		bots = []*auth.User{}
+	}
+	writeJSON(w, http.StatusOK, bots)
+}
+
+// handleCreateBot creates a bot owned by the authenticated user.
+// POST /api/v1/bots
+// Body: { "username": "deploy-bot", "display_name": "Deploy Bot" }
+// Complexity: O(1)
+func (s *Server) handleCreateBot(w http.ResponseWriter, r *http.Request) {
+	if s.auth == nil {
+		writeError(w, http.StatusServiceUnavailable, "auth service not available")
+		return
+	}
+
+	var req createBotRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	bot, err := s.auth.CreateBot(r.Context(), UserIDFromContext(r.Context()), req.Username, req.DisplayName)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, bot)
+}
+
+// handleListAPITokens returns the tokens of a bot owned by the authenticated user.
+// Token values are never included.
+// GET /api/v1/bots/{botID}/tokens
+// Complexity: O(n) where n = number of tokens of the bot
+func (s *Server) handleListAPITokens(w http.ResponseWriter, r *http.Request) {
+	if s.auth == nil {
+		writeError(w, http.StatusServiceUnavailable, "auth service not available")
+		return
+	}
+
+	botID := chi.URLParam(r, "botID")
+	if botID == "" {
+		writeError(w, http.StatusBadRequest, "bot ID is required")
+		return
+	}
+
+	tokens, err := s.auth.ListAPITokens(r.Context(), UserIDFromContext(r.Context()), botID)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if tokens == nil {
+		tokens = []*auth.APIToken{}
+	}
+	writeJSON(w, http.StatusOK, tokens)
+}
+
+// handleCreateAPIToken issues a scoped API token for a bot. The response is the
+// only time the token value is shown.
+// POST /api/v1/bots/{botID}/tokens
+// Body: { "name": "ci", "scopes": ["messages.read", "messages.send"] }
+// Complexity: O(1)
+func (s *Server) handleCreateAPIToken(w http.ResponseWriter, r *http.Request) {
+	if s.auth == nil {
+		writeError(w, http.StatusServiceUnavailable, "auth service not available")
+		return
+	}
+
+	botID := chi.URLParam(r, "botID")
+	if botID == "" {
+		writeError(w, http.StatusBadRequest, "bot ID is required")
+		return
+	}
+
+	var req createTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	token, err := s.auth.CreateAPIToken(r.Context(), UserIDFromContext(r.Context()), botID, req.Name, req.Scopes)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, token)
+}
+
+// handleRevokeAPIToken revokes a token of a bot owned by the authenticated user.
+// DELETE /api/v1/bots/{botID}/tokens/{tokenID}
+// Complexity: O(1)
+func (s *Server) handleRevokeAPIToken(w http.ResponseWriter, r *http.Request) {
+	if s.auth == nil {
+		writeError(w, http.StatusServiceUnavailable, "auth service not available")
+		return
+	}
+
+	botID := chi.URLParam(r, "botID")
+	tokenID := chi.URLParam(r, "tokenID")
+	if botID == "" || tokenID == "" {
+		writeError(w, http.StatusBadRequest, "bot ID and token ID are required")
+		return
+	}
+
+	if err := s.auth.RevokeAPIToken(r.Context(), UserIDFromContext(r.Context()), botID, tokenID); err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleAddBotToServer adds a bot owned by the authenticated user to the server
+// of an invite code with an explicit role.
+// POST /api/v1/bots/{botID}/servers
+// Body: { "invite_code": "abc123", "role": "member" }
+// Complexity: O(1)
+func (s *Server) handleAddBotToServer(w http.ResponseWriter, r *http.Request) {
+	if s.auth == nil || s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "bot service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	botID := chi.URLParam(r, "botID")
+	if botID == "" {
+		writeError(w, http.StatusBadRequest, "bot ID is required")
+		return
+	}
+
+	var req addBotRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.InviteCode == "" {
+		writeError(w, http.StatusBadRequest, "invite code is required")
+		return
+	}
+	role := req.Role
+	if role == "" {
+		role = server.RoleMember
+	}
+
+	if _, err := s.auth.OwnedBot(r.Context(), userID, botID); err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	srv, err := s.servers.AddBotViaInvite(r.Context(), req.InviteCode, userID, botID, role)
+	if err != nil {
+		writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, srv)
+}
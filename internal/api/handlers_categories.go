@@ -0,0 +1,200 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/concord-chat/concord/internal/server"
+)
+
+// categoryRequest is the body for creating or renaming a channel category.
+type categoryRequest struct {
+	Name string `json:"name"`
+}
+
+// handleListCategories returns the channel categories of a server, ordered by position.
+// GET /api/v1/servers/{serverID}/categories
+// Complexity: O(k) where k = categories of the server
+func (s *Server) handleListCategories(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	if serverID == "" {
+		writeError(w, http.StatusBadRequest, "server ID is required")
+		return
+	}
+
+	member, err := s.servers.IsMember(r.Context(), serverID, userID)
+	if err != nil {
+		s.logger.Error().Err(err).Str("server_id", serverID).Msg("failed to check membership")
+		writeError(w, http.StatusInternalServerError, "failed to list categories")
+		return
+	}
+	if !member {
+		writeError(w, http.StatusForbidden, "not a member of this server")
+		return
+	}
+
+	categories, err := s.servers.ListCategories(r.Context(), serverID)
+	if err != nil {
+		s.logger.Error().Err(err).Str("server_id", serverID).Msg("failed to list categories")
+		writeError(w, http.StatusInternalServerError, "failed to list categories")
+		return
+	}
+	if categories == nil {
+		categories = []*server.Category{}
+	}
+	writeJSON(w, http.StatusOK, categories)
+}
+
+// handleCreateCategory creates a channel category after the server's last category.
+// POST /api/v1/servers/{serverID}/categories
+// Body: { "name": "Voice rooms" }
+// Requires PermManageChannels.
+// Complexity: O(k) where k = categories of the server
+func (s *Server) handleCreateCategory(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	if serverID == "" {
+		writeError(w, http.StatusBadRequest, "server ID is required")
+		return
+	}
+
+	var req categoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	c, err := s.servers.CreateCategory(r.Context(), serverID, userID, req.Name)
+	if err != nil {
+		writeChannelError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, c)
+}
+
+// handleRenameCategory renames a channel category.
+// PATCH /api/v1/servers/{serverID}/categories/{categoryID}
+// Body: { "name": "Voice rooms" }
+// Requires PermManageChannels.
+// Complexity: O(1)
+func (s *Server) handleRenameCategory(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	categoryID := chi.URLParam(r, "categoryID")
+	if serverID == "" || categoryID == "" {
+		writeError(w, http.StatusBadRequest, "server ID and category ID are required")
+		return
+	}
+
+	var req categoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	c, err := s.servers.RenameCategory(r.Context(), serverID, userID, categoryID, req.Name)
+	if err != nil {
+		writeChannelError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, c)
+}
+
+// handleDeleteCategory removes a channel category; its channels become uncategorized.
+// DELETE /api/v1/servers/{serverID}/categories/{categoryID}
+// Requires PermManageChannels.
+// Complexity: O(n) where n = channels of the server
+func (s *Server) handleDeleteCategory(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	categoryID := chi.URLParam(r, "categoryID")
+	if serverID == "" || categoryID == "" {
+		writeError(w, http.StatusBadRequest, "server ID and category ID are required")
+		return
+	}
+
+	if err := s.servers.DeleteCategory(r.Context(), serverID, userID, categoryID); err != nil {
+		writeChannelError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleReorderChannels replaces the order of all channels and categories of a server
+// in one transaction, moving channels between categories as listed.
+// PUT /api/v1/servers/{serverID}/channels/order
+// Body: { "uncategorized": ["..."], "categories": [{ "id": "...", "channels": ["..."] }] }
+// Requires PermManageChannels.
+// Complexity: O(n + k) where n = channels, k = categories of the server
+func (s *Server) handleReorderChannels(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	if serverID == "" {
+		writeError(w, http.StatusBadRequest, "server ID is required")
+		return
+	}
+
+	var layout server.ChannelLayout
+	if err := json.NewDecoder(r.Body).Decode(&layout); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := s.servers.ReorderChannels(r.Context(), serverID, userID, layout); err != nil {
+		writeChannelError(w, err)
+		return
+	}
+
+	channels, err := s.servers.VisibleChannels(r.Context(), serverID, userID)
+	if err != nil {
+		s.logger.Error().Err(err).Str("server_id", serverID).Msg("failed to list channels")
+		writeError(w, http.StatusInternalServerError, "failed to list channels")
+		return
+	}
+	if channels == nil {
+		channels = []*server.Channel{}
+	}
+	writeJSON(w, http.StatusOK, channels)
+}
+
+// writeChannelError maps channel, category and overwrite errors to HTTP statuses.
+func writeChannelError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, server.ErrChannelNotFound), errors.Is(err, server.ErrCategoryNotFound),
+		errors.Is(err, server.ErrRoleNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, server.ErrLayoutMismatch):
+		writeError(w, http.StatusConflict, err.Error())
+	default:
+		writeError(w, http.StatusForbidden, err.Error())
+	}
+}
@@ -2,12 +2,16 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/go-chi/chi/v5"
 
 	"github.com/concord-chat/concord/internal/chat"
+	"github.com/concord-chat/concord/internal/security"
+	"github.com/concord-chat/concord/internal/server"
+	"github.com/concord-chat/concord/internal/typing"
 )
 
 // sendMessageRequest is the expected body for POST /api/v1/channels/{channelID}/messages.
@@ -23,6 +27,7 @@ type editMessageRequest struct {
 // handleGetMessages retrieves messages for a channel with cursor-based pagination.
 // GET /api/v1/channels/{channelID}/messages
 // Query params: before, after, limit
+// Requires PermViewChannel in the channel.
 // Complexity: O(log n) — indexed query with LIMIT
 func (s *Server) handleGetMessages(w http.ResponseWriter, r *http.Request) {
 	if s.chat == nil {
@@ -35,6 +40,9 @@ func (s *Server) handleGetMessages(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "channel ID is required")
 		return
 	}
+	if !s.allowChannel(w, r, channelID, server.PermViewChannel) {
+		return
+	}
 
 	opts := chat.PaginationOpts{
 		Before: r.URL.Query().Get("before"),
@@ -60,6 +68,9 @@ func (s *Server) handleGetMessages(w http.ResponseWriter, r *http.Request) {
 	if messages == nil {
 		messages = []*chat.Message{}
 	}
+	s.stripEmbedsFor(r.Context(), UserIDFromContext(r.Context()), messages...)
+	chat.ApplyPollViewer(UserIDFromContext(r.Context()), messages...)
+	chat.ApplyReactionViewer(UserIDFromContext(r.Context()), messages...)
 
 	writeJSON(w, http.StatusOK, messages)
 }
@@ -67,6 +78,7 @@ func (s *Server) handleGetMessages(w http.ResponseWriter, r *http.Request) {
 // handleSendMessage creates a new message in a channel.
 // POST /api/v1/channels/{channelID}/messages
 // Body: { "content": "Hello!" }
+// Requires PermSendMessages in the channel.
 // Complexity: O(1) + O(log n) FTS index update
 func (s *Server) handleSendMessage(w http.ResponseWriter, r *http.Request) {
 	if s.chat == nil {
@@ -91,9 +103,17 @@ func (s *Server) handleSendMessage(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "message content is required")
 		return
 	}
+	if !s.allowChannel(w, r, channelID, server.PermSendMessages) {
+		return
+	}
 
 	msg, err := s.chat.SendMessage(r.Context(), channelID, userID, req.Content)
 	if err != nil {
+		var rlErr *security.RateLimitError
+		if errors.As(err, &rlErr) {
+			writeRateLimited(w, rlErr)
+			return
+		}
 		s.logger.Error().Err(err).
 			Str("channel_id", channelID).
 			Str("user_id", userID).
@@ -102,6 +122,7 @@ func (s *Server) handleSendMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	s.stopTyping(typing.ChannelScope(channelID), userID)
 	writeJSON(w, http.StatusCreated, msg)
 }
 
@@ -179,10 +200,10 @@ func (s *Server) handleDeleteMessage(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// handleSearchMessages performs full-text search within a channel.
+// handleSearchMessages searches within a channel.
 // GET /api/v1/channels/{channelID}/messages/search
 // Query: q (search query), limit (max results)
-// Complexity: O(log n) — FTS5 inverted index lookup
+// Complexity: O(log n) — full-text index lookup
 func (s *Server) handleSearchMessages(w http.ResponseWriter, r *http.Request) {
 	if s.chat == nil {
 		writeError(w, http.StatusServiceUnavailable, "chat service not available")
@@ -195,6 +216,44 @@ func (s *Server) handleSearchMessages(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	s.serveSearch(w, r, chat.SearchScope{ChannelID: channelID})
+}
+
+// handleSearchServerMessages searches every readable channel of a server.
+// GET /api/v1/servers/{serverID}/messages/search
+// Query: q (search query), limit (max results)
+// Complexity: O(log n) — full-text index lookup
+func (s *Server) handleSearchServerMessages(w http.ResponseWriter, r *http.Request) {
+	if s.chat == nil {
+		writeError(w, http.StatusServiceUnavailable, "chat service not available")
+		return
+	}
+
+	serverID := chi.URLParam(r, "serverID")
+	if serverID == "" {
+		writeError(w, http.StatusBadRequest, "server ID is required")
+		return
+	}
+
+	s.serveSearch(w, r, chat.SearchScope{ServerID: serverID})
+}
+
+// handleSearchAllMessages searches every server the user belongs to.
+// GET /api/v1/messages/search
+// Query: q (search query), limit (max results)
+// Complexity: O(log n) — full-text index lookup
+func (s *Server) handleSearchAllMessages(w http.ResponseWriter, r *http.Request) {
+	if s.chat == nil {
+		writeError(w, http.StatusServiceUnavailable, "chat service not available")
+		return
+	}
+
+	s.serveSearch(w, r, chat.SearchScope{})
+}
+
+// serveSearch validates the q/limit parameters and runs a scoped search.
+// Malformed queries map to 400, access failures to 403.
+func (s *Server) serveSearch(w http.ResponseWriter, r *http.Request, scope chat.SearchScope) {
 	query := r.URL.Query().Get("q")
 	if query == "" {
 		writeError(w, http.StatusBadRequest, "search query (q) is required")
@@ -211,13 +270,19 @@ func (s *Server) handleSearchMessages(w http.ResponseWriter, r *http.Request) {
 		limit = parsed
 	}
 
-	results, err := s.chat.SearchMessages(r.Context(), channelID, query, limit)
+	userID := UserIDFromContext(r.Context())
+	results, err := s.chat.Search(r.Context(), userID, scope, query, limit)
 	if err != nil {
-		s.logger.Error().Err(err).
-			Str("channel_id", channelID).
-			Str("query", query).
+		if errors.Is(err, chat.ErrInvalidQuery) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.logger.Warn().Err(err).
+			Str("user_id", userID).
+			Str("server_id", scope.ServerID).
+			Str("channel_id", scope.ChannelID).
 			Msg("failed to search messages")
-		writeError(w, http.StatusInternalServerError, "failed to search messages")
+		writeError(w, http.StatusForbidden, err.Error())
 		return
 	}
 
@@ -227,3 +292,23 @@ func (s *Server) handleSearchMessages(w http.ResponseWriter, r *http.Request) {
 
 	writeJSON(w, http.StatusOK, results)
 }
+
+// writeRateLimited writes a 429 response with a Retry-After header (in seconds).
+// Complexity: O(1)
+func writeRateLimited(w http.ResponseWriter, err *security.RateLimitError) {
+	w.Header().Set("Retry-After", strconv.Itoa(err.RetryAfterSeconds()))
+	writeError(w, http.StatusTooManyRequests, err.Error())
+}
+
+// allowChannel checks that the caller holds perm in a server channel, writing the
+// error response when they don't. Without a server service every channel is allowed.
+func (s *Server) allowChannel(w http.ResponseWriter, r *http.Request, channelID string, perm server.Permission) bool {
+	if s.servers == nil {
+		return true
+	}
+	if err := s.servers.CheckChannelPermission(r.Context(), channelID, UserIDFromContext(r.Context()), perm); err != nil {
+		writeChannelError(w, err)
+		return false
+	}
+	return true
+}
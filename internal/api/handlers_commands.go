@@ -0,0 +1,224 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/concord-chat/concord/internal/commands"
+)
+
+// SetCommands enables the slash command endpoints.
+func (s *Server) SetCommands(svc *commands.Service) {
+	s.commands = svc
+}
+
+// invokeCommandRequest is the body for invoking a slash command.
+type invokeCommandRequest struct {
+	Name    string                 `json:"name"`
+	Options map[string]interface{} `json:"options"`
+}
+
+// requireBot writes a 403 and returns false unless the request was made with a bot API token.
+func requireBot(w http.ResponseWriter, r *http.Request) bool {
+	if APITokenFromContext(r.Context()) == nil {
+		writeError(w, http.StatusForbidden, "only bots can use this endpoint")
+		return false
+	}
+	return true
+}
+
+// handleListCommands returns the slash commands of a server.
+// GET /api/v1/servers/{serverID}/commands
+// Complexity: O(n) where n = number of commands in the server
+func (s *Server) handleListCommands(w http.ResponseWriter, r *http.Request) {
+	if s.commands == nil {
+		writeError(w, http.StatusServiceUnavailable, "slash commands not available")
+		return
+	}
+
+	serverID := chi.URLParam(r, "serverID")
+	if serverID == "" {
+		writeError(w, http.StatusBadRequest, "server ID is required")
+		return
+	}
+
+	list, err := s.commands.ListCommands(r.Context(), serverID, UserIDFromContext(r.Context()))
+	if err != nil {
+		writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if list == nil {
+		list = []*commands.Command{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// handleRegisterCommand creates or updates a slash command of the calling bot.
+// The response to the first registration is the only time the signing secret is shown.
+// POST /api/v1/servers/{serverID}/commands
+// Body: { "name": "deploy", "description": "...", "options": [{ "name": "env", "type": "string", "required": true }], "callback_url": "https://..." }
+// Complexity: O(o) where o = number of options
+func (s *Server) handleRegisterCommand(w http.ResponseWriter, r *http.Request) {
+	if s.commands == nil {
+		writeError(w, http.StatusServiceUnavailable, "slash commands not available")
+		return
+	}
+	if !requireBot(w, r) {
+		return
+	}
+
+	serverID := chi.URLParam(r, "serverID")
+	if serverID == "" {
+		writeError(w, http.StatusBadRequest, "server ID is required")
+		return
+	}
+
+	var req commands.Command
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	cmd, err := s.commands.RegisterCommand(r.Context(), serverID, UserIDFromContext(r.Context()), req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, cmd)
+}
+
+// handleDeleteCommand removes a slash command. Allowed for the bot that
+// registered it and for members with the manage server permission.
+// DELETE /api/v1/servers/{serverID}/commands/{commandID}
+// Complexity: O(i) where i = number of interactions of the command
+func (s *Server) handleDeleteCommand(w http.ResponseWriter, r *http.Request) {
+	if s.commands == nil {
+		writeError(w, http.StatusServiceUnavailable, "slash commands not available")
+		return
+	}
+
+	serverID := chi.URLParam(r, "serverID")
+	commandID := chi.URLParam(r, "commandID")
+	if serverID == "" || commandID == "" {
+		writeError(w, http.StatusBadRequest, "server ID and command ID are required")
+		return
+	}
+
+	if err := s.commands.DeleteCommand(r.Context(), serverID, UserIDFromContext(r.Context()), commandID); err != nil {
+		writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleInvokeCommand runs a slash command in a channel.
+// POST /api/v1/channels/{channelID}/interactions
+// Body: { "name": "deploy", "options": { "env": "staging" } }
+// Complexity: O(o) where o = number of options, plus the bot callback when configured
+func (s *Server) handleInvokeCommand(w http.ResponseWriter, r *http.Request) {
+	if s.commands == nil {
+		writeError(w, http.StatusServiceUnavailable, "slash commands not available")
+		return
+	}
+
+	channelID := chi.URLParam(r, "channelID")
+	if channelID == "" {
+		writeError(w, http.StatusBadRequest, "channel ID is required")
+		return
+	}
+
+	var req invokeCommandRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	in, err := s.commands.Invoke(r.Context(), channelID, UserIDFromContext(r.Context()), req.Name, req.Options)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, in)
+}
+
+// handlePendingInteractions returns the interactions waiting for the calling bot.
+// GET /api/v1/interactions
+// Complexity: O(k) where k = size of the batch
+func (s *Server) handlePendingInteractions(w http.ResponseWriter, r *http.Request) {
+	if s.commands == nil {
+		writeError(w, http.StatusServiceUnavailable, "slash commands not available")
+		return
+	}
+	if !requireBot(w, r) {
+		return
+	}
+
+	pending, err := s.commands.PendingInteractions(r.Context(), UserIDFromContext(r.Context()))
+	if err != nil {
+		s.logger.Error().Err(err).Msg("failed to list interactions")
+		writeError(w, http.StatusInternalServerError, "failed to list interactions")
+		return
+	}
+	if pending == nil {
+		pending = []*commands.Interaction{}
+	}
+	writeJSON(w, http.StatusOK, pending)
+}
+
+// handleGetInteraction returns an interaction to its invoker or its bot.
+// GET /api/v1/interactions/{interactionID}
+// Complexity: O(1)
+func (s *Server) handleGetInteraction(w http.ResponseWriter, r *http.Request) {
+	if s.commands == nil {
+		writeError(w, http.StatusServiceUnavailable, "slash commands not available")
+		return
+	}
+
+	interactionID := chi.URLParam(r, "interactionID")
+	if interactionID == "" {
+		writeError(w, http.StatusBadRequest, "interaction ID is required")
+		return
+	}
+
+	in, err := s.commands.GetInteraction(r.Context(), UserIDFromContext(r.Context()), interactionID)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, in)
+}
+
+// handleRespondInteraction answers an interaction of the calling bot.
+// POST /api/v1/interactions/{interactionID}/response
+// Body: { "content": "Deployed!", "ephemeral": false }
+// Complexity: O(1)
+func (s *Server) handleRespondInteraction(w http.ResponseWriter, r *http.Request) {
+	if s.commands == nil {
+		writeError(w, http.StatusServiceUnavailable, "slash commands not available")
+		return
+	}
+	if !requireBot(w, r) {
+		return
+	}
+
+	interactionID := chi.URLParam(r, "interactionID")
+	if interactionID == "" {
+		writeError(w, http.StatusBadRequest, "interaction ID is required")
+		return
+	}
+
+	var req commands.Response
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	in, err := s.commands.Respond(r.Context(), UserIDFromContext(r.Context()), interactionID, req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, in)
+}
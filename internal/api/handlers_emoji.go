@@ -0,0 +1,221 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/concord-chat/concord/internal/chat"
+	"github.com/concord-chat/concord/internal/emoji"
+)
+
+// SetEmoji enables the custom emoji endpoints.
+func (s *Server) SetEmoji(svc *emoji.Service) {
+	s.emoji = svc
+}
+
+// renameEmojiRequest is the body for renaming a custom emoji.
+type renameEmojiRequest struct {
+	Name string `json:"name"`
+}
+
+// handleListEmoji returns the custom emoji of a server.
+// GET /api/v1/servers/{serverID}/emoji
+// Complexity: O(n log n) where n = number of emoji in the server
+func (s *Server) handleListEmoji(w http.ResponseWriter, r *http.Request) {
+	if s.emoji == nil {
+		writeError(w, http.StatusServiceUnavailable, "custom emoji not available")
+		return
+	}
+
+	serverID := chi.URLParam(r, "serverID")
+	if serverID == "" {
+		writeError(w, http.StatusBadRequest, "server ID is required")
+		return
+	}
+
+	list, err := s.emoji.List(r.Context(), serverID, UserIDFromContext(r.Context()))
+	if err != nil {
+		writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if list == nil {
+		list = []*emoji.Emoji{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// handleUploadEmoji adds a custom emoji to a server.
+// POST /api/v1/servers/{serverID}/emoji
+// Body: multipart/form-data with "name" and an "image" file (PNG, GIF or WebP, at most 256 KB)
+// Complexity: O(b) where b = image size
+func (s *Server) handleUploadEmoji(w http.ResponseWriter, r *http.Request) {
+	if s.emoji == nil {
+		writeError(w, http.StatusServiceUnavailable, "custom emoji not available")
+		return
+	}
+
+	serverID := chi.URLParam(r, "serverID")
+	if serverID == "" {
+		writeError(w, http.StatusBadRequest, "server ID is required")
+		return
+	}
+
+	if err := r.ParseMultipartForm(emoji.MaxImageSize * 2); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid multipart body")
+		return
+	}
+	file, header, err := r.FormFile("image")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "image file is required")
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, emoji.MaxImageSize+1))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "failed to read image")
+		return
+	}
+
+	e, err := s.emoji.Upload(r.Context(), serverID, UserIDFromContext(r.Context()), r.FormValue("name"), header.Filename, data)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, e)
+}
+
+// handleRenameEmoji changes the name of a custom emoji.
+// PATCH /api/v1/servers/{serverID}/emoji/{emojiID}
+// Body: { "name": "party_blob" }
+// Complexity: O(1)
+func (s *Server) handleRenameEmoji(w http.ResponseWriter, r *http.Request) {
+	if s.emoji == nil {
+		writeError(w, http.StatusServiceUnavailable, "custom emoji not available")
+		return
+	}
+
+	serverID := chi.URLParam(r, "serverID")
+	emojiID := chi.URLParam(r, "emojiID")
+	if serverID == "" || emojiID == "" {
+		writeError(w, http.StatusBadRequest, "server ID and emoji ID are required")
+		return
+	}
+
+	var req renameEmojiRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	e, err := s.emoji.Rename(r.Context(), serverID, UserIDFromContext(r.Context()), emojiID, req.Name)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, e)
+}
+
+// handleDeleteEmoji removes a custom emoji and the reactions that used it.
+// DELETE /api/v1/servers/{serverID}/emoji/{emojiID}
+// Complexity: O(r) where r = number of reactions with the emoji
+func (s *Server) handleDeleteEmoji(w http.ResponseWriter, r *http.Request) {
+	if s.emoji == nil {
+		writeError(w, http.StatusServiceUnavailable, "custom emoji not available")
+		return
+	}
+
+	serverID := chi.URLParam(r, "serverID")
+	emojiID := chi.URLParam(r, "emojiID")
+	if serverID == "" || emojiID == "" {
+		writeError(w, http.StatusBadRequest, "server ID and emoji ID are required")
+		return
+	}
+
+	if err := s.emoji.Delete(r.Context(), serverID, UserIDFromContext(r.Context()), emojiID); err != nil {
+		writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleEmojiImage serves the image of a custom emoji.
+// GET /api/v1/emoji/{emojiID}
+// Complexity: O(b) where b = image size
+func (s *Server) handleEmojiImage(w http.ResponseWriter, r *http.Request) {
+	if s.emoji == nil {
+		writeError(w, http.StatusServiceUnavailable, "custom emoji not available")
+		return
+	}
+
+	emojiID := chi.URLParam(r, "emojiID")
+	if emojiID == "" {
+		writeError(w, http.StatusBadRequest, "emoji ID is required")
+		return
+	}
+
+	e, rc, err := s.emoji.Image(r.Context(), emojiID)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "emoji not found")
+		return
+	}
+	defer rc.Close()
+
+	// Images never change under an ID; renames only affect the name.
+	w.Header().Set("Content-Type", e.MimeType)
+	w.Header().Set("Cache-Control", "private, max-age=86400")
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, rc); err != nil {
+		s.logger.Warn().Err(err).Str("emoji_id", emojiID).Msg("failed to send emoji image")
+	}
+}
+
+// handleAddReaction reacts to a message as the caller.
+// PUT /api/v1/messages/{messageID}/reactions/{emoji}
+// {emoji} is a URL-encoded Unicode emoji, or name:id for a custom emoji of the channel's server.
+// Complexity: O(r) where r = reactions on the message
+func (s *Server) handleAddReaction(w http.ResponseWriter, r *http.Request) {
+	s.changeReaction(w, r, s.chat.AddReaction)
+}
+
+// handleRemoveReaction removes the caller's reaction from a message.
+// DELETE /api/v1/messages/{messageID}/reactions/{emoji}
+// Complexity: O(r) where r = reactions on the message
+func (s *Server) handleRemoveReaction(w http.ResponseWriter, r *http.Request) {
+	s.changeReaction(w, r, s.chat.RemoveReaction)
+}
+
+// changeReaction runs a reaction change and returns the updated message.
+func (s *Server) changeReaction(w http.ResponseWriter, r *http.Request,
+	change func(ctx context.Context, messageID, userID, emoji string) (*chat.Message, error)) {
+	if s.chat == nil {
+		writeError(w, http.StatusServiceUnavailable, "chat service not available")
+		return
+	}
+
+	messageID := chi.URLParam(r, "messageID")
+	reaction, err := url.PathUnescape(chi.URLParam(r, "emoji"))
+	if messageID == "" || err != nil || reaction == "" {
+		writeError(w, http.StatusBadRequest, "message ID and emoji are required")
+		return
+	}
+
+	msg, err := change(r.Context(), messageID, UserIDFromContext(r.Context()), reaction)
+	if err != nil {
+		switch {
+		case errors.Is(err, chat.ErrSendForbidden):
+			writeError(w, http.StatusForbidden, err.Error())
+		case errors.Is(err, chat.ErrMessageNotFound):
+			writeError(w, http.StatusNotFound, err.Error())
+		default:
+			writeError(w, http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, msg)
+}
@@ -0,0 +1,176 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/concord-chat/concord/internal/webhooks"
+)
+
+// SetOutgoingWebhooks enables the webhook subscription endpoints.
+func (s *Server) SetOutgoingWebhooks(svc *webhooks.Service) {
+	s.eventHooks = svc
+}
+
+// subscriptionRequest is the body for creating or updating a webhook subscription.
+type subscriptionRequest struct {
+	URL     string   `json:"url"`
+	Events  []string `json:"events"`
+	Enabled *bool    `json:"enabled"`
+}
+
+// subscriptionParams reads the server and subscription IDs of a request.
+// On failure it writes the error response and returns ok=false.
+func (s *Server) subscriptionParams(w http.ResponseWriter, r *http.Request) (serverID, subscriptionID string, ok bool) {
+	if s.eventHooks == nil {
+		writeError(w, http.StatusServiceUnavailable, "webhook subscriptions not available")
+		return "", "", false
+	}
+	serverID = chi.URLParam(r, "serverID")
+	subscriptionID = chi.URLParam(r, "subscriptionID")
+	if serverID == "" || subscriptionID == "" {
+		writeError(w, http.StatusBadRequest, "server ID and subscription ID are required")
+		return "", "", false
+	}
+	return serverID, subscriptionID, true
+}
+
+// handleListSubscriptions returns the webhook subscriptions of a server (without secrets).
+// GET /api/v1/servers/{serverID}/webhook-subscriptions
+// Complexity: O(n) where n = number of subscriptions
+func (s *Server) handleListSubscriptions(w http.ResponseWriter, r *http.Request) {
+	if s.eventHooks == nil {
+		writeError(w, http.StatusServiceUnavailable, "webhook subscriptions not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	if serverID == "" {
+		writeError(w, http.StatusBadRequest, "server ID is required")
+		return
+	}
+
+	subs, err := s.eventHooks.ListSubscriptions(r.Context(), serverID, userID)
+	if err != nil {
+		writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if subs == nil {
+		subs = []*webhooks.Subscription{}
+	}
+	writeJSON(w, http.StatusOK, subs)
+}
+
+// handleCreateSubscription subscribes a URL to server events. The response is the
+// only time the signing secret is shown.
+// POST /api/v1/servers/{serverID}/webhook-subscriptions
+// Body: { "url": "https://...", "events": ["message.created", "member.joined"] }
+// Complexity: O(n) where n = number of subscriptions
+func (s *Server) handleCreateSubscription(w http.ResponseWriter, r *http.Request) {
+	if s.eventHooks == nil {
+		writeError(w, http.StatusServiceUnavailable, "webhook subscriptions not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	if serverID == "" {
+		writeError(w, http.StatusBadRequest, "server ID is required")
+		return
+	}
+
+	var req subscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	sub, err := s.eventHooks.CreateSubscription(r.Context(), serverID, userID, req.URL, req.Events)
+	if err != nil {
+		writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, sub)
+}
+
+// handleUpdateSubscription changes the URL, events or enabled state of a subscription.
+// Omitted fields keep their values; enabling a disabled subscription resets its failures.
+// PATCH /api/v1/servers/{serverID}/webhook-subscriptions/{subscriptionID}
+// Body: { "url": "https://...", "events": ["member.joined"], "enabled": true }
+// Complexity: O(1)
+func (s *Server) handleUpdateSubscription(w http.ResponseWriter, r *http.Request) {
+	serverID, subscriptionID, ok := s.subscriptionParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req subscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	sub, err := s.eventHooks.UpdateSubscription(r.Context(), serverID, userID, subscriptionID, req.URL, req.Events, req.Enabled)
+	if err != nil {
+		writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, sub)
+}
+
+// handleRotateSubscriptionSecret replaces the signing secret of a subscription.
+// POST /api/v1/servers/{serverID}/webhook-subscriptions/{subscriptionID}/secret
+// Complexity: O(1)
+func (s *Server) handleRotateSubscriptionSecret(w http.ResponseWriter, r *http.Request) {
+	serverID, subscriptionID, ok := s.subscriptionParams(w, r)
+	if !ok {
+		return
+	}
+
+	sub, err := s.eventHooks.RotateSecret(r.Context(), serverID, UserIDFromContext(r.Context()), subscriptionID)
+	if err != nil {
+		writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, sub)
+}
+
+// handleDeleteSubscription removes a subscription and its delivery log.
+// DELETE /api/v1/servers/{serverID}/webhook-subscriptions/{subscriptionID}
+// Complexity: O(d) where d = number of deliveries
+func (s *Server) handleDeleteSubscription(w http.ResponseWriter, r *http.Request) {
+	serverID, subscriptionID, ok := s.subscriptionParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := s.eventHooks.DeleteSubscription(r.Context(), serverID, UserIDFromContext(r.Context()), subscriptionID); err != nil {
+		writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleListDeliveries returns the recent delivery log of a subscription, newest first.
+// GET /api/v1/servers/{serverID}/webhook-subscriptions/{subscriptionID}/deliveries
+// Complexity: O(k) where k = size of the log page
+func (s *Server) handleListDeliveries(w http.ResponseWriter, r *http.Request) {
+	serverID, subscriptionID, ok := s.subscriptionParams(w, r)
+	if !ok {
+		return
+	}
+
+	deliveries, err := s.eventHooks.ListDeliveries(r.Context(), serverID, UserIDFromContext(r.Context()), subscriptionID)
+	if err != nil {
+		writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if deliveries == nil {
+		deliveries = []*webhooks.Delivery{}
+	}
+	writeJSON(w, http.StatusOK, deliveries)
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/concord-chat/concord/internal/export"
+)
+
+// SetExport enables the history export endpoints.
+func (s *Server) SetExport(svc *export.Service) {
+	s.export = svc
+}
+
+// handleExportChannel downloads the full history of a channel the caller can read.
+// GET /api/v1/servers/{serverID}/channels/{channelID}/export?format=json|html|markdown
+// Complexity: O(n) — streamed page by page
+func (s *Server) handleExportChannel(w http.ResponseWriter, r *http.Request) {
+	if s.export == nil || s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "export service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	channelID := chi.URLParam(r, "channelID")
+	if serverID == "" || channelID == "" {
+		writeError(w, http.StatusBadRequest, "server ID and channel ID are required")
+		return
+	}
+
+	format, err := export.ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	readable, err := s.servers.ReadableChannels(r.Context(), userID, serverID)
+	if err != nil {
+		writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	name, ok := readable[channelID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "channel not found")
+		return
+	}
+
+	meta := export.Meta{ID: channelID, Name: name, ServerID: serverID, ExportedBy: userID}
+	if srv, err := s.servers.GetServer(r.Context(), serverID); err == nil && srv != nil {
+		meta.ServerName = srv.Name
+	}
+
+	s.streamExport(w, r, format, meta, func(meta export.Meta) (int, error) {
+		return s.export.ExportChannel(r.Context(), w, format, meta)
+	})
+}
+
+// handleExportDirectMessages downloads the caller's conversation with another user.
+// Former friends can still export their own history.
+// GET /api/v1/friends/{friendID}/export?format=json|html|markdown
+// Complexity: O(n) — streamed page by page
+func (s *Server) handleExportDirectMessages(w http.ResponseWriter, r *http.Request) {
+	if s.export == nil {
+		writeError(w, http.StatusServiceUnavailable, "export service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+	friendID := chi.URLParam(r, "friendID")
+	if friendID == "" {
+		writeError(w, http.StatusBadRequest, "friend ID is required")
+		return
+	}
+
+	format, err := export.ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	meta := export.Meta{ID: friendID, ExportedBy: userID}
+	s.streamExport(w, r, format, meta, func(meta export.Meta) (int, error) {
+		return s.export.ExportDM(r.Context(), w, format, userID, meta)
+	})
+}
+
+// streamExport sets download headers and runs the export. Once the body has
+// started, failures can only be logged: the client sees a truncated file.
+func (s *Server) streamExport(w http.ResponseWriter, r *http.Request, format export.Format, meta export.Meta, run func(export.Meta) (int, error)) {
+	meta.ExportedAt = time.Now().UTC().Format(time.RFC3339)
+
+	// Large histories outlive the server's write timeout.
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", meta.Filename(format)))
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := run(meta); err != nil {
+		s.logger.Error().Err(err).
+			Str("id", meta.ID).
+			Str("user_id", meta.ExportedBy).
+			Msg("export interrupted")
+	}
+}
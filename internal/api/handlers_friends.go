@@ -8,6 +8,7 @@ import (
 	"github.com/go-chi/chi/v5"
 
 	"github.com/concord-chat/concord/internal/friends"
+	"github.com/concord-chat/concord/internal/typing"
 )
 
 // sendFriendRequestBody is the expected body for POST /api/v1/friends/request.
@@ -20,6 +21,12 @@ type sendDirectMessageBody struct {
 	Content string `json:"content"`
 }
 
+// ackDirectMessagesBody is the expected body for POST /api/v1/friends/{friendID}/messages/ack.
+type ackDirectMessagesBody struct {
+	MessageID string                `json:"message_id"` // acknowledge up to this message; empty = all
+	Status    friends.MessageStatus `json:"status"`     // "delivered" or "read"
+}
+
 // handleSendFriendRequest sends a friend request to a user by username.
 // POST /api/v1/friends/request
 // Body: { "username": "someone" }
@@ -294,5 +301,43 @@ func (s *Server) handleSendDirectMessage(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	s.stopTyping(typing.DMScope(userID, friendID), userID)
 	writeJSON(w, http.StatusCreated, msg)
 }
+
+// handleAckDirectMessages marks a friend's messages as delivered or read by the caller.
+// POST /api/v1/friends/{friendID}/messages/ack
+// Body: { "message_id": "...", "status": "read" }
+func (s *Server) handleAckDirectMessages(w http.ResponseWriter, r *http.Request) {
+	if s.friends == nil {
+		writeError(w, http.StatusServiceUnavailable, "friends service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	friendID := chi.URLParam(r, "friendID")
+	if friendID == "" {
+		writeError(w, http.StatusBadRequest, "friend ID is required")
+		return
+	}
+
+	var req ackDirectMessagesBody
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	updated, err := s.friends.AckDirectMessages(r.Context(), userID, friendID, req.MessageID, req.Status)
+	if err != nil {
+		s.logger.Error().Err(err).Str("user_id", userID).Str("friend_id", friendID).Msg("failed to acknowledge direct messages")
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]int64{"updated": updated})
+}
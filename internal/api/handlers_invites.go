@@ -0,0 +1,133 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/concord-chat/concord/internal/server"
+)
+
+// handleGetInviteInfo previews the server an invite code leads to without joining it.
+// GET /api/v1/invite/{code}
+// Public: the code itself is the credential.
+// Complexity: O(1)
+func (s *Server) handleGetInviteInfo(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	code := chi.URLParam(r, "code")
+	if code == "" {
+		writeError(w, http.StatusBadRequest, "invite code is required")
+		return
+	}
+
+	info, err := s.servers.GetInviteInfo(r.Context(), code)
+	if err != nil {
+		writeInviteError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+// handleListInvites returns the invites of a server that can still be used, newest first.
+// GET /api/v1/servers/{serverID}/invites
+// Requires PermManageMembers.
+// Complexity: O(i) where i = invites of the server
+func (s *Server) handleListInvites(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	if serverID == "" {
+		writeError(w, http.StatusBadRequest, "server ID is required")
+		return
+	}
+
+	invites, err := s.servers.ListInvites(r.Context(), serverID, userID)
+	if err != nil {
+		writeInviteError(w, err)
+		return
+	}
+	if invites == nil {
+		invites = []*server.Invite{}
+	}
+	writeJSON(w, http.StatusOK, invites)
+}
+
+// handleCreateInvite creates an invite to a server.
+// POST /api/v1/servers/{serverID}/invites
+// Body (optional): { "max_uses": 10, "max_age": 86400, "code": "my-server" }
+// Requires PermCreateInvite, and PermManageServer for a vanity code.
+// Complexity: O(i) where i = invites of the server
+func (s *Server) handleCreateInvite(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	if serverID == "" {
+		writeError(w, http.StatusBadRequest, "server ID is required")
+		return
+	}
+
+	var opts server.InviteOptions
+	if err := json.NewDecoder(r.Body).Decode(&opts); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	inv, err := s.servers.CreateInvite(r.Context(), serverID, userID, opts)
+	if err != nil {
+		writeInviteError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, inv)
+}
+
+// handleRevokeInvite deletes an invite of a server.
+// DELETE /api/v1/servers/{serverID}/invites/{code}
+// Members may revoke their own invites; others' require PermManageMembers.
+// Complexity: O(1)
+func (s *Server) handleRevokeInvite(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	code := chi.URLParam(r, "code")
+	if serverID == "" || code == "" {
+		writeError(w, http.StatusBadRequest, "server ID and invite code are required")
+		return
+	}
+
+	if err := s.servers.RevokeInvite(r.Context(), serverID, userID, code); err != nil {
+		writeInviteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeInviteError maps invite errors to HTTP statuses.
+func writeInviteError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, server.ErrInvalidInvite), errors.Is(err, server.ErrInviteNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, server.ErrInviteCodeTaken):
+		writeError(w, http.StatusConflict, err.Error())
+	default:
+		writeError(w, http.StatusForbidden, err.Error())
+	}
+}
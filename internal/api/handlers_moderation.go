@@ -0,0 +1,208 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/concord-chat/concord/internal/server"
+)
+
+// banRequest is the body for banning a user from a server.
+type banRequest struct {
+	Reason   string `json:"reason"`
+	Duration int    `json:"duration"` // seconds, 0 for permanent
+}
+
+// timeoutRequest is the body for timing out a member.
+type timeoutRequest struct {
+	Duration int `json:"duration"` // seconds
+}
+
+// handleListBans returns the bans of a server still in effect, newest first.
+// GET /api/v1/servers/{serverID}/bans
+// Requires PermManageMembers.
+// Complexity: O(b) where b = bans of the server
+func (s *Server) handleListBans(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	if serverID == "" {
+		writeError(w, http.StatusBadRequest, "server ID is required")
+		return
+	}
+
+	bans, err := s.servers.ListBans(r.Context(), serverID, userID)
+	if err != nil {
+		writeModerationError(w, err)
+		return
+	}
+	if bans == nil {
+		bans = []*server.Ban{}
+	}
+	writeJSON(w, http.StatusOK, bans)
+}
+
+// handleBanMember bans a user from a server, removing them if they are a member.
+// PUT /api/v1/servers/{serverID}/bans/{userID}
+// Body (optional): { "reason": "spam", "duration": 86400 }
+// Requires PermManageMembers and a higher role than the target.
+// Complexity: O(r + o) where r = roles, o = channel overwrites of the target
+func (s *Server) handleBanMember(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	actorID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	targetID := chi.URLParam(r, "userID")
+	if serverID == "" || targetID == "" {
+		writeError(w, http.StatusBadRequest, "server ID and user ID are required")
+		return
+	}
+
+	var req banRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	ban, err := s.servers.BanMember(r.Context(), serverID, actorID, targetID, req.Reason, req.Duration)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("server_id", serverID).
+			Str("target_id", targetID).
+			Msg("failed to ban member")
+		writeModerationError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, ban)
+}
+
+// handleUnbanMember lifts the ban of a user from a server.
+// DELETE /api/v1/servers/{serverID}/bans/{userID}
+// Requires PermManageMembers.
+// Complexity: O(1)
+func (s *Server) handleUnbanMember(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	actorID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	targetID := chi.URLParam(r, "userID")
+	if serverID == "" || targetID == "" {
+		writeError(w, http.StatusBadRequest, "server ID and user ID are required")
+		return
+	}
+
+	if err := s.servers.UnbanMember(r.Context(), serverID, actorID, targetID); err != nil {
+		writeModerationError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleListTimeouts returns the members of a server that are currently timed out.
+// GET /api/v1/servers/{serverID}/timeouts
+// Requires PermManageMembers.
+// Complexity: O(n) where n = members of the server
+func (s *Server) handleListTimeouts(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	if serverID == "" {
+		writeError(w, http.StatusBadRequest, "server ID is required")
+		return
+	}
+
+	members, err := s.servers.ListTimeouts(r.Context(), serverID, userID)
+	if err != nil {
+		writeModerationError(w, err)
+		return
+	}
+	if members == nil {
+		members = []*server.Member{}
+	}
+	writeJSON(w, http.StatusOK, members)
+}
+
+// handleTimeoutMember keeps a member from sending messages and speaking for a while.
+// PUT /api/v1/servers/{serverID}/timeouts/{userID}
+// Body: { "duration": 600 }
+// Requires PermManageMembers and a higher role than the target.
+// Complexity: O(r) where r = roles of the target
+func (s *Server) handleTimeoutMember(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	actorID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	targetID := chi.URLParam(r, "userID")
+	if serverID == "" || targetID == "" {
+		writeError(w, http.StatusBadRequest, "server ID and user ID are required")
+		return
+	}
+
+	var req timeoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	member, err := s.servers.TimeoutMember(r.Context(), serverID, actorID, targetID, req.Duration)
+	if err != nil {
+		writeModerationError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, member)
+}
+
+// handleRemoveTimeout ends the timeout of a member early.
+// DELETE /api/v1/servers/{serverID}/timeouts/{userID}
+// Requires PermManageMembers and a higher role than the target.
+// Complexity: O(r) where r = roles of the target
+func (s *Server) handleRemoveTimeout(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	actorID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	targetID := chi.URLParam(r, "userID")
+	if serverID == "" || targetID == "" {
+		writeError(w, http.StatusBadRequest, "server ID and user ID are required")
+		return
+	}
+
+	if err := s.servers.RemoveTimeout(r.Context(), serverID, actorID, targetID); err != nil {
+		writeModerationError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeModerationError maps ban and timeout errors to HTTP statuses.
+func writeModerationError(w http.ResponseWriter, err error) {
+	if errors.Is(err, server.ErrBanNotFound) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	writeError(w, http.StatusForbidden, err.Error())
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/concord-chat/concord/internal/server"
+)
+
+// overwriteRequest is the body for setting a channel permission overwrite.
+type overwriteRequest struct {
+	Allow server.Permission `json:"allow"`
+	Deny  server.Permission `json:"deny"`
+}
+
+// handleListOverwrites returns the permission overwrites of a channel.
+// GET /api/v1/servers/{serverID}/channels/{channelID}/overwrites
+// Requires PermManageChannels.
+// Complexity: O(o) where o = overwrites of the server
+func (s *Server) handleListOverwrites(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	channelID := chi.URLParam(r, "channelID")
+	if serverID == "" || channelID == "" {
+		writeError(w, http.StatusBadRequest, "server ID and channel ID are required")
+		return
+	}
+
+	overwrites, err := s.servers.ChannelOverwrites(r.Context(), serverID, userID, channelID)
+	if err != nil {
+		writeChannelError(w, err)
+		return
+	}
+	if overwrites == nil {
+		overwrites = []*server.Overwrite{}
+	}
+	writeJSON(w, http.StatusOK, overwrites)
+}
+
+// handleSetOverwrite allows and denies permissions in a channel for a role or a member.
+// PUT /api/v1/servers/{serverID}/channels/{channelID}/overwrites/{targetType}/{targetID}
+// Body: { "allow": "0", "deny": "512" }
+// targetType is "role" or "member". Requires PermManageChannels.
+// Complexity: O(r) where r = roles of the caller
+func (s *Server) handleSetOverwrite(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	o := server.Overwrite{
+		ChannelID:  chi.URLParam(r, "channelID"),
+		TargetType: chi.URLParam(r, "targetType"),
+		TargetID:   chi.URLParam(r, "targetID"),
+	}
+	if serverID == "" || o.ChannelID == "" || o.TargetID == "" {
+		writeError(w, http.StatusBadRequest, "server ID, channel ID and target ID are required")
+		return
+	}
+
+	var req overwriteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	o.Allow, o.Deny = req.Allow, req.Deny
+
+	saved, err := s.servers.SetChannelOverwrite(r.Context(), serverID, userID, o)
+	if err != nil {
+		writeChannelError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, saved)
+}
+
+// handleDeleteOverwrite removes the overwrite of a channel for a role or a member.
+// DELETE /api/v1/servers/{serverID}/channels/{channelID}/overwrites/{targetType}/{targetID}
+// Requires PermManageChannels.
+// Complexity: O(1)
+func (s *Server) handleDeleteOverwrite(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	channelID := chi.URLParam(r, "channelID")
+	targetType := chi.URLParam(r, "targetType")
+	targetID := chi.URLParam(r, "targetID")
+	if serverID == "" || channelID == "" || targetID == "" {
+		writeError(w, http.StatusBadRequest, "server ID, channel ID and target ID are required")
+		return
+	}
+
+	if err := s.servers.DeleteChannelOverwrite(r.Context(), serverID, userID, channelID, targetType, targetID); err != nil {
+		writeChannelError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
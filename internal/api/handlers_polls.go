@@ -0,0 +1,133 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/concord-chat/concord/internal/chat"
+	"github.com/concord-chat/concord/internal/security"
+	"github.com/concord-chat/concord/internal/typing"
+)
+
+// votePollRequest is the expected body for PUT /api/v1/messages/{messageID}/votes.
+type votePollRequest struct {
+	OptionIDs []string `json:"option_ids"`
+}
+
+// handleCreatePoll posts a poll in a channel.
+// POST /api/v1/channels/{channelID}/polls
+// Body: { "question": "Lunch?", "options": ["Pizza", "Sushi"], "multiple_choice": false,
+//
+//	"anonymous": false, "closes_at": "2026-03-01T12:00:00Z" }
+//
+// Complexity: O(k) where k = number of options
+func (s *Server) handleCreatePoll(w http.ResponseWriter, r *http.Request) {
+	if s.chat == nil {
+		writeError(w, http.StatusServiceUnavailable, "chat service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	channelID := chi.URLParam(r, "channelID")
+	if channelID == "" {
+		writeError(w, http.StatusBadRequest, "channel ID is required")
+		return
+	}
+
+	var req chat.PollInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	msg, err := s.chat.CreatePoll(r.Context(), channelID, userID, req)
+	if err != nil {
+		s.writePollError(w, err, "failed to create poll")
+		return
+	}
+
+	chat.ApplyPollViewer(userID, msg)
+	s.stopTyping(typing.ChannelScope(channelID), userID)
+	writeJSON(w, http.StatusCreated, msg)
+}
+
+// handleVotePoll replaces the caller's votes on a poll. An empty list retracts the vote.
+// PUT /api/v1/messages/{messageID}/votes
+// Body: { "option_ids": ["..."] }
+// Complexity: O(k + v) where k = options chosen, v = votes on the poll
+func (s *Server) handleVotePoll(w http.ResponseWriter, r *http.Request) {
+	if s.chat == nil {
+		writeError(w, http.StatusServiceUnavailable, "chat service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	messageID := chi.URLParam(r, "messageID")
+	if messageID == "" {
+		writeError(w, http.StatusBadRequest, "message ID is required")
+		return
+	}
+
+	var req votePollRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	msg, err := s.chat.VotePoll(r.Context(), messageID, userID, req.OptionIDs)
+	if err != nil {
+		s.writePollError(w, err, "failed to vote")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, msg)
+}
+
+// handleClosePoll ends a poll before its close time.
+// POST /api/v1/messages/{messageID}/poll/close
+// Query: ?is_manager=true (optional, close as a member with PermManageMessages)
+// Complexity: O(v) where v = votes on the poll
+func (s *Server) handleClosePoll(w http.ResponseWriter, r *http.Request) {
+	if s.chat == nil {
+		writeError(w, http.StatusServiceUnavailable, "chat service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	messageID := chi.URLParam(r, "messageID")
+	if messageID == "" {
+		writeError(w, http.StatusBadRequest, "message ID is required")
+		return
+	}
+
+	isManager := r.URL.Query().Get("is_manager") == "true"
+
+	msg, err := s.chat.ClosePoll(r.Context(), messageID, userID, isManager)
+	if err != nil {
+		s.writePollError(w, err, "failed to close poll")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, msg)
+}
+
+// writePollError maps poll service errors: rate limits to 429, missing
+// permissions to 403, unknown or hidden messages to 404 and everything else
+// (validation) to 400.
+func (s *Server) writePollError(w http.ResponseWriter, err error, msg string) {
+	var rlErr *security.RateLimitError
+	switch {
+	case errors.As(err, &rlErr):
+		writeRateLimited(w, rlErr)
+	case errors.Is(err, chat.ErrSendForbidden), errors.Is(err, chat.ErrManageForbidden):
+		writeError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, chat.ErrMessageNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	default:
+		s.logger.Warn().Err(err).Msg(msg)
+		writeError(w, http.StatusBadRequest, err.Error())
+	}
+}
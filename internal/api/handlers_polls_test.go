@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/concord-chat/concord/internal/cache"
+	"github.com/concord-chat/concord/internal/chat"
+	"github.com/concord-chat/concord/internal/config"
+	"github.com/concord-chat/concord/internal/observability"
+	"github.com/concord-chat/concord/internal/server"
+	"github.com/concord-chat/concord/internal/store/sqlite"
+)
+
+func TestClosePoll_VerifiesManagerFlag(t *testing.T) {
+	logger := zerolog.Nop()
+	db, err := sqlite.New(sqlite.Config{
+		Path:         filepath.Join(t.TempDir(), "test.db"),
+		MaxOpenConns: 1,
+		ForeignKeys:  true,
+		BusyTimeout:  5 * time.Second,
+	}, logger)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	ctx := context.Background()
+	require.NoError(t, sqlite.NewMigrator(db, logger).Migrate(ctx))
+
+	for _, stmt := range []string{
+		`INSERT INTO users (id, username) VALUES ('alice', 'alice'), ('bob', 'bob'), ('carol', 'carol')`,
+		`INSERT INTO servers (id, name, owner_id, invite_code) VALUES ('srv-1', 'Test', 'alice', 'inv-1')`,
+		`INSERT INTO server_members (server_id, user_id) VALUES ('srv-1', 'alice'), ('srv-1', 'bob')`,
+		`INSERT INTO channels (id, server_id, name, type) VALUES ('ch-1', 'srv-1', 'general', 'text')`,
+	} {
+		_, err := db.ExecContext(ctx, stmt)
+		require.NoError(t, err)
+	}
+
+	serverSvc := server.NewService(server.NewRepository(db, server.NewStdlibTransactor(db.Conn()), logger), cache.NewLRU(100), logger)
+	chatSvc := chat.NewService(chat.NewRepository(db, logger), logger)
+	chatSvc.SetSendPolicy(serverSvc)
+	chatSvc.SetModeration(serverSvc)
+
+	poll, err := chatSvc.CreatePoll(ctx, "ch-1", "alice", chat.PollInput{Question: "Ship it?", Options: []string{"Yes", "No"}})
+	require.NoError(t, err)
+
+	jwt := testJWTManager(t)
+	cfg := config.ServerConfig{Host: "127.0.0.1", ReadTimeout: 5 * time.Second, WriteTimeout: 5 * time.Second}
+	s := New(cfg, nil, serverSvc, chatSvc, nil, nil, jwt, nil, observability.NewHealthChecker(logger, "test"), nil, logger)
+
+	closePoll := func(userID string) int {
+		pair, err := jwt.GenerateTokenPair(userID, 1, userID)
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/messages/"+poll.ID+"/poll/close?is_manager=true", nil)
+		req.Header.Set("Authorization", "Bearer "+pair.AccessToken)
+		w := httptest.NewRecorder()
+		s.Handler().ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusForbidden, closePoll("bob"), "members without manage messages cannot claim to be managers")
+	assert.Equal(t, http.StatusNotFound, closePoll("carol"), "non-members cannot see the poll")
+	assert.Equal(t, http.StatusOK, closePoll("alice"))
+}
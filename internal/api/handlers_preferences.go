@@ -0,0 +1,76 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/concord-chat/concord/internal/chat"
+	"github.com/concord-chat/concord/internal/preferences"
+)
+
+// SetPreferences enables the user preferences endpoints and preference-aware responses.
+func (s *Server) SetPreferences(svc *preferences.Service) {
+	s.preferences = svc
+}
+
+// handleGetPreferences returns the authenticated user's preferences.
+// GET /api/v1/users/@me/preferences
+// Complexity: O(1) with cache
+func (s *Server) handleGetPreferences(w http.ResponseWriter, r *http.Request) {
+	if s.preferences == nil {
+		writeError(w, http.StatusServiceUnavailable, "preferences service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	prefs, err := s.preferences.Get(r.Context(), userID)
+	if err != nil {
+		s.logger.Error().Err(err).Str("user_id", userID).Msg("failed to get preferences")
+		writeError(w, http.StatusInternalServerError, "failed to get preferences")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, prefs)
+}
+
+// handleUpdatePreferences applies a partial update to the authenticated user's preferences.
+// PUT /api/v1/users/@me/preferences
+// Body: { "link_previews": false, "read_receipts": true }
+// Complexity: O(1)
+func (s *Server) handleUpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	if s.preferences == nil {
+		writeError(w, http.StatusServiceUnavailable, "preferences service not available")
+		return
+	}
+
+	var req preferences.Update
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	prefs, err := s.preferences.Update(r.Context(), userID, req)
+	if err != nil {
+		s.logger.Error().Err(err).Str("user_id", userID).Msg("failed to update preferences")
+		writeError(w, http.StatusInternalServerError, "failed to update preferences")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, prefs)
+}
+
+// stripEmbedsFor removes link previews from messages when the viewer disabled them,
+// so clients never load third-party preview images on their behalf.
+// Complexity: O(n)
+func (s *Server) stripEmbedsFor(ctx context.Context, userID string, msgs ...*chat.Message) {
+	if s.preferences == nil || userID == "" || s.preferences.LinkPreviewsEnabled(ctx, userID) {
+		return
+	}
+	for _, m := range msgs {
+		if m != nil {
+			m.Embeds = nil
+		}
+	}
+}
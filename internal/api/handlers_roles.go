@@ -0,0 +1,240 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/concord-chat/concord/internal/server"
+)
+
+// createRoleRequest is the body for creating a role.
+type createRoleRequest struct {
+	Name        string            `json:"name"`
+	Color       int               `json:"color"`
+	Permissions server.Permission `json:"permissions"`
+}
+
+// reorderRolesRequest is the body for reordering the roles of a server.
+type reorderRolesRequest struct {
+	Roles []string `json:"roles"`
+}
+
+// handleListRoles returns the roles of a server, highest first.
+// GET /api/v1/servers/{serverID}/roles
+// Complexity: O(r) where r = roles of the server
+func (s *Server) handleListRoles(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	if serverID == "" {
+		writeError(w, http.StatusBadRequest, "server ID is required")
+		return
+	}
+
+	member, err := s.servers.IsMember(r.Context(), serverID, userID)
+	if err != nil {
+		s.logger.Error().Err(err).Str("server_id", serverID).Msg("failed to check membership")
+		writeError(w, http.StatusInternalServerError, "failed to list roles")
+		return
+	}
+	if !member {
+		writeError(w, http.StatusForbidden, "not a member of this server")
+		return
+	}
+
+	s.writeRoles(w, r, serverID)
+}
+
+// handleCreateRole creates a role just above the default role.
+// POST /api/v1/servers/{serverID}/roles
+// Body: { "name": "Helpers", "color": 3066993, "permissions": "48" }
+// Requires PermManageRoles; the role cannot grant permissions the caller lacks.
+// Complexity: O(r) where r = roles of the server
+func (s *Server) handleCreateRole(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	if serverID == "" {
+		writeError(w, http.StatusBadRequest, "server ID is required")
+		return
+	}
+
+	var req createRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	role, err := s.servers.CreateRole(r.Context(), serverID, userID, req.Name, req.Color, req.Permissions)
+	if err != nil {
+		writeRoleError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, role)
+}
+
+// handleUpdateRole renames a role or changes its color or permissions.
+// PATCH /api/v1/servers/{serverID}/roles/{roleID}
+// Body: { "name": "Helpers", "color": 3066993, "permissions": "48" } (all optional)
+// Requires PermManageRoles and a highest role above the role.
+// Complexity: O(r) where r = roles of the server
+func (s *Server) handleUpdateRole(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	roleID := chi.URLParam(r, "roleID")
+	if serverID == "" || roleID == "" {
+		writeError(w, http.StatusBadRequest, "server ID and role ID are required")
+		return
+	}
+
+	var update server.RoleUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	role, err := s.servers.UpdateRole(r.Context(), serverID, userID, roleID, update)
+	if err != nil {
+		writeRoleError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, role)
+}
+
+// handleDeleteRole removes a role and unassigns it from every member.
+// DELETE /api/v1/servers/{serverID}/roles/{roleID}
+// Requires PermManageRoles and a highest role above the role.
+// Complexity: O(r + a) where r = roles of the server, a = assignments of the role
+func (s *Server) handleDeleteRole(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	roleID := chi.URLParam(r, "roleID")
+	if serverID == "" || roleID == "" {
+		writeError(w, http.StatusBadRequest, "server ID and role ID are required")
+		return
+	}
+
+	if err := s.servers.DeleteRole(r.Context(), serverID, userID, roleID); err != nil {
+		writeRoleError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleReorderRoles sets the order of every role of a server except the default role.
+// PUT /api/v1/servers/{serverID}/roles/order
+// Body: { "roles": ["<highest>", "...", "<lowest>"] }
+// Requires PermManageRoles; roles at or above the caller's highest role keep their positions.
+// Complexity: O(r) where r = roles of the server
+func (s *Server) handleReorderRoles(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	if serverID == "" {
+		writeError(w, http.StatusBadRequest, "server ID is required")
+		return
+	}
+
+	var req reorderRolesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := s.servers.ReorderRoles(r.Context(), serverID, userID, req.Roles); err != nil {
+		writeRoleError(w, err)
+		return
+	}
+	s.writeRoles(w, r, serverID)
+}
+
+// handleAddMemberRole assigns a role to a member.
+// PUT /api/v1/servers/{serverID}/members/{userID}/roles/{roleID}
+// Requires PermManageRoles; both the role and the member must rank below the caller.
+// Complexity: O(r) where r = roles of the server
+func (s *Server) handleAddMemberRole(w http.ResponseWriter, r *http.Request) {
+	s.handleMemberRole(w, r, s.servers.AddMemberRole)
+}
+
+// handleRemoveMemberRole unassigns a role from a member.
+// DELETE /api/v1/servers/{serverID}/members/{userID}/roles/{roleID}
+// Requires PermManageRoles; both the role and the member must rank below the caller.
+// Complexity: O(r) where r = roles of the server
+func (s *Server) handleRemoveMemberRole(w http.ResponseWriter, r *http.Request) {
+	s.handleMemberRole(w, r, s.servers.RemoveMemberRole)
+}
+
+// handleMemberRole runs a role assignment change for the member and role of the route.
+func (s *Server) handleMemberRole(w http.ResponseWriter, r *http.Request, change func(ctx context.Context, serverID, actorID, targetID, roleID string) error) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	actorID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	targetID := chi.URLParam(r, "userID")
+	roleID := chi.URLParam(r, "roleID")
+	if serverID == "" || targetID == "" || roleID == "" {
+		writeError(w, http.StatusBadRequest, "server ID, user ID and role ID are required")
+		return
+	}
+
+	if err := change(r.Context(), serverID, actorID, targetID, roleID); err != nil {
+		writeRoleError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeRoles writes the roles of a server, highest first.
+func (s *Server) writeRoles(w http.ResponseWriter, r *http.Request, serverID string) {
+	roles, err := s.servers.ListRoles(r.Context(), serverID)
+	if err != nil {
+		s.logger.Error().Err(err).Str("server_id", serverID).Msg("failed to list roles")
+		writeError(w, http.StatusInternalServerError, "failed to list roles")
+		return
+	}
+	if roles == nil {
+		roles = []*server.Role{}
+	}
+	writeJSON(w, http.StatusOK, roles)
+}
+
+// writeRoleError maps role errors to HTTP statuses.
+func writeRoleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, server.ErrRoleNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, server.ErrRoleOrderMismatch):
+		writeError(w, http.StatusConflict, err.Error())
+	default:
+		writeError(w, http.StatusForbidden, err.Error())
+	}
+}
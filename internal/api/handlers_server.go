@@ -2,6 +2,7 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -12,6 +13,10 @@ import (
 // createServerRequest is the expected body for POST /api/v1/servers.
 type createServerRequest struct {
 	Name string `json:"name"`
+	// Template is the code of a template to copy the structure of.
+	Template string `json:"template,omitempty"`
+	// Snapshot is a template snapshot document to copy the structure of.
+	Snapshot *server.TemplateSnapshot `json:"snapshot,omitempty"`
 }
 
 // createChannelRequest is the expected body for POST /api/v1/servers/{serverID}/channels.
@@ -26,6 +31,11 @@ type updateServerRequest struct {
 	IconURL string `json:"icon_url"`
 }
 
+// setSlowModeRequest is the expected body for PUT /api/v1/servers/{serverID}/channels/{channelID}/slow-mode.
+type setSlowModeRequest struct {
+	Seconds int `json:"seconds"` // 0 disables slow mode
+}
+
 // updateMemberRoleRequest is the expected body for PUT /api/v1/servers/{serverID}/members/{userID}/role.
 type updateMemberRoleRequest struct {
 	Role string `json:"role"` // "admin", "moderator", "member"
@@ -60,10 +70,12 @@ func (s *Server) handleListServers(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, servers)
 }
 
-// handleCreateServer creates a new server owned by the authenticated user.
+// handleCreateServer creates a new server owned by the authenticated user, with
+// the default channels or the structure of a template.
 // POST /api/v1/servers
-// Body: { "name": "My Server" }
-// Complexity: O(1) (DB insert + default channel creation)
+// Body: { "name": "My Server" }, optionally with "template": "code" or
+// "snapshot": {...}; the name then defaults to the template's.
+// Complexity: O(n) where n = roles + categories + channels created
 func (s *Server) handleCreateServer(w http.ResponseWriter, r *http.Request) {
 	if s.servers == nil {
 		writeError(w, http.StatusServiceUnavailable, "server service not available")
@@ -82,13 +94,32 @@ func (s *Server) handleCreateServer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.Name == "" {
+	var (
+		srv *server.Server
+		err error
+	)
+	switch {
+	case req.Template != "" && req.Snapshot != nil:
+		writeError(w, http.StatusBadRequest, "template and snapshot are mutually exclusive")
+		return
+	case req.Template != "":
+		srv, err = s.servers.CreateServerFromTemplate(r.Context(), req.Template, req.Name, userID)
+	case req.Snapshot != nil:
+		srv, err = s.servers.CreateServerFromSnapshot(r.Context(), req.Snapshot, req.Name, userID)
+	case req.Name == "":
 		writeError(w, http.StatusBadRequest, "server name is required")
 		return
+	default:
+		srv, err = s.servers.CreateServer(r.Context(), req.Name, userID)
 	}
-
-	srv, err := s.servers.CreateServer(r.Context(), req.Name, userID)
-	if err != nil {
+	switch {
+	case errors.Is(err, server.ErrTemplateNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, server.ErrInvalidTemplate):
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	case err != nil:
 		s.logger.Error().Err(err).Str("user_id", userID).Msg("failed to create server")
 		writeError(w, http.StatusInternalServerError, "failed to create server")
 		return
@@ -189,7 +220,7 @@ func (s *Server) handleDeleteServer(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// handleListChannels returns all channels for a server.
+// handleListChannels returns the channels of a server the caller can see.
 // GET /api/v1/servers/{serverID}/channels
 // Complexity: O(n) where n is the number of channels
 func (s *Server) handleListChannels(w http.ResponseWriter, r *http.Request) {
@@ -198,16 +229,16 @@ func (s *Server) handleListChannels(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	userID := UserIDFromContext(r.Context())
 	serverID := chi.URLParam(r, "serverID")
 	if serverID == "" {
 		writeError(w, http.StatusBadRequest, "server ID is required")
 		return
 	}
 
-	channels, err := s.servers.ListChannels(r.Context(), serverID)
+	channels, err := s.servers.VisibleChannels(r.Context(), serverID, userID)
 	if err != nil {
-		s.logger.Error().Err(err).Str("server_id", serverID).Msg("failed to list channels")
-		writeError(w, http.StatusInternalServerError, "failed to list channels")
+		writeError(w, http.StatusForbidden, err.Error())
 		return
 	}
 
@@ -260,6 +291,100 @@ func (s *Server) handleCreateChannel(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusCreated, ch)
 }
 
+// handleUpdateChannel renames a channel, changes its type or moves it to another category.
+// PATCH /api/v1/servers/{serverID}/channels/{channelID}
+// Body: { "name": "announcements", "type": "text", "category_id": "..." }
+// Requires PermManageChannels.
+// Complexity: O(n) where n = channels of the server
+func (s *Server) handleUpdateChannel(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	channelID := chi.URLParam(r, "channelID")
+	if serverID == "" || channelID == "" {
+		writeError(w, http.StatusBadRequest, "server ID and channel ID are required")
+		return
+	}
+
+	var update server.ChannelUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	ch, err := s.servers.UpdateChannel(r.Context(), serverID, userID, channelID, update)
+	if err != nil {
+		writeChannelError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, ch)
+}
+
+// handleDeleteChannel removes a channel from a server.
+// DELETE /api/v1/servers/{serverID}/channels/{channelID}
+// Requires PermManageChannels.
+// Complexity: O(n) where n = channels of the server
+func (s *Server) handleDeleteChannel(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	channelID := chi.URLParam(r, "channelID")
+	if serverID == "" || channelID == "" {
+		writeError(w, http.StatusBadRequest, "server ID and channel ID are required")
+		return
+	}
+
+	if err := s.servers.DeleteChannel(r.Context(), serverID, userID, channelID); err != nil {
+		writeChannelError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleSetSlowMode configures the per-user message interval for a channel.
+// PUT /api/v1/servers/{serverID}/channels/{channelID}/slow-mode
+// Body: { "seconds": 30 }
+// Complexity: O(1)
+func (s *Server) handleSetSlowMode(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	channelID := chi.URLParam(r, "channelID")
+	if serverID == "" || channelID == "" {
+		writeError(w, http.StatusBadRequest, "server ID and channel ID are required")
+		return
+	}
+
+	var req setSlowModeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := s.servers.SetSlowMode(r.Context(), serverID, userID, channelID, req.Seconds); err != nil {
+		s.logger.Error().Err(err).
+			Str("server_id", serverID).
+			Str("channel_id", channelID).
+			Msg("failed to set slow mode")
+		writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // handleListMembers returns all members of a server.
 // GET /api/v1/servers/{serverID}/members
 // Complexity: O(n) where n is the number of members
@@ -323,7 +448,7 @@ func (s *Server) handleKickMember(w http.ResponseWriter, r *http.Request) {
 // handleUpdateMemberRole changes a member's role in a server.
 // PUT /api/v1/servers/{serverID}/members/{userID}/role
 // Body: { "role": "admin" }
-// Requires PermManageMembers.
+// Assigns the matching preset role. Requires PermManageRoles.
 // Complexity: O(1)
 func (s *Server) handleUpdateMemberRole(w http.ResponseWriter, r *http.Request) {
 	if s.servers == nil {
@@ -346,7 +471,7 @@ func (s *Server) handleUpdateMemberRole(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	role := server.Role(req.Role)
+	role := req.Role
 	if role != server.RoleAdmin && role != server.RoleModerator && role != server.RoleMember {
 		writeError(w, http.StatusBadRequest, "role must be admin, moderator, or member")
 		return
@@ -410,6 +535,14 @@ func (s *Server) handleRedeemInvite(w http.ResponseWriter, r *http.Request) {
 
 	srv, err := s.servers.RedeemInvite(r.Context(), code, userID)
 	if err != nil {
+		switch {
+		case errors.Is(err, server.ErrInvalidInvite):
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		case errors.Is(err, server.ErrBanned):
+			writeError(w, http.StatusForbidden, err.Error())
+			return
+		}
 		s.logger.Error().Err(err).Str("code", code).Msg("failed to redeem invite")
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
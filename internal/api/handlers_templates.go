@@ -0,0 +1,161 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/concord-chat/concord/internal/server"
+)
+
+// templateRequest is the body for creating a server template.
+type templateRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// handleGetTemplate returns a template with its snapshot, e.g. to preview or export it.
+// GET /api/v1/templates/{code}
+// Any authenticated user: the code itself is the credential.
+// Complexity: O(1)
+func (s *Server) handleGetTemplate(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	code := chi.URLParam(r, "code")
+	if code == "" {
+		writeError(w, http.StatusBadRequest, "template code is required")
+		return
+	}
+
+	t, err := s.servers.GetTemplate(r.Context(), code)
+	if err != nil {
+		writeTemplateError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+// handleListTemplates returns the templates of a server, flagging those out of sync.
+// GET /api/v1/servers/{serverID}/templates
+// Requires PermManageServer.
+// Complexity: O(t + n) where t = templates, n = roles + channels + overwrites of the server
+func (s *Server) handleListTemplates(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	if serverID == "" {
+		writeError(w, http.StatusBadRequest, "server ID is required")
+		return
+	}
+
+	templates, err := s.servers.ListTemplates(r.Context(), serverID, userID)
+	if err != nil {
+		writeTemplateError(w, err)
+		return
+	}
+	if templates == nil {
+		templates = []*server.Template{}
+	}
+	writeJSON(w, http.StatusOK, templates)
+}
+
+// handleCreateTemplate captures the structure of a server in a new template.
+// POST /api/v1/servers/{serverID}/templates
+// Body: { "name": "Community", "description": "Our usual layout" }
+// Requires PermManageServer.
+// Complexity: O(n) where n = roles + channels + overwrites of the server
+func (s *Server) handleCreateTemplate(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	if serverID == "" {
+		writeError(w, http.StatusBadRequest, "server ID is required")
+		return
+	}
+
+	var req templateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	t, err := s.servers.CreateTemplate(r.Context(), serverID, userID, req.Name, req.Description)
+	if err != nil {
+		writeTemplateError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, t)
+}
+
+// handleSyncTemplate re-captures the structure of a server in one of its templates.
+// PUT /api/v1/servers/{serverID}/templates/{code}
+// Requires PermManageServer.
+// Complexity: O(n) where n = roles + channels + overwrites of the server
+func (s *Server) handleSyncTemplate(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	code := chi.URLParam(r, "code")
+	if serverID == "" || code == "" {
+		writeError(w, http.StatusBadRequest, "server ID and template code are required")
+		return
+	}
+
+	t, err := s.servers.SyncTemplate(r.Context(), serverID, userID, code)
+	if err != nil {
+		writeTemplateError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+// handleDeleteTemplate deletes a template of a server.
+// DELETE /api/v1/servers/{serverID}/templates/{code}
+// Requires PermManageServer.
+// Complexity: O(1)
+func (s *Server) handleDeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	code := chi.URLParam(r, "code")
+	if serverID == "" || code == "" {
+		writeError(w, http.StatusBadRequest, "server ID and template code are required")
+		return
+	}
+
+	if err := s.servers.DeleteTemplate(r.Context(), serverID, userID, code); err != nil {
+		writeTemplateError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeTemplateError maps template errors to HTTP statuses.
+func writeTemplateError(w http.ResponseWriter, err error) {
+	if errors.Is(err, server.ErrTemplateNotFound) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	writeError(w, http.StatusForbidden, err.Error())
+}
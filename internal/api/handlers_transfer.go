@@ -0,0 +1,139 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/concord-chat/concord/internal/server"
+)
+
+// transferRequest is the body for offering a server to a member.
+type transferRequest struct {
+	UserID string `json:"user_id"`
+}
+
+// handleGetTransfer returns the pending ownership transfer of a server.
+// GET /api/v1/servers/{serverID}/transfer
+// Only the owner and the target of the transfer can see it.
+// Complexity: O(1)
+func (s *Server) handleGetTransfer(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	if serverID == "" {
+		writeError(w, http.StatusBadRequest, "server ID is required")
+		return
+	}
+
+	t, err := s.servers.PendingOwnershipTransfer(r.Context(), serverID, userID)
+	if err != nil {
+		s.logger.Error().Err(err).Str("server_id", serverID).Msg("failed to get ownership transfer")
+		writeError(w, http.StatusInternalServerError, "failed to get ownership transfer")
+		return
+	}
+	if t == nil {
+		writeError(w, http.StatusNotFound, server.ErrTransferNotFound.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+// handleRequestTransfer offers a server to a member, replacing any pending offer.
+// POST /api/v1/servers/{serverID}/transfer
+// Body: { "user_id": "..." }
+// Only the owner can transfer ownership.
+// Complexity: O(1)
+func (s *Server) handleRequestTransfer(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	if serverID == "" {
+		writeError(w, http.StatusBadRequest, "server ID is required")
+		return
+	}
+
+	var req transferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.UserID == "" {
+		writeError(w, http.StatusBadRequest, "user_id is required")
+		return
+	}
+
+	t, err := s.servers.RequestOwnershipTransfer(r.Context(), serverID, userID, req.UserID)
+	if err != nil {
+		writeTransferError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, t)
+}
+
+// handleAcceptTransfer makes the caller the owner of a server offered to them.
+// POST /api/v1/servers/{serverID}/transfer/accept
+// Complexity: O(1)
+func (s *Server) handleAcceptTransfer(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	if serverID == "" {
+		writeError(w, http.StatusBadRequest, "server ID is required")
+		return
+	}
+
+	srv, err := s.servers.AcceptOwnershipTransfer(r.Context(), serverID, userID)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("server_id", serverID).
+			Str("user_id", userID).
+			Msg("failed to accept ownership transfer")
+		writeTransferError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, srv)
+}
+
+// handleCancelTransfer withdraws or declines the pending ownership transfer of a server.
+// DELETE /api/v1/servers/{serverID}/transfer
+// Either the owner or the target of the transfer can cancel it.
+// Complexity: O(1)
+func (s *Server) handleCancelTransfer(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	if serverID == "" {
+		writeError(w, http.StatusBadRequest, "server ID is required")
+		return
+	}
+
+	if err := s.servers.CancelOwnershipTransfer(r.Context(), serverID, userID); err != nil {
+		writeTransferError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeTransferError maps ownership transfer errors to HTTP statuses.
+func writeTransferError(w http.ResponseWriter, err error) {
+	if errors.Is(err, server.ErrTransferNotFound) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	writeError(w, http.StatusForbidden, err.Error())
+}
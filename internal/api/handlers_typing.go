@@ -0,0 +1,141 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/concord-chat/concord/internal/typing"
+)
+
+// SetTyping enables the typing indicator endpoints.
+func (s *Server) SetTyping(tracker *typing.Tracker) {
+	s.typing = tracker
+}
+
+// typingScopeFunc resolves and authorizes the typing scope of a request.
+// On failure it writes the error response and returns ok=false.
+type typingScopeFunc func(w http.ResponseWriter, r *http.Request) (scope, userID string, ok bool)
+
+// channelTypingScope authorizes the caller for a server text channel.
+func (s *Server) channelTypingScope(w http.ResponseWriter, r *http.Request) (string, string, bool) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return "", "", false
+	}
+
+	userID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	channelID := chi.URLParam(r, "channelID")
+	if serverID == "" || channelID == "" {
+		writeError(w, http.StatusBadRequest, "server ID and channel ID are required")
+		return "", "", false
+	}
+
+	readable, err := s.servers.ReadableChannels(r.Context(), userID, serverID)
+	if err != nil {
+		writeError(w, http.StatusForbidden, err.Error())
+		return "", "", false
+	}
+	if _, ok := readable[channelID]; !ok {
+		writeError(w, http.StatusNotFound, "channel not found")
+		return "", "", false
+	}
+	return typing.ChannelScope(channelID), userID, true
+}
+
+// dmTypingScope authorizes the caller for the conversation with one friend.
+func (s *Server) dmTypingScope(w http.ResponseWriter, r *http.Request) (string, string, bool) {
+	if s.friends == nil {
+		writeError(w, http.StatusServiceUnavailable, "friends service not available")
+		return "", "", false
+	}
+
+	userID := UserIDFromContext(r.Context())
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "user not authenticated")
+		return "", "", false
+	}
+
+	friendID := chi.URLParam(r, "friendID")
+	if friendID == "" {
+		writeError(w, http.StatusBadRequest, "friend ID is required")
+		return "", "", false
+	}
+
+	areFriends, err := s.friends.AreFriends(r.Context(), userID, friendID)
+	if err != nil {
+		s.logger.Error().Err(err).Str("user_id", userID).Str("friend_id", friendID).Msg("failed to check friendship")
+		writeError(w, http.StatusInternalServerError, "failed to check friendship")
+		return "", "", false
+	}
+	if !areFriends {
+		writeError(w, http.StatusForbidden, "you can only message friends")
+		return "", "", false
+	}
+	return typing.DMScope(userID, friendID), userID, true
+}
+
+// handleStartTyping marks the caller as typing. Clients refresh it every few
+// seconds while the user types; the indicator expires on its own otherwise.
+// POST /api/v1/servers/{serverID}/channels/{channelID}/typing
+// POST /api/v1/friends/{friendID}/typing
+// Complexity: O(1) plus the scope check
+func (s *Server) handleStartTyping(resolve typingScopeFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.typing == nil {
+			writeError(w, http.StatusServiceUnavailable, "typing service not available")
+			return
+		}
+		scope, userID, ok := resolve(w, r)
+		if !ok {
+			return
+		}
+		s.typing.Start(scope, userID)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// handleStopTyping clears the caller's typing indicator.
+// DELETE /api/v1/servers/{serverID}/channels/{channelID}/typing
+// DELETE /api/v1/friends/{friendID}/typing
+// Complexity: O(1) plus the scope check
+func (s *Server) handleStopTyping(resolve typingScopeFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.typing == nil {
+			writeError(w, http.StatusServiceUnavailable, "typing service not available")
+			return
+		}
+		scope, userID, ok := resolve(w, r)
+		if !ok {
+			return
+		}
+		s.typing.Stop(scope, userID)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// handleGetTyping lists the other users currently typing, oldest first.
+// GET /api/v1/servers/{serverID}/channels/{channelID}/typing
+// GET /api/v1/friends/{friendID}/typing
+// Complexity: O(k log k) where k = typers in the scope
+func (s *Server) handleGetTyping(resolve typingScopeFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.typing == nil {
+			writeError(w, http.StatusServiceUnavailable, "typing service not available")
+			return
+		}
+		scope, userID, ok := resolve(w, r)
+		if !ok {
+			return
+		}
+		writeJSON(w, http.StatusOK, s.typing.Active(scope, userID))
+	}
+}
+
+// stopTyping clears the author's indicator once a message is sent.
+func (s *Server) stopTyping(scope, userID string) {
+	if s.typing != nil && userID != "" {
+		s.typing.Stop(scope, userID)
+	}
+}
@@ -0,0 +1,231 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/concord-chat/concord/internal/chat"
+	"github.com/concord-chat/concord/internal/security"
+	"github.com/concord-chat/concord/internal/server"
+)
+
+// webhookRequest is the body for creating or updating an incoming webhook.
+type webhookRequest struct {
+	Name      string `json:"name"`
+	AvatarURL string `json:"avatar_url"`
+	ChannelID string `json:"channel_id"`
+}
+
+// executeWebhookRequest is the body posted to a webhook URL.
+// Username and avatar_url override the webhook's defaults for this message only.
+type executeWebhookRequest struct {
+	Content   string `json:"content"`
+	Username  string `json:"username"`
+	AvatarURL string `json:"avatar_url"`
+}
+
+// handleListWebhooks returns the incoming webhooks of a server (without tokens).
+// GET /api/v1/servers/{serverID}/webhooks
+// Complexity: O(n) where n = number of webhooks
+func (s *Server) handleListWebhooks(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	if serverID == "" {
+		writeError(w, http.StatusBadRequest, "server ID is required")
+		return
+	}
+
+	webhooks, err := s.servers.ListWebhooks(r.Context(), serverID, userID)
+	if err != nil {
+		writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if webhooks == nil {
+		webhooks = []*server.Webhook{}
+	}
+	writeJSON(w, http.StatusOK, webhooks)
+}
+
+// handleCreateWebhook creates an incoming webhook. The response is the only time the token is shown.
+// POST /api/v1/servers/{serverID}/webhooks
+// Body: { "channel_id": "...", "name": "CI", "avatar_url": "https://..." }
+// Complexity: O(1)
+func (s *Server) handleCreateWebhook(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	if serverID == "" {
+		writeError(w, http.StatusBadRequest, "server ID is required")
+		return
+	}
+
+	var req webhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.ChannelID == "" {
+		writeError(w, http.StatusBadRequest, "channel ID is required")
+		return
+	}
+
+	wh, err := s.servers.CreateWebhook(r.Context(), serverID, userID, req.ChannelID, req.Name, req.AvatarURL)
+	if err != nil {
+		writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, wh)
+}
+
+// handleUpdateWebhook renames a webhook, changes its avatar or moves it to another channel.
+// PUT /api/v1/servers/{serverID}/webhooks/{webhookID}
+// Body: { "name": "CI", "avatar_url": "", "channel_id": "..." }
+// Complexity: O(1)
+func (s *Server) handleUpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	webhookID := chi.URLParam(r, "webhookID")
+	if serverID == "" || webhookID == "" {
+		writeError(w, http.StatusBadRequest, "server ID and webhook ID are required")
+		return
+	}
+
+	var req webhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	wh, err := s.servers.UpdateWebhook(r.Context(), serverID, userID, webhookID, req.Name, req.AvatarURL, req.ChannelID)
+	if err != nil {
+		writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, wh)
+}
+
+// handleRegenerateWebhookToken replaces a webhook's token; the old URL stops working.
+// POST /api/v1/servers/{serverID}/webhooks/{webhookID}/token
+// Complexity: O(1)
+func (s *Server) handleRegenerateWebhookToken(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	webhookID := chi.URLParam(r, "webhookID")
+	if serverID == "" || webhookID == "" {
+		writeError(w, http.StatusBadRequest, "server ID and webhook ID are required")
+		return
+	}
+
+	wh, err := s.servers.RegenerateWebhookToken(r.Context(), serverID, userID, webhookID)
+	if err != nil {
+		writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, wh)
+}
+
+// handleDeleteWebhook removes a webhook. Messages it posted are kept.
+// DELETE /api/v1/servers/{serverID}/webhooks/{webhookID}
+// Complexity: O(1)
+func (s *Server) handleDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil {
+		writeError(w, http.StatusServiceUnavailable, "server service not available")
+		return
+	}
+
+	userID := UserIDFromContext(r.Context())
+	serverID := chi.URLParam(r, "serverID")
+	webhookID := chi.URLParam(r, "webhookID")
+	if serverID == "" || webhookID == "" {
+		writeError(w, http.StatusBadRequest, "server ID and webhook ID are required")
+		return
+	}
+
+	if err := s.servers.DeleteWebhook(r.Context(), serverID, userID, webhookID); err != nil {
+		writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleExecuteWebhook posts a message through an incoming webhook. It is public:
+// the token in the URL is the credential.
+// POST /api/v1/webhooks/{webhookID}/{token}
+// Body: { "content": "Build #42 passed", "username": "CI", "avatar_url": "https://..." }
+// Complexity: O(1) + O(log n) FTS index update
+func (s *Server) handleExecuteWebhook(w http.ResponseWriter, r *http.Request) {
+	if s.servers == nil || s.chat == nil {
+		writeError(w, http.StatusServiceUnavailable, "webhooks not available")
+		return
+	}
+
+	wh, err := s.servers.AuthenticateWebhook(r.Context(), chi.URLParam(r, "webhookID"), chi.URLParam(r, "token"))
+	if err != nil {
+		if errors.Is(err, server.ErrInvalidWebhook) {
+			writeError(w, http.StatusUnauthorized, "invalid webhook token")
+			return
+		}
+		s.logger.Error().Err(err).Msg("failed to authenticate webhook")
+		writeError(w, http.StatusInternalServerError, "failed to authenticate webhook")
+		return
+	}
+
+	var req executeWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Content == "" {
+		writeError(w, http.StatusBadRequest, "message content is required")
+		return
+	}
+	name, avatarURL, err := server.ValidateWebhookOverride(req.Username, req.AvatarURL)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if name == "" {
+		name = wh.Name
+	}
+	if avatarURL == "" {
+		avatarURL = wh.AvatarURL
+	}
+
+	msg, err := s.chat.PostWebhookMessage(r.Context(), wh.ChannelID, wh.ID, wh.CreatedBy, name, avatarURL, req.Content)
+	if err != nil {
+		var rlErr *security.RateLimitError
+		switch {
+		case errors.As(err, &rlErr):
+			writeRateLimited(w, rlErr)
+		case errors.Is(err, chat.ErrInvalidMessage):
+			writeError(w, http.StatusBadRequest, err.Error())
+		default:
+			s.logger.Error().Err(err).Str("webhook_id", wh.ID).Msg("failed to post webhook message")
+			writeError(w, http.StatusInternalServerError, "failed to post message")
+		}
+		return
+	}
+	writeJSON(w, http.StatusCreated, msg)
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -9,6 +10,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/rs/zerolog"
 
 	"github.com/concord-chat/concord/internal/auth"
@@ -20,7 +22,10 @@ import (
 // contextKey is a private type used for context value keys to prevent collisions.
 type contextKey string
 
-const userIDKey contextKey = "user_id"
+const (
+	userIDKey   contextKey = "user_id"
+	apiTokenKey contextKey = "api_token"
+)
 
 // UserIDFromContext extracts the authenticated user ID from the request context.
 // Returns an empty string if no user ID is present.
@@ -30,11 +35,22 @@ func UserIDFromContext(ctx context.Context) string {
 	return v
 }
 
-// AuthMiddleware validates the Bearer JWT token from the Authorization header
-// and injects the user_id into the request context.
+// APITokenFromContext returns the API token the request was authenticated with,
+// or nil for JWT-authenticated requests. A non-nil token means the caller is a bot.
+func APITokenFromContext(ctx context.Context) *auth.APIToken {
+	v, _ := ctx.Value(apiTokenKey).(*auth.APIToken)
+	return v
+}
+
+// AuthMiddleware validates the Bearer token from the Authorization header and
+// injects the user_id into the request context. The token is either a JWT or,
+// when authSvc is set, a bot API token (auth.APITokenPrefix). API tokens only
+// reach the routes listed in tokenRouteScopes and need the scope listed there,
+// so the middleware must be installed on a chi Group whose routes are already
+// matched when it runs.
 // Requests without a valid token receive a 401 Unauthorized response.
-// Complexity: O(1) per request (JWT validation is constant time)
-func AuthMiddleware(jwtManager *auth.JWTManager) func(http.Handler) http.Handler {
+// Complexity: O(1) per request (JWT validation is constant time; API tokens cost one indexed lookup)
+func AuthMiddleware(jwtManager *auth.JWTManager, authSvc *auth.Service) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			header := r.Header.Get("Authorization")
@@ -49,6 +65,31 @@ func AuthMiddleware(jwtManager *auth.JWTManager) func(http.Handler) http.Handler
 				return
 			}
 
+			if authSvc != nil && strings.HasPrefix(parts[1], auth.APITokenPrefix) {
+				token, err := authSvc.AuthenticateAPIToken(r.Context(), parts[1])
+				if errors.Is(err, auth.ErrInvalidAPIToken) {
+					writeError(w, http.StatusUnauthorized, "invalid or revoked API token")
+					return
+				}
+				if err != nil {
+					writeError(w, http.StatusInternalServerError, "failed to authenticate API token")
+					return
+				}
+				scope, ok := tokenRouteScopes[r.Method+" "+routePattern(r)]
+				if !ok {
+					writeError(w, http.StatusForbidden, "this endpoint is not available to API tokens")
+					return
+				}
+				if !token.HasScope(scope) {
+					writeError(w, http.StatusForbidden, "API token lacks the "+scope+" scope")
+					return
+				}
+				ctx := context.WithValue(r.Context(), userIDKey, token.UserID)
+				ctx = context.WithValue(ctx, apiTokenKey, token)
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
 			claims, err := jwtManager.ValidateToken(parts[1])
 			if err != nil {
 				writeError(w, http.StatusUnauthorized, "invalid or expired token")
@@ -61,6 +102,59 @@ func AuthMiddleware(jwtManager *auth.JWTManager) func(http.Handler) http.Handler
 	}
 }
 
+// tokenRouteScopes lists the routes API tokens may call and the scope each one
+// needs, keyed by method and chi route pattern. Routes not listed here are
+// closed to API tokens: account, friend, invite and administration endpoints
+// stay JWT-only. Server permissions still apply on top of the scope.
+var tokenRouteScopes = map[string]string{
+	"GET /api/v1/servers":                                                                       auth.ScopeMessagesRead,
+	"GET /api/v1/servers/{serverID}":                                                            auth.ScopeMessagesRead,
+	"GET /api/v1/servers/{serverID}/channels":                                                   auth.ScopeMessagesRead,
+	"GET /api/v1/servers/{serverID}/members":                                                    auth.ScopeMessagesRead,
+	"GET /api/v1/servers/{serverID}/roles":                                                      auth.ScopeMessagesRead,
+	"GET /api/v1/channels/{channelID}/messages":                                                 auth.ScopeMessagesRead,
+	"GET /api/v1/channels/{channelID}/messages/search":                                          auth.ScopeMessagesRead,
+	"GET /api/v1/servers/{serverID}/messages/search":                                            auth.ScopeMessagesRead,
+	"GET /api/v1/messages/search":                                                               auth.ScopeMessagesRead,
+	"POST /api/v1/channels/{channelID}/messages":                                                auth.ScopeMessagesSend,
+	"PUT /api/v1/messages/{messageID}":                                                          auth.ScopeMessagesSend,
+	"DELETE /api/v1/messages/{messageID}":                                                       auth.ScopeMessagesSend,
+	"POST /api/v1/channels/{channelID}/polls":                                                   auth.ScopeMessagesSend,
+	"PUT /api/v1/messages/{messageID}/votes":                                                    auth.ScopeMessagesSend,
+	"POST /api/v1/messages/{messageID}/poll/close":                                              auth.ScopeMessagesSend,
+	"PUT /api/v1/messages/{messageID}/reactions/{emoji}":                                        auth.ScopeMessagesSend,
+	"DELETE /api/v1/messages/{messageID}/reactions/{emoji}":                                     auth.ScopeMessagesSend,
+	"GET /api/v1/servers/{serverID}/emoji":                                                      auth.ScopeMessagesRead,
+	"GET /api/v1/emoji/{emojiID}":                                                               auth.ScopeMessagesRead,
+	"POST /api/v1/servers/{serverID}/channels":                                                  auth.ScopeChannelsManage,
+	"PUT /api/v1/servers/{serverID}/channels/{channelID}/slow-mode":                             auth.ScopeChannelsManage,
+	"PUT /api/v1/servers/{serverID}/channels/order":                                             auth.ScopeChannelsManage,
+	"PATCH /api/v1/servers/{serverID}/channels/{channelID}":                                     auth.ScopeChannelsManage,
+	"DELETE /api/v1/servers/{serverID}/channels/{channelID}":                                    auth.ScopeChannelsManage,
+	"GET /api/v1/servers/{serverID}/categories":                                                 auth.ScopeMessagesRead,
+	"POST /api/v1/servers/{serverID}/categories":                                                auth.ScopeChannelsManage,
+	"PATCH /api/v1/servers/{serverID}/categories/{categoryID}":                                  auth.ScopeChannelsManage,
+	"DELETE /api/v1/servers/{serverID}/categories/{categoryID}":                                 auth.ScopeChannelsManage,
+	"GET /api/v1/servers/{serverID}/channels/{channelID}/overwrites":                            auth.ScopeChannelsManage,
+	"PUT /api/v1/servers/{serverID}/channels/{channelID}/overwrites/{targetType}/{targetID}":    auth.ScopeChannelsManage,
+	"DELETE /api/v1/servers/{serverID}/channels/{channelID}/overwrites/{targetType}/{targetID}": auth.ScopeChannelsManage,
+	"GET /api/v1/servers/{serverID}/commands":                                                   auth.ScopeCommands,
+	"POST /api/v1/servers/{serverID}/commands":                                                  auth.ScopeCommands,
+	"DELETE /api/v1/servers/{serverID}/commands/{commandID}":                                    auth.ScopeCommands,
+	"GET /api/v1/interactions":                                                                  auth.ScopeCommands,
+	"GET /api/v1/interactions/{interactionID}":                                                  auth.ScopeCommands,
+	"POST /api/v1/interactions/{interactionID}/response":                                        auth.ScopeCommands,
+}
+
+// routePattern returns the chi pattern of the matched route, or "" outside a chi router.
+func routePattern(r *http.Request) string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil {
+		return ""
+	}
+	return rctx.RoutePattern()
+}
+
 // PresenceMiddleware updates the in-memory presence tracker for authenticated users.
 // Should run after AuthMiddleware.
 // Complexity: O(1) per request.
@@ -134,7 +228,7 @@ func RequestLogger(logger zerolog.Logger) func(http.Handler) http.Handler {
 
 			logger.Info().
 				Str("method", r.Method).
-				Str("path", r.URL.Path).
+				Str("path", redactPath(r.URL.Path)).
 				Int("status", ww.statusCode).
 				Dur("duration_ms", duration).
 				Str("remote_addr", r.RemoteAddr).
@@ -144,6 +238,20 @@ func RequestLogger(logger zerolog.Logger) func(http.Handler) http.Handler {
 	}
 }
 
+// redactPath hides the secret token of incoming webhook URLs
+// (/api/v1/webhooks/{id}/{token}) so it never reaches the logs.
+func redactPath(path string) string {
+	const prefix = "/api/v1/webhooks/"
+	if !strings.HasPrefix(path, prefix) {
+		return path
+	}
+	rest := path[len(prefix):]
+	if i := strings.IndexByte(rest, '/'); i >= 0 {
+		return prefix + rest[:i] + "/[redacted]"
+	}
+	return path
+}
+
 // responseWriter wraps http.ResponseWriter to capture the status code.
 type responseWriter struct {
 	http.ResponseWriter
@@ -166,6 +274,11 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 	return rw.ResponseWriter.Write(b)
 }
 
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // SecurityHeaders adds standard security headers to every response.
 // Complexity: O(1) per request
 func SecurityHeaders() func(http.Handler) http.Handler {
@@ -310,6 +423,11 @@ func (w *metricsResponseWriter) Write(b []byte) (int, error) {
 	return n, err
 }
 
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (w *metricsResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 // normalizePath replaces dynamic path segments with placeholders
 // to prevent Prometheus label cardinality explosion.
 func normalizePath(path string) string {
@@ -330,8 +448,13 @@ func normalizePath(path string) string {
 func isStaticSegment(s string) bool {
 	switch s {
 	case "api", "v1", "auth", "servers", "channels", "members",
-		"messages", "invite", "health", "metrics", "device-code",
-		"token", "refresh", "search", "role":
+		"messages", "invite", "invites", "health", "metrics", "device-code",
+		"token", "refresh", "search", "role", "slow-mode",
+		"users", "@me", "preferences", "webhooks",
+		"webhook-subscriptions", "deliveries", "commands",
+		"interactions", "response", "reactions", "bookmarks",
+		"categories", "roles", "overwrites", "audit-log",
+		"bans", "timeouts", "transfer", "templates":
 		return true
 	}
 	return false
@@ -13,14 +13,21 @@ import (
 	"github.com/rs/zerolog"
 
 	"github.com/concord-chat/concord/internal/auth"
+	"github.com/concord-chat/concord/internal/bookmarks"
 	"github.com/concord-chat/concord/internal/chat"
+	"github.com/concord-chat/concord/internal/commands"
 	"github.com/concord-chat/concord/internal/config"
+	"github.com/concord-chat/concord/internal/emoji"
+	"github.com/concord-chat/concord/internal/export"
 	"github.com/concord-chat/concord/internal/friends"
 	"github.com/concord-chat/concord/internal/network/signaling"
 	"github.com/concord-chat/concord/internal/observability"
+	"github.com/concord-chat/concord/internal/preferences"
 	"github.com/concord-chat/concord/internal/presence"
 	"github.com/concord-chat/concord/internal/server"
+	"github.com/concord-chat/concord/internal/typing"
 	"github.com/concord-chat/concord/internal/voice"
+	"github.com/concord-chat/concord/internal/webhooks"
 )
 
 // Server is the central HTTP API server for Concord.
@@ -35,6 +42,13 @@ type Server struct {
 	signaling   *signaling.Server
 	iceProvider *voice.ICECredentialsProvider
 	presence    *presence.Tracker
+	preferences *preferences.Service
+	typing      *typing.Tracker
+	export      *export.Service
+	eventHooks  *webhooks.Service
+	commands    *commands.Service
+	emoji       *emoji.Service
+	bookmarks   *bookmarks.Service
 	jwt         *auth.JWTManager
 	health      *observability.HealthChecker
 	metrics     *observability.Metrics
@@ -128,10 +142,16 @@ func New(
 			ar.Post("/refresh", s.handleRefresh)
 		})
 
-		// Protected routes — require valid JWT
+		// Incoming webhooks (public — the token in the URL is the credential)
+		api.Post("/webhooks/{webhookID}/{token}", s.handleExecuteWebhook)
+
+		// Invite previews (public — the code is the credential)
+		api.Get("/invite/{code}", s.handleGetInviteInfo)
+
+		// Protected routes — require a valid JWT, or an API token for the routes in tokenRouteScopes
 		api.Group(func(protected chi.Router) {
 			if jwtManager != nil {
-				protected.Use(AuthMiddleware(jwtManager))
+				protected.Use(AuthMiddleware(jwtManager, authSvc))
 			}
 			if s.presence != nil {
 				protected.Use(PresenceMiddleware(s.presence))
@@ -147,14 +167,98 @@ func New(
 			// Channels (nested under servers)
 			protected.Get("/servers/{serverID}/channels", s.handleListChannels)
 			protected.Post("/servers/{serverID}/channels", s.handleCreateChannel)
+			protected.Put("/servers/{serverID}/channels/order", s.handleReorderChannels)
+			protected.Patch("/servers/{serverID}/channels/{channelID}", s.handleUpdateChannel)
+			protected.Delete("/servers/{serverID}/channels/{channelID}", s.handleDeleteChannel)
+			protected.Put("/servers/{serverID}/channels/{channelID}/slow-mode", s.handleSetSlowMode)
+			protected.Get("/servers/{serverID}/channels/{channelID}/typing", s.handleGetTyping(s.channelTypingScope))
+			protected.Post("/servers/{serverID}/channels/{channelID}/typing", s.handleStartTyping(s.channelTypingScope))
+			protected.Delete("/servers/{serverID}/channels/{channelID}/typing", s.handleStopTyping(s.channelTypingScope))
+			protected.Get("/servers/{serverID}/channels/{channelID}/export", s.handleExportChannel)
+			protected.Get("/servers/{serverID}/channels/{channelID}/overwrites", s.handleListOverwrites)
+			protected.Put("/servers/{serverID}/channels/{channelID}/overwrites/{targetType}/{targetID}", s.handleSetOverwrite)
+			protected.Delete("/servers/{serverID}/channels/{channelID}/overwrites/{targetType}/{targetID}", s.handleDeleteOverwrite)
+
+			// Channel categories (nested under servers)
+			protected.Get("/servers/{serverID}/categories", s.handleListCategories)
+			protected.Post("/servers/{serverID}/categories", s.handleCreateCategory)
+			protected.Patch("/servers/{serverID}/categories/{categoryID}", s.handleRenameCategory)
+			protected.Delete("/servers/{serverID}/categories/{categoryID}", s.handleDeleteCategory)
+
+			// Audit log (nested under servers)
+			protected.Get("/servers/{serverID}/audit-log", s.handleListAuditLog)
+
+			// Incoming webhooks (nested under servers)
+			protected.Get("/servers/{serverID}/webhooks", s.handleListWebhooks)
+			protected.Post("/servers/{serverID}/webhooks", s.handleCreateWebhook)
+			protected.Put("/servers/{serverID}/webhooks/{webhookID}", s.handleUpdateWebhook)
+			protected.Delete("/servers/{serverID}/webhooks/{webhookID}", s.handleDeleteWebhook)
+			protected.Post("/servers/{serverID}/webhooks/{webhookID}/token", s.handleRegenerateWebhookToken)
+
+			// Outgoing webhook subscriptions (nested under servers)
+			protected.Get("/servers/{serverID}/webhook-subscriptions", s.handleListSubscriptions)
+			protected.Post("/servers/{serverID}/webhook-subscriptions", s.handleCreateSubscription)
+			protected.Patch("/servers/{serverID}/webhook-subscriptions/{subscriptionID}", s.handleUpdateSubscription)
+			protected.Delete("/servers/{serverID}/webhook-subscriptions/{subscriptionID}", s.handleDeleteSubscription)
+			protected.Post("/servers/{serverID}/webhook-subscriptions/{subscriptionID}/secret", s.handleRotateSubscriptionSecret)
+			protected.Get("/servers/{serverID}/webhook-subscriptions/{subscriptionID}/deliveries", s.handleListDeliveries)
+
+			// Slash commands
+			protected.Get("/servers/{serverID}/commands", s.handleListCommands)
+			protected.Post("/servers/{serverID}/commands", s.handleRegisterCommand)
+			protected.Delete("/servers/{serverID}/commands/{commandID}", s.handleDeleteCommand)
+			protected.Post("/channels/{channelID}/interactions", s.handleInvokeCommand)
+			protected.Get("/interactions", s.handlePendingInteractions)
+			protected.Get("/interactions/{interactionID}", s.handleGetInteraction)
+			protected.Post("/interactions/{interactionID}/response", s.handleRespondInteraction)
+
+			// Custom emoji
+			protected.Get("/servers/{serverID}/emoji", s.handleListEmoji)
+			protected.Post("/servers/{serverID}/emoji", s.handleUploadEmoji)
+			protected.Patch("/servers/{serverID}/emoji/{emojiID}", s.handleRenameEmoji)
+			protected.Delete("/servers/{serverID}/emoji/{emojiID}", s.handleDeleteEmoji)
+			protected.Get("/emoji/{emojiID}", s.handleEmojiImage)
 
 			// Members (nested under servers)
 			protected.Get("/servers/{serverID}/members", s.handleListMembers)
 			protected.Delete("/servers/{serverID}/members/{userID}", s.handleKickMember)
 			protected.Put("/servers/{serverID}/members/{userID}/role", s.handleUpdateMemberRole)
+			protected.Put("/servers/{serverID}/members/{userID}/roles/{roleID}", s.handleAddMemberRole)
+			protected.Delete("/servers/{serverID}/members/{userID}/roles/{roleID}", s.handleRemoveMemberRole)
+
+			// Bans and timeouts (nested under servers)
+			protected.Get("/servers/{serverID}/bans", s.handleListBans)
+			protected.Put("/servers/{serverID}/bans/{userID}", s.handleBanMember)
+			protected.Delete("/servers/{serverID}/bans/{userID}", s.handleUnbanMember)
+			protected.Get("/servers/{serverID}/timeouts", s.handleListTimeouts)
+			protected.Put("/servers/{serverID}/timeouts/{userID}", s.handleTimeoutMember)
+			protected.Delete("/servers/{serverID}/timeouts/{userID}", s.handleRemoveTimeout)
+
+			// Ownership transfer (nested under servers)
+			protected.Get("/servers/{serverID}/transfer", s.handleGetTransfer)
+			protected.Post("/servers/{serverID}/transfer", s.handleRequestTransfer)
+			protected.Delete("/servers/{serverID}/transfer", s.handleCancelTransfer)
+			protected.Post("/servers/{serverID}/transfer/accept", s.handleAcceptTransfer)
+
+			// Templates
+			protected.Get("/servers/{serverID}/templates", s.handleListTemplates)
+			protected.Post("/servers/{serverID}/templates", s.handleCreateTemplate)
+			protected.Put("/servers/{serverID}/templates/{code}", s.handleSyncTemplate)
+			protected.Delete("/servers/{serverID}/templates/{code}", s.handleDeleteTemplate)
+			protected.Get("/templates/{code}", s.handleGetTemplate)
+
+			// Roles
+			protected.Get("/servers/{serverID}/roles", s.handleListRoles)
+			protected.Post("/servers/{serverID}/roles", s.handleCreateRole)
+			protected.Put("/servers/{serverID}/roles/order", s.handleReorderRoles)
+			protected.Patch("/servers/{serverID}/roles/{roleID}", s.handleUpdateRole)
+			protected.Delete("/servers/{serverID}/roles/{roleID}", s.handleDeleteRole)
 
 			// Invites
 			protected.Post("/servers/{serverID}/invite", s.handleGenerateInvite)
+			protected.Get("/servers/{serverID}/invites", s.handleListInvites)
+			protected.Post("/servers/{serverID}/invites", s.handleCreateInvite)
+			protected.Delete("/servers/{serverID}/invites/{code}", s.handleRevokeInvite)
 			protected.Post("/invite/{code}/redeem", s.handleRedeemInvite)
 
 			// Messages
@@ -163,6 +267,20 @@ func New(
 			protected.Put("/messages/{messageID}", s.handleEditMessage)
 			protected.Delete("/messages/{messageID}", s.handleDeleteMessage)
 			protected.Get("/channels/{channelID}/messages/search", s.handleSearchMessages)
+			protected.Get("/servers/{serverID}/messages/search", s.handleSearchServerMessages)
+			protected.Get("/messages/search", s.handleSearchAllMessages)
+			protected.Post("/channels/{channelID}/polls", s.handleCreatePoll)
+			protected.Put("/messages/{messageID}/votes", s.handleVotePoll)
+			protected.Post("/messages/{messageID}/poll/close", s.handleClosePoll)
+			protected.Put("/messages/{messageID}/reactions/{emoji}", s.handleAddReaction)
+			protected.Delete("/messages/{messageID}/reactions/{emoji}", s.handleRemoveReaction)
+
+			// Bookmarks
+			protected.Get("/bookmarks", s.handleListBookmarks)
+			protected.Post("/bookmarks", s.handleCreateBookmark)
+			protected.Get("/bookmarks/folders", s.handleListBookmarkFolders)
+			protected.Patch("/bookmarks/{bookmarkID}", s.handleUpdateBookmark)
+			protected.Delete("/bookmarks/{bookmarkID}", s.handleDeleteBookmark)
 
 			// Voice
 			protected.Get("/servers/{serverID}/channels/{channelID}/voice/participants", s.handleVoiceParticipants)
@@ -172,6 +290,18 @@ func New(
 			// Presence
 			protected.Post("/presence/offline", s.handleSetPresenceOffline)
 
+			// Bots and API tokens (JWT only)
+			protected.Get("/bots", s.handleListBots)
+			protected.Post("/bots", s.handleCreateBot)
+			protected.Get("/bots/{botID}/tokens", s.handleListAPITokens)
+			protected.Post("/bots/{botID}/tokens", s.handleCreateAPIToken)
+			protected.Delete("/bots/{botID}/tokens/{tokenID}", s.handleRevokeAPIToken)
+			protected.Post("/bots/{botID}/servers", s.handleAddBotToServer)
+
+			// User preferences
+			protected.Get("/users/@me/preferences", s.handleGetPreferences)
+			protected.Put("/users/@me/preferences", s.handleUpdatePreferences)
+
 			// Friends
 			protected.Post("/friends/request", s.handleSendFriendRequest)
 			protected.Get("/friends/requests", s.handleGetPendingRequests)
@@ -180,6 +310,11 @@ func New(
 			protected.Get("/friends", s.handleGetFriends)
 			protected.Get("/friends/{friendID}/messages", s.handleGetDirectMessages)
 			protected.Post("/friends/{friendID}/messages", s.handleSendDirectMessage)
+			protected.Post("/friends/{friendID}/messages/ack", s.handleAckDirectMessages)
+			protected.Get("/friends/{friendID}/typing", s.handleGetTyping(s.dmTypingScope))
+			protected.Post("/friends/{friendID}/typing", s.handleStartTyping(s.dmTypingScope))
+			protected.Delete("/friends/{friendID}/typing", s.handleStopTyping(s.dmTypingScope))
+			protected.Get("/friends/{friendID}/export", s.handleExportDirectMessages)
 			protected.Delete("/friends/{friendID}", s.handleRemoveFriend)
 			protected.Post("/friends/{friendID}/block", s.handleBlockUser)
 			protected.Delete("/friends/{friendID}/block", s.handleUnblockUser)
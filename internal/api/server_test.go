@@ -1,9 +1,11 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -13,8 +15,12 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/concord-chat/concord/internal/auth"
+	"github.com/concord-chat/concord/internal/cache"
 	"github.com/concord-chat/concord/internal/config"
 	"github.com/concord-chat/concord/internal/observability"
+	"github.com/concord-chat/concord/internal/server"
+	"github.com/concord-chat/concord/internal/store/sqlite"
+	"github.com/concord-chat/concord/internal/typing"
 )
 
 // testServer creates a test API server with default config and nil services.
@@ -410,6 +416,108 @@ func TestSearchMessages_NilService(t *testing.T) {
 	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
 }
 
+func TestCreatePoll_NilService(t *testing.T) {
+	s := testServer(t, nil)
+	body := `{"question":"Lunch?","options":["Pizza","Sushi"]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/channels/ch-1/polls", strings.NewReader(body))
+	w := httptest.NewRecorder()
+
+	s.Handler().ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestVotePoll_NilService(t *testing.T) {
+	s := testServer(t, nil)
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/messages/msg-1/votes", strings.NewReader(`{"option_ids":["opt-1"]}`))
+	w := httptest.NewRecorder()
+
+	s.Handler().ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestClosePoll_NilService(t *testing.T) {
+	s := testServer(t, nil)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/messages/msg-1/poll/close", nil)
+	w := httptest.NewRecorder()
+
+	s.Handler().ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestExportChannel_NilService(t *testing.T) {
+	s := testServer(t, nil)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/servers/server-1/channels/channel-1/export?format=html", nil)
+	w := httptest.NewRecorder()
+
+	s.Handler().ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestExportDirectMessages_NilService(t *testing.T) {
+	s := testServer(t, nil)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/friends/friend-1/export", nil)
+	w := httptest.NewRecorder()
+
+	s.Handler().ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+// --- Webhook handlers ---
+
+func TestCreateWebhook_NilService(t *testing.T) {
+	s := testServer(t, nil)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/servers/srv-1/webhooks", strings.NewReader(`{"channel_id":"ch-1","name":"CI"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	s.Handler().ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestExecuteWebhook_NilService(t *testing.T) {
+	s := testServer(t, nil)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/wh-1/secret", strings.NewReader(`{"content":"hi"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	s.Handler().ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestRedactPath_HidesWebhookToken(t *testing.T) {
+	assert.Equal(t, "/api/v1/webhooks/wh-1/[redacted]", redactPath("/api/v1/webhooks/wh-1/secret-token"))
+	assert.Equal(t, "/api/v1/servers/srv-1/webhooks", redactPath("/api/v1/servers/srv-1/webhooks"))
+}
+
+func TestCreateWebhookSubscription_NilService(t *testing.T) {
+	s := testServer(t, nil)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/servers/srv-1/webhook-subscriptions",
+		strings.NewReader(`{"url":"https://example.com/hook","events":["member.joined"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	s.Handler().ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestListWebhookDeliveries_NilService(t *testing.T) {
+	s := testServer(t, nil)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/servers/srv-1/webhook-subscriptions/sub-1/deliveries", nil)
+	w := httptest.NewRecorder()
+
+	s.Handler().ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
 // --- Member handlers ---
 
 func TestListMembers_NilService(t *testing.T) {
@@ -444,6 +552,137 @@ func TestRedeemInvite_NilService(t *testing.T) {
 	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
 }
 
+func TestModeration_NilService(t *testing.T) {
+	s := testServer(t, nil)
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/api/v1/servers/srv-1/bans", nil),
+		httptest.NewRequest(http.MethodPut, "/api/v1/servers/srv-1/bans/user-1", strings.NewReader(`{"reason":"spam","duration":86400}`)),
+		httptest.NewRequest(http.MethodDelete, "/api/v1/servers/srv-1/bans/user-1", nil),
+		httptest.NewRequest(http.MethodGet, "/api/v1/servers/srv-1/timeouts", nil),
+		httptest.NewRequest(http.MethodPut, "/api/v1/servers/srv-1/timeouts/user-1", strings.NewReader(`{"duration":600}`)),
+		httptest.NewRequest(http.MethodDelete, "/api/v1/servers/srv-1/timeouts/user-1", nil),
+	} {
+		w := httptest.NewRecorder()
+		s.Handler().ServeHTTP(w, req)
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code, req.Method+" "+req.URL.Path)
+	}
+}
+
+func TestOwnershipTransfer_NilService(t *testing.T) {
+	s := testServer(t, nil)
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/api/v1/servers/srv-1/transfer", nil),
+		httptest.NewRequest(http.MethodPost, "/api/v1/servers/srv-1/transfer", strings.NewReader(`{"user_id":"user-1"}`)),
+		httptest.NewRequest(http.MethodDelete, "/api/v1/servers/srv-1/transfer", nil),
+		httptest.NewRequest(http.MethodPost, "/api/v1/servers/srv-1/transfer/accept", nil),
+	} {
+		w := httptest.NewRecorder()
+		s.Handler().ServeHTTP(w, req)
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code, req.Method+" "+req.URL.Path)
+	}
+}
+
+func TestTemplates_NilService(t *testing.T) {
+	s := testServer(t, nil)
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/api/v1/templates/abc123", nil),
+		httptest.NewRequest(http.MethodGet, "/api/v1/servers/srv-1/templates", nil),
+		httptest.NewRequest(http.MethodPost, "/api/v1/servers/srv-1/templates", strings.NewReader(`{"name":"Community"}`)),
+		httptest.NewRequest(http.MethodPut, "/api/v1/servers/srv-1/templates/abc123", nil),
+		httptest.NewRequest(http.MethodDelete, "/api/v1/servers/srv-1/templates/abc123", nil),
+		httptest.NewRequest(http.MethodPost, "/api/v1/servers", strings.NewReader(`{"template":"abc123"}`)),
+	} {
+		w := httptest.NewRecorder()
+		s.Handler().ServeHTTP(w, req)
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code, req.Method+" "+req.URL.Path)
+	}
+}
+
+func TestInvites_NilService(t *testing.T) {
+	s := testServer(t, nil)
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/api/v1/invite/abc123", nil),
+		httptest.NewRequest(http.MethodGet, "/api/v1/servers/srv-1/invites", nil),
+		httptest.NewRequest(http.MethodPost, "/api/v1/servers/srv-1/invites", strings.NewReader(`{"max_uses":5,"max_age":3600}`)),
+		httptest.NewRequest(http.MethodDelete, "/api/v1/servers/srv-1/invites/abc123", nil),
+	} {
+		w := httptest.NewRecorder()
+		s.Handler().ServeHTTP(w, req)
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code, req.Method+" "+req.URL.Path)
+	}
+}
+
+// --- Friend handlers ---
+
+func TestAckDirectMessages_NilService(t *testing.T) {
+	s := testServer(t, nil)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/friends/friend-1/messages/ack", strings.NewReader(`{"status":"read"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	s.Handler().ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+// --- Typing handlers ---
+
+func TestStartChannelTyping_NilService(t *testing.T) {
+	s := testServer(t, nil)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/servers/server-1/channels/channel-1/typing", nil)
+	w := httptest.NewRecorder()
+
+	s.Handler().ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestGetDMTyping_NilService(t *testing.T) {
+	s := testServer(t, nil)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/friends/friend-1/typing", nil)
+	w := httptest.NewRecorder()
+
+	s.Handler().ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestStopChannelTyping_NilServerService(t *testing.T) {
+	s := testServer(t, nil)
+	tracker := typing.NewTracker(0, 0)
+	t.Cleanup(tracker.Close)
+	s.SetTyping(tracker)
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/servers/server-1/channels/channel-1/typing", nil)
+	w := httptest.NewRecorder()
+
+	s.Handler().ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+// --- Preferences handlers ---
+
+func TestGetPreferences_NilService(t *testing.T) {
+	s := testServer(t, nil)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/@me/preferences", nil)
+	w := httptest.NewRecorder()
+
+	s.Handler().ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestUpdatePreferences_NilService(t *testing.T) {
+	s := testServer(t, nil)
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/users/@me/preferences", strings.NewReader(`{"link_previews":false}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	s.Handler().ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
 // ============================================================================
 // Validation tests: verify that bad inputs are rejected before hitting services.
 // ============================================================================
@@ -480,3 +719,151 @@ func TestSearchMessages_InvalidLimit_NilService(t *testing.T) {
 	// Nil check precedes validation
 	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
 }
+
+func TestCreateBot_NilService(t *testing.T) {
+	s := testServer(t, nil)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/bots", strings.NewReader(`{"username":"deploy-bot"}`))
+	w := httptest.NewRecorder()
+
+	s.Handler().ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+// TestAuthMiddleware_APITokenScopes verifies that API tokens only reach the
+// routes their scopes allow, and that bot management stays JWT-only.
+func TestAuthMiddleware_APITokenScopes(t *testing.T) {
+	logger := zerolog.Nop()
+	db, err := sqlite.New(sqlite.Config{
+		Path:         filepath.Join(t.TempDir(), "test.db"),
+		MaxOpenConns: 1,
+		ForeignKeys:  true,
+		BusyTimeout:  5 * time.Second,
+	}, logger)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	ctx := context.Background()
+	require.NoError(t, sqlite.NewMigrator(db, logger).Migrate(ctx))
+	_, err = db.ExecContext(ctx, `INSERT INTO users (id, github_id, username, display_name, avatar_url) VALUES ('alice', 1, 'alice', 'Alice', '')`)
+	require.NoError(t, err)
+
+	authSvc := auth.NewService(nil, nil, auth.NewRepository(db, logger), nil, nil, logger)
+	serverSvc := server.NewService(server.NewRepository(db, server.NewStdlibTransactor(db.Conn()), logger), cache.NewLRU(100), logger)
+	bot, err := authSvc.CreateBot(ctx, "alice", "reader", "")
+	require.NoError(t, err)
+	token, err := authSvc.CreateAPIToken(ctx, "alice", bot.ID, "read-only", []string{auth.ScopeMessagesRead})
+	require.NoError(t, err)
+
+	cfg := config.ServerConfig{Host: "127.0.0.1", ReadTimeout: 5 * time.Second, WriteTimeout: 5 * time.Second}
+	s := New(cfg, authSvc, serverSvc, nil, nil, nil, testJWTManager(t), nil, observability.NewHealthChecker(logger, "test"), nil, logger)
+
+	do := func(method, path, bearer string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(`{}`))
+		req.Header.Set("Authorization", "Bearer "+bearer)
+		w := httptest.NewRecorder()
+		s.Handler().ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/api/v1/servers", token.Token))
+	assert.Equal(t, http.StatusForbidden, do(http.MethodPost, "/api/v1/channels/ch-1/messages", token.Token), "missing messages.send")
+	assert.Equal(t, http.StatusForbidden, do(http.MethodGet, "/api/v1/bots", token.Token), "bot management is JWT-only")
+	assert.Equal(t, http.StatusForbidden, do(http.MethodPost, "/api/v1/servers", token.Token), "route not open to tokens")
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "/api/v1/servers", auth.APITokenPrefix+"bogus"))
+
+	require.NoError(t, authSvc.RevokeAPIToken(ctx, "alice", bot.ID, token.ID))
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "/api/v1/servers", token.Token), "revoked")
+}
+
+func TestInvokeCommand_NilService(t *testing.T) {
+	s := testServer(t, nil)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/channels/ch-1/interactions", strings.NewReader(`{"name":"deploy"}`))
+	w := httptest.NewRecorder()
+
+	s.Handler().ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestEmoji_NilService(t *testing.T) {
+	s := testServer(t, nil)
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/api/v1/servers/srv-1/emoji", nil),
+		httptest.NewRequest(http.MethodGet, "/api/v1/emoji/e-1", nil),
+		httptest.NewRequest(http.MethodPut, "/api/v1/messages/msg-1/reactions/%F0%9F%91%8D", nil),
+	} {
+		w := httptest.NewRecorder()
+		s.Handler().ServeHTTP(w, req)
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code, req.URL.Path)
+	}
+}
+
+func TestBookmarks_NilService(t *testing.T) {
+	s := testServer(t, nil)
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/api/v1/bookmarks", nil),
+		httptest.NewRequest(http.MethodGet, "/api/v1/bookmarks/folders", nil),
+		httptest.NewRequest(http.MethodPost, "/api/v1/bookmarks", strings.NewReader(`{"message_id":"msg-1"}`)),
+		httptest.NewRequest(http.MethodPatch, "/api/v1/bookmarks/bm-1", strings.NewReader(`{"note":"later"}`)),
+		httptest.NewRequest(http.MethodDelete, "/api/v1/bookmarks/bm-1", nil),
+	} {
+		w := httptest.NewRecorder()
+		s.Handler().ServeHTTP(w, req)
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code, req.Method+" "+req.URL.Path)
+	}
+}
+
+func TestChannelCategories_NilService(t *testing.T) {
+	s := testServer(t, nil)
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodPatch, "/api/v1/servers/srv-1/channels/ch-1", strings.NewReader(`{"name":"news"}`)),
+		httptest.NewRequest(http.MethodDelete, "/api/v1/servers/srv-1/channels/ch-1", nil),
+		httptest.NewRequest(http.MethodPut, "/api/v1/servers/srv-1/channels/order", strings.NewReader(`{"uncategorized":["ch-1"]}`)),
+		httptest.NewRequest(http.MethodGet, "/api/v1/servers/srv-1/categories", nil),
+		httptest.NewRequest(http.MethodPost, "/api/v1/servers/srv-1/categories", strings.NewReader(`{"name":"Info"}`)),
+		httptest.NewRequest(http.MethodPatch, "/api/v1/servers/srv-1/categories/cat-1", strings.NewReader(`{"name":"News"}`)),
+		httptest.NewRequest(http.MethodDelete, "/api/v1/servers/srv-1/categories/cat-1", nil),
+	} {
+		w := httptest.NewRecorder()
+		s.Handler().ServeHTTP(w, req)
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code, req.Method+" "+req.URL.Path)
+	}
+}
+
+func TestRoles_NilService(t *testing.T) {
+	s := testServer(t, nil)
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/api/v1/servers/srv-1/roles", nil),
+		httptest.NewRequest(http.MethodPost, "/api/v1/servers/srv-1/roles", strings.NewReader(`{"name":"Helpers","permissions":"48"}`)),
+		httptest.NewRequest(http.MethodPut, "/api/v1/servers/srv-1/roles/order", strings.NewReader(`{"roles":["role-1"]}`)),
+		httptest.NewRequest(http.MethodPatch, "/api/v1/servers/srv-1/roles/role-1", strings.NewReader(`{"color":255}`)),
+		httptest.NewRequest(http.MethodDelete, "/api/v1/servers/srv-1/roles/role-1", nil),
+		httptest.NewRequest(http.MethodPut, "/api/v1/servers/srv-1/members/user-1/roles/role-1", nil),
+		httptest.NewRequest(http.MethodDelete, "/api/v1/servers/srv-1/members/user-1/roles/role-1", nil),
+	} {
+		w := httptest.NewRecorder()
+		s.Handler().ServeHTTP(w, req)
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code, req.Method+" "+req.URL.Path)
+	}
+}
+
+func TestAuditLog_NilService(t *testing.T) {
+	s := testServer(t, nil)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/servers/srv-1/audit-log?limit=10", nil)
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestChannelOverwrites_NilService(t *testing.T) {
+	s := testServer(t, nil)
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/api/v1/servers/srv-1/channels/ch-1/overwrites", nil),
+		httptest.NewRequest(http.MethodPut, "/api/v1/servers/srv-1/channels/ch-1/overwrites/role/role-1", strings.NewReader(`{"allow":"0","deny":"512"}`)),
+		httptest.NewRequest(http.MethodDelete, "/api/v1/servers/srv-1/channels/ch-1/overwrites/member/user-1", nil),
+	} {
+		w := httptest.NewRecorder()
+		s.Handler().ServeHTTP(w, req)
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code, req.Method+" "+req.URL.Path)
+	}
+}
@@ -0,0 +1,273 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/concord-chat/concord/internal/security"
+)
+
+// Scopes an API token can be granted.
+const (
+	ScopeMessagesRead   = "messages.read"   // list servers, channels and messages; search
+	ScopeMessagesSend   = "messages.send"   // post, edit and delete own messages and polls
+	ScopeChannelsManage = "channels.manage" // create, edit and delete channels (still subject to server permissions)
+	ScopeCommands       = "commands"        // register slash commands and answer their interactions
+)
+
+// Scopes lists every scope an API token can be granted.
+var Scopes = []string{ScopeMessagesRead, ScopeMessagesSend, ScopeChannelsManage, ScopeCommands}
+
+// APITokenPrefix starts every API token, which tells them apart from JWTs.
+const APITokenPrefix = "cnd_"
+
+const (
+	maxBotsPerOwner    = 10
+	maxBotDisplayName  = 64
+	maxTokenName       = 64
+	tokenTouchInterval = time.Minute
+)
+
+// ErrInvalidAPIToken is returned for unknown, malformed or revoked API tokens.
+var ErrInvalidAPIToken = errors.New("invalid API token")
+
+// APIToken is a long-lived, revocable credential of a bot.
+type APIToken struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"user_id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	CreatedBy  string     `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	// Token is only set when the token is created; it is not retrievable afterwards.
+	Token string `json:"token,omitempty"`
+}
+
+// HasScope reports whether the token was granted scope.
+func (t *APIToken) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateBot creates a bot account owned by ownerID. Bots cannot log in; they
+// authenticate with API tokens created by their owner.
+func (s *Service) CreateBot(ctx context.Context, ownerID, username, displayName string) (*User, error) {
+	owner, err := s.repo.GetUser(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if owner == nil {
+		return nil, fmt.Errorf("user not found")
+	}
+	if owner.Bot {
+		return nil, fmt.Errorf("bots cannot own bots")
+	}
+
+	username = strings.TrimSpace(username)
+	if err := security.NewValidator().ValidateUsername(username); err != nil {
+		return nil, err
+	}
+	displayName = strings.TrimSpace(displayName)
+	if displayName == "" {
+		displayName = username
+	}
+	if len(displayName) > maxBotDisplayName {
+		return nil, fmt.Errorf("display name cannot exceed %d characters", maxBotDisplayName)
+	}
+
+	bots, err := s.repo.ListBots(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if len(bots) >= maxBotsPerOwner {
+		return nil, fmt.Errorf("a user can own at most %d bots", maxBotsPerOwner)
+	}
+
+	githubID, err := placeholderGitHubID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bot: %w", err)
+	}
+	bot := &User{
+		ID:          "bot_" + uuid.New().String(),
+		GitHubID:    githubID,
+		Username:    username,
+		DisplayName: displayName,
+		Bot:         true,
+		BotOwnerID:  ownerID,
+	}
+	if err := s.repo.CreateBot(ctx, bot); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info().
+		Str("bot_id", bot.ID).
+		Str("owner_id", ownerID).
+		Msg("bot created")
+	return s.repo.GetUser(ctx, bot.ID)
+}
+
+// ListBots returns the bots owned by ownerID.
+func (s *Service) ListBots(ctx context.Context, ownerID string) ([]*User, error) {
+	return s.repo.ListBots(ctx, ownerID)
+}
+
+// OwnedBot returns botID if it is a bot owned by ownerID.
+func (s *Service) OwnedBot(ctx context.Context, ownerID, botID string) (*User, error) {
+	bot, err := s.repo.GetUser(ctx, botID)
+	if err != nil {
+		return nil, err
+	}
+	if bot == nil || !bot.Bot || bot.BotOwnerID != ownerID {
+		return nil, fmt.Errorf("bot not found")
+	}
+	return bot, nil
+}
+
+// CreateAPIToken issues a token for a bot owned by ownerID. The returned token
+// carries its secret value, which is not retrievable afterwards.
+func (s *Service) CreateAPIToken(ctx context.Context, ownerID, botID, name string, scopes []string) (*APIToken, error) {
+	if _, err := s.OwnedBot(ctx, ownerID, botID); err != nil {
+		return nil, err
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("token name cannot be empty")
+	}
+	if len(name) > maxTokenName {
+		return nil, fmt.Errorf("token name cannot exceed %d characters", maxTokenName)
+	}
+	scopes, err := normalizeScopes(scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("failed to generate API token: %w", err)
+	}
+	raw := APITokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+
+	t := &APIToken{
+		ID:        uuid.New().String(),
+		UserID:    botID,
+		Name:      name,
+		Scopes:    scopes,
+		CreatedBy: ownerID,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := s.repo.CreateAPIToken(ctx, t, hashAPIToken(raw)); err != nil {
+		return nil, err
+	}
+	t.Token = raw
+
+	s.logger.Info().
+		Str("token_id", t.ID).
+		Str("bot_id", botID).
+		Strs("scopes", scopes).
+		Msg("API token created")
+	return t, nil
+}
+
+// ListAPITokens returns the tokens of a bot owned by ownerID, revoked ones included.
+func (s *Service) ListAPITokens(ctx context.Context, ownerID, botID string) ([]*APIToken, error) {
+	if _, err := s.OwnedBot(ctx, ownerID, botID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListAPITokens(ctx, botID)
+}
+
+// RevokeAPIToken revokes a token of a bot owned by ownerID. It stops working immediately.
+func (s *Service) RevokeAPIToken(ctx context.Context, ownerID, botID, tokenID string) error {
+	if _, err := s.OwnedBot(ctx, ownerID, botID); err != nil {
+		return err
+	}
+	revoked, err := s.repo.RevokeAPIToken(ctx, botID, tokenID, time.Now())
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return fmt.Errorf("token not found")
+	}
+	s.logger.Info().Str("token_id", tokenID).Str("bot_id", botID).Msg("API token revoked")
+	return nil
+}
+
+// AuthenticateAPIToken resolves a raw API token. Returns ErrInvalidAPIToken when
+// it is unknown or revoked.
+// Complexity: O(1)
+func (s *Service) AuthenticateAPIToken(ctx context.Context, raw string) (*APIToken, error) {
+	if !strings.HasPrefix(raw, APITokenPrefix) {
+		return nil, ErrInvalidAPIToken
+	}
+	t, err := s.repo.GetAPITokenByHash(ctx, hashAPIToken(raw))
+	if err != nil {
+		return nil, err
+	}
+	if t == nil || t.RevokedAt != nil {
+		return nil, ErrInvalidAPIToken
+	}
+	if err := s.repo.TouchAPIToken(ctx, t.ID, time.Now(), tokenTouchInterval); err != nil {
+		s.logger.Warn().Err(err).Str("token_id", t.ID).Msg("failed to record API token usage")
+	}
+	return t, nil
+}
+
+func normalizeScopes(scopes []string) ([]string, error) {
+	seen := make(map[string]bool, len(scopes))
+	var out []string
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if scope == "" || seen[scope] {
+			continue
+		}
+		known := false
+		for _, s := range Scopes {
+			if scope == s {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown scope %q", scope)
+		}
+		seen[scope] = true
+		out = append(out, scope)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("at least one scope is required")
+	}
+	return out, nil
+}
+
+// hashAPIToken returns the stored form of a token. Tokens are random 256-bit
+// values, so a plain SHA-256 is enough.
+func hashAPIToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// placeholderGitHubID fills the required, unique github_id of a bot.
+// Real GitHub IDs are positive, so a random negative value cannot collide with one.
+func placeholderGitHubID() (int64, error) {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return 0, err
+	}
+	return -int64(binary.BigEndian.Uint64(b[:])>>1) - 1, nil
+}
@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/concord-chat/concord/internal/store/sqlite"
+)
+
+func setupBotService(t *testing.T) *Service {
+	t.Helper()
+	logger := zerolog.Nop()
+	db, err := sqlite.New(sqlite.Config{
+		Path:         filepath.Join(t.TempDir(), "test.db"),
+		MaxOpenConns: 1,
+		ForeignKeys:  true,
+		BusyTimeout:  5 * time.Second,
+	}, logger)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	ctx := context.Background()
+	require.NoError(t, sqlite.NewMigrator(db, logger).Migrate(ctx))
+
+	_, err = db.ExecContext(ctx, `INSERT INTO users (id, github_id, username, display_name, avatar_url) VALUES ('alice', 1, 'alice', 'Alice', ''), ('bob', 2, 'bob', 'Bob', '')`)
+	require.NoError(t, err)
+	return NewService(nil, nil, NewRepository(db, logger), nil, nil, logger)
+}
+
+func TestCreateBot(t *testing.T) {
+	svc := setupBotService(t)
+	ctx := context.Background()
+
+	bot, err := svc.CreateBot(ctx, "alice", "deploy-bot", "")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(bot.ID, "bot_"))
+	assert.True(t, bot.Bot)
+	assert.Equal(t, "alice", bot.BotOwnerID)
+	assert.Equal(t, "deploy-bot", bot.DisplayName)
+	assert.Less(t, bot.GitHubID, int64(0))
+
+	_, err = svc.CreateBot(ctx, bot.ID, "sub-bot", "")
+	assert.Error(t, err, "bots cannot own bots")
+	_, err = svc.CreateBot(ctx, "alice", "", "")
+	assert.Error(t, err, "username is required")
+
+	bots, err := svc.ListBots(ctx, "alice")
+	require.NoError(t, err)
+	require.Len(t, bots, 1)
+	assert.Equal(t, bot.ID, bots[0].ID)
+
+	_, err = svc.OwnedBot(ctx, "bob", bot.ID)
+	assert.Error(t, err, "only the owner sees the bot")
+	_, err = svc.OwnedBot(ctx, "alice", "bob")
+	assert.Error(t, err, "humans are not bots")
+}
+
+func TestAPIToken_Lifecycle(t *testing.T) {
+	svc := setupBotService(t)
+	ctx := context.Background()
+	bot, err := svc.CreateBot(ctx, "alice", "deploy-bot", "Deploy Bot")
+	require.NoError(t, err)
+
+	token, err := svc.CreateAPIToken(ctx, "alice", bot.ID, "ci", []string{ScopeMessagesSend, " messages.read ", ScopeMessagesSend})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(token.Token, APITokenPrefix))
+	assert.Equal(t, []string{ScopeMessagesSend, ScopeMessagesRead}, token.Scopes)
+
+	got, err := svc.AuthenticateAPIToken(ctx, token.Token)
+	require.NoError(t, err)
+	assert.Equal(t, bot.ID, got.UserID)
+	assert.True(t, got.HasScope(ScopeMessagesRead))
+	assert.False(t, got.HasScope(ScopeChannelsManage))
+
+	tokens, err := svc.ListAPITokens(ctx, "alice", bot.ID)
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	assert.Empty(t, tokens[0].Token, "the token value is only shown once")
+	assert.NotNil(t, tokens[0].LastUsedAt)
+
+	_, err = svc.AuthenticateAPIToken(ctx, token.Token+"x")
+	assert.ErrorIs(t, err, ErrInvalidAPIToken)
+	_, err = svc.AuthenticateAPIToken(ctx, "not-a-token")
+	assert.ErrorIs(t, err, ErrInvalidAPIToken)
+
+	assert.Error(t, svc.RevokeAPIToken(ctx, "bob", bot.ID, token.ID), "only the owner can revoke")
+	require.NoError(t, svc.RevokeAPIToken(ctx, "alice", bot.ID, token.ID))
+	_, err = svc.AuthenticateAPIToken(ctx, token.Token)
+	assert.ErrorIs(t, err, ErrInvalidAPIToken)
+	assert.Error(t, svc.RevokeAPIToken(ctx, "alice", bot.ID, token.ID), "already revoked")
+}
+
+func TestAPIToken_Validation(t *testing.T) {
+	svc := setupBotService(t)
+	ctx := context.Background()
+	bot, err := svc.CreateBot(ctx, "alice", "deploy-bot", "")
+	require.NoError(t, err)
+
+	_, err = svc.CreateAPIToken(ctx, "alice", bot.ID, "ci", nil)
+	assert.Error(t, err, "no scopes")
+	_, err = svc.CreateAPIToken(ctx, "alice", bot.ID, "ci", []string{"admin"})
+	assert.Error(t, err, "unknown scope")
+	_, err = svc.CreateAPIToken(ctx, "alice", bot.ID, " ", []string{ScopeMessagesRead})
+	assert.Error(t, err, "empty name")
+	_, err = svc.CreateAPIToken(ctx, "bob", bot.ID, "ci", []string{ScopeMessagesRead})
+	assert.Error(t, err, "not the owner")
+	_, err = svc.CreateAPIToken(ctx, "alice", "bob", "ci", []string{ScopeMessagesRead})
+	assert.Error(t, err, "tokens are only issued to bots")
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/rs/zerolog"
@@ -16,6 +17,8 @@ type User struct {
 	Username    string    `json:"username"`
 	DisplayName string    `json:"display_name"`
 	AvatarURL   string    `json:"avatar_url"`
+	Bot         bool      `json:"bot"`
+	BotOwnerID  string    `json:"bot_owner_id,omitempty"` // human owning a bot account
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
@@ -39,6 +42,7 @@ type Repository struct {
 type querier interface {
 	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
 	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
 }
 
 // NewRepository creates a new auth repository.
@@ -49,6 +53,24 @@ func NewRepository(db querier, logger zerolog.Logger) *Repository {
 	}
 }
 
+const userColumns = `id, github_id, username, display_name, avatar_url, is_bot, COALESCE(bot_owner_id, ''), created_at, updated_at`
+
+// scanner is implemented by *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanUser(row scanner) (*User, error) {
+	var user User
+	if err := row.Scan(
+		&user.ID, &user.GitHubID, &user.Username, &user.DisplayName,
+		&user.AvatarURL, &user.Bot, &user.BotOwnerID, &user.CreatedAt, &user.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 // UpsertUser creates or updates a user from GitHub profile data.
 // Complexity: O(1)
 func (r *Repository) UpsertUser(ctx context.Context, user *User) error {
@@ -78,14 +100,10 @@ func (r *Repository) UpsertUser(ctx context.Context, user *User) error {
 // GetUserByGitHubID retrieves a user by their GitHub ID.
 // Complexity: O(1) — indexed lookup
 func (r *Repository) GetUserByGitHubID(ctx context.Context, githubID int64) (*User, error) {
-	query := `SELECT id, github_id, username, display_name, avatar_url, created_at, updated_at
+	query := `SELECT ` + userColumns + `
 		FROM users WHERE github_id = ?`
 
-	var user User
-	err := r.db.QueryRowContext(ctx, query, githubID).Scan(
-		&user.ID, &user.GitHubID, &user.Username, &user.DisplayName,
-		&user.AvatarURL, &user.CreatedAt, &user.UpdatedAt,
-	)
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, githubID))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -93,7 +111,7 @@ func (r *Repository) GetUserByGitHubID(ctx context.Context, githubID int64) (*Us
 		return nil, fmt.Errorf("failed to get user by github_id: %w", err)
 	}
 
-	return &user, nil
+	return user, nil
 }
 
 // SaveSession stores an encrypted refresh token session.
@@ -162,14 +180,10 @@ func (r *Repository) DeleteUserSessions(ctx context.Context, userID string) erro
 // GetUser retrieves a user by their primary ID.
 // Complexity: O(1) — indexed lookup
 func (r *Repository) GetUser(ctx context.Context, userID string) (*User, error) {
-	query := `SELECT id, github_id, username, display_name, avatar_url, created_at, updated_at
+	query := `SELECT ` + userColumns + `
 		FROM users WHERE id = ?`
 
-	var user User
-	err := r.db.QueryRowContext(ctx, query, userID).Scan(
-		&user.ID, &user.GitHubID, &user.Username, &user.DisplayName,
-		&user.AvatarURL, &user.CreatedAt, &user.UpdatedAt,
-	)
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, userID))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -177,7 +191,7 @@ func (r *Repository) GetUser(ctx context.Context, userID string) (*User, error)
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
-	return &user, nil
+	return user, nil
 }
 
 // CleanExpiredSessions removes all expired sessions.
@@ -197,3 +211,145 @@ func (r *Repository) CleanExpiredSessions(ctx context.Context) (int64, error) {
 
 	return count, nil
 }
+
+// --- Bots and API tokens ---
+
+// CreateBot inserts a bot user. Bots have no GitHub account; GitHubID must be a
+// unique placeholder (negative, so it cannot collide with a real one).
+// Complexity: O(1)
+func (r *Repository) CreateBot(ctx context.Context, bot *User) error {
+	query := `
+		INSERT INTO users (id, github_id, username, display_name, avatar_url, is_bot, bot_owner_id, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		bot.ID, bot.GitHubID, bot.Username, bot.DisplayName, bot.AvatarURL, true, bot.BotOwnerID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create bot: %w", err)
+	}
+	return nil
+}
+
+// ListBots returns the bots owned by ownerID, oldest first.
+// Complexity: O(n) where n = number of bots of the owner
+func (r *Repository) ListBots(ctx context.Context, ownerID string) ([]*User, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+userColumns+` FROM users WHERE bot_owner_id = ? ORDER BY created_at, id`, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bots: %w", err)
+	}
+	defer rows.Close()
+
+	var bots []*User
+	for rows.Next() {
+		bot, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan bot: %w", err)
+		}
+		bots = append(bots, bot)
+	}
+	return bots, rows.Err()
+}
+
+const tokenColumns = `id, user_id, name, scopes, created_by, created_at, last_used_at, revoked_at`
+
+func scanAPIToken(row scanner) (*APIToken, error) {
+	var t APIToken
+	var scopes string
+	var lastUsed, revoked sql.NullTime
+	if err := row.Scan(&t.ID, &t.UserID, &t.Name, &scopes, &t.CreatedBy, &t.CreatedAt, &lastUsed, &revoked); err != nil {
+		return nil, err
+	}
+	t.Scopes = strings.Split(scopes, ",")
+	if lastUsed.Valid {
+		t.LastUsedAt = &lastUsed.Time
+	}
+	if revoked.Valid {
+		t.RevokedAt = &revoked.Time
+	}
+	return &t, nil
+}
+
+// CreateAPIToken stores a token by hash.
+// Complexity: O(1)
+func (r *Repository) CreateAPIToken(ctx context.Context, t *APIToken, tokenHash string) error {
+	query := `
+		INSERT INTO api_tokens (id, user_id, name, token_hash, scopes, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		t.ID, t.UserID, t.Name, tokenHash, strings.Join(t.Scopes, ","), t.CreatedBy, t.CreatedAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create API token: %w", err)
+	}
+	return nil
+}
+
+// GetAPITokenByHash returns the token with the given hash, revoked or not, or nil if not found.
+// Complexity: O(1) — unique index on token_hash
+func (r *Repository) GetAPITokenByHash(ctx context.Context, tokenHash string) (*APIToken, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+tokenColumns+` FROM api_tokens WHERE token_hash = ?`, tokenHash)
+	t, err := scanAPIToken(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API token: %w", err)
+	}
+	return t, nil
+}
+
+// ListAPITokens returns the tokens of a user, newest first.
+// Complexity: O(n) where n = number of tokens of the user
+func (r *Repository) ListAPITokens(ctx context.Context, userID string) ([]*APIToken, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+tokenColumns+` FROM api_tokens WHERE user_id = ? ORDER BY created_at DESC, id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API tokens: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []*APIToken
+	for rows.Next() {
+		t, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan API token: %w", err)
+		}
+		tokens = append(tokens, t)
+	}
+	return tokens, rows.Err()
+}
+
+// RevokeAPIToken marks a token of userID revoked. Returns false if there was no
+// such active token.
+// Complexity: O(1)
+func (r *Repository) RevokeAPIToken(ctx context.Context, userID, tokenID string, at time.Time) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE api_tokens SET revoked_at = ? WHERE id = ? AND user_id = ? AND revoked_at IS NULL`,
+		at.UTC(), tokenID, userID,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke API token: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke API token: %w", err)
+	}
+	return n > 0, nil
+}
+
+// TouchAPIToken records that a token was used, at most once per interval so
+// authenticated requests do not all turn into writes.
+// Complexity: O(1)
+func (r *Repository) TouchAPIToken(ctx context.Context, tokenID string, now time.Time, interval time.Duration) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE api_tokens SET last_used_at = ? WHERE id = ? AND (last_used_at IS NULL OR last_used_at < ?)`,
+		now.UTC(), tokenID, now.Add(-interval).UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update API token usage: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+// Package bookmarks lets users save channel messages and direct messages they can
+// read, with an optional note and folder. Each bookmark keeps a snapshot of the
+// message, so it stays readable after the message is deleted or the user loses
+// access to it.
+package bookmarks
+
+import "time"
+
+// Kinds of bookmarked messages.
+const (
+	KindChannel = "channel"
+	KindDM      = "dm"
+)
+
+// Status of the source message of a bookmark, resolved when bookmarks are listed.
+const (
+	StatusAvailable   = "available"   // the message exists and the user can read it
+	StatusDeleted     = "deleted"     // the message was deleted; the snapshot is shown
+	StatusUnavailable = "unavailable" // the user can no longer read the message; the snapshot is shown
+)
+
+// Bookmark is a message saved by a user.
+type Bookmark struct {
+	ID        string `json:"id"`
+	Kind      string `json:"kind"` // KindChannel or KindDM
+	MessageID string `json:"message_id"`
+	ChannelID string `json:"channel_id,omitempty"` // set for channel messages
+	PeerID    string `json:"peer_id,omitempty"`    // the other participant of a direct message
+	AuthorID  string `json:"author_id"`
+	// AuthorName is the name shown on the message when it was bookmarked
+	// (the current username for direct messages).
+	AuthorName string `json:"author_name"`
+	// Content is the current message content while Status is StatusAvailable,
+	// and the content saved with the bookmark otherwise.
+	Content          string    `json:"content"`
+	MessageCreatedAt string    `json:"message_created_at"` // ISO 8601
+	Note             string    `json:"note"`
+	Folder           string    `json:"folder"` // empty when unfiled
+	Status           string    `json:"status"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+
+	liveContent *string // content of the source message, nil when it was deleted
+}
+
+// Folder is a bookmark folder with the number of bookmarks in it.
+type Folder struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// ListOpts filters and paginates a user's bookmarks, newest first.
+type ListOpts struct {
+	Folder *string // only bookmarks in this folder ("" for unfiled); nil for all
+	Query  string  // case-insensitive match on the note, content and author name
+	Before string  // bookmark ID to list bookmarks saved before
+	Limit  int     // default 50, max 100
+}
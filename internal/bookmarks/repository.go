@@ -0,0 +1,205 @@
+package bookmarks
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// Repository handles bookmark persistence.
+type Repository struct {
+	db     querier
+	logger zerolog.Logger
+}
+
+// NewRepository creates a new bookmark repository.
+func NewRepository(db querier, logger zerolog.Logger) *Repository {
+	return &Repository{
+		db:     db,
+		logger: logger.With().Str("component", "bookmarks_repo").Logger(),
+	}
+}
+
+// scanner is implemented by *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// bookmarkColumns reads a bookmark with the current content of its source message,
+// which is NULL once the message is deleted. Direct message bookmarks take the
+// current username of the author.
+const bookmarkColumns = `b.id, b.kind, b.message_id, b.channel_id, b.peer_id, b.author_id,
+	COALESCE(NULLIF(b.author_name, ''), u.username, ''), b.content, b.message_created_at,
+	b.note, b.folder, b.created_at, b.updated_at, COALESCE(m.content, d.content)`
+
+const bookmarkJoins = `FROM bookmarks b
+	LEFT JOIN messages m ON b.kind = 'channel' AND m.id = b.message_id
+	LEFT JOIN friend_messages d ON b.kind = 'dm' AND d.id = b.message_id
+	LEFT JOIN users u ON u.id = b.author_id`
+
+func scanBookmark(row scanner) (*Bookmark, error) {
+	var b Bookmark
+	var live sql.NullString
+	if err := row.Scan(&b.ID, &b.Kind, &b.MessageID, &b.ChannelID, &b.PeerID, &b.AuthorID,
+		&b.AuthorName, &b.Content, &b.MessageCreatedAt, &b.Note, &b.Folder, &b.CreatedAt, &b.UpdatedAt, &live); err != nil {
+		return nil, err
+	}
+	if live.Valid {
+		b.liveContent = &live.String
+	}
+	return &b, nil
+}
+
+// Create inserts a bookmark with its message snapshot. Returns false when the user
+// already bookmarked the message.
+// Complexity: O(1) — unique index on (user_id, message_id)
+func (r *Repository) Create(ctx context.Context, userID string, b *Bookmark) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		`INSERT INTO bookmarks (id, user_id, kind, message_id, channel_id, peer_id, author_id, author_name,
+			content, message_created_at, note, folder, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (user_id, message_id) DO NOTHING`,
+		b.ID, userID, b.Kind, b.MessageID, b.ChannelID, b.PeerID, b.AuthorID, b.AuthorName,
+		b.Content, b.MessageCreatedAt, b.Note, b.Folder, b.CreatedAt.UTC(), b.UpdatedAt.UTC(),
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to create bookmark: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to create bookmark: %w", err)
+	}
+	return n > 0, nil
+}
+
+// Get returns a bookmark of userID, or nil if not found.
+// Complexity: O(1)
+func (r *Repository) Get(ctx context.Context, userID, id string) (*Bookmark, error) {
+	b, err := scanBookmark(r.db.QueryRowContext(ctx,
+		`SELECT `+bookmarkColumns+` `+bookmarkJoins+` WHERE b.id = ? AND b.user_id = ?`, id, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bookmark: %w", err)
+	}
+	return b, nil
+}
+
+// List returns the bookmarks of userID matching opts, newest first.
+// Complexity: O(log n + k) for folder listings; searches scan the user's bookmarks
+func (r *Repository) List(ctx context.Context, userID string, opts ListOpts) ([]*Bookmark, error) {
+	limit := opts.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+
+	query := `SELECT ` + bookmarkColumns + ` ` + bookmarkJoins + ` WHERE b.user_id = ?`
+	args := []interface{}{userID}
+	if opts.Folder != nil {
+		query += ` AND b.folder = ?`
+		args = append(args, *opts.Folder)
+	}
+	if q := strings.TrimSpace(opts.Query); q != "" {
+		pattern := "%" + escapeLike(strings.ToLower(q)) + "%"
+		query += ` AND (LOWER(b.note) LIKE ? ESCAPE '\'
+			OR LOWER(b.content) LIKE ? ESCAPE '\'
+			OR LOWER(COALESCE(m.content, d.content, '')) LIKE ? ESCAPE '\'
+			OR LOWER(COALESCE(NULLIF(b.author_name, ''), u.username, '')) LIKE ? ESCAPE '\')`
+		args = append(args, pattern, pattern, pattern, pattern)
+	}
+	if opts.Before != "" {
+		query += ` AND (b.created_at < (SELECT created_at FROM bookmarks WHERE id = ?)
+			OR (b.created_at = (SELECT created_at FROM bookmarks WHERE id = ?) AND b.id < ?))`
+		args = append(args, opts.Before, opts.Before, opts.Before)
+	}
+	query += ` ORDER BY b.created_at DESC, b.id DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bookmarks: %w", err)
+	}
+	defer rows.Close()
+
+	var list []*Bookmark
+	for rows.Next() {
+		b, err := scanBookmark(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan bookmark: %w", err)
+		}
+		list = append(list, b)
+	}
+	return list, rows.Err()
+}
+
+// Count returns the number of bookmarks of userID.
+// Complexity: O(n) where n = number of bookmarks of the user
+func (r *Repository) Count(ctx context.Context, userID string) (int, error) {
+	var n int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM bookmarks WHERE user_id = ?`, userID).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to count bookmarks: %w", err)
+	}
+	return n, nil
+}
+
+// Folders returns the folders of userID by name, with the unfiled bookmarks under "".
+// Complexity: O(n) where n = number of bookmarks of the user
+func (r *Repository) Folders(ctx context.Context, userID string) ([]*Folder, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT folder, COUNT(*) FROM bookmarks WHERE user_id = ? GROUP BY folder ORDER BY folder`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bookmark folders: %w", err)
+	}
+	defer rows.Close()
+
+	var folders []*Folder
+	for rows.Next() {
+		var f Folder
+		if err := rows.Scan(&f.Name, &f.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan bookmark folder: %w", err)
+		}
+		folders = append(folders, &f)
+	}
+	return folders, rows.Err()
+}
+
+// Update sets the note and folder of a bookmark.
+// Complexity: O(1)
+func (r *Repository) Update(ctx context.Context, id, note, folder string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE bookmarks SET note = ?, folder = ?, updated_at = ? WHERE id = ?`, note, folder, at.UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to update bookmark: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a bookmark of userID. Returns false if it did not exist.
+// Complexity: O(1)
+func (r *Repository) Delete(ctx context.Context, userID, id string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM bookmarks WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete bookmark: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete bookmark: %w", err)
+	}
+	return n > 0, nil
+}
+
+// escapeLike escapes the LIKE wildcards of s for use with ESCAPE '\'.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,331 @@
+package bookmarks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+
+	"github.com/concord-chat/concord/internal/chat"
+	"github.com/concord-chat/concord/internal/friends"
+	"github.com/concord-chat/concord/internal/server"
+)
+
+const (
+	maxBookmarksPerUser = 1000
+	maxNoteLength       = 500 // characters
+	maxFolderLength     = 32  // characters
+)
+
+var (
+	// ErrMessageNotFound is returned when bookmarking a message that does not exist
+	// or that the user cannot read.
+	ErrMessageNotFound = errors.New("message not found")
+	// ErrNotFound is returned for bookmarks that do not exist or belong to another user.
+	ErrNotFound = errors.New("bookmark not found")
+	// ErrAlreadyBookmarked is returned when the user already bookmarked the message.
+	ErrAlreadyBookmarked = errors.New("message is already bookmarked")
+)
+
+// Messages loads channel messages. Implemented by chat.Service.
+type Messages interface {
+	GetMessage(ctx context.Context, messageID string) (*chat.Message, error)
+}
+
+// Channels decides which channels a user can read. Implemented by server.Service.
+type Channels interface {
+	GetChannel(ctx context.Context, channelID string) (*server.Channel, error)
+	IsMember(ctx context.Context, serverID, userID string) (bool, error)
+	ReadableChannels(ctx context.Context, userID, serverID string) (map[string]string, error)
+}
+
+// DirectMessages loads direct messages for one of their participants.
+// Implemented by friends.Service.
+type DirectMessages interface {
+	GetDirectMessage(ctx context.Context, userID, messageID string) (*friends.DirectMessage, error)
+	AreFriends(ctx context.Context, userID, friendID string) (bool, error)
+}
+
+// Service manages personal message bookmarks.
+type Service struct {
+	repo     *Repository
+	messages Messages
+	channels Channels
+	dms      DirectMessages // optional; without it direct messages cannot be bookmarked
+	now      func() time.Time
+	logger   zerolog.Logger
+}
+
+// NewService creates a new bookmark service. dms may be nil when direct messages
+// are not stored on this node.
+func NewService(repo *Repository, messages Messages, channels Channels, dms DirectMessages, logger zerolog.Logger) *Service {
+	return &Service{
+		repo:     repo,
+		messages: messages,
+		channels: channels,
+		dms:      dms,
+		now:      time.Now,
+		logger:   logger.With().Str("component", "bookmarks_service").Logger(),
+	}
+}
+
+// Add bookmarks a message userID can read. kind is KindChannel or KindDM; note and
+// folder are optional.
+// Complexity: O(c) where c = channels of the message's server
+func (s *Service) Add(ctx context.Context, userID, kind, messageID, note, folder string) (*Bookmark, error) {
+	note, folder, err := normalize(note, folder)
+	if err != nil {
+		return nil, err
+	}
+	count, err := s.repo.Count(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxBookmarksPerUser {
+		return nil, fmt.Errorf("you can have at most %d bookmarks", maxBookmarksPerUser)
+	}
+
+	b, err := s.snapshot(ctx, userID, kind, messageID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	b.ID = uuid.New().String()
+	b.Note = note
+	b.Folder = folder
+	b.CreatedAt = now
+	b.UpdatedAt = now
+
+	created, err := s.repo.Create(ctx, userID, b)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, ErrAlreadyBookmarked
+	}
+
+	s.logger.Debug().
+		Str("bookmark_id", b.ID).
+		Str("user_id", userID).
+		Str("message_id", messageID).
+		Msg("bookmark added")
+
+	// Reload to resolve the author name of direct messages.
+	saved, err := s.repo.Get(ctx, userID, b.ID)
+	if err != nil {
+		return nil, err
+	}
+	if saved == nil {
+		return nil, ErrNotFound
+	}
+	if err := s.resolve(ctx, userID, saved); err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+// List returns the bookmarks of userID matching opts, newest first, each with the
+// status of its source message.
+// Complexity: O(k + c) where k = bookmarks returned, c = channels readable by the user
+func (s *Service) List(ctx context.Context, userID string, opts ListOpts) ([]*Bookmark, error) {
+	list, err := s.repo.List(ctx, userID, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.resolve(ctx, userID, list...); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Folders returns the bookmark folders of userID with their sizes.
+// Complexity: O(n) where n = number of bookmarks of the user
+func (s *Service) Folders(ctx context.Context, userID string) ([]*Folder, error) {
+	return s.repo.Folders(ctx, userID)
+}
+
+// Update changes the note and/or folder of a bookmark; nil leaves a field unchanged.
+// Complexity: O(c) where c = channels readable by the user
+func (s *Service) Update(ctx context.Context, userID, bookmarkID string, note, folder *string) (*Bookmark, error) {
+	b, err := s.repo.Get(ctx, userID, bookmarkID)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, ErrNotFound
+	}
+	if note != nil {
+		b.Note = *note
+	}
+	if folder != nil {
+		b.Folder = *folder
+	}
+	if b.Note, b.Folder, err = normalize(b.Note, b.Folder); err != nil {
+		return nil, err
+	}
+
+	b.UpdatedAt = s.now()
+	if err := s.repo.Update(ctx, b.ID, b.Note, b.Folder, b.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := s.resolve(ctx, userID, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Delete removes a bookmark of userID.
+// Complexity: O(1)
+func (s *Service) Delete(ctx context.Context, userID, bookmarkID string) error {
+	deleted, err := s.repo.Delete(ctx, userID, bookmarkID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// snapshot loads a message userID can read and copies it into a new bookmark.
+func (s *Service) snapshot(ctx context.Context, userID, kind, messageID string) (*Bookmark, error) {
+	switch kind {
+	case KindChannel:
+		msg, err := s.messages.GetMessage(ctx, messageID)
+		if err != nil {
+			return nil, err
+		}
+		if msg == nil {
+			return nil, ErrMessageNotFound
+		}
+		readable, err := s.canReadChannel(ctx, userID, msg.ChannelID)
+		if err != nil {
+			return nil, err
+		}
+		if !readable {
+			return nil, ErrMessageNotFound
+		}
+		return &Bookmark{
+			Kind:             KindChannel,
+			MessageID:        msg.ID,
+			ChannelID:        msg.ChannelID,
+			AuthorID:         msg.AuthorID,
+			AuthorName:       msg.AuthorName,
+			Content:          msg.Content,
+			MessageCreatedAt: msg.CreatedAt,
+		}, nil
+
+	case KindDM:
+		if s.dms == nil {
+			return nil, fmt.Errorf("direct messages cannot be bookmarked on this server")
+		}
+		msg, err := s.dms.GetDirectMessage(ctx, userID, messageID)
+		if err != nil {
+			return nil, err
+		}
+		if msg == nil {
+			return nil, ErrMessageNotFound
+		}
+		peerID := msg.SenderID
+		if peerID == userID {
+			peerID = msg.ReceiverID
+		}
+		return &Bookmark{
+			Kind:             KindDM,
+			MessageID:        msg.ID,
+			PeerID:           peerID,
+			AuthorID:         msg.SenderID,
+			Content:          msg.Content,
+			MessageCreatedAt: msg.CreatedAt,
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("kind must be %q or %q", KindChannel, KindDM)
+	}
+}
+
+// canReadChannel reports whether userID can read channelID.
+func (s *Service) canReadChannel(ctx context.Context, userID, channelID string) (bool, error) {
+	ch, err := s.channels.GetChannel(ctx, channelID)
+	if err != nil || ch == nil {
+		return false, err
+	}
+	member, err := s.channels.IsMember(ctx, ch.ServerID, userID)
+	if err != nil || !member {
+		return false, err
+	}
+	readable, err := s.channels.ReadableChannels(ctx, userID, ch.ServerID)
+	if err != nil {
+		return false, err
+	}
+	_, ok := readable[channelID]
+	return ok, nil
+}
+
+// resolve sets the status of bookmarks and shows the current content of the
+// messages userID can still read.
+func (s *Service) resolve(ctx context.Context, userID string, list ...*Bookmark) error {
+	var readable map[string]string // loaded on the first live channel bookmark
+	friendsWith := make(map[string]bool)
+
+	for _, b := range list {
+		if b.liveContent == nil {
+			b.Status = StatusDeleted
+			continue
+		}
+
+		var ok bool
+		switch b.Kind {
+		case KindChannel:
+			if readable == nil {
+				var err error
+				if readable, err = s.channels.ReadableChannels(ctx, userID, ""); err != nil {
+					return err
+				}
+			}
+			_, ok = readable[b.ChannelID]
+		case KindDM:
+			known, cached := friendsWith[b.PeerID]
+			if !cached && s.dms != nil {
+				var err error
+				if known, err = s.dms.AreFriends(ctx, userID, b.PeerID); err != nil {
+					return err
+				}
+				friendsWith[b.PeerID] = known
+			}
+			ok = known
+		}
+
+		if ok {
+			b.Status = StatusAvailable
+			b.Content = *b.liveContent
+		} else {
+			b.Status = StatusUnavailable
+		}
+	}
+	return nil
+}
+
+// normalize trims and validates a note and folder name.
+func normalize(note, folder string) (string, string, error) {
+	note = strings.TrimSpace(note)
+	if utf8.RuneCountInString(note) > maxNoteLength {
+		return "", "", fmt.Errorf("notes are limited to %d characters", maxNoteLength)
+	}
+	folder = strings.TrimSpace(folder)
+	if utf8.RuneCountInString(folder) > maxFolderLength {
+		return "", "", fmt.Errorf("folder names are limited to %d characters", maxFolderLength)
+	}
+	for _, r := range folder {
+		if unicode.IsControl(r) {
+			return "", "", fmt.Errorf("folder names cannot contain control characters")
+		}
+	}
+	return note, folder, nil
+}
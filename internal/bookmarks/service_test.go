@@ -0,0 +1,232 @@
+package bookmarks
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/concord-chat/concord/internal/cache"
+	"github.com/concord-chat/concord/internal/chat"
+	"github.com/concord-chat/concord/internal/friends"
+	"github.com/concord-chat/concord/internal/server"
+	"github.com/concord-chat/concord/internal/store/sqlite"
+)
+
+type fixture struct {
+	svc     *Service
+	chat    *chat.Service
+	friends *friends.Service
+	servers *server.Service
+}
+
+func setupService(t *testing.T) *fixture {
+	t.Helper()
+	logger := zerolog.Nop()
+	db, err := sqlite.New(sqlite.Config{
+		Path:         filepath.Join(t.TempDir(), "test.db"),
+		MaxOpenConns: 1,
+		ForeignKeys:  true,
+		BusyTimeout:  5 * time.Second,
+	}, logger)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	ctx := context.Background()
+	require.NoError(t, sqlite.NewMigrator(db, logger).Migrate(ctx))
+
+	for _, stmt := range []string{
+		`INSERT INTO users (id, username) VALUES ('alice', 'alice'), ('bob', 'bob'), ('carol', 'carol')`,
+		`INSERT INTO servers (id, name, owner_id, invite_code) VALUES ('srv-1', 'Test', 'alice', 'inv-1')`,
+		`INSERT INTO server_members (server_id, user_id) VALUES ('srv-1', 'alice'), ('srv-1', 'bob')`,
+		`INSERT INTO channels (id, server_id, name, type) VALUES ('ch-1', 'srv-1', 'general', 'text')`,
+		`INSERT INTO friends (user_id, friend_id) VALUES ('alice', 'bob'), ('bob', 'alice')`,
+	} {
+		_, err := db.ExecContext(ctx, stmt)
+		require.NoError(t, err)
+	}
+
+	servers := server.NewService(server.NewRepository(db, server.NewStdlibTransactor(db.Conn()), logger), cache.NewLRU(100), logger)
+	chatSvc := chat.NewService(chat.NewRepository(db, logger), logger)
+	chatSvc.SetSendPolicy(servers)
+	friendsSvc := friends.NewService(friends.NewRepository(db, friends.NewStdlibTransactor(db.Conn()), logger), nil, logger)
+	return &fixture{
+		svc:     NewService(NewRepository(db, logger), chatSvc, servers, friendsSvc, logger),
+		chat:    chatSvc,
+		friends: friendsSvc,
+		servers: servers,
+	}
+}
+
+func TestAdd(t *testing.T) {
+	f := setupService(t)
+	ctx := context.Background()
+
+	msg, err := f.chat.SendMessage(ctx, "ch-1", "alice", "release notes are out")
+	require.NoError(t, err)
+	b, err := f.svc.Add(ctx, "bob", KindChannel, msg.ID, "  read later ", "work")
+	require.NoError(t, err)
+	assert.Equal(t, "ch-1", b.ChannelID)
+	assert.Equal(t, "alice", b.AuthorName)
+	assert.Equal(t, "release notes are out", b.Content)
+	assert.Equal(t, "read later", b.Note)
+	assert.Equal(t, "work", b.Folder)
+	assert.Equal(t, StatusAvailable, b.Status)
+
+	_, err = f.svc.Add(ctx, "bob", KindChannel, msg.ID, "", "")
+	assert.ErrorIs(t, err, ErrAlreadyBookmarked)
+	_, err = f.svc.Add(ctx, "carol", KindChannel, msg.ID, "", "")
+	assert.ErrorIs(t, err, ErrMessageNotFound, "not a member of the server")
+	_, err = f.svc.Add(ctx, "bob", KindChannel, "missing", "", "")
+	assert.ErrorIs(t, err, ErrMessageNotFound)
+	_, err = f.svc.Add(ctx, "bob", "thread", msg.ID, "", "")
+	assert.Error(t, err)
+	_, err = f.svc.Add(ctx, "alice", KindChannel, msg.ID, strings.Repeat("a", maxNoteLength+1), "")
+	assert.Error(t, err, "note too long")
+
+	dm, err := f.friends.SendDirectMessage(ctx, "bob", "alice", "see you at 5")
+	require.NoError(t, err)
+	saved, err := f.svc.Add(ctx, "alice", KindDM, dm.ID, "", "")
+	require.NoError(t, err)
+	assert.Equal(t, "bob", saved.PeerID)
+	assert.Equal(t, "bob", saved.AuthorName)
+	_, err = f.svc.Add(ctx, "carol", KindDM, dm.ID, "", "")
+	assert.ErrorIs(t, err, ErrMessageNotFound, "not a participant")
+}
+
+func TestList_FoldersAndSearch(t *testing.T) {
+	f := setupService(t)
+	ctx := context.Background()
+
+	var ids []string
+	for i, content := range []string{"deploy at noon", "lunch menu", "100% done"} {
+		msg, err := f.chat.SendMessage(ctx, "ch-1", "alice", content)
+		require.NoError(t, err)
+		folder := "work"
+		if i == 1 {
+			folder = ""
+		}
+		b, err := f.svc.Add(ctx, "bob", KindChannel, msg.ID, "", folder)
+		require.NoError(t, err)
+		ids = append(ids, b.ID)
+		f.svc.now = func() time.Time { return time.Now().Add(time.Duration(i+1) * time.Minute) }
+	}
+	_, err := f.svc.Update(ctx, "bob", ids[1], strPtr("Friday lunch"), nil)
+	require.NoError(t, err)
+
+	all, err := f.svc.List(ctx, "bob", ListOpts{})
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	assert.Equal(t, ids[2], all[0].ID, "newest first")
+
+	work := "work"
+	list, err := f.svc.List(ctx, "bob", ListOpts{Folder: &work})
+	require.NoError(t, err)
+	assert.Len(t, list, 2)
+	unfiled := ""
+	list, err = f.svc.List(ctx, "bob", ListOpts{Folder: &unfiled})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, ids[1], list[0].ID)
+
+	list, err = f.svc.List(ctx, "bob", ListOpts{Query: "FRIDAY"})
+	require.NoError(t, err)
+	require.Len(t, list, 1, "matches the note")
+	list, err = f.svc.List(ctx, "bob", ListOpts{Query: "0%"})
+	require.NoError(t, err)
+	require.Len(t, list, 1, "wildcards are literal")
+	assert.Equal(t, "100% done", list[0].Content)
+
+	page, err := f.svc.List(ctx, "bob", ListOpts{Before: all[0].ID, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, all[1].ID, page[0].ID)
+
+	folders, err := f.svc.Folders(ctx, "bob")
+	require.NoError(t, err)
+	assert.Equal(t, []*Folder{{Name: "", Count: 1}, {Name: "work", Count: 2}}, folders)
+
+	others, err := f.svc.List(ctx, "alice", ListOpts{})
+	require.NoError(t, err)
+	assert.Empty(t, others)
+}
+
+func TestList_DegradesGracefully(t *testing.T) {
+	f := setupService(t)
+	ctx := context.Background()
+
+	edited, err := f.chat.SendMessage(ctx, "ch-1", "bob", "typo")
+	require.NoError(t, err)
+	deleted, err := f.chat.SendMessage(ctx, "ch-1", "alice", "short lived")
+	require.NoError(t, err)
+	dm, err := f.friends.SendDirectMessage(ctx, "alice", "bob", "secret plan")
+	require.NoError(t, err)
+	for _, add := range []struct{ kind, id string }{{KindChannel, edited.ID}, {KindChannel, deleted.ID}, {KindDM, dm.ID}} {
+		_, err := f.svc.Add(ctx, "bob", add.kind, add.id, "", "")
+		require.NoError(t, err)
+	}
+
+	_, err = f.chat.EditMessage(ctx, edited.ID, "bob", "fixed")
+	require.NoError(t, err)
+	require.NoError(t, f.chat.DeleteMessage(ctx, deleted.ID, "alice", false))
+
+	byMessage := func() map[string]*Bookmark {
+		list, err := f.svc.List(ctx, "bob", ListOpts{})
+		require.NoError(t, err)
+		m := make(map[string]*Bookmark, len(list))
+		for _, b := range list {
+			m[b.MessageID] = b
+		}
+		return m
+	}
+	got := byMessage()
+	assert.Equal(t, StatusAvailable, got[edited.ID].Status)
+	assert.Equal(t, "fixed", got[edited.ID].Content, "shows the current content")
+	assert.Equal(t, StatusDeleted, got[deleted.ID].Status)
+	assert.Equal(t, "short lived", got[deleted.ID].Content, "keeps the snapshot")
+	assert.Equal(t, StatusAvailable, got[dm.ID].Status)
+
+	require.NoError(t, f.servers.KickMember(ctx, "srv-1", "alice", "bob"))
+	require.NoError(t, f.friends.RemoveFriend(ctx, "bob", "alice"))
+	got = byMessage()
+	assert.Equal(t, StatusUnavailable, got[edited.ID].Status)
+	assert.Equal(t, "typo", got[edited.ID].Content, "edits are hidden once access is lost")
+	assert.Equal(t, StatusUnavailable, got[dm.ID].Status)
+	assert.Equal(t, "secret plan", got[dm.ID].Content)
+}
+
+func TestUpdateAndDelete(t *testing.T) {
+	f := setupService(t)
+	ctx := context.Background()
+
+	msg, err := f.chat.SendMessage(ctx, "ch-1", "alice", "hello")
+	require.NoError(t, err)
+	b, err := f.svc.Add(ctx, "bob", KindChannel, msg.ID, "note", "inbox")
+	require.NoError(t, err)
+
+	_, err = f.svc.Update(ctx, "alice", b.ID, strPtr("mine"), nil)
+	assert.ErrorIs(t, err, ErrNotFound, "another user's bookmark")
+	_, err = f.svc.Update(ctx, "bob", b.ID, nil, strPtr("bad\nfolder"))
+	assert.Error(t, err)
+
+	updated, err := f.svc.Update(ctx, "bob", b.ID, nil, strPtr(" archive "))
+	require.NoError(t, err)
+	assert.Equal(t, "note", updated.Note)
+	assert.Equal(t, "archive", updated.Folder)
+
+	assert.ErrorIs(t, f.svc.Delete(ctx, "alice", b.ID), ErrNotFound)
+	require.NoError(t, f.svc.Delete(ctx, "bob", b.ID))
+	assert.ErrorIs(t, f.svc.Delete(ctx, "bob", b.ID), ErrNotFound)
+
+	// The message can be bookmarked again once the bookmark is gone
+	_, err = f.svc.Add(ctx, "bob", KindChannel, msg.ID, "", "")
+	assert.NoError(t, err)
+}
+
+func strPtr(s string) *string {
+	return &s
+}
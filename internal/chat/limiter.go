@@ -0,0 +1,130 @@
+package chat
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/concord-chat/concord/internal/security"
+)
+
+// SlowModePolicy resolves the slow mode interval of a channel for a given user.
+// Implementations return bypass=true for users allowed to ignore slow mode (moderators and above).
+type SlowModePolicy interface {
+	SlowModeFor(ctx context.Context, channelID, userID string) (interval time.Duration, bypass bool, err error)
+}
+
+// SendLimiter enforces per-user message rate limits and per-channel slow mode.
+type SendLimiter struct {
+	messages *security.RateLimiter // nil = no global per-user limit
+	policy   SlowModePolicy        // nil = slow mode disabled
+
+	mu       sync.Mutex
+	lastSent map[string]time.Time // "channelID:userID" -> last accepted message
+	now      func() time.Time
+}
+
+// NewSendLimiter creates a limiter allowing perMinute messages per user across all channels.
+// A non-positive perMinute disables the per-user limit; slow mode is still applied via policy.
+// Complexity: O(1)
+func NewSendLimiter(perMinute int, policy SlowModePolicy) *SendLimiter {
+	l := &SendLimiter{
+		policy:   policy,
+		lastSent: make(map[string]time.Time),
+		now:      time.Now,
+	}
+	if perMinute > 0 {
+		l.messages = security.NewRateLimiter(perMinute, time.Minute, perMinute)
+	}
+	return l
+}
+
+// Check reports whether userID may post in channelID right now.
+// On success the send is recorded; otherwise a *security.RateLimitError carrying
+// the retry-after duration is returned.
+// Complexity: O(1) plus the policy lookup
+func (l *SendLimiter) Check(ctx context.Context, channelID, userID string) error {
+	var interval time.Duration
+	if l.policy != nil {
+		iv, bypass, err := l.policy.SlowModeFor(ctx, channelID, userID)
+		if err != nil {
+			return err
+		}
+		if !bypass {
+			interval = iv
+		}
+	}
+
+	key := channelID + ":" + userID
+
+	// Slow mode is checked first so a rejected message does not burn a rate-limit token.
+	// The check and the record share one critical section: two concurrent sends can't
+	// both see the slot free.
+	var (
+		sentAt, prev time.Time
+		hadPrev      bool
+	)
+	if interval > 0 {
+		l.mu.Lock()
+		sentAt = l.now()
+		prev, hadPrev = l.lastSent[key]
+		if hadPrev {
+			if wait := interval - sentAt.Sub(prev); wait > 0 {
+				l.mu.Unlock()
+				return &security.RateLimitError{Reason: "slow mode is enabled in this channel", RetryAfter: wait}
+			}
+		}
+		l.lastSent[key] = sentAt
+		l.pruneLocked(interval)
+		l.mu.Unlock()
+	}
+
+	if l.messages != nil {
+		if ok, wait := l.messages.AllowWithRetry(userID); !ok {
+			if interval > 0 {
+				l.undo(key, sentAt, prev, hadPrev)
+			}
+			return &security.RateLimitError{Reason: "you are sending messages too quickly", RetryAfter: wait}
+		}
+	}
+	return nil
+}
+
+// undo gives back a slow mode slot recorded for a send the rate limit then
+// rejected, unless a later send has replaced it since.
+// Complexity: O(1)
+func (l *SendLimiter) undo(key string, sentAt, prev time.Time, hadPrev bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if cur, ok := l.lastSent[key]; !ok || !cur.Equal(sentAt) {
+		return
+	}
+	if hadPrev {
+		l.lastSent[key] = prev
+	} else {
+		delete(l.lastSent, key)
+	}
+}
+
+// pruneLocked drops slow mode entries old enough to no longer matter once the map grows.
+// Caller must hold l.mu.
+// Complexity: O(n) amortized over inserts
+func (l *SendLimiter) pruneLocked(interval time.Duration) {
+	const maxTracked = 10000
+	if len(l.lastSent) < maxTracked {
+		return
+	}
+	// Slow mode is capped server-side, so anything older than the cap can never block.
+	cutoff := l.now().Add(-maxSlowModeWindow)
+	if interval > maxSlowModeWindow {
+		cutoff = l.now().Add(-interval)
+	}
+	for k, t := range l.lastSent {
+		if t.Before(cutoff) {
+			delete(l.lastSent, k)
+		}
+	}
+}
+
+// maxSlowModeWindow bounds how long slow mode entries are retained.
+const maxSlowModeWindow = 6 * time.Hour
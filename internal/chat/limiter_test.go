@@ -0,0 +1,117 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/concord-chat/concord/internal/security"
+)
+
+type stubSlowMode struct {
+	interval time.Duration
+	bypass   map[string]bool
+}
+
+func (p *stubSlowMode) SlowModeFor(_ context.Context, _, userID string) (time.Duration, bool, error) {
+	return p.interval, p.bypass[userID], nil
+}
+
+func TestSendLimiter_SlowMode(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	l := NewSendLimiter(0, &stubSlowMode{interval: 30 * time.Second, bypass: map[string]bool{"mod": true}})
+	l.now = func() time.Time { return now }
+
+	require.NoError(t, l.Check(ctx, "ch1", "alice"))
+
+	err := l.Check(ctx, "ch1", "alice")
+	var rlErr *security.RateLimitError
+	require.True(t, errors.As(err, &rlErr))
+	assert.Equal(t, 30*time.Second, rlErr.RetryAfter)
+
+	// Other channels and other users are independent
+	assert.NoError(t, l.Check(ctx, "ch2", "alice"))
+	assert.NoError(t, l.Check(ctx, "ch1", "bob"))
+
+	// Moderators bypass slow mode
+	assert.NoError(t, l.Check(ctx, "ch1", "mod"))
+	assert.NoError(t, l.Check(ctx, "ch1", "mod"))
+
+	now = now.Add(31 * time.Second)
+	assert.NoError(t, l.Check(ctx, "ch1", "alice"))
+}
+
+func TestSendLimiter_PerUserLimit(t *testing.T) {
+	ctx := context.Background()
+	l := NewSendLimiter(2, nil)
+
+	assert.NoError(t, l.Check(ctx, "ch1", "alice"))
+	assert.NoError(t, l.Check(ctx, "ch2", "alice"))
+
+	err := l.Check(ctx, "ch3", "alice")
+	var rlErr *security.RateLimitError
+	require.True(t, errors.As(err, &rlErr))
+	assert.Greater(t, rlErr.RetryAfter, time.Duration(0))
+
+	assert.NoError(t, l.Check(ctx, "ch1", "bob"))
+}
+
+func TestSendLimiter_SlowModeConcurrent(t *testing.T) {
+	ctx := context.Background()
+	l := NewSendLimiter(0, &stubSlowMode{interval: time.Minute})
+
+	const senders = 50
+	results := make(chan error, senders)
+	var wg sync.WaitGroup
+	for i := 0; i < senders; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results <- l.Check(ctx, "ch1", "alice")
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	accepted := 0
+	for err := range results {
+		if err == nil {
+			accepted++
+		}
+	}
+	assert.Equal(t, 1, accepted, "slow mode lets exactly one concurrent send through")
+}
+
+func TestSendLimiter_RateLimitKeepsSlowModeSlot(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := NewSendLimiter(1, &stubSlowMode{interval: 10 * time.Second})
+	l.now = func() time.Time { return now }
+
+	require.NoError(t, l.Check(ctx, "ch1", "alice"))
+	now = now.Add(11 * time.Second)
+
+	// The per-user limit rejects this send, so its slow mode slot is given back.
+	err := l.Check(ctx, "ch2", "alice")
+	var rlErr *security.RateLimitError
+	require.True(t, errors.As(err, &rlErr))
+	assert.Equal(t, "you are sending messages too quickly", rlErr.Reason)
+	l.mu.Lock()
+	_, tracked := l.lastSent["ch2:alice"]
+	l.mu.Unlock()
+	assert.False(t, tracked)
+
+	err = l.Check(ctx, "ch1", "alice")
+	require.True(t, errors.As(err, &rlErr))
+	assert.Equal(t, "you are sending messages too quickly", rlErr.Reason, "the rate limit is checked after slow mode")
+	l.mu.Lock()
+	assert.Equal(t, now.Add(-11*time.Second), l.lastSent["ch1:alice"], "the earlier slot is restored")
+	l.mu.Unlock()
+}
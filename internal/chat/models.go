@@ -1,17 +1,106 @@
 package chat
 
+import (
+	"github.com/concord-chat/concord/internal/linkpreview"
+	"github.com/concord-chat/concord/internal/markdown"
+)
+
+// Author types of a message.
+const (
+	AuthorUser    = "user"
+	AuthorWebhook = "webhook"
+)
+
 // Message represents a text message in a channel.
 type Message struct {
 	ID        string  `json:"id"`
 	ChannelID string  `json:"channel_id"`
 	AuthorID  string  `json:"author_id"`
 	Content   string  `json:"content"`
-	Type      string  `json:"type"` // "text", "file", "system"
+	Type      string  `json:"type"`                // "text", "file", "system", "poll"
 	EditedAt  *string `json:"edited_at,omitempty"` // ISO 8601
 	CreatedAt string  `json:"created_at"`          // ISO 8601
-	// Joined fields (from users table)
+	// Joined fields (from users table, or the per-message override of a webhook post)
 	AuthorName   string `json:"author_name,omitempty"`
 	AuthorAvatar string `json:"author_avatar,omitempty"`
+	// AuthorType is AuthorUser, or AuthorWebhook for posts made through an incoming
+	// webhook; AuthorID is then the user who created the webhook.
+	AuthorType string `json:"author_type"`
+	WebhookID  string `json:"webhook_id,omitempty"`
+	// Parsed formatting entities (filled by the service, not stored)
+	Markup *markdown.Document `json:"markup,omitempty"`
+	// Link previews, attached asynchronously after the message is sent
+	Embeds []*linkpreview.Preview `json:"embeds,omitempty"`
+	// Poll definition and aggregated results, set when Type is "poll"
+	Poll *Poll `json:"poll,omitempty"`
+	// Reactions grouped by emoji, in the order they were first added
+	Reactions []*Reaction `json:"reactions,omitempty"`
+}
+
+// Reaction is one emoji reacted on a message with the number of users who chose it.
+type Reaction struct {
+	Emoji   string `json:"emoji"`              // the Unicode emoji, or the name of a custom emoji
+	EmojiID string `json:"emoji_id,omitempty"` // set for custom emoji
+	Count   int    `json:"count"`
+	Me      bool   `json:"me"` // whether the viewer reacted with this emoji
+
+	users []string // always filled, used to resolve Me
+}
+
+// ReactionRow is one stored reaction.
+type ReactionRow struct {
+	MessageID string
+	Emoji     string // the Unicode emoji, or the custom emoji ID
+	EmojiID   string
+	Name      string // name of the custom emoji
+	UserID    string
+}
+
+// Poll is the structured content of a "poll" message. The question is the message content.
+type Poll struct {
+	Options        []*PollOption `json:"options"`
+	MultipleChoice bool          `json:"multiple_choice"`
+	Anonymous      bool          `json:"anonymous"`
+	ClosesAt       *string       `json:"closes_at,omitempty"` // ISO 8601
+	Closed         bool          `json:"closed"`
+	TotalVoters    int           `json:"total_voters"`
+	MyVotes        []string      `json:"my_votes"` // option IDs chosen by the viewer
+}
+
+// PollOption is one answer of a poll with its vote count. Voters is only
+// filled for polls that are not anonymous.
+type PollOption struct {
+	ID     string   `json:"id"`
+	Label  string   `json:"label"`
+	Votes  int      `json:"votes"`
+	Voters []string `json:"voters,omitempty"`
+
+	voters []string // always filled, used to resolve MyVotes
+}
+
+// PollInput describes a new poll.
+type PollInput struct {
+	Question       string   `json:"question"`
+	Options        []string `json:"options"`
+	MultipleChoice bool     `json:"multiple_choice"`
+	Anonymous      bool     `json:"anonymous"`
+	ClosesAt       string   `json:"closes_at,omitempty"` // ISO 8601; empty = never
+}
+
+// PollVote is one stored vote.
+type PollVote struct {
+	MessageID string
+	OptionID  string
+	UserID    string
+}
+
+// Attachment is the metadata of a file attached to a message.
+type Attachment struct {
+	ID        string `json:"id"`
+	Filename  string `json:"filename"`
+	SizeBytes int64  `json:"size_bytes"`
+	MimeType  string `json:"mime_type"`
+	Hash      string `json:"hash"`
 }
 
 // PaginationOpts controls cursor-based pagination for message listing.
@@ -24,5 +113,6 @@ type PaginationOpts struct {
 // SearchResult represents a message found by full-text search.
 type SearchResult struct {
 	Message
-	Snippet string `json:"snippet"` // Highlighted matching text
+	ServerID string `json:"server_id"`
+	Snippet  string `json:"snippet"` // Highlighted matching text
 }
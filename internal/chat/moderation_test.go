@@ -0,0 +1,42 @@
+package chat_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// moderation lets the listed users manage messages and records deletions.
+type moderation struct {
+	managers map[string]bool
+	deleted  []string
+}
+
+func (m *moderation) CanManageMessages(_ context.Context, _, userID string) (bool, error) {
+	return m.managers[userID], nil
+}
+
+func (m *moderation) MessageDeleted(_ context.Context, _, messageID, authorID, actorID string) {
+	m.deleted = append(m.deleted, actorID+" deleted "+messageID+" by "+authorID)
+}
+
+func TestDeleteMessage_Moderation(t *testing.T) {
+	svc, _ := setupPollService(t)
+	mod := &moderation{managers: map[string]bool{"alice": true}}
+	svc.SetModeration(mod)
+	ctx := context.Background()
+
+	own, err := svc.SendMessage(ctx, "ch-1", "bob", "mine")
+	require.NoError(t, err)
+	require.NoError(t, svc.DeleteMessage(ctx, own.ID, "bob", false))
+	assert.Empty(t, mod.deleted, "authors deleting their own messages are not recorded")
+
+	msg, err := svc.SendMessage(ctx, "ch-1", "bob", "spam")
+	require.NoError(t, err)
+	assert.Error(t, svc.DeleteMessage(ctx, msg.ID, "carol", false))
+	assert.Error(t, svc.DeleteMessage(ctx, msg.ID, "carol", true), "the manager flag is verified")
+	require.NoError(t, svc.DeleteMessage(ctx, msg.ID, "alice", true))
+	assert.Equal(t, []string{"alice deleted " + msg.ID + " by bob"}, mod.deleted)
+}
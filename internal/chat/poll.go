@@ -0,0 +1,353 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	minPollOptions      = 2
+	maxPollOptions      = 10
+	maxPollOptionLength = 100
+	maxPollQuestion     = 300
+	maxPollDuration     = 30 * 24 * time.Hour
+)
+
+var (
+	// ErrSendForbidden is returned when the user lacks permission to post in the channel.
+	ErrSendForbidden = errors.New("insufficient permissions to send messages in this channel")
+	// ErrManageForbidden is returned when the user may not act on others' messages.
+	ErrManageForbidden = errors.New("insufficient permissions to manage messages in this channel")
+	// ErrMessageNotFound is returned for missing messages and for messages in
+	// channels the user cannot see.
+	ErrMessageNotFound = errors.New("message not found")
+)
+
+// SendPolicy decides who may read and post in a channel.
+type SendPolicy interface {
+	// CanViewChannel reports whether userID may see channelID and its messages.
+	CanViewChannel(ctx context.Context, channelID, userID string) (bool, error)
+	// CanSendMessages reports whether userID may send messages in channelID.
+	CanSendMessages(ctx context.Context, channelID, userID string) (bool, error)
+}
+
+// SetSendPolicy enables permission checks for polls and reactions.
+func (s *Service) SetSendPolicy(p SendPolicy) {
+	s.policy = p
+}
+
+// CreatePoll posts a poll message. The question becomes the message content.
+// Returns a *security.RateLimitError when the author is rate limited or in slow mode.
+// Complexity: O(k) where k = number of options
+func (s *Service) CreatePoll(ctx context.Context, channelID, authorID string, in PollInput) (*Message, error) {
+	question := strings.TrimSpace(in.Question)
+	if question == "" {
+		return nil, fmt.Errorf("poll question cannot be empty")
+	}
+	if len(question) > maxPollQuestion {
+		return nil, fmt.Errorf("poll question exceeds maximum length of %d characters", maxPollQuestion)
+	}
+	if len(in.Options) < minPollOptions || len(in.Options) > maxPollOptions {
+		return nil, fmt.Errorf("a poll needs between %d and %d options", minPollOptions, maxPollOptions)
+	}
+
+	poll := &Poll{MultipleChoice: in.MultipleChoice, Anonymous: in.Anonymous}
+	seen := make(map[string]bool, len(in.Options))
+	for _, label := range in.Options {
+		label = strings.TrimSpace(label)
+		if label == "" {
+			return nil, fmt.Errorf("poll options cannot be empty")
+		}
+		if len(label) > maxPollOptionLength {
+			return nil, fmt.Errorf("poll option exceeds maximum length of %d characters", maxPollOptionLength)
+		}
+		key := strings.ToLower(label)
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate poll option %q", label)
+		}
+		seen[key] = true
+		poll.Options = append(poll.Options, &PollOption{ID: uuid.New().String(), Label: label})
+	}
+
+	var closesAt *time.Time
+	if in.ClosesAt != "" {
+		t, err := time.Parse(time.RFC3339, in.ClosesAt)
+		if err != nil {
+			return nil, fmt.Errorf("closes_at must be an RFC 3339 timestamp")
+		}
+		now := s.now()
+		if !t.After(now) {
+			return nil, fmt.Errorf("closes_at must be in the future")
+		}
+		if t.Sub(now) > maxPollDuration {
+			return nil, fmt.Errorf("polls can stay open for at most %d days", int(maxPollDuration.Hours()/24))
+		}
+		closesAt = &t
+	}
+
+	if err := s.requireSend(ctx, channelID, authorID); err != nil {
+		return nil, err
+	}
+	if s.limiter != nil {
+		if err := s.limiter.Check(ctx, channelID, authorID); err != nil {
+			return nil, err
+		}
+	}
+
+	msg := &Message{
+		ID:        uuid.New().String(),
+		ChannelID: channelID,
+		AuthorID:  authorID,
+		Content:   question,
+		Type:      "poll",
+	}
+	if err := s.repo.Save(ctx, msg); err != nil {
+		return nil, fmt.Errorf("failed to send poll: %w", err)
+	}
+	if err := s.repo.SavePoll(ctx, msg.ID, poll, closesAt); err != nil {
+		if delErr := s.repo.Delete(ctx, msg.ID); delErr != nil {
+			s.logger.Error().Err(delErr).Str("message_id", msg.ID).Msg("failed to remove incomplete poll")
+		}
+		return nil, err
+	}
+
+	s.logger.Info().
+		Str("message_id", msg.ID).
+		Str("channel_id", channelID).
+		Str("author_id", authorID).
+		Int("options", len(poll.Options)).
+		Msg("poll created")
+
+	created, err := s.GetMessage(ctx, msg.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.messageCreated(ctx, created)
+	return created, nil
+}
+
+// VotePoll replaces userID's votes on a poll with optionIDs; an empty list retracts
+// the vote. Single-choice polls accept at most one option. The returned message has
+// MyVotes resolved for userID.
+// Complexity: O(k + v) where k = options chosen, v = votes on the poll
+func (s *Service) VotePoll(ctx context.Context, messageID, userID string, optionIDs []string) (*Message, error) {
+	msg, err := s.getPoll(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if msg.Poll.Closed {
+		return nil, fmt.Errorf("poll is closed")
+	}
+	if err := s.requireSend(ctx, msg.ChannelID, userID); err != nil {
+		return nil, err
+	}
+
+	valid := make(map[string]bool, len(msg.Poll.Options))
+	for _, opt := range msg.Poll.Options {
+		valid[opt.ID] = true
+	}
+	chosen := make([]string, 0, len(optionIDs))
+	seen := make(map[string]bool, len(optionIDs))
+	for _, id := range optionIDs {
+		if !valid[id] {
+			return nil, fmt.Errorf("unknown poll option %q", id)
+		}
+		if !seen[id] {
+			seen[id] = true
+			chosen = append(chosen, id)
+		}
+	}
+	if !msg.Poll.MultipleChoice && len(chosen) > 1 {
+		return nil, fmt.Errorf("this poll allows a single choice")
+	}
+
+	if err := s.repo.ReplacePollVotes(ctx, messageID, userID, chosen); err != nil {
+		return nil, err
+	}
+
+	s.logger.Debug().
+		Str("message_id", messageID).
+		Str("user_id", userID).
+		Int("options", len(chosen)).
+		Msg("poll vote recorded")
+
+	updated, err := s.GetMessage(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	ApplyPollViewer(userID, updated)
+	return updated, nil
+}
+
+// ClosePoll ends a poll early. Only the author or a manager can close it; like
+// DeleteMessage, the isManager flag is verified against the moderation checker and
+// refused without one.
+// Complexity: O(v) where v = votes on the poll
+func (s *Service) ClosePoll(ctx context.Context, messageID, actorID string, isManager bool) (*Message, error) {
+	msg, err := s.getPoll(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requireView(ctx, msg.ChannelID, actorID); err != nil {
+		return nil, err
+	}
+	if msg.AuthorID != actorID {
+		if !isManager || s.mod == nil {
+			return nil, ErrManageForbidden
+		}
+		ok, err := s.mod.CanManageMessages(ctx, msg.ChannelID, actorID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrManageForbidden
+		}
+	}
+	if !msg.Poll.Closed {
+		if err := s.repo.ClosePoll(ctx, messageID, s.now()); err != nil {
+			return nil, err
+		}
+		s.logger.Info().Str("message_id", messageID).Str("actor_id", actorID).Msg("poll closed")
+	}
+
+	updated, err := s.GetMessage(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	ApplyPollViewer(actorID, updated)
+	return updated, nil
+}
+
+// ApplyPollViewer fills Poll.MyVotes on msgs with the options userID voted for.
+// Complexity: O(v) where v = votes on the polls
+func ApplyPollViewer(userID string, msgs ...*Message) {
+	for _, m := range msgs {
+		if m == nil || m.Poll == nil {
+			continue
+		}
+		m.Poll.MyVotes = []string{}
+		for _, opt := range m.Poll.Options {
+			for _, voter := range opt.voters {
+				if voter == userID {
+					m.Poll.MyVotes = append(m.Poll.MyVotes, opt.ID)
+					break
+				}
+			}
+		}
+	}
+}
+
+// getPoll loads a poll message with its results.
+func (s *Service) getPoll(ctx context.Context, messageID string) (*Message, error) {
+	msg, err := s.GetMessage(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if msg == nil {
+		return nil, ErrMessageNotFound
+	}
+	if msg.Type != "poll" || msg.Poll == nil {
+		return nil, fmt.Errorf("message is not a poll")
+	}
+	return msg, nil
+}
+
+// requireView checks the send policy when one is configured. Channels the user
+// cannot see fail with ErrMessageNotFound, so their messages are not revealed.
+func (s *Service) requireView(ctx context.Context, channelID, userID string) error {
+	if s.policy == nil {
+		return nil
+	}
+	ok, err := s.policy.CanViewChannel(ctx, channelID, userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrMessageNotFound
+	}
+	return nil
+}
+
+// requireSend checks the send policy when one is configured.
+func (s *Service) requireSend(ctx context.Context, channelID, userID string) error {
+	if s.policy == nil {
+		return nil
+	}
+	ok, err := s.policy.CanSendMessages(ctx, channelID, userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrSendForbidden
+	}
+	return nil
+}
+
+// withPolls loads poll definitions and aggregated results onto poll messages.
+// Failures are logged, not returned, so a broken poll never hides the channel history.
+// Complexity: O(k + v) where k = options and v = votes of the polls in msgs
+func (s *Service) withPolls(ctx context.Context, msgs ...*Message) {
+	ids := make([]string, 0)
+	for _, m := range msgs {
+		if m != nil && m.Type == "poll" {
+			ids = append(ids, m.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	polls, err := s.repo.GetPolls(ctx, ids)
+	if err != nil {
+		s.logger.Warn().Err(err).Msg("failed to load polls")
+		return
+	}
+	votes, err := s.repo.GetPollVotes(ctx, ids)
+	if err != nil {
+		s.logger.Warn().Err(err).Msg("failed to load poll votes")
+		return
+	}
+
+	options := make(map[string]*PollOption)
+	voters := make(map[string]map[string]bool)
+	for messageID, poll := range polls {
+		for _, opt := range poll.Options {
+			options[opt.ID] = opt
+		}
+		voters[messageID] = make(map[string]bool)
+	}
+	for _, v := range votes {
+		opt := options[v.OptionID]
+		if opt == nil {
+			continue
+		}
+		opt.Votes++
+		opt.voters = append(opt.voters, v.UserID)
+		voters[v.MessageID][v.UserID] = true
+	}
+
+	now := s.now()
+	for messageID, poll := range polls {
+		poll.TotalVoters = len(voters[messageID])
+		if poll.ClosesAt != nil {
+			if t, err := time.Parse(time.RFC3339, *poll.ClosesAt); err == nil && !now.Before(t) {
+				poll.Closed = true
+			}
+		}
+		for _, opt := range poll.Options {
+			if !poll.Anonymous {
+				opt.Voters = opt.voters
+			}
+		}
+	}
+	for _, m := range msgs {
+		if m != nil && m.Type == "poll" {
+			m.Poll = polls[m.ID]
+		}
+	}
+}
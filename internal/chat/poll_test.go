@@ -0,0 +1,217 @@
+package chat_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/concord-chat/concord/internal/chat"
+	"github.com/concord-chat/concord/internal/store/sqlite"
+)
+
+type sendPolicy map[string]bool
+
+func (p sendPolicy) CanViewChannel(_ context.Context, _, userID string) (bool, error) {
+	return p[userID], nil
+}
+
+func (p sendPolicy) CanSendMessages(_ context.Context, _, userID string) (bool, error) {
+	return p[userID], nil
+}
+
+func setupPollService(t *testing.T) (*chat.Service, *sqlite.DB) {
+	t.Helper()
+	logger := zerolog.Nop()
+	db, err := sqlite.New(sqlite.Config{
+		Path:         filepath.Join(t.TempDir(), "test.db"),
+		MaxOpenConns: 1,
+		ForeignKeys:  true,
+		BusyTimeout:  5 * time.Second,
+	}, logger)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	ctx := context.Background()
+	require.NoError(t, sqlite.NewMigrator(db, logger).Migrate(ctx))
+
+	for _, stmt := range []string{
+		`INSERT INTO users (id, username) VALUES ('alice', 'alice'), ('bob', 'bob'), ('carol', 'carol')`,
+		`INSERT INTO servers (id, name, owner_id) VALUES ('srv-1', 'Test', 'alice')`,
+		`INSERT INTO channels (id, server_id, name) VALUES ('ch-1', 'srv-1', 'general')`,
+	} {
+		_, err := db.ExecContext(ctx, stmt)
+		require.NoError(t, err)
+	}
+
+	svc := chat.NewService(chat.NewRepository(db, logger), logger)
+	svc.SetSendPolicy(sendPolicy{"alice": true, "bob": true})
+	return svc, db
+}
+
+func optionIDs(msg *chat.Message) []string {
+	ids := make([]string, len(msg.Poll.Options))
+	for i, opt := range msg.Poll.Options {
+		ids[i] = opt.ID
+	}
+	return ids
+}
+
+func TestPoll_SingleChoiceVoting(t *testing.T) {
+	svc, _ := setupPollService(t)
+	ctx := context.Background()
+
+	msg, err := svc.CreatePoll(ctx, "ch-1", "alice", chat.PollInput{
+		Question: "Lunch?",
+		Options:  []string{"Pizza", "Sushi", "Tacos"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "poll", msg.Type)
+	assert.Equal(t, "Lunch?", msg.Content)
+	require.Len(t, msg.Poll.Options, 3)
+	assert.Equal(t, "Pizza", msg.Poll.Options[0].Label)
+	opts := optionIDs(msg)
+
+	_, err = svc.VotePoll(ctx, msg.ID, "alice", opts[:2])
+	assert.Error(t, err, "single-choice polls reject two options")
+	_, err = svc.VotePoll(ctx, msg.ID, "alice", []string{"nope"})
+	assert.Error(t, err)
+
+	_, err = svc.VotePoll(ctx, msg.ID, "alice", opts[:1])
+	require.NoError(t, err)
+	voted, err := svc.VotePoll(ctx, msg.ID, "bob", opts[:1])
+	require.NoError(t, err)
+	assert.Equal(t, opts[:1], voted.Poll.MyVotes)
+	assert.Equal(t, 2, voted.Poll.Options[0].Votes)
+	assert.ElementsMatch(t, []string{"alice", "bob"}, voted.Poll.Options[0].Voters)
+
+	// Changing the vote replaces it, an empty list retracts it.
+	_, err = svc.VotePoll(ctx, msg.ID, "bob", opts[1:2])
+	require.NoError(t, err)
+	retracted, err := svc.VotePoll(ctx, msg.ID, "alice", nil)
+	require.NoError(t, err)
+	assert.Empty(t, retracted.Poll.MyVotes)
+	assert.Equal(t, 0, retracted.Poll.Options[0].Votes)
+	assert.Equal(t, 1, retracted.Poll.Options[1].Votes)
+	assert.Equal(t, 1, retracted.Poll.TotalVoters)
+}
+
+func TestPoll_MultipleChoiceAnonymous(t *testing.T) {
+	svc, _ := setupPollService(t)
+	ctx := context.Background()
+
+	msg, err := svc.CreatePoll(ctx, "ch-1", "alice", chat.PollInput{
+		Question:       "Which days?",
+		Options:        []string{"Mon", "Tue", "Wed"},
+		MultipleChoice: true,
+		Anonymous:      true,
+	})
+	require.NoError(t, err)
+	opts := optionIDs(msg)
+
+	_, err = svc.VotePoll(ctx, msg.ID, "alice", []string{opts[0], opts[2], opts[0]})
+	require.NoError(t, err)
+	_, err = svc.VotePoll(ctx, msg.ID, "bob", opts[2:])
+	require.NoError(t, err)
+
+	msgs, err := svc.GetMessages(ctx, "ch-1", chat.PaginationOpts{})
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	chat.ApplyPollViewer("alice", msgs...)
+
+	poll := msgs[0].Poll
+	assert.Equal(t, 2, poll.TotalVoters)
+	assert.Equal(t, []int{1, 0, 2}, []int{poll.Options[0].Votes, poll.Options[1].Votes, poll.Options[2].Votes})
+	assert.Equal(t, []string{opts[0], opts[2]}, poll.MyVotes, "viewers still see their own votes")
+	for _, opt := range poll.Options {
+		assert.Empty(t, opt.Voters, "anonymous polls hide voters")
+	}
+}
+
+func TestPoll_Close(t *testing.T) {
+	svc, db := setupPollService(t)
+	ctx := context.Background()
+
+	msg, err := svc.CreatePoll(ctx, "ch-1", "alice", chat.PollInput{
+		Question: "Ship it?",
+		Options:  []string{"Yes", "No"},
+		ClosesAt: time.Now().Add(time.Hour).UTC().Format(time.RFC3339),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, msg.Poll.ClosesAt)
+	assert.False(t, msg.Poll.Closed)
+
+	_, err = svc.ClosePoll(ctx, msg.ID, "bob", false)
+	assert.ErrorIs(t, err, chat.ErrManageForbidden, "only the author or a manager can close")
+	_, err = svc.ClosePoll(ctx, msg.ID, "bob", true)
+	assert.ErrorIs(t, err, chat.ErrManageForbidden, "the manager flag is refused without a moderation checker")
+	_, err = svc.ClosePoll(ctx, msg.ID, "carol", true)
+	assert.ErrorIs(t, err, chat.ErrMessageNotFound, "polls in hidden channels are not revealed")
+
+	mod := &moderation{managers: map[string]bool{"bob": true}}
+	svc.SetModeration(mod)
+	svc.SetSendPolicy(sendPolicy{"alice": true, "bob": true, "carol": true})
+	_, err = svc.ClosePoll(ctx, msg.ID, "carol", true)
+	assert.ErrorIs(t, err, chat.ErrManageForbidden, "the manager flag is verified")
+	closed, err := svc.ClosePoll(ctx, msg.ID, "bob", true)
+	require.NoError(t, err)
+	assert.True(t, closed.Poll.Closed)
+
+	_, err = svc.VotePoll(ctx, msg.ID, "alice", optionIDs(msg)[:1])
+	assert.Error(t, err, "closed polls reject votes")
+
+	// Polls past their close time are closed without an explicit close.
+	other, err := svc.CreatePoll(ctx, "ch-1", "alice", chat.PollInput{Question: "Tea?", Options: []string{"Yes", "No"}})
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, `UPDATE polls SET closes_at = ? WHERE message_id = ?`, time.Now().Add(-time.Minute).UTC(), other.ID)
+	require.NoError(t, err)
+	expired, err := svc.GetMessage(ctx, other.ID)
+	require.NoError(t, err)
+	assert.True(t, expired.Poll.Closed)
+
+	_, err = svc.EditMessage(ctx, other.ID, "alice", "Coffee?")
+	assert.Error(t, err, "polls cannot be edited")
+}
+
+func TestPoll_Validation(t *testing.T) {
+	svc, _ := setupPollService(t)
+	ctx := context.Background()
+
+	for name, in := range map[string]chat.PollInput{
+		"no question":      {Options: []string{"a", "b"}},
+		"one option":       {Question: "q", Options: []string{"a"}},
+		"duplicate option": {Question: "q", Options: []string{"Yes", "yes"}},
+		"blank option":     {Question: "q", Options: []string{"a", " "}},
+		"past close":       {Question: "q", Options: []string{"a", "b"}, ClosesAt: "2000-01-01T00:00:00Z"},
+		"bad close":        {Question: "q", Options: []string{"a", "b"}, ClosesAt: "tomorrow"},
+	} {
+		_, err := svc.CreatePoll(ctx, "ch-1", "alice", in)
+		assert.Error(t, err, name)
+	}
+
+	_, err := svc.CreatePoll(ctx, "ch-1", "carol", chat.PollInput{Question: "q", Options: []string{"a", "b"}})
+	assert.ErrorIs(t, err, chat.ErrSendForbidden)
+
+	msg, err := svc.CreatePoll(ctx, "ch-1", "alice", chat.PollInput{Question: "q", Options: []string{"a", "b"}})
+	require.NoError(t, err)
+	_, err = svc.VotePoll(ctx, msg.ID, "carol", optionIDs(msg)[:1])
+	assert.ErrorIs(t, err, chat.ErrSendForbidden)
+}
+
+func TestPoll_RebuiltMessagesTableKeepsSearch(t *testing.T) {
+	svc, db := setupPollService(t)
+	ctx := context.Background()
+	svc.SetSearcher(sqlite.NewChatSearcher(db, zerolog.Nop()))
+
+	_, err := svc.SendMessage(ctx, "ch-1", "alice", "deploy window tonight")
+	require.NoError(t, err)
+	_, err = svc.CreatePoll(ctx, "ch-1", "alice", chat.PollInput{Question: "deploy now?", Options: []string{"a", "b"}})
+	require.NoError(t, err)
+
+	results, err := svc.SearchMessages(ctx, "ch-1", "deploy", 10)
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+}
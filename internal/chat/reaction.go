@@ -0,0 +1,219 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	maxReactionsPerMessage = 20 // distinct emoji
+	maxUnicodeEmojiBytes   = 64 // long enough for ZWJ sequences with skin tones
+)
+
+// EmojiResolver maps custom emoji tokens to the emoji of a channel's server.
+type EmojiResolver interface {
+	// ResolveEmoji rewrites :name: tokens in content to the custom emoji of
+	// channelID's server and drops references to emoji of other servers.
+	ResolveEmoji(ctx context.Context, channelID, content string) (string, error)
+	// ChannelEmoji reports whether emojiID is a custom emoji of channelID's server.
+	ChannelEmoji(ctx context.Context, channelID, emojiID string) (bool, error)
+}
+
+// SetEmojiResolver enables custom emoji in messages and reactions.
+func (s *Service) SetEmojiResolver(r EmojiResolver) {
+	s.emoji = r
+}
+
+// AddReaction reacts to a message as userID. emoji is a Unicode emoji, or a custom
+// emoji of the channel's server written as name:id. The returned message has Me
+// resolved for userID.
+// Complexity: O(r) where r = reactions on the message
+func (s *Service) AddReaction(ctx context.Context, messageID, userID, emoji string) (*Message, error) {
+	key, emojiID, err := parseReaction(emoji)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := s.GetMessage(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if msg == nil {
+		return nil, ErrMessageNotFound
+	}
+	if err := s.requireSend(ctx, msg.ChannelID, userID); err != nil {
+		return nil, err
+	}
+
+	if emojiID != "" {
+		if s.emoji == nil {
+			return nil, fmt.Errorf("custom emoji are not available")
+		}
+		ok, err := s.emoji.ChannelEmoji(ctx, msg.ChannelID, emojiID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("unknown emoji for this server")
+		}
+	}
+	if reactionIndex(msg, key) < 0 && len(msg.Reactions) >= maxReactionsPerMessage {
+		return nil, fmt.Errorf("a message can have at most %d different reactions", maxReactionsPerMessage)
+	}
+
+	if err := s.repo.AddReaction(ctx, messageID, userID, key, emojiID, s.now()); err != nil {
+		return nil, err
+	}
+
+	s.logger.Debug().
+		Str("message_id", messageID).
+		Str("user_id", userID).
+		Str("emoji", key).
+		Msg("reaction added")
+
+	return s.reactedMessage(ctx, messageID, userID)
+}
+
+// RemoveReaction removes userID's reaction with emoji, in the same format as AddReaction.
+// Messages in channels userID cannot see fail with ErrMessageNotFound.
+// Complexity: O(r) where r = reactions on the message
+func (s *Service) RemoveReaction(ctx context.Context, messageID, userID, emoji string) (*Message, error) {
+	key, _, err := parseReaction(emoji)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := s.GetMessage(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if msg == nil {
+		return nil, ErrMessageNotFound
+	}
+	if err := s.requireView(ctx, msg.ChannelID, userID); err != nil {
+		return nil, err
+	}
+	if err := s.repo.RemoveReaction(ctx, messageID, userID, key); err != nil {
+		return nil, err
+	}
+	return s.reactedMessage(ctx, messageID, userID)
+}
+
+// ApplyReactionViewer fills Reaction.Me on msgs for userID.
+// Complexity: O(r) where r = reactions on the messages
+func ApplyReactionViewer(userID string, msgs ...*Message) {
+	for _, m := range msgs {
+		if m == nil {
+			continue
+		}
+		for _, r := range m.Reactions {
+			r.Me = false
+			for _, u := range r.users {
+				if u == userID {
+					r.Me = true
+					break
+				}
+			}
+		}
+	}
+}
+
+// reactedMessage reloads a message after a reaction change.
+func (s *Service) reactedMessage(ctx context.Context, messageID, userID string) (*Message, error) {
+	msg, err := s.GetMessage(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if msg == nil {
+		return nil, ErrMessageNotFound
+	}
+	ApplyReactionViewer(userID, msg)
+	ApplyPollViewer(userID, msg)
+	return msg, nil
+}
+
+// parseReaction splits a reaction into its storage key and custom emoji ID. Custom
+// emoji are given as name:id (a message token such as <:name:id> is accepted too)
+// and keyed by ID; Unicode emoji are their own key.
+func parseReaction(emoji string) (key, emojiID string, err error) {
+	emoji = strings.TrimSpace(emoji)
+	if strings.Contains(emoji, ":") {
+		token := strings.TrimSuffix(strings.TrimPrefix(emoji, "<"), ">")
+		i := strings.LastIndex(token, ":")
+		emojiID = token[i+1:]
+		if emojiID == "" || len(emojiID) > 64 {
+			return "", "", fmt.Errorf("custom emoji must be given as name:id")
+		}
+		return emojiID, emojiID, nil
+	}
+
+	if emoji == "" || len(emoji) > maxUnicodeEmojiBytes || !utf8.ValidString(emoji) {
+		return "", "", fmt.Errorf("invalid emoji")
+	}
+	pictographic := false
+	for _, r := range emoji {
+		if r < utf8.RuneSelf {
+			// ASCII only appears in keycap sequences such as 1️⃣ or #️⃣
+			if !unicode.IsDigit(r) && r != '#' && r != '*' {
+				return "", "", fmt.Errorf("invalid emoji")
+			}
+			continue
+		}
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return "", "", fmt.Errorf("invalid emoji")
+		}
+		pictographic = true
+	}
+	if !pictographic {
+		return "", "", fmt.Errorf("invalid emoji")
+	}
+	return emoji, "", nil
+}
+
+// reactionIndex returns the position of the reaction keyed by key on msg, or -1.
+func reactionIndex(msg *Message, key string) int {
+	for i, r := range msg.Reactions {
+		if r.EmojiID == key || (r.EmojiID == "" && r.Emoji == key) {
+			return i
+		}
+	}
+	return -1
+}
+
+// withReactions loads the reactions of msgs, grouped by emoji.
+// Failures are logged, not returned, so broken reactions never hide the channel history.
+// Complexity: O(r) where r = reactions on the messages
+func (s *Service) withReactions(ctx context.Context, msgs ...*Message) {
+	ids := make([]string, 0, len(msgs))
+	byID := make(map[string]*Message, len(msgs))
+	for _, m := range msgs {
+		if m != nil {
+			ids = append(ids, m.ID)
+			byID[m.ID] = m
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	rows, err := s.repo.GetReactions(ctx, ids)
+	if err != nil {
+		s.logger.Warn().Err(err).Msg("failed to load reactions")
+		return
+	}
+	for _, row := range rows {
+		m := byID[row.MessageID]
+		i := reactionIndex(m, row.Emoji)
+		if i < 0 {
+			r := &Reaction{Emoji: row.Emoji, EmojiID: row.EmojiID}
+			if row.EmojiID != "" {
+				r.Emoji = row.Name
+			}
+			m.Reactions = append(m.Reactions, r)
+			i = len(m.Reactions) - 1
+		}
+		m.Reactions[i].Count++
+		m.Reactions[i].users = append(m.Reactions[i].users, row.UserID)
+	}
+}
@@ -0,0 +1,106 @@
+package chat_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/concord-chat/concord/internal/chat"
+)
+
+// emojiResolver knows the custom emoji "blob" (ID e-1) of ch-1's server.
+type emojiResolver struct{}
+
+func (emojiResolver) ResolveEmoji(_ context.Context, _, content string) (string, error) {
+	return strings.ReplaceAll(content, ":blob:", "<:blob:e-1>"), nil
+}
+
+func (emojiResolver) ChannelEmoji(_ context.Context, channelID, emojiID string) (bool, error) {
+	return channelID == "ch-1" && emojiID == "e-1", nil
+}
+
+func TestReactions(t *testing.T) {
+	svc, db := setupPollService(t)
+	ctx := context.Background()
+	_, err := db.ExecContext(ctx, `INSERT INTO server_emoji (id, server_id, name, mime_type, size_bytes, local_path, created_by, created_at)
+		VALUES ('e-1', 'srv-1', 'blob', 'image/png', 10, '/tmp/e-1.png', 'alice', CURRENT_TIMESTAMP)`)
+	require.NoError(t, err)
+
+	msg, err := svc.SendMessage(ctx, "ch-1", "alice", "hello")
+	require.NoError(t, err)
+
+	_, err = svc.AddReaction(ctx, msg.ID, "alice", "👍")
+	require.NoError(t, err)
+	_, err = svc.AddReaction(ctx, msg.ID, "alice", "blob:e-1")
+	assert.Error(t, err, "custom emoji need a resolver")
+
+	svc.SetEmojiResolver(emojiResolver{})
+	_, err = svc.AddReaction(ctx, msg.ID, "bob", "blob:e-1")
+	require.NoError(t, err)
+	_, err = svc.AddReaction(ctx, msg.ID, "bob", "other:e-2")
+	assert.Error(t, err, "emoji of another server")
+	_, err = svc.AddReaction(ctx, msg.ID, "carol", "👍")
+	assert.ErrorIs(t, err, chat.ErrSendForbidden)
+
+	updated, err := svc.AddReaction(ctx, msg.ID, "bob", "👍")
+	require.NoError(t, err)
+	require.Len(t, updated.Reactions, 2)
+	assert.Equal(t, "👍", updated.Reactions[0].Emoji)
+	assert.Equal(t, 2, updated.Reactions[0].Count)
+	assert.True(t, updated.Reactions[0].Me)
+	assert.Equal(t, &chat.Reaction{Emoji: "blob", EmojiID: "e-1", Count: 1, Me: true}, cleanReaction(updated.Reactions[1]))
+
+	// Reacting twice is a no-op
+	updated, err = svc.AddReaction(ctx, msg.ID, "bob", "<:blob:e-1>")
+	require.NoError(t, err)
+	assert.Equal(t, 1, updated.Reactions[1].Count)
+
+	_, err = svc.RemoveReaction(ctx, msg.ID, "carol", "👍")
+	assert.ErrorIs(t, err, chat.ErrMessageNotFound, "non-members cannot read the message back")
+	_, err = svc.RemoveReaction(ctx, "missing", "alice", "👍")
+	assert.ErrorIs(t, err, chat.ErrMessageNotFound)
+
+	updated, err = svc.RemoveReaction(ctx, msg.ID, "alice", "👍")
+	require.NoError(t, err)
+	assert.Equal(t, 1, updated.Reactions[0].Count)
+	assert.False(t, updated.Reactions[0].Me)
+}
+
+func TestReactions_InvalidEmoji(t *testing.T) {
+	svc, _ := setupPollService(t)
+	ctx := context.Background()
+	msg, err := svc.SendMessage(ctx, "ch-1", "alice", "hello")
+	require.NoError(t, err)
+
+	for _, emoji := range []string{"", "thumbsup", "👍 👍", "blob:", strings.Repeat("👍", 20)} {
+		_, err := svc.AddReaction(ctx, msg.ID, "alice", emoji)
+		assert.Error(t, err, emoji)
+	}
+	_, err = svc.AddReaction(ctx, msg.ID, "alice", "1️⃣")
+	assert.NoError(t, err, "keycap sequence")
+	_, err = svc.AddReaction(ctx, "missing", "alice", "👍")
+	assert.Error(t, err)
+}
+
+func TestSendMessage_ResolvesEmoji(t *testing.T) {
+	svc, _ := setupPollService(t)
+	ctx := context.Background()
+	svc.SetEmojiResolver(emojiResolver{})
+
+	msg, err := svc.SendMessage(ctx, "ch-1", "alice", "nice :blob:")
+	require.NoError(t, err)
+	assert.Equal(t, "nice <:blob:e-1>", msg.Content)
+	assert.Equal(t, []string{"blob"}, msg.Markup.EmojiNames())
+
+	edited, err := svc.EditMessage(ctx, msg.ID, "alice", ":blob: again")
+	require.NoError(t, err)
+	assert.Equal(t, "<:blob:e-1> again", edited.Content)
+}
+
+// cleanReaction copies the exported fields of r for comparison.
+func cleanReaction(r *chat.Reaction) *chat.Reaction {
+	return &chat.Reaction{Emoji: r.Emoji, EmojiID: r.EmojiID, Count: r.Count, Me: r.Me}
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/rs/zerolog"
+
+	"github.com/concord-chat/concord/internal/linkpreview"
 )
 
 type querier interface {
@@ -15,6 +18,9 @@ type querier interface {
 	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
 }
 
+// maxListLimit caps a ListAfter page.
+const maxListLimit = 500
+
 // Repository handles message-related database operations.
 type Repository struct {
 	db     querier
@@ -49,11 +55,79 @@ func (r *Repository) Save(ctx context.Context, msg *Message) error {
 	return nil
 }
 
+// SaveWebhookMessage inserts a message posted through a webhook, keeping the
+// display name and avatar it was posted with (an empty avatar is stored as such,
+// so the creator's avatar never shows through).
+// Complexity: O(1) + O(log n) FTS index update via trigger
+func (r *Repository) SaveWebhookMessage(ctx context.Context, msg *Message) error {
+	query := `INSERT INTO messages (id, channel_id, author_id, content, type, author_type, webhook_id, override_name, override_avatar, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)`
+
+	_, err := r.db.ExecContext(ctx, query, msg.ID, msg.ChannelID, msg.AuthorID, msg.Content, msg.Type,
+		AuthorWebhook, msg.WebhookID, msg.AuthorName, msg.AuthorAvatar)
+	if err != nil {
+		return fmt.Errorf("failed to save webhook message: %w", err)
+	}
+
+	r.logger.Debug().
+		Str("message_id", msg.ID).
+		Str("channel_id", msg.ChannelID).
+		Str("webhook_id", msg.WebhookID).
+		Msg("webhook message saved")
+
+	return nil
+}
+
+// Import inserts a message with its original timestamps, as when migrating history
+// from another platform. It reports false if a message with the same ID already exists.
+// Complexity: O(1) + O(log n) FTS index update via trigger
+func (r *Repository) Import(ctx context.Context, msg *Message, createdAt time.Time, editedAt *time.Time) (bool, error) {
+	var edited interface{}
+	if editedAt != nil {
+		edited = editedAt.UTC()
+	}
+	res, err := r.db.ExecContext(ctx,
+		`INSERT INTO messages (id, channel_id, author_id, content, type, edited_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO NOTHING`,
+		msg.ID, msg.ChannelID, msg.AuthorID, msg.Content, msg.Type, edited, createdAt.UTC(),
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to import message: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to import message: %w", err)
+	}
+	return n > 0, nil
+}
+
+// SaveAttachment records the metadata of a stored file attached to a message.
+// It reports false if an attachment with the same ID already exists.
+// Complexity: O(1)
+func (r *Repository) SaveAttachment(ctx context.Context, messageID string, a *Attachment, localPath string) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		`INSERT INTO attachments (id, message_id, filename, size_bytes, mime_type, hash, local_path, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT (id) DO NOTHING`,
+		a.ID, messageID, a.Filename, a.SizeBytes, a.MimeType, a.Hash, localPath,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to save attachment: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to save attachment: %w", err)
+	}
+	return n > 0, nil
+}
+
 // GetByID retrieves a single message by ID with author info.
 // Complexity: O(1)
 func (r *Repository) GetByID(ctx context.Context, id string) (*Message, error) {
 	query := `SELECT m.id, m.channel_id, m.author_id, m.content, m.type, m.edited_at, m.created_at,
-			u.username, COALESCE(u.avatar_url, '')
+			COALESCE(m.override_name, u.username), COALESCE(m.override_avatar, u.avatar_url, ''),
+			m.author_type, COALESCE(m.webhook_id, '')
 		FROM messages m
 		INNER JOIN users u ON m.author_id = u.id
 		WHERE m.id = ?`
@@ -62,7 +136,7 @@ func (r *Repository) GetByID(ctx context.Context, id string) (*Message, error) {
 	var editedAt sql.NullTime
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&msg.ID, &msg.ChannelID, &msg.AuthorID, &msg.Content, &msg.Type,
-		&editedAt, &msg.CreatedAt, &msg.AuthorName, &msg.AuthorAvatar,
+		&editedAt, &msg.CreatedAt, &msg.AuthorName, &msg.AuthorAvatar, &msg.AuthorType, &msg.WebhookID,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -92,7 +166,8 @@ func (r *Repository) GetByChannel(ctx context.Context, channelID string, opts Pa
 	if opts.Before != "" {
 		// Load messages older than the given message
 		query = `SELECT m.id, m.channel_id, m.author_id, m.content, m.type, m.edited_at, m.created_at,
-				u.username, COALESCE(u.avatar_url, '')
+				COALESCE(m.override_name, u.username), COALESCE(m.override_avatar, u.avatar_url, ''),
+				m.author_type, COALESCE(m.webhook_id, '')
 			FROM messages m
 			INNER JOIN users u ON m.author_id = u.id
 			WHERE m.channel_id = ? AND m.created_at < (SELECT created_at FROM messages WHERE id = ?)
@@ -102,7 +177,8 @@ func (r *Repository) GetByChannel(ctx context.Context, channelID string, opts Pa
 	} else if opts.After != "" {
 		// Load messages newer than the given message
 		query = `SELECT m.id, m.channel_id, m.author_id, m.content, m.type, m.edited_at, m.created_at,
-				u.username, COALESCE(u.avatar_url, '')
+				COALESCE(m.override_name, u.username), COALESCE(m.override_avatar, u.avatar_url, ''),
+				m.author_type, COALESCE(m.webhook_id, '')
 			FROM messages m
 			INNER JOIN users u ON m.author_id = u.id
 			WHERE m.channel_id = ? AND m.created_at >= (SELECT created_at FROM messages WHERE id = ?)
@@ -112,7 +188,8 @@ func (r *Repository) GetByChannel(ctx context.Context, channelID string, opts Pa
 	} else {
 		// Load most recent messages
 		query = `SELECT m.id, m.channel_id, m.author_id, m.content, m.type, m.edited_at, m.created_at,
-				u.username, COALESCE(u.avatar_url, '')
+				COALESCE(m.override_name, u.username), COALESCE(m.override_avatar, u.avatar_url, ''),
+				m.author_type, COALESCE(m.webhook_id, '')
 			FROM messages m
 			INNER JOIN users u ON m.author_id = u.id
 			WHERE m.channel_id = ?
@@ -133,7 +210,58 @@ func (r *Repository) GetByChannel(ctx context.Context, channelID string, opts Pa
 		var editedAt sql.NullTime
 		if err := rows.Scan(
 			&msg.ID, &msg.ChannelID, &msg.AuthorID, &msg.Content, &msg.Type,
-			&editedAt, &msg.CreatedAt, &msg.AuthorName, &msg.AuthorAvatar,
+			&editedAt, &msg.CreatedAt, &msg.AuthorName, &msg.AuthorAvatar, &msg.AuthorType, &msg.WebhookID,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan message: %w", err)
+		}
+		if editedAt.Valid {
+			s := editedAt.Time.UTC().Format(time.RFC3339)
+			msg.EditedAt = &s
+		}
+		messages = append(messages, &msg)
+	}
+
+	return messages, rows.Err()
+}
+
+// ListAfter returns up to limit messages of a channel in chronological order,
+// starting after the message afterID (from the beginning when empty). The
+// (created_at, id) cursor keeps pages stable when timestamps collide, so callers
+// can walk a whole channel page by page.
+// Complexity: O(log n + limit) with the channel/created_at index
+func (r *Repository) ListAfter(ctx context.Context, channelID, afterID string, limit int) ([]*Message, error) {
+	if limit <= 0 || limit > maxListLimit {
+		limit = maxListLimit
+	}
+
+	query := `SELECT m.id, m.channel_id, m.author_id, m.content, m.type, m.edited_at, m.created_at,
+			COALESCE(m.override_name, u.username), COALESCE(m.override_avatar, u.avatar_url, ''),
+			m.author_type, COALESCE(m.webhook_id, '')
+		FROM messages m
+		INNER JOIN users u ON m.author_id = u.id
+		WHERE m.channel_id = ?`
+	args := []interface{}{channelID}
+	if afterID != "" {
+		query += ` AND (m.created_at > (SELECT created_at FROM messages WHERE id = ?)
+			OR (m.created_at = (SELECT created_at FROM messages WHERE id = ?) AND m.id > ?))`
+		args = append(args, afterID, afterID, afterID)
+	}
+	query += ` ORDER BY m.created_at ASC, m.id ASC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list messages: %w", err)
+	}
+	defer rows.Close()
+
+	messages := make([]*Message, 0, limit)
+	for rows.Next() {
+		var msg Message
+		var editedAt sql.NullTime
+		if err := rows.Scan(
+			&msg.ID, &msg.ChannelID, &msg.AuthorID, &msg.Content, &msg.Type,
+			&editedAt, &msg.CreatedAt, &msg.AuthorName, &msg.AuthorAvatar, &msg.AuthorType, &msg.WebhookID,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan message: %w", err)
 		}
@@ -147,6 +275,34 @@ func (r *Repository) GetByChannel(ctx context.Context, channelID string, opts Pa
 	return messages, rows.Err()
 }
 
+// GetAttachments returns the attachment metadata of messageIDs, keyed by message ID.
+// Complexity: O(k) where k = attachments on the messages
+func (r *Repository) GetAttachments(ctx context.Context, messageIDs []string) (map[string][]*Attachment, error) {
+	result := make(map[string][]*Attachment)
+	if len(messageIDs) == 0 {
+		return result, nil
+	}
+
+	placeholders, args := inClause(messageIDs)
+	rows, err := r.db.QueryContext(ctx, `SELECT id, message_id, filename, size_bytes, mime_type, hash
+		FROM attachments WHERE message_id IN (`+placeholders+`)
+		ORDER BY created_at ASC, id ASC`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachments: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var a Attachment
+		var messageID string
+		if err := rows.Scan(&a.ID, &messageID, &a.Filename, &a.SizeBytes, &a.MimeType, &a.Hash); err != nil {
+			return nil, fmt.Errorf("failed to scan attachment: %w", err)
+		}
+		result[messageID] = append(result[messageID], &a)
+	}
+	return result, rows.Err()
+}
+
 // Update modifies the content of an existing message and sets edited_at.
 // Complexity: O(1) + O(log n) FTS update via trigger
 func (r *Repository) Update(ctx context.Context, id, content string) error {
@@ -183,64 +339,269 @@ func (r *Repository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
-// Search performs full-text search across messages in a channel using FTS5.
-// Complexity: O(log n) — FTS5 inverted index lookup
-func (r *Repository) Search(ctx context.Context, channelID, query string, limit int) ([]*SearchResult, error) {
-	if limit <= 0 || limit > 50 {
-		limit = 20
+// CountByChannel returns the total message count for a channel.
+// Complexity: O(1) with index
+func (r *Repository) CountByChannel(ctx context.Context, channelID string) (int64, error) {
+	query := `SELECT COUNT(*) FROM messages WHERE channel_id = ?`
+	var count int64
+	err := r.db.QueryRowContext(ctx, query, channelID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count messages: %w", err)
 	}
+	return count, nil
+}
 
-	sqlQuery := `SELECT m.id, m.channel_id, m.author_id, m.content, m.type, m.edited_at, m.created_at,
-			u.username, COALESCE(u.avatar_url, ''),
-			snippet(messages_fts, 0, '<mark>', '</mark>', '...', 32) as snippet
-		FROM messages_fts
-		INNER JOIN messages m ON messages_fts.rowid = m.rowid
-		INNER JOIN users u ON m.author_id = u.id
-		WHERE m.channel_id = ? AND messages_fts MATCH ?
-		ORDER BY rank
-		LIMIT ?`
+// ReplaceEmbeds stores the link previews of a message, replacing any previous set.
+// Complexity: O(k) where k = number of embeds
+func (r *Repository) ReplaceEmbeds(ctx context.Context, messageID string, embeds []*linkpreview.Preview) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM message_embeds WHERE message_id = ?`, messageID); err != nil {
+		return fmt.Errorf("failed to clear embeds: %w", err)
+	}
 
-	rows, err := r.db.QueryContext(ctx, sqlQuery, channelID, query, limit)
+	query := `INSERT INTO message_embeds (message_id, position, url, title, description, image_url, site_name, provider, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)`
+	for i, e := range embeds {
+		if _, err := r.db.ExecContext(ctx, query,
+			messageID, i, e.URL, e.Title, e.Description, e.ImageURL, e.SiteName, e.Provider,
+		); err != nil {
+			return fmt.Errorf("failed to save embed: %w", err)
+		}
+	}
+
+	r.logger.Debug().Str("message_id", messageID).Int("embeds", len(embeds)).Msg("message embeds saved")
+	return nil
+}
+
+// GetEmbeds loads the link previews for a batch of messages, keyed by message ID.
+// Complexity: O(k) where k = number of embeds for the given messages
+func (r *Repository) GetEmbeds(ctx context.Context, messageIDs []string) (map[string][]*linkpreview.Preview, error) {
+	result := make(map[string][]*linkpreview.Preview)
+	if len(messageIDs) == 0 {
+		return result, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(messageIDs)), ",")
+	query := `SELECT message_id, url, title, description, image_url, site_name, provider
+		FROM message_embeds
+		WHERE message_id IN (` + placeholders + `)
+		ORDER BY message_id, position`
+
+	args := make([]interface{}, len(messageIDs))
+	for i, id := range messageIDs {
+		args[i] = id
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to search messages: %w", err)
+		return nil, fmt.Errorf("failed to get embeds: %w", err)
 	}
 	defer rows.Close()
 
-	var results []*SearchResult
 	for rows.Next() {
-		var sr SearchResult
-		var editedAt sql.NullTime
-		if err := rows.Scan(
-			&sr.ID, &sr.ChannelID, &sr.AuthorID, &sr.Content, &sr.Type,
-			&editedAt, &sr.CreatedAt, &sr.AuthorName, &sr.AuthorAvatar,
-			&sr.Snippet,
-		); err != nil {
-			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		var messageID string
+		var e linkpreview.Preview
+		if err := rows.Scan(&messageID, &e.URL, &e.Title, &e.Description, &e.ImageURL, &e.SiteName, &e.Provider); err != nil {
+			return nil, fmt.Errorf("failed to scan embed: %w", err)
 		}
-		if editedAt.Valid {
-			s := editedAt.Time.UTC().Format(time.RFC3339)
-			sr.EditedAt = &s
+		result[messageID] = append(result[messageID], &e)
+	}
+	return result, rows.Err()
+}
+
+// SavePoll stores the settings and options of a poll message. Option IDs must be set.
+// Complexity: O(k) where k = number of options
+func (r *Repository) SavePoll(ctx context.Context, messageID string, poll *Poll, closesAt *time.Time) error {
+	var closes interface{}
+	if closesAt != nil {
+		closes = closesAt.UTC()
+	}
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO polls (message_id, multiple_choice, anonymous, closes_at, created_at)
+		VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP)`,
+		messageID, poll.MultipleChoice, poll.Anonymous, closes,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save poll: %w", err)
+	}
+
+	query := `INSERT INTO poll_options (id, message_id, position, label) VALUES (?, ?, ?, ?)`
+	for i, opt := range poll.Options {
+		if _, err := r.db.ExecContext(ctx, query, opt.ID, messageID, i, opt.Label); err != nil {
+			return fmt.Errorf("failed to save poll option: %w", err)
 		}
-		results = append(results, &sr)
 	}
+	return nil
+}
+
+// GetPolls loads the poll definitions (without votes) for a batch of messages, keyed by message ID.
+// Complexity: O(k) where k = number of options for the given messages
+func (r *Repository) GetPolls(ctx context.Context, messageIDs []string) (map[string]*Poll, error) {
+	result := make(map[string]*Poll)
+	if len(messageIDs) == 0 {
+		return result, nil
+	}
+
+	placeholders, args := inClause(messageIDs)
+	query := `SELECT p.message_id, p.multiple_choice, p.anonymous, p.closes_at, o.id, o.label
+		FROM polls p
+		INNER JOIN poll_options o ON o.message_id = p.message_id
+		WHERE p.message_id IN (` + placeholders + `)
+		ORDER BY p.message_id, o.position`
 
-	r.logger.Info().
-		Str("channel_id", channelID).
-		Str("query", query).
-		Int("results", len(results)).
-		Msg("message search completed")
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get polls: %w", err)
+	}
+	defer rows.Close()
 
-	return results, rows.Err()
+	for rows.Next() {
+		var messageID string
+		var multiple, anonymous bool
+		var closesAt sql.NullTime
+		var opt PollOption
+		if err := rows.Scan(&messageID, &multiple, &anonymous, &closesAt, &opt.ID, &opt.Label); err != nil {
+			return nil, fmt.Errorf("failed to scan poll: %w", err)
+		}
+		poll := result[messageID]
+		if poll == nil {
+			poll = &Poll{MultipleChoice: multiple, Anonymous: anonymous}
+			if closesAt.Valid {
+				s := closesAt.Time.UTC().Format(time.RFC3339)
+				poll.ClosesAt = &s
+			}
+			result[messageID] = poll
+		}
+		poll.Options = append(poll.Options, &opt)
+	}
+	return result, rows.Err()
 }
 
-// CountByChannel returns the total message count for a channel.
-// Complexity: O(1) with index
-func (r *Repository) CountByChannel(ctx context.Context, channelID string) (int64, error) {
-	query := `SELECT COUNT(*) FROM messages WHERE channel_id = ?`
-	var count int64
-	err := r.db.QueryRowContext(ctx, query, channelID).Scan(&count)
+// GetPollVotes loads every vote cast on the given poll messages, oldest first.
+// Complexity: O(v) where v = number of votes
+func (r *Repository) GetPollVotes(ctx context.Context, messageIDs []string) ([]PollVote, error) {
+	if len(messageIDs) == 0 {
+		return nil, nil
+	}
+
+	placeholders, args := inClause(messageIDs)
+	query := `SELECT message_id, option_id, user_id FROM poll_votes
+		WHERE message_id IN (` + placeholders + `)
+		ORDER BY created_at, user_id`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return 0, fmt.Errorf("failed to count messages: %w", err)
+		return nil, fmt.Errorf("failed to get poll votes: %w", err)
 	}
-	return count, nil
+	defer rows.Close()
+
+	var votes []PollVote
+	for rows.Next() {
+		var v PollVote
+		if err := rows.Scan(&v.MessageID, &v.OptionID, &v.UserID); err != nil {
+			return nil, fmt.Errorf("failed to scan poll vote: %w", err)
+		}
+		votes = append(votes, v)
+	}
+	return votes, rows.Err()
+}
+
+// ReplacePollVotes replaces a user's votes on a poll. An empty optionIDs retracts the vote.
+// Complexity: O(k) where k = number of chosen options
+func (r *Repository) ReplacePollVotes(ctx context.Context, messageID, userID string, optionIDs []string) error {
+	if _, err := r.db.ExecContext(ctx,
+		`DELETE FROM poll_votes WHERE message_id = ? AND user_id = ?`, messageID, userID,
+	); err != nil {
+		return fmt.Errorf("failed to clear poll votes: %w", err)
+	}
+
+	query := `INSERT INTO poll_votes (message_id, option_id, user_id, created_at) VALUES (?, ?, ?, CURRENT_TIMESTAMP)`
+	for _, optionID := range optionIDs {
+		if _, err := r.db.ExecContext(ctx, query, messageID, optionID, userID); err != nil {
+			return fmt.Errorf("failed to save poll vote: %w", err)
+		}
+	}
+	return nil
+}
+
+// ClosePoll sets the close time of a poll.
+// Complexity: O(1)
+func (r *Repository) ClosePoll(ctx context.Context, messageID string, at time.Time) error {
+	if _, err := r.db.ExecContext(ctx,
+		`UPDATE polls SET closes_at = ? WHERE message_id = ?`, at.UTC(), messageID,
+	); err != nil {
+		return fmt.Errorf("failed to close poll: %w", err)
+	}
+	return nil
+}
+
+// AddReaction records a reaction; adding the same one twice is a no-op.
+// emoji is the Unicode emoji, or the custom emoji ID when emojiID is set.
+// Complexity: O(1)
+func (r *Repository) AddReaction(ctx context.Context, messageID, userID, emoji, emojiID string, at time.Time) error {
+	var customID interface{}
+	if emojiID != "" {
+		customID = emojiID
+	}
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO message_reactions (message_id, user_id, emoji, emoji_id, created_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (message_id, user_id, emoji) DO NOTHING`,
+		messageID, userID, emoji, customID, at.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to add reaction: %w", err)
+	}
+	return nil
+}
+
+// RemoveReaction deletes a user's reaction.
+// Complexity: O(1)
+func (r *Repository) RemoveReaction(ctx context.Context, messageID, userID, emoji string) error {
+	if _, err := r.db.ExecContext(ctx,
+		`DELETE FROM message_reactions WHERE message_id = ? AND user_id = ? AND emoji = ?`, messageID, userID, emoji,
+	); err != nil {
+		return fmt.Errorf("failed to remove reaction: %w", err)
+	}
+	return nil
+}
+
+// GetReactions returns the reactions on the given messages, oldest first, with
+// the current name of custom emoji.
+// Complexity: O(r) where r = number of reactions on the messages
+func (r *Repository) GetReactions(ctx context.Context, messageIDs []string) ([]ReactionRow, error) {
+	if len(messageIDs) == 0 {
+		return nil, nil
+	}
+
+	placeholders, args := inClause(messageIDs)
+	query := `SELECT r.message_id, r.emoji, COALESCE(r.emoji_id, ''), COALESCE(e.name, ''), r.user_id
+		FROM message_reactions r
+		LEFT JOIN server_emoji e ON e.id = r.emoji_id
+		WHERE r.message_id IN (` + placeholders + `)
+		ORDER BY r.created_at, r.user_id`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reactions: %w", err)
+	}
+	defer rows.Close()
+
+	var reactions []ReactionRow
+	for rows.Next() {
+		var row ReactionRow
+		if err := rows.Scan(&row.MessageID, &row.Emoji, &row.EmojiID, &row.Name, &row.UserID); err != nil {
+			return nil, fmt.Errorf("failed to scan reaction: %w", err)
+		}
+		reactions = append(reactions, row)
+	}
+	return reactions, rows.Err()
+}
+
+// inClause returns "?,?,..." and the matching arguments for an IN list.
+func inClause(ids []string) (string, []interface{}) {
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return strings.TrimSuffix(strings.Repeat("?,", len(ids)), ","), args
 }
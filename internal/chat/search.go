@@ -0,0 +1,344 @@
+package chat
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	maxSearchQueryLength = 512
+	maxSearchTerms       = 32
+	searchDateLayout     = "2006-01-02"
+)
+
+// ErrInvalidQuery is returned (wrapped) when a search query cannot be parsed.
+var ErrInvalidQuery = errors.New("invalid search query")
+
+// SearchQuery is a parsed search expression.
+//
+// Syntax: free words and "quoted phrases" (all must match), -word / -"phrase"
+// to exclude, plus the filters from:user, in:#channel, has:file|link|embed|mention,
+// mentions:user, before:YYYY-MM-DD and after:YYYY-MM-DD. Filter values may be quoted.
+// Repeating a filter ORs its values (from:alice from:bob).
+type SearchQuery struct {
+	Terms    []string // single words that must match
+	Phrases  []string // multi-word phrases that must match in order
+	Excluded []string // words or phrases that must not match
+
+	From     []string // author usernames or user IDs
+	In       []string // channel names (without '#') or channel IDs
+	Has      []string // "file", "link", "embed", "mention"
+	Mentions []string // mentioned usernames or user IDs
+	Before   *time.Time
+	After    *time.Time
+}
+
+// SearchScope restricts which channels a search covers.
+// With neither field set the search covers every server the user belongs to.
+type SearchScope struct {
+	ChannelID string `json:"channel_id,omitempty"`
+	ServerID  string `json:"server_id,omitempty"`
+}
+
+var validHas = map[string]bool{"file": true, "link": true, "embed": true, "mention": true}
+
+// ParseSearchQuery parses raw user input into a SearchQuery.
+// Unknown key:value pairs (e.g. URLs) are treated as plain text.
+// Complexity: O(n) where n = len(raw)
+func ParseSearchQuery(raw string) (*SearchQuery, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, fmt.Errorf("%w: query cannot be empty", ErrInvalidQuery)
+	}
+	if len(raw) > maxSearchQueryLength {
+		return nil, fmt.Errorf("%w: query exceeds %d characters", ErrInvalidQuery, maxSearchQueryLength)
+	}
+
+	q := &SearchQuery{}
+	tokens, err := tokenizeSearch(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tok := range tokens {
+		if tok.key != "" {
+			if err := q.applyFilter(tok.key, tok.value); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		words := searchWords(tok.value)
+		if len(words) == 0 {
+			continue
+		}
+		text := strings.Join(words, " ")
+		switch {
+		case tok.negated:
+			q.Excluded = append(q.Excluded, text)
+		case len(words) > 1:
+			q.Phrases = append(q.Phrases, text)
+		default:
+			q.Terms = append(q.Terms, text)
+		}
+	}
+
+	if len(q.Terms)+len(q.Phrases)+len(q.Excluded) > maxSearchTerms {
+		return nil, fmt.Errorf("%w: too many search terms (max %d)", ErrInvalidQuery, maxSearchTerms)
+	}
+	if q.HasText() && len(q.Terms)+len(q.Phrases) == 0 {
+		return nil, fmt.Errorf("%w: exclusions need at least one search term", ErrInvalidQuery)
+	}
+	if !q.HasText() && !q.HasFilters() {
+		return nil, fmt.Errorf("%w: query has no searchable terms", ErrInvalidQuery)
+	}
+	if q.Before != nil && q.After != nil && !q.After.Before(*q.Before) {
+		return nil, fmt.Errorf("%w: after: must be earlier than before:", ErrInvalidQuery)
+	}
+	return q, nil
+}
+
+func (q *SearchQuery) applyFilter(key, value string) error {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return fmt.Errorf("%w: %s: needs a value", ErrInvalidQuery, key)
+	}
+	switch key {
+	case "from":
+		q.From = append(q.From, normalizeUserRef(value))
+	case "mentions":
+		q.Mentions = append(q.Mentions, normalizeUserRef(value))
+	case "in":
+		if strings.HasPrefix(value, "<#") && strings.HasSuffix(value, ">") {
+			value = value[2 : len(value)-1]
+		}
+		q.In = append(q.In, strings.TrimPrefix(value, "#"))
+	case "has":
+		value = strings.ToLower(value)
+		if !validHas[value] {
+			return fmt.Errorf("%w: has: must be file, link, embed or mention", ErrInvalidQuery)
+		}
+		q.Has = append(q.Has, value)
+	case "before", "after":
+		t, err := time.Parse(searchDateLayout, value)
+		if err != nil {
+			return fmt.Errorf("%w: %s: expects a date like 2026-01-31", ErrInvalidQuery, key)
+		}
+		if key == "before" {
+			q.Before = &t
+		} else {
+			// after: is exclusive of the given day
+			next := t.AddDate(0, 0, 1)
+			q.After = &next
+		}
+	}
+	return nil
+}
+
+// HasText reports whether the query contains full-text terms.
+func (q *SearchQuery) HasText() bool {
+	return len(q.Terms)+len(q.Phrases)+len(q.Excluded) > 0
+}
+
+// HasFilters reports whether the query contains any key:value filter.
+func (q *SearchQuery) HasFilters() bool {
+	return len(q.From)+len(q.In)+len(q.Has)+len(q.Mentions) > 0 || q.Before != nil || q.After != nil
+}
+
+// FTS5 compiles the text part of the query into an SQLite FTS5 MATCH expression.
+// Every word is emitted as a quoted string so user input can never inject FTS5 operators.
+// Complexity: O(n)
+func (q *SearchQuery) FTS5() string {
+	parts := make([]string, 0, len(q.Terms)+len(q.Phrases)+len(q.Excluded))
+	for _, t := range q.Terms {
+		parts = append(parts, fts5Quote(t))
+	}
+	for _, p := range q.Phrases {
+		parts = append(parts, fts5Quote(p))
+	}
+	expr := strings.Join(parts, " AND ")
+	for _, e := range q.Excluded {
+		expr += " NOT " + fts5Quote(e)
+	}
+	return expr
+}
+
+// TSQuery compiles the text part of the query into a PostgreSQL to_tsquery expression.
+// Words contain only letters and digits (see searchWords), so quoting them is sufficient.
+// Complexity: O(n)
+func (q *SearchQuery) TSQuery() string {
+	parts := make([]string, 0, len(q.Terms)+len(q.Phrases)+len(q.Excluded))
+	for _, t := range q.Terms {
+		parts = append(parts, tsPhrase(t))
+	}
+	for _, p := range q.Phrases {
+		parts = append(parts, tsPhrase(p))
+	}
+	for _, e := range q.Excluded {
+		parts = append(parts, "!("+tsPhrase(e)+")")
+	}
+	return strings.Join(parts, " & ")
+}
+
+// FilterSQL returns a WHERE fragment (with ? placeholders) for the key:value filters
+// and the channel restriction. It expects messages aliased as m and authors as u,
+// and only uses SQL that runs on both SQLite and PostgreSQL.
+// Complexity: O(f + c) where f = filters, c = channels
+func (q *SearchQuery) FilterSQL(channelIDs []string) (string, []interface{}) {
+	var clauses []string
+	var args []interface{}
+
+	clauses = append(clauses, "m.channel_id IN ("+placeholders(len(channelIDs))+")")
+	for _, id := range channelIDs {
+		args = append(args, id)
+	}
+
+	if len(q.From) > 0 {
+		var or []string
+		for _, f := range q.From {
+			or = append(or, "(LOWER(u.username) = LOWER(?) OR u.id = ?)")
+			args = append(args, f, f)
+		}
+		clauses = append(clauses, "("+strings.Join(or, " OR ")+")")
+	}
+
+	if len(q.Mentions) > 0 {
+		var or []string
+		for _, mention := range q.Mentions {
+			or = append(or, `(m.content LIKE '%<@' || ? || '>%'
+				OR m.content LIKE '%<@' || (SELECT mu.id FROM users mu WHERE LOWER(mu.username) = LOWER(?) LIMIT 1) || '>%')`)
+			args = append(args, mention, mention)
+		}
+		clauses = append(clauses, "("+strings.Join(or, " OR ")+")")
+	}
+
+	for _, h := range q.Has {
+		switch h {
+		case "file":
+			clauses = append(clauses, "(m.type = 'file' OR EXISTS (SELECT 1 FROM attachments a WHERE a.message_id = m.id))")
+		case "link":
+			clauses = append(clauses, "(m.content LIKE '%http://%' OR m.content LIKE '%https://%')")
+		case "embed":
+			clauses = append(clauses, "EXISTS (SELECT 1 FROM message_embeds e WHERE e.message_id = m.id)")
+		case "mention":
+			clauses = append(clauses, "m.content LIKE '%<@%'")
+		}
+	}
+
+	if q.Before != nil {
+		clauses = append(clauses, "m.created_at < ?")
+		args = append(args, q.Before.UTC().Format("2006-01-02 15:04:05"))
+	}
+	if q.After != nil {
+		clauses = append(clauses, "m.created_at >= ?")
+		args = append(args, q.After.UTC().Format("2006-01-02 15:04:05"))
+	}
+
+	return strings.Join(clauses, " AND "), args
+}
+
+// searchToken is one lexical unit of a raw query.
+type searchToken struct {
+	key     string // filter key, empty for text
+	value   string
+	negated bool
+}
+
+// tokenizeSearch splits raw input on whitespace, honoring double quotes and
+// recognizing key:value filters for the known keys.
+// Complexity: O(n)
+func tokenizeSearch(raw string) ([]searchToken, error) {
+	var tokens []searchToken
+	runes := []rune(raw)
+	i := 0
+	for i < len(runes) {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+
+		tok := searchToken{}
+		if runes[i] == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) {
+			tok.negated = true
+			i++
+		}
+
+		// key:value filter?
+		if !tok.negated {
+			if key, n := filterKeyAt(runes[i:]); n > 0 {
+				tok.key = key
+				i += n
+			}
+		}
+
+		if i < len(runes) && runes[i] == '"' {
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("%w: unterminated quote", ErrInvalidQuery)
+			}
+			tok.value = string(runes[i+1 : end])
+			i = end + 1
+		} else {
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) {
+				i++
+			}
+			tok.value = string(runes[start:i])
+		}
+		tokens = append(tokens, tok)
+	}
+	return tokens, nil
+}
+
+var searchFilterKeys = []string{"from", "in", "has", "before", "after", "mentions"}
+
+// filterKeyAt returns the filter key at the start of rs and the length of "key:".
+func filterKeyAt(rs []rune) (string, int) {
+	for _, k := range searchFilterKeys {
+		n := len(k)
+		if len(rs) > n && rs[n] == ':' && strings.EqualFold(string(rs[:n]), k) {
+			return k, n + 1
+		}
+	}
+	return "", 0
+}
+
+// searchWords splits text into lowercase words of letters and digits, matching how
+// both FTS5's unicode61 tokenizer and Postgres' parser break text apart.
+func searchWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// normalizeUserRef strips mention syntax: "<@id>" -> "id", "@name" -> "name".
+func normalizeUserRef(v string) string {
+	if strings.HasPrefix(v, "<@") && strings.HasSuffix(v, ">") {
+		return v[2 : len(v)-1]
+	}
+	return strings.TrimPrefix(v, "@")
+}
+
+func fts5Quote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+func tsPhrase(s string) string {
+	words := strings.Fields(s)
+	for i, w := range words {
+		words[i] = "'" + w + "'"
+	}
+	return strings.Join(words, " <-> ")
+}
+
+func placeholders(n int) string {
+	if n <= 0 {
+		return "NULL"
+	}
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
@@ -0,0 +1,108 @@
+package chat
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	q, err := ParseSearchQuery(`deploy "release notes" -draft from:@alice in:#general has:file mentions:<@u-2> after:2026-01-01 before:2026-02-01`)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"deploy"}, q.Terms)
+	assert.Equal(t, []string{"release notes"}, q.Phrases)
+	assert.Equal(t, []string{"draft"}, q.Excluded)
+	assert.Equal(t, []string{"alice"}, q.From)
+	assert.Equal(t, []string{"general"}, q.In)
+	assert.Equal(t, []string{"file"}, q.Has)
+	assert.Equal(t, []string{"u-2"}, q.Mentions)
+	require.NotNil(t, q.After)
+	require.NotNil(t, q.Before)
+	assert.Equal(t, time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), *q.After)
+	assert.Equal(t, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), *q.Before)
+}
+
+func TestParseSearchQuery_QuotedFilterValues(t *testing.T) {
+	q, err := ParseSearchQuery(`from:"Jane Doe" in:"<#ch-9>"`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Jane Doe"}, q.From)
+	assert.Equal(t, []string{"ch-9"}, q.In)
+	assert.False(t, q.HasText())
+	assert.True(t, q.HasFilters())
+}
+
+func TestParseSearchQuery_UnknownKeysAreText(t *testing.T) {
+	q, err := ParseSearchQuery(`https://example.com/a`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https example com a"}, q.Phrases)
+}
+
+func TestParseSearchQuery_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"empty", "   "},
+		{"unterminated quote", `"hello`},
+		{"bad has", "has:banana"},
+		{"bad date", "before:yesterday"},
+		{"empty filter", "from:"},
+		{"only exclusions", "-spam"},
+		{"only punctuation", "!!! ???"},
+		{"inverted range", "x after:2026-02-01 before:2026-01-01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSearchQuery(tt.query)
+			assert.ErrorIs(t, err, ErrInvalidQuery)
+		})
+	}
+}
+
+func TestSearchQuery_FTS5(t *testing.T) {
+	q, err := ParseSearchQuery(`hello "big world" -spam`)
+	require.NoError(t, err)
+	assert.Equal(t, `"hello" AND "big world" NOT "spam"`, q.FTS5())
+
+	// FTS5 operators in user input stay literal.
+	q, err = ParseSearchQuery(`NEAR(a b) OR *`)
+	require.NoError(t, err)
+	assert.Equal(t, `"b" AND "or" AND "near a"`, q.FTS5())
+}
+
+func TestSearchQuery_TSQuery(t *testing.T) {
+	q, err := ParseSearchQuery(`hello "big world" -spam`)
+	require.NoError(t, err)
+	assert.Equal(t, `'hello' & 'big' <-> 'world' & !('spam')`, q.TSQuery())
+
+	q, err = ParseSearchQuery(`it's a "te'st" | !x`)
+	require.NoError(t, err)
+	assert.NotContains(t, q.TSQuery(), "''")
+	assert.NotContains(t, q.TSQuery(), "|")
+}
+
+func TestSearchQuery_FilterSQL(t *testing.T) {
+	q, err := ParseSearchQuery(`from:alice from:bob has:link before:2026-01-01`)
+	require.NoError(t, err)
+
+	sql, args := q.FilterSQL([]string{"c1", "c2"})
+	assert.Contains(t, sql, "m.channel_id IN (?,?)")
+	assert.Contains(t, sql, "LOWER(u.username) = LOWER(?) OR u.id = ?) OR (LOWER(u.username)")
+	assert.Contains(t, sql, "LIKE '%https://%'")
+	assert.Contains(t, sql, "m.created_at < ?")
+	assert.Equal(t, []interface{}{"c1", "c2", "alice", "alice", "bob", "bob", "2026-01-01 00:00:00"}, args)
+}
+
+func TestSelectSearchChannels(t *testing.T) {
+	readable := map[string]string{"c1": "general", "c2": "random", "c3": "General"}
+
+	assert.Equal(t, []string{"c1", "c2", "c3"}, selectSearchChannels(readable, "", nil))
+	assert.Equal(t, []string{"c2"}, selectSearchChannels(readable, "c2", nil))
+	assert.Equal(t, []string{"c1", "c3"}, selectSearchChannels(readable, "", []string{"general"}))
+	assert.Equal(t, []string{"c2"}, selectSearchChannels(readable, "", []string{"c2"}))
+	assert.Empty(t, selectSearchChannels(readable, "c9", nil))
+	assert.Empty(t, selectSearchChannels(readable, "c2", []string{"general"}))
+}
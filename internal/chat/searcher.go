@@ -0,0 +1,13 @@
+package chat
+
+import "context"
+
+// Searcher runs parsed search queries against a store's full-text index.
+// Implementations live next to their store: sqlite.ChatSearcher (FTS5) and
+// postgres.ChatSearcher (tsvector). Both must satisfy the contract in package searchtest.
+type Searcher interface {
+	// Search returns up to limit messages from channelIDs matching q, ranked by
+	// relevance when q has text and newest first otherwise. Snippets highlight
+	// matches with <mark> tags. An empty channelIDs yields no results.
+	Search(ctx context.Context, q *SearchQuery, channelIDs []string, limit int) ([]*SearchResult, error)
+}
@@ -0,0 +1,190 @@
+// Package searchtest holds the behavioural contract every chat.Searcher must satisfy.
+// Store packages call Run from their own tests with a migrated database.
+package searchtest
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/concord-chat/concord/internal/chat"
+)
+
+// Execer runs statements written with ? placeholders (postgres callers pass an Adapter).
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// fixture IDs are randomized per run so the contract can share a database with other tests.
+type fixture struct {
+	prefix    string
+	server    string
+	general   string
+	random    string
+	alice     string
+	bob       string
+	bobName   string
+	aliceName string
+	messages  map[string]string // key -> message ID
+}
+
+func (f *fixture) id(name string) string { return f.prefix + name }
+
+// Run seeds a server with two channels and a handful of messages, then checks
+// ranking, highlighting, phrase/exclusion semantics, every filter, channel
+// scoping and limits against searcher.
+func Run(t *testing.T, db Execer, searcher chat.Searcher) {
+	t.Helper()
+	ctx := context.Background()
+	f := seed(t, ctx, db)
+
+	search := func(t *testing.T, raw string, channels ...string) []*chat.SearchResult {
+		t.Helper()
+		q, err := chat.ParseSearchQuery(raw)
+		require.NoError(t, err)
+		if len(channels) == 0 {
+			channels = []string{f.general, f.random}
+		}
+		results, err := searcher.Search(ctx, q, channels, 20)
+		require.NoError(t, err)
+		return results
+	}
+	ids := func(results []*chat.SearchResult) []string {
+		out := make([]string, len(results))
+		for i, r := range results {
+			out[i] = r.ID
+		}
+		return out
+	}
+
+	t.Run("ranks and highlights", func(t *testing.T) {
+		results := search(t, "deploy")
+		require.Len(t, results, 2)
+		assert.Equal(t, f.messages["deploy-heavy"], results[0].ID, "denser match ranks first")
+		assert.Contains(t, results[0].Snippet, "<mark>")
+		assert.Equal(t, f.server, results[0].ServerID)
+		assert.Equal(t, f.aliceName, results[0].AuthorName)
+		assert.NotEmpty(t, results[0].CreatedAt)
+	})
+
+	t.Run("all terms must match", func(t *testing.T) {
+		assert.Equal(t, []string{f.messages["deploy-light"]}, ids(search(t, "deploy staging")))
+	})
+
+	t.Run("phrase", func(t *testing.T) {
+		assert.Equal(t, []string{f.messages["phrase"]}, ids(search(t, `"release notes"`)))
+		assert.Empty(t, search(t, `"notes release"`))
+	})
+
+	t.Run("exclusion", func(t *testing.T) {
+		assert.Equal(t, []string{f.messages["deploy-heavy"]}, ids(search(t, "deploy -staging")))
+	})
+
+	t.Run("operators in input are literal", func(t *testing.T) {
+		assert.Empty(t, search(t, `deploy OR "NEAR(x"`))
+	})
+
+	t.Run("from", func(t *testing.T) {
+		assert.ElementsMatch(t, []string{f.messages["bob-link"]}, ids(search(t, "from:"+strings.ToUpper(f.bobName))))
+		assert.ElementsMatch(t, []string{f.messages["bob-link"]}, ids(search(t, "from:<@"+f.bob+">")))
+	})
+
+	t.Run("mentions", func(t *testing.T) {
+		assert.Equal(t, []string{f.messages["mention"]}, ids(search(t, "mentions:"+f.bobName)))
+		assert.Equal(t, []string{f.messages["mention"]}, ids(search(t, "has:mention")))
+	})
+
+	t.Run("has", func(t *testing.T) {
+		assert.Equal(t, []string{f.messages["bob-link"]}, ids(search(t, "has:link")))
+		assert.Equal(t, []string{f.messages["file"]}, ids(search(t, "has:file")))
+		assert.Equal(t, []string{f.messages["bob-link"]}, ids(search(t, "has:embed")))
+	})
+
+	t.Run("dates", func(t *testing.T) {
+		assert.Equal(t, []string{f.messages["old"]}, ids(search(t, "before:2025-01-01")))
+		assert.NotContains(t, ids(search(t, "after:2025-06-01")), f.messages["old"])
+	})
+
+	t.Run("filter-only queries are newest first", func(t *testing.T) {
+		results := search(t, "from:"+f.aliceName)
+		require.NotEmpty(t, results)
+		for i := 1; i < len(results); i++ {
+			assert.GreaterOrEqual(t, results[i-1].CreatedAt, results[i].CreatedAt)
+		}
+	})
+
+	t.Run("channel scope", func(t *testing.T) {
+		assert.Empty(t, search(t, "deploy", f.random))
+		assert.Equal(t, []string{f.messages["random"]}, ids(search(t, "lunch", f.random)))
+		assert.Empty(t, search(t, "lunch", f.general))
+
+		q, err := chat.ParseSearchQuery("deploy")
+		require.NoError(t, err)
+		results, err := searcher.Search(ctx, q, nil, 20)
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		q, err := chat.ParseSearchQuery("from:" + f.aliceName)
+		require.NoError(t, err)
+		results, err := searcher.Search(ctx, q, []string{f.general, f.random}, 1)
+		require.NoError(t, err)
+		assert.Len(t, results, 1)
+	})
+}
+
+func seed(t *testing.T, ctx context.Context, db Execer) *fixture {
+	t.Helper()
+	f := &fixture{prefix: "st-" + strings.ReplaceAll(uuid.New().String()[:8], "-", "") + "-", messages: map[string]string{}}
+	f.server, f.general, f.random = f.id("server"), f.id("general"), f.id("random")
+	f.alice, f.bob = f.id("alice"), f.id("bob")
+	// Usernames differ from IDs and are unique per run so name lookups cannot hit other rows.
+	f.aliceName, f.bobName = "alice_"+f.prefix[3:11], "bob_"+f.prefix[3:11]
+
+	exec := func(query string, args ...interface{}) {
+		t.Helper()
+		_, err := db.ExecContext(ctx, query, args...)
+		require.NoError(t, err)
+	}
+
+	githubBase := int64(uuid.New().ID())
+	exec(`INSERT INTO users (id, github_id, username) VALUES (?, ?, ?)`, f.alice, githubBase*2, f.aliceName)
+	exec(`INSERT INTO users (id, github_id, username) VALUES (?, ?, ?)`, f.bob, githubBase*2+1, f.bobName)
+	exec(`INSERT INTO servers (id, name, owner_id) VALUES (?, ?, ?)`, f.server, "Search Contract", f.alice)
+	exec(`INSERT INTO channels (id, server_id, name, type, position) VALUES (?, ?, ?, 'text', 0)`, f.general, f.server, "general")
+	exec(`INSERT INTO channels (id, server_id, name, type, position) VALUES (?, ?, ?, 'text', 1)`, f.random, f.server, "random")
+
+	t.Cleanup(func() {
+		// Channels and their messages cascade from the server.
+		_, _ = db.ExecContext(context.Background(), `DELETE FROM servers WHERE id = ?`, f.server)
+		_, _ = db.ExecContext(context.Background(), `DELETE FROM users WHERE id IN (?, ?)`, f.alice, f.bob)
+	})
+
+	msg := func(key, channel, author, msgType, content, createdAt string) {
+		t.Helper()
+		id := f.id(key)
+		exec(`INSERT INTO messages (id, channel_id, author_id, content, type, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+			id, channel, author, content, msgType, createdAt)
+		f.messages[key] = id
+	}
+
+	msg("old", f.general, f.alice, "text", "archived announcement from last year", "2024-06-01 10:00:00")
+	msg("deploy-heavy", f.general, f.alice, "text", "deploy deploy deploy finished", "2025-07-01 10:00:00")
+	msg("deploy-light", f.general, f.alice, "text", "we will deploy to staging after the review of several pending changes", "2025-07-01 11:00:00")
+	msg("phrase", f.general, f.alice, "text", "please read the release notes before upgrading", "2025-07-01 12:00:00")
+	msg("mention", f.general, f.alice, "text", "thanks <@"+f.bob+"> for the review", "2025-07-01 13:00:00")
+	msg("bob-link", f.general, f.bob, "text", "docs at https://example.com/guide", "2025-07-01 14:00:00")
+	msg("file", f.general, f.alice, "file", "screenshot.png", "2025-07-01 15:00:00")
+	msg("random", f.random, f.alice, "text", "anyone up for lunch", "2025-07-01 16:00:00")
+
+	exec(`INSERT INTO message_embeds (message_id, position, url, title) VALUES (?, 0, ?, ?)`,
+		f.messages["bob-link"], "https://example.com/guide", "Guide")
+
+	return f
+}
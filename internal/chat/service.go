@@ -2,21 +2,38 @@ package chat
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/rs/zerolog"
+
+	"github.com/concord-chat/concord/internal/markdown"
 )
 
 const (
 	maxMessageLength = 4000
 )
 
+// ErrInvalidMessage is returned (wrapped) when message content fails validation.
+var ErrInvalidMessage = errors.New("invalid message")
+
 // Service orchestrates chat operations.
 type Service struct {
-	repo   *Repository
-	logger zerolog.Logger
+	repo     *Repository
+	limiter  *SendLimiter  // optional rate limit / slow mode enforcement
+	unfurl   unfurlState   // optional link previews
+	access   ChannelAccess // optional, required for cross-channel search
+	searcher Searcher      // optional, required for search
+	policy   SendPolicy    // optional permission check for polls
+	events   EventSink     // optional, notified after a message is created
+	emoji    EmojiResolver // optional custom emoji of servers
+	mod      Moderation    // optional check and record of moderator deletions
+	logger   zerolog.Logger
+	now      func() time.Time
 }
 
 // NewService creates a new chat service.
@@ -24,17 +41,73 @@ func NewService(repo *Repository, logger zerolog.Logger) *Service {
 	return &Service{
 		repo:   repo,
 		logger: logger.With().Str("component", "chat_service").Logger(),
+		now:    time.Now,
 	}
 }
 
+// SetSendLimiter enables per-user rate limiting and channel slow mode for SendMessage.
+func (s *Service) SetSendLimiter(l *SendLimiter) {
+	s.limiter = l
+}
+
+// ChannelAccess resolves which channels a user may read.
+type ChannelAccess interface {
+	// ReadableChannels returns channel ID -> name for the readable text channels of
+	// serverID, or of every server the user belongs to when serverID is empty.
+	ReadableChannels(ctx context.Context, userID, serverID string) (map[string]string, error)
+}
+
+// EventSink receives chat events, e.g. to forward them to outgoing webhooks.
+// Calls are synchronous and must not block for long.
+type EventSink interface {
+	MessageCreated(ctx context.Context, msg *Message)
+}
+
+// SetEventSink registers the receiver of chat events.
+func (s *Service) SetEventSink(sink EventSink) {
+	s.events = sink
+}
+
+// Moderation checks and records deletions of other users' messages.
+type Moderation interface {
+	// CanManageMessages reports whether userID may delete others' messages in channelID.
+	CanManageMessages(ctx context.Context, channelID, userID string) (bool, error)
+	// MessageDeleted is called after actorID deleted a message written by authorID.
+	MessageDeleted(ctx context.Context, channelID, messageID, authorID, actorID string)
+}
+
+// SetModeration verifies the isManager flag of DeleteMessage against channel
+// permissions and records moderator deletions.
+func (s *Service) SetModeration(m Moderation) {
+	s.mod = m
+}
+
+// SetSearcher sets the full-text search backend matching the store in use.
+func (s *Service) SetSearcher(searcher Searcher) {
+	s.searcher = searcher
+}
+
+// SetChannelAccess enables scoped search across channels and servers.
+func (s *Service) SetChannelAccess(a ChannelAccess) {
+	s.access = a
+}
+
 // SendMessage creates and stores a new message.
+// Returns a *security.RateLimitError when the author is rate limited or in slow mode.
 func (s *Service) SendMessage(ctx context.Context, channelID, authorID, content string) (*Message, error) {
 	content = strings.TrimSpace(content)
 	if content == "" {
-		return nil, fmt.Errorf("message content cannot be empty")
+		return nil, fmt.Errorf("%w: content cannot be empty", ErrInvalidMessage)
 	}
 	if len(content) > maxMessageLength {
-		return nil, fmt.Errorf("message exceeds maximum length of %d characters", maxMessageLength)
+		return nil, fmt.Errorf("%w: content exceeds maximum length of %d characters", ErrInvalidMessage, maxMessageLength)
+	}
+	content = s.resolveEmoji(ctx, channelID, content)
+
+	if s.limiter != nil {
+		if err := s.limiter.Check(ctx, channelID, authorID); err != nil {
+			return nil, err
+		}
 	}
 
 	msg := &Message{
@@ -61,22 +134,119 @@ func (s *Service) SendMessage(ctx context.Context, channelID, authorID, content
 		Str("author_id", authorID).
 		Msg("message sent")
 
+	s.messageCreated(ctx, saved)
+	withMarkup(saved)
+	s.scheduleUnfurl(saved)
 	return saved, nil
 }
 
+// PostWebhookMessage stores a message posted through an incoming webhook. The
+// message is attributed to the webhook's creator (authorID) but displays name and
+// avatarURL. Webhooks share the per-user rate limit and channel slow mode, keyed by
+// webhook instead of user.
+func (s *Service) PostWebhookMessage(ctx context.Context, channelID, webhookID, authorID, name, avatarURL, content string) (*Message, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, fmt.Errorf("%w: content cannot be empty", ErrInvalidMessage)
+	}
+	if len(content) > maxMessageLength {
+		return nil, fmt.Errorf("%w: content exceeds maximum length of %d characters", ErrInvalidMessage, maxMessageLength)
+	}
+	content = s.resolveEmoji(ctx, channelID, content)
+
+	if s.limiter != nil {
+		if err := s.limiter.Check(ctx, channelID, AuthorWebhook+":"+webhookID); err != nil {
+			return nil, err
+		}
+	}
+
+	msg := &Message{
+		ID:           uuid.New().String(),
+		ChannelID:    channelID,
+		AuthorID:     authorID,
+		AuthorName:   name,
+		AuthorAvatar: avatarURL,
+		AuthorType:   AuthorWebhook,
+		WebhookID:    webhookID,
+		Content:      content,
+		Type:         "text",
+	}
+	if err := s.repo.SaveWebhookMessage(ctx, msg); err != nil {
+		return nil, fmt.Errorf("failed to post webhook message: %w", err)
+	}
+
+	saved, err := s.repo.GetByID(ctx, msg.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info().
+		Str("message_id", msg.ID).
+		Str("channel_id", channelID).
+		Str("webhook_id", webhookID).
+		Msg("webhook message posted")
+
+	s.messageCreated(ctx, saved)
+	withMarkup(saved)
+	s.scheduleUnfurl(saved)
+	return saved, nil
+}
+
+// resolveEmoji links the custom emoji tokens of content to the channel's server.
+// On failure the content is kept as written.
+func (s *Service) resolveEmoji(ctx context.Context, channelID, content string) string {
+	if s.emoji == nil {
+		return content
+	}
+	resolved, err := s.emoji.ResolveEmoji(ctx, channelID, content)
+	if err != nil {
+		s.logger.Warn().Err(err).Str("channel_id", channelID).Msg("failed to resolve custom emoji")
+		return content
+	}
+	return resolved
+}
+
+// messageCreated notifies the event sink, if any, of a new message.
+func (s *Service) messageCreated(ctx context.Context, msg *Message) {
+	if s.events != nil {
+		s.events.MessageCreated(ctx, msg)
+	}
+}
+
+// GetMessage retrieves a single message by ID. Returns nil if not found.
+func (s *Service) GetMessage(ctx context.Context, messageID string) (*Message, error) {
+	msg, err := s.repo.GetByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	withMarkup(msg)
+	s.withEmbeds(ctx, msg)
+	s.withPolls(ctx, msg)
+	s.withReactions(ctx, msg)
+	return msg, nil
+}
+
 // GetMessages retrieves messages for a channel with cursor-based pagination.
 func (s *Service) GetMessages(ctx context.Context, channelID string, opts PaginationOpts) ([]*Message, error) {
-	return s.repo.GetByChannel(ctx, channelID, opts)
+	msgs, err := s.repo.GetByChannel(ctx, channelID, opts)
+	if err != nil {
+		return nil, err
+	}
+	withMarkup(msgs...)
+	s.withEmbeds(ctx, msgs...)
+	s.withPolls(ctx, msgs...)
+	s.withReactions(ctx, msgs...)
+	return msgs, nil
 }
 
 // EditMessage updates the content of a message. Only the author can edit.
 func (s *Service) EditMessage(ctx context.Context, messageID, authorID, content string) (*Message, error) {
 	content = strings.TrimSpace(content)
 	if content == "" {
-		return nil, fmt.Errorf("message content cannot be empty")
+		return nil, fmt.Errorf("%w: content cannot be empty", ErrInvalidMessage)
 	}
 	if len(content) > maxMessageLength {
-		return nil, fmt.Errorf("message exceeds maximum length of %d characters", maxMessageLength)
+		return nil, fmt.Errorf("%w: content exceeds maximum length of %d characters", ErrInvalidMessage, maxMessageLength)
 	}
 
 	existing, err := s.repo.GetByID(ctx, messageID)
@@ -89,6 +259,14 @@ func (s *Service) EditMessage(ctx context.Context, messageID, authorID, content
 	if existing.AuthorID != authorID {
 		return nil, fmt.Errorf("only the author can edit a message")
 	}
+	if existing.Type == "poll" {
+		return nil, fmt.Errorf("polls cannot be edited")
+	}
+	if existing.AuthorType == AuthorWebhook {
+		return nil, fmt.Errorf("webhook messages cannot be edited")
+	}
+
+	content = s.resolveEmoji(ctx, existing.ChannelID, content)
 
 	if err := s.repo.Update(ctx, messageID, content); err != nil {
 		return nil, err
@@ -104,11 +282,14 @@ func (s *Service) EditMessage(ctx context.Context, messageID, authorID, content
 		Str("author_id", authorID).
 		Msg("message edited")
 
+	withMarkup(updated)
+	s.scheduleUnfurl(updated)
 	return updated, nil
 }
 
-// DeleteMessage removes a message. The author or someone with PermManageMessages can delete.
-// For simplicity in Phase 4, only the author can delete. Permission-based delete uses isManager flag.
+// DeleteMessage removes a message. The author can always delete; others need
+// isManager, which is checked against the channel's permissions when a
+// Moderation is set. Deletions by others are reported to the Moderation.
 func (s *Service) DeleteMessage(ctx context.Context, messageID, actorID string, isManager bool) error {
 	existing, err := s.repo.GetByID(ctx, messageID)
 	if err != nil {
@@ -118,13 +299,23 @@ func (s *Service) DeleteMessage(ctx context.Context, messageID, actorID string,
 		return fmt.Errorf("message not found")
 	}
 
-	if existing.AuthorID != actorID && !isManager {
+	moderated := existing.AuthorID != actorID
+	if moderated && isManager && s.mod != nil {
+		isManager, err = s.mod.CanManageMessages(ctx, existing.ChannelID, actorID)
+		if err != nil {
+			return fmt.Errorf("failed to check permissions: %w", err)
+		}
+	}
+	if moderated && !isManager {
 		return fmt.Errorf("insufficient permissions to delete this message")
 	}
 
 	if err := s.repo.Delete(ctx, messageID); err != nil {
 		return err
 	}
+	if moderated && s.mod != nil {
+		s.mod.MessageDeleted(ctx, existing.ChannelID, messageID, existing.AuthorID, actorID)
+	}
 
 	s.logger.Info().
 		Str("message_id", messageID).
@@ -135,11 +326,99 @@ func (s *Service) DeleteMessage(ctx context.Context, messageID, actorID string,
 	return nil
 }
 
-// SearchMessages performs FTS5 search within a channel.
+// SearchMessages searches a single channel with the query language described on SearchQuery.
+// It does not check channel access; callers that accept user input should use Search.
 func (s *Service) SearchMessages(ctx context.Context, channelID, query string, limit int) ([]*SearchResult, error) {
-	query = strings.TrimSpace(query)
-	if query == "" {
-		return nil, fmt.Errorf("search query cannot be empty")
+	q, err := ParseSearchQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	return s.runSearch(ctx, q, []string{channelID}, limit)
+}
+
+// Search runs a query for userID over the channel, server or (with an empty scope)
+// all servers the user can read. Returns ErrInvalidQuery (wrapped) for malformed queries.
+func (s *Service) Search(ctx context.Context, userID string, scope SearchScope, query string, limit int) ([]*SearchResult, error) {
+	q, err := ParseSearchQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	if s.access == nil {
+		return nil, fmt.Errorf("search access control not configured")
+	}
+
+	channels, err := s.access.ReadableChannels(ctx, userID, scope.ServerID)
+	if err != nil {
+		return nil, err
+	}
+	channelIDs := selectSearchChannels(channels, scope.ChannelID, q.In)
+	if scope.ChannelID != "" && len(channelIDs) == 0 && len(q.In) == 0 {
+		return nil, fmt.Errorf("channel not found or not accessible")
+	}
+
+	results, err := s.runSearch(ctx, q, channelIDs, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Debug().
+		Str("user_id", userID).
+		Str("server_id", scope.ServerID).
+		Str("channel_id", scope.ChannelID).
+		Int("channels", len(channelIDs)).
+		Int("results", len(results)).
+		Msg("scoped search completed")
+
+	return results, nil
+}
+
+func (s *Service) runSearch(ctx context.Context, q *SearchQuery, channelIDs []string, limit int) ([]*SearchResult, error) {
+	if s.searcher == nil {
+		return nil, fmt.Errorf("search backend not configured")
+	}
+	results, err := s.searcher.Search(ctx, q, channelIDs, limit)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range results {
+		withMarkup(&r.Message)
+	}
+	return results, nil
+}
+
+// selectSearchChannels narrows the readable channels (ID -> name) to the scope
+// channel and any in: filters, which match channel names case-insensitively or IDs.
+// Complexity: O(c * f) where c = channels, f = in: filters
+func selectSearchChannels(readable map[string]string, scopeChannel string, in []string) []string {
+	ids := make([]string, 0, len(readable))
+	for id, name := range readable {
+		if scopeChannel != "" && id != scopeChannel {
+			continue
+		}
+		if len(in) > 0 {
+			match := false
+			for _, f := range in {
+				if id == f || strings.EqualFold(name, f) {
+					match = true
+					break
+				}
+			}
+			if !match {
+				continue
+			}
+		}
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// withMarkup parses the content of each message into formatting entities.
+// Complexity: O(total content length)
+func withMarkup(msgs ...*Message) {
+	for _, m := range msgs {
+		if m != nil {
+			m.Markup = markdown.Parse(m.Content)
+		}
 	}
-	return s.repo.Search(ctx, channelID, query, limit)
 }
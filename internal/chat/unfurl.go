@@ -0,0 +1,155 @@
+package chat
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/concord-chat/concord/internal/linkpreview"
+	"github.com/concord-chat/concord/internal/markdown"
+)
+
+const (
+	maxEmbedsPerMessage = 3
+	unfurlTimeout       = 15 * time.Second
+)
+
+// Unfurler fetches a link preview for a URL.
+type Unfurler interface {
+	Fetch(ctx context.Context, rawURL string) (*linkpreview.Preview, error)
+}
+
+// PreviewPreferences reports whether a user has link previews enabled.
+type PreviewPreferences interface {
+	LinkPreviewsEnabled(ctx context.Context, userID string) bool
+}
+
+// EmbedListener is notified once previews for a message have been stored.
+type EmbedListener func(messageID, channelID string, embeds []*linkpreview.Preview)
+
+// unfurlState holds the optional link preview dependencies of the service.
+type unfurlState struct {
+	unfurler Unfurler
+	prefs    PreviewPreferences // nil = previews always enabled
+	listener EmbedListener
+}
+
+// SetUnfurler enables asynchronous link previews for sent and edited messages.
+// prefs may be nil, in which case previews are fetched for every author.
+func (s *Service) SetUnfurler(u Unfurler, prefs PreviewPreferences) {
+	s.unfurl.unfurler = u
+	s.unfurl.prefs = prefs
+}
+
+// OnEmbeds registers a callback invoked after previews are attached to a message.
+func (s *Service) OnEmbeds(fn EmbedListener) {
+	s.unfurl.listener = fn
+}
+
+// scheduleUnfurl fetches previews for msg in the background. The request context
+// is not used since the fetch outlives the HTTP request that created the message.
+func (s *Service) scheduleUnfurl(msg *Message) {
+	if s.unfurl.unfurler == nil || msg == nil || msg.Markup == nil {
+		return
+	}
+	urls := unfurlTargets(msg.Markup)
+	edited := msg.EditedAt != nil
+	if len(urls) == 0 && !edited {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), unfurlTimeout)
+		defer cancel()
+
+		if s.unfurl.prefs != nil && !s.unfurl.prefs.LinkPreviewsEnabled(ctx, msg.AuthorID) {
+			urls = nil
+		}
+
+		embeds := make([]*linkpreview.Preview, 0, len(urls))
+		for _, u := range urls {
+			p, err := s.unfurl.unfurler.Fetch(ctx, u)
+			if err != nil {
+				continue
+			}
+			embeds = append(embeds, p)
+		}
+		// Edits always rewrite the set so removed links lose their preview.
+		if len(embeds) == 0 && !edited {
+			return
+		}
+
+		if err := s.repo.ReplaceEmbeds(ctx, msg.ID, embeds); err != nil {
+			s.logger.Warn().Err(err).Str("message_id", msg.ID).Msg("failed to store link previews")
+			return
+		}
+		if s.unfurl.listener != nil {
+			s.unfurl.listener(msg.ID, msg.ChannelID, embeds)
+		}
+	}()
+}
+
+// withEmbeds loads stored previews onto msgs. Failures are logged, not returned,
+// because previews are decorative.
+// Complexity: O(n + k)
+func (s *Service) withEmbeds(ctx context.Context, msgs ...*Message) {
+	ids := make([]string, 0, len(msgs))
+	for _, m := range msgs {
+		if m != nil {
+			ids = append(ids, m.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+	embeds, err := s.repo.GetEmbeds(ctx, ids)
+	if err != nil {
+		s.logger.Warn().Err(err).Msg("failed to load link previews")
+		return
+	}
+	for _, m := range msgs {
+		if m != nil {
+			m.Embeds = embeds[m.ID]
+		}
+	}
+}
+
+// unfurlTargets returns the distinct URLs of a message eligible for previews,
+// capped at maxEmbedsPerMessage. Links inside spoilers are not unfurled.
+// Complexity: O(e) where e = number of entities
+func unfurlTargets(doc *markdown.Document) []string {
+	var spoilers []markdown.Entity
+	for _, e := range doc.Entities {
+		if e.Type == markdown.EntitySpoiler {
+			spoilers = append(spoilers, e)
+		}
+	}
+
+	seen := make(map[string]bool)
+	var urls []string
+	for _, e := range doc.Entities {
+		if e.Type != markdown.EntityLink || seen[e.URL] || !isWebURL(e.URL) {
+			continue
+		}
+		hidden := false
+		for _, sp := range spoilers {
+			if e.Offset >= sp.Offset && e.Offset+e.Length <= sp.Offset+sp.Length {
+				hidden = true
+				break
+			}
+		}
+		if hidden {
+			continue
+		}
+		seen[e.URL] = true
+		urls = append(urls, e.URL)
+		if len(urls) == maxEmbedsPerMessage {
+			break
+		}
+	}
+	return urls
+}
+
+func isWebURL(u string) bool {
+	return strings.HasPrefix(u, "http://") || strings.HasPrefix(u, "https://")
+}
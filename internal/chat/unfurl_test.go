@@ -0,0 +1,29 @@
+package chat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/concord-chat/concord/internal/markdown"
+)
+
+func TestUnfurlTargets(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{"none", "just text", nil},
+		{"single", "see https://example.com/a", []string{"https://example.com/a"}},
+		{"dedup", "https://a.io https://a.io", []string{"https://a.io"}},
+		{"skips mailto", "[mail](mailto:x@y.z) and http://b.io", []string{"http://b.io"}},
+		{"skips spoilers", "||https://secret.io|| https://open.io", []string{"https://open.io"}},
+		{"capped", "https://1.io https://2.io https://3.io https://4.io", []string{"https://1.io", "https://2.io", "https://3.io"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, unfurlTargets(markdown.Parse(tt.content)))
+		})
+	}
+}
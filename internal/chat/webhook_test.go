@@ -0,0 +1,61 @@
+package chat_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/concord-chat/concord/internal/chat"
+	"github.com/concord-chat/concord/internal/security"
+)
+
+func TestPostWebhookMessage(t *testing.T) {
+	svc, db := setupPollService(t)
+	ctx := context.Background()
+	_, err := db.ExecContext(ctx, `UPDATE users SET avatar_url = 'https://example.com/alice.png' WHERE id = 'alice'`)
+	require.NoError(t, err)
+
+	msg, err := svc.PostWebhookMessage(ctx, "ch-1", "wh-1", "alice", "CI", "", "  Build #42 passed  ")
+	require.NoError(t, err)
+	assert.Equal(t, chat.AuthorWebhook, msg.AuthorType)
+	assert.Equal(t, "wh-1", msg.WebhookID)
+	assert.Equal(t, "CI", msg.AuthorName)
+	assert.Empty(t, msg.AuthorAvatar, "the creator's avatar does not show through")
+	assert.Equal(t, "Build #42 passed", msg.Content)
+
+	user, err := svc.SendMessage(ctx, "ch-1", "alice", "thanks")
+	require.NoError(t, err)
+	assert.Equal(t, chat.AuthorUser, user.AuthorType)
+	assert.Equal(t, "alice", user.AuthorName)
+
+	msgs, err := svc.GetMessages(ctx, "ch-1", chat.PaginationOpts{})
+	require.NoError(t, err)
+	require.Len(t, msgs, 2)
+
+	_, err = svc.EditMessage(ctx, msg.ID, "alice", "changed")
+	assert.Error(t, err, "webhook messages cannot be edited, even by the creator")
+
+	_, err = svc.PostWebhookMessage(ctx, "ch-1", "wh-1", "alice", "CI", "", " ")
+	assert.ErrorIs(t, err, chat.ErrInvalidMessage)
+}
+
+func TestPostWebhookMessage_RateLimitedPerWebhook(t *testing.T) {
+	svc, _ := setupPollService(t)
+	ctx := context.Background()
+	svc.SetSendLimiter(chat.NewSendLimiter(2, nil))
+
+	for i := 0; i < 2; i++ {
+		_, err := svc.PostWebhookMessage(ctx, "ch-1", "wh-1", "alice", "CI", "", "ping")
+		require.NoError(t, err)
+	}
+	_, err := svc.PostWebhookMessage(ctx, "ch-1", "wh-1", "alice", "CI", "", "ping")
+	var rlErr *security.RateLimitError
+	assert.ErrorAs(t, err, &rlErr)
+
+	_, err = svc.PostWebhookMessage(ctx, "ch-1", "wh-2", "alice", "Alerts", "", "ping")
+	assert.NoError(t, err, "each webhook has its own budget")
+	_, err = svc.SendMessage(ctx, "ch-1", "alice", "hi")
+	assert.NoError(t, err, "the creator's own budget is untouched")
+}
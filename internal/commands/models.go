@@ -0,0 +1,76 @@
+// Package commands implements slash commands. Bots register typed commands in the
+// servers they belong to, members discover and invoke them from a channel, and the
+// owning bot receives each invocation (an interaction) through its HTTP callback
+// or by polling, then answers with a channel message or an ephemeral response.
+package commands
+
+import "time"
+
+// Option types a command option can declare.
+const (
+	OptionString  = "string"
+	OptionInteger = "integer"
+	OptionBoolean = "boolean"
+	OptionUser    = "user"    // a member of the server, passed as a user ID
+	OptionChannel = "channel" // a channel of the server, passed as a channel ID
+)
+
+// OptionTypes lists every supported option type.
+var OptionTypes = []string{OptionString, OptionInteger, OptionBoolean, OptionUser, OptionChannel}
+
+// Status is the state of an interaction.
+type Status string
+
+const (
+	StatusPending   Status = "pending"   // waiting for the bot to respond
+	StatusResponded Status = "responded" // the bot answered
+)
+
+// Option is a typed argument of a command.
+type Option struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Type        string `json:"type"`
+	Required    bool   `json:"required"`
+}
+
+// Command is a slash command a bot registered in a server.
+type Command struct {
+	ID          string    `json:"id"`
+	ServerID    string    `json:"server_id"`
+	BotID       string    `json:"bot_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Options     []Option  `json:"options"`
+	CallbackURL string    `json:"callback_url,omitempty"` // empty when the bot polls for interactions
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	// Secret signs the callbacks. Only set when the command is first registered.
+	Secret string `json:"secret,omitempty"`
+}
+
+// Interaction is one invocation of a command by a member.
+type Interaction struct {
+	ID          string                 `json:"id"`
+	CommandID   string                 `json:"command_id"`
+	CommandName string                 `json:"command_name"`
+	ServerID    string                 `json:"server_id"`
+	ChannelID   string                 `json:"channel_id"`
+	UserID      string                 `json:"user_id"`
+	BotID       string                 `json:"bot_id"`
+	Options     map[string]interface{} `json:"options"`
+	Status      Status                 `json:"status"`
+	Response    *Response              `json:"response,omitempty"`
+	MessageID   string                 `json:"message_id,omitempty"` // the reply, when it was not ephemeral
+	CreatedAt   time.Time              `json:"created_at"`
+	ExpiresAt   time.Time              `json:"expires_at"` // the bot must respond before this
+	RespondedAt *time.Time             `json:"responded_at,omitempty"`
+}
+
+// Response is a bot's answer to an interaction. An ephemeral response is only
+// shown to the member who invoked the command; otherwise it is posted to the
+// channel as a message from the bot.
+type Response struct {
+	Content   string `json:"content"`
+	Ephemeral bool   `json:"ephemeral"`
+}
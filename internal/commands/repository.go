@@ -0,0 +1,304 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// Repository handles slash command persistence.
+type Repository struct {
+	db     querier
+	logger zerolog.Logger
+}
+
+// NewRepository creates a new slash command repository.
+func NewRepository(db querier, logger zerolog.Logger) *Repository {
+	return &Repository{
+		db:     db,
+		logger: logger.With().Str("component", "commands_repo").Logger(),
+	}
+}
+
+// scanner is implemented by *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// --- Commands ---
+
+const commandColumns = `id, server_id, bot_id, name, description, options, callback_url, created_at, updated_at`
+
+func scanCommand(row scanner) (*Command, error) {
+	var c Command
+	var options string
+	if err := row.Scan(&c.ID, &c.ServerID, &c.BotID, &c.Name, &c.Description, &options,
+		&c.CallbackURL, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(options), &c.Options); err != nil {
+		return nil, fmt.Errorf("invalid options of command %s: %w", c.ID, err)
+	}
+	if c.Options == nil {
+		c.Options = []Option{}
+	}
+	return &c, nil
+}
+
+// CreateCommand inserts a command. Secret must be set.
+// Complexity: O(1)
+func (r *Repository) CreateCommand(ctx context.Context, c *Command) error {
+	options, err := json.Marshal(c.Options)
+	if err != nil {
+		return fmt.Errorf("failed to encode command options: %w", err)
+	}
+	_, err = r.db.ExecContext(ctx,
+		`INSERT INTO slash_commands (`+commandColumns+`, secret) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		c.ID, c.ServerID, c.BotID, c.Name, c.Description, string(options), c.CallbackURL,
+		c.CreatedAt.UTC(), c.UpdatedAt.UTC(), c.Secret,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create command: %w", err)
+	}
+	return nil
+}
+
+// UpdateCommand saves the description, options and callback URL of a command.
+// Complexity: O(1)
+func (r *Repository) UpdateCommand(ctx context.Context, c *Command) error {
+	options, err := json.Marshal(c.Options)
+	if err != nil {
+		return fmt.Errorf("failed to encode command options: %w", err)
+	}
+	_, err = r.db.ExecContext(ctx,
+		`UPDATE slash_commands SET description = ?, options = ?, callback_url = ?, updated_at = ? WHERE id = ?`,
+		c.Description, string(options), c.CallbackURL, c.UpdatedAt.UTC(), c.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update command: %w", err)
+	}
+	return nil
+}
+
+// GetCommand returns a command without its secret, or nil if not found.
+// Complexity: O(1)
+func (r *Repository) GetCommand(ctx context.Context, id string) (*Command, error) {
+	return r.getCommand(ctx, `SELECT `+commandColumns+` FROM slash_commands WHERE id = ?`, id)
+}
+
+// GetCommandByName returns the command of a server with the given name, or nil if not found.
+// Complexity: O(1) — unique index on (server_id, name)
+func (r *Repository) GetCommandByName(ctx context.Context, serverID, name string) (*Command, error) {
+	return r.getCommand(ctx, `SELECT `+commandColumns+` FROM slash_commands WHERE server_id = ? AND name = ?`, serverID, name)
+}
+
+func (r *Repository) getCommand(ctx context.Context, query string, args ...interface{}) (*Command, error) {
+	c, err := scanCommand(r.db.QueryRowContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get command: %w", err)
+	}
+	return c, nil
+}
+
+// GetSecret returns the signing secret of a command.
+// Complexity: O(1)
+func (r *Repository) GetSecret(ctx context.Context, commandID string) (string, error) {
+	var secret string
+	err := r.db.QueryRowContext(ctx, `SELECT secret FROM slash_commands WHERE id = ?`, commandID).Scan(&secret)
+	if err != nil {
+		return "", fmt.Errorf("failed to get command secret: %w", err)
+	}
+	return secret, nil
+}
+
+// ListCommands returns the commands of a server by name.
+// Complexity: O(n log n) where n = number of commands in the server
+func (r *Repository) ListCommands(ctx context.Context, serverID string) ([]*Command, error) {
+	return r.listCommands(ctx, `SELECT `+commandColumns+` FROM slash_commands WHERE server_id = ? ORDER BY name`, serverID)
+}
+
+// ListBotCommands returns the commands a bot registered in a server, by name.
+// Complexity: O(n log n) where n = number of commands of the bot
+func (r *Repository) ListBotCommands(ctx context.Context, serverID, botID string) ([]*Command, error) {
+	return r.listCommands(ctx,
+		`SELECT `+commandColumns+` FROM slash_commands WHERE server_id = ? AND bot_id = ? ORDER BY name`, serverID, botID)
+}
+
+func (r *Repository) listCommands(ctx context.Context, query string, args ...interface{}) ([]*Command, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commands: %w", err)
+	}
+	defer rows.Close()
+
+	var commands []*Command
+	for rows.Next() {
+		c, err := scanCommand(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan command: %w", err)
+		}
+		commands = append(commands, c)
+	}
+	return commands, rows.Err()
+}
+
+// DeleteCommand removes a command and its interactions.
+// Complexity: O(i) where i = number of interactions of the command
+func (r *Repository) DeleteCommand(ctx context.Context, id string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM slash_commands WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete command: %w", err)
+	}
+	return nil
+}
+
+// --- Interactions ---
+
+const interactionColumns = `id, command_id, command_name, server_id, channel_id, user_id, bot_id, options,
+	status, response, ephemeral, message_id, created_at, expires_at, responded_at`
+
+func scanInteraction(row scanner) (*Interaction, error) {
+	var in Interaction
+	var options, response string
+	var ephemeral bool
+	var respondedAt sql.NullTime
+	if err := row.Scan(&in.ID, &in.CommandID, &in.CommandName, &in.ServerID, &in.ChannelID, &in.UserID,
+		&in.BotID, &options, &in.Status, &response, &ephemeral, &in.MessageID,
+		&in.CreatedAt, &in.ExpiresAt, &respondedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(options), &in.Options); err != nil {
+		return nil, fmt.Errorf("invalid options of interaction %s: %w", in.ID, err)
+	}
+	if in.Status == StatusResponded {
+		in.Response = &Response{Content: response, Ephemeral: ephemeral}
+	}
+	if respondedAt.Valid {
+		in.RespondedAt = &respondedAt.Time
+	}
+	return &in, nil
+}
+
+// CreateInteraction inserts a pending interaction.
+// Complexity: O(1)
+func (r *Repository) CreateInteraction(ctx context.Context, in *Interaction) error {
+	options, err := json.Marshal(in.Options)
+	if err != nil {
+		return fmt.Errorf("failed to encode interaction options: %w", err)
+	}
+	_, err = r.db.ExecContext(ctx,
+		`INSERT INTO command_interactions
+		(id, command_id, command_name, server_id, channel_id, user_id, bot_id, options, status, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		in.ID, in.CommandID, in.CommandName, in.ServerID, in.ChannelID, in.UserID, in.BotID,
+		string(options), in.Status, in.CreatedAt.UTC(), in.ExpiresAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create interaction: %w", err)
+	}
+	return nil
+}
+
+// GetInteraction returns an interaction, or nil if not found.
+// Complexity: O(1)
+func (r *Repository) GetInteraction(ctx context.Context, id string) (*Interaction, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+interactionColumns+` FROM command_interactions WHERE id = ?`, id)
+	in, err := scanInteraction(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get interaction: %w", err)
+	}
+	return in, nil
+}
+
+// PendingInteractions returns the unexpired interactions awaiting a bot's response, oldest first.
+// Complexity: O(k) where k = limit
+func (r *Repository) PendingInteractions(ctx context.Context, botID string, now time.Time, limit int) ([]*Interaction, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+interactionColumns+` FROM command_interactions
+		WHERE bot_id = ? AND status = ? AND expires_at > ?
+		ORDER BY created_at, id LIMIT ?`,
+		botID, StatusPending, now.UTC(), limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list interactions: %w", err)
+	}
+	defer rows.Close()
+
+	var interactions []*Interaction
+	for rows.Next() {
+		in, err := scanInteraction(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan interaction: %w", err)
+		}
+		interactions = append(interactions, in)
+	}
+	return interactions, rows.Err()
+}
+
+// RecordResponse marks a pending interaction as responded. It returns false if the
+// interaction was already answered, so concurrent responses cannot both win.
+// Complexity: O(1)
+func (r *Repository) RecordResponse(ctx context.Context, id string, resp Response, at time.Time) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE command_interactions
+		SET status = ?, response = ?, ephemeral = ?, responded_at = ?
+		WHERE id = ? AND status = ?`,
+		StatusResponded, resp.Content, resp.Ephemeral, at.UTC(), id, StatusPending,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to record interaction response: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to record interaction response: %w", err)
+	}
+	return n > 0, nil
+}
+
+// SetMessageID records the message posted as the reply to an interaction.
+// Complexity: O(1)
+func (r *Repository) SetMessageID(ctx context.Context, id, messageID string) error {
+	if _, err := r.db.ExecContext(ctx, `UPDATE command_interactions SET message_id = ? WHERE id = ?`, messageID, id); err != nil {
+		return fmt.Errorf("failed to update interaction: %w", err)
+	}
+	return nil
+}
+
+// ReopenInteraction returns a responded interaction to pending, for when posting
+// the reply failed after the response was recorded.
+// Complexity: O(1)
+func (r *Repository) ReopenInteraction(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE command_interactions SET status = ?, response = '', ephemeral = ?, responded_at = NULL WHERE id = ?`,
+		StatusPending, false, id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to reopen interaction: %w", err)
+	}
+	return nil
+}
+
+// DeleteInteractionsBefore removes interactions created before cutoff.
+// Complexity: O(n) where n = number of removed interactions
+func (r *Repository) DeleteInteractionsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM command_interactions WHERE created_at < ?`, cutoff.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune interactions: %w", err)
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,575 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+
+	"github.com/concord-chat/concord/internal/chat"
+	"github.com/concord-chat/concord/internal/security"
+	"github.com/concord-chat/concord/internal/server"
+	"github.com/concord-chat/concord/internal/webhooks"
+	"github.com/concord-chat/concord/pkg/version"
+)
+
+// EventInteraction is the X-Concord-Event header of interaction callbacks.
+const EventInteraction = "interaction.create"
+
+const (
+	maxCommandsPerBot   = 25
+	maxOptions          = 10
+	maxDescription      = 100
+	maxStringOption     = 1000
+	maxResponseLength   = 4000
+	maxPendingBatch     = 50
+	interactionTimeout  = 15 * time.Minute
+	interactionTTL      = 24 * time.Hour
+	callbackTimeout     = 3 * time.Second
+	maxCallbackResponse = 16 << 10
+)
+
+// channelPerms are needed in a channel to invoke a command there or answer it.
+const channelPerms = server.PermViewChannel | server.PermSendMessages
+
+// namePattern matches command and option names: lowercase, digits, '-' and '_'.
+var namePattern = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+// Servers is the part of server.Service used to authorize commands.
+type Servers interface {
+	CheckPermission(ctx context.Context, serverID, userID string, perm server.Permission) error
+	CheckChannelPermission(ctx context.Context, channelID, userID string, perm server.Permission) error
+	GetChannel(ctx context.Context, channelID string) (*server.Channel, error)
+	IsMember(ctx context.Context, serverID, userID string) (bool, error)
+}
+
+// Messages posts the non-ephemeral replies of bots.
+type Messages interface {
+	SendMessage(ctx context.Context, channelID, authorID, content string) (*chat.Message, error)
+}
+
+// Service registers slash commands and dispatches their invocations to bots.
+type Service struct {
+	repo     *Repository
+	servers  Servers
+	messages Messages
+	client   *http.Client
+	outbound *security.Outbound
+	now      func() time.Time
+	logger   zerolog.Logger
+}
+
+// NewService creates a new slash command service.
+func NewService(repo *Repository, servers Servers, messages Messages, logger zerolog.Logger) *Service {
+	outbound := security.NewOutbound()
+	return &Service{
+		repo:     repo,
+		servers:  servers,
+		messages: messages,
+		client:   outbound.Client(callbackTimeout, 0),
+		outbound: outbound,
+		now:      time.Now,
+		logger:   logger.With().Str("component", "commands_service").Logger(),
+	}
+}
+
+// --- Registry ---
+
+// RegisterCommand creates or replaces a command of botID in a server the bot is a
+// member of. Re-registering a name the bot already owns updates it and keeps its
+// secret; names are unique per server. The secret is only returned on creation.
+func (s *Service) RegisterCommand(ctx context.Context, serverID, botID string, c Command) (*Command, error) {
+	member, err := s.servers.IsMember(ctx, serverID, botID)
+	if err != nil {
+		return nil, err
+	}
+	if !member {
+		return nil, fmt.Errorf("bot is not a member of this server")
+	}
+	if err := s.validateCommand(&c); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.GetCommandByName(ctx, serverID, c.Name)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	if existing != nil {
+		if existing.BotID != botID {
+			return nil, fmt.Errorf("command /%s is already registered by another bot", c.Name)
+		}
+		existing.Description = c.Description
+		existing.Options = c.Options
+		existing.CallbackURL = c.CallbackURL
+		existing.UpdatedAt = now
+		if err := s.repo.UpdateCommand(ctx, existing); err != nil {
+			return nil, err
+		}
+		return existing, nil
+	}
+
+	owned, err := s.repo.ListBotCommands(ctx, serverID, botID)
+	if err != nil {
+		return nil, err
+	}
+	if len(owned) >= maxCommandsPerBot {
+		return nil, fmt.Errorf("a bot can register at most %d commands per server", maxCommandsPerBot)
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate command secret: %w", err)
+	}
+	c.ID = uuid.New().String()
+	c.ServerID = serverID
+	c.BotID = botID
+	c.CreatedAt = now
+	c.UpdatedAt = now
+	c.Secret = secret
+	if err := s.repo.CreateCommand(ctx, &c); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info().
+		Str("command_id", c.ID).
+		Str("server_id", serverID).
+		Str("bot_id", botID).
+		Str("name", c.Name).
+		Msg("slash command registered")
+	return &c, nil
+}
+
+// ListCommands returns the commands of a server, for its members to discover.
+func (s *Service) ListCommands(ctx context.Context, serverID, userID string) ([]*Command, error) {
+	member, err := s.servers.IsMember(ctx, serverID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !member {
+		return nil, fmt.Errorf("not a member of this server")
+	}
+	return s.repo.ListCommands(ctx, serverID)
+}
+
+// DeleteCommand removes a command. The bot that registered it may always delete
+// it; anyone else needs PermManageServer.
+func (s *Service) DeleteCommand(ctx context.Context, serverID, actorID, commandID string) error {
+	c, err := s.repo.GetCommand(ctx, commandID)
+	if err != nil {
+		return err
+	}
+	if c == nil || c.ServerID != serverID {
+		return fmt.Errorf("command not found")
+	}
+	if c.BotID != actorID {
+		if err := s.servers.CheckPermission(ctx, serverID, actorID, server.PermManageServer); err != nil {
+			return err
+		}
+	}
+	if err := s.repo.DeleteCommand(ctx, commandID); err != nil {
+		return err
+	}
+	s.logger.Info().Str("command_id", commandID).Str("deleted_by", actorID).Msg("slash command deleted")
+	return nil
+}
+
+// --- Dispatch ---
+
+// Invoke runs /name in a channel on behalf of userID. Both userID and the bot
+// owning the command need PermViewChannel and PermSendMessages in the channel, so
+// channel overwrites apply to commands as they do to messages. Option values are checked against the command's declared types.
+// When the command has a callback URL the interaction is posted to it, and a
+// response in the callback's answer is applied straight away; otherwise the bot
+// picks it up with PendingInteractions, as nothing is pushed over the gateway.
+// The returned interaction reflects that.
+func (s *Service) Invoke(ctx context.Context, channelID, userID, name string, options map[string]interface{}) (*Interaction, error) {
+	ch, err := s.servers.GetChannel(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	if ch == nil || ch.Type != "text" {
+		return nil, fmt.Errorf("commands can only be used in text channels")
+	}
+	if err := s.servers.CheckChannelPermission(ctx, channelID, userID, channelPerms); err != nil {
+		return nil, err
+	}
+
+	c, err := s.repo.GetCommandByName(ctx, ch.ServerID, strings.TrimPrefix(strings.TrimSpace(name), "/"))
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return nil, fmt.Errorf("unknown command")
+	}
+	if err := s.servers.CheckChannelPermission(ctx, channelID, c.BotID, channelPerms); err != nil {
+		return nil, fmt.Errorf("the bot cannot reply in this channel")
+	}
+	values, err := s.checkOptions(ctx, ch.ServerID, c.Options, options)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	in := &Interaction{
+		ID:          uuid.New().String(),
+		CommandID:   c.ID,
+		CommandName: c.Name,
+		ServerID:    ch.ServerID,
+		ChannelID:   channelID,
+		UserID:      userID,
+		BotID:       c.BotID,
+		Options:     values,
+		Status:      StatusPending,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(interactionTimeout),
+	}
+	if err := s.repo.CreateInteraction(ctx, in); err != nil {
+		return nil, err
+	}
+
+	s.logger.Debug().
+		Str("interaction_id", in.ID).
+		Str("command", c.Name).
+		Str("user_id", userID).
+		Msg("slash command invoked")
+
+	if c.CallbackURL == "" {
+		return in, nil
+	}
+	resp, err := s.callback(ctx, c, in)
+	if err != nil {
+		s.logger.Warn().Err(err).Str("interaction_id", in.ID).Msg("slash command callback failed")
+		return in, nil
+	}
+	if resp == nil {
+		return in, nil // the bot answers later
+	}
+	answered, err := s.Respond(ctx, c.BotID, in.ID, *resp)
+	if err != nil {
+		s.logger.Warn().Err(err).Str("interaction_id", in.ID).Msg("slash command callback response rejected")
+		return in, nil
+	}
+	return answered, nil
+}
+
+// PendingInteractions returns the interactions waiting for botID to respond, oldest first.
+func (s *Service) PendingInteractions(ctx context.Context, botID string) ([]*Interaction, error) {
+	return s.repo.PendingInteractions(ctx, botID, s.now(), maxPendingBatch)
+}
+
+// GetInteraction returns an interaction to the member who invoked it or the bot
+// answering it; ephemeral responses are only visible this way.
+func (s *Service) GetInteraction(ctx context.Context, userID, interactionID string) (*Interaction, error) {
+	in, err := s.repo.GetInteraction(ctx, interactionID)
+	if err != nil {
+		return nil, err
+	}
+	if in == nil || (in.UserID != userID && in.BotID != userID) {
+		return nil, fmt.Errorf("interaction not found")
+	}
+	return in, nil
+}
+
+// Respond answers a pending interaction of botID before it expires. A
+// non-ephemeral response is posted to the channel as a message from the bot,
+// which needs PermViewChannel and PermSendMessages in that channel. Each interaction is answered once.
+func (s *Service) Respond(ctx context.Context, botID, interactionID string, resp Response) (*Interaction, error) {
+	resp.Content = strings.TrimSpace(resp.Content)
+	if resp.Content == "" {
+		return nil, fmt.Errorf("response content cannot be empty")
+	}
+	if len(resp.Content) > maxResponseLength {
+		return nil, fmt.Errorf("response exceeds maximum length of %d characters", maxResponseLength)
+	}
+
+	in, err := s.repo.GetInteraction(ctx, interactionID)
+	if err != nil {
+		return nil, err
+	}
+	if in == nil || in.BotID != botID {
+		return nil, fmt.Errorf("interaction not found")
+	}
+	now := s.now()
+	if in.Status != StatusPending {
+		return nil, fmt.Errorf("interaction already answered")
+	}
+	if !now.Before(in.ExpiresAt) {
+		return nil, fmt.Errorf("interaction expired")
+	}
+	if !resp.Ephemeral {
+		if err := s.servers.CheckChannelPermission(ctx, in.ChannelID, botID, channelPerms); err != nil {
+			return nil, err
+		}
+	}
+
+	claimed, err := s.repo.RecordResponse(ctx, in.ID, resp, now)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, fmt.Errorf("interaction already answered")
+	}
+	if !resp.Ephemeral {
+		msg, err := s.messages.SendMessage(ctx, in.ChannelID, botID, resp.Content)
+		if err != nil {
+			if rerr := s.repo.ReopenInteraction(ctx, in.ID); rerr != nil {
+				s.logger.Error().Err(rerr).Str("interaction_id", in.ID).Msg("failed to reopen interaction")
+			}
+			return nil, err
+		}
+		if err := s.repo.SetMessageID(ctx, in.ID, msg.ID); err != nil {
+			return nil, err
+		}
+	}
+	return s.repo.GetInteraction(ctx, in.ID)
+}
+
+// Run prunes old interactions every interval until ctx is done.
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.repo.DeleteInteractionsBefore(ctx, s.now().Add(-interactionTTL)); err != nil {
+				s.logger.Warn().Err(err).Msg("interaction prune failed")
+			}
+		}
+	}
+}
+
+// callback posts an interaction to the command's callback URL, signed like
+// outgoing webhooks. A 200 answer with a JSON Response body answers the
+// interaction; any other 2xx answer defers the response.
+func (s *Service) callback(ctx context.Context, c *Command, in *Interaction) (*Response, error) {
+	if err := s.checkURL(c.CallbackURL); err != nil {
+		return nil, err
+	}
+	secret, err := s.repo.GetSecret(ctx, c.ID)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(in)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode interaction: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, callbackTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.CallbackURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	ts := s.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Concord-Commands/"+version.Version)
+	req.Header.Set(webhooks.HeaderEvent, EventInteraction)
+	req.Header.Set(webhooks.HeaderDelivery, in.ID)
+	req.Header.Set(webhooks.HeaderSignature, fmt.Sprintf("t=%d,v1=%s", ts, webhooks.Sign(secret, ts, body)))
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, fmt.Errorf("callback answered %d", res.StatusCode)
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, nil
+	}
+	raw, err := io.ReadAll(io.LimitReader(res.Body, maxCallbackResponse))
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return nil, nil
+	}
+	var resp Response
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return nil, fmt.Errorf("invalid callback response: %w", err)
+	}
+	if strings.TrimSpace(resp.Content) == "" {
+		return nil, nil
+	}
+	return &resp, nil
+}
+
+// validateCommand normalizes and checks a command definition.
+func (s *Service) validateCommand(c *Command) error {
+	c.Name = strings.TrimPrefix(strings.TrimSpace(c.Name), "/")
+	if !namePattern.MatchString(c.Name) {
+		return fmt.Errorf("command name must be 1-32 lowercase letters, digits, '-' or '_'")
+	}
+	c.Description = strings.TrimSpace(c.Description)
+	if utf8.RuneCountInString(c.Description) > maxDescription {
+		return fmt.Errorf("description cannot exceed %d characters", maxDescription)
+	}
+	if len(c.Options) > maxOptions {
+		return fmt.Errorf("a command can have at most %d options", maxOptions)
+	}
+
+	seen := make(map[string]bool, len(c.Options))
+	optional := false
+	for i := range c.Options {
+		o := &c.Options[i]
+		o.Name = strings.TrimSpace(o.Name)
+		if !namePattern.MatchString(o.Name) {
+			return fmt.Errorf("option name must be 1-32 lowercase letters, digits, '-' or '_'")
+		}
+		if seen[o.Name] {
+			return fmt.Errorf("duplicate option %q", o.Name)
+		}
+		seen[o.Name] = true
+		if !knownType(o.Type) {
+			return fmt.Errorf("option %q has unknown type %q", o.Name, o.Type)
+		}
+		o.Description = strings.TrimSpace(o.Description)
+		if utf8.RuneCountInString(o.Description) > maxDescription {
+			return fmt.Errorf("option description cannot exceed %d characters", maxDescription)
+		}
+		if o.Required && optional {
+			return fmt.Errorf("required option %q must come before optional ones", o.Name)
+		}
+		optional = optional || !o.Required
+	}
+	if c.Options == nil {
+		c.Options = []Option{}
+	}
+
+	c.CallbackURL = strings.TrimSpace(c.CallbackURL)
+	if c.CallbackURL != "" {
+		if err := s.checkURL(c.CallbackURL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkOptions validates the values of an invocation and returns them normalized:
+// integers as int64, everything else as decoded from JSON.
+func (s *Service) checkOptions(ctx context.Context, serverID string, declared []Option, values map[string]interface{}) (map[string]interface{}, error) {
+	byName := make(map[string]Option, len(declared))
+	for _, o := range declared {
+		byName[o.Name] = o
+	}
+	for name := range values {
+		if _, ok := byName[name]; !ok {
+			return nil, fmt.Errorf("unknown option %q", name)
+		}
+	}
+
+	out := make(map[string]interface{}, len(values))
+	for _, o := range declared {
+		v, ok := values[o.Name]
+		if !ok || v == nil {
+			if o.Required {
+				return nil, fmt.Errorf("option %q is required", o.Name)
+			}
+			continue
+		}
+		value, err := s.checkValue(ctx, serverID, o, v)
+		if err != nil {
+			return nil, err
+		}
+		out[o.Name] = value
+	}
+	return out, nil
+}
+
+func (s *Service) checkValue(ctx context.Context, serverID string, o Option, v interface{}) (interface{}, error) {
+	invalid := fmt.Errorf("option %q must be a %s", o.Name, o.Type)
+	switch o.Type {
+	case OptionString:
+		str, ok := v.(string)
+		if !ok {
+			return nil, invalid
+		}
+		if utf8.RuneCountInString(str) > maxStringOption {
+			return nil, fmt.Errorf("option %q cannot exceed %d characters", o.Name, maxStringOption)
+		}
+		return str, nil
+	case OptionInteger:
+		f, ok := v.(float64)
+		if !ok || f != math.Trunc(f) || math.Abs(f) > 1<<53 {
+			return nil, invalid
+		}
+		return int64(f), nil
+	case OptionBoolean:
+		b, ok := v.(bool)
+		if !ok {
+			return nil, invalid
+		}
+		return b, nil
+	case OptionUser:
+		id, ok := v.(string)
+		if !ok || id == "" {
+			return nil, invalid
+		}
+		member, err := s.servers.IsMember(ctx, serverID, id)
+		if err != nil {
+			return nil, err
+		}
+		if !member {
+			return nil, fmt.Errorf("option %q must be a member of this server", o.Name)
+		}
+		return id, nil
+	case OptionChannel:
+		id, ok := v.(string)
+		if !ok || id == "" {
+			return nil, invalid
+		}
+		ch, err := s.servers.GetChannel(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if ch == nil || ch.ServerID != serverID {
+			return nil, fmt.Errorf("option %q must be a channel of this server", o.Name)
+		}
+		return id, nil
+	}
+	return nil, invalid
+}
+
+func (s *Service) checkURL(rawURL string) error {
+	if err := s.outbound.CheckURL(rawURL); err != nil {
+		if errors.Is(err, security.ErrBlockedAddress) {
+			return fmt.Errorf("callback URL points at a private address")
+		}
+		return fmt.Errorf("invalid callback URL: %w", err)
+	}
+	return nil
+}
+
+func knownType(t string) bool {
+	for _, known := range OptionTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "cmdsec_" + base64.RawURLEncoding.EncodeToString(b), nil
+}
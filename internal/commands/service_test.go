@@ -0,0 +1,282 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/concord-chat/concord/internal/cache"
+	"github.com/concord-chat/concord/internal/chat"
+	"github.com/concord-chat/concord/internal/server"
+	"github.com/concord-chat/concord/internal/store/sqlite"
+	"github.com/concord-chat/concord/internal/webhooks"
+)
+
+func setupService(t *testing.T) (*Service, *chat.Service) {
+	t.Helper()
+	logger := zerolog.Nop()
+	db, err := sqlite.New(sqlite.Config{
+		Path:         filepath.Join(t.TempDir(), "test.db"),
+		MaxOpenConns: 1,
+		ForeignKeys:  true,
+		BusyTimeout:  5 * time.Second,
+	}, logger)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	ctx := context.Background()
+	require.NoError(t, sqlite.NewMigrator(db, logger).Migrate(ctx))
+
+	for _, stmt := range []string{
+		`INSERT INTO users (id, username) VALUES ('owner', 'owner'), ('member', 'member'), ('stranger', 'stranger')`,
+		`INSERT INTO users (id, username, is_bot, bot_owner_id) VALUES ('bot', 'bot', 1, 'owner'), ('bot-2', 'bot2', 1, 'owner')`,
+		`INSERT INTO servers (id, name, owner_id) VALUES ('srv-1', 'Test', 'owner'), ('srv-2', 'Other', 'owner')`,
+		`INSERT INTO server_members (server_id, user_id) VALUES ('srv-1', 'owner'), ('srv-1', 'member'), ('srv-1', 'bot'), ('srv-1', 'bot-2')`,
+		`INSERT INTO channels (id, server_id, name, type, position) VALUES ('ch-1', 'srv-1', 'general', 'text', 0), ('vc-1', 'srv-1', 'Lounge', 'voice', 1),
+			('ch-x', 'srv-2', 'elsewhere', 'text', 0)`,
+	} {
+		_, err := db.ExecContext(ctx, stmt)
+		require.NoError(t, err)
+	}
+
+	servers := server.NewService(server.NewRepository(db, server.NewStdlibTransactor(db.Conn()), logger), cache.NewLRU(100), logger)
+	chatSvc := chat.NewService(chat.NewRepository(db, logger), logger)
+	svc := NewService(NewRepository(db, logger), servers, chatSvc, logger)
+	svc.outbound.AllowPrivate = true
+	return svc, chatSvc
+}
+
+func deployCommand(callbackURL string) Command {
+	return Command{
+		Name:        "deploy",
+		Description: "Deploy a build",
+		Options: []Option{
+			{Name: "env", Type: OptionString, Required: true},
+			{Name: "replicas", Type: OptionInteger},
+			{Name: "notify", Type: OptionUser},
+		},
+		CallbackURL: callbackURL,
+	}
+}
+
+func TestRegisterCommand(t *testing.T) {
+	svc, _ := setupService(t)
+	ctx := context.Background()
+
+	c, err := svc.RegisterCommand(ctx, "srv-1", "bot", deployCommand(""))
+	require.NoError(t, err)
+	assert.NotEmpty(t, c.Secret)
+	assert.Equal(t, "bot", c.BotID)
+
+	update := deployCommand("")
+	update.Description = "Ship it"
+	updated, err := svc.RegisterCommand(ctx, "srv-1", "bot", update)
+	require.NoError(t, err)
+	assert.Equal(t, c.ID, updated.ID, "re-registering updates in place")
+	assert.Empty(t, updated.Secret)
+
+	_, err = svc.RegisterCommand(ctx, "srv-1", "bot-2", deployCommand(""))
+	assert.Error(t, err, "name taken by another bot")
+	_, err = svc.RegisterCommand(ctx, "srv-2", "bot", deployCommand(""))
+	assert.Error(t, err, "bot is not a member")
+
+	list, err := svc.ListCommands(ctx, "srv-1", "member")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "Ship it", list[0].Description)
+	assert.Empty(t, list[0].Secret)
+	_, err = svc.ListCommands(ctx, "srv-1", "stranger")
+	assert.Error(t, err)
+
+	assert.Error(t, svc.DeleteCommand(ctx, "srv-1", "member", c.ID), "needs PermManageServer")
+	require.NoError(t, svc.DeleteCommand(ctx, "srv-1", "bot", c.ID))
+}
+
+func TestRegisterCommand_Validation(t *testing.T) {
+	svc, _ := setupService(t)
+	ctx := context.Background()
+
+	for name, c := range map[string]Command{
+		"bad name":          {Name: "Deploy Now"},
+		"unknown type":      {Name: "x", Options: []Option{{Name: "a", Type: "float"}}},
+		"duplicate option":  {Name: "x", Options: []Option{{Name: "a", Type: OptionString}, {Name: "a", Type: OptionBoolean}}},
+		"required last":     {Name: "x", Options: []Option{{Name: "a", Type: OptionString}, {Name: "b", Type: OptionString, Required: true}}},
+		"long description":  {Name: "x", Description: strings.Repeat("d", maxDescription+1)},
+		"bad callback":      {Name: "x", CallbackURL: "ftp://example.com"},
+		"private callback":  {Name: "x", CallbackURL: "http://127.0.0.1:9000/hook"},
+		"bad option name":   {Name: "x", Options: []Option{{Name: "A B", Type: OptionString}}},
+		"empty option type": {Name: "x", Options: []Option{{Name: "a"}}},
+	} {
+		svc.outbound.AllowPrivate = !strings.HasSuffix(name, "callback")
+		_, err := svc.RegisterCommand(ctx, "srv-1", "bot", c)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestInvoke_PollAndRespond(t *testing.T) {
+	svc, chatSvc := setupService(t)
+	ctx := context.Background()
+	_, err := svc.RegisterCommand(ctx, "srv-1", "bot", deployCommand(""))
+	require.NoError(t, err)
+
+	_, err = svc.Invoke(ctx, "ch-1", "member", "deploy", map[string]interface{}{"replicas": float64(2)})
+	assert.Error(t, err, "missing required option")
+	_, err = svc.Invoke(ctx, "ch-1", "member", "deploy", map[string]interface{}{"env": "prod", "replicas": 2.5})
+	assert.Error(t, err, "not an integer")
+	_, err = svc.Invoke(ctx, "ch-1", "member", "deploy", map[string]interface{}{"env": "prod", "force": true})
+	assert.Error(t, err, "unknown option")
+	_, err = svc.Invoke(ctx, "ch-1", "member", "deploy", map[string]interface{}{"env": "prod", "notify": "stranger"})
+	assert.Error(t, err, "user option must be a member")
+	_, err = svc.Invoke(ctx, "ch-1", "stranger", "deploy", map[string]interface{}{"env": "prod"})
+	assert.Error(t, err, "invoker must be a member")
+	_, err = svc.Invoke(ctx, "vc-1", "member", "deploy", map[string]interface{}{"env": "prod"})
+	assert.Error(t, err, "text channels only")
+	_, err = svc.Invoke(ctx, "ch-1", "member", "rollback", nil)
+	assert.Error(t, err, "unknown command")
+
+	in, err := svc.Invoke(ctx, "ch-1", "member", "/deploy", map[string]interface{}{"env": "prod", "replicas": float64(3), "notify": "owner"})
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, in.Status)
+	assert.Equal(t, int64(3), in.Options["replicas"])
+
+	pending, err := svc.PendingInteractions(ctx, "bot")
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, in.ID, pending[0].ID)
+	assert.Equal(t, "prod", pending[0].Options["env"])
+
+	_, err = svc.Respond(ctx, "bot-2", in.ID, Response{Content: "hijack"})
+	assert.Error(t, err, "only the command's bot answers")
+
+	answered, err := svc.Respond(ctx, "bot", in.ID, Response{Content: "Deployed to prod"})
+	require.NoError(t, err)
+	assert.Equal(t, StatusResponded, answered.Status)
+	require.NotEmpty(t, answered.MessageID)
+	msg, err := chatSvc.GetMessage(ctx, answered.MessageID)
+	require.NoError(t, err)
+	assert.Equal(t, "bot", msg.AuthorID)
+	assert.Equal(t, "Deployed to prod", msg.Content)
+
+	_, err = svc.Respond(ctx, "bot", in.ID, Response{Content: "again"})
+	assert.Error(t, err, "answered once")
+	pending, err = svc.PendingInteractions(ctx, "bot")
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestInvoke_EphemeralAndExpiry(t *testing.T) {
+	svc, _ := setupService(t)
+	ctx := context.Background()
+	now := time.Now()
+	svc.now = func() time.Time { return now }
+	_, err := svc.RegisterCommand(ctx, "srv-1", "bot", Command{Name: "ping"})
+	require.NoError(t, err)
+
+	first, err := svc.Invoke(ctx, "ch-1", "member", "ping", nil)
+	require.NoError(t, err)
+	second, err := svc.Invoke(ctx, "ch-1", "member", "ping", nil)
+	require.NoError(t, err)
+
+	_, err = svc.Respond(ctx, "bot", first.ID, Response{Content: "pong", Ephemeral: true})
+	require.NoError(t, err)
+	seen, err := svc.GetInteraction(ctx, "member", first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, &Response{Content: "pong", Ephemeral: true}, seen.Response)
+	assert.Empty(t, seen.MessageID, "ephemeral responses are not posted")
+	_, err = svc.GetInteraction(ctx, "owner", first.ID)
+	assert.Error(t, err, "only the invoker and the bot can see it")
+
+	now = now.Add(interactionTimeout)
+	_, err = svc.Respond(ctx, "bot", second.ID, Response{Content: "late"})
+	assert.Error(t, err, "expired")
+	pending, err := svc.PendingInteractions(ctx, "bot")
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestInvoke_ChannelOverwrites(t *testing.T) {
+	svc, _ := setupService(t)
+	ctx := context.Background()
+	servers := svc.servers.(*server.Service)
+	_, err := svc.RegisterCommand(ctx, "srv-1", "bot", Command{Name: "ping"})
+	require.NoError(t, err)
+
+	deny := func(userID string, perm server.Permission) {
+		t.Helper()
+		_, err := servers.SetChannelOverwrite(ctx, "srv-1", "owner", server.Overwrite{
+			ChannelID: "ch-1", TargetType: server.OverwriteMember, TargetID: userID, Deny: perm,
+		})
+		require.NoError(t, err)
+	}
+
+	in, err := svc.Invoke(ctx, "ch-1", "member", "ping", nil)
+	require.NoError(t, err)
+
+	// The bot loses access after the invocation: it can still answer privately.
+	deny("bot", server.PermSendMessages)
+	_, err = svc.Respond(ctx, "bot", in.ID, Response{Content: "pong"})
+	assert.Error(t, err)
+	_, err = svc.Respond(ctx, "bot", in.ID, Response{Content: "pong", Ephemeral: true})
+	require.NoError(t, err)
+	_, err = svc.Invoke(ctx, "ch-1", "member", "ping", nil)
+	assert.Error(t, err, "the bot cannot reply in the channel")
+
+	require.NoError(t, servers.DeleteChannelOverwrite(ctx, "srv-1", "owner", "ch-1", server.OverwriteMember, "bot"))
+	deny("member", server.PermViewChannel)
+	_, err = svc.Invoke(ctx, "ch-1", "member", "ping", nil)
+	assert.ErrorIs(t, err, server.ErrChannelNotFound, "hidden channels cannot be used")
+	_, err = svc.Invoke(ctx, "ch-1", "owner", "ping", nil)
+	require.NoError(t, err)
+}
+
+func TestInvoke_Callback(t *testing.T) {
+	svc, _ := setupService(t)
+	ctx := context.Background()
+
+	var secret string
+	var gotSignature bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var tsUnix int64
+		var sig string
+		_, err := fmt.Sscanf(strings.Replace(r.Header.Get(webhooks.HeaderSignature), ",v1=", " ", 1), "t=%d %s", &tsUnix, &sig)
+		gotSignature = err == nil && sig == webhooks.Sign(secret, tsUnix, body)
+
+		var in Interaction
+		_ = json.Unmarshal(body, &in)
+		if in.Options["env"] == "later" {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(Response{Content: "on it: " + in.Options["env"].(string), Ephemeral: true})
+	}))
+	defer ts.Close()
+
+	c, err := svc.RegisterCommand(ctx, "srv-1", "bot", deployCommand(strings.Replace(ts.URL, "127.0.0.1", "localhost", 1)))
+	require.NoError(t, err)
+	secret = c.Secret
+
+	in, err := svc.Invoke(ctx, "ch-1", "member", "deploy", map[string]interface{}{"env": "prod"})
+	require.NoError(t, err)
+	assert.True(t, gotSignature)
+	assert.Equal(t, StatusResponded, in.Status)
+	assert.Equal(t, "on it: prod", in.Response.Content)
+
+	deferred, err := svc.Invoke(ctx, "ch-1", "member", "deploy", map[string]interface{}{"env": "later"})
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, deferred.Status)
+	pending, err := svc.PendingInteractions(ctx, "bot")
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, deferred.ID, pending[0].ID)
+}
@@ -130,8 +130,8 @@ func Default() *Config {
 			JWTRefreshExpiry: 30 * 24 * time.Hour,
 
 			RateLimitEnabled:  true,
-			RateLimitMessages: 10, // 10 messages per second
-			RateLimitFiles:    5,  // 5 files per minute
+			RateLimitMessages: 30, // 30 messages per minute per user
+			RateLimitFiles:    10, // 10 uploads per hour per user
 			RateLimitAPI:      60, // 60 requests per minute
 
 			MaxFileSize: 50 * 1024 * 1024, // 50MB
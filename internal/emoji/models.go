@@ -0,0 +1,28 @@
+// Package emoji manages the custom emoji of servers. Members with the manage emoji
+// permission upload small PNG, GIF or WebP images under a name; messages reference
+// them as :name:, which is resolved to <:name:id> (<a:name:id> for GIFs) when the
+// message is sent, and members can use them as reactions.
+package emoji
+
+import "time"
+
+// Emoji is a custom emoji of a server.
+type Emoji struct {
+	ID        string    `json:"id"`
+	ServerID  string    `json:"server_id"`
+	Name      string    `json:"name"`
+	MimeType  string    `json:"mime_type"`
+	SizeBytes int64     `json:"size_bytes"`
+	Animated  bool      `json:"animated"` // GIF images
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	LocalPath string    `json:"-"`
+}
+
+// Token returns the message token that references the emoji.
+func (e *Emoji) Token() string {
+	if e.Animated {
+		return "<a:" + e.Name + ":" + e.ID + ">"
+	}
+	return "<:" + e.Name + ":" + e.ID + ">"
+}
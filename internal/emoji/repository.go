@@ -0,0 +1,137 @@
+package emoji
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/rs/zerolog"
+)
+
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// Repository handles custom emoji persistence.
+type Repository struct {
+	db     querier
+	logger zerolog.Logger
+}
+
+// NewRepository creates a new custom emoji repository.
+func NewRepository(db querier, logger zerolog.Logger) *Repository {
+	return &Repository{
+		db:     db,
+		logger: logger.With().Str("component", "emoji_repo").Logger(),
+	}
+}
+
+// scanner is implemented by *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+const emojiColumns = `id, server_id, name, mime_type, size_bytes, local_path, created_by, created_at`
+
+func scanEmoji(row scanner) (*Emoji, error) {
+	var e Emoji
+	if err := row.Scan(&e.ID, &e.ServerID, &e.Name, &e.MimeType, &e.SizeBytes, &e.LocalPath,
+		&e.CreatedBy, &e.CreatedAt); err != nil {
+		return nil, err
+	}
+	e.Animated = e.MimeType == "image/gif"
+	return &e, nil
+}
+
+// Create inserts an emoji.
+// Complexity: O(1)
+func (r *Repository) Create(ctx context.Context, e *Emoji) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO server_emoji (`+emojiColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		e.ID, e.ServerID, e.Name, e.MimeType, e.SizeBytes, e.LocalPath, e.CreatedBy, e.CreatedAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create emoji: %w", err)
+	}
+	return nil
+}
+
+// Get returns an emoji, or nil if not found.
+// Complexity: O(1)
+func (r *Repository) Get(ctx context.Context, id string) (*Emoji, error) {
+	e, err := scanEmoji(r.db.QueryRowContext(ctx, `SELECT `+emojiColumns+` FROM server_emoji WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get emoji: %w", err)
+	}
+	return e, nil
+}
+
+// List returns the emoji of a server by name.
+// Complexity: O(n log n) where n = number of emoji in the server
+func (r *Repository) List(ctx context.Context, serverID string) ([]*Emoji, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+emojiColumns+` FROM server_emoji WHERE server_id = ? ORDER BY name`, serverID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list emoji: %w", err)
+	}
+	defer rows.Close()
+
+	var list []*Emoji
+	for rows.Next() {
+		e, err := scanEmoji(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan emoji: %w", err)
+		}
+		list = append(list, e)
+	}
+	return list, rows.Err()
+}
+
+// Count returns the number of emoji of a server.
+// Complexity: O(n) where n = number of emoji in the server
+func (r *Repository) Count(ctx context.Context, serverID string) (int, error) {
+	var n int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM server_emoji WHERE server_id = ?`, serverID).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to count emoji: %w", err)
+	}
+	return n, nil
+}
+
+// NameTaken reports whether a server already has an emoji with the given name.
+// Complexity: O(1) — unique index on (server_id, name)
+func (r *Repository) NameTaken(ctx context.Context, serverID, name string) (bool, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM server_emoji WHERE server_id = ? AND name = ?`, serverID, name).Scan(&n)
+	if err != nil {
+		return false, fmt.Errorf("failed to check emoji name: %w", err)
+	}
+	return n > 0, nil
+}
+
+// Rename changes the name of an emoji.
+// Complexity: O(1)
+func (r *Repository) Rename(ctx context.Context, id, name string) error {
+	if _, err := r.db.ExecContext(ctx, `UPDATE server_emoji SET name = ? WHERE id = ?`, name, id); err != nil {
+		return fmt.Errorf("failed to rename emoji: %w", err)
+	}
+	return nil
+}
+
+// Delete removes an emoji and the reactions that used it.
+// Complexity: O(r) where r = number of reactions with the emoji
+func (r *Repository) Delete(ctx context.Context, id string) error {
+	// Also removed by the foreign key cascade; explicit for connections without foreign_keys=ON.
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM message_reactions WHERE emoji_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete emoji reactions: %w", err)
+	}
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM server_emoji WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete emoji: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,282 @@
+package emoji
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+
+	"github.com/concord-chat/concord/internal/files"
+	"github.com/concord-chat/concord/internal/markdown"
+	"github.com/concord-chat/concord/internal/server"
+)
+
+const (
+	// MaxImageSize is the largest emoji image accepted, in bytes.
+	MaxImageSize      = 256 << 10
+	maxEmojiPerServer = 50
+)
+
+// imageExtensions maps the accepted image types to their file extension.
+var imageExtensions = map[string]string{
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// Servers is the part of server.Service used to authorize emoji management.
+type Servers interface {
+	CheckPermission(ctx context.Context, serverID, userID string, perm server.Permission) error
+	GetChannel(ctx context.Context, channelID string) (*server.Channel, error)
+	IsMember(ctx context.Context, serverID, userID string) (bool, error)
+}
+
+// Service manages the custom emoji of servers.
+type Service struct {
+	repo    *Repository
+	storage files.Storage
+	scanner *files.Scanner
+	servers Servers
+	now     func() time.Time
+	logger  zerolog.Logger
+}
+
+// NewService creates a new custom emoji service storing images in storage.
+func NewService(repo *Repository, storage files.Storage, servers Servers, logger zerolog.Logger) *Service {
+	return &Service{
+		repo:    repo,
+		storage: storage,
+		scanner: files.NewScanner(),
+		servers: servers,
+		now:     time.Now,
+		logger:  logger.With().Str("component", "emoji_service").Logger(),
+	}
+}
+
+// Upload adds an emoji to a server. Requires PermManageEmoji. The image must be a
+// PNG, GIF or WebP of at most MaxImageSize bytes; filename is only used for the scan.
+// Complexity: O(b) where b = image size
+func (s *Service) Upload(ctx context.Context, serverID, userID, name, filename string, data []byte) (*Emoji, error) {
+	if err := s.servers.CheckPermission(ctx, serverID, userID, server.PermManageEmoji); err != nil {
+		return nil, err
+	}
+	if !markdown.IsEmojiName(name) {
+		return nil, fmt.Errorf("emoji names are 2 to 32 letters, digits or underscores")
+	}
+	if len(data) > MaxImageSize {
+		return nil, fmt.Errorf("emoji images are limited to %d KB", MaxImageSize>>10)
+	}
+	scan := s.scanner.ScanBytes(data, filename)
+	if !scan.Valid {
+		return nil, fmt.Errorf("invalid emoji image: %s", scan.Error)
+	}
+	ext, ok := imageExtensions[scan.MimeType]
+	if !ok {
+		return nil, fmt.Errorf("emoji images must be PNG, GIF or WebP")
+	}
+
+	count, err := s.repo.Count(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxEmojiPerServer {
+		return nil, fmt.Errorf("a server can have at most %d emoji", maxEmojiPerServer)
+	}
+	if err := s.requireFreeName(ctx, serverID, name); err != nil {
+		return nil, err
+	}
+
+	e := &Emoji{
+		ID:        uuid.New().String(),
+		ServerID:  serverID,
+		Name:      name,
+		MimeType:  scan.MimeType,
+		SizeBytes: scan.Size,
+		Animated:  scan.MimeType == "image/gif",
+		CreatedBy: userID,
+		CreatedAt: s.now(),
+	}
+	e.LocalPath, err = s.storage.Save(e.ID+ext, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to store emoji image: %w", err)
+	}
+	if err := s.repo.Create(ctx, e); err != nil {
+		s.removeImage(e)
+		return nil, err
+	}
+
+	s.logger.Info().
+		Str("emoji_id", e.ID).
+		Str("server_id", serverID).
+		Str("name", name).
+		Str("user_id", userID).
+		Msg("emoji uploaded")
+	return e, nil
+}
+
+// List returns the emoji of a server to one of its members.
+// Complexity: O(n log n) where n = number of emoji in the server
+func (s *Service) List(ctx context.Context, serverID, userID string) ([]*Emoji, error) {
+	member, err := s.servers.IsMember(ctx, serverID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !member {
+		return nil, fmt.Errorf("not a member of this server")
+	}
+	return s.repo.List(ctx, serverID)
+}
+
+// Rename changes the name of an emoji. Requires PermManageEmoji. Messages keep
+// working since they reference emoji by ID.
+// Complexity: O(1)
+func (s *Service) Rename(ctx context.Context, serverID, userID, emojiID, name string) (*Emoji, error) {
+	e, err := s.managedEmoji(ctx, serverID, userID, emojiID)
+	if err != nil {
+		return nil, err
+	}
+	if !markdown.IsEmojiName(name) {
+		return nil, fmt.Errorf("emoji names are 2 to 32 letters, digits or underscores")
+	}
+	if name == e.Name {
+		return e, nil
+	}
+	if err := s.requireFreeName(ctx, serverID, name); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Rename(ctx, emojiID, name); err != nil {
+		return nil, err
+	}
+	e.Name = name
+	return e, nil
+}
+
+// Delete removes an emoji, its image and the reactions that used it. Requires PermManageEmoji.
+// Complexity: O(r) where r = number of reactions with the emoji
+func (s *Service) Delete(ctx context.Context, serverID, userID, emojiID string) error {
+	e, err := s.managedEmoji(ctx, serverID, userID, emojiID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, emojiID); err != nil {
+		return err
+	}
+	s.removeImage(e)
+
+	s.logger.Info().
+		Str("emoji_id", emojiID).
+		Str("server_id", serverID).
+		Str("user_id", userID).
+		Msg("emoji deleted")
+	return nil
+}
+
+// Image opens the image of an emoji. Emoji images are not secret: anyone signed in
+// can load them, as messages of other servers may still show their names.
+// Complexity: O(1)
+func (s *Service) Image(ctx context.Context, emojiID string) (*Emoji, io.ReadCloser, error) {
+	e, err := s.repo.Get(ctx, emojiID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if e == nil {
+		return nil, nil, fmt.Errorf("emoji not found")
+	}
+	rc, err := s.storage.Load(e.LocalPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return e, rc, nil
+}
+
+// ResolveEmoji rewrites the custom emoji tokens of a message posted in channelID:
+// :name: becomes <:name:id> when the channel's server has that emoji, and
+// <:name:id> tokens of emoji from other servers fall back to :name:.
+// Implements chat.EmojiResolver.
+// Complexity: O(c + n) where c = content length, n = number of emoji in the server
+func (s *Service) ResolveEmoji(ctx context.Context, channelID, content string) (string, error) {
+	if !strings.Contains(content, ":") {
+		return content, nil
+	}
+	ch, err := s.servers.GetChannel(ctx, channelID)
+	if err != nil {
+		return "", err
+	}
+	if ch == nil {
+		return content, nil
+	}
+	list, err := s.repo.List(ctx, ch.ServerID)
+	if err != nil {
+		return "", err
+	}
+
+	byName := make(map[string]*Emoji, len(list))
+	byID := make(map[string]*Emoji, len(list))
+	for _, e := range list {
+		byName[e.Name] = e
+		byID[e.ID] = e
+	}
+	return markdown.RewriteEmoji(content, func(token, name, emojiID string) string {
+		if e := byID[emojiID]; e != nil {
+			return e.Token()
+		}
+		if e := byName[name]; e != nil {
+			return e.Token()
+		}
+		return ":" + name + ":"
+	}), nil
+}
+
+// ChannelEmoji reports whether emojiID is a custom emoji of channelID's server.
+// Implements chat.EmojiResolver.
+// Complexity: O(1)
+func (s *Service) ChannelEmoji(ctx context.Context, channelID, emojiID string) (bool, error) {
+	ch, err := s.servers.GetChannel(ctx, channelID)
+	if err != nil || ch == nil {
+		return false, err
+	}
+	e, err := s.repo.Get(ctx, emojiID)
+	if err != nil {
+		return false, err
+	}
+	return e != nil && e.ServerID == ch.ServerID, nil
+}
+
+// managedEmoji checks PermManageEmoji and returns an emoji of the server.
+func (s *Service) managedEmoji(ctx context.Context, serverID, userID, emojiID string) (*Emoji, error) {
+	if err := s.servers.CheckPermission(ctx, serverID, userID, server.PermManageEmoji); err != nil {
+		return nil, err
+	}
+	e, err := s.repo.Get(ctx, emojiID)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil || e.ServerID != serverID {
+		return nil, fmt.Errorf("emoji not found")
+	}
+	return e, nil
+}
+
+// requireFreeName fails when the server already has an emoji called name.
+func (s *Service) requireFreeName(ctx context.Context, serverID, name string) error {
+	taken, err := s.repo.NameTaken(ctx, serverID, name)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return fmt.Errorf("an emoji named :%s: already exists", name)
+	}
+	return nil
+}
+
+// removeImage deletes the stored image of an emoji, logging failures.
+func (s *Service) removeImage(e *Emoji) {
+	if err := s.storage.Delete(e.LocalPath); err != nil {
+		s.logger.Warn().Err(err).Str("emoji_id", e.ID).Msg("failed to remove emoji image")
+	}
+}
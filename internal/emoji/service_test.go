@@ -0,0 +1,180 @@
+package emoji
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/concord-chat/concord/internal/cache"
+	"github.com/concord-chat/concord/internal/chat"
+	"github.com/concord-chat/concord/internal/files"
+	"github.com/concord-chat/concord/internal/server"
+	"github.com/concord-chat/concord/internal/store/sqlite"
+)
+
+func setupService(t *testing.T) (*Service, *chat.Service) {
+	t.Helper()
+	logger := zerolog.Nop()
+	db, err := sqlite.New(sqlite.Config{
+		Path:         filepath.Join(t.TempDir(), "test.db"),
+		MaxOpenConns: 1,
+		ForeignKeys:  true,
+		BusyTimeout:  5 * time.Second,
+	}, logger)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	ctx := context.Background()
+	require.NoError(t, sqlite.NewMigrator(db, logger).Migrate(ctx))
+
+	for _, stmt := range []string{
+		`INSERT INTO users (id, username) VALUES ('owner', 'owner'), ('admin', 'admin'), ('member', 'member'), ('stranger', 'stranger')`,
+		`INSERT INTO servers (id, name, owner_id) VALUES ('srv-1', 'Test', 'owner'), ('srv-2', 'Other', 'stranger')`,
+		`INSERT INTO server_members (server_id, user_id) VALUES ('srv-1', 'owner'), ('srv-1', 'admin'), ('srv-1', 'member'), ('srv-2', 'stranger')`,
+		`INSERT INTO server_roles (id, server_id, name, position, permissions) VALUES ('srv-1:admin', 'srv-1', 'Admin', 1, 254)`,
+		`INSERT INTO server_member_roles (server_id, user_id, role_id) VALUES ('srv-1', 'admin', 'srv-1:admin')`,
+		`INSERT INTO channels (id, server_id, name, type) VALUES ('ch-1', 'srv-1', 'general', 'text'), ('ch-2', 'srv-2', 'general', 'text')`,
+	} {
+		_, err := db.ExecContext(ctx, stmt)
+		require.NoError(t, err)
+	}
+
+	storage, err := files.NewLocalStorage(t.TempDir(), logger)
+	require.NoError(t, err)
+	servers := server.NewService(server.NewRepository(db, server.NewStdlibTransactor(db.Conn()), logger), cache.NewLRU(100), logger)
+	svc := NewService(NewRepository(db, logger), storage, servers, logger)
+
+	chatSvc := chat.NewService(chat.NewRepository(db, logger), logger)
+	chatSvc.SetSendPolicy(servers)
+	chatSvc.SetEmojiResolver(svc)
+	return svc, chatSvc
+}
+
+func pngImage(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 8, 8))))
+	return buf.Bytes()
+}
+
+func gifImage(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, gif.Encode(&buf, image.NewPaletted(image.Rect(0, 0, 8, 8), []color.Color{color.White}), nil))
+	return buf.Bytes()
+}
+
+func TestUpload(t *testing.T) {
+	svc, _ := setupService(t)
+	ctx := context.Background()
+
+	e, err := svc.Upload(ctx, "srv-1", "admin", "blob", "blob.png", pngImage(t))
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", e.MimeType)
+	assert.False(t, e.Animated)
+	assert.Equal(t, "<:blob:"+e.ID+">", e.Token())
+
+	party, err := svc.Upload(ctx, "srv-1", "owner", "party", "party.gif", gifImage(t))
+	require.NoError(t, err)
+	assert.True(t, party.Animated)
+
+	got, rc, err := svc.Image(ctx, e.ID)
+	require.NoError(t, err)
+	data, err := io.ReadAll(rc)
+	rc.Close()
+	require.NoError(t, err)
+	assert.Equal(t, pngImage(t), data)
+	assert.Equal(t, e.ID, got.ID)
+
+	list, err := svc.List(ctx, "srv-1", "member")
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "blob", list[0].Name)
+	_, err = svc.List(ctx, "srv-1", "stranger")
+	assert.Error(t, err)
+}
+
+func TestUpload_Validation(t *testing.T) {
+	svc, _ := setupService(t)
+	ctx := context.Background()
+
+	_, err := svc.Upload(ctx, "srv-1", "member", "blob", "blob.png", pngImage(t))
+	assert.Error(t, err, "needs PermManageEmoji")
+	_, err = svc.Upload(ctx, "srv-1", "admin", "no spaces", "blob.png", pngImage(t))
+	assert.Error(t, err, "invalid name")
+	_, err = svc.Upload(ctx, "srv-1", "admin", "notes", "notes.png", []byte("just some text"))
+	assert.Error(t, err, "not an image")
+	_, err = svc.Upload(ctx, "srv-1", "admin", "big", "big.png", append(pngImage(t), make([]byte, MaxImageSize)...))
+	assert.Error(t, err, "too large")
+	_, err = svc.Upload(ctx, "srv-1", "admin", "blob", "blob.exe", pngImage(t))
+	assert.Error(t, err, "blocked extension")
+
+	_, err = svc.Upload(ctx, "srv-1", "admin", "blob", "blob.png", pngImage(t))
+	require.NoError(t, err)
+	_, err = svc.Upload(ctx, "srv-1", "admin", "blob", "blob.png", pngImage(t))
+	assert.Error(t, err, "duplicate name")
+}
+
+func TestRenameAndDelete(t *testing.T) {
+	svc, chatSvc := setupService(t)
+	ctx := context.Background()
+
+	e, err := svc.Upload(ctx, "srv-1", "admin", "blob", "blob.png", pngImage(t))
+	require.NoError(t, err)
+	_, err = svc.Upload(ctx, "srv-1", "admin", "wave", "wave.png", pngImage(t))
+	require.NoError(t, err)
+
+	_, err = svc.Rename(ctx, "srv-1", "member", e.ID, "blobby")
+	assert.Error(t, err, "needs PermManageEmoji")
+	_, err = svc.Rename(ctx, "srv-1", "admin", e.ID, "wave")
+	assert.Error(t, err, "name taken")
+	_, err = svc.Rename(ctx, "srv-2", "stranger", e.ID, "mine")
+	assert.Error(t, err, "emoji of another server")
+	renamed, err := svc.Rename(ctx, "srv-1", "admin", e.ID, "blobby")
+	require.NoError(t, err)
+	assert.Equal(t, "blobby", renamed.Name)
+
+	msg, err := chatSvc.SendMessage(ctx, "ch-1", "member", "hi")
+	require.NoError(t, err)
+	_, err = chatSvc.AddReaction(ctx, msg.ID, "member", "blobby:"+e.ID)
+	require.NoError(t, err)
+
+	require.NoError(t, svc.Delete(ctx, "srv-1", "owner", e.ID))
+	_, _, err = svc.Image(ctx, e.ID)
+	assert.Error(t, err)
+	got, err := chatSvc.GetMessage(ctx, msg.ID)
+	require.NoError(t, err)
+	assert.Empty(t, got.Reactions, "reactions with the emoji are removed")
+}
+
+func TestResolveEmoji(t *testing.T) {
+	svc, chatSvc := setupService(t)
+	ctx := context.Background()
+
+	blob, err := svc.Upload(ctx, "srv-1", "admin", "blob", "blob.png", pngImage(t))
+	require.NoError(t, err)
+	party, err := svc.Upload(ctx, "srv-1", "admin", "party", "party.gif", gifImage(t))
+	require.NoError(t, err)
+	foreign, err := svc.Upload(ctx, "srv-2", "stranger", "secret", "secret.png", pngImage(t))
+	require.NoError(t, err)
+
+	msg, err := chatSvc.SendMessage(ctx, "ch-1", "member",
+		"hi :blob: :party: :unknown: `:blob:` <:secret:"+foreign.ID+"> <:old_name:"+blob.ID+">")
+	require.NoError(t, err)
+	assert.Equal(t, "hi <:blob:"+blob.ID+"> <a:party:"+party.ID+"> :unknown: `:blob:` :secret: <:blob:"+blob.ID+">", msg.Content)
+
+	_, err = chatSvc.AddReaction(ctx, msg.ID, "member", "secret:"+foreign.ID)
+	assert.Error(t, err, "emoji of another server")
+	_, err = chatSvc.AddReaction(ctx, msg.ID, "stranger", "👍")
+	assert.ErrorIs(t, err, chat.ErrSendForbidden, "only members react")
+}
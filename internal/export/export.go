@@ -0,0 +1,202 @@
+// Package export writes the full history of a channel or direct conversation
+// as a portable archive: JSON for machines, a self-contained HTML page or
+// Markdown for humans. History is read page by page from the repositories and
+// written as it arrives, so the size of a channel never has to fit in memory.
+package export
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// pageSize is how many messages are read from the store per round trip.
+const pageSize = 500
+
+// Format is an export file format.
+type Format string
+
+const (
+	FormatJSON     Format = "json"
+	FormatHTML     Format = "html"
+	FormatMarkdown Format = "markdown"
+)
+
+// ParseFormat parses a format name. "md" is accepted for Markdown and an
+// empty name defaults to JSON.
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "json":
+		return FormatJSON, nil
+	case "html", "htm":
+		return FormatHTML, nil
+	case "markdown", "md":
+		return FormatMarkdown, nil
+	default:
+		return "", fmt.Errorf("unsupported export format %q (use json, html or markdown)", s)
+	}
+}
+
+// Extension returns the file extension for the format, without the dot.
+func (f Format) Extension() string {
+	if f == FormatMarkdown {
+		return "md"
+	}
+	return string(f)
+}
+
+// ContentType returns the MIME type for the format.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatHTML:
+		return "text/html; charset=utf-8"
+	case FormatMarkdown:
+		return "text/markdown; charset=utf-8"
+	default:
+		return "application/json"
+	}
+}
+
+// Kind of conversation being exported.
+const (
+	KindChannel = "channel"
+	KindDM      = "dm"
+)
+
+// Meta describes the exported conversation.
+type Meta struct {
+	Kind       string `json:"kind"` // KindChannel or KindDM
+	ID         string `json:"id"`   // channel ID, or the other user's ID for a DM
+	Name       string `json:"name"`
+	ServerID   string `json:"server_id,omitempty"`
+	ServerName string `json:"server_name,omitempty"`
+	ExportedBy string `json:"exported_by,omitempty"`
+	ExportedAt string `json:"exported_at"` // RFC 3339
+}
+
+// Title returns a human-readable title such as "#general (My Server)".
+func (m Meta) Title() string {
+	if m.Kind == KindDM {
+		return "Direct messages with " + m.Name
+	}
+	title := "#" + m.Name
+	if m.ServerName != "" {
+		title += " (" + m.ServerName + ")"
+	}
+	return title
+}
+
+// Filename suggests a file name for an export in format f.
+func (m Meta) Filename(f Format) string {
+	name := m.Name
+	if name == "" {
+		name = m.ID
+	}
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('-')
+		}
+	}
+	slug := strings.Trim(b.String(), "-")
+	if slug == "" {
+		slug = "history"
+	}
+	date := m.ExportedAt
+	if len(date) >= 10 {
+		date = date[:10]
+	}
+	return fmt.Sprintf("concord-%s-%s-%s.%s", m.Kind, slug, date, f.Extension())
+}
+
+// Attachment is the metadata of an attached file. File contents are not exported.
+type Attachment struct {
+	ID        string `json:"id"`
+	Filename  string `json:"filename"`
+	SizeBytes int64  `json:"size_bytes"`
+	MimeType  string `json:"mime_type"`
+	Hash      string `json:"hash"`
+}
+
+// Message is one exported message.
+type Message struct {
+	ID          string       `json:"id"`
+	AuthorID    string       `json:"author_id"`
+	AuthorName  string       `json:"author_name"`
+	Content     string       `json:"content"`
+	Type        string       `json:"type"`
+	CreatedAt   string       `json:"created_at"`
+	EditedAt    *string      `json:"edited_at,omitempty"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+}
+
+// Source yields the history of one conversation in chronological order.
+// Next returns an empty page once the history is exhausted.
+type Source interface {
+	Next(ctx context.Context) ([]Message, error)
+}
+
+// writer renders one format. begin and end are called exactly once.
+type writer interface {
+	begin(meta Meta) error
+	message(m Message) error
+	end(count int) error
+}
+
+func newWriter(w io.Writer, f Format) (writer, error) {
+	switch f {
+	case FormatJSON:
+		return &jsonWriter{w: w}, nil
+	case FormatHTML:
+		return &htmlWriter{w: w}, nil
+	case FormatMarkdown:
+		return &markdownWriter{w: w}, nil
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", f)
+	}
+}
+
+// Write streams every message of src to w in format f and returns how many
+// messages were written. ExportedAt defaults to the current time.
+// Complexity: O(n) time, O(pageSize) memory
+func Write(ctx context.Context, w io.Writer, f Format, meta Meta, src Source) (int, error) {
+	out, err := newWriter(w, f)
+	if err != nil {
+		return 0, err
+	}
+	if meta.ExportedAt == "" {
+		meta.ExportedAt = time.Now().UTC().Format(time.RFC3339)
+	}
+
+	if err := out.begin(meta); err != nil {
+		return 0, fmt.Errorf("failed to write export: %w", err)
+	}
+	count := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return count, err
+		}
+		page, err := src.Next(ctx)
+		if err != nil {
+			return count, err
+		}
+		if len(page) == 0 {
+			break
+		}
+		for _, m := range page {
+			if err := out.message(m); err != nil {
+				return count, fmt.Errorf("failed to write export: %w", err)
+			}
+			count++
+		}
+	}
+	if err := out.end(count); err != nil {
+		return count, fmt.Errorf("failed to write export: %w", err)
+	}
+	return count, nil
+}
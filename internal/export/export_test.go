@@ -0,0 +1,184 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/concord-chat/concord/internal/chat"
+	"github.com/concord-chat/concord/internal/friends"
+	"github.com/concord-chat/concord/internal/store/sqlite"
+)
+
+func setupService(t *testing.T) (*Service, *sqlite.DB) {
+	t.Helper()
+	logger := zerolog.Nop()
+	db, err := sqlite.New(sqlite.Config{
+		Path:         filepath.Join(t.TempDir(), "test.db"),
+		MaxOpenConns: 1,
+		ForeignKeys:  true,
+		BusyTimeout:  5 * time.Second,
+	}, logger)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	ctx := context.Background()
+	require.NoError(t, sqlite.NewMigrator(db, logger).Migrate(ctx))
+
+	for _, stmt := range []string{
+		`INSERT INTO users (id, username) VALUES ('alice', 'alice'), ('bob', 'bob')`,
+		`INSERT INTO servers (id, name, owner_id) VALUES ('srv-1', 'Test', 'alice')`,
+		`INSERT INTO channels (id, server_id, name) VALUES ('ch-1', 'srv-1', 'general')`,
+	} {
+		_, err := db.ExecContext(ctx, stmt)
+		require.NoError(t, err)
+	}
+
+	friendsRepo := friends.NewRepository(db, friends.NewStdlibTransactor(db.Conn()), logger)
+	return NewService(chat.NewRepository(db, logger), friendsRepo, logger), db
+}
+
+type jsonExport struct {
+	Version      int       `json:"version"`
+	Export       Meta      `json:"export"`
+	Messages     []Message `json:"messages"`
+	MessageCount int       `json:"message_count"`
+}
+
+func TestExportChannel_JSONWalksEveryPage(t *testing.T) {
+	svc, db := setupService(t)
+	ctx := context.Background()
+
+	// More than two pages, all sharing one timestamp so only the id tiebreak orders them.
+	const total = 2*pageSize + 7
+	for i := 0; i < total; i++ {
+		_, err := db.ExecContext(ctx,
+			`INSERT INTO messages (id, channel_id, author_id, content, created_at) VALUES (?, 'ch-1', 'alice', ?, '2026-01-15 10:00:00')`,
+			fmt.Sprintf("m-%04d", i), fmt.Sprintf("message %d", i))
+		require.NoError(t, err)
+	}
+	_, err := db.ExecContext(ctx, `UPDATE messages SET edited_at = '2026-01-15 11:00:00' WHERE id = 'm-0001'`)
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx,
+		`INSERT INTO attachments (id, message_id, filename, size_bytes, mime_type, hash) VALUES ('att-1', 'm-0002', 'plan.pdf', 2048, 'application/pdf', 'abc')`)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	n, err := svc.ExportChannel(ctx, &buf, FormatJSON, Meta{ID: "ch-1", Name: "general", ServerName: "Test"})
+	require.NoError(t, err)
+	assert.Equal(t, total, n)
+
+	var out jsonExport
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+	assert.Equal(t, 1, out.Version)
+	assert.Equal(t, KindChannel, out.Export.Kind)
+	assert.Equal(t, total, out.MessageCount)
+	require.Len(t, out.Messages, total)
+	for i, m := range out.Messages {
+		require.Equal(t, fmt.Sprintf("m-%04d", i), m.ID, "messages are complete and in order")
+	}
+	assert.Equal(t, "alice", out.Messages[0].AuthorName)
+	assert.NotNil(t, out.Messages[1].EditedAt)
+	require.Len(t, out.Messages[2].Attachments, 1)
+	assert.Equal(t, "plan.pdf", out.Messages[2].Attachments[0].Filename)
+}
+
+func TestExportChannel_EmptyJSONIsValid(t *testing.T) {
+	svc, _ := setupService(t)
+
+	var buf bytes.Buffer
+	n, err := svc.ExportChannel(context.Background(), &buf, FormatJSON, Meta{ID: "ch-1", Name: "general"})
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	var out jsonExport
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+	assert.Empty(t, out.Messages)
+}
+
+func TestExportChannel_HTMLAndMarkdown(t *testing.T) {
+	svc, db := setupService(t)
+	ctx := context.Background()
+
+	for _, stmt := range []string{
+		`INSERT INTO messages (id, channel_id, author_id, content, created_at) VALUES ('m-1', 'ch-1', 'alice', '<script>alert(1)</script> **hi**', '2026-01-15 10:00:00')`,
+		`INSERT INTO messages (id, channel_id, author_id, content, created_at) VALUES ('m-2', 'ch-1', 'bob', 'see file', '2026-01-15 10:01:00')`,
+		`INSERT INTO attachments (id, message_id, filename, size_bytes, mime_type, hash) VALUES ('att-1', 'm-2', 'photo.png', 1536, 'image/png', 'abc')`,
+	} {
+		_, err := db.ExecContext(ctx, stmt)
+		require.NoError(t, err)
+	}
+	meta := Meta{ID: "ch-1", Name: "general", ServerName: "Test"}
+
+	var page bytes.Buffer
+	_, err := svc.ExportChannel(ctx, &page, FormatHTML, meta)
+	require.NoError(t, err)
+	html := page.String()
+	assert.True(t, strings.HasPrefix(html, "<!DOCTYPE html>"))
+	assert.Contains(t, html, "<title>#general (Test)</title>")
+	assert.NotContains(t, html, "<script>", "content is escaped")
+	assert.Contains(t, html, "&lt;script&gt;")
+	assert.Contains(t, html, "photo.png &middot; 1.5 KB &middot; image/png")
+	assert.NotContains(t, html, "http", "the page loads no remote resources")
+
+	var md bytes.Buffer
+	_, err = svc.ExportChannel(ctx, &md, FormatMarkdown, meta)
+	require.NoError(t, err)
+	assert.Contains(t, md.String(), "# \\#general (Test)")
+	assert.Contains(t, md.String(), "### alice")
+	assert.Contains(t, md.String(), "**hi**", "markdown content is kept as written")
+	assert.Contains(t, md.String(), "- Attachment: `photo.png` (1.5 KB, image/png)")
+	assert.Contains(t, md.String(), "2 messages")
+}
+
+func TestExportDM(t *testing.T) {
+	svc, db := setupService(t)
+	ctx := context.Background()
+
+	for i, sender := range []string{"alice", "bob", "alice"} {
+		receiver := "bob"
+		if sender == "bob" {
+			receiver = "alice"
+		}
+		_, err := db.ExecContext(ctx,
+			`INSERT INTO friend_messages (id, sender_id, receiver_id, content, created_at) VALUES (?, ?, ?, ?, ?)`,
+			fmt.Sprintf("dm-%d", i), sender, receiver, fmt.Sprintf("hello %d", i), fmt.Sprintf("2026-01-15 10:00:0%d", i))
+		require.NoError(t, err)
+	}
+
+	var buf bytes.Buffer
+	n, err := svc.ExportDM(ctx, &buf, FormatJSON, "alice", Meta{ID: "bob"})
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	var out jsonExport
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+	assert.Equal(t, KindDM, out.Export.Kind)
+	assert.Equal(t, "bob", out.Export.Name, "the other user's name is resolved")
+	require.Len(t, out.Messages, 3)
+	assert.Equal(t, []string{"alice", "bob", "alice"},
+		[]string{out.Messages[0].AuthorName, out.Messages[1].AuthorName, out.Messages[2].AuthorName})
+}
+
+func TestParseFormat(t *testing.T) {
+	for in, want := range map[string]Format{"": FormatJSON, "JSON": FormatJSON, "html": FormatHTML, "md": FormatMarkdown, "markdown": FormatMarkdown} {
+		got, err := ParseFormat(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+	_, err := ParseFormat("pdf")
+	assert.Error(t, err)
+}
+
+func TestMeta_Filename(t *testing.T) {
+	meta := Meta{Kind: KindChannel, ID: "ch-1", Name: "Off Topic!", ExportedAt: "2026-01-15T10:00:00Z"}
+	assert.Equal(t, "concord-channel-off-topic-2026-01-15.md", meta.Filename(FormatMarkdown))
+}
@@ -0,0 +1,78 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/rs/zerolog"
+
+	"github.com/concord-chat/concord/internal/chat"
+	"github.com/concord-chat/concord/internal/friends"
+)
+
+// Service exports channel and direct message history. It does not check
+// access; callers authorize the requesting user first.
+type Service struct {
+	chat    *chat.Repository
+	friends *friends.Repository
+	logger  zerolog.Logger
+}
+
+// NewService creates an export service. Either repository may be nil when the
+// corresponding kind of export is not offered.
+func NewService(chatRepo *chat.Repository, friendsRepo *friends.Repository, logger zerolog.Logger) *Service {
+	return &Service{
+		chat:    chatRepo,
+		friends: friendsRepo,
+		logger:  logger.With().Str("component", "export_service").Logger(),
+	}
+}
+
+// ExportChannel streams the history of meta.ID (a channel) to w.
+// Complexity: O(n) time, O(pageSize) memory
+func (s *Service) ExportChannel(ctx context.Context, w io.Writer, f Format, meta Meta) (int, error) {
+	if s.chat == nil {
+		return 0, fmt.Errorf("channel export not available")
+	}
+	meta.Kind = KindChannel
+	return s.run(ctx, w, f, meta, NewChannelSource(s.chat, meta.ID))
+}
+
+// ExportDM streams the conversation between userID and meta.ID (the other user) to w.
+// An empty meta.Name is filled with the other user's username.
+// Complexity: O(n) time, O(pageSize) memory
+func (s *Service) ExportDM(ctx context.Context, w io.Writer, f Format, userID string, meta Meta) (int, error) {
+	if s.friends == nil {
+		return 0, fmt.Errorf("direct message export not available")
+	}
+	meta.Kind = KindDM
+	if meta.Name == "" {
+		names, err := s.friends.GetUsernames(ctx, meta.ID)
+		if err != nil {
+			return 0, err
+		}
+		meta.Name = names[meta.ID]
+	}
+	return s.run(ctx, w, f, meta, NewDMSource(s.friends, userID, meta.ID))
+}
+
+func (s *Service) run(ctx context.Context, w io.Writer, f Format, meta Meta, src Source) (int, error) {
+	n, err := Write(ctx, w, f, meta, src)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("kind", meta.Kind).
+			Str("id", meta.ID).
+			Int("written", n).
+			Msg("export failed")
+		return n, err
+	}
+
+	s.logger.Info().
+		Str("kind", meta.Kind).
+		Str("id", meta.ID).
+		Str("format", string(f)).
+		Int("messages", n).
+		Msg("history exported")
+	return n, nil
+}
@@ -0,0 +1,134 @@
+package export
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/concord-chat/concord/internal/chat"
+	"github.com/concord-chat/concord/internal/friends"
+)
+
+// ChannelSource walks a channel with chat.Repository.ListAfter and attaches
+// attachment metadata per page.
+type ChannelSource struct {
+	repo      *chat.Repository
+	channelID string
+	cursor    string
+	done      bool
+}
+
+// NewChannelSource creates a source for the history of channelID.
+func NewChannelSource(repo *chat.Repository, channelID string) *ChannelSource {
+	return &ChannelSource{repo: repo, channelID: channelID}
+}
+
+// Next returns the next page of channel messages.
+// Complexity: O(log n + pageSize)
+func (s *ChannelSource) Next(ctx context.Context) ([]Message, error) {
+	if s.done {
+		return nil, nil
+	}
+	msgs, err := s.repo.ListAfter(ctx, s.channelID, s.cursor, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read channel history: %w", err)
+	}
+	if len(msgs) < pageSize {
+		s.done = true
+	}
+	if len(msgs) == 0 {
+		return nil, nil
+	}
+	s.cursor = msgs[len(msgs)-1].ID
+
+	ids := make([]string, len(msgs))
+	for i, m := range msgs {
+		ids[i] = m.ID
+	}
+	attachments, err := s.repo.GetAttachments(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attachments: %w", err)
+	}
+
+	page := make([]Message, len(msgs))
+	for i, m := range msgs {
+		page[i] = Message{
+			ID:         m.ID,
+			AuthorID:   m.AuthorID,
+			AuthorName: m.AuthorName,
+			Content:    m.Content,
+			Type:       m.Type,
+			CreatedAt:  m.CreatedAt,
+			EditedAt:   m.EditedAt,
+		}
+		for _, a := range attachments[m.ID] {
+			page[i].Attachments = append(page[i].Attachments, Attachment{
+				ID:        a.ID,
+				Filename:  a.Filename,
+				SizeBytes: a.SizeBytes,
+				MimeType:  a.MimeType,
+				Hash:      a.Hash,
+			})
+		}
+	}
+	return page, nil
+}
+
+// DMSource walks the direct conversation between two users with
+// friends.Repository.ListDirectMessagesAfter.
+type DMSource struct {
+	repo     *friends.Repository
+	userID   string
+	friendID string
+	names    map[string]string
+	cursor   string
+	done     bool
+}
+
+// NewDMSource creates a source for the conversation between userID and friendID.
+func NewDMSource(repo *friends.Repository, userID, friendID string) *DMSource {
+	return &DMSource{repo: repo, userID: userID, friendID: friendID}
+}
+
+// Next returns the next page of direct messages.
+// Complexity: O(log n + pageSize)
+func (s *DMSource) Next(ctx context.Context) ([]Message, error) {
+	if s.done {
+		return nil, nil
+	}
+	if s.names == nil {
+		names, err := s.repo.GetUsernames(ctx, s.userID, s.friendID)
+		if err != nil {
+			return nil, err
+		}
+		s.names = names
+	}
+
+	msgs, err := s.repo.ListDirectMessagesAfter(ctx, s.userID, s.friendID, s.cursor, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read direct messages: %w", err)
+	}
+	if len(msgs) < pageSize {
+		s.done = true
+	}
+	if len(msgs) == 0 {
+		return nil, nil
+	}
+	s.cursor = msgs[len(msgs)-1].ID
+
+	page := make([]Message, len(msgs))
+	for i, m := range msgs {
+		name := s.names[m.SenderID]
+		if name == "" {
+			name = m.SenderID
+		}
+		page[i] = Message{
+			ID:         m.ID,
+			AuthorID:   m.SenderID,
+			AuthorName: name,
+			Content:    m.Content,
+			Type:       "text",
+			CreatedAt:  m.CreatedAt,
+		}
+	}
+	return page, nil
+}
@@ -0,0 +1,177 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+// jsonWriter writes {"export": meta, "messages": [...], "message_count": n},
+// encoding one message at a time.
+type jsonWriter struct {
+	w     io.Writer
+	count int
+}
+
+func (j *jsonWriter) begin(meta Meta) error {
+	head, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(j.w, "{\n  \"version\": 1,\n  \"export\": %s,\n  \"messages\": [", head)
+	return err
+}
+
+func (j *jsonWriter) message(m Message) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	sep := ",\n    "
+	if j.count == 0 {
+		sep = "\n    "
+	}
+	j.count++
+	_, err = fmt.Fprintf(j.w, "%s%s", sep, data)
+	return err
+}
+
+func (j *jsonWriter) end(count int) error {
+	closing := "\n  ]"
+	if count == 0 {
+		closing = "]"
+	}
+	_, err := fmt.Fprintf(j.w, "%s,\n  \"message_count\": %d\n}\n", closing, count)
+	return err
+}
+
+// markdownWriter writes a heading per author run and keeps message content as
+// written, since Concord messages are already Markdown.
+type markdownWriter struct {
+	w          io.Writer
+	lastAuthor string
+}
+
+func (m *markdownWriter) begin(meta Meta) error {
+	_, err := fmt.Fprintf(m.w, "# %s\n\nExported %s\n", markdownEscape(meta.Title()), meta.ExportedAt)
+	return err
+}
+
+func (m *markdownWriter) message(msg Message) error {
+	var b strings.Builder
+	if msg.AuthorID != m.lastAuthor {
+		fmt.Fprintf(&b, "\n### %s\n", markdownEscape(msg.AuthorName))
+		m.lastAuthor = msg.AuthorID
+	}
+	fmt.Fprintf(&b, "\n*%s*", msg.CreatedAt)
+	if msg.EditedAt != nil {
+		fmt.Fprintf(&b, " *(edited %s)*", *msg.EditedAt)
+	}
+	if msg.Type == "poll" {
+		b.WriteString(" **Poll:**")
+	}
+	b.WriteString("\n\n")
+	if msg.Content != "" {
+		b.WriteString(msg.Content)
+		b.WriteString("\n")
+	}
+	for _, a := range msg.Attachments {
+		fmt.Fprintf(&b, "\n- Attachment: `%s` (%s, %s)", strings.ReplaceAll(a.Filename, "`", "'"), humanSize(a.SizeBytes), a.MimeType)
+	}
+	if len(msg.Attachments) > 0 {
+		b.WriteString("\n")
+	}
+	_, err := io.WriteString(m.w, b.String())
+	return err
+}
+
+func (m *markdownWriter) end(count int) error {
+	_, err := fmt.Fprintf(m.w, "\n---\n\n%d messages\n", count)
+	return err
+}
+
+// markdownEscape escapes characters that would turn a plain name into markup.
+func markdownEscape(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "#", `\#`, "[", `\[`, "]", `\]`, "<", `\<`)
+	return r.Replace(s)
+}
+
+func humanSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGT"[exp])
+}
+
+// htmlWriter writes a single self-contained page: inline styles, no scripts
+// and no remote resources, so the archive opens offline.
+type htmlWriter struct {
+	w          io.Writer
+	lastAuthor string
+}
+
+var htmlTemplates = template.Must(template.New("export").Funcs(template.FuncMap{
+	"size": humanSize,
+}).Parse(`
+{{define "begin"}}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body{margin:0;background:#313338;color:#dbdee1;font:15px/1.45 system-ui,-apple-system,"Segoe UI",sans-serif}
+header{padding:16px 24px;border-bottom:1px solid #1e1f22;background:#2b2d31}
+header h1{margin:0;font-size:20px;color:#f2f3f5}
+header p{margin:4px 0 0;color:#949ba4;font-size:13px}
+main{padding:8px 24px 24px}
+.author{margin-top:16px;font-weight:600;color:#f2f3f5}
+.msg{padding:2px 0}
+.meta{color:#949ba4;font-size:12px;margin-right:8px}
+.content{white-space:pre-wrap;word-wrap:break-word}
+.poll{color:#c9cdfb;font-weight:600;margin-right:4px}
+.att{margin:4px 0;padding:6px 10px;border:1px solid #1e1f22;border-radius:4px;background:#2b2d31;display:inline-block;font-size:13px}
+footer{padding:16px 24px;color:#949ba4;font-size:12px;border-top:1px solid #1e1f22}
+</style>
+</head>
+<body>
+<header><h1>{{.Title}}</h1><p>Exported {{.Meta.ExportedAt}}</p></header>
+<main>
+{{end}}
+{{define "message"}}{{if .NewAuthor}}<div class="author">{{.AuthorName}}</div>
+{{end}}<div class="msg" id="m-{{.ID}}"><span class="meta">{{.CreatedAt}}{{with .EditedAt}} (edited {{.}}){{end}}</span>{{if eq .Type "poll"}}<span class="poll">Poll:</span>{{end}}<span class="content">{{.Content}}</span>{{range .Attachments}}
+<div class="att">{{.Filename}} &middot; {{size .SizeBytes}} &middot; {{.MimeType}}</div>{{end}}</div>
+{{end}}
+{{define "end"}}</main>
+<footer>{{.}} messages</footer>
+</body>
+</html>
+{{end}}`))
+
+func (h *htmlWriter) begin(meta Meta) error {
+	return htmlTemplates.ExecuteTemplate(h.w, "begin", struct {
+		Title string
+		Meta  Meta
+	}{meta.Title(), meta})
+}
+
+func (h *htmlWriter) message(m Message) error {
+	newAuthor := m.AuthorID != h.lastAuthor
+	h.lastAuthor = m.AuthorID
+	return htmlTemplates.ExecuteTemplate(h.w, "message", struct {
+		Message
+		NewAuthor bool
+	}{m, newAuthor})
+}
+
+func (h *htmlWriter) end(count int) error {
+	return htmlTemplates.ExecuteTemplate(h.w, "end", count)
+}
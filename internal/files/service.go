@@ -10,6 +10,8 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/rs/zerolog"
+
+	"github.com/concord-chat/concord/internal/security"
 )
 
 // Service orchestrates file upload, download, validation, and chunking.
@@ -18,7 +20,8 @@ type Service struct {
 	storage   Storage
 	scanner   *Scanner
 	chunker   *Chunker
-	transfers sync.Map // transferID -> *TransferState
+	transfers sync.Map              // transferID -> *TransferState
+	uploads   *security.RateLimiter // per-user upload limit (nil = unlimited)
 	logger    zerolog.Logger
 }
 
@@ -33,6 +36,27 @@ func NewService(repo *Repository, storage Storage, logger zerolog.Logger) *Servi
 	}
 }
 
+// SetUploadLimiter enables a per-user upload limit for UploadAs.
+// perHour <= 0 disables the limit.
+func (s *Service) SetUploadLimiter(perHour int) {
+	if perHour <= 0 {
+		s.uploads = nil
+		return
+	}
+	s.uploads = security.NewRateLimiter(perHour, time.Hour, perHour)
+}
+
+// UploadAs is Upload on behalf of a user, subject to the per-user upload limit.
+// Returns a *security.RateLimitError when the user must wait before uploading again.
+func (s *Service) UploadAs(ctx context.Context, uploaderID, messageID, filename string, data []byte) (*Attachment, error) {
+	if s.uploads != nil {
+		if ok, wait := s.uploads.AllowWithRetry(uploaderID); !ok {
+			return nil, &security.RateLimitError{Reason: "upload limit reached", RetryAfter: wait}
+		}
+	}
+	return s.Upload(ctx, messageID, filename, data)
+}
+
 // Upload validates and stores a file, creating an attachment record.
 func (s *Service) Upload(ctx context.Context, messageID, filename string, data []byte) (*Attachment, error) {
 	// Validate file
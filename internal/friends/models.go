@@ -1,5 +1,7 @@
 package friends
 
+import "github.com/concord-chat/concord/internal/markdown"
+
 // RequestStatus represents the state of a friend request.
 type RequestStatus string
 
@@ -39,6 +41,15 @@ type FriendView struct {
 	Status      string `json:"status"` // "online" | "offline"
 }
 
+// MessageStatus is the delivery state of a direct message as seen by its sender.
+type MessageStatus string
+
+const (
+	MessageSent      MessageStatus = "sent"      // stored on the server
+	MessageDelivered MessageStatus = "delivered" // fetched or acknowledged by the receiver
+	MessageRead      MessageStatus = "read"      // read by the receiver (only when both allow read receipts)
+)
+
 // DMPaginationOpts controls cursor-based pagination for direct messages.
 type DMPaginationOpts struct {
 	After string `json:"after"` // Message ID to load messages after (newer)
@@ -52,4 +63,10 @@ type DirectMessage struct {
 	ReceiverID string `json:"receiver_id"`
 	Content    string `json:"content"`
 	CreatedAt  string `json:"created_at"`
+	// Delivery markers; ReadAt is hidden unless both users allow read receipts
+	DeliveredAt *string       `json:"delivered_at,omitempty"`
+	ReadAt      *string       `json:"read_at,omitempty"`
+	Status      MessageStatus `json:"status"`
+	// Parsed formatting entities (filled by the service, not stored)
+	Markup *markdown.Document `json:"markup,omitempty"`
 }
@@ -400,13 +400,12 @@ func (r *Repository) SaveDirectMessage(ctx context.Context, senderID, receiverID
 		return nil, fmt.Errorf("save direct message: %w", err)
 	}
 
-	var msg DirectMessage
-	err = r.db.QueryRowContext(ctx,
-		`SELECT id, sender_id, receiver_id, content, created_at
+	msg, err := scanDirectMessage(r.db.QueryRowContext(ctx,
+		`SELECT `+directMessageColumns+`
 		 FROM friend_messages
 		 WHERE id = ?`,
 		id,
-	).Scan(&msg.ID, &msg.SenderID, &msg.ReceiverID, &msg.Content, &msg.CreatedAt)
+	))
 	if err != nil {
 		return nil, fmt.Errorf("read direct message: %w", err)
 	}
@@ -417,7 +416,25 @@ func (r *Repository) SaveDirectMessage(ctx context.Context, senderID, receiverID
 		Str("receiver_id", receiverID).
 		Msg("direct message saved")
 
-	return &msg, nil
+	return msg, nil
+}
+
+// GetDirectMessage returns a direct message by ID, or nil if not found.
+// Complexity: O(1).
+func (r *Repository) GetDirectMessage(ctx context.Context, id string) (*DirectMessage, error) {
+	msg, err := scanDirectMessage(r.db.QueryRowContext(ctx,
+		`SELECT `+directMessageColumns+`
+		 FROM friend_messages
+		 WHERE id = ?`,
+		id,
+	))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get direct message: %w", err)
+	}
+	return msg, nil
 }
 
 // GetDirectMessages lists direct messages between two users.
@@ -436,7 +453,7 @@ func (r *Repository) GetDirectMessages(ctx context.Context, userID, friendID str
 
 	if opts.After != "" {
 		query = `
-			SELECT id, sender_id, receiver_id, content, created_at
+			SELECT ` + directMessageColumns + `
 			FROM friend_messages
 			WHERE ((sender_id = ? AND receiver_id = ?) OR (sender_id = ? AND receiver_id = ?))
 			  AND created_at >= (SELECT created_at FROM friend_messages WHERE id = ?)
@@ -445,7 +462,7 @@ func (r *Repository) GetDirectMessages(ctx context.Context, userID, friendID str
 		args = []interface{}{userID, friendID, friendID, userID, opts.After, limit}
 	} else {
 		query = `
-			SELECT id, sender_id, receiver_id, content, created_at
+			SELECT ` + directMessageColumns + `
 			FROM friend_messages
 			WHERE ((sender_id = ? AND receiver_id = ?) OR (sender_id = ? AND receiver_id = ?))
 			ORDER BY created_at DESC, id DESC
@@ -461,11 +478,11 @@ func (r *Repository) GetDirectMessages(ctx context.Context, userID, friendID str
 
 	results := make([]DirectMessage, 0, limit)
 	for rows.Next() {
-		var msg DirectMessage
-		if err := rows.Scan(&msg.ID, &msg.SenderID, &msg.ReceiverID, &msg.Content, &msg.CreatedAt); err != nil {
+		msg, err := scanDirectMessage(rows)
+		if err != nil {
 			return nil, fmt.Errorf("scan direct message: %w", err)
 		}
-		results = append(results, msg)
+		results = append(results, *msg)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
@@ -473,3 +490,116 @@ func (r *Repository) GetDirectMessages(ctx context.Context, userID, friendID str
 
 	return results, nil
 }
+
+// ListDirectMessagesAfter returns up to limit messages between two users in
+// chronological order, starting after afterID (from the beginning when empty).
+// Used to walk a whole conversation page by page.
+// Complexity: O(log n + limit) with pair indexes.
+func (r *Repository) ListDirectMessagesAfter(ctx context.Context, userID, friendID, afterID string, limit int) ([]DirectMessage, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 500
+	}
+
+	query := `
+		SELECT ` + directMessageColumns + `
+		FROM friend_messages
+		WHERE ((sender_id = ? AND receiver_id = ?) OR (sender_id = ? AND receiver_id = ?))`
+	args := []interface{}{userID, friendID, friendID, userID}
+	if afterID != "" {
+		query += `
+		  AND (created_at > (SELECT created_at FROM friend_messages WHERE id = ?)
+		    OR (created_at = (SELECT created_at FROM friend_messages WHERE id = ?) AND id > ?))`
+		args = append(args, afterID, afterID, afterID)
+	}
+	query += `
+		ORDER BY created_at ASC, id ASC
+		LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list direct messages: %w", err)
+	}
+	defer rows.Close()
+
+	results := make([]DirectMessage, 0, limit)
+	for rows.Next() {
+		msg, err := scanDirectMessage(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan direct message: %w", err)
+		}
+		results = append(results, *msg)
+	}
+	return results, rows.Err()
+}
+
+// GetUsernames resolves user IDs to usernames. Unknown IDs are left out.
+// Complexity: O(k) where k = len(userIDs)
+func (r *Repository) GetUsernames(ctx context.Context, userIDs ...string) (map[string]string, error) {
+	names := make(map[string]string, len(userIDs))
+	for _, id := range userIDs {
+		var username string
+		err := r.db.QueryRowContext(ctx, `SELECT username FROM users WHERE id = ?`, id).Scan(&username)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("get username: %w", err)
+		}
+		names[id] = username
+	}
+	return names, nil
+}
+
+// MarkDirectMessages records that receiverID got (and, if read, read) the messages
+// senderID sent them, up to and including upToID (all messages when upToID is empty).
+// Existing markers are kept, so repeated acknowledgements are no-ops.
+// Complexity: O(log n + k) with the receiver/sender index
+func (r *Repository) MarkDirectMessages(ctx context.Context, receiverID, senderID, upToID string, read bool) (int64, error) {
+	set := `delivered_at = COALESCE(delivered_at, CURRENT_TIMESTAMP)`
+	pending := `delivered_at IS NULL`
+	if read {
+		set += `, read_at = COALESCE(read_at, CURRENT_TIMESTAMP)`
+		pending = `(delivered_at IS NULL OR read_at IS NULL)`
+	}
+
+	query := `UPDATE friend_messages SET ` + set + `
+		WHERE receiver_id = ? AND sender_id = ? AND ` + pending
+	args := []interface{}{receiverID, senderID}
+	if upToID != "" {
+		query += ` AND created_at <= (
+			SELECT created_at FROM friend_messages WHERE id = ? AND receiver_id = ? AND sender_id = ?)`
+		args = append(args, upToID, receiverID, senderID)
+	}
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("mark direct messages: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("mark direct messages: %w", err)
+	}
+	return n, nil
+}
+
+const directMessageColumns = `id, sender_id, receiver_id, content, created_at, delivered_at, read_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanDirectMessage(row rowScanner) (*DirectMessage, error) {
+	var msg DirectMessage
+	var deliveredAt, readAt sql.NullString
+	if err := row.Scan(&msg.ID, &msg.SenderID, &msg.ReceiverID, &msg.Content, &msg.CreatedAt, &deliveredAt, &readAt); err != nil {
+		return nil, err
+	}
+	if deliveredAt.Valid {
+		msg.DeliveredAt = &deliveredAt.String
+	}
+	if readAt.Valid {
+		msg.ReadAt = &readAt.String
+	}
+	return &msg, nil
+}
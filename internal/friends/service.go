@@ -6,6 +6,8 @@ import (
 	"strings"
 
 	"github.com/rs/zerolog"
+
+	"github.com/concord-chat/concord/internal/markdown"
 )
 
 const (
@@ -16,6 +18,7 @@ const (
 type Service struct {
 	repo     *Repository
 	presence PresenceChecker
+	receipts ReceiptPolicy // optional; without it read receipts are always on
 	logger   zerolog.Logger
 }
 
@@ -24,6 +27,12 @@ type PresenceChecker interface {
 	IsOnline(userID string) bool
 }
 
+// ReceiptPolicy reports whether a user allows read receipts.
+// Implemented by preferences.Service.
+type ReceiptPolicy interface {
+	ReadReceiptsEnabled(ctx context.Context, userID string) bool
+}
+
 // NewService creates a new friends service.
 func NewService(repo *Repository, presence PresenceChecker, logger zerolog.Logger) *Service {
 	return &Service{
@@ -33,6 +42,11 @@ func NewService(repo *Repository, presence PresenceChecker, logger zerolog.Logge
 	}
 }
 
+// SetReceiptPolicy makes read receipts respect each user's privacy setting.
+func (s *Service) SetReceiptPolicy(p ReceiptPolicy) {
+	s.receipts = p
+}
+
 // SendRequest sends a friend request from senderID to the user with the given username.
 // Validates: not self, not already friends, no duplicate pending request, user exists.
 // Complexity: O(1).
@@ -143,6 +157,15 @@ func (s *Service) UnblockUser(ctx context.Context, userID, targetUsername string
 	return s.repo.UnblockUser(ctx, userID, targetID)
 }
 
+// AreFriends reports whether two users are friends.
+func (s *Service) AreFriends(ctx context.Context, userID, friendID string) (bool, error) {
+	areFriends, err := s.repo.AreFriends(ctx, userID, friendID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check friendship: %w", err)
+	}
+	return areFriends, nil
+}
+
 // SendDirectMessage sends a direct message to a friend.
 func (s *Service) SendDirectMessage(ctx context.Context, senderID, friendID, content string) (*DirectMessage, error) {
 	content = strings.TrimSpace(content)
@@ -172,6 +195,8 @@ func (s *Service) SendDirectMessage(ctx context.Context, senderID, friendID, con
 		Str("message_id", msg.ID).
 		Msg("direct message sent")
 
+	msg.Markup = markdown.Parse(msg.Content)
+	msg.Status = MessageSent
 	return msg, nil
 }
 
@@ -185,5 +210,108 @@ func (s *Service) GetDirectMessages(ctx context.Context, userID, friendID string
 		return nil, fmt.Errorf("you can only access direct messages with friends")
 	}
 
-	return s.repo.GetDirectMessages(ctx, userID, friendID, opts)
+	// Fetching a conversation delivers the friend's messages to this user.
+	if _, err := s.repo.MarkDirectMessages(ctx, userID, friendID, "", false); err != nil {
+		return nil, err
+	}
+
+	msgs, err := s.repo.GetDirectMessages(ctx, userID, friendID, opts)
+	if err != nil {
+		return nil, err
+	}
+	showRead := s.readReceiptsAllowed(ctx, userID, friendID)
+	for i := range msgs {
+		msgs[i].Markup = markdown.Parse(msgs[i].Content)
+		if !showRead {
+			msgs[i].ReadAt = nil
+		}
+		msgs[i].Status = statusOf(&msgs[i])
+	}
+	return msgs, nil
+}
+
+// GetDirectMessage returns a direct message to one of its two participants while they
+// are still friends. Returns nil if the message does not exist or userID cannot read it.
+// Complexity: O(1)
+func (s *Service) GetDirectMessage(ctx context.Context, userID, messageID string) (*DirectMessage, error) {
+	msg, err := s.repo.GetDirectMessage(ctx, messageID)
+	if err != nil || msg == nil {
+		return nil, err
+	}
+	friendID := msg.SenderID
+	if friendID == userID {
+		friendID = msg.ReceiverID
+	} else if msg.ReceiverID != userID {
+		return nil, nil
+	}
+
+	areFriends, err := s.repo.AreFriends(ctx, userID, friendID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check friendship: %w", err)
+	}
+	if !areFriends {
+		return nil, nil
+	}
+	msg.Markup = markdown.Parse(msg.Content)
+	if !s.readReceiptsAllowed(ctx, userID, friendID) {
+		msg.ReadAt = nil
+	}
+	msg.Status = statusOf(msg)
+	return msg, nil
+}
+
+// AckDirectMessages marks the friend's messages up to messageID (all when empty) as
+// delivered or read by userID. Read markers are only recorded while userID allows read
+// receipts, so turning them off never leaks read state later. Returns the number of
+// messages updated.
+// Complexity: O(log n + k)
+func (s *Service) AckDirectMessages(ctx context.Context, userID, friendID, messageID string, status MessageStatus) (int64, error) {
+	if status != MessageDelivered && status != MessageRead {
+		return 0, fmt.Errorf("status must be %q or %q", MessageDelivered, MessageRead)
+	}
+
+	areFriends, err := s.repo.AreFriends(ctx, userID, friendID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to check friendship: %w", err)
+	}
+	if !areFriends {
+		return 0, fmt.Errorf("you can only access direct messages with friends")
+	}
+
+	read := status == MessageRead && s.receiptsEnabled(ctx, userID)
+	n, err := s.repo.MarkDirectMessages(ctx, userID, friendID, messageID, read)
+	if err != nil {
+		return 0, err
+	}
+
+	s.logger.Debug().
+		Str("user_id", userID).
+		Str("friend_id", friendID).
+		Str("status", string(status)).
+		Int64("updated", n).
+		Msg("direct messages acknowledged")
+
+	return n, nil
+}
+
+// readReceiptsAllowed reports whether read state is visible between two users.
+// Receipts are symmetric: if either side turned them off, neither sees them.
+func (s *Service) readReceiptsAllowed(ctx context.Context, userA, userB string) bool {
+	return s.receiptsEnabled(ctx, userA) && s.receiptsEnabled(ctx, userB)
+}
+
+func (s *Service) receiptsEnabled(ctx context.Context, userID string) bool {
+	return s.receipts == nil || s.receipts.ReadReceiptsEnabled(ctx, userID)
+}
+
+// statusOf derives the sender-facing status from the stored markers.
+func statusOf(m *DirectMessage) MessageStatus {
+	switch {
+	case m.ReadAt != nil:
+		return MessageRead
+	case m.DeliveredAt != nil:
+		return MessageDelivered
+	default:
+		return MessageSent
+	}
 }
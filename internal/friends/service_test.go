@@ -0,0 +1,174 @@
+package friends
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/concord-chat/concord/internal/store/sqlite"
+)
+
+type receiptPrefs map[string]bool
+
+func (p receiptPrefs) ReadReceiptsEnabled(_ context.Context, userID string) bool {
+	enabled, ok := p[userID]
+	return !ok || enabled
+}
+
+func setupService(t *testing.T) (*Service, *sqlite.DB) {
+	t.Helper()
+	logger := zerolog.Nop()
+	db, err := sqlite.New(sqlite.Config{
+		Path:         filepath.Join(t.TempDir(), "test.db"),
+		MaxOpenConns: 1,
+		ForeignKeys:  true,
+		BusyTimeout:  5 * time.Second,
+	}, logger)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	ctx := context.Background()
+	require.NoError(t, sqlite.NewMigrator(db, logger).Migrate(ctx))
+
+	for _, stmt := range []string{
+		`INSERT INTO users (id, username) VALUES ('alice', 'alice'), ('bob', 'bob')`,
+		`INSERT INTO friends (user_id, friend_id) VALUES ('alice', 'bob'), ('bob', 'alice')`,
+	} {
+		_, err := db.ExecContext(ctx, stmt)
+		require.NoError(t, err)
+	}
+
+	repo := NewRepository(db, NewStdlibTransactor(db.Conn()), logger)
+	return NewService(repo, nil, logger), db
+}
+
+func TestService_DirectMessageReceipts(t *testing.T) {
+	svc, _ := setupService(t)
+	ctx := context.Background()
+
+	sent, err := svc.SendDirectMessage(ctx, "alice", "bob", "hi bob")
+	require.NoError(t, err)
+	assert.Equal(t, MessageSent, sent.Status)
+
+	msgs, err := svc.GetDirectMessages(ctx, "alice", "bob", DMPaginationOpts{})
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	assert.Equal(t, MessageSent, msgs[0].Status, "the sender fetching does not deliver")
+
+	// Bob fetching the conversation delivers the message.
+	_, err = svc.GetDirectMessages(ctx, "bob", "alice", DMPaginationOpts{})
+	require.NoError(t, err)
+	msgs, err = svc.GetDirectMessages(ctx, "alice", "bob", DMPaginationOpts{})
+	require.NoError(t, err)
+	assert.Equal(t, MessageDelivered, msgs[0].Status)
+	assert.NotNil(t, msgs[0].DeliveredAt)
+	assert.Nil(t, msgs[0].ReadAt)
+
+	n, err := svc.AckDirectMessages(ctx, "bob", "alice", sent.ID, MessageRead)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	n, err = svc.AckDirectMessages(ctx, "bob", "alice", sent.ID, MessageRead)
+	require.NoError(t, err)
+	assert.Zero(t, n, "repeated acks are no-ops")
+
+	msgs, err = svc.GetDirectMessages(ctx, "alice", "bob", DMPaginationOpts{})
+	require.NoError(t, err)
+	assert.Equal(t, MessageRead, msgs[0].Status)
+	assert.NotNil(t, msgs[0].ReadAt)
+}
+
+func TestService_AckOnlyCoversFriendMessagesUpToID(t *testing.T) {
+	svc, db := setupService(t)
+	ctx := context.Background()
+
+	first, err := svc.SendDirectMessage(ctx, "alice", "bob", "one")
+	require.NoError(t, err)
+	_, err = svc.SendDirectMessage(ctx, "alice", "bob", "two")
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, `UPDATE friend_messages SET created_at = '2026-01-01 00:00:00' WHERE id = ?`, first.ID)
+	require.NoError(t, err)
+
+	// Alice cannot acknowledge her own messages.
+	n, err := svc.AckDirectMessages(ctx, "alice", "bob", first.ID, MessageRead)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	n, err = svc.AckDirectMessages(ctx, "bob", "alice", first.ID, MessageDelivered)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+}
+
+func TestService_ReadReceiptsAreSymmetric(t *testing.T) {
+	svc, _ := setupService(t)
+	ctx := context.Background()
+
+	toBob, err := svc.SendDirectMessage(ctx, "alice", "bob", "hi bob")
+	require.NoError(t, err)
+	toAlice, err := svc.SendDirectMessage(ctx, "bob", "alice", "hi alice")
+	require.NoError(t, err)
+
+	_, err = svc.AckDirectMessages(ctx, "bob", "alice", toBob.ID, MessageRead)
+	require.NoError(t, err)
+
+	// Alice turns read receipts off: she no longer sees Bob's reads...
+	svc.SetReceiptPolicy(receiptPrefs{"alice": false})
+	msgs, err := svc.GetDirectMessages(ctx, "alice", "bob", DMPaginationOpts{})
+	require.NoError(t, err)
+	for _, m := range msgs {
+		assert.Nil(t, m.ReadAt)
+		assert.NotEqual(t, MessageRead, m.Status)
+	}
+
+	// ...and her own reads are not recorded.
+	_, err = svc.AckDirectMessages(ctx, "alice", "bob", toAlice.ID, MessageRead)
+	require.NoError(t, err)
+	svc.SetReceiptPolicy(nil)
+	msgs, err = svc.GetDirectMessages(ctx, "bob", "alice", DMPaginationOpts{})
+	require.NoError(t, err)
+	for _, m := range msgs {
+		if m.ID == toAlice.ID {
+			assert.Equal(t, MessageDelivered, m.Status)
+		}
+	}
+}
+
+func TestService_AckValidation(t *testing.T) {
+	svc, _ := setupService(t)
+	ctx := context.Background()
+
+	_, err := svc.AckDirectMessages(ctx, "bob", "alice", "", MessageSent)
+	assert.Error(t, err)
+	_, err = svc.AckDirectMessages(ctx, "bob", "stranger", "", MessageRead)
+	assert.Error(t, err)
+}
+
+func TestService_GetDirectMessage(t *testing.T) {
+	svc, db := setupService(t)
+	ctx := context.Background()
+
+	sent, err := svc.SendDirectMessage(ctx, "alice", "bob", "hi bob")
+	require.NoError(t, err)
+
+	for _, userID := range []string{"alice", "bob"} {
+		msg, err := svc.GetDirectMessage(ctx, userID, sent.ID)
+		require.NoError(t, err)
+		require.NotNil(t, msg, userID)
+		assert.Equal(t, "hi bob", msg.Content)
+	}
+
+	_, err = db.ExecContext(ctx, `INSERT INTO users (id, username) VALUES ('carol', 'carol')`)
+	require.NoError(t, err)
+	msg, err := svc.GetDirectMessage(ctx, "carol", sent.ID)
+	require.NoError(t, err)
+	assert.Nil(t, msg, "not a participant")
+
+	require.NoError(t, svc.RemoveFriend(ctx, "bob", "alice"))
+	msg, err = svc.GetDirectMessage(ctx, "bob", sent.ID)
+	require.NoError(t, err)
+	assert.Nil(t, msg, "no longer friends")
+}
@@ -0,0 +1,136 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// The types below mirror the JSON written by DiscordChatExporter (and tools that copy
+// its format): one file per channel, with the guild and channel first and then the
+// messages oldest first. Only the fields Concord can store are decoded.
+
+// discordGuild is the "guild" object of an export.
+type discordGuild struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	IconURL string `json:"iconUrl"`
+}
+
+// discordChannel is the "channel" object of an export.
+type discordChannel struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Category string `json:"category"`
+	Name     string `json:"name"`
+	Topic    string `json:"topic"`
+}
+
+// discordAuthor is the author of a message.
+type discordAuthor struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	Discriminator string `json:"discriminator"`
+	Nickname      string `json:"nickname"`
+	IsBot         bool   `json:"isBot"`
+	AvatarURL     string `json:"avatarUrl"`
+}
+
+// discordAttachment is a file attached to a message. URL is either the Discord CDN
+// address or, when the export was made with media download, a path relative to the
+// export file.
+type discordAttachment struct {
+	ID            string `json:"id"`
+	URL           string `json:"url"`
+	FileName      string `json:"fileName"`
+	FileSizeBytes int64  `json:"fileSizeBytes"`
+}
+
+// discordMessage is one entry of the "messages" array.
+type discordMessage struct {
+	ID              string              `json:"id"`
+	Type            string              `json:"type"`
+	Timestamp       time.Time           `json:"timestamp"`
+	TimestampEdited *time.Time          `json:"timestampEdited"`
+	Content         string              `json:"content"`
+	Author          discordAuthor       `json:"author"`
+	Attachments     []discordAttachment `json:"attachments"`
+}
+
+// discordReader streams an export: the header objects are decoded as they are met and
+// messages are returned one at a time, so large channels never sit in memory at once.
+type discordReader struct {
+	dec     *json.Decoder
+	guild   *discordGuild
+	channel *discordChannel
+	inArray bool
+}
+
+// newDiscordReader reads up to the start of the messages array.
+// Complexity: O(h) where h = size of the header objects
+func newDiscordReader(r io.Reader) (*discordReader, error) {
+	d := &discordReader{dec: json.NewDecoder(r)}
+	if err := d.expectDelim('{'); err != nil {
+		return nil, err
+	}
+
+	for d.dec.More() {
+		tok, err := d.dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("read export: %w", err)
+		}
+		switch key, _ := tok.(string); key {
+		case "guild":
+			d.guild = &discordGuild{}
+			if err := d.dec.Decode(d.guild); err != nil {
+				return nil, fmt.Errorf("read guild: %w", err)
+			}
+		case "channel":
+			d.channel = &discordChannel{}
+			if err := d.dec.Decode(d.channel); err != nil {
+				return nil, fmt.Errorf("read channel: %w", err)
+			}
+		case "messages":
+			if d.guild == nil || d.channel == nil {
+				return nil, fmt.Errorf("read export: guild and channel must precede messages")
+			}
+			if err := d.expectDelim('['); err != nil {
+				return nil, err
+			}
+			d.inArray = true
+			return d, nil
+		default:
+			var skip json.RawMessage
+			if err := d.dec.Decode(&skip); err != nil {
+				return nil, fmt.Errorf("read export: %w", err)
+			}
+		}
+	}
+	return nil, fmt.Errorf("read export: no messages array (is this a DiscordChatExporter JSON file?)")
+}
+
+// next returns the next message, or io.EOF after the last one.
+// Complexity: O(1) per message
+func (d *discordReader) next() (*discordMessage, error) {
+	if !d.inArray || !d.dec.More() {
+		d.inArray = false
+		return nil, io.EOF
+	}
+	var m discordMessage
+	if err := d.dec.Decode(&m); err != nil {
+		return nil, fmt.Errorf("read message: %w", err)
+	}
+	return &m, nil
+}
+
+func (d *discordReader) expectDelim(want json.Delim) error {
+	tok, err := d.dec.Token()
+	if err != nil {
+		return fmt.Errorf("read export: %w", err)
+	}
+	if got, ok := tok.(json.Delim); !ok || got != want {
+		return fmt.Errorf("read export: expected %q, got %v", want, tok)
+	}
+	return nil
+}
@@ -0,0 +1,411 @@
+// Package importer brings message history from other chat platforms into Concord.
+// Imports are idempotent: every record gets an ID derived from its original ID, so
+// running the same export twice only adds what is missing.
+package importer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+
+	"github.com/concord-chat/concord/internal/auth"
+	"github.com/concord-chat/concord/internal/chat"
+	"github.com/concord-chat/concord/internal/files"
+	"github.com/concord-chat/concord/internal/server"
+)
+
+// maxNameLength matches the server and channel name limits of server.Service.
+const maxNameLength = 100
+
+// discordNamespace scopes the derived IDs so they cannot collide with random UUIDs
+// or with IDs derived for another platform.
+var discordNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://discord.com"))
+
+// systemText describes Discord system messages, which carry no content of their own.
+var systemText = map[string]string{
+	"RecipientAdd":         "added someone to the conversation.",
+	"RecipientRemove":      "removed someone from the conversation.",
+	"Call":                 "started a call.",
+	"ChannelNameChange":    "changed the channel name.",
+	"ChannelIconChange":    "changed the channel icon.",
+	"ChannelPinnedMessage": "pinned a message.",
+	"GuildMemberJoin":      "joined the server.",
+	"ThreadCreated":        "started a thread.",
+}
+
+// Options control an import.
+type Options struct {
+	// OwnerID is the Concord user who owns the server when the import creates it.
+	OwnerID string
+}
+
+// Result summarises one imported file.
+type Result struct {
+	ServerID           string `json:"server_id"`
+	ChannelID          string `json:"channel_id"`
+	Imported           int    `json:"imported"`
+	AlreadyImported    int    `json:"already_imported"`
+	Unsupported        int    `json:"unsupported"` // e.g. sticker- or embed-only messages
+	Users              int    `json:"users"`
+	Attachments        int    `json:"attachments"`
+	MissingAttachments int    `json:"missing_attachments"`
+}
+
+// Importer writes exported history through the regular repositories.
+type Importer struct {
+	servers *server.Repository
+	chat    *chat.Repository
+	users   *auth.Repository
+	storage files.Storage
+	logger  zerolog.Logger
+}
+
+// NewImporter creates an importer. Attachment files are copied into storage.
+func NewImporter(servers *server.Repository, chatRepo *chat.Repository, users *auth.Repository, storage files.Storage, logger zerolog.Logger) *Importer {
+	return &Importer{
+		servers: servers,
+		chat:    chatRepo,
+		users:   users,
+		storage: storage,
+		logger:  logger.With().Str("component", "importer").Logger(),
+	}
+}
+
+// ImportDiscordFile imports one channel exported by DiscordChatExporter in JSON format.
+// Downloaded media is looked up relative to the file.
+// Complexity: O(n) where n = messages in the file
+func (im *Importer) ImportDiscordFile(ctx context.Context, path string, opts Options) (*Result, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open export: %w", err)
+	}
+	defer f.Close()
+
+	return im.ImportDiscord(ctx, f, filepath.Dir(path), opts)
+}
+
+// ImportDiscord imports one channel export read from r. The server and channel are
+// created on first import; message authors become placeholder users. Local
+// attachments are resolved inside baseDir; CDN links are kept in the message text.
+// Complexity: O(n) where n = messages in the export
+func (im *Importer) ImportDiscord(ctx context.Context, r io.Reader, baseDir string, opts Options) (*Result, error) {
+	if opts.OwnerID == "" {
+		return nil, fmt.Errorf("owner ID is required")
+	}
+
+	export, err := newDiscordReader(r)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(export.channel.Type, "Direct") {
+		return nil, fmt.Errorf("direct message exports are not supported, only server channels")
+	}
+
+	res := &Result{}
+	if res.ServerID, err = im.ensureServer(ctx, export.guild, opts.OwnerID); err != nil {
+		return nil, err
+	}
+	if res.ChannelID, err = im.ensureChannel(ctx, res.ServerID, export.channel); err != nil {
+		return nil, err
+	}
+
+	authors := make(map[string]string) // Discord user ID -> Concord user ID
+	for {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+		m, err := export.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return res, err
+		}
+		if err := im.importMessage(ctx, res, authors, baseDir, m); err != nil {
+			return res, fmt.Errorf("message %s: %w", m.ID, err)
+		}
+		if done := res.Imported + res.AlreadyImported; done%1000 == 0 && done > 0 {
+			im.logger.Info().Str("channel_id", res.ChannelID).Int("messages", done).Msg("import progress")
+		}
+	}
+
+	im.logger.Info().
+		Str("server_id", res.ServerID).
+		Str("channel_id", res.ChannelID).
+		Int("imported", res.Imported).
+		Int("already_imported", res.AlreadyImported).
+		Int("attachments", res.Attachments).
+		Msg("discord channel imported")
+
+	return res, nil
+}
+
+func (im *Importer) ensureServer(ctx context.Context, g *discordGuild, ownerID string) (string, error) {
+	id := derivedID("guild", g.ID)
+	existing, err := im.servers.GetServer(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if existing != nil {
+		return id, nil
+	}
+
+	code, err := server.GenerateInviteCode()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate invite code: %w", err)
+	}
+	srv := &server.Server{ID: id, Name: truncate(g.Name, maxNameLength), OwnerID: ownerID, InviteCode: code}
+	if srv.Name == "" {
+		srv.Name = "Imported server"
+	}
+	// One transaction, so a failed first run leaves no server without its owner or
+	// roles for reruns to find and skip.
+	if err := im.servers.CreateServer(ctx, srv, server.PresetRoles(id), nil, nil, nil); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+func (im *Importer) ensureChannel(ctx context.Context, serverID string, c *discordChannel) (string, error) {
+	id := derivedID("channel", c.ID)
+	existing, err := im.servers.GetChannel(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if existing != nil {
+		return id, nil
+	}
+
+	// Voice-channel chats and threads are imported as text channels, after the others.
+	ch := &server.Channel{
+		ID:       id,
+		ServerID: serverID,
+		Name:     truncate(c.Name, maxNameLength),
+		Type:     "text",
+	}
+	if ch.Name == "" {
+		ch.Name = "imported"
+	}
+	if err := im.servers.CreateChannel(ctx, ch); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// ensureUser creates (or refreshes) the placeholder user of a Discord author once per import.
+func (im *Importer) ensureUser(ctx context.Context, res *Result, authors map[string]string, a discordAuthor) (string, error) {
+	if id, ok := authors[a.ID]; ok {
+		return id, nil
+	}
+
+	name := a.Name
+	if name == "" {
+		name = "discord-" + a.ID
+	}
+	display := a.Nickname
+	if display == "" {
+		display = name
+	}
+	user := &auth.User{
+		ID:          derivedID("user", a.ID),
+		GitHubID:    placeholderGitHubID(a.ID),
+		Username:    name,
+		DisplayName: display,
+	}
+	if err := im.users.UpsertUser(ctx, user); err != nil {
+		return "", err
+	}
+	authors[a.ID] = user.ID
+	res.Users++
+	return user.ID, nil
+}
+
+func (im *Importer) importMessage(ctx context.Context, res *Result, authors map[string]string, baseDir string, m *discordMessage) error {
+	content := strings.TrimSpace(m.Content)
+	msgType := "text"
+	if text, ok := systemText[m.Type]; ok && content == "" {
+		content = text
+		msgType = "system"
+	}
+
+	var local []discordAttachment
+	for _, a := range m.Attachments {
+		if isRemote(a.URL) {
+			content = strings.TrimSpace(content + "\n" + a.URL)
+		} else {
+			local = append(local, a)
+		}
+	}
+	if content == "" {
+		if len(local) == 0 {
+			res.Unsupported++
+			return nil
+		}
+		msgType = "file"
+	}
+
+	authorID, err := im.ensureUser(ctx, res, authors, m.Author)
+	if err != nil {
+		return err
+	}
+
+	msg := &chat.Message{
+		ID:        derivedID("message", m.ID),
+		ChannelID: res.ChannelID,
+		AuthorID:  authorID,
+		Content:   content,
+		Type:      msgType,
+	}
+	inserted, err := im.chat.Import(ctx, msg, m.Timestamp, m.TimestampEdited)
+	if err != nil {
+		return err
+	}
+	if inserted {
+		res.Imported++
+	} else {
+		res.AlreadyImported++
+	}
+	if len(local) == 0 {
+		return nil
+	}
+
+	// A previous run may have stopped between the message and its files.
+	stored := make(map[string]bool)
+	if !inserted {
+		existing, err := im.chat.GetAttachments(ctx, []string{msg.ID})
+		if err != nil {
+			return err
+		}
+		for _, a := range existing[msg.ID] {
+			stored[a.ID] = true
+		}
+	}
+
+	for _, a := range local {
+		id := derivedID("attachment", a.ID)
+		if stored[id] {
+			continue
+		}
+		if err := im.storeAttachment(ctx, msg.ID, id, baseDir, a); err != nil {
+			im.logger.Warn().Err(err).Str("attachment", a.URL).Msg("attachment not imported")
+			res.MissingAttachments++
+			continue
+		}
+		res.Attachments++
+	}
+	return nil
+}
+
+// storeAttachment copies a downloaded media file into storage and records it.
+func (im *Importer) storeAttachment(ctx context.Context, messageID, id, baseDir string, a discordAttachment) error {
+	path, err := resolveLocal(baseDir, a.URL)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	name := a.FileName
+	if name == "" {
+		name = filepath.Base(path)
+	}
+	h := sha256.New()
+	stored, err := im.storage.Save(name, io.TeeReader(f, h))
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		im.storage.Delete(stored)
+		return err
+	}
+
+	att := &chat.Attachment{
+		ID:        id,
+		Filename:  name,
+		SizeBytes: info.Size(),
+		MimeType:  mimeType(name),
+		Hash:      hex.EncodeToString(h.Sum(nil)),
+	}
+	if _, err := im.chat.SaveAttachment(ctx, messageID, att, stored); err != nil {
+		im.storage.Delete(stored)
+		return err
+	}
+	return nil
+}
+
+// resolveLocal maps an attachment URL written by a media export to a file inside
+// baseDir. Paths escaping baseDir are refused so an export cannot pull in other files.
+func resolveLocal(baseDir, ref string) (string, error) {
+	if filepath.IsAbs(ref) {
+		return "", fmt.Errorf("absolute attachment path %q refused", ref)
+	}
+	candidates := []string{ref}
+	if unescaped, err := url.PathUnescape(ref); err == nil && unescaped != ref {
+		candidates = append(candidates, unescaped)
+	}
+
+	base := filepath.Clean(baseDir)
+	for _, c := range candidates {
+		path := filepath.Join(base, filepath.FromSlash(c))
+		if rel, err := filepath.Rel(base, path); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return "", fmt.Errorf("attachment path %q escapes the export directory", ref)
+		}
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("attachment file %q not found", ref)
+}
+
+func isRemote(ref string) bool {
+	return strings.HasPrefix(ref, "https://") || strings.HasPrefix(ref, "http://")
+}
+
+func mimeType(name string) string {
+	if t := mime.TypeByExtension(strings.ToLower(filepath.Ext(name))); t != "" {
+		if mt, _, err := mime.ParseMediaType(t); err == nil {
+			return mt
+		}
+	}
+	return "application/octet-stream"
+}
+
+// derivedID returns a stable UUID for a Discord object, which is what makes reruns idempotent.
+func derivedID(kind, discordID string) string {
+	return uuid.NewSHA1(discordNamespace, []byte(kind+":"+discordID)).String()
+}
+
+// placeholderGitHubID fills the required, unique github_id of a placeholder user.
+// Real GitHub IDs are positive, so the negated Discord snowflake cannot collide.
+func placeholderGitHubID(discordID string) int64 {
+	if n, err := strconv.ParseInt(discordID, 10, 64); err == nil && n > 0 {
+		return -n
+	}
+	h := fnv.New64a()
+	h.Write([]byte(discordID))
+	return -int64(h.Sum64() >> 1)
+}
+
+func truncate(s string, n int) string {
+	s = strings.TrimSpace(s)
+	if r := []rune(s); len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
@@ -0,0 +1,188 @@
+package importer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/concord-chat/concord/internal/auth"
+	"github.com/concord-chat/concord/internal/chat"
+	"github.com/concord-chat/concord/internal/files"
+	"github.com/concord-chat/concord/internal/server"
+	"github.com/concord-chat/concord/internal/store/sqlite"
+)
+
+const sampleExport = `{
+  "guild": {"id": "111", "name": "Old Guild", "iconUrl": "https://cdn.discordapp.com/icons/111/a.png"},
+  "channel": {"id": "222", "type": "GuildTextChat", "categoryId": "3", "category": "Text", "name": "general", "topic": null},
+  "dateRange": {"after": null, "before": null},
+  "messages": [
+    {"id": "1001", "type": "Default", "timestamp": "2021-03-04T05:06:07.123+00:00", "timestampEdited": null,
+     "content": "hello from discord", "author": {"id": "900000000000000001", "name": "alice", "discriminator": "0000", "nickname": "Alice", "isBot": false},
+     "attachments": [], "embeds": [], "reactions": []},
+    {"id": "1002", "type": "GuildMemberJoin", "timestamp": "2021-03-04T05:07:00+00:00", "timestampEdited": null,
+     "content": "", "author": {"id": "900000000000000002", "name": "bob", "discriminator": "1234", "nickname": "bob", "isBot": false},
+     "attachments": []},
+    {"id": "1003", "type": "Default", "timestamp": "2021-03-04T05:08:00+00:00", "timestampEdited": "2021-03-04T06:00:00+00:00",
+     "content": "", "author": {"id": "900000000000000001", "name": "alice", "discriminator": "0000", "nickname": "Alice", "isBot": false},
+     "attachments": [
+       {"id": "5001", "url": "export_Files/photo-ABCD.png", "fileName": "photo.png", "fileSizeBytes": 4},
+       {"id": "5002", "url": "https://cdn.discordapp.com/attachments/222/5002/notes.txt", "fileName": "notes.txt", "fileSizeBytes": 10},
+       {"id": "5003", "url": "../../etc/passwd", "fileName": "passwd", "fileSizeBytes": 10}
+     ]},
+    {"id": "1004", "type": "Default", "timestamp": "2021-03-04T05:09:00+00:00", "timestampEdited": null,
+     "content": "", "author": {"id": "900000000000000002", "name": "bob", "discriminator": "1234", "nickname": "bob", "isBot": false},
+     "attachments": [], "stickers": [{"id": "1", "name": "wave"}]}
+  ],
+  "messageCount": 4
+}`
+
+func setupImporter(t *testing.T) (*Importer, *sqlite.DB, string) {
+	t.Helper()
+	logger := zerolog.Nop()
+	db, err := sqlite.New(sqlite.Config{
+		Path:         filepath.Join(t.TempDir(), "test.db"),
+		MaxOpenConns: 1,
+		ForeignKeys:  true,
+		BusyTimeout:  5 * time.Second,
+	}, logger)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	ctx := context.Background()
+	require.NoError(t, sqlite.NewMigrator(db, logger).Migrate(ctx))
+
+	_, err = db.ExecContext(ctx, `INSERT INTO users (id, username) VALUES ('owner', 'owner')`)
+	require.NoError(t, err)
+
+	storage, err := files.NewLocalStorage(t.TempDir(), logger)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "export_Files"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "export_Files", "photo-ABCD.png"), []byte("\x89PNG"), 0o644))
+	path := filepath.Join(dir, "export.json")
+	require.NoError(t, os.WriteFile(path, []byte(sampleExport), 0o644))
+
+	im := NewImporter(server.NewRepository(db, server.NewStdlibTransactor(db.Conn()), logger), chat.NewRepository(db, logger), auth.NewRepository(db, logger), storage, logger)
+	return im, db, path
+}
+
+func TestImportDiscordFile(t *testing.T) {
+	im, db, path := setupImporter(t)
+	ctx := context.Background()
+
+	res, err := im.ImportDiscordFile(ctx, path, Options{OwnerID: "owner"})
+	require.NoError(t, err)
+	assert.Equal(t, 3, res.Imported)
+	assert.Equal(t, 1, res.Unsupported, "sticker-only messages have nothing to store")
+	assert.Equal(t, 2, res.Users)
+	assert.Equal(t, 1, res.Attachments)
+	assert.Equal(t, 1, res.MissingAttachments, "paths outside the export directory are refused")
+
+	srv, err := im.servers.GetServer(ctx, res.ServerID)
+	require.NoError(t, err)
+	require.NotNil(t, srv)
+	assert.Equal(t, "Old Guild", srv.Name)
+	member, err := im.servers.GetMember(ctx, res.ServerID, "owner")
+	require.NoError(t, err)
+	require.NotNil(t, member)
+	assert.Equal(t, server.RoleOwner, member.Role)
+
+	msgs, err := im.chat.ListAfter(ctx, res.ChannelID, "", 10)
+	require.NoError(t, err)
+	require.Len(t, msgs, 3)
+	assert.Equal(t, "hello from discord", msgs[0].Content)
+	assert.Equal(t, "alice", msgs[0].AuthorName)
+	assert.True(t, strings.HasPrefix(msgs[0].CreatedAt, "2021-03-04T05:06:07"), msgs[0].CreatedAt)
+	assert.Equal(t, "system", msgs[1].Type)
+	assert.Equal(t, "joined the server.", msgs[1].Content)
+	assert.Equal(t, "https://cdn.discordapp.com/attachments/222/5002/notes.txt", msgs[2].Content, "CDN attachments stay as links")
+	require.NotNil(t, msgs[2].EditedAt)
+
+	atts, err := im.chat.GetAttachments(ctx, []string{msgs[2].ID})
+	require.NoError(t, err)
+	require.Len(t, atts[msgs[2].ID], 1)
+	assert.Equal(t, "photo.png", atts[msgs[2].ID][0].Filename)
+	assert.Equal(t, "image/png", atts[msgs[2].ID][0].MimeType)
+	assert.Equal(t, int64(4), atts[msgs[2].ID][0].SizeBytes)
+
+	var githubID int64
+	require.NoError(t, db.QueryRowContext(ctx, `SELECT github_id FROM users WHERE username = 'alice'`).Scan(&githubID))
+	assert.Equal(t, int64(-900000000000000001), githubID)
+}
+
+func TestImportDiscordFile_RerunAddsNothing(t *testing.T) {
+	im, db, path := setupImporter(t)
+	ctx := context.Background()
+
+	first, err := im.ImportDiscordFile(ctx, path, Options{OwnerID: "owner"})
+	require.NoError(t, err)
+	second, err := im.ImportDiscordFile(ctx, path, Options{OwnerID: "owner"})
+	require.NoError(t, err)
+
+	assert.Equal(t, first.ServerID, second.ServerID)
+	assert.Equal(t, first.ChannelID, second.ChannelID)
+	assert.Zero(t, second.Imported)
+	assert.Equal(t, 3, second.AlreadyImported)
+	assert.Zero(t, second.Attachments)
+
+	for table, want := range map[string]int{"servers": 1, "channels": 1, "messages": 3, "attachments": 1, "server_members": 1} {
+		var n int
+		require.NoError(t, db.QueryRowContext(ctx, `SELECT COUNT(*) FROM `+table).Scan(&n))
+		assert.Equal(t, want, n, table)
+	}
+}
+
+func TestImportDiscordFile_FailedServerCreationIsRetried(t *testing.T) {
+	im, db, path := setupImporter(t)
+	ctx := context.Background()
+
+	// A clashing role ID makes the server creation fail after the server row is written.
+	serverID := derivedID("guild", "111")
+	for _, stmt := range []string{
+		`INSERT INTO servers (id, name, owner_id) VALUES ('srv-x', 'Other', 'owner')`,
+		`INSERT INTO server_roles (id, server_id, name) VALUES ('` + server.DefaultRoleID(serverID) + `', 'srv-x', 'clash')`,
+	} {
+		_, err := db.ExecContext(ctx, stmt)
+		require.NoError(t, err)
+	}
+	_, err := im.ImportDiscordFile(ctx, path, Options{OwnerID: "owner"})
+	require.Error(t, err)
+	srv, err := im.servers.GetServer(ctx, serverID)
+	require.NoError(t, err)
+	assert.Nil(t, srv, "a failed creation leaves no server behind")
+
+	_, err = db.ExecContext(ctx, `DELETE FROM server_roles WHERE server_id = 'srv-x'`)
+	require.NoError(t, err)
+	res, err := im.ImportDiscordFile(ctx, path, Options{OwnerID: "owner"})
+	require.NoError(t, err)
+	assert.Equal(t, 3, res.Imported)
+	member, err := im.servers.GetMember(ctx, serverID, "owner")
+	require.NoError(t, err)
+	require.NotNil(t, member)
+	roles, err := im.servers.ListRoles(ctx, serverID)
+	require.NoError(t, err)
+	assert.Len(t, roles, len(server.PresetRoles(serverID)))
+}
+
+func TestImportDiscord_Rejects(t *testing.T) {
+	im, _, _ := setupImporter(t)
+	ctx := context.Background()
+
+	_, err := im.ImportDiscord(ctx, strings.NewReader(sampleExport), t.TempDir(), Options{})
+	assert.Error(t, err, "an owner is required")
+
+	dm := `{"guild": {"id": "0", "name": "Direct Messages"}, "channel": {"id": "9", "type": "DirectTextChat", "name": "bob"}, "messages": []}`
+	_, err = im.ImportDiscord(ctx, strings.NewReader(dm), t.TempDir(), Options{OwnerID: "owner"})
+	assert.Error(t, err)
+
+	_, err = im.ImportDiscord(ctx, strings.NewReader(`{"messages": []}`), t.TempDir(), Options{OwnerID: "owner"})
+	assert.Error(t, err, "guild and channel must come first")
+}
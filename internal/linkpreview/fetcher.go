@@ -0,0 +1,243 @@
+// Package linkpreview fetches OpenGraph/oEmbed metadata for URLs posted in
+// messages. All outbound requests go through the SSRF-hardened client of
+// security.Outbound: every dialed address is re-checked after DNS resolution
+// (defeating DNS rebinding), redirects are re-validated, and response size and
+// time are bounded.
+package linkpreview
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/concord-chat/concord/internal/cache"
+	"github.com/concord-chat/concord/internal/security"
+)
+
+const (
+	fetchTimeout     = 5 * time.Second
+	maxBodyBytes     = 512 * 1024 // previews only need <head>
+	maxRedirects     = 3
+	maxFieldLength   = 512
+	cacheTTL         = 1 * time.Hour
+	negativeCacheTTL = 10 * time.Minute
+	userAgent        = "ConcordBot/1.0 (+link preview)"
+)
+
+// Preview is the metadata extracted for a single URL.
+type Preview struct {
+	URL         string `json:"url"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	ImageURL    string `json:"image_url,omitempty"`
+	SiteName    string `json:"site_name,omitempty"`
+	Provider    string `json:"provider"` // "opengraph" or "oembed"
+}
+
+// Fetcher resolves previews over a hardened HTTP client with an LRU cache.
+type Fetcher struct {
+	client   *http.Client
+	outbound *security.Outbound
+	cache    *cache.LRU
+	logger   zerolog.Logger
+}
+
+// NewFetcher creates a preview fetcher caching up to cacheSize URLs.
+// Complexity: O(1)
+func NewFetcher(cacheSize int, logger zerolog.Logger) *Fetcher {
+	outbound := security.NewOutbound()
+	outbound.StandardPorts = true
+	f := &Fetcher{
+		client:   outbound.Client(fetchTimeout, maxRedirects),
+		outbound: outbound,
+		cache:    cache.NewLRU(cacheSize),
+		logger:   logger.With().Str("component", "link_preview").Logger(),
+	}
+	return f
+}
+
+// Fetch returns the preview for rawURL, using the cache when possible.
+// Failed lookups are cached briefly so a broken link is not refetched on every message.
+// Complexity: O(1) on cache hit; otherwise one bounded HTTP request (two for oEmbed)
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (*Preview, error) {
+	cacheKey := "preview:" + rawURL
+	if val, ok := f.cache.Get(cacheKey); ok {
+		if p, _ := val.(*Preview); p != nil {
+			return p, nil
+		}
+		return nil, fmt.Errorf("linkpreview: no preview available")
+	}
+
+	p, err := f.fetch(ctx, rawURL)
+	if err != nil {
+		f.cache.Set(cacheKey, (*Preview)(nil), negativeCacheTTL)
+		f.logger.Debug().Err(err).Str("url", rawURL).Msg("link preview failed")
+		return nil, err
+	}
+	f.cache.Set(cacheKey, p, cacheTTL)
+	return p, nil
+}
+
+func (f *Fetcher) fetch(ctx context.Context, rawURL string) (*Preview, error) {
+	if err := f.checkURL(rawURL); err != nil {
+		return nil, err
+	}
+
+	body, finalURL, err := f.get(ctx, rawURL, "text/html")
+	if err != nil {
+		return nil, err
+	}
+
+	meta := parseHead(body)
+	p := &Preview{
+		URL:         rawURL,
+		Title:       clip(firstNonEmpty(meta.og["og:title"], meta.og["twitter:title"], meta.title)),
+		Description: clip(firstNonEmpty(meta.og["og:description"], meta.og["twitter:description"], meta.description)),
+		SiteName:    clip(meta.og["og:site_name"]),
+		Provider:    "opengraph",
+	}
+	p.ImageURL = f.resolveImage(finalURL, firstNonEmpty(meta.og["og:image"], meta.og["twitter:image"]))
+
+	// Fall back to oEmbed when the page exposes no usable OpenGraph data.
+	if (p.Title == "" || p.ImageURL == "") && meta.oembed != "" {
+		if oe, err := f.fetchOEmbed(ctx, finalURL, meta.oembed); err == nil {
+			if p.Title == "" {
+				p.Title = clip(oe.Title)
+			}
+			if p.SiteName == "" {
+				p.SiteName = clip(oe.ProviderName)
+			}
+			if p.ImageURL == "" {
+				p.ImageURL = f.resolveImage(finalURL, oe.ThumbnailURL)
+			}
+			p.Provider = "oembed"
+		}
+	}
+
+	if p.Title == "" && p.Description == "" && p.ImageURL == "" {
+		return nil, fmt.Errorf("linkpreview: no metadata found")
+	}
+	return p, nil
+}
+
+// oembedResponse holds the subset of the oEmbed spec used for previews.
+type oembedResponse struct {
+	Title        string `json:"title"`
+	ProviderName string `json:"provider_name"`
+	ThumbnailURL string `json:"thumbnail_url"`
+}
+
+func (f *Fetcher) fetchOEmbed(ctx context.Context, pageURL *url.URL, href string) (*oembedResponse, error) {
+	ref, err := url.Parse(href)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := pageURL.ResolveReference(ref).String()
+	if err := f.checkURL(endpoint); err != nil {
+		return nil, err
+	}
+
+	body, _, err := f.get(ctx, endpoint, "application/json")
+	if err != nil {
+		return nil, err
+	}
+	var oe oembedResponse
+	if err := json.Unmarshal(body, &oe); err != nil {
+		return nil, fmt.Errorf("linkpreview: invalid oembed: %w", err)
+	}
+	return &oe, nil
+}
+
+// get performs a bounded GET and returns at most maxBodyBytes of the body
+// along with the final URL after redirects.
+func (f *Fetcher) get(ctx context.Context, rawURL, wantType string) ([]byte, *url.URL, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("linkpreview: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", wantType)
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("linkpreview: fetch: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("linkpreview: unexpected status %d", resp.StatusCode)
+	}
+	if cl := resp.Header.Get("Content-Length"); cl != "" {
+		if n, err := strconv.ParseInt(cl, 10, 64); err == nil && n > maxBodyBytes*4 {
+			return nil, nil, fmt.Errorf("linkpreview: response too large")
+		}
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if !acceptableType(mediaType, wantType) {
+		return nil, nil, fmt.Errorf("linkpreview: unsupported content type %q", mediaType)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes))
+	if err != nil {
+		return nil, nil, fmt.Errorf("linkpreview: read: %w", err)
+	}
+	return body, resp.Request.URL, nil
+}
+
+func (f *Fetcher) checkURL(rawURL string) error {
+	if err := f.outbound.CheckURL(rawURL); err != nil {
+		return fmt.Errorf("linkpreview: %w", err)
+	}
+	return nil
+}
+
+// resolveImage makes the image URL absolute and keeps it only if it is a safe public http(s) URL.
+func (f *Fetcher) resolveImage(base *url.URL, raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || base == nil {
+		return ""
+	}
+	ref, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	abs := base.ResolveReference(ref).String()
+	if err := f.checkURL(abs); err != nil {
+		return ""
+	}
+	return abs
+}
+
+func acceptableType(got, want string) bool {
+	if want == "text/html" {
+		return got == "text/html" || got == "application/xhtml+xml"
+	}
+	return got == want || got == "text/json" || got == "text/javascript"
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// clip bounds untrusted metadata so a hostile page cannot bloat stored previews.
+func clip(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) <= maxFieldLength {
+		return s
+	}
+	return strings.ToValidUTF8(s[:maxFieldLength], "") + "…"
+}
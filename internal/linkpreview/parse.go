@@ -0,0 +1,80 @@
+package linkpreview
+
+import (
+	"bytes"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// headMeta holds the metadata collected from a page's <head>.
+type headMeta struct {
+	title       string
+	description string
+	og          map[string]string // og:* and twitter:* properties, first value wins
+	oembed      string            // href of the JSON oEmbed discovery link
+}
+
+// parseHead tokenizes body and collects title, meta and oEmbed link tags.
+// Tokenizing stops at <body> since preview metadata lives in <head>.
+// Complexity: O(n) where n = len(body)
+func parseHead(body []byte) headMeta {
+	meta := headMeta{og: make(map[string]string)}
+	z := html.NewTokenizer(bytes.NewReader(body))
+	inTitle := false
+
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return meta
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			switch tok.Data {
+			case "body":
+				return meta
+			case "title":
+				inTitle = meta.title == ""
+			case "meta":
+				attrs := attrMap(tok.Attr)
+				key := strings.ToLower(firstNonEmpty(attrs["property"], attrs["name"]))
+				content := attrs["content"]
+				if key == "" || content == "" {
+					continue
+				}
+				if key == "description" && meta.description == "" {
+					meta.description = content
+				} else if strings.HasPrefix(key, "og:") || strings.HasPrefix(key, "twitter:") {
+					if _, seen := meta.og[key]; !seen {
+						meta.og[key] = content
+					}
+				}
+			case "link":
+				attrs := attrMap(tok.Attr)
+				if strings.EqualFold(attrs["rel"], "alternate") &&
+					strings.EqualFold(attrs["type"], "application/json+oembed") && meta.oembed == "" {
+					meta.oembed = attrs["href"]
+				}
+			}
+		case html.TextToken:
+			if inTitle {
+				meta.title = string(z.Text())
+			}
+		case html.EndTagToken:
+			tok := z.Token()
+			switch tok.Data {
+			case "title":
+				inTitle = false
+			case "head":
+				return meta
+			}
+		}
+	}
+}
+
+func attrMap(attrs []html.Attribute) map[string]string {
+	m := make(map[string]string, len(attrs))
+	for _, a := range attrs {
+		m[strings.ToLower(a.Key)] = strings.TrimSpace(a.Val)
+	}
+	return m
+}
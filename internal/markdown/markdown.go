@@ -0,0 +1,564 @@
+// Package markdown parses Concord message markup into plain text plus a flat
+// list of formatting entities, so every client renders messages identically and
+// server features (link previews, mentions, emoji) share one extraction path.
+//
+// Supported syntax (Discord-flavoured):
+//
+//	**bold**  *italic*  _italic_  __underline__  ~~strike~~  ||spoiler||
+//	`code`  ```lang\ncode block```  [text](https://url)  https://bare.url
+//	<@userID>  <#channelID>  @everyone  @here  <:name:emojiID>  :name:
+//
+// Formatting delimiters are removed from the plain text; mention and emoji
+// tokens are kept verbatim so clients can substitute display names.
+package markdown
+
+import (
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// EntityType identifies the kind of formatting an Entity applies.
+type EntityType string
+
+const (
+	EntityBold        EntityType = "bold"
+	EntityItalic      EntityType = "italic"
+	EntityUnderline   EntityType = "underline"
+	EntityStrike      EntityType = "strikethrough"
+	EntitySpoiler     EntityType = "spoiler"
+	EntityCode        EntityType = "code"
+	EntityCodeBlock   EntityType = "code_block"
+	EntityLink        EntityType = "link"
+	EntityMention     EntityType = "mention"
+	EntityChannel     EntityType = "channel_mention"
+	EntityEveryone    EntityType = "everyone"
+	EntityCustomEmoji EntityType = "custom_emoji"
+)
+
+// Entity is a formatted span of Document.Text.
+// Offset and Length are measured in UTF-16 code units, matching JavaScript strings.
+type Entity struct {
+	Type     EntityType `json:"type"`
+	Offset   int        `json:"offset"`
+	Length   int        `json:"length"`
+	Language string     `json:"language,omitempty"`   // code_block
+	URL      string     `json:"url,omitempty"`        // link
+	UserID   string     `json:"user_id,omitempty"`    // mention
+	Channel  string     `json:"channel_id,omitempty"` // channel_mention
+	Name     string     `json:"name,omitempty"`       // custom_emoji name, or "everyone"/"here"
+	EmojiID  string     `json:"emoji_id,omitempty"`   // custom_emoji (empty for bare :name: tokens)
+}
+
+// Document is the parsed form of a message.
+type Document struct {
+	Text     string   `json:"text"`
+	Entities []Entity `json:"entities"`
+}
+
+// maxDepth bounds nested formatting to keep parsing linear on hostile input.
+const maxDepth = 8
+
+// Parse converts raw message content into a Document.
+// Complexity: O(n) typical; O(n^2) worst case for many unmatched delimiters (n is bounded by message length)
+func Parse(content string) *Document {
+	p := &parser{src: []rune(content)}
+	p.inline(0, len(p.src), 0, false)
+	return &Document{Text: p.out.String(), Entities: p.entities}
+}
+
+// URLs returns every link target in the document, in order of appearance, without duplicates.
+// Complexity: O(e) where e is the number of entities
+func (d *Document) URLs() []string {
+	return d.collect(EntityLink, func(e Entity) string { return e.URL })
+}
+
+// MentionedUserIDs returns the IDs of all users mentioned with <@id>, without duplicates.
+// Complexity: O(e)
+func (d *Document) MentionedUserIDs() []string {
+	return d.collect(EntityMention, func(e Entity) string { return e.UserID })
+}
+
+// MentionedChannelIDs returns the IDs of all channels referenced with <#id>, without duplicates.
+// Complexity: O(e)
+func (d *Document) MentionedChannelIDs() []string {
+	return d.collect(EntityChannel, func(e Entity) string { return e.Channel })
+}
+
+// EmojiNames returns the names of all custom emoji referenced, without duplicates.
+// Complexity: O(e)
+func (d *Document) EmojiNames() []string {
+	return d.collect(EntityCustomEmoji, func(e Entity) string { return e.Name })
+}
+
+// MentionsEveryone reports whether the document contains @everyone or @here.
+// Complexity: O(e)
+func (d *Document) MentionsEveryone() bool {
+	for _, e := range d.Entities {
+		if e.Type == EntityEveryone {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Document) collect(t EntityType, value func(Entity) string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, e := range d.Entities {
+		if e.Type != t {
+			continue
+		}
+		v := value(e)
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		out = append(out, v)
+	}
+	return out
+}
+
+// RewriteEmoji returns content with every custom emoji token outside code
+// (:name:, <:name:id> or <a:name:id>) replaced by rewrite(token, name, emojiID),
+// where emojiID is empty for :name: tokens. Returning token keeps it unchanged.
+// Complexity: same as Parse
+func RewriteEmoji(content string, rewrite func(token, name, emojiID string) string) string {
+	p := &parser{src: []rune(content)}
+	p.inline(0, len(p.src), 0, false)
+	if len(p.emoji) == 0 {
+		return content
+	}
+
+	var b strings.Builder
+	prev := 0
+	for _, t := range p.emoji {
+		b.WriteString(string(p.src[prev:t.start]))
+		b.WriteString(rewrite(string(p.src[t.start:t.end]), t.name, t.id))
+		prev = t.end
+	}
+	b.WriteString(string(p.src[prev:]))
+	return b.String()
+}
+
+// parser holds the state for a single Parse call.
+type parser struct {
+	src      []rune
+	out      strings.Builder
+	outLen   int // length of out in UTF-16 code units
+	entities []Entity
+	emoji    []emojiToken // source positions of custom emoji, for RewriteEmoji
+}
+
+// emojiToken is a custom emoji token at src[start:end].
+type emojiToken struct {
+	start, end int
+	name, id   string
+}
+
+// emit appends runes to the plain text output.
+func (p *parser) emit(rs ...rune) {
+	for _, r := range rs {
+		p.out.WriteRune(r)
+		p.outLen += utf16.RuneLen(r)
+	}
+}
+
+// span records an entity covering everything emitted since start.
+func (p *parser) span(e Entity, start int) {
+	if p.outLen == start {
+		return
+	}
+	e.Offset = start
+	e.Length = p.outLen - start
+	p.entities = append(p.entities, e)
+}
+
+// delimiters that wrap nested inline content, longest first so "**" wins over "*".
+var wrappers = []struct {
+	delim string
+	typ   EntityType
+}{
+	{"||", EntitySpoiler},
+	{"**", EntityBold},
+	{"__", EntityUnderline},
+	{"~~", EntityStrike},
+	{"*", EntityItalic},
+	{"_", EntityItalic},
+}
+
+// inline parses src[start:end] and emits text and entities.
+// inLink disables nested links inside masked link text.
+func (p *parser) inline(start, end, depth int, inLink bool) {
+	i := start
+	for i < end {
+		if n := p.tryAt(i, end, depth, inLink); n > 0 {
+			i += n
+			continue
+		}
+		p.emit(p.src[i])
+		i++
+	}
+}
+
+// tryAt attempts to parse a construct beginning at i and returns the runes consumed (0 = none).
+func (p *parser) tryAt(i, end, depth int, inLink bool) int {
+	r := p.src[i]
+	switch r {
+	case '\\':
+		if i+1 < end && isEscapable(p.src[i+1]) {
+			p.emit(p.src[i+1])
+			return 2
+		}
+	case '`':
+		if p.hasPrefix(i, end, "```") {
+			if n := p.codeBlock(i, end); n > 0 {
+				return n
+			}
+		}
+		return p.codeSpan(i, end)
+	case '[':
+		if !inLink {
+			return p.maskedLink(i, end, depth)
+		}
+	case '<':
+		return p.angleToken(i, end)
+	case '@':
+		return p.everyone(i, end)
+	case ':':
+		return p.shortEmoji(i, end)
+	case 'h':
+		if !inLink {
+			return p.autoLink(i, end)
+		}
+	}
+
+	if depth >= maxDepth {
+		return 0
+	}
+	for _, w := range wrappers {
+		if n := p.wrapped(i, end, depth, inLink, w.delim, w.typ); n > 0 {
+			return n
+		}
+	}
+	return 0
+}
+
+// wrapped handles paired delimiters such as **bold** with nested content.
+func (p *parser) wrapped(i, end, depth int, inLink bool, delim string, typ EntityType) int {
+	if !p.hasPrefix(i, end, delim) {
+		return 0
+	}
+	dl := len([]rune(delim))
+	innerStart := i + dl
+	if innerStart >= end || unicode.IsSpace(p.src[innerStart]) {
+		return 0
+	}
+	// Underscore emphasis must sit on word boundaries so snake_case stays literal.
+	if delim == "_" && i > 0 && isWordRune(p.src[i-1]) {
+		return 0
+	}
+
+	closeAt := p.findClose(innerStart, end, delim)
+	if closeAt < 0 || closeAt == innerStart || unicode.IsSpace(p.src[closeAt-1]) {
+		return 0
+	}
+	if delim == "_" && closeAt+dl < end && isWordRune(p.src[closeAt+dl]) {
+		return 0
+	}
+
+	start := p.outLen
+	p.inline(innerStart, closeAt, depth+1, inLink)
+	p.span(Entity{Type: typ}, start)
+	return closeAt + dl - i
+}
+
+// findClose locates the next unescaped delim in src[from:end], skipping code spans.
+// For single-rune delimiters a doubled occurrence (e.g. "**" when looking for "*") is skipped.
+func (p *parser) findClose(from, end int, delim string) int {
+	dl := len([]rune(delim))
+	for j := from; j < end; j++ {
+		switch p.src[j] {
+		case '\\':
+			j++
+			continue
+		case '`':
+			if k := p.indexFrom(j+1, end, "`"); k >= 0 {
+				j = k
+				continue
+			}
+		}
+		if !p.hasPrefix(j, end, delim) {
+			continue
+		}
+		if dl == 1 && j+1 < end && p.src[j+1] == p.src[j] {
+			j++ // part of a longer delimiter, not ours
+			continue
+		}
+		return j
+	}
+	return -1
+}
+
+// codeBlock parses ```lang\ncode``` starting at i.
+func (p *parser) codeBlock(i, end int) int {
+	closeAt := p.indexFrom(i+3, end, "```")
+	if closeAt < 0 {
+		return 0
+	}
+	body := p.src[i+3 : closeAt]
+
+	lang := ""
+	if nl := indexRune(body, '\n'); nl >= 0 && isLanguageTag(body[:nl]) {
+		lang = string(body[:nl])
+		body = body[nl+1:]
+	} else if len(body) > 0 && body[0] == '\n' {
+		body = body[1:]
+	}
+	if len(body) == 0 {
+		return 0
+	}
+
+	start := p.outLen
+	p.emit(body...)
+	p.span(Entity{Type: EntityCodeBlock, Language: strings.ToLower(lang)}, start)
+	return closeAt + 3 - i
+}
+
+// codeSpan parses `code` starting at i. Content is verbatim.
+func (p *parser) codeSpan(i, end int) int {
+	closeAt := p.indexFrom(i+1, end, "`")
+	if closeAt <= i+1 {
+		return 0
+	}
+	start := p.outLen
+	p.emit(p.src[i+1 : closeAt]...)
+	p.span(Entity{Type: EntityCode}, start)
+	return closeAt + 1 - i
+}
+
+// maskedLink parses [text](url). Only http, https and mailto targets become links.
+func (p *parser) maskedLink(i, end, depth int) int {
+	textEnd := p.indexFrom(i+1, end, "]")
+	if textEnd <= i+1 || textEnd+1 >= end || p.src[textEnd+1] != '(' {
+		return 0
+	}
+	urlEnd := p.indexFrom(textEnd+2, end, ")")
+	if urlEnd < 0 {
+		return 0
+	}
+	target := strings.TrimSpace(string(p.src[textEnd+2 : urlEnd]))
+	if !IsSafeURL(target) {
+		return 0
+	}
+
+	start := p.outLen
+	p.inline(i+1, textEnd, depth+1, true)
+	p.span(Entity{Type: EntityLink, URL: target}, start)
+	return urlEnd + 1 - i
+}
+
+// autoLink turns bare http(s) URLs into link entities.
+func (p *parser) autoLink(i, end int) int {
+	if !p.hasPrefix(i, end, "http://") && !p.hasPrefix(i, end, "https://") {
+		return 0
+	}
+	if i > 0 && isWordRune(p.src[i-1]) {
+		return 0
+	}
+
+	j := i
+	for j < end && !unicode.IsSpace(p.src[j]) && p.src[j] != '<' && p.src[j] != '>' {
+		j++
+	}
+	// Trim trailing punctuation that is almost always sentence syntax, keeping balanced parens.
+	for j > i {
+		last := p.src[j-1]
+		if strings.ContainsRune(".,:;!?'\"*_~|", last) {
+			j--
+			continue
+		}
+		if last == ')' && strings.Count(string(p.src[i:j]), "(") < strings.Count(string(p.src[i:j]), ")") {
+			j--
+			continue
+		}
+		break
+	}
+
+	raw := string(p.src[i:j])
+	if !IsSafeURL(raw) {
+		return 0
+	}
+	start := p.outLen
+	p.emit(p.src[i:j]...)
+	p.span(Entity{Type: EntityLink, URL: raw}, start)
+	return j - i
+}
+
+// angleToken parses <@user>, <@!user>, <#channel>, <:name:id> and <a:name:id>.
+func (p *parser) angleToken(i, end int) int {
+	closeAt := p.indexFrom(i+1, end, ">")
+	if closeAt < 0 || closeAt-i > 100 {
+		return 0
+	}
+	body := string(p.src[i+1 : closeAt])
+
+	var e Entity
+	switch {
+	case strings.HasPrefix(body, "@"):
+		id := strings.TrimPrefix(strings.TrimPrefix(body, "@"), "!")
+		if !isIdentifier(id) {
+			return 0
+		}
+		e = Entity{Type: EntityMention, UserID: id}
+	case strings.HasPrefix(body, "#"):
+		id := strings.TrimPrefix(body, "#")
+		if !isIdentifier(id) {
+			return 0
+		}
+		e = Entity{Type: EntityChannel, Channel: id}
+	case strings.HasPrefix(body, ":") || strings.HasPrefix(body, "a:"):
+		parts := strings.Split(strings.TrimPrefix(body, "a"), ":")
+		if len(parts) != 3 || !IsEmojiName(parts[1]) || !isIdentifier(parts[2]) {
+			return 0
+		}
+		e = Entity{Type: EntityCustomEmoji, Name: parts[1], EmojiID: parts[2]}
+		p.emoji = append(p.emoji, emojiToken{start: i, end: closeAt + 1, name: parts[1], id: parts[2]})
+	default:
+		return 0
+	}
+
+	start := p.outLen
+	p.emit(p.src[i : closeAt+1]...)
+	p.span(e, start)
+	return closeAt + 1 - i
+}
+
+// everyone parses @everyone and @here.
+func (p *parser) everyone(i, end int) int {
+	if i > 0 && isWordRune(p.src[i-1]) {
+		return 0
+	}
+	for _, name := range []string{"everyone", "here"} {
+		n := len(name) + 1
+		if p.hasPrefix(i+1, end, name) && (i+n >= end || !isWordRune(p.src[i+n])) {
+			start := p.outLen
+			p.emit(p.src[i : i+n]...)
+			p.span(Entity{Type: EntityEveryone, Name: name}, start)
+			return n
+		}
+	}
+	return 0
+}
+
+// shortEmoji parses :name: tokens referring to server custom emoji.
+func (p *parser) shortEmoji(i, end int) int {
+	if i > 0 && isWordRune(p.src[i-1]) {
+		return 0
+	}
+	closeAt := p.indexFrom(i+1, end, ":")
+	if closeAt < 0 {
+		return 0
+	}
+	name := string(p.src[i+1 : closeAt])
+	if !IsEmojiName(name) {
+		return 0
+	}
+	start := p.outLen
+	p.emit(p.src[i : closeAt+1]...)
+	p.span(Entity{Type: EntityCustomEmoji, Name: name}, start)
+	p.emoji = append(p.emoji, emojiToken{start: i, end: closeAt + 1, name: name})
+	return closeAt + 1 - i
+}
+
+func (p *parser) hasPrefix(i, end int, s string) bool {
+	for _, r := range s {
+		if i >= end || p.src[i] != r {
+			return false
+		}
+		i++
+	}
+	return true
+}
+
+func (p *parser) indexFrom(from, end int, s string) int {
+	for j := from; j < end; j++ {
+		if p.hasPrefix(j, end, s) {
+			return j
+		}
+	}
+	return -1
+}
+
+// IsSafeURL reports whether target is an absolute http(s) or mailto URL.
+// Complexity: O(n)
+func IsSafeURL(target string) bool {
+	u, err := url.Parse(target)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		return u.Host != ""
+	case "mailto":
+		return u.Opaque != ""
+	}
+	return false
+}
+
+// IsEmojiName reports whether name is a valid custom emoji name (2-32 chars of [A-Za-z0-9_]).
+// Complexity: O(n)
+func IsEmojiName(name string) bool {
+	if len(name) < 2 || len(name) > 32 {
+		return false
+	}
+	for _, r := range name {
+		if !(r == '_' || r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))) {
+			return false
+		}
+	}
+	return true
+}
+
+// isIdentifier accepts the ID formats used across Concord (UUIDs, gh_123, etc.).
+func isIdentifier(s string) bool {
+	if s == "" || len(s) > 64 {
+		return false
+	}
+	for _, r := range s {
+		if !(r == '_' || r == '-' || r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))) {
+			return false
+		}
+	}
+	return true
+}
+
+func isLanguageTag(rs []rune) bool {
+	if len(rs) == 0 || len(rs) > 20 {
+		return false
+	}
+	for _, r := range rs {
+		if !(unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("+-#._", r)) {
+			return false
+		}
+	}
+	return true
+}
+
+func isEscapable(r rune) bool {
+	return strings.ContainsRune("\\*_~`|[]()<>:@#", r)
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+func indexRune(rs []rune, r rune) int {
+	for i, c := range rs {
+		if c == r {
+			return i
+		}
+	}
+	return -1
+}
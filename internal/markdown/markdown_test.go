@@ -0,0 +1,150 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_PlainText(t *testing.T) {
+	doc := Parse("hello world")
+	assert.Equal(t, "hello world", doc.Text)
+	assert.Empty(t, doc.Entities)
+}
+
+func TestParse_InlineFormatting(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		text   string
+		entity Entity
+	}{
+		{"bold", "a **b** c", "a b c", Entity{Type: EntityBold, Offset: 2, Length: 1}},
+		{"italic star", "*hi*", "hi", Entity{Type: EntityItalic, Offset: 0, Length: 2}},
+		{"italic underscore", "_hi_ there", "hi there", Entity{Type: EntityItalic, Offset: 0, Length: 2}},
+		{"underline", "__u__", "u", Entity{Type: EntityUnderline, Offset: 0, Length: 1}},
+		{"strike", "~~gone~~", "gone", Entity{Type: EntityStrike, Offset: 0, Length: 4}},
+		{"spoiler", "||secret||", "secret", Entity{Type: EntitySpoiler, Offset: 0, Length: 6}},
+		{"inline code", "run `go test`", "run go test", Entity{Type: EntityCode, Offset: 4, Length: 7}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := Parse(tt.input)
+			assert.Equal(t, tt.text, doc.Text)
+			require.Len(t, doc.Entities, 1)
+			assert.Equal(t, tt.entity, doc.Entities[0])
+		})
+	}
+}
+
+func TestParse_Nested(t *testing.T) {
+	doc := Parse("||**x**||")
+	assert.Equal(t, "x", doc.Text)
+	require.Len(t, doc.Entities, 2)
+	assert.Equal(t, EntityBold, doc.Entities[0].Type)
+	assert.Equal(t, EntitySpoiler, doc.Entities[1].Type)
+}
+
+func TestParse_LiteralCases(t *testing.T) {
+	tests := []string{
+		"snake_case_name",
+		"2 * 3 * 4",
+		"** not bold **",
+		"unclosed **bold",
+		"time 12:30:45",
+		"[label](javascript:alert(1))",
+		"`",
+	}
+	for _, input := range tests {
+		doc := Parse(input)
+		assert.Equal(t, input, doc.Text, input)
+		assert.Empty(t, doc.Entities, input)
+	}
+}
+
+func TestParse_Escapes(t *testing.T) {
+	doc := Parse(`\*\*not bold\*\*`)
+	assert.Equal(t, "**not bold**", doc.Text)
+	assert.Empty(t, doc.Entities)
+}
+
+func TestParse_CodeBlock(t *testing.T) {
+	doc := Parse("look:\n```go\nfmt.Println(\"**hi**\")\n```")
+	assert.Equal(t, "look:\nfmt.Println(\"**hi**\")\n", doc.Text)
+	require.Len(t, doc.Entities, 1)
+	assert.Equal(t, EntityCodeBlock, doc.Entities[0].Type)
+	assert.Equal(t, "go", doc.Entities[0].Language)
+	assert.Equal(t, 6, doc.Entities[0].Offset)
+
+	doc = Parse("```\nno lang\n```")
+	require.Len(t, doc.Entities, 1)
+	assert.Empty(t, doc.Entities[0].Language)
+	assert.Equal(t, "no lang\n", doc.Text)
+}
+
+func TestParse_Links(t *testing.T) {
+	doc := Parse("see [the docs](https://example.com/a) or https://example.org/x_(y), ok")
+	assert.Equal(t, "see the docs or https://example.org/x_(y), ok", doc.Text)
+	require.Len(t, doc.Entities, 2)
+	assert.Equal(t, Entity{Type: EntityLink, Offset: 4, Length: 8, URL: "https://example.com/a"}, doc.Entities[0])
+	assert.Equal(t, "https://example.org/x_(y)", doc.Entities[1].URL)
+	assert.Equal(t, []string{"https://example.com/a", "https://example.org/x_(y)"}, doc.URLs())
+}
+
+func TestParse_MentionsAndEmoji(t *testing.T) {
+	doc := Parse("hey <@gh_42> and <@!gh_7> in <#general-id> @everyone :party_parrot: <:blob:e1> <@gh_42>")
+	assert.Equal(t, []string{"gh_42", "gh_7"}, doc.MentionedUserIDs())
+	assert.Equal(t, []string{"general-id"}, doc.MentionedChannelIDs())
+	assert.Equal(t, []string{"party_parrot", "blob"}, doc.EmojiNames())
+	assert.True(t, doc.MentionsEveryone())
+
+	// Tokens stay verbatim in the text so clients can substitute names
+	assert.Contains(t, doc.Text, "<@gh_42>")
+
+	assert.False(t, Parse("email me@everyone.com").MentionsEveryone())
+}
+
+func TestRewriteEmoji(t *testing.T) {
+	rewrite := func(token, name, emojiID string) string {
+		if emojiID == "" {
+			return "<:" + name + ":id-" + name + ">"
+		}
+		return ":" + name + ":"
+	}
+
+	assert.Equal(t, "**hi <:wave:id-wave>** and :blob:", RewriteEmoji("**hi :wave:** and <a:blob:e1>", rewrite))
+	assert.Equal(t, "`:wave:` :not a token:", RewriteEmoji("`:wave:` :not a token:", rewrite), "code and invalid names are left alone")
+	assert.Equal(t, "plain", RewriteEmoji("plain", rewrite))
+}
+
+func TestParse_UTF16Offsets(t *testing.T) {
+	// The emoji occupies two UTF-16 code units
+	doc := Parse("😀 **b**")
+	require.Len(t, doc.Entities, 1)
+	assert.Equal(t, 3, doc.Entities[0].Offset)
+	assert.Equal(t, 1, doc.Entities[0].Length)
+}
+
+func TestParse_DeepNestingIsBounded(t *testing.T) {
+	input := ""
+	for i := 0; i < 50; i++ {
+		input += "||"
+	}
+	input += "x"
+	for i := 0; i < 50; i++ {
+		input += "||"
+	}
+	doc := Parse(input)
+	assert.Contains(t, doc.Text, "x")
+	assert.LessOrEqual(t, len(doc.Entities), maxDepth)
+}
+
+func TestIsSafeURL(t *testing.T) {
+	assert.True(t, IsSafeURL("https://example.com"))
+	assert.True(t, IsSafeURL("mailto:someone@example.com"))
+	assert.False(t, IsSafeURL("javascript:alert(1)"))
+	assert.False(t, IsSafeURL("data:text/html;base64,xx"))
+	assert.False(t, IsSafeURL("/relative"))
+}
@@ -59,16 +59,20 @@ type PeerInfo struct {
 // MessageHandler is called when a message is received from a peer.
 type MessageHandler func(peerID string, data []byte)
 
+// ConnectHandler is called when the first connection to a peer is established.
+type ConnectHandler func(peerID string)
+
 // Host wraps a libp2p host with Concord-specific functionality.
 type Host struct {
-	mu       sync.RWMutex
-	host     host.Host
-	dht      *dht.IpfsDHT
-	mdns     mdns.Service
-	handler  MessageHandler
-	logger   zerolog.Logger
-	ctx      context.Context
-	cancel   context.CancelFunc
+	mu        sync.RWMutex
+	host      host.Host
+	dht       *dht.IpfsDHT
+	mdns      mdns.Service
+	handler   MessageHandler
+	onConnect ConnectHandler
+	logger    zerolog.Logger
+	ctx       context.Context
+	cancel    context.CancelFunc
 }
 
 // New creates and starts a new P2P host.
@@ -103,6 +107,7 @@ func New(cfg Config, logger zerolog.Logger) (*Host, error) {
 
 	// Set stream handler for incoming messages
 	h.SetStreamHandler(ConcordProtocol, p2pHost.handleStream)
+	h.Network().Notify(&network.NotifyBundle{ConnectedF: p2pHost.handleConnected})
 
 	logger.Info().
 		Str("peer_id", h.ID().String()).
@@ -142,6 +147,14 @@ func (h *Host) OnMessage(handler MessageHandler) {
 	h.handler = handler
 }
 
+// OnPeerConnected registers a handler for newly connected peers.
+// It runs on its own goroutine, so it may send to the peer.
+func (h *Host) OnPeerConnected(handler ConnectHandler) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onConnect = handler
+}
+
 // Connect connects to a peer by their multiaddr string.
 func (h *Host) Connect(ctx context.Context, addrStr string) error {
 	addr, err := peer.AddrInfoFromString(addrStr)
@@ -279,6 +292,18 @@ func (h *Host) handleStream(s network.Stream) {
 	handler(s.Conn().RemotePeer().String(), buf[:n])
 }
 
+// handleConnected fires the connect handler once per peer, ignoring extra connections.
+func (h *Host) handleConnected(n network.Network, conn network.Conn) {
+	h.mu.RLock()
+	handler := h.onConnect
+	h.mu.RUnlock()
+
+	if handler == nil || len(n.ConnsToPeer(conn.RemotePeer())) > 1 {
+		return
+	}
+	go handler(conn.RemotePeer().String())
+}
+
 // startMDNS sets up mDNS for LAN peer discovery.
 func (h *Host) startMDNS() error {
 	notifee := &mdnsNotifee{host: h}
@@ -109,6 +109,36 @@ func TestSendAndReceiveData(t *testing.T) {
 	}
 }
 
+func TestOnPeerConnected(t *testing.T) {
+	cfg := Config{
+		ListenPort: 0,
+		EnableMDNS: false,
+		EnableDHT:  false,
+	}
+
+	h1, err := New(cfg, testLogger())
+	require.NoError(t, err)
+	defer h1.Stop()
+
+	h2, err := New(cfg, testLogger())
+	require.NoError(t, err)
+	defer h2.Stop()
+
+	connected := make(chan string, 4)
+	h1.OnPeerConnected(func(peerID string) { connected <- peerID })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, h2.Connect(ctx, h1.Addrs()[0]))
+
+	select {
+	case peerID := <-connected:
+		assert.Equal(t, h2.ID(), peerID)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for connect notification")
+	}
+}
+
 func TestPeersInfo(t *testing.T) {
 	cfg := Config{
 		ListenPort: 0,
@@ -11,6 +11,8 @@ type MessageType string
 const (
 	TypeProfile MessageType = "profile"
 	TypeChat    MessageType = "chat"
+	TypeAck     MessageType = "ack"
+	TypeTyping  MessageType = "typing"
 )
 
 // Envelope é o wrapper JSON trafegado pelo stream libp2p.
@@ -27,11 +29,24 @@ type ProfilePayload struct {
 }
 
 // ChatPayload é o payload do envelope de chat.
+// ID é estável entre reenvios; o destinatário o usa para deduplicar e confirmar.
 type ChatPayload struct {
+	ID      string `json:"id,omitempty"`
 	Content string `json:"content"`
 	SentAt  string `json:"sent_at"`
 }
 
+// AckPayload confirma o recebimento de uma mensagem de chat.
+type AckPayload struct {
+	MessageID string `json:"message_id"`
+}
+
+// TypingPayload indica que o remetente começou ou parou de digitar.
+// Envelopes "typing" não são confirmados nem reenviados: o indicador expira sozinho.
+type TypingPayload struct {
+	Typing bool `json:"typing"`
+}
+
 // EncodeEnvelope serializa um envelope para bytes JSON.
 // Complexity: O(n) onde n é o tamanho do payload.
 func EncodeEnvelope(msgType MessageType, senderID string, payload any) ([]byte, error) {
@@ -53,3 +53,29 @@ func TestEncodeEnvelope_NilPayload(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, TypeChat, env.Type)
 }
+
+func TestEncodeDecodeEnvelope_Typing(t *testing.T) {
+	data, err := EncodeEnvelope(TypeTyping, "peer-123", TypingPayload{Typing: true})
+	require.NoError(t, err)
+
+	env, err := DecodeEnvelope(data)
+	require.NoError(t, err)
+	assert.Equal(t, TypeTyping, env.Type)
+
+	var decoded TypingPayload
+	require.NoError(t, json.Unmarshal(env.Payload, &decoded))
+	assert.True(t, decoded.Typing)
+}
+
+func TestEncodeDecodeEnvelope_Ack(t *testing.T) {
+	data, err := EncodeEnvelope(TypeAck, "peer-123", AckPayload{MessageID: "peer-456-2026-02-21T10:00:00Z"})
+	require.NoError(t, err)
+
+	env, err := DecodeEnvelope(data)
+	require.NoError(t, err)
+	assert.Equal(t, TypeAck, env.Type)
+
+	var decoded AckPayload
+	require.NoError(t, json.Unmarshal(env.Payload, &decoded))
+	assert.Equal(t, "peer-456-2026-02-21T10:00:00Z", decoded.MessageID)
+}
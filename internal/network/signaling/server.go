@@ -1,6 +1,7 @@
 package signaling
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -18,6 +19,7 @@ const (
 	pingPeriod     = 15 * time.Second
 	maxMessageSize = 64 * 1024
 	peerSendBuffer = 128
+	policyTimeout  = 5 * time.Second
 )
 
 var (
@@ -33,9 +35,18 @@ type Server struct {
 	// channels maps "serverID:channelID" -> map of peerID -> connection
 	channels     map[string]map[string]*peerConn
 	channelStart map[string]time.Time
+	onOccupied   func(serverID, channelID, userID string)
+	policy       JoinPolicy
 	logger       zerolog.Logger
 }
 
+// JoinPolicy decides who may join a voice channel and whether they may speak.
+type JoinPolicy interface {
+	// VoiceAccess reports whether userID may join channelID of serverID, and
+	// whether they may unmute there.
+	VoiceAccess(ctx context.Context, serverID, channelID, userID string) (connect, speak bool, err error)
+}
+
 type peerConn struct {
 	conn          *websocket.Conn
 	userID        string
@@ -45,6 +56,7 @@ type peerConn struct {
 	muted         bool
 	deafened      bool
 	screenSharing bool
+	suppressed    bool // may not speak; kept muted
 	send          chan []byte
 	closeOnce     sync.Once
 }
@@ -110,6 +122,18 @@ func NewServer(logger zerolog.Logger) *Server {
 	}
 }
 
+// OnChannelOccupied registers fn to be called when the first peer joins an empty
+// voice channel. It runs on its own goroutine. Call before serving connections.
+func (s *Server) OnChannelOccupied(fn func(serverID, channelID, userID string)) {
+	s.onOccupied = fn
+}
+
+// SetJoinPolicy checks every join against p; peers that may not speak are kept
+// muted. Without a policy anyone may join any channel. Call before serving connections.
+func (s *Server) SetJoinPolicy(p JoinPolicy) {
+	s.policy = p
+}
+
 // Handler returns an HTTP handler for WebSocket connections.
 func (s *Server) Handler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -175,6 +199,27 @@ func (s *Server) handleConnection(conn *websocket.Conn) {
 				continue
 			}
 
+			joined := signal.Payload
+			suppressed := false
+			if s.policy != nil {
+				ctx, cancel := context.WithTimeout(context.Background(), policyTimeout)
+				connect, speak, err := s.policy.VoiceAccess(ctx, signal.ServerID, signal.ChannelID, payload.UserID)
+				cancel()
+				if err != nil || !connect {
+					s.logger.Warn().Err(err).
+						Str("user", payload.UserID).
+						Str("channel", channelKey).
+						Msg("voice join denied")
+					s.rejectJoin(conn, currentPC)
+					continue
+				}
+				if !speak && !payload.Muted {
+					payload.Muted = true
+					joined, _ = json.Marshal(payload)
+				}
+				suppressed = !speak
+			}
+
 			if payload.Deafened {
 				payload.Muted = true
 			}
@@ -190,6 +235,7 @@ func (s *Server) handleConnection(conn *websocket.Conn) {
 				muted:         payload.Muted,
 				deafened:      payload.Deafened,
 				screenSharing: payload.ScreenSharing,
+				suppressed:    suppressed,
 				send:          make(chan []byte, peerSendBuffer),
 			}
 
@@ -205,7 +251,7 @@ func (s *Server) handleConnection(conn *websocket.Conn) {
 				From:      payload.PeerID,
 				ServerID:  signal.ServerID,
 				ChannelID: signal.ChannelID,
-				Payload:   signal.Payload,
+				Payload:   joined,
 			})
 
 			s.logger.Info().
@@ -276,7 +322,7 @@ func (s *Server) handleConnection(conn *websocket.Conn) {
 				continue // debug-only peer_state, don't broadcast
 			}
 
-			muted := payload.Muted
+			muted := payload.Muted || currentPC.suppressed
 			deafened := payload.Deafened
 			if deafened {
 				muted = true
@@ -307,15 +353,20 @@ func (s *Server) handleConnection(conn *websocket.Conn) {
 
 func (s *Server) addPeer(channelKey string, pc *peerConn) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-
 	if _, ok := s.channels[channelKey]; !ok {
 		s.channels[channelKey] = make(map[string]*peerConn)
 	}
-	if len(s.channels[channelKey]) == 0 {
+	occupied := len(s.channels[channelKey]) == 0
+	if occupied {
 		s.channelStart[channelKey] = time.Now().UTC()
 	}
 	s.channels[channelKey][pc.peerID] = pc
+	s.mu.Unlock()
+
+	if occupied && s.onOccupied != nil {
+		parts := splitChannelKey(channelKey)
+		go s.onOccupied(parts[0], parts[1], pc.userID)
+	}
 }
 
 func (s *Server) removePeer(channelKey, peerID string) {
@@ -445,11 +496,65 @@ func (s *Server) forwardToPeer(channelKey, toPeerID string, signal *Signal) {
 	}
 }
 
+// rejectJoin tells a peer it may not join a voice channel. Peers already in a
+// channel are told through their write pump; others are written to directly,
+// since nothing else writes to their connection yet.
+func (s *Server) rejectJoin(conn *websocket.Conn, pc *peerConn) {
+	sig := s.makeErrorSignal(http.StatusForbidden, "not allowed to join this voice channel")
+	if pc != nil {
+		_ = pc.enqueueJSON(sig)
+		return
+	}
+	_ = conn.SetWriteDeadline(time.Now().Add(writeWait))
+	_ = conn.WriteJSON(sig)
+}
+
 func (s *Server) makeErrorSignal(code int, message string) *Signal {
 	sig, _ := NewSignal(SignalError, "", ErrorPayload{Code: code, Message: message})
 	return sig
 }
 
+// DisconnectUser drops every connection of userID in the voice channels of
+// serverID, e.g. after they were banned. Each connection is sent a 403 error
+// signal before it is closed, and the remaining peers are told it left.
+// Returns the number of connections dropped.
+func (s *Server) DisconnectUser(serverID, userID string) int {
+	prefix := serverID + ":"
+	type peerRef struct{ channelKey, peerID string }
+	var dropped []peerRef
+
+	s.mu.RLock()
+	for key, ch := range s.channels {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		for pid, pc := range ch {
+			if pc.userID == userID {
+				_ = pc.enqueueJSON(s.makeErrorSignal(http.StatusForbidden, "removed from this server's voice channels"))
+				dropped = append(dropped, peerRef{key, pid})
+			}
+		}
+	}
+	s.mu.RUnlock()
+
+	for _, p := range dropped {
+		s.removePeer(p.channelKey, p.peerID)
+		parts := splitChannelKey(p.channelKey)
+		s.broadcast(p.channelKey, p.peerID, &Signal{
+			Type:      SignalPeerLeft,
+			From:      p.peerID,
+			ServerID:  parts[0],
+			ChannelID: parts[1],
+		})
+		s.logger.Info().
+			Str("user", userID).
+			Str("peer", p.peerID).
+			Str("channel", p.channelKey).
+			Msg("peer disconnected by moderation")
+	}
+	return len(dropped)
+}
+
 // ChannelCount returns the number of active channels.
 func (s *Server) ChannelCount() int {
 	s.mu.RLock()
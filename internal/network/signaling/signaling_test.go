@@ -304,3 +304,113 @@ func TestMultipleChannels(t *testing.T) {
 	// Get the per-channel breakdown
 	assert.Equal(t, fmt.Sprintf("%d channels, %d peers", srv.ChannelCount(), srv.PeerCount()), "2 channels, 3 peers")
 }
+
+// fakePolicy grants voice access per user ID.
+type fakePolicy map[string][2]bool
+
+func (p fakePolicy) VoiceAccess(_ context.Context, _, _, userID string) (bool, bool, error) {
+	access := p[userID]
+	return access[0], access[1], nil
+}
+
+func TestJoinPolicy(t *testing.T) {
+	srv, httpSrv := setupServer(t)
+	srv.SetJoinPolicy(fakePolicy{"listener": {true, false}, "watcher": {true, true}})
+	url := wsURL(httpSrv)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	denied := NewClient(url, testLogger())
+	require.NoError(t, denied.Connect(ctx))
+	defer denied.Close()
+	errs := make(chan ErrorPayload, 1)
+	denied.On(SignalError, func(sig *Signal) {
+		var ep ErrorPayload
+		sig.DecodePayload(&ep)
+		errs <- ep
+	})
+	require.NoError(t, denied.JoinChannel("server-1", "channel-1", JoinPayload{UserID: "stranger", PeerID: "peer-1"}))
+	select {
+	case ep := <-errs:
+		assert.Equal(t, http.StatusForbidden, ep.Code)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for join rejection")
+	}
+	assert.Equal(t, 0, srv.PeerCount())
+
+	// Members who may not speak join muted and stay muted
+	listener := NewClient(url, testLogger())
+	require.NoError(t, listener.Connect(ctx))
+	defer listener.Close()
+	require.NoError(t, listener.JoinChannel("server-1", "channel-1", JoinPayload{UserID: "listener", PeerID: "peer-2"}))
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, 1, srv.PeerCount())
+
+	peers := make(chan []PeerEntry, 1)
+	watcher := NewClient(url, testLogger())
+	require.NoError(t, watcher.Connect(ctx))
+	defer watcher.Close()
+	watcher.On(SignalPeerList, func(sig *Signal) {
+		var pl PeerListPayload
+		sig.DecodePayload(&pl)
+		peers <- pl.Peers
+	})
+	require.NoError(t, watcher.JoinChannel("server-1", "channel-1", JoinPayload{UserID: "watcher", PeerID: "peer-3"}))
+	select {
+	case list := <-peers:
+		require.Len(t, list, 1)
+		assert.True(t, list[0].Muted)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for peer list")
+	}
+}
+
+func TestDisconnectUser(t *testing.T) {
+	srv, httpSrv := setupServer(t)
+	url := wsURL(httpSrv)
+	ctx := context.Background()
+
+	stay := NewClient(url, testLogger())
+	require.NoError(t, stay.Connect(ctx))
+	defer stay.Close()
+	banned := NewClient(url, testLogger())
+	require.NoError(t, banned.Connect(ctx))
+	defer banned.Close()
+	elsewhere := NewClient(url, testLogger())
+	require.NoError(t, elsewhere.Connect(ctx))
+	defer elsewhere.Close()
+
+	require.NoError(t, stay.JoinChannel("s1", "c1", JoinPayload{UserID: "u1", PeerID: "p1"}))
+	require.NoError(t, banned.JoinChannel("s1", "c1", JoinPayload{UserID: "u2", PeerID: "p2"}))
+	require.NoError(t, elsewhere.JoinChannel("s2", "c1", JoinPayload{UserID: "u2", PeerID: "p3"}))
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, 3, srv.PeerCount())
+
+	peerLeft := make(chan string, 1)
+	stay.On(SignalPeerLeft, func(sig *Signal) {
+		peerLeft <- sig.From
+	})
+	errs := make(chan ErrorPayload, 1)
+	banned.On(SignalError, func(sig *Signal) {
+		var ep ErrorPayload
+		sig.DecodePayload(&ep)
+		errs <- ep
+	})
+
+	assert.Equal(t, 1, srv.DisconnectUser("s1", "u2"))
+	select {
+	case ep := <-errs:
+		assert.Equal(t, http.StatusForbidden, ep.Code)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for error signal")
+	}
+	select {
+	case from := <-peerLeft:
+		assert.Equal(t, "p2", from)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for peer_left")
+	}
+	assert.Equal(t, 2, srv.PeerCount(), "connections in other servers are kept")
+	assert.Zero(t, srv.DisconnectUser("s1", "u2"))
+}
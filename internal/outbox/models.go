@@ -0,0 +1,38 @@
+// Package outbox persists outgoing messages until the recipient acknowledges them,
+// retrying with exponential backoff while a peer or the central server is unreachable.
+package outbox
+
+// State is the delivery state of an outbox entry.
+type State string
+
+const (
+	StateQueued    State = "queued"    // waiting for the first or next attempt
+	StateSent      State = "sent"      // handed to the transport, awaiting acknowledgement
+	StateDelivered State = "delivered" // acknowledged by the recipient
+	StateFailed    State = "failed"    // gave up after MaxAttempts
+)
+
+// Delivery targets.
+const (
+	TargetP2P    = "p2p"    // recipient is a libp2p peer ID
+	TargetServer = "server" // recipient is a channel ID on the central server
+)
+
+// Entry is a message waiting in (or delivered from) the outbox.
+type Entry struct {
+	ID            string `json:"id"`
+	Target        string `json:"target"`
+	Recipient     string `json:"recipient"`
+	Content       string `json:"content"`
+	State         State  `json:"state"`
+	Attempts      int    `json:"attempts"`
+	LastError     string `json:"last_error,omitempty"`
+	NextAttemptAt string `json:"next_attempt_at"` // ISO 8601
+	CreatedAt     string `json:"created_at"`      // ISO 8601
+	UpdatedAt     string `json:"updated_at"`      // ISO 8601
+}
+
+// Pending reports whether the entry may still be (re)delivered.
+func (e *Entry) Pending() bool {
+	return e.State == StateQueued || e.State == StateSent
+}
@@ -0,0 +1,176 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// timeLayout is fixed-width so stored times sort and compare correctly as text.
+const timeLayout = "2006-01-02T15:04:05.000Z"
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// Repository handles outbox persistence.
+type Repository struct {
+	db     querier
+	logger zerolog.Logger
+}
+
+// NewRepository creates a new outbox repository.
+func NewRepository(db querier, logger zerolog.Logger) *Repository {
+	return &Repository{
+		db:     db,
+		logger: logger.With().Str("component", "outbox_repo").Logger(),
+	}
+}
+
+const entryColumns = `id, target, recipient, content, state, attempts, last_error, next_attempt_at, created_at, updated_at`
+
+// Insert stores a new entry.
+// Complexity: O(1)
+func (r *Repository) Insert(ctx context.Context, e *Entry) error {
+	query := `INSERT INTO outbox (` + entryColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := r.db.ExecContext(ctx, query,
+		e.ID, e.Target, e.Recipient, e.Content, e.State, e.Attempts, e.LastError,
+		e.NextAttemptAt, e.CreatedAt, e.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert outbox entry: %w", err)
+	}
+	return nil
+}
+
+// GetByID returns an entry, or nil if not found.
+// Complexity: O(1)
+func (r *Repository) GetByID(ctx context.Context, id string) (*Entry, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+entryColumns+` FROM outbox WHERE id = ?`, id)
+	e, err := scanEntry(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get outbox entry: %w", err)
+	}
+	return e, nil
+}
+
+// UpdateState records the outcome of a delivery attempt.
+// Complexity: O(1)
+func (r *Repository) UpdateState(ctx context.Context, e *Entry) error {
+	query := `UPDATE outbox SET state = ?, attempts = ?, last_error = ?, next_attempt_at = ?, updated_at = ?
+		WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, e.State, e.Attempts, e.LastError, e.NextAttemptAt, e.UpdatedAt, e.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update outbox entry: %w", err)
+	}
+	return nil
+}
+
+// MarkDelivered moves a pending entry to delivered. It returns false when the entry
+// does not exist or was already delivered or failed.
+// Complexity: O(1)
+func (r *Repository) MarkDelivered(ctx context.Context, id string, now time.Time) (bool, error) {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE outbox SET state = 'delivered', last_error = '', updated_at = ?
+		WHERE id = ? AND state IN ('queued', 'sent')`,
+		formatTime(now), id,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark outbox entry delivered: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to mark outbox entry delivered: %w", err)
+	}
+	return n > 0, nil
+}
+
+// ListDue returns pending entries for target whose next attempt is at or before now,
+// oldest first. An empty recipient matches every recipient; ignoreSchedule returns all
+// pending entries regardless of next_attempt_at (used when a peer reconnects).
+// Complexity: O(log n + k) with idx_outbox_due
+func (r *Repository) ListDue(ctx context.Context, target, recipient string, now time.Time, ignoreSchedule bool, limit int) ([]*Entry, error) {
+	query := `SELECT ` + entryColumns + ` FROM outbox
+		WHERE state IN ('queued', 'sent') AND target = ?`
+	args := []interface{}{target}
+	if recipient != "" {
+		query += ` AND recipient = ?`
+		args = append(args, recipient)
+	}
+	if !ignoreSchedule {
+		query += ` AND next_attempt_at <= ?`
+		args = append(args, formatTime(now))
+	}
+	query += ` ORDER BY created_at ASC LIMIT ?`
+	args = append(args, limit)
+
+	return r.list(ctx, query, args...)
+}
+
+// ListByRecipient returns every entry for a recipient, oldest first.
+// Complexity: O(log n + k)
+func (r *Repository) ListByRecipient(ctx context.Context, target, recipient string, limit int) ([]*Entry, error) {
+	query := `SELECT ` + entryColumns + ` FROM outbox
+		WHERE target = ? AND recipient = ?
+		ORDER BY created_at ASC LIMIT ?`
+	return r.list(ctx, query, target, recipient, limit)
+}
+
+// DeleteDelivered removes delivered entries last updated before cutoff.
+// Complexity: O(k) where k = removed rows
+func (r *Repository) DeleteDelivered(ctx context.Context, cutoff time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM outbox WHERE state = 'delivered' AND updated_at < ?`, formatTime(cutoff))
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune outbox: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+func (r *Repository) list(ctx context.Context, query string, args ...interface{}) ([]*Entry, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list outbox entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*Entry
+	for rows.Next() {
+		e, err := scanEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan outbox entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating outbox entries: %w", err)
+	}
+	return entries, nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanEntry(s scanner) (*Entry, error) {
+	var e Entry
+	var state string
+	if err := s.Scan(&e.ID, &e.Target, &e.Recipient, &e.Content, &state, &e.Attempts, &e.LastError,
+		&e.NextAttemptAt, &e.CreatedAt, &e.UpdatedAt); err != nil {
+		return nil, err
+	}
+	e.State = State(state)
+	return &e, nil
+}
@@ -0,0 +1,446 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// MaxAttempts is how many deliveries are tried before an entry is marked failed.
+	MaxAttempts = 10
+
+	defaultBaseDelay  = 5 * time.Second
+	defaultMaxDelay   = 10 * time.Minute
+	defaultAckTimeout = 30 * time.Second
+	deliveredTTL      = 7 * 24 * time.Hour
+	batchSize         = 100
+)
+
+// Deliverer hands an entry to its transport. A nil error means the transport accepted
+// it; the entry stays "sent" until Ack is called or the acknowledgement times out.
+type Deliverer func(ctx context.Context, e *Entry) error
+
+// Listener is notified after every state change.
+type Listener func(e *Entry)
+
+// Service queues outgoing messages and retries them until acknowledged.
+// Targets with a registered Deliverer are retried in-process (Run, Flush); other
+// targets are delivered by the caller through Claim and Report.
+type Service struct {
+	repo *Repository
+
+	mu         sync.RWMutex
+	deliverers map[string]Deliverer
+	listeners  []Listener
+
+	attemptMu sync.Mutex          // serializes state changes around deliveries
+	inFlight  map[string]struct{} // entries handed to a deliverer; guarded by attemptMu
+
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+	ackTimeout time.Duration
+	now        func() time.Time
+	logger     zerolog.Logger
+}
+
+// NewService creates a new outbox service.
+func NewService(repo *Repository, logger zerolog.Logger) *Service {
+	return &Service{
+		repo:       repo,
+		deliverers: make(map[string]Deliverer),
+		inFlight:   make(map[string]struct{}),
+		baseDelay:  defaultBaseDelay,
+		maxDelay:   defaultMaxDelay,
+		ackTimeout: defaultAckTimeout,
+		now:        time.Now,
+		logger:     logger.With().Str("component", "outbox_service").Logger(),
+	}
+}
+
+// SetDeliverer registers the transport for a target.
+func (s *Service) SetDeliverer(target string, d Deliverer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deliverers[target] = d
+}
+
+// OnChange registers a listener for entry state changes.
+func (s *Service) OnChange(l Listener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, l)
+}
+
+// Enqueue stores a message for recipient and, when the target has a deliverer, tries
+// to deliver it immediately. A failed first attempt is not an error: the entry stays
+// queued and is retried with backoff. An empty id generates one.
+// Complexity: O(1) plus one delivery
+func (s *Service) Enqueue(ctx context.Context, target, recipient, id, content string) (*Entry, error) {
+	if target != TargetP2P && target != TargetServer {
+		return nil, fmt.Errorf("unknown outbox target %q", target)
+	}
+	if recipient == "" {
+		return nil, fmt.Errorf("recipient is required")
+	}
+	if strings.TrimSpace(content) == "" {
+		return nil, fmt.Errorf("message content cannot be empty")
+	}
+	if id == "" {
+		id = uuid.New().String()
+	}
+
+	now := formatTime(s.now())
+	e := &Entry{
+		ID:            id,
+		Target:        target,
+		Recipient:     recipient,
+		Content:       content,
+		State:         StateQueued,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := s.repo.Insert(ctx, e); err != nil {
+		return nil, err
+	}
+
+	s.logger.Debug().
+		Str("id", id).
+		Str("target", target).
+		Str("recipient", recipient).
+		Msg("message queued")
+	s.notify(e)
+
+	if d := s.deliverer(target); d != nil {
+		if _, err := s.attempt(ctx, e, d); err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+// Ack marks an entry delivered. Unknown or already settled IDs are ignored,
+// so duplicate acknowledgements are harmless.
+// Complexity: O(1)
+func (s *Service) Ack(ctx context.Context, id string) error {
+	changed, err := s.repo.MarkDelivered(ctx, id, s.now())
+	if err != nil || !changed {
+		return err
+	}
+	e, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if e != nil {
+		s.logger.Debug().Str("id", id).Msg("message delivered")
+		s.notify(e)
+	}
+	return nil
+}
+
+// Flush retries every pending entry for a recipient immediately, ignoring backoff.
+// Call it when a peer reconnects. Returns how many entries were handed to the transport.
+// Complexity: O(k) deliveries
+func (s *Service) Flush(ctx context.Context, target, recipient string) (int, error) {
+	return s.deliverPending(ctx, target, recipient, true)
+}
+
+// RetryDue retries entries whose backoff has elapsed for every target with a deliverer.
+// Complexity: O(k) deliveries
+func (s *Service) RetryDue(ctx context.Context) (int, error) {
+	s.mu.RLock()
+	targets := make([]string, 0, len(s.deliverers))
+	for t := range s.deliverers {
+		targets = append(targets, t)
+	}
+	s.mu.RUnlock()
+
+	total := 0
+	for _, t := range targets {
+		n, err := s.deliverPending(ctx, t, "", false)
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// Run calls RetryDue every interval and prunes old delivered entries until ctx is done.
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	lastPrune := time.Time{}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.RetryDue(ctx); err != nil {
+				s.logger.Warn().Err(err).Msg("outbox retry failed")
+			}
+			if now := s.now(); now.Sub(lastPrune) > time.Hour {
+				lastPrune = now
+				if _, err := s.repo.DeleteDelivered(ctx, now.Add(-deliveredTTL)); err != nil {
+					s.logger.Warn().Err(err).Msg("outbox prune failed")
+				}
+			}
+		}
+	}
+}
+
+// Claim leases up to limit due entries of a target delivered outside this service
+// (e.g. by the frontend over HTTP). Claimed entries move to "sent" and become due
+// again if Report is not called within the acknowledgement timeout.
+// Complexity: O(k)
+func (s *Service) Claim(ctx context.Context, target string, limit int) ([]*Entry, error) {
+	if limit <= 0 || limit > batchSize {
+		limit = batchSize
+	}
+
+	s.attemptMu.Lock()
+	defer s.attemptMu.Unlock()
+
+	due, err := s.repo.ListDue(ctx, target, "", s.now(), false, limit)
+	if err != nil {
+		return nil, err
+	}
+	claimed := make([]*Entry, 0, len(due))
+	for _, e := range due {
+		if e.Attempts >= MaxAttempts {
+			if err := s.fail(ctx, e, "no acknowledgement"); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		e.Attempts++
+		s.markSent(e)
+		if err := s.repo.UpdateState(ctx, e); err != nil {
+			return nil, err
+		}
+		s.notify(e)
+		claimed = append(claimed, e)
+	}
+	return claimed, nil
+}
+
+// Report records the outcome of delivering a claimed entry: nil acknowledges it,
+// anything else schedules a retry (or fails the entry after MaxAttempts).
+// Complexity: O(1)
+func (s *Service) Report(ctx context.Context, id string, deliveryErr error) error {
+	if deliveryErr == nil {
+		return s.Ack(ctx, id)
+	}
+
+	s.attemptMu.Lock()
+	defer s.attemptMu.Unlock()
+
+	e, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		return fmt.Errorf("outbox entry not found")
+	}
+	if !e.Pending() {
+		return nil
+	}
+	return s.recordFailure(ctx, e, deliveryErr)
+}
+
+// List returns the entries for a recipient, oldest first.
+// Complexity: O(k)
+func (s *Service) List(ctx context.Context, target, recipient string) ([]*Entry, error) {
+	return s.repo.ListByRecipient(ctx, target, recipient, batchSize)
+}
+
+func (s *Service) deliverPending(ctx context.Context, target, recipient string, ignoreSchedule bool) (int, error) {
+	d := s.deliverer(target)
+	if d == nil {
+		return 0, nil
+	}
+
+	due, err := s.repo.ListDue(ctx, target, recipient, s.now(), ignoreSchedule, batchSize)
+	if err != nil {
+		return 0, err
+	}
+	sent := 0
+	unreachable := make(map[string]bool)
+	for _, e := range due {
+		// One failure is enough to know a recipient is unreachable; the rest of
+		// its entries wait for the next retry instead of burning an attempt each.
+		if unreachable[e.Recipient] {
+			continue
+		}
+		attempted, err := s.attempt(ctx, e, d)
+		if err != nil {
+			return sent, err
+		}
+		if !attempted {
+			continue
+		}
+		if e.State == StateSent {
+			sent++
+		} else {
+			unreachable[e.Recipient] = true
+		}
+	}
+	return sent, nil
+}
+
+// attempt delivers e once and persists the outcome. It reports whether e was handed
+// to the deliverer; the returned error is a storage error, delivery errors are
+// recorded on the entry. attemptMu is held only while the entry's state changes, never
+// across the deliverer, so a slow recipient does not hold up deliveries to others.
+func (s *Service) attempt(ctx context.Context, e *Entry, d Deliverer) (bool, error) {
+	claimed, err := s.begin(ctx, e)
+	if err != nil || !claimed {
+		return false, err
+	}
+	return true, s.finish(ctx, e, d(ctx, e))
+}
+
+// begin marks e in flight: it counts the attempt and moves the entry to "sent" so
+// scheduled retries and claims skip it until the acknowledgement timeout. It returns
+// false when e was settled, attempted or taken in flight since it was loaded.
+func (s *Service) begin(ctx context.Context, e *Entry) (bool, error) {
+	s.attemptMu.Lock()
+	defer s.attemptMu.Unlock()
+
+	if _, busy := s.inFlight[e.ID]; busy {
+		return false, nil
+	}
+	current, err := s.repo.GetByID(ctx, e.ID)
+	if err != nil {
+		return false, err
+	}
+	if current == nil || !current.Pending() || current.Attempts != e.Attempts {
+		return false, nil
+	}
+
+	if e.Attempts >= MaxAttempts {
+		reason := e.LastError
+		if reason == "" {
+			reason = "no acknowledgement"
+		}
+		return false, s.fail(ctx, e, reason)
+	}
+
+	e.Attempts++
+	s.markSent(e)
+	if err := s.repo.UpdateState(ctx, e); err != nil {
+		return false, err
+	}
+	s.inFlight[e.ID] = struct{}{}
+	return true, nil
+}
+
+// finish records the outcome of a delivery started by begin. An entry acknowledged
+// while its delivery was in flight is left alone.
+func (s *Service) finish(ctx context.Context, e *Entry, deliveryErr error) error {
+	s.attemptMu.Lock()
+	defer s.attemptMu.Unlock()
+	defer delete(s.inFlight, e.ID)
+
+	current, err := s.repo.GetByID(ctx, e.ID)
+	if err != nil {
+		return err
+	}
+	if current == nil || !current.Pending() {
+		return nil
+	}
+
+	if deliveryErr != nil {
+		s.logger.Debug().Err(deliveryErr).
+			Str("id", e.ID).
+			Str("recipient", e.Recipient).
+			Int("attempts", e.Attempts).
+			Msg("delivery attempt failed")
+		return s.recordFailure(ctx, e, deliveryErr)
+	}
+
+	// The acknowledgement timeout starts once the transport has the entry.
+	s.markSent(e)
+	if err := s.repo.UpdateState(ctx, e); err != nil {
+		return err
+	}
+	s.notify(e)
+	return nil
+}
+
+func (s *Service) markSent(e *Entry) {
+	now := s.now()
+	e.State = StateSent
+	e.LastError = ""
+	e.NextAttemptAt = formatTime(now.Add(s.ackTimeout))
+	e.UpdatedAt = formatTime(now)
+}
+
+func (s *Service) recordFailure(ctx context.Context, e *Entry, deliveryErr error) error {
+	if e.Attempts >= MaxAttempts {
+		return s.fail(ctx, e, deliveryErr.Error())
+	}
+	now := s.now()
+	e.State = StateQueued
+	e.LastError = deliveryErr.Error()
+	e.NextAttemptAt = formatTime(now.Add(s.backoff(e.Attempts)))
+	e.UpdatedAt = formatTime(now)
+	if err := s.repo.UpdateState(ctx, e); err != nil {
+		return err
+	}
+	s.notify(e)
+	return nil
+}
+
+func (s *Service) fail(ctx context.Context, e *Entry, reason string) error {
+	e.State = StateFailed
+	e.LastError = reason
+	e.UpdatedAt = formatTime(s.now())
+	if err := s.repo.UpdateState(ctx, e); err != nil {
+		return err
+	}
+	s.logger.Warn().
+		Str("id", e.ID).
+		Str("target", e.Target).
+		Str("recipient", e.Recipient).
+		Str("reason", reason).
+		Msg("message delivery failed permanently")
+	s.notify(e)
+	return nil
+}
+
+// backoff returns the delay before the next attempt: baseDelay doubled per attempt, capped.
+// Complexity: O(1)
+func (s *Service) backoff(attempts int) time.Duration {
+	d := s.baseDelay
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= s.maxDelay {
+			return s.maxDelay
+		}
+	}
+	return d
+}
+
+func (s *Service) deliverer(target string) Deliverer {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.deliverers[target]
+}
+
+func (s *Service) notify(e *Entry) {
+	s.mu.RLock()
+	listeners := s.listeners
+	s.mu.RUnlock()
+	for _, l := range listeners {
+		snapshot := *e
+		l(&snapshot)
+	}
+}
@@ -0,0 +1,292 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/concord-chat/concord/internal/store/sqlite"
+)
+
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func setupService(t *testing.T) (*Service, *fakeClock) {
+	t.Helper()
+	logger := zerolog.Nop()
+	db, err := sqlite.New(sqlite.Config{
+		Path:         filepath.Join(t.TempDir(), "test.db"),
+		MaxOpenConns: 1,
+		ForeignKeys:  true,
+		BusyTimeout:  5 * time.Second,
+	}, logger)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, sqlite.NewMigrator(db, logger).Migrate(context.Background()))
+
+	clock := &fakeClock{t: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+	svc := NewService(NewRepository(db, logger), logger)
+	svc.now = clock.now
+	return svc, clock
+}
+
+func TestService_DeliversImmediatelyAndAcks(t *testing.T) {
+	svc, _ := setupService(t)
+	ctx := context.Background()
+
+	var delivered []string
+	svc.SetDeliverer(TargetP2P, func(_ context.Context, e *Entry) error {
+		delivered = append(delivered, e.ID)
+		return nil
+	})
+	var states []State
+	svc.OnChange(func(e *Entry) { states = append(states, e.State) })
+
+	e, err := svc.Enqueue(ctx, TargetP2P, "peer-1", "m1", "hello")
+	require.NoError(t, err)
+	assert.Equal(t, StateSent, e.State)
+	assert.Equal(t, 1, e.Attempts)
+	assert.Equal(t, []string{"m1"}, delivered)
+
+	require.NoError(t, svc.Ack(ctx, "m1"))
+	require.NoError(t, svc.Ack(ctx, "m1"), "duplicate acks are ignored")
+
+	entries, err := svc.List(ctx, TargetP2P, "peer-1")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, StateDelivered, entries[0].State)
+	assert.Equal(t, []State{StateQueued, StateSent, StateDelivered}, states)
+}
+
+func TestService_RetriesWithBackoff(t *testing.T) {
+	svc, clock := setupService(t)
+	ctx := context.Background()
+
+	online := false
+	calls := 0
+	svc.SetDeliverer(TargetP2P, func(context.Context, *Entry) error {
+		calls++
+		if !online {
+			return errors.New("peer offline")
+		}
+		return nil
+	})
+
+	e, err := svc.Enqueue(ctx, TargetP2P, "peer-1", "", "hello")
+	require.NoError(t, err)
+	assert.Equal(t, StateQueued, e.State)
+	assert.Equal(t, "peer offline", e.LastError)
+
+	// Backoff has not elapsed yet.
+	n, err := svc.RetryDue(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	assert.Equal(t, 1, calls)
+
+	clock.advance(defaultBaseDelay)
+	online = true
+	n, err = svc.RetryDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, 2, calls)
+}
+
+func TestService_FlushIgnoresBackoff(t *testing.T) {
+	svc, _ := setupService(t)
+	ctx := context.Background()
+
+	online := false
+	svc.SetDeliverer(TargetP2P, func(context.Context, *Entry) error {
+		if !online {
+			return errors.New("peer offline")
+		}
+		return nil
+	})
+
+	_, err := svc.Enqueue(ctx, TargetP2P, "peer-1", "a", "one")
+	require.NoError(t, err)
+	_, err = svc.Enqueue(ctx, TargetP2P, "peer-2", "b", "two")
+	require.NoError(t, err)
+
+	online = true
+	n, err := svc.Flush(ctx, TargetP2P, "peer-1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	entries, err := svc.List(ctx, TargetP2P, "peer-2")
+	require.NoError(t, err)
+	assert.Equal(t, StateQueued, entries[0].State, "other peers keep their backoff")
+}
+
+func TestService_ResendsWithoutAck(t *testing.T) {
+	svc, clock := setupService(t)
+	ctx := context.Background()
+
+	calls := 0
+	svc.SetDeliverer(TargetP2P, func(context.Context, *Entry) error {
+		calls++
+		return nil
+	})
+
+	_, err := svc.Enqueue(ctx, TargetP2P, "peer-1", "m1", "hello")
+	require.NoError(t, err)
+
+	clock.advance(defaultAckTimeout)
+	n, err := svc.RetryDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, 2, calls)
+}
+
+func TestService_FailsAfterMaxAttempts(t *testing.T) {
+	svc, clock := setupService(t)
+	ctx := context.Background()
+
+	svc.SetDeliverer(TargetP2P, func(context.Context, *Entry) error {
+		return errors.New("unreachable")
+	})
+
+	_, err := svc.Enqueue(ctx, TargetP2P, "peer-1", "m1", "hello")
+	require.NoError(t, err)
+	for i := 0; i < MaxAttempts; i++ {
+		clock.advance(defaultMaxDelay)
+		_, err := svc.RetryDue(ctx)
+		require.NoError(t, err)
+	}
+
+	entries, err := svc.List(ctx, TargetP2P, "peer-1")
+	require.NoError(t, err)
+	assert.Equal(t, StateFailed, entries[0].State)
+	assert.Equal(t, MaxAttempts, entries[0].Attempts)
+	assert.Equal(t, "unreachable", entries[0].LastError)
+}
+
+func TestService_StopsBatchAfterFailure(t *testing.T) {
+	svc, clock := setupService(t)
+	ctx := context.Background()
+
+	online := false
+	calls := map[string]int{}
+	svc.SetDeliverer(TargetP2P, func(_ context.Context, e *Entry) error {
+		calls[e.Recipient]++
+		if !online && e.Recipient == "peer-1" {
+			return errors.New("peer offline")
+		}
+		return nil
+	})
+
+	for _, id := range []string{"a", "b", "c"} {
+		_, err := svc.Enqueue(ctx, TargetP2P, "peer-1", id, "hello")
+		require.NoError(t, err)
+	}
+	_, err := svc.Enqueue(ctx, TargetP2P, "peer-2", "d", "hello")
+	require.NoError(t, err)
+	calls = map[string]int{}
+
+	clock.advance(defaultAckTimeout)
+	n, err := svc.RetryDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, map[string]int{"peer-1": 1, "peer-2": 1}, calls, "peer-1 is tried once per batch")
+
+	entries, err := svc.List(ctx, TargetP2P, "peer-1")
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 1, 1}, []int{entries[0].Attempts, entries[1].Attempts, entries[2].Attempts})
+}
+
+func TestService_DeliversOutsideLock(t *testing.T) {
+	svc, _ := setupService(t)
+	ctx := context.Background()
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	svc.SetDeliverer(TargetP2P, func(_ context.Context, e *Entry) error {
+		if e.Recipient == "slow" {
+			close(entered)
+			<-release
+		}
+		return nil
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := svc.Enqueue(ctx, TargetP2P, "slow", "s1", "hello")
+		done <- err
+	}()
+	<-entered
+
+	// The slow delivery is in flight: other peers are not held up, and the entry
+	// itself is not handed out again.
+	e, err := svc.Enqueue(ctx, TargetP2P, "fast", "f1", "hello")
+	require.NoError(t, err)
+	assert.Equal(t, StateSent, e.State)
+	n, err := svc.Flush(ctx, TargetP2P, "slow")
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	close(release)
+	require.NoError(t, <-done)
+	entries, err := svc.List(ctx, TargetP2P, "slow")
+	require.NoError(t, err)
+	assert.Equal(t, StateSent, entries[0].State)
+	assert.Equal(t, 1, entries[0].Attempts)
+}
+
+func TestService_ClaimAndReport(t *testing.T) {
+	svc, clock := setupService(t)
+	ctx := context.Background()
+
+	e, err := svc.Enqueue(ctx, TargetServer, "channel-1", "", "hello")
+	require.NoError(t, err)
+	assert.Equal(t, StateQueued, e.State, "server entries wait for a claim")
+
+	claimed, err := svc.Claim(ctx, TargetServer, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, StateSent, claimed[0].State)
+
+	// Leased entries are not handed out twice.
+	again, err := svc.Claim(ctx, TargetServer, 10)
+	require.NoError(t, err)
+	assert.Empty(t, again)
+
+	require.NoError(t, svc.Report(ctx, e.ID, errors.New("server unavailable")))
+	clock.advance(defaultBaseDelay)
+	claimed, err = svc.Claim(ctx, TargetServer, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, 2, claimed[0].Attempts)
+
+	require.NoError(t, svc.Report(ctx, e.ID, nil))
+	entries, err := svc.List(ctx, TargetServer, "channel-1")
+	require.NoError(t, err)
+	assert.Equal(t, StateDelivered, entries[0].State)
+}
+
+func TestService_EnqueueValidation(t *testing.T) {
+	svc, _ := setupService(t)
+	ctx := context.Background()
+
+	_, err := svc.Enqueue(ctx, "email", "x", "", "hi")
+	assert.Error(t, err)
+	_, err = svc.Enqueue(ctx, TargetP2P, "", "", "hi")
+	assert.Error(t, err)
+	_, err = svc.Enqueue(ctx, TargetP2P, "peer", "", "  ")
+	assert.Error(t, err)
+}
+
+func TestService_Backoff(t *testing.T) {
+	svc, _ := setupService(t)
+	assert.Equal(t, 5*time.Second, svc.backoff(1))
+	assert.Equal(t, 10*time.Second, svc.backoff(2))
+	assert.Equal(t, 40*time.Second, svc.backoff(4))
+	assert.Equal(t, defaultMaxDelay, svc.backoff(20))
+}
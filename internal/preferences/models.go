@@ -0,0 +1,14 @@
+package preferences
+
+// Preferences holds per-user privacy settings.
+type Preferences struct {
+	UserID       string `json:"user_id"`
+	LinkPreviews bool   `json:"link_previews"` // fetch and show link previews for this user's messages
+	ReadReceipts bool   `json:"read_receipts"` // send and see DM read receipts (symmetric)
+	UpdatedAt    string `json:"updated_at,omitempty"`
+}
+
+// Defaults returns the settings used for users who never saved preferences.
+func Defaults(userID string) *Preferences {
+	return &Preferences{UserID: userID, LinkPreviews: true, ReadReceipts: true}
+}
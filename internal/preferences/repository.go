@@ -0,0 +1,68 @@
+package preferences
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// Repository handles user preference persistence.
+type Repository struct {
+	db     querier
+	logger zerolog.Logger
+}
+
+// NewRepository creates a new preferences repository.
+func NewRepository(db querier, logger zerolog.Logger) *Repository {
+	return &Repository{
+		db:     db,
+		logger: logger.With().Str("component", "preferences_repo").Logger(),
+	}
+}
+
+// Get returns the stored preferences for a user, or nil if none were saved.
+// Complexity: O(1)
+func (r *Repository) Get(ctx context.Context, userID string) (*Preferences, error) {
+	query := `SELECT user_id, link_previews, read_receipts, updated_at FROM user_preferences WHERE user_id = ?`
+
+	var p Preferences
+	var updatedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&p.UserID, &p.LinkPreviews, &p.ReadReceipts, &updatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get preferences: %w", err)
+	}
+	if updatedAt.Valid {
+		p.UpdatedAt = updatedAt.Time.UTC().Format(time.RFC3339)
+	}
+	return &p, nil
+}
+
+// Upsert creates or replaces the preferences row for a user.
+// Complexity: O(1)
+func (r *Repository) Upsert(ctx context.Context, p *Preferences) error {
+	query := `INSERT INTO user_preferences (user_id, link_previews, read_receipts, updated_at)
+		VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(user_id) DO UPDATE SET
+			link_previews = excluded.link_previews,
+			read_receipts = excluded.read_receipts,
+			updated_at = CURRENT_TIMESTAMP`
+
+	if _, err := r.db.ExecContext(ctx, query, p.UserID, p.LinkPreviews, p.ReadReceipts); err != nil {
+		return fmt.Errorf("failed to save preferences: %w", err)
+	}
+
+	r.logger.Debug().Str("user_id", p.UserID).Msg("preferences saved")
+	return nil
+}
@@ -0,0 +1,110 @@
+package preferences
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/concord-chat/concord/internal/cache"
+)
+
+const cacheTTL = 5 * time.Minute
+
+// Update describes a partial preferences change. Nil fields are left untouched.
+type Update struct {
+	LinkPreviews *bool `json:"link_previews,omitempty"`
+	ReadReceipts *bool `json:"read_receipts,omitempty"`
+}
+
+// Service provides cached access to user preferences.
+type Service struct {
+	repo   *Repository
+	cache  *cache.LRU
+	logger zerolog.Logger
+}
+
+// NewService creates a new preferences service.
+func NewService(repo *Repository, cache *cache.LRU, logger zerolog.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		cache:  cache,
+		logger: logger.With().Str("component", "preferences_service").Logger(),
+	}
+}
+
+// Get returns the preferences for a user, falling back to defaults when none are stored.
+// Complexity: O(1) with cache
+func (s *Service) Get(ctx context.Context, userID string) (*Preferences, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user ID is required")
+	}
+	cacheKey := "prefs:" + userID
+	if val, ok := s.cache.Get(cacheKey); ok {
+		return val.(*Preferences), nil
+	}
+
+	p, err := s.repo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		p = Defaults(userID)
+	}
+	s.cache.Set(cacheKey, p, cacheTTL)
+	return p, nil
+}
+
+// Update applies a partial change to a user's preferences and returns the result.
+// Complexity: O(1)
+func (s *Service) Update(ctx context.Context, userID string, upd Update) (*Preferences, error) {
+	current, err := s.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	next := *current
+	if upd.LinkPreviews != nil {
+		next.LinkPreviews = *upd.LinkPreviews
+	}
+	if upd.ReadReceipts != nil {
+		next.ReadReceipts = *upd.ReadReceipts
+	}
+
+	if err := s.repo.Upsert(ctx, &next); err != nil {
+		return nil, err
+	}
+	s.cache.Delete("prefs:" + userID)
+
+	s.logger.Info().
+		Str("user_id", userID).
+		Bool("link_previews", next.LinkPreviews).
+		Bool("read_receipts", next.ReadReceipts).
+		Msg("preferences updated")
+
+	return s.Get(ctx, userID)
+}
+
+// LinkPreviewsEnabled reports whether link previews are enabled for a user.
+// Lookup errors default to enabled so a transient DB failure does not hide previews.
+// Complexity: O(1) with cache
+func (s *Service) LinkPreviewsEnabled(ctx context.Context, userID string) bool {
+	p, err := s.Get(ctx, userID)
+	if err != nil {
+		s.logger.Warn().Err(err).Str("user_id", userID).Msg("failed to load preferences")
+		return true
+	}
+	return p.LinkPreviews
+}
+
+// ReadReceiptsEnabled reports whether a user sends and sees DM read receipts.
+// Lookup errors default to disabled so a transient DB failure never leaks read state.
+// Complexity: O(1) with cache
+func (s *Service) ReadReceiptsEnabled(ctx context.Context, userID string) bool {
+	p, err := s.Get(ctx, userID)
+	if err != nil {
+		s.logger.Warn().Err(err).Str("user_id", userID).Msg("failed to load preferences")
+		return false
+	}
+	return p.ReadReceipts
+}
@@ -0,0 +1,85 @@
+package preferences
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/concord-chat/concord/internal/cache"
+	"github.com/concord-chat/concord/internal/store/sqlite"
+)
+
+func setupService(t *testing.T) *Service {
+	t.Helper()
+	logger := zerolog.Nop()
+	db, err := sqlite.New(sqlite.Config{
+		Path:         filepath.Join(t.TempDir(), "test.db"),
+		MaxOpenConns: 1,
+		ForeignKeys:  true,
+		BusyTimeout:  5 * time.Second,
+	}, logger)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, sqlite.NewMigrator(db, logger).Migrate(context.Background()))
+
+	_, err = db.ExecContext(context.Background(), `INSERT INTO users (id, username) VALUES ('u1', 'alice')`)
+	require.NoError(t, err)
+
+	return NewService(NewRepository(db, logger), cache.NewLRU(10), logger)
+}
+
+func TestService_DefaultsWhenUnset(t *testing.T) {
+	svc := setupService(t)
+
+	p, err := svc.Get(context.Background(), "u1")
+	require.NoError(t, err)
+	assert.True(t, p.LinkPreviews)
+	assert.True(t, svc.LinkPreviewsEnabled(context.Background(), "u1"))
+}
+
+func TestService_Update(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	off := false
+	p, err := svc.Update(ctx, "u1", Update{LinkPreviews: &off})
+	require.NoError(t, err)
+	assert.False(t, p.LinkPreviews)
+	assert.NotEmpty(t, p.UpdatedAt)
+	assert.False(t, svc.LinkPreviewsEnabled(ctx, "u1"))
+
+	// An empty update keeps the stored value.
+	p, err = svc.Update(ctx, "u1", Update{})
+	require.NoError(t, err)
+	assert.False(t, p.LinkPreviews)
+
+	on := true
+	p, err = svc.Update(ctx, "u1", Update{LinkPreviews: &on})
+	require.NoError(t, err)
+	assert.True(t, p.LinkPreviews)
+}
+
+func TestService_RequiresUserID(t *testing.T) {
+	svc := setupService(t)
+	_, err := svc.Get(context.Background(), "")
+	assert.Error(t, err)
+}
+
+func TestService_ReadReceipts(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	assert.True(t, svc.ReadReceiptsEnabled(ctx, "u1"))
+
+	off := false
+	p, err := svc.Update(ctx, "u1", Update{ReadReceipts: &off})
+	require.NoError(t, err)
+	assert.False(t, p.ReadReceipts)
+	assert.True(t, p.LinkPreviews, "other settings are untouched")
+	assert.False(t, svc.ReadReceiptsEnabled(ctx, "u1"))
+}
@@ -0,0 +1,120 @@
+package security
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrBlockedAddress is returned when an outbound request would reach a private,
+// reserved or internal address.
+var ErrBlockedAddress = errors.New("destination address not allowed")
+
+// Outbound guards server-side requests to user-supplied URLs (link previews,
+// webhooks, bot callbacks) against SSRF. CheckURL fails fast on URLs that are
+// obviously internal; clients built by Client re-check every dialed address after
+// DNS resolution, which defeats DNS rebinding and is the authoritative guard.
+type Outbound struct {
+	// AllowPrivate disables the address checks, except that literal private IPs
+	// are always rejected. Only tests set it, to reach httptest servers by name.
+	AllowPrivate bool
+	// StandardPorts limits URLs to ports 80 and 443, keeping requests away from
+	// other services on public hosts.
+	StandardPorts bool
+
+	validator *Validator
+}
+
+// NewOutbound creates an outbound request guard with private addresses blocked.
+// Complexity: O(1)
+func NewOutbound() *Outbound {
+	return &Outbound{validator: NewValidator()}
+}
+
+// CheckURL validates scheme and length and rejects credentials, private IP
+// literals, internal hostnames and, with StandardPorts, unusual ports.
+// Complexity: O(n) where n is the length of the URL
+func (o *Outbound) CheckURL(rawURL string) error {
+	if err := o.validator.ValidateURL(rawURL); err != nil {
+		return err
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if u.Host == "" {
+		return fmt.Errorf("URL has no host")
+	}
+	if u.User != nil {
+		return fmt.Errorf("credentials in URL not allowed")
+	}
+	if o.AllowPrivate {
+		return nil
+	}
+	if port := u.Port(); o.StandardPorts && port != "" && port != "80" && port != "443" {
+		return fmt.Errorf("port %s not allowed", port)
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") || strings.HasSuffix(host, ".internal") {
+		return ErrBlockedAddress
+	}
+	return nil
+}
+
+// CheckDialAddress rejects connections to private IPs. address is the resolved
+// host:port being dialed.
+// Complexity: O(1)
+func (o *Outbound) CheckDialAddress(address string) error {
+	if o.AllowPrivate {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return ErrBlockedAddress
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || IsPrivateIP(ip) {
+		return ErrBlockedAddress
+	}
+	return nil
+}
+
+// Client returns an HTTP client that refuses to connect to private addresses and
+// never uses a proxy. Up to maxRedirects redirects are followed, each re-checked
+// with CheckURL; with zero the first response is returned as is.
+// Complexity: O(1)
+func (o *Outbound) Client(timeout time.Duration, maxRedirects int) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		// Control runs after DNS resolution with the concrete IP being dialed,
+		// so a hostname that re-resolves to a private address is still refused.
+		Control: func(network, address string, _ syscall.RawConn) error {
+			return o.CheckDialAddress(address)
+		},
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:                 nil, // a proxy would bypass the dial-time address check
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   timeout,
+			ResponseHeaderTimeout: timeout,
+			MaxIdleConns:          16,
+			IdleConnTimeout:       30 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if maxRedirects == 0 {
+				return http.ErrUseLastResponse
+			}
+			if len(via) > maxRedirects {
+				return fmt.Errorf("too many redirects")
+			}
+			return o.CheckURL(req.URL.String())
+		},
+	}
+}
@@ -49,23 +49,18 @@ func NewRateLimiter(rate int, interval time.Duration, capacity int) *RateLimiter
 // Returns true if request is allowed, false if rate limit exceeded
 // Complexity: O(1)
 func (rl *RateLimiter) Allow(key string) bool {
-	rl.mu.RLock()
-	b, exists := rl.buckets[key]
-	rl.mu.RUnlock()
-
-	if !exists {
-		// Create new bucket
-		b = &bucket{
-			tokens:    rl.capacity,
-			lastCheck: time.Now(),
-		}
+	return rl.bucketFor(key).take(rl.rate, rl.interval, rl.capacity)
+}
 
-		rl.mu.Lock()
-		rl.buckets[key] = b
-		rl.mu.Unlock()
+// AllowWithRetry behaves like Allow but also reports how long the caller
+// must wait before the next token becomes available when the request is denied.
+// Complexity: O(1)
+func (rl *RateLimiter) AllowWithRetry(key string) (bool, time.Duration) {
+	b := rl.bucketFor(key)
+	if b.take(rl.rate, rl.interval, rl.capacity) {
+		return true, 0
 	}
-
-	return b.take(rl.rate, rl.interval, rl.capacity)
+	return false, b.untilNext(rl.rate, rl.interval)
 }
 
 // AllowN checks if N requests from the given key should be allowed
@@ -75,21 +70,7 @@ func (rl *RateLimiter) AllowN(key string, n int) bool {
 		return true
 	}
 
-	rl.mu.RLock()
-	b, exists := rl.buckets[key]
-	rl.mu.RUnlock()
-
-	if !exists {
-		b = &bucket{
-			tokens:    rl.capacity,
-			lastCheck: time.Now(),
-		}
-
-		rl.mu.Lock()
-		rl.buckets[key] = b
-		rl.mu.Unlock()
-	}
-
+	b := rl.bucketFor(key)
 	return b.takeN(n, rl.rate, rl.interval, rl.capacity)
 }
 
@@ -102,23 +83,37 @@ func (rl *RateLimiter) Reset(key string) {
 	delete(rl.buckets, key)
 }
 
+// bucketFor returns the bucket for key, creating a full one on first use
+// Complexity: O(1)
+func (rl *RateLimiter) bucketFor(key string) *bucket {
+	rl.mu.RLock()
+	b, exists := rl.buckets[key]
+	rl.mu.RUnlock()
+	if exists {
+		return b
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	// Another goroutine may have created it between the locks
+	if b, exists = rl.buckets[key]; exists {
+		return b
+	}
+	b = &bucket{
+		tokens:    rl.capacity,
+		lastCheck: time.Now(),
+	}
+	rl.buckets[key] = b
+	return b
+}
+
 // take attempts to take one token from the bucket
 func (b *bucket) take(rate int, interval time.Duration, capacity int) bool {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	now := time.Now()
-	elapsed := now.Sub(b.lastCheck)
-
-	// Refill tokens based on elapsed time
-	tokensToAdd := int(elapsed.Nanoseconds() * int64(rate) / interval.Nanoseconds())
-	b.tokens += tokensToAdd
-
-	if b.tokens > capacity {
-		b.tokens = capacity
-	}
-
-	b.lastCheck = now
+	b.refill(time.Now(), rate, interval, capacity)
 
 	// Check if we have tokens available
 	if b.tokens > 0 {
@@ -129,23 +124,47 @@ func (b *bucket) take(rate int, interval time.Duration, capacity int) bool {
 	return false
 }
 
-// takeN attempts to take N tokens from the bucket
-func (b *bucket) takeN(n, rate int, interval time.Duration, capacity int) bool {
-	b.mu.Lock()
-	defer b.mu.Unlock()
-
-	now := time.Now()
+// refill adds the tokens earned since the last check. Only the time that was
+// converted into whole tokens is consumed, so frequent calls do not starve the bucket.
+// Caller must hold b.mu.
+func (b *bucket) refill(now time.Time, rate int, interval time.Duration, capacity int) {
 	elapsed := now.Sub(b.lastCheck)
-
-	// Refill tokens based on elapsed time
 	tokensToAdd := int(elapsed.Nanoseconds() * int64(rate) / interval.Nanoseconds())
-	b.tokens += tokensToAdd
+	if tokensToAdd <= 0 {
+		return
+	}
 
-	if b.tokens > capacity {
+	b.tokens += tokensToAdd
+	if b.tokens >= capacity {
 		b.tokens = capacity
+		b.lastCheck = now
+		return
+	}
+	b.lastCheck = b.lastCheck.Add(time.Duration(int64(tokensToAdd) * interval.Nanoseconds() / int64(rate)))
+}
+
+// untilNext returns how long until the bucket earns its next token
+func (b *bucket) untilNext(rate int, interval time.Duration) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.tokens > 0 {
+		return 0
+	}
+	perToken := time.Duration(interval.Nanoseconds() / int64(rate))
+	wait := perToken - time.Since(b.lastCheck)
+	if wait < 0 {
+		return 0
 	}
+	return wait
+}
 
-	b.lastCheck = now
+// takeN attempts to take N tokens from the bucket
+func (b *bucket) takeN(n, rate int, interval time.Duration, capacity int) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(time.Now(), rate, interval, capacity)
 
 	// Check if we have enough tokens
 	if b.tokens >= n {
@@ -178,6 +197,28 @@ func (rl *RateLimiter) cleanup() {
 	}
 }
 
+// RateLimitError is returned when a caller has exhausted a rate limit and
+// must wait RetryAfter before trying again.
+type RateLimitError struct {
+	Reason     string
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s, retry in %ds", e.Reason, e.RetryAfterSeconds())
+}
+
+// RetryAfterSeconds returns RetryAfter rounded up to whole seconds (minimum 1),
+// suitable for a Retry-After header.
+func (e *RateLimitError) RetryAfterSeconds() int {
+	secs := int((e.RetryAfter + time.Second - 1) / time.Second)
+	if secs < 1 {
+		return 1
+	}
+	return secs
+}
+
 // BruteForceProtector protects against brute force attacks
 // Uses exponential backoff for repeated failures
 type BruteForceProtector struct {
@@ -58,6 +58,47 @@ func TestRateLimiter_AllowN(t *testing.T) {
 	})
 }
 
+func TestRateLimiter_AllowWithRetry(t *testing.T) {
+	t.Run("reports retry-after when exhausted", func(t *testing.T) {
+		rl := NewRateLimiter(1, 1*time.Minute, 1)
+
+		ok, wait := rl.AllowWithRetry("test-key")
+		assert.True(t, ok)
+		assert.Zero(t, wait)
+
+		ok, wait = rl.AllowWithRetry("test-key")
+		assert.False(t, ok)
+		assert.Greater(t, wait, 50*time.Second)
+		assert.LessOrEqual(t, wait, 1*time.Minute)
+	})
+
+	t.Run("frequent calls do not starve refill", func(t *testing.T) {
+		rl := NewRateLimiter(1, 100*time.Millisecond, 1)
+		assert.True(t, rl.Allow("test-key"))
+
+		// Polling faster than the refill rate must still earn a token eventually
+		deadline := time.Now().Add(300 * time.Millisecond)
+		allowed := false
+		for time.Now().Before(deadline) {
+			if ok, _ := rl.AllowWithRetry("test-key"); ok {
+				allowed = true
+				break
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		assert.True(t, allowed)
+	})
+}
+
+func TestRateLimitError(t *testing.T) {
+	err := &RateLimitError{Reason: "slow down", RetryAfter: 1500 * time.Millisecond}
+	assert.Equal(t, 2, err.RetryAfterSeconds())
+	assert.Equal(t, "slow down, retry in 2s", err.Error())
+
+	err = &RateLimitError{Reason: "slow down", RetryAfter: 0}
+	assert.Equal(t, 1, err.RetryAfterSeconds())
+}
+
 func TestRateLimiter_Reset(t *testing.T) {
 	rl := NewRateLimiter(1, 1*time.Second, 1)
 
@@ -221,6 +221,39 @@ func (v *Validator) ValidateFileExtension(filename string, allowedExtensions []s
 	return fmt.Errorf("file extension .%s is not allowed", ext)
 }
 
+// IsPrivateIP reports whether ip must not be reached by server-side fetches
+// (loopback, link-local, private, CGNAT, multicast, unspecified or reserved ranges).
+// Complexity: O(1)
+func IsPrivateIP(ip net.IP) bool {
+	return isPrivateIP(ip)
+}
+
+// privateNets holds the parsed non-public ranges checked by isPrivateIP
+var privateNets = func() []*net.IPNet {
+	cidrs := []string{
+		"0.0.0.0/8",      // "This" network
+		"10.0.0.0/8",     // Private
+		"100.64.0.0/10",  // Carrier-grade NAT
+		"172.16.0.0/12",  // Private
+		"192.0.0.0/24",   // IETF protocol assignments
+		"192.168.0.0/16", // Private
+		"169.254.0.0/16", // Link-local
+		"198.18.0.0/15",  // Benchmarking
+		"240.0.0.0/4",    // Reserved + broadcast
+		"64:ff9b::/96",   // NAT64 (can embed private IPv4)
+		"fc00::/7",       // IPv6 unique local
+		"fe80::/10",      // IPv6 link-local
+	}
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, subnet, err := net.ParseCIDR(cidr)
+		if err == nil {
+			nets = append(nets, subnet)
+		}
+	}
+	return nets
+}()
+
 // isPrivateIP checks if an IP address is in a private range
 // Prevents SSRF attacks by blocking requests to internal services
 func isPrivateIP(ip net.IP) bool {
@@ -234,19 +267,14 @@ func isPrivateIP(ip net.IP) bool {
 		return true
 	}
 
-	// Check for private ranges
-	privateRanges := []string{
-		"10.0.0.0/8",
-		"172.16.0.0/12",
-		"192.168.0.0/16",
-		"169.254.0.0/16", // Link-local
-		"fc00::/7",       // IPv6 unique local
-		"fe80::/10",      // IPv6 link-local
+	// Unspecified (0.0.0.0, ::) and multicast are never valid fetch targets
+	if ip.IsUnspecified() || ip.IsMulticast() {
+		return true
 	}
 
-	for _, cidr := range privateRanges {
-		_, subnet, _ := net.ParseCIDR(cidr)
-		if subnet != nil && subnet.Contains(ip) {
+	// Check for private ranges
+	for _, subnet := range privateNets {
+		if subnet.Contains(ip) {
 			return true
 		}
 	}
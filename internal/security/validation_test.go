@@ -226,6 +226,12 @@ func TestIsPrivateIP(t *testing.T) {
 		{"172.16.0.1", true},
 		{"8.8.8.8", false},
 		{"1.1.1.1", false},
+		{"0.0.0.0", true},
+		{"100.64.1.1", true},
+		{"224.0.0.1", true},
+		{"::1", true},
+		{"::ffff:127.0.0.1", true},
+		{"2606:4700:4700::1111", false},
 	}
 
 	for _, tt := range tests {
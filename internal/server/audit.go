@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// AuditLog returns entries of the audit log of a server matching q, newest first.
+// Requires PermViewAuditLog.
+func (s *Service) AuditLog(ctx context.Context, serverID, userID string, q AuditQuery) ([]*AuditEntry, error) {
+	if err := s.requirePermission(ctx, serverID, userID, PermViewAuditLog); err != nil {
+		return nil, err
+	}
+	return s.repo.ListAuditLog(ctx, serverID, q)
+}
+
+// CanManageMessages reports whether userID can see channelID and delete other
+// members' messages in it. Unknown channels are never manageable.
+// Implements chat.Moderation.
+func (s *Service) CanManageMessages(ctx context.Context, channelID, userID string) (bool, error) {
+	perms, err := s.ChannelPermissions(ctx, channelID, userID)
+	if err != nil {
+		return false, err
+	}
+	return perms.Has(PermViewChannel | PermManageMessages), nil
+}
+
+// MessageDeleted records the deletion of a member's message by a moderator in the
+// audit log of the channel's server.
+// Implements chat.Moderation.
+func (s *Service) MessageDeleted(ctx context.Context, channelID, messageID, authorID, actorID string) {
+	ch, err := s.getChannel(ctx, channelID)
+	if err != nil || ch == nil {
+		s.logger.Warn().Err(err).Str("channel_id", channelID).Msg("failed to audit message deletion")
+		return
+	}
+	s.audit(ctx, ch.ServerID, actorID, AuditMessageDelete, "message", messageID, map[string]any{
+		"channel_id": channelID,
+		"author_id":  authorID,
+	})
+}
+
+// audit appends an entry to the audit log of a server. The action has already
+// happened, so failures are logged rather than returned.
+func (s *Service) audit(ctx context.Context, serverID, actorID, action, targetType, targetID string, details map[string]any) {
+	entry := &AuditEntry{
+		ServerID:   serverID,
+		ActorID:    actorID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+	}
+	if len(details) > 0 {
+		data, err := json.Marshal(details)
+		if err != nil {
+			s.logger.Warn().Err(err).Str("action", action).Msg("failed to encode audit details")
+		}
+		entry.Details = data
+	}
+	if err := s.repo.CreateAuditEntry(ctx, entry); err != nil {
+		s.logger.Error().Err(err).
+			Str("server_id", serverID).
+			Str("actor_id", actorID).
+			Str("action", action).
+			Msg("failed to write audit log")
+	}
+}
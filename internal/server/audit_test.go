@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// auditActions returns the actions of the audit log of srv-1 matching q, newest first.
+func auditActions(t *testing.T, svc *Service, q AuditQuery) []string {
+	t.Helper()
+	entries, err := svc.AuditLog(context.Background(), "srv-1", "owner", q)
+	require.NoError(t, err)
+	var actions []string
+	for _, e := range entries {
+		actions = append(actions, e.Action)
+	}
+	return actions
+}
+
+func TestAuditLog_RecordsActions(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	require.NoError(t, svc.UpdateServer(ctx, "srv-1", "owner", "Renamed", ""))
+	ch, err := svc.CreateChannel(ctx, "srv-1", "admin", "logs", "text")
+	require.NoError(t, err)
+	_, err = svc.GenerateInvite(ctx, "srv-1", "member")
+	require.NoError(t, err)
+	require.NoError(t, svc.UpdateMemberRole(ctx, "srv-1", "admin", "member", RoleModerator))
+	require.NoError(t, svc.DeleteChannel(ctx, "srv-1", "admin", ch.ID))
+	require.NoError(t, svc.KickMember(ctx, "srv-1", "admin", "member"))
+	assert.Error(t, svc.KickMember(ctx, "srv-1", "admin", "owner"))
+
+	assert.Equal(t, []string{
+		AuditMemberKick, AuditChannelDelete, AuditMemberRoleUpdate,
+		AuditInviteCreate, AuditChannelCreate, AuditServerUpdate,
+	}, auditActions(t, svc, AuditQuery{}), "failed actions are not recorded")
+
+	entries, err := svc.AuditLog(ctx, "srv-1", "owner", AuditQuery{Action: AuditServerUpdate})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "owner", entries[0].ActorID)
+	assert.Equal(t, "owner", entries[0].ActorUsername)
+	assert.Equal(t, "srv-1", entries[0].TargetID)
+	assert.NotEmpty(t, entries[0].CreatedAt)
+	var details map[string]string
+	require.NoError(t, json.Unmarshal(entries[0].Details, &details))
+	assert.Equal(t, "Test", details["old_name"])
+	assert.Equal(t, "Renamed", details["name"])
+
+	entries, err = svc.AuditLog(ctx, "srv-1", "owner", AuditQuery{TargetType: "member", TargetID: "member"})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, AuditMemberKick, entries[0].Action)
+	assert.Empty(t, entries[0].Details)
+}
+
+func TestAuditLog_QueryAndAccess(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	for _, name := range []string{"one", "two", "three"} {
+		_, err := svc.CreateChannel(ctx, "srv-1", "admin", name, "text")
+		require.NoError(t, err)
+	}
+	require.NoError(t, svc.UpdateServer(ctx, "srv-1", "owner", "Renamed", ""))
+
+	page, err := svc.AuditLog(ctx, "srv-1", "admin", AuditQuery{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	assert.Equal(t, AuditServerUpdate, page[0].Action)
+	rest, err := svc.AuditLog(ctx, "srv-1", "admin", AuditQuery{Before: page[1].ID})
+	require.NoError(t, err)
+	require.Len(t, rest, 2)
+	assert.Less(t, rest[0].ID, page[1].ID)
+
+	assert.Len(t, auditActions(t, svc, AuditQuery{ActorID: "admin"}), 3)
+	assert.Empty(t, auditActions(t, svc, AuditQuery{ActorID: "member"}))
+
+	_, err = svc.AuditLog(ctx, "srv-1", "member", AuditQuery{})
+	assert.Error(t, err, "members cannot read the audit log")
+	_, err = svc.AuditLog(ctx, "srv-2", "admin", AuditQuery{})
+	assert.Error(t, err, "non-members cannot read the audit log")
+}
+
+func TestAuditLog_MessageModeration(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	ok, err := svc.CanManageMessages(ctx, "ch-1", "admin")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = svc.CanManageMessages(ctx, "ch-1", "member")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = svc.CanManageMessages(ctx, "missing", "admin")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	svc.MessageDeleted(ctx, "ch-1", "msg-1", "member", "admin")
+	entries, err := svc.AuditLog(ctx, "srv-1", "owner", AuditQuery{Action: AuditMessageDelete})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "msg-1", entries[0].TargetID)
+	assert.JSONEq(t, `{"channel_id":"ch-1","author_id":"member"}`, string(entries[0].Details))
+}
@@ -0,0 +1,206 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	maxCategoryName        = 100
+	maxCategoriesPerServer = 50
+)
+
+var (
+	// ErrChannelNotFound is returned for channels that do not exist or belong to another server.
+	ErrChannelNotFound = errors.New("channel not found")
+	// ErrCategoryNotFound is returned for categories that do not exist or belong to another server.
+	ErrCategoryNotFound = errors.New("category not found")
+	// ErrLayoutMismatch is returned when a channel layout does not list every channel
+	// and category of the server exactly once, e.g. because it is stale.
+	ErrLayoutMismatch = errors.New("layout does not match the server's channels and categories")
+)
+
+// CreateCategory creates a channel category after the server's last category.
+// Requires PermManageChannels.
+func (s *Service) CreateCategory(ctx context.Context, serverID, userID, name string) (*Category, error) {
+	if err := s.requirePermission(ctx, serverID, userID, PermManageChannels); err != nil {
+		return nil, err
+	}
+	name, err := validateCategoryName(name)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.ListCategories(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+	if len(categories) >= maxCategoriesPerServer {
+		return nil, fmt.Errorf("a server can have at most %d categories", maxCategoriesPerServer)
+	}
+
+	c := &Category{
+		ID:       uuid.New().String(),
+		ServerID: serverID,
+		Name:     name,
+	}
+	if err := s.repo.CreateCategory(ctx, c); err != nil {
+		return nil, err
+	}
+	s.cache.Delete("categories:server:" + serverID)
+
+	created, err := s.repo.GetCategory(ctx, c.ID)
+	if err != nil {
+		return nil, err
+	}
+	if created == nil {
+		return nil, ErrCategoryNotFound
+	}
+	s.audit(ctx, serverID, userID, AuditCategoryCreate, "category", c.ID, map[string]any{
+		"name": c.Name,
+	})
+	return created, nil
+}
+
+// ListCategories returns the channel categories of a server, ordered by position.
+func (s *Service) ListCategories(ctx context.Context, serverID string) ([]*Category, error) {
+	cacheKey := "categories:server:" + serverID
+	if val, ok := s.cache.Get(cacheKey); ok {
+		return val.([]*Category), nil
+	}
+	categories, err := s.repo.ListCategories(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.Set(cacheKey, categories, cacheTTL)
+	return categories, nil
+}
+
+// RenameCategory renames a channel category. Requires PermManageChannels.
+func (s *Service) RenameCategory(ctx context.Context, serverID, userID, categoryID, name string) (*Category, error) {
+	c, err := s.managedCategory(ctx, serverID, userID, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	oldName := c.Name
+	if c.Name, err = validateCategoryName(name); err != nil {
+		return nil, err
+	}
+	if err := s.repo.RenameCategory(ctx, c.ID, c.Name); err != nil {
+		return nil, err
+	}
+	s.cache.Delete("categories:server:" + serverID)
+	s.audit(ctx, serverID, userID, AuditCategoryUpdate, "category", c.ID, map[string]any{
+		"old_name": oldName,
+		"name":     c.Name,
+	})
+	return c, nil
+}
+
+// DeleteCategory removes a channel category. Its channels are kept and appended,
+// in order, to the server's uncategorized channels. Requires PermManageChannels.
+func (s *Service) DeleteCategory(ctx context.Context, serverID, userID, categoryID string) error {
+	c, err := s.managedCategory(ctx, serverID, userID, categoryID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteCategory(ctx, serverID, categoryID); err != nil {
+		return err
+	}
+	s.invalidateChannels(ctx, serverID)
+	s.audit(ctx, serverID, userID, AuditCategoryDelete, "category", categoryID, map[string]any{
+		"name": c.Name,
+	})
+	return nil
+}
+
+// ReorderChannels replaces the order of all channels and categories of a server,
+// moving channels between categories as listed. The layout must list every
+// channel and category of the server exactly once; a stale layout fails with
+// ErrLayoutMismatch and changes nothing. Requires PermManageChannels.
+func (s *Service) ReorderChannels(ctx context.Context, serverID, userID string, layout ChannelLayout) error {
+	if err := s.requirePermission(ctx, serverID, userID, PermManageChannels); err != nil {
+		return err
+	}
+	if err := s.repo.ApplyLayout(ctx, serverID, layout); err != nil {
+		return err
+	}
+	s.invalidateChannels(ctx, serverID)
+	s.audit(ctx, serverID, userID, AuditChannelReorder, "server", serverID, nil)
+	return nil
+}
+
+// managedCategory loads a category of serverID after checking that userID
+// holds PermManageChannels.
+func (s *Service) managedCategory(ctx context.Context, serverID, userID, categoryID string) (*Category, error) {
+	if err := s.requirePermission(ctx, serverID, userID, PermManageChannels); err != nil {
+		return nil, err
+	}
+	c, err := s.repo.GetCategory(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil || c.ServerID != serverID {
+		return nil, ErrCategoryNotFound
+	}
+	return c, nil
+}
+
+// invalidateChannels drops the cached channels and categories of a server,
+// including each cached channel.
+func (s *Service) invalidateChannels(ctx context.Context, serverID string) {
+	if channels, err := s.repo.ListChannels(ctx, serverID); err == nil {
+		for _, ch := range channels {
+			s.cache.Delete("channel:" + ch.ID)
+		}
+	}
+	s.cache.Delete("channels:server:" + serverID)
+	s.cache.Delete("categories:server:" + serverID)
+}
+
+// covers reports whether the layout lists each of the given channels and
+// categories exactly once, and nothing else.
+func (l ChannelLayout) covers(channels, categories map[string]bool) bool {
+	if len(l.Categories) != len(categories) {
+		return false
+	}
+	seen := make(map[string]bool, len(channels))
+	place := func(ids []string) bool {
+		for _, id := range ids {
+			if !channels[id] || seen[id] {
+				return false
+			}
+			seen[id] = true
+		}
+		return true
+	}
+
+	if !place(l.Uncategorized) {
+		return false
+	}
+	seenCategories := make(map[string]bool, len(categories))
+	for _, cat := range l.Categories {
+		if !categories[cat.ID] || seenCategories[cat.ID] {
+			return false
+		}
+		seenCategories[cat.ID] = true
+		if !place(cat.Channels) {
+			return false
+		}
+	}
+	return len(seen) == len(channels)
+}
+
+func validateCategoryName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("category name cannot be empty")
+	}
+	if len(name) > maxCategoryName {
+		return "", fmt.Errorf("category name cannot exceed %d characters", maxCategoryName)
+	}
+	return name, nil
+}
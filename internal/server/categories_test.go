@@ -0,0 +1,160 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// channelOrder returns "category/channel" for each channel of srv-1 in display order.
+func channelOrder(t *testing.T, svc *Service) []string {
+	t.Helper()
+	channels, err := svc.ListChannels(context.Background(), "srv-1")
+	require.NoError(t, err)
+	var order []string
+	for _, ch := range channels {
+		order = append(order, ch.CategoryID+"/"+ch.ID)
+	}
+	return order
+}
+
+func TestCategories_Lifecycle(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	_, err := svc.CreateCategory(ctx, "srv-1", "member", "Info")
+	assert.Error(t, err, "members cannot manage channels")
+	_, err = svc.CreateCategory(ctx, "srv-1", "admin", "  ")
+	assert.Error(t, err)
+
+	info, err := svc.CreateCategory(ctx, "srv-1", "admin", " Info ")
+	require.NoError(t, err)
+	assert.Equal(t, "Info", info.Name)
+	assert.Equal(t, 0, info.Position)
+	voice, err := svc.CreateCategory(ctx, "srv-1", "admin", "Voice")
+	require.NoError(t, err)
+	assert.Equal(t, 1, voice.Position)
+
+	renamed, err := svc.RenameCategory(ctx, "srv-1", "admin", voice.ID, "Voice rooms")
+	require.NoError(t, err)
+	assert.Equal(t, "Voice rooms", renamed.Name)
+	_, err = svc.RenameCategory(ctx, "srv-1", "admin", "nope", "Elsewhere")
+	assert.ErrorIs(t, err, ErrCategoryNotFound)
+
+	list, err := svc.ListCategories(ctx, "srv-1")
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "Voice rooms", list[1].Name)
+
+	// Moving a channel appends it to the target category
+	ch, err := svc.UpdateChannel(ctx, "srv-1", "admin", "ch-2", ChannelUpdate{CategoryID: &info.ID})
+	require.NoError(t, err)
+	assert.Equal(t, info.ID, ch.CategoryID)
+	assert.Equal(t, 0, ch.Position)
+	_, err = svc.UpdateChannel(ctx, "srv-1", "admin", "ch-1", ChannelUpdate{CategoryID: &info.ID})
+	require.NoError(t, err)
+	_, err = svc.UpdateChannel(ctx, "srv-1", "admin", "vc-1", ChannelUpdate{CategoryID: &voice.ID})
+	require.NoError(t, err)
+	assert.Equal(t, []string{info.ID + "/ch-2", info.ID + "/ch-1", voice.ID + "/vc-1"}, channelOrder(t, svc))
+
+	// Deleting a category keeps its channels, after the uncategorized ones
+	require.NoError(t, svc.DeleteCategory(ctx, "srv-1", "admin", info.ID))
+	assert.Equal(t, []string{"/ch-2", "/ch-1", voice.ID + "/vc-1"}, channelOrder(t, svc))
+	list, err = svc.ListCategories(ctx, "srv-1")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, 0, list[0].Position, "remaining categories are compacted")
+	assert.ErrorIs(t, svc.DeleteCategory(ctx, "srv-1", "admin", info.ID), ErrCategoryNotFound)
+
+	created, err := svc.CreateChannel(ctx, "srv-1", "admin", "new", "text")
+	require.NoError(t, err)
+	assert.Equal(t, 2, created.Position, "new channels go after the uncategorized channels")
+}
+
+func TestReorderChannels(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	cat, err := svc.CreateCategory(ctx, "srv-1", "admin", "Ops")
+	require.NoError(t, err)
+	layout := ChannelLayout{
+		Uncategorized: []string{"vc-1"},
+		Categories:    []CategoryLayout{{ID: cat.ID, Channels: []string{"ch-2", "ch-1"}}},
+	}
+
+	assert.Error(t, svc.ReorderChannels(ctx, "srv-1", "member", layout))
+	require.NoError(t, svc.ReorderChannels(ctx, "srv-1", "admin", layout))
+	assert.Equal(t, []string{"/vc-1", cat.ID + "/ch-2", cat.ID + "/ch-1"}, channelOrder(t, svc))
+
+	ch, err := svc.GetChannel(ctx, "ch-1")
+	require.NoError(t, err)
+	assert.Equal(t, cat.ID, ch.CategoryID, "cached channels are refreshed")
+	assert.Equal(t, 1, ch.Position)
+
+	for name, bad := range map[string]ChannelLayout{
+		"missing channel":    {Uncategorized: []string{"vc-1"}, Categories: []CategoryLayout{{ID: cat.ID, Channels: []string{"ch-2"}}}},
+		"duplicate channel":  {Uncategorized: []string{"vc-1", "ch-1"}, Categories: []CategoryLayout{{ID: cat.ID, Channels: []string{"ch-2", "ch-1"}}}},
+		"foreign channel":    {Uncategorized: []string{"vc-1", "ch-x"}, Categories: []CategoryLayout{{ID: cat.ID, Channels: []string{"ch-2", "ch-1"}}}},
+		"missing category":   {Uncategorized: []string{"vc-1", "ch-2", "ch-1"}},
+		"unknown category":   {Uncategorized: []string{"vc-1"}, Categories: []CategoryLayout{{ID: "nope", Channels: []string{"ch-2", "ch-1"}}}},
+		"duplicate category": {Uncategorized: []string{"vc-1"}, Categories: []CategoryLayout{{ID: cat.ID, Channels: []string{"ch-2"}}, {ID: cat.ID, Channels: []string{"ch-1"}}}},
+	} {
+		assert.ErrorIs(t, svc.ReorderChannels(ctx, "srv-1", "admin", bad), ErrLayoutMismatch, name)
+	}
+	assert.Equal(t, []string{"/vc-1", cat.ID + "/ch-2", cat.ID + "/ch-1"}, channelOrder(t, svc), "rejected layouts change nothing")
+}
+
+func TestUpdateAndDeleteChannel(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+	require.NoError(t, svc.ReorderChannels(ctx, "srv-1", "owner", ChannelLayout{Uncategorized: []string{"ch-1", "ch-2", "vc-1"}}))
+
+	name := " releases "
+	ch, err := svc.UpdateChannel(ctx, "srv-1", "admin", "ch-1", ChannelUpdate{Name: &name})
+	require.NoError(t, err)
+	assert.Equal(t, "releases", ch.Name)
+	assert.Equal(t, "text", ch.Type)
+
+	bad := "video"
+	_, err = svc.UpdateChannel(ctx, "srv-1", "admin", "ch-1", ChannelUpdate{Type: &bad})
+	assert.Error(t, err)
+	_, err = svc.UpdateChannel(ctx, "srv-1", "admin", "ch-x", ChannelUpdate{Name: &name})
+	assert.ErrorIs(t, err, ErrChannelNotFound, "channel of another server")
+	_, err = svc.UpdateChannel(ctx, "srv-1", "member", "ch-1", ChannelUpdate{Name: &name})
+	assert.Error(t, err)
+
+	assert.ErrorIs(t, svc.DeleteChannel(ctx, "srv-1", "admin", "ch-x"), ErrChannelNotFound)
+	require.NoError(t, svc.DeleteChannel(ctx, "srv-1", "admin", "ch-1"))
+	channels, err := svc.ListChannels(ctx, "srv-1")
+	require.NoError(t, err)
+	require.Len(t, channels, 2)
+	assert.Equal(t, 0, channels[0].Position, "positions close the gap")
+	assert.Equal(t, 1, channels[1].Position)
+}
+
+func TestCreateChannel_ConcurrentPositions(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := svc.CreateChannel(ctx, "srv-1", "admin", fmt.Sprintf("room-%d", i), "text")
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	channels, err := svc.ListChannels(ctx, "srv-1")
+	require.NoError(t, err)
+	require.Len(t, channels, 11)
+	for i, ch := range channels {
+		assert.Equal(t, i, ch.Position, "every channel gets its own position")
+	}
+}
@@ -0,0 +1,315 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	maxInvitesPerServer = 1000
+	maxInviteUses       = 1000
+	maxInviteAge        = 30 * 24 * 60 * 60 // 30 days, in seconds
+)
+
+var (
+	// ErrInvalidInvite is returned for invite codes that do not exist, have expired
+	// or have been used up. The cases are deliberately indistinguishable.
+	ErrInvalidInvite = errors.New("invalid or expired invite")
+	// ErrInviteNotFound is returned when revoking an invite that does not exist or
+	// belongs to another server.
+	ErrInviteNotFound = errors.New("invite not found")
+	// ErrInviteCodeTaken is returned when a vanity code is already in use.
+	ErrInviteCodeTaken = errors.New("invite code is already taken")
+)
+
+// vanityCodePattern matches valid vanity invite codes.
+var vanityCodePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{2,31}$`)
+
+// CreateInvite creates an invite to a server. Requires PermCreateInvite, and
+// additionally PermManageServer for a vanity code.
+func (s *Service) CreateInvite(ctx context.Context, serverID, userID string, opts InviteOptions) (*Invite, error) {
+	if opts.MaxUses < 0 || opts.MaxUses > maxInviteUses {
+		return nil, fmt.Errorf("max uses must be between 0 and %d", maxInviteUses)
+	}
+	if opts.MaxAge < 0 || opts.MaxAge > maxInviteAge {
+		return nil, fmt.Errorf("max age must be between 0 and %d seconds", maxInviteAge)
+	}
+
+	perm := PermCreateInvite
+	code := strings.ToLower(strings.TrimSpace(opts.Code))
+	vanity := code != ""
+	if vanity {
+		if !vanityCodePattern.MatchString(code) {
+			return nil, fmt.Errorf("vanity codes must be 3-32 lowercase letters, digits or dashes")
+		}
+		perm |= PermManageServer
+	}
+	if err := s.requirePermission(ctx, serverID, userID, perm); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	invites, err := s.repo.ListInvites(ctx, serverID, now)
+	if err != nil {
+		return nil, err
+	}
+	if len(invites) >= maxInvitesPerServer {
+		return nil, fmt.Errorf("a server can have at most %d active invites", maxInvitesPerServer)
+	}
+
+	if vanity {
+		existing, err := s.repo.GetInvite(ctx, code)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return nil, ErrInviteCodeTaken
+		}
+	} else if code, err = GenerateInviteCode(); err != nil {
+		return nil, fmt.Errorf("failed to generate invite: %w", err)
+	}
+
+	inv := &Invite{
+		Code:      code,
+		ServerID:  serverID,
+		CreatorID: userID,
+		MaxUses:   opts.MaxUses,
+		Vanity:    vanity,
+	}
+	if opts.MaxAge > 0 {
+		expiresAt := now.Add(time.Duration(opts.MaxAge) * time.Second).UTC()
+		inv.ExpiresAt = &expiresAt
+	}
+	if err := s.repo.CreateInvite(ctx, inv); err != nil {
+		return nil, err
+	}
+
+	details := map[string]any{}
+	if opts.MaxUses > 0 {
+		details["max_uses"] = opts.MaxUses
+	}
+	if opts.MaxAge > 0 {
+		details["max_age"] = opts.MaxAge
+	}
+	if vanity {
+		details["vanity"] = true
+	}
+	s.audit(ctx, serverID, userID, AuditInviteCreate, "invite", code, details)
+	return s.repo.GetInvite(ctx, code)
+}
+
+// GenerateInvite creates an invite to a server that never expires and can be used
+// any number of times, returning its code. Requires PermCreateInvite.
+func (s *Service) GenerateInvite(ctx context.Context, serverID, userID string) (string, error) {
+	inv, err := s.CreateInvite(ctx, serverID, userID, InviteOptions{})
+	if err != nil {
+		return "", err
+	}
+	return inv.Code, nil
+}
+
+// ListInvites returns the invites of a server that can still be used, newest
+// first. Requires PermManageMembers.
+func (s *Service) ListInvites(ctx context.Context, serverID, userID string) ([]*Invite, error) {
+	if err := s.requirePermission(ctx, serverID, userID, PermManageMembers); err != nil {
+		return nil, err
+	}
+	return s.repo.ListInvites(ctx, serverID, time.Now())
+}
+
+// RevokeInvite deletes an invite of a server. Members may revoke the invites they
+// created; revoking others' invites requires PermManageMembers.
+func (s *Service) RevokeInvite(ctx context.Context, serverID, userID, code string) error {
+	actor, err := s.requireStanding(ctx, serverID, userID, 0)
+	if err != nil {
+		return err
+	}
+	inv, err := s.repo.GetInvite(ctx, strings.ToLower(code))
+	if err != nil {
+		return err
+	}
+	if inv == nil || inv.ServerID != serverID {
+		return ErrInviteNotFound
+	}
+	if inv.CreatorID != userID && !actor.perms.Has(PermManageMembers) {
+		return fmt.Errorf("insufficient permissions")
+	}
+
+	if err := s.repo.DeleteInvite(ctx, inv.Code); err != nil {
+		return err
+	}
+
+	// The server's invite code is cleared when its initial invite is revoked
+	s.cache.Delete("server:" + serverID)
+	s.cache.DeletePrefix("servers:user:")
+
+	s.audit(ctx, serverID, userID, AuditInviteDelete, "invite", inv.Code, map[string]any{
+		"creator_id": inv.CreatorID,
+		"uses":       inv.Uses,
+	})
+	return nil
+}
+
+// usableInvite loads an invite that can still be redeemed and its server.
+func (s *Service) usableInvite(ctx context.Context, code string) (*Invite, *Server, error) {
+	inv, err := s.repo.GetInvite(ctx, strings.ToLower(strings.TrimSpace(code)))
+	if err != nil {
+		return nil, nil, err
+	}
+	if inv == nil || !inv.usable(time.Now()) {
+		return nil, nil, ErrInvalidInvite
+	}
+	srv, err := s.GetServer(ctx, inv.ServerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if srv == nil {
+		return nil, nil, ErrInvalidInvite
+	}
+	return inv, srv, nil
+}
+
+// joinViaInvite adds userID to the server of an invite, counting a use of it and
+// assigning roleID if it is not empty. Fails with ErrBanned for banned users, and
+// with ErrInvalidInvite when the invite ran out in the meantime.
+func (s *Service) joinViaInvite(ctx context.Context, inv *Invite, userID, roleID string) error {
+	ok, err := s.repo.UseInvite(ctx, inv.Code, inv.ServerID, userID, roleID, time.Now())
+	if errors.Is(err, ErrBanned) {
+		return ErrBanned
+	}
+	if err != nil {
+		return fmt.Errorf("failed to join server: %w", err)
+	}
+	if !ok {
+		return ErrInvalidInvite
+	}
+	return nil
+}
+
+// RedeemInvite adds a user to a server via invite code. Users who are already
+// members do not use up the invite.
+func (s *Service) RedeemInvite(ctx context.Context, code, userID string) (*Server, error) {
+	inv, srv, err := s.usableInvite(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if already a member
+	existing, err := s.repo.GetMember(ctx, srv.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return srv, nil // Already a member, return server
+	}
+
+	if err := s.joinViaInvite(ctx, inv, userID, ""); err != nil {
+		return nil, err
+	}
+
+	// Invalidate caches for member list and user server list
+	s.cache.Delete("members:server:" + srv.ID)
+	s.cache.DeletePrefix("servers:user:" + userID)
+
+	s.logger.Info().
+		Str("server_id", srv.ID).
+		Str("user_id", userID).
+		Str("code", inv.Code).
+		Msg("user joined server via invite")
+
+	if s.events != nil {
+		s.events.MemberJoined(ctx, srv.ID, userID)
+	}
+	return srv, nil
+}
+
+// AddBotViaInvite adds a bot to the server of an invite code with an explicit legacy
+// role. The caller (the bot's owner) may grant RoleMember with just the invite;
+// RoleModerator and RoleAdmin assign the matching preset role, which requires
+// PermManageRoles in that server and must rank below the caller's highest role.
+// A bot that is already a member keeps its current roles.
+func (s *Service) AddBotViaInvite(ctx context.Context, code, actorID, botID, role string) (*Server, error) {
+	inv, srv, err := s.usableInvite(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	var roleID string
+	switch role {
+	case RoleMember:
+	case RoleModerator, RoleAdmin:
+		actor, err := s.requireStanding(ctx, srv.ID, actorID, PermManageRoles)
+		if err != nil {
+			return nil, err
+		}
+		if roleID, err = s.presetRole(ctx, srv.ID, role, actor); err != nil {
+			return nil, fmt.Errorf("cannot grant a bot your role or above: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("invalid role for a bot: %q", role)
+	}
+
+	existing, err := s.repo.GetMember(ctx, srv.ID, botID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return srv, nil
+	}
+	if err := s.joinViaInvite(ctx, inv, botID, roleID); err != nil {
+		return nil, err
+	}
+
+	s.cache.Delete("members:server:" + srv.ID)
+	s.cache.DeletePrefix("servers:user:" + botID)
+
+	s.logger.Info().
+		Str("server_id", srv.ID).
+		Str("bot_id", botID).
+		Str("added_by", actorID).
+		Str("role", role).
+		Msg("bot joined server via invite")
+
+	if s.events != nil {
+		s.events.MemberJoined(ctx, srv.ID, botID)
+	}
+	return srv, nil
+}
+
+// GetInviteInfo returns info about a server from an invite code that can still
+// be used.
+func (s *Service) GetInviteInfo(ctx context.Context, code string) (*InviteInfo, error) {
+	inv, srv, err := s.usableInvite(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.repo.CountMembers(ctx, srv.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &InviteInfo{
+		ServerID:    srv.ID,
+		ServerName:  srv.Name,
+		InviteCode:  inv.Code,
+		MemberCount: count,
+		ExpiresAt:   inv.ExpiresAt,
+	}, nil
+}
+
+// GenerateInviteCode generates a random 8-character invite code.
+// Complexity: O(1)
+func GenerateInviteCode() (string, error) {
+	b := make([]byte, 5) // 5 bytes = 8 base32 chars
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b)), nil
+}
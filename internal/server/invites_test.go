@@ -0,0 +1,120 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvites_UseLimitAndExpiry(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	srv, err := svc.CreateServer(ctx, "Fresh", "owner")
+	require.NoError(t, err)
+	once, err := svc.CreateInvite(ctx, srv.ID, "owner", InviteOptions{MaxUses: 1, MaxAge: 3600})
+	require.NoError(t, err)
+	assert.Equal(t, "owner", once.CreatorID)
+	assert.Equal(t, 1, once.MaxUses)
+	require.NotNil(t, once.ExpiresAt)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), *once.ExpiresAt, time.Minute)
+
+	info, err := svc.GetInviteInfo(ctx, once.Code)
+	require.NoError(t, err)
+	assert.Equal(t, "Fresh", info.ServerName)
+	assert.Equal(t, 1, info.MemberCount)
+
+	_, err = svc.RedeemInvite(ctx, once.Code, "owner")
+	require.NoError(t, err, "members do not use up invites")
+	_, err = svc.RedeemInvite(ctx, once.Code, "admin")
+	require.NoError(t, err)
+	_, err = svc.RedeemInvite(ctx, once.Code, "member")
+	assert.ErrorIs(t, err, ErrInvalidInvite)
+	_, err = svc.GetInviteInfo(ctx, once.Code)
+	assert.ErrorIs(t, err, ErrInvalidInvite)
+
+	invites, err := svc.ListInvites(ctx, srv.ID, "owner")
+	require.NoError(t, err)
+	require.Len(t, invites, 1, "used up invites are not listed")
+	assert.Equal(t, srv.InviteCode, invites[0].Code)
+
+	timed, err := svc.CreateInvite(ctx, srv.ID, "owner", InviteOptions{MaxAge: 60})
+	require.NoError(t, err)
+	later := time.Now().Add(2 * time.Minute)
+	invites, err = svc.repo.ListInvites(ctx, srv.ID, later)
+	require.NoError(t, err)
+	assert.Len(t, invites, 1, "expired invites are not listed")
+	ok, err := svc.repo.UseInvite(ctx, timed.Code, srv.ID, "member", "", later)
+	require.NoError(t, err)
+	assert.False(t, ok, "expired invites cannot be used")
+
+	_, err = svc.CreateInvite(ctx, srv.ID, "owner", InviteOptions{MaxUses: -1})
+	assert.Error(t, err)
+	_, err = svc.CreateInvite(ctx, srv.ID, "owner", InviteOptions{MaxAge: maxInviteAge + 1})
+	assert.Error(t, err)
+	_, err = svc.CreateInvite(ctx, srv.ID, "member", InviteOptions{})
+	assert.Error(t, err, "non-members cannot create invites")
+}
+
+func TestInvites_Vanity(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	_, err := svc.CreateInvite(ctx, "srv-1", "admin", InviteOptions{Code: "concord"})
+	assert.Error(t, err, "vanity codes require PermManageServer")
+	_, err = svc.CreateInvite(ctx, "srv-1", "owner", InviteOptions{Code: "no way"})
+	assert.Error(t, err)
+
+	inv, err := svc.CreateInvite(ctx, "srv-1", "owner", InviteOptions{Code: " Concord-HQ "})
+	require.NoError(t, err)
+	assert.Equal(t, "concord-hq", inv.Code)
+	assert.True(t, inv.Vanity)
+	assert.Nil(t, inv.ExpiresAt)
+
+	_, err = svc.CreateInvite(ctx, "srv-1", "owner", InviteOptions{Code: "concord-hq"})
+	assert.ErrorIs(t, err, ErrInviteCodeTaken)
+	_, err = svc.CreateInvite(ctx, "srv-1", "owner", InviteOptions{Code: "join-2"})
+	assert.ErrorIs(t, err, ErrInviteCodeTaken, "codes are unique across servers")
+
+	info, err := svc.GetInviteInfo(ctx, "CONCORD-HQ")
+	require.NoError(t, err)
+	assert.Equal(t, "srv-1", info.ServerID)
+}
+
+func TestInvites_Revoke(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	own, err := svc.CreateInvite(ctx, "srv-1", "member", InviteOptions{})
+	require.NoError(t, err)
+	other, err := svc.GenerateInvite(ctx, "srv-1", "admin")
+	require.NoError(t, err)
+
+	_, err = svc.ListInvites(ctx, "srv-1", "member")
+	assert.Error(t, err, "listing invites requires PermManageMembers")
+	invites, err := svc.ListInvites(ctx, "srv-1", "admin")
+	require.NoError(t, err)
+	assert.Len(t, invites, 3)
+
+	assert.Error(t, svc.RevokeInvite(ctx, "srv-1", "member", other))
+	require.NoError(t, svc.RevokeInvite(ctx, "srv-1", "member", own.Code))
+	require.NoError(t, svc.RevokeInvite(ctx, "srv-1", "admin", other))
+	assert.ErrorIs(t, svc.RevokeInvite(ctx, "srv-1", "admin", other), ErrInviteNotFound)
+	assert.ErrorIs(t, svc.RevokeInvite(ctx, "srv-1", "owner", "join-2"), ErrInviteNotFound)
+	_, err = svc.RedeemInvite(ctx, own.Code, "bot")
+	assert.ErrorIs(t, err, ErrInvalidInvite)
+
+	srv, err := svc.GetServer(ctx, "srv-1")
+	require.NoError(t, err)
+	assert.Equal(t, "join-1", srv.InviteCode)
+	require.NoError(t, svc.RevokeInvite(ctx, "srv-1", "admin", "join-1"))
+	srv, err = svc.GetServer(ctx, "srv-1")
+	require.NoError(t, err)
+	assert.Empty(t, srv.InviteCode, "revoking the initial invite clears the server's code")
+
+	assert.Equal(t, []string{AuditInviteDelete, AuditInviteDelete, AuditInviteDelete, AuditInviteCreate, AuditInviteCreate},
+		auditActions(t, svc, AuditQuery{TargetType: "invite"}))
+}
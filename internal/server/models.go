@@ -1,15 +1,41 @@
 package server
 
-// Role represents a member's permission level within a server.
-type Role string
+import (
+	"encoding/json"
+	"time"
+)
 
+// Legacy role names. Admin and moderator map to the preset roles every server
+// starts with; owner is the server's owner and member holds neither preset.
 const (
-	RoleOwner     Role = "owner"
-	RoleAdmin     Role = "admin"
-	RoleModerator Role = "moderator"
-	RoleMember    Role = "member"
+	RoleOwner     = "owner"
+	RoleAdmin     = "admin"
+	RoleModerator = "moderator"
+	RoleMember    = "member"
 )
 
+// Role is a server-defined role granting a set of permissions. Roles at higher
+// positions outrank lower ones; the default role is at position 0.
+type Role struct {
+	ID          string     `json:"id"`
+	ServerID    string     `json:"server_id"`
+	Name        string     `json:"name"`
+	Color       int        `json:"color"` // 0xRRGGBB, 0 for no color
+	Position    int        `json:"position"`
+	Permissions Permission `json:"permissions"`
+	// Default is set on the role every member holds implicitly; it cannot be
+	// assigned, renamed, moved or deleted.
+	Default   bool   `json:"default"`
+	CreatedAt string `json:"created_at"` // ISO 8601
+}
+
+// RoleUpdate changes a role; nil fields are left unchanged.
+type RoleUpdate struct {
+	Name        *string     `json:"name,omitempty"`
+	Color       *int        `json:"color,omitempty"`
+	Permissions *Permission `json:"permissions,omitempty"`
+}
+
 // Server represents a Concord server (guild).
 type Server struct {
 	ID         string `json:"id"`
@@ -27,23 +53,272 @@ type Channel struct {
 	Name      string `json:"name"`
 	Type      string `json:"type"` // "text" or "voice"
 	Position  int    `json:"position"`
+	SlowMode  int    `json:"slow_mode_seconds"` // Minimum seconds between messages per user (0 = off)
+	CreatedAt string `json:"created_at"`        // ISO 8601
+	// CategoryID is the category the channel is grouped under; empty when uncategorized.
+	CategoryID string `json:"category_id,omitempty"`
+}
+
+// Category is a named, collapsible group of channels within a server.
+type Category struct {
+	ID        string `json:"id"`
+	ServerID  string `json:"server_id"`
+	Name      string `json:"name"`
+	Position  int    `json:"position"`   // among the server's categories, starting at 0
 	CreatedAt string `json:"created_at"` // ISO 8601
 }
 
+// ChannelLayout is the complete order of a server's channels: the uncategorized
+// channels first, then each category with its channels, top to bottom.
+type ChannelLayout struct {
+	Uncategorized []string         `json:"uncategorized"` // channel IDs
+	Categories    []CategoryLayout `json:"categories"`
+}
+
+// CategoryLayout is one category of a ChannelLayout with its channels in order.
+type CategoryLayout struct {
+	ID       string   `json:"id"`
+	Channels []string `json:"channels"` // channel IDs
+}
+
+// ChannelUpdate changes a channel; nil fields are left unchanged.
+type ChannelUpdate struct {
+	Name       *string `json:"name,omitempty"`
+	Type       *string `json:"type,omitempty"`        // "text" or "voice"
+	CategoryID *string `json:"category_id,omitempty"` // "" moves the channel out of its category
+}
+
+// Overwrite target types.
+const (
+	OverwriteRole   = "role"
+	OverwriteMember = "member"
+)
+
+// Overwrite allows or denies permissions in one channel for a role or a member,
+// on top of their server-wide permissions. A channel whose default role overwrite
+// denies PermViewChannel is private.
+type Overwrite struct {
+	ChannelID  string     `json:"channel_id"`
+	TargetType string     `json:"target_type"` // OverwriteRole or OverwriteMember
+	TargetID   string     `json:"target_id"`
+	Allow      Permission `json:"allow"`
+	Deny       Permission `json:"deny"`
+}
+
 // Member represents a user's membership in a server.
 type Member struct {
 	ServerID string `json:"server_id"`
 	UserID   string `json:"user_id"`
 	Username string `json:"username"`
 	Avatar   string `json:"avatar_url"`
-	Role     Role   `json:"role"`
-	JoinedAt string `json:"joined_at"` // ISO 8601
+	// Role summarizes the member's rank with a legacy role name: RoleOwner,
+	// RoleAdmin or RoleModerator when holding that preset role, RoleMember otherwise.
+	Role     string   `json:"role"`
+	Roles    []string `json:"roles"`     // IDs of the assigned roles, excluding the default role
+	JoinedAt string   `json:"joined_at"` // ISO 8601
+	// TimeoutUntil is set while the member is timed out.
+	TimeoutUntil *time.Time `json:"timeout_until,omitempty"`
+}
+
+// OwnershipTransfer is a pending handover of a server from its owner to a member.
+// It takes effect once the member accepts it, before ExpiresAt.
+type OwnershipTransfer struct {
+	ServerID  string    `json:"server_id"`
+	FromID    string    `json:"from_id"`
+	ToID      string    `json:"to_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt string    `json:"created_at"` // ISO 8601
+}
+
+// Ban keeps a user from joining a server until it is lifted or expires.
+type Ban struct {
+	ServerID  string     `json:"server_id"`
+	UserID    string     `json:"user_id"`
+	Username  string     `json:"username"`
+	ActorID   string     `json:"actor_id"`
+	Reason    string     `json:"reason"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // nil for never
+	CreatedAt string     `json:"created_at"`           // ISO 8601
 }
 
 // InviteInfo is returned when inspecting an invite code.
 type InviteInfo struct {
-	ServerID   string `json:"server_id"`
-	ServerName string `json:"server_name"`
-	InviteCode string `json:"invite_code"`
-	MemberCount int   `json:"member_count"`
+	ServerID    string     `json:"server_id"`
+	ServerName  string     `json:"server_name"`
+	InviteCode  string     `json:"invite_code"`
+	MemberCount int        `json:"member_count"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
+
+// Invite is a code that lets users join a server. It stops working once it expires
+// or has been used MaxUses times.
+type Invite struct {
+	Code      string     `json:"code"`
+	ServerID  string     `json:"server_id"`
+	CreatorID string     `json:"creator_id"`
+	MaxUses   int        `json:"max_uses"` // 0 for unlimited
+	Uses      int        `json:"uses"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // nil for never
+	// Vanity is set on invites with a custom code instead of a generated one.
+	Vanity    bool   `json:"vanity"`
+	CreatedAt string `json:"created_at"` // ISO 8601
+}
+
+// usable reports whether the invite can still be redeemed at now.
+func (inv *Invite) usable(now time.Time) bool {
+	if inv.MaxUses > 0 && inv.Uses >= inv.MaxUses {
+		return false
+	}
+	return inv.ExpiresAt == nil || inv.ExpiresAt.After(now)
+}
+
+// InviteOptions configures a new invite. The zero value creates an invite that
+// never expires and can be used any number of times.
+type InviteOptions struct {
+	MaxUses int `json:"max_uses,omitempty"` // 0 for unlimited
+	MaxAge  int `json:"max_age,omitempty"`  // seconds until the invite expires, 0 for never
+	// Code is a custom vanity code to use instead of a generated one. Requires
+	// PermManageServer.
+	Code string `json:"code,omitempty"`
+}
+
+// Template is a shareable snapshot of a server's structure that new servers can be
+// created from. Anyone with the code can read it; syncing re-captures the source
+// server and increments Version.
+type Template struct {
+	Code        string           `json:"code"`
+	ServerID    string           `json:"server_id"` // the source server
+	CreatorID   string           `json:"creator_id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Version     int              `json:"version"` // 1 when created, incremented by each sync
+	Snapshot    TemplateSnapshot `json:"snapshot"`
+	// Dirty is set when the source server changed since the last sync. It is only
+	// computed when listing the templates of a server.
+	Dirty     bool   `json:"dirty"`
+	CreatedAt string `json:"created_at"` // ISO 8601
+	UpdatedAt string `json:"updated_at"` // ISO 8601, last sync
+}
+
+// TemplateSnapshot is the structure of a server captured by a template: its
+// settings, roles, categories and channels with their role overwrites. Messages,
+// members and member overwrites are left out. Channels refer to categories and
+// overwrites to roles by their index in the snapshot, so a snapshot can be written
+// by hand and shared as a JSON document.
+type TemplateSnapshot struct {
+	Format     int                `json:"format"` // version of the snapshot format
+	Name       string             `json:"name"`
+	IconURL    string             `json:"icon_url"`
+	Roles      []TemplateRole     `json:"roles"`      // highest first, the default role last
+	Categories []TemplateCategory `json:"categories"` // top to bottom
+	Channels   []TemplateChannel  `json:"channels"`   // in display order
+}
+
+// TemplateRole is a role of a TemplateSnapshot.
+type TemplateRole struct {
+	Name        string     `json:"name"`
+	Color       int        `json:"color"`
+	Permissions Permission `json:"permissions"`
+	// Preset is RoleAdmin or RoleModerator for the preset roles, which keep their
+	// legacy role name in servers created from the template.
+	Preset  string `json:"preset,omitempty"`
+	Default bool   `json:"default,omitempty"`
+}
+
+// TemplateCategory is a channel category of a TemplateSnapshot.
+type TemplateCategory struct {
+	Name string `json:"name"`
+}
+
+// TemplateChannel is a channel of a TemplateSnapshot.
+type TemplateChannel struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"` // "text" or "voice"
+	SlowMode int    `json:"slow_mode_seconds"`
+	// Category is the index of the channel's category, nil when uncategorized.
+	Category   *int                `json:"category,omitempty"`
+	Overwrites []TemplateOverwrite `json:"overwrites,omitempty"`
+}
+
+// TemplateOverwrite is a role overwrite of a TemplateChannel.
+type TemplateOverwrite struct {
+	Role  int        `json:"role"` // index of the role
+	Allow Permission `json:"allow"`
+	Deny  Permission `json:"deny"`
+}
+
+// Webhook is an incoming webhook that posts into one channel of a server.
+// Token is only set when the webhook is created or its token regenerated;
+// only a hash of it is stored.
+type Webhook struct {
+	ID        string `json:"id"`
+	ServerID  string `json:"server_id"`
+	ChannelID string `json:"channel_id"`
+	Name      string `json:"name"`
+	AvatarURL string `json:"avatar_url"`
+	CreatedBy string `json:"created_by"`
+	CreatedAt string `json:"created_at"` // ISO 8601
+	Token     string `json:"token,omitempty"`
+	TokenHash string `json:"-"`
+}
+
+// Audit log actions.
+const (
+	AuditServerUpdate     = "server.update"
+	AuditServerTransfer   = "server.transfer"
+	AuditChannelCreate    = "channel.create"
+	AuditChannelUpdate    = "channel.update"
+	AuditChannelDelete    = "channel.delete"
+	AuditChannelReorder   = "channel.reorder"
+	AuditOverwriteUpdate  = "channel.overwrite_update"
+	AuditOverwriteDelete  = "channel.overwrite_delete"
+	AuditCategoryCreate   = "category.create"
+	AuditCategoryUpdate   = "category.update"
+	AuditCategoryDelete   = "category.delete"
+	AuditWebhookCreate    = "webhook.create"
+	AuditWebhookUpdate    = "webhook.update"
+	AuditWebhookToken     = "webhook.token_reset"
+	AuditWebhookDelete    = "webhook.delete"
+	AuditInviteCreate     = "invite.create"
+	AuditInviteDelete     = "invite.delete"
+	AuditMemberKick       = "member.kick"
+	AuditMemberBan        = "member.ban"
+	AuditMemberUnban      = "member.unban"
+	AuditMemberTimeout    = "member.timeout"
+	AuditMemberUntimeout  = "member.timeout_remove"
+	AuditMemberRoleUpdate = "member.role_update"
+	AuditMemberRoleAdd    = "member.role_add"
+	AuditMemberRoleRemove = "member.role_remove"
+	AuditRoleCreate       = "role.create"
+	AuditRoleUpdate       = "role.update"
+	AuditRoleDelete       = "role.delete"
+	AuditRoleReorder      = "role.reorder"
+	AuditTemplateCreate   = "template.create"
+	AuditTemplateSync     = "template.sync"
+	AuditTemplateDelete   = "template.delete"
+	AuditMessageDelete    = "message.delete"
+)
+
+// AuditEntry records an administrative action taken in a server.
+type AuditEntry struct {
+	ID            int64           `json:"id"`
+	ServerID      string          `json:"server_id"`
+	ActorID       string          `json:"actor_id"`
+	ActorUsername string          `json:"actor_username"`
+	Action        string          `json:"action"`
+	TargetType    string          `json:"target_type"` // server, channel, category, webhook, invite, member, role, template or message
+	TargetID      string          `json:"target_id"`
+	Details       json.RawMessage `json:"details,omitempty"`
+	CreatedAt     string          `json:"created_at"` // ISO 8601
+}
+
+// AuditQuery filters and paginates the audit log of a server, newest first.
+// Empty fields match every entry.
+type AuditQuery struct {
+	ActorID    string `json:"actor_id,omitempty"`
+	Action     string `json:"action,omitempty"`
+	TargetType string `json:"target_type,omitempty"`
+	TargetID   string `json:"target_id,omitempty"`
+	Before     int64  `json:"before,omitempty"` // only entries with a lower ID
+	Limit      int    `json:"limit,omitempty"`  // 1-100, default 50
 }
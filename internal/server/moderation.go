@@ -0,0 +1,198 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	maxBanReason      = 512
+	maxBanSeconds     = 365 * 24 * 60 * 60 // 1 year
+	maxTimeoutSeconds = 28 * 24 * 60 * 60  // 28 days
+)
+
+var (
+	// ErrBanned is returned when a banned user tries to join a server.
+	ErrBanned = errors.New("banned from this server")
+	// ErrBanNotFound is returned when lifting a ban that does not exist or has expired.
+	ErrBanNotFound = errors.New("ban not found")
+)
+
+// BanMember bans targetID from a server, removing them if they are a member, for
+// the given number of seconds or permanently when seconds is 0. Banning a banned
+// user replaces their ban. Users who are not members can be banned in advance.
+// Requires PermManageMembers and outranking the target.
+func (s *Service) BanMember(ctx context.Context, serverID, actorID, targetID, reason string, seconds int) (*Ban, error) {
+	reason = strings.TrimSpace(reason)
+	if len(reason) > maxBanReason {
+		return nil, fmt.Errorf("ban reason cannot exceed %d characters", maxBanReason)
+	}
+	if seconds < 0 || seconds > maxBanSeconds {
+		return nil, fmt.Errorf("ban duration must be between 0 and %d seconds", maxBanSeconds)
+	}
+	if actorID == targetID {
+		return nil, fmt.Errorf("cannot ban yourself")
+	}
+
+	actor, err := s.requireStanding(ctx, serverID, actorID, PermManageMembers)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.standing(ctx, serverID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if target != nil && !actor.outranks(target.rank) {
+		return nil, fmt.Errorf("cannot ban a member with equal or higher role")
+	}
+
+	now := time.Now()
+	ban := &Ban{ServerID: serverID, UserID: targetID, ActorID: actorID, Reason: reason}
+	if seconds > 0 {
+		expiresAt := now.Add(time.Duration(seconds) * time.Second).UTC()
+		ban.ExpiresAt = &expiresAt
+	}
+	if err := s.repo.BanMember(ctx, ban); err != nil {
+		return nil, err
+	}
+
+	s.cache.Delete("members:server:" + serverID)
+	s.cache.Delete("overwrites:server:" + serverID)
+	s.cache.DeletePrefix("servers:user:" + targetID)
+	s.disconnectVoice(serverID, targetID)
+
+	details := map[string]any{}
+	if reason != "" {
+		details["reason"] = reason
+	}
+	if seconds > 0 {
+		details["duration"] = seconds
+	}
+	s.audit(ctx, serverID, actorID, AuditMemberBan, "member", targetID, details)
+	return s.repo.GetBan(ctx, serverID, targetID, now)
+}
+
+// UnbanMember lifts the ban of targetID from a server. Requires PermManageMembers.
+func (s *Service) UnbanMember(ctx context.Context, serverID, actorID, targetID string) error {
+	if err := s.requirePermission(ctx, serverID, actorID, PermManageMembers); err != nil {
+		return err
+	}
+	ban, err := s.repo.GetBan(ctx, serverID, targetID, time.Now())
+	if err != nil {
+		return err
+	}
+	if ban == nil {
+		return ErrBanNotFound
+	}
+	if err := s.repo.DeleteBan(ctx, serverID, targetID); err != nil {
+		return err
+	}
+	s.audit(ctx, serverID, actorID, AuditMemberUnban, "member", targetID, nil)
+	return nil
+}
+
+// ListBans returns the bans of a server still in effect, newest first.
+// Requires PermManageMembers.
+func (s *Service) ListBans(ctx context.Context, serverID, userID string) ([]*Ban, error) {
+	if err := s.requirePermission(ctx, serverID, userID, PermManageMembers); err != nil {
+		return nil, err
+	}
+	return s.repo.ListBans(ctx, serverID, time.Now())
+}
+
+// TimeoutMember withholds TimeoutPermissions from a member for the given number of
+// seconds, replacing any running timeout, and disconnects them from voice.
+// Administrators cannot be timed out. Requires PermManageMembers and outranking
+// the target.
+func (s *Service) TimeoutMember(ctx context.Context, serverID, actorID, targetID string, seconds int) (*Member, error) {
+	if seconds < 1 || seconds > maxTimeoutSeconds {
+		return nil, fmt.Errorf("timeout duration must be between 1 and %d seconds", maxTimeoutSeconds)
+	}
+	target, err := s.moderatedMember(ctx, serverID, actorID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if target.perms == AllPermissions {
+		return nil, fmt.Errorf("cannot time out an administrator")
+	}
+
+	until := time.Now().Add(time.Duration(seconds) * time.Second).UTC()
+	if err := s.repo.SetMemberTimeout(ctx, serverID, targetID, &until); err != nil {
+		return nil, err
+	}
+	s.cache.Delete("members:server:" + serverID)
+	s.disconnectVoice(serverID, targetID)
+	s.audit(ctx, serverID, actorID, AuditMemberTimeout, "member", targetID, map[string]any{
+		"duration": seconds,
+	})
+	return s.repo.GetMember(ctx, serverID, targetID)
+}
+
+// RemoveTimeout ends the timeout of a member early. Requires PermManageMembers and
+// outranking the target.
+func (s *Service) RemoveTimeout(ctx context.Context, serverID, actorID, targetID string) error {
+	target, err := s.moderatedMember(ctx, serverID, actorID, targetID)
+	if err != nil {
+		return err
+	}
+	if target.member.TimeoutUntil == nil {
+		return fmt.Errorf("member is not timed out")
+	}
+	if err := s.repo.SetMemberTimeout(ctx, serverID, targetID, nil); err != nil {
+		return err
+	}
+	s.cache.Delete("members:server:" + serverID)
+	s.audit(ctx, serverID, actorID, AuditMemberUntimeout, "member", targetID, nil)
+	return nil
+}
+
+// ListTimeouts returns the members of a server that are currently timed out.
+// Requires PermManageMembers.
+func (s *Service) ListTimeouts(ctx context.Context, serverID, userID string) ([]*Member, error) {
+	if err := s.requirePermission(ctx, serverID, userID, PermManageMembers); err != nil {
+		return nil, err
+	}
+	members, err := s.ListMembers(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	var timedOut []*Member
+	for _, m := range members {
+		if m.TimeoutUntil != nil && m.TimeoutUntil.After(now) {
+			timedOut = append(timedOut, m)
+		}
+	}
+	return timedOut, nil
+}
+
+// moderatedMember returns the standing of targetID, a member of serverID ranked
+// below actorID, who must hold PermManageMembers.
+func (s *Service) moderatedMember(ctx context.Context, serverID, actorID, targetID string) (*standing, error) {
+	actor, err := s.requireStanding(ctx, serverID, actorID, PermManageMembers)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.standing(ctx, serverID, targetID)
+	if err != nil || target == nil {
+		return nil, fmt.Errorf("target member not found")
+	}
+	if !actor.outranks(target.rank) {
+		return nil, fmt.Errorf("cannot moderate a member with equal or higher role")
+	}
+	return target, nil
+}
+
+// disconnectVoice drops the voice connections of userID in serverID, if a voice
+// server is registered.
+func (s *Service) disconnectVoice(serverID, userID string) {
+	if s.voice == nil {
+		return
+	}
+	if n := s.voice.DisconnectUser(serverID, userID); n > 0 {
+		s.logger.Info().Str("server_id", serverID).Str("user_id", userID).Int("connections", n).Msg("disconnected from voice")
+	}
+}
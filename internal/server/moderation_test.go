@@ -0,0 +1,140 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// voiceSessions records the users disconnected from voice.
+type voiceSessions struct {
+	disconnected []string
+}
+
+func (v *voiceSessions) DisconnectUser(serverID, userID string) int {
+	v.disconnected = append(v.disconnected, serverID+"/"+userID)
+	return 1
+}
+
+func TestBans(t *testing.T) {
+	svc := setupService(t)
+	voice := &voiceSessions{}
+	svc.SetVoiceSessions(voice)
+	ctx := context.Background()
+
+	_, err := svc.BanMember(ctx, "srv-1", "member", "admin", "", 0)
+	assert.Error(t, err, "banning requires PermManageMembers")
+	_, err = svc.BanMember(ctx, "srv-1", "admin", "owner", "", 0)
+	assert.Error(t, err, "cannot ban a higher ranked member")
+	_, err = svc.BanMember(ctx, "srv-1", "admin", "admin", "", 0)
+	assert.Error(t, err)
+	_, err = svc.BanMember(ctx, "srv-1", "admin", "member", "", -1)
+	assert.Error(t, err)
+
+	ban, err := svc.BanMember(ctx, "srv-1", "admin", "member", " spam ", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "spam", ban.Reason)
+	assert.Equal(t, "member", ban.Username)
+	assert.Equal(t, "admin", ban.ActorID)
+	assert.Nil(t, ban.ExpiresAt)
+	assert.Equal(t, []string{"srv-1/member"}, voice.disconnected)
+
+	isMember, err := svc.IsMember(ctx, "srv-1", "member")
+	require.NoError(t, err)
+	assert.False(t, isMember)
+	_, err = svc.RedeemInvite(ctx, "join-1", "member")
+	assert.ErrorIs(t, err, ErrBanned)
+
+	// Users can be banned before they join
+	_, err = svc.BanMember(ctx, "srv-1", "admin", "bot", "", 3600)
+	require.NoError(t, err)
+	_, err = svc.AddBotViaInvite(ctx, "join-1", "member", "bot", RoleMember)
+	assert.ErrorIs(t, err, ErrBanned)
+
+	// The ban is checked inside the join transaction, so a refused join uses nothing
+	inv, err := svc.CreateInvite(ctx, "srv-1", "owner", InviteOptions{MaxUses: 1})
+	require.NoError(t, err)
+	_, err = svc.repo.UseInvite(ctx, inv.Code, "srv-1", "bot", "", time.Now())
+	assert.ErrorIs(t, err, ErrBanned)
+	joined, err := svc.repo.UseInvite(ctx, inv.Code, "srv-1", "bot", "", time.Now().Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.True(t, joined, "the invite's only use is left once the ban expires")
+
+	bans, err := svc.ListBans(ctx, "srv-1", "admin")
+	require.NoError(t, err)
+	assert.Len(t, bans, 2)
+	later := time.Now().Add(2 * time.Hour)
+	bans, err = svc.repo.ListBans(ctx, "srv-1", later)
+	require.NoError(t, err)
+	require.Len(t, bans, 1, "expired bans are not listed")
+	assert.Equal(t, "member", bans[0].UserID)
+	expired, err := svc.repo.GetBan(ctx, "srv-1", "bot", later)
+	require.NoError(t, err)
+	assert.Nil(t, expired)
+
+	require.NoError(t, svc.UnbanMember(ctx, "srv-1", "admin", "member"))
+	assert.ErrorIs(t, svc.UnbanMember(ctx, "srv-1", "admin", "member"), ErrBanNotFound)
+	_, err = svc.RedeemInvite(ctx, "join-1", "member")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{AuditMemberUnban, AuditMemberBan, AuditMemberBan},
+		auditActions(t, svc, AuditQuery{ActorID: "admin"}))
+}
+
+func TestTimeouts(t *testing.T) {
+	svc := setupService(t)
+	voice := &voiceSessions{}
+	svc.SetVoiceSessions(voice)
+	ctx := context.Background()
+
+	_, err := svc.SetChannelOverwrite(ctx, "srv-1", "owner", Overwrite{
+		ChannelID: "ch-1", TargetType: OverwriteMember, TargetID: "member", Allow: PermSendMessages,
+	})
+	require.NoError(t, err)
+
+	_, err = svc.TimeoutMember(ctx, "srv-1", "admin", "member", 0)
+	assert.Error(t, err)
+	_, err = svc.TimeoutMember(ctx, "srv-1", "admin", "member", maxTimeoutSeconds+1)
+	assert.Error(t, err)
+	_, err = svc.TimeoutMember(ctx, "srv-1", "admin", "owner", 60)
+	assert.Error(t, err, "cannot time out a higher ranked member")
+	_, err = svc.TimeoutMember(ctx, "srv-1", "member", "admin", 60)
+	assert.Error(t, err, "timeouts require PermManageMembers")
+
+	m, err := svc.TimeoutMember(ctx, "srv-1", "admin", "member", 600)
+	require.NoError(t, err)
+	require.NotNil(t, m.TimeoutUntil)
+	assert.WithinDuration(t, time.Now().Add(10*time.Minute), *m.TimeoutUntil, time.Minute)
+	assert.Equal(t, []string{"srv-1/member"}, voice.disconnected)
+
+	ok, err := svc.CanSendMessages(ctx, "ch-1", "member")
+	require.NoError(t, err)
+	assert.False(t, ok, "overwrites do not lift timeouts")
+	assert.Error(t, svc.CheckPermission(ctx, "srv-1", "member", PermSendMessages))
+	connect, speak, err := svc.VoiceAccess(ctx, "srv-1", "vc-1", "member")
+	require.NoError(t, err)
+	assert.True(t, connect)
+	assert.False(t, speak)
+
+	timedOut, err := svc.ListTimeouts(ctx, "srv-1", "admin")
+	require.NoError(t, err)
+	require.Len(t, timedOut, 1)
+	assert.Equal(t, "member", timedOut[0].UserID)
+	_, err = svc.ListTimeouts(ctx, "srv-1", "member")
+	assert.Error(t, err)
+
+	require.NoError(t, svc.RemoveTimeout(ctx, "srv-1", "admin", "member"))
+	assert.Error(t, svc.RemoveTimeout(ctx, "srv-1", "admin", "member"), "not timed out")
+	ok, err = svc.CanSendMessages(ctx, "ch-1", "member")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	timedOut, err = svc.ListTimeouts(ctx, "srv-1", "admin")
+	require.NoError(t, err)
+	assert.Empty(t, timedOut)
+
+	assert.Equal(t, []string{AuditMemberUntimeout, AuditMemberTimeout},
+		auditActions(t, svc, AuditQuery{ActorID: "admin"}))
+}
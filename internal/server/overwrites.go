@@ -0,0 +1,281 @@
+package server
+
+import (
+	"context"
+	"fmt"
+)
+
+// ChannelOverwrites returns the permission overwrites of a channel.
+// Requires PermManageChannels.
+func (s *Service) ChannelOverwrites(ctx context.Context, serverID, userID, channelID string) ([]*Overwrite, error) {
+	if _, err := s.managedChannel(ctx, serverID, userID, channelID); err != nil {
+		return nil, err
+	}
+	overwrites, err := s.serverOverwrites(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+	return overwrites[channelID], nil
+}
+
+// SetChannelOverwrite allows and denies permissions in a channel for a role or a
+// member, replacing any previous overwrite for that target. Only
+// OverwritablePermissions can be set, and only those the caller holds in the
+// channel. Requires PermManageChannels.
+func (s *Service) SetChannelOverwrite(ctx context.Context, serverID, userID string, o Overwrite) (*Overwrite, error) {
+	actor, err := s.managedChannel(ctx, serverID, userID, o.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validateOverwriteTarget(ctx, serverID, o.TargetType, o.TargetID); err != nil {
+		return nil, err
+	}
+	if (o.Allow|o.Deny)&^OverwritablePermissions != 0 {
+		return nil, fmt.Errorf("overwrites can only allow or deny view, send, connect and speak")
+	}
+	if o.Allow&o.Deny != 0 {
+		return nil, fmt.Errorf("a permission cannot be both allowed and denied")
+	}
+	if (o.Allow|o.Deny)&^actor != 0 {
+		return nil, fmt.Errorf("cannot overwrite permissions you do not have")
+	}
+
+	if err := s.repo.SetOverwrite(ctx, &o); err != nil {
+		return nil, err
+	}
+	s.cache.Delete("overwrites:server:" + serverID)
+	s.audit(ctx, serverID, userID, AuditOverwriteUpdate, "channel", o.ChannelID, map[string]any{
+		"target_type": o.TargetType,
+		"target_id":   o.TargetID,
+		"allow":       o.Allow,
+		"deny":        o.Deny,
+	})
+	return &o, nil
+}
+
+// DeleteChannelOverwrite removes the overwrite of a channel for a role or a member.
+// Requires PermManageChannels.
+func (s *Service) DeleteChannelOverwrite(ctx context.Context, serverID, userID, channelID, targetType, targetID string) error {
+	if _, err := s.managedChannel(ctx, serverID, userID, channelID); err != nil {
+		return err
+	}
+	if targetType != OverwriteRole && targetType != OverwriteMember {
+		return fmt.Errorf("overwrite target must be 'role' or 'member'")
+	}
+	if err := s.repo.DeleteOverwrite(ctx, channelID, targetType, targetID); err != nil {
+		return err
+	}
+	s.cache.Delete("overwrites:server:" + serverID)
+	s.audit(ctx, serverID, userID, AuditOverwriteDelete, "channel", channelID, map[string]any{
+		"target_type": targetType,
+		"target_id":   targetID,
+	})
+	return nil
+}
+
+// ChannelPermissions returns the effective permissions of userID in a channel:
+// the member's server permissions with the channel's overwrites applied.
+// Returns 0 for unknown channels and non-members.
+func (s *Service) ChannelPermissions(ctx context.Context, channelID, userID string) (Permission, error) {
+	ch, err := s.getChannel(ctx, channelID)
+	if err != nil || ch == nil {
+		return 0, err
+	}
+	st, err := s.standing(ctx, ch.ServerID, userID)
+	if err != nil || st == nil {
+		return 0, err
+	}
+	overwrites, err := s.serverOverwrites(ctx, ch.ServerID)
+	if err != nil {
+		return 0, err
+	}
+	return st.inChannel(ch.ServerID, overwrites[ch.ID]), nil
+}
+
+// CheckChannelPermission returns an error unless userID can see channelID and holds
+// perm in it. Channels the user cannot see fail with ErrChannelNotFound.
+func (s *Service) CheckChannelPermission(ctx context.Context, channelID, userID string, perm Permission) error {
+	ch, err := s.getChannel(ctx, channelID)
+	if err != nil {
+		return err
+	}
+	if ch == nil {
+		return ErrChannelNotFound
+	}
+	_, err = s.requireChannelPermission(ctx, ch, userID, perm)
+	return err
+}
+
+// VisibleChannels returns the channels of a server userID can see, in display order.
+func (s *Service) VisibleChannels(ctx context.Context, serverID, userID string) ([]*Channel, error) {
+	st, err := s.standing(ctx, serverID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if st == nil {
+		return nil, fmt.Errorf("not a member of this server")
+	}
+	return s.visibleChannels(ctx, serverID, st)
+}
+
+// VoiceAccess reports whether userID may join the voice channel channelID of
+// serverID, and whether they may speak in it.
+// Implements signaling.JoinPolicy.
+func (s *Service) VoiceAccess(ctx context.Context, serverID, channelID, userID string) (bool, bool, error) {
+	ch, err := s.getChannel(ctx, channelID)
+	if err != nil {
+		return false, false, err
+	}
+	if ch == nil || ch.ServerID != serverID || ch.Type != "voice" {
+		return false, false, nil
+	}
+	perms, err := s.ChannelPermissions(ctx, channelID, userID)
+	if err != nil {
+		return false, false, err
+	}
+	return perms.Has(PermViewChannel | PermConnect), perms.Has(PermSpeak), nil
+}
+
+// visibleChannels filters the channels of a server down to those st can see.
+func (s *Service) visibleChannels(ctx context.Context, serverID string, st *standing) ([]*Channel, error) {
+	channels, err := s.ListChannels(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+	overwrites, err := s.serverOverwrites(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+	visible := make([]*Channel, 0, len(channels))
+	for _, ch := range channels {
+		if st.inChannel(serverID, overwrites[ch.ID]).Has(PermViewChannel) {
+			visible = append(visible, ch)
+		}
+	}
+	return visible, nil
+}
+
+// requireChannelPermission returns the effective permissions of a member of the
+// channel's server who can see the channel and holds perm in it.
+func (s *Service) requireChannelPermission(ctx context.Context, ch *Channel, userID string, perm Permission) (Permission, error) {
+	st, err := s.standing(ctx, ch.ServerID, userID)
+	if err != nil {
+		return 0, err
+	}
+	if st == nil {
+		return 0, fmt.Errorf("not a member of this server")
+	}
+	overwrites, err := s.serverOverwrites(ctx, ch.ServerID)
+	if err != nil {
+		return 0, err
+	}
+	perms := st.inChannel(ch.ServerID, overwrites[ch.ID])
+	if !perms.Has(PermViewChannel) {
+		return 0, ErrChannelNotFound
+	}
+	if !perms.Has(perm) {
+		return 0, fmt.Errorf("insufficient permissions")
+	}
+	return perms, nil
+}
+
+// managedChannel checks that a channel belongs to serverID and that userID holds
+// PermManageChannels in it, returning the caller's channel permissions.
+func (s *Service) managedChannel(ctx context.Context, serverID, userID, channelID string) (Permission, error) {
+	ch, err := s.getChannel(ctx, channelID)
+	if err != nil {
+		return 0, err
+	}
+	if ch == nil || ch.ServerID != serverID {
+		return 0, ErrChannelNotFound
+	}
+	return s.requireChannelPermission(ctx, ch, userID, PermManageChannels)
+}
+
+// validateOverwriteTarget checks that an overwrite targets a role or a member of serverID.
+func (s *Service) validateOverwriteTarget(ctx context.Context, serverID, targetType, targetID string) error {
+	switch targetType {
+	case OverwriteRole:
+		role, err := s.repo.GetRole(ctx, targetID)
+		if err != nil {
+			return err
+		}
+		if role == nil || role.ServerID != serverID {
+			return ErrRoleNotFound
+		}
+	case OverwriteMember:
+		member, err := s.repo.GetMember(ctx, serverID, targetID)
+		if err != nil {
+			return err
+		}
+		if member == nil {
+			return fmt.Errorf("target member not found")
+		}
+	default:
+		return fmt.Errorf("overwrite target must be 'role' or 'member'")
+	}
+	return nil
+}
+
+// serverOverwrites returns the channel overwrites of a server keyed by channel ID.
+func (s *Service) serverOverwrites(ctx context.Context, serverID string) (map[string][]*Overwrite, error) {
+	cacheKey := "overwrites:server:" + serverID
+	if val, ok := s.cache.Get(cacheKey); ok {
+		return val.(map[string][]*Overwrite), nil
+	}
+	list, err := s.repo.ListServerOverwrites(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+	overwrites := make(map[string][]*Overwrite)
+	for _, o := range list {
+		overwrites[o.ChannelID] = append(overwrites[o.ChannelID], o)
+	}
+	s.cache.Set(cacheKey, overwrites, cacheTTL)
+	return overwrites, nil
+}
+
+// inChannel applies the overwrites of a channel to the member's server permissions:
+// the default role's overwrite first, then the union of the overwrites of the
+// member's roles, then the member's own. Owners and administrators are not
+// affected, and members who cannot view the channel lose every channel permission.
+// Complexity: O(o + r) where o = overwrites of the channel, r = roles of the member
+func (st *standing) inChannel(serverID string, overwrites []*Overwrite) Permission {
+	perms := st.perms
+	if perms == AllPermissions {
+		return perms
+	}
+
+	held := make(map[string]bool, len(st.member.Roles))
+	for _, id := range st.member.Roles {
+		held[id] = true
+	}
+	var everyone, member *Overwrite
+	var allow, deny Permission
+	for _, o := range overwrites {
+		switch {
+		case o.TargetType == OverwriteRole && o.TargetID == DefaultRoleID(serverID):
+			everyone = o
+		case o.TargetType == OverwriteRole && held[o.TargetID]:
+			allow |= o.Allow
+			deny |= o.Deny
+		case o.TargetType == OverwriteMember && o.TargetID == st.member.UserID:
+			member = o
+		}
+	}
+
+	if everyone != nil {
+		perms = perms&^everyone.Deny | everyone.Allow
+	}
+	perms = perms&^deny | allow
+	if member != nil {
+		perms = perms&^member.Deny | member.Allow
+	}
+	if !perms.Has(PermViewChannel) {
+		perms &^= OverwritablePermissions
+	}
+	if st.member.TimeoutUntil != nil {
+		perms &^= TimeoutPermissions
+	}
+	return perms
+}
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// visibleNames returns the names of the channels of srv-1 userID can see.
+func visibleNames(t *testing.T, svc *Service, userID string) []string {
+	t.Helper()
+	channels, err := svc.VisibleChannels(context.Background(), "srv-1", userID)
+	require.NoError(t, err)
+	var names []string
+	for _, ch := range channels {
+		names = append(names, ch.Name)
+	}
+	return names
+}
+
+func TestChannelOverwrites_PrivateChannel(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+	everyone := DefaultRoleID("srv-1")
+
+	_, err := svc.SetChannelOverwrite(ctx, "srv-1", "member", Overwrite{ChannelID: "ch-2", TargetType: OverwriteRole, TargetID: everyone, Deny: PermViewChannel})
+	assert.Error(t, err, "members cannot manage channels")
+	_, err = svc.SetChannelOverwrite(ctx, "srv-1", "admin", Overwrite{ChannelID: "ch-2", TargetType: OverwriteRole, TargetID: everyone, Deny: PermViewChannel})
+	require.NoError(t, err)
+
+	// A private channel is hidden from listing, search and history
+	assert.Equal(t, []string{"ci", "Voice"}, visibleNames(t, svc, "member"))
+	assert.Equal(t, []string{"ci", "Voice"}, visibleNames(t, svc, "admin"), "roles without Administrator are subject to overwrites")
+	assert.Equal(t, []string{"ci", "alerts", "Voice"}, visibleNames(t, svc, "owner"), "the owner bypasses overwrites")
+	readable, err := svc.ReadableChannels(ctx, "member", "")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"ch-1": "ci"}, readable)
+	assert.ErrorIs(t, svc.CheckChannelPermission(ctx, "ch-2", "member", PermViewChannel), ErrChannelNotFound)
+	ok, err := svc.CanSendMessages(ctx, "ch-2", "member")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// Role and member overwrites reopen it
+	helpers, err := svc.CreateRole(ctx, "srv-1", "owner", "Helpers", 0, 0)
+	require.NoError(t, err)
+	require.NoError(t, svc.AddMemberRole(ctx, "srv-1", "owner", "member", helpers.ID))
+	_, err = svc.SetChannelOverwrite(ctx, "srv-1", "owner", Overwrite{ChannelID: "ch-2", TargetType: OverwriteRole, TargetID: helpers.ID, Allow: PermViewChannel})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ci", "alerts", "Voice"}, visibleNames(t, svc, "member"))
+	_, err = svc.SetChannelOverwrite(ctx, "srv-1", "owner", Overwrite{ChannelID: "ch-2", TargetType: OverwriteMember, TargetID: "member", Deny: PermSendMessages})
+	require.NoError(t, err)
+	require.NoError(t, svc.CheckChannelPermission(ctx, "ch-2", "member", PermViewChannel))
+	ok, err = svc.CanSendMessages(ctx, "ch-2", "member")
+	require.NoError(t, err)
+	assert.False(t, ok, "member overwrites apply last")
+
+	overwrites, err := svc.ChannelOverwrites(ctx, "srv-1", "owner", "ch-2")
+	require.NoError(t, err)
+	assert.Len(t, overwrites, 3)
+
+	// Deleting the role drops its overwrites
+	require.NoError(t, svc.DeleteRole(ctx, "srv-1", "owner", helpers.ID))
+	assert.Equal(t, []string{"ci", "Voice"}, visibleNames(t, svc, "member"))
+	overwrites, err = svc.ChannelOverwrites(ctx, "srv-1", "owner", "ch-2")
+	require.NoError(t, err)
+	assert.Len(t, overwrites, 2)
+
+	require.NoError(t, svc.DeleteChannelOverwrite(ctx, "srv-1", "owner", "ch-2", OverwriteRole, everyone))
+	assert.Equal(t, []string{"ci", "alerts", "Voice"}, visibleNames(t, svc, "member"))
+}
+
+func TestChannelOverwrites_Validation(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+	everyone := DefaultRoleID("srv-1")
+
+	for name, o := range map[string]Overwrite{
+		"unknown target type":     {ChannelID: "ch-1", TargetType: "user", TargetID: "member", Deny: PermViewChannel},
+		"non-member target":       {ChannelID: "ch-1", TargetType: OverwriteMember, TargetID: "bot", Deny: PermViewChannel},
+		"not overwritable":        {ChannelID: "ch-1", TargetType: OverwriteRole, TargetID: everyone, Allow: PermManageMessages},
+		"allowed and denied":      {ChannelID: "ch-1", TargetType: OverwriteRole, TargetID: everyone, Allow: PermSpeak, Deny: PermSpeak},
+		"channel of other server": {ChannelID: "ch-x", TargetType: OverwriteRole, TargetID: everyone, Deny: PermViewChannel},
+	} {
+		_, err := svc.SetChannelOverwrite(ctx, "srv-1", "owner", o)
+		assert.Error(t, err, name)
+	}
+	_, err := svc.SetChannelOverwrite(ctx, "srv-1", "owner", Overwrite{ChannelID: "ch-1", TargetType: OverwriteRole, TargetID: "nope", Deny: PermViewChannel})
+	assert.ErrorIs(t, err, ErrRoleNotFound)
+	_, err = svc.ChannelOverwrites(ctx, "srv-1", "owner", "ch-x")
+	assert.ErrorIs(t, err, ErrChannelNotFound)
+
+	// Managers cannot overwrite permissions they lack in the channel
+	_, err = svc.SetChannelOverwrite(ctx, "srv-1", "owner", Overwrite{ChannelID: "ch-1", TargetType: OverwriteMember, TargetID: "admin", Deny: PermSpeak})
+	require.NoError(t, err)
+	_, err = svc.SetChannelOverwrite(ctx, "srv-1", "admin", Overwrite{ChannelID: "ch-1", TargetType: OverwriteRole, TargetID: everyone, Allow: PermSpeak})
+	assert.Error(t, err)
+}
+
+func TestVoiceAccess(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+	everyone := DefaultRoleID("srv-1")
+
+	connect, speak, err := svc.VoiceAccess(ctx, "srv-1", "vc-1", "member")
+	require.NoError(t, err)
+	assert.True(t, connect)
+	assert.True(t, speak)
+	connect, _, err = svc.VoiceAccess(ctx, "srv-1", "ch-1", "member")
+	require.NoError(t, err)
+	assert.False(t, connect, "text channels cannot be joined")
+	connect, _, err = svc.VoiceAccess(ctx, "srv-2", "vc-1", "member")
+	require.NoError(t, err)
+	assert.False(t, connect, "channel of another server")
+	connect, _, err = svc.VoiceAccess(ctx, "srv-1", "vc-1", "bot")
+	require.NoError(t, err)
+	assert.False(t, connect, "non-members cannot join")
+
+	_, err = svc.SetChannelOverwrite(ctx, "srv-1", "owner", Overwrite{ChannelID: "vc-1", TargetType: OverwriteRole, TargetID: everyone, Deny: PermSpeak})
+	require.NoError(t, err)
+	connect, speak, err = svc.VoiceAccess(ctx, "srv-1", "vc-1", "member")
+	require.NoError(t, err)
+	assert.True(t, connect)
+	assert.False(t, speak)
+
+	_, err = svc.SetChannelOverwrite(ctx, "srv-1", "owner", Overwrite{ChannelID: "vc-1", TargetType: OverwriteMember, TargetID: "member", Deny: PermConnect})
+	require.NoError(t, err)
+	connect, _, err = svc.VoiceAccess(ctx, "srv-1", "vc-1", "member")
+	require.NoError(t, err)
+	assert.False(t, connect)
+
+	// Kicked members lose their overwrites
+	require.NoError(t, svc.KickMember(ctx, "srv-1", "owner", "member"))
+	overwrites, err := svc.ChannelOverwrites(ctx, "srv-1", "owner", "vc-1")
+	require.NoError(t, err)
+	assert.Len(t, overwrites, 1)
+}
@@ -1,69 +1,77 @@
 package server
 
-// Permission represents a specific action that can be performed on a server.
-type Permission int
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Permission is a 64-bit set of actions that can be performed on a server.
+// Roles grant permissions; a member holds the union of the permissions of
+// the default role and of every role assigned to them.
+// Permissions are encoded in JSON as decimal strings, since JavaScript
+// numbers cannot represent every 64-bit value.
+type Permission uint64
 
+// Permission bits. Values are stored in the database; never renumber them.
 const (
-	PermManageServer   Permission = iota // Rename, delete server
-	PermManageChannels                   // Create, edit, delete channels
-	PermManageMembers                    // Kick, change roles
-	PermCreateInvite                     // Generate invite codes
-	PermSendMessages                     // Send text messages
-	PermManageMessages                   // Delete others' messages
+	PermManageServer   Permission = 1 << iota // Rename, delete server
+	PermManageChannels                        // Create, edit, delete channels
+	PermManageMembers                         // Kick, ban and time out members
+	PermCreateInvite                          // Generate invite codes
+	PermSendMessages                          // Send text messages
+	PermManageMessages                        // Delete others' messages
+	PermManageEmoji                           // Upload, rename, delete custom emoji
+	PermManageRoles                           // Create, edit, delete and assign roles below one's own
+	PermAdministrator                         // Every permission
+	PermViewChannel                           // See a channel, read and search its messages
+	PermConnect                               // Join a voice channel
+	PermSpeak                                 // Unmute in a voice channel
+	PermViewAuditLog                          // Read the server's audit log
 )
 
-// rolePermissions maps each role to its allowed permissions.
-// Complexity: O(1) lookup
-var rolePermissions = map[Role]map[Permission]bool{
-	RoleOwner: {
-		PermManageServer:   true,
-		PermManageChannels: true,
-		PermManageMembers:  true,
-		PermCreateInvite:   true,
-		PermSendMessages:   true,
-		PermManageMessages: true,
-	},
-	RoleAdmin: {
-		PermManageChannels: true,
-		PermManageMembers:  true,
-		PermCreateInvite:   true,
-		PermSendMessages:   true,
-		PermManageMessages: true,
-	},
-	RoleModerator: {
-		PermCreateInvite:   true,
-		PermSendMessages:   true,
-		PermManageMessages: true,
-	},
-	RoleMember: {
-		PermCreateInvite: true,
-		PermSendMessages: true,
-	},
-}
+const (
+	// AllPermissions is every defined permission.
+	AllPermissions = PermViewAuditLog<<1 - 1
+
+	// OverwritablePermissions are the permissions channel overwrites can allow or deny.
+	OverwritablePermissions = PermViewChannel | PermSendMessages | PermConnect | PermSpeak
+
+	// TimeoutPermissions are withheld from members while they are timed out.
+	TimeoutPermissions = PermSendMessages | PermSpeak
+
+	// DefaultPermissions are granted by the default role of new servers, and to every
+	// member of a server whose default role is missing.
+	DefaultPermissions = PermCreateInvite | PermSendMessages | PermViewChannel | PermConnect | PermSpeak
+
+	// ModeratorPermissions are granted by the preset Moderator role.
+	ModeratorPermissions = DefaultPermissions | PermManageMessages
 
-// HasPermission checks if a role has the given permission.
+	// AdminPermissions are granted by the preset Admin role.
+	AdminPermissions = ModeratorPermissions | PermManageChannels | PermManageMembers | PermManageEmoji | PermManageRoles | PermViewAuditLog
+)
+
+// Has reports whether p includes perm. PermAdministrator includes every permission.
 // Complexity: O(1)
-func HasPermission(role Role, perm Permission) bool {
-	perms, ok := rolePermissions[role]
-	if !ok {
-		return false
-	}
-	return perms[perm]
+func (p Permission) Has(perm Permission) bool {
+	return p&PermAdministrator != 0 || p&perm == perm
 }
 
-// RoleHierarchy returns the rank of a role (higher = more powerful).
-// Complexity: O(1)
-func RoleHierarchy(role Role) int {
-	switch role {
-	case RoleOwner:
-		return 4
-	case RoleAdmin:
-		return 3
-	case RoleModerator:
-		return 2
-	case RoleMember:
-		return 1
-	default:
-		return 0
+// MarshalJSON encodes the permission set as a decimal string.
+func (p Permission) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strconv.FormatUint(uint64(p), 10))
+}
+
+// UnmarshalJSON accepts a decimal string or a JSON number.
+func (p *Permission) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		s = string(data)
+	}
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid permissions %s", data)
 	}
+	*p = Permission(v)
+	return nil
 }
@@ -3,42 +3,118 @@ package server
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/rs/zerolog"
 )
 
-type querier interface {
+// Querier is the database handle used by the repository: a connection pool,
+// a transaction, or an adapter translating placeholders for PostgreSQL.
+type Querier interface {
 	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
 	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
 	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
 }
 
+// querier is an alias for internal use.
+type querier = Querier
+
+// Transactor runs a function inside a database transaction.
+type Transactor interface {
+	InTransaction(ctx context.Context, fn func(Querier) error) error
+}
+
+// StdlibTransactor runs transactions on a *sql.DB.
+type StdlibTransactor struct {
+	db      *sql.DB
+	wrapper func(Querier) Querier // optional wrapper applied to the tx (e.g. placeholder translation)
+}
+
+// NewStdlibTransactor creates a transactor from a standard *sql.DB.
+func NewStdlibTransactor(db *sql.DB) *StdlibTransactor {
+	return &StdlibTransactor{db: db}
+}
+
+// NewStdlibTransactorWithWrapper creates a transactor that wraps each transaction's
+// querier with the given function (e.g. for placeholder translation on PostgreSQL).
+func NewStdlibTransactorWithWrapper(db *sql.DB, wrapper func(Querier) Querier) *StdlibTransactor {
+	return &StdlibTransactor{db: db, wrapper: wrapper}
+}
+
+// InTransaction runs fn inside a database transaction, committing when it returns nil.
+func (t *StdlibTransactor) InTransaction(ctx context.Context, fn func(Querier) error) error {
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	var q Querier = tx
+	if t.wrapper != nil {
+		q = t.wrapper(q)
+	}
+	if err := fn(q); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
 // Repository handles server-related database operations.
 type Repository struct {
 	db     querier
+	tx     Transactor
 	logger zerolog.Logger
 }
 
-// NewRepository creates a new server repository.
-func NewRepository(db querier, logger zerolog.Logger) *Repository {
+// NewRepository creates a new server repository. tx runs the multi-statement
+// updates, such as channel reordering, atomically.
+func NewRepository(db querier, tx Transactor, logger zerolog.Logger) *Repository {
 	return &Repository{
 		db:     db,
+		tx:     tx,
 		logger: logger.With().Str("component", "server_repo").Logger(),
 	}
 }
 
 // --- Server CRUD ---
 
-// CreateServer inserts a new server.
-// Complexity: O(1)
-func (r *Repository) CreateServer(ctx context.Context, s *Server) error {
-	query := `INSERT INTO servers (id, name, icon_url, owner_id, invite_code, created_at)
-		VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)`
-
-	_, err := r.db.ExecContext(ctx, query, s.ID, s.Name, s.IconURL, s.OwnerID, s.InviteCode)
+// CreateServer inserts a new server together with its initial invite, created by
+// the owner, which never expires, the owner's membership and the given roles,
+// categories, channels and channel overwrites, in one transaction so a failure
+// leaves no half-created server behind.
+// Complexity: O(n) where n = rows inserted
+func (r *Repository) CreateServer(ctx context.Context, s *Server, roles []*Role, categories []*Category, channels []*Channel, overwrites []*Overwrite) error {
+	err := r.tx.InTransaction(ctx, func(q Querier) error {
+		if _, err := q.ExecContext(ctx,
+			`INSERT INTO servers (id, name, icon_url, owner_id, invite_code, created_at)
+			VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)`,
+			s.ID, s.Name, s.IconURL, s.OwnerID, s.InviteCode,
+		); err != nil {
+			return fmt.Errorf("failed to create server: %w", err)
+		}
+		if _, err := q.ExecContext(ctx,
+			`INSERT INTO server_invites (code, server_id, creator_id, created_at)
+			VALUES (?, ?, ?, CURRENT_TIMESTAMP)`,
+			s.InviteCode, s.ID, s.OwnerID,
+		); err != nil {
+			return fmt.Errorf("failed to create invite: %w", err)
+		}
+		if _, err := q.ExecContext(ctx,
+			`INSERT INTO server_members (server_id, user_id, joined_at)
+			VALUES (?, ?, CURRENT_TIMESTAMP)`,
+			s.ID, s.OwnerID,
+		); err != nil {
+			return fmt.Errorf("failed to add owner: %w", err)
+		}
+		return insertStructure(ctx, q, roles, categories, channels, overwrites)
+	})
 	if err != nil {
-		return fmt.Errorf("failed to create server: %w", err)
+		return err
 	}
 
 	r.logger.Info().Str("server_id", s.ID).Str("name", s.Name).Msg("server created")
@@ -48,7 +124,7 @@ func (r *Repository) CreateServer(ctx context.Context, s *Server) error {
 // GetServer retrieves a server by ID.
 // Complexity: O(1)
 func (r *Repository) GetServer(ctx context.Context, id string) (*Server, error) {
-	query := `SELECT id, name, icon_url, owner_id, invite_code, created_at FROM servers WHERE id = ?`
+	query := `SELECT id, name, icon_url, owner_id, COALESCE(invite_code, ''), created_at FROM servers WHERE id = ?`
 
 	var s Server
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
@@ -66,7 +142,7 @@ func (r *Repository) GetServer(ctx context.Context, id string) (*Server, error)
 // ListServersByUser retrieves all servers a user belongs to.
 // Complexity: O(n) where n = number of user's servers
 func (r *Repository) ListServersByUser(ctx context.Context, userID string) ([]*Server, error) {
-	query := `SELECT s.id, s.name, s.icon_url, s.owner_id, s.invite_code, s.created_at
+	query := `SELECT s.id, s.name, s.icon_url, s.owner_id, COALESCE(s.invite_code, ''), s.created_at
 		FROM servers s
 		INNER JOIN server_members sm ON s.id = sm.server_id
 		WHERE sm.user_id = ?
@@ -113,57 +189,102 @@ func (r *Repository) DeleteServer(ctx context.Context, id string) error {
 	return nil
 }
 
-// GetServerByInvite retrieves a server by its invite code.
-// Complexity: O(1) — indexed lookup
-func (r *Repository) GetServerByInvite(ctx context.Context, code string) (*Server, error) {
-	query := `SELECT id, name, icon_url, owner_id, invite_code, created_at FROM servers WHERE invite_code = ?`
-
-	var s Server
-	err := r.db.QueryRowContext(ctx, query, code).Scan(
-		&s.ID, &s.Name, &s.IconURL, &s.OwnerID, &s.InviteCode, &s.CreatedAt,
-	)
-	if err == sql.ErrNoRows {
-		return nil, nil
+// insertStructure inserts the roles, categories, channels and channel overwrites
+// of a new server using q, as given, without moving existing rows.
+func insertStructure(ctx context.Context, q Querier, roles []*Role, categories []*Category, channels []*Channel, overwrites []*Overwrite) error {
+	for _, role := range roles {
+		if _, err := q.ExecContext(ctx,
+			`INSERT INTO server_roles (id, server_id, name, color, position, permissions, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)`,
+			role.ID, role.ServerID, role.Name, role.Color, role.Position, int64(role.Permissions),
+		); err != nil {
+			return fmt.Errorf("failed to create role: %w", err)
+		}
 	}
-	if err != nil {
-		return nil, fmt.Errorf("failed to get server by invite: %w", err)
+	for _, c := range categories {
+		if _, err := q.ExecContext(ctx,
+			`INSERT INTO channel_categories (id, server_id, name, position, created_at)
+			VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP)`,
+			c.ID, c.ServerID, c.Name, c.Position,
+		); err != nil {
+			return fmt.Errorf("failed to create category: %w", err)
+		}
 	}
-	return &s, nil
-}
-
-// UpdateInviteCode sets a new invite code for a server.
-// Complexity: O(1)
-func (r *Repository) UpdateInviteCode(ctx context.Context, serverID, code string) error {
-	query := `UPDATE servers SET invite_code = ? WHERE id = ?`
-	_, err := r.db.ExecContext(ctx, query, code, serverID)
-	if err != nil {
-		return fmt.Errorf("failed to update invite code: %w", err)
+	for _, ch := range channels {
+		if _, err := q.ExecContext(ctx,
+			`INSERT INTO channels (id, server_id, name, type, position, slow_mode_seconds, category_id, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, NULLIF(?, ''), CURRENT_TIMESTAMP)`,
+			ch.ID, ch.ServerID, ch.Name, ch.Type, ch.Position, ch.SlowMode, ch.CategoryID,
+		); err != nil {
+			return fmt.Errorf("failed to create channel: %w", err)
+		}
+	}
+	for _, o := range overwrites {
+		if _, err := q.ExecContext(ctx,
+			`INSERT INTO channel_overwrites (channel_id, target_type, target_id, allow, deny)
+			VALUES (?, ?, ?, ?, ?)`,
+			o.ChannelID, o.TargetType, o.TargetID, int64(o.Allow), int64(o.Deny),
+		); err != nil {
+			return fmt.Errorf("failed to set channel overwrite: %w", err)
+		}
 	}
 	return nil
 }
 
 // --- Channel CRUD ---
 
-// CreateChannel inserts a new channel.
-// Complexity: O(1)
-func (r *Repository) CreateChannel(ctx context.Context, ch *Channel) error {
-	query := `INSERT INTO channels (id, server_id, name, type, position, created_at)
-		VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)`
+// channelColumns are the columns read by scanChannel.
+const channelColumns = `id, server_id, name, type, position, slow_mode_seconds, created_at, COALESCE(category_id, '')`
+
+// scanner is implemented by *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanChannel(row scanner) (*Channel, error) {
+	var ch Channel
+	if err := row.Scan(&ch.ID, &ch.ServerID, &ch.Name, &ch.Type, &ch.Position, &ch.SlowMode, &ch.CreatedAt, &ch.CategoryID); err != nil {
+		return nil, err
+	}
+	return &ch, nil
+}
 
-	_, err := r.db.ExecContext(ctx, query, ch.ID, ch.ServerID, ch.Name, ch.Type, ch.Position)
+// CreateChannel inserts a channel after the last channel of its category and sets
+// its Position.
+// Complexity: O(n) where n = channels of the server
+func (r *Repository) CreateChannel(ctx context.Context, ch *Channel) error {
+	err := r.tx.InTransaction(ctx, func(q Querier) error {
+		next, err := nextChannelPosition(ctx, q, ch.ServerID, ch.CategoryID)
+		if err != nil {
+			return err
+		}
+		ch.Position = next
+		if _, err := q.ExecContext(ctx,
+			`INSERT INTO channels (id, server_id, name, type, position, category_id, created_at)
+			VALUES (?, ?, ?, ?, ?, NULLIF(?, ''), CURRENT_TIMESTAMP)`,
+			ch.ID, ch.ServerID, ch.Name, ch.Type, ch.Position, ch.CategoryID,
+		); err != nil {
+			return fmt.Errorf("failed to create channel: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to create channel: %w", err)
+		return err
 	}
 
 	r.logger.Info().Str("channel_id", ch.ID).Str("server_id", ch.ServerID).Msg("channel created")
 	return nil
 }
 
-// ListChannels retrieves all channels for a server, ordered by position.
-// Complexity: O(n) where n = number of channels
+// ListChannels retrieves all channels for a server in display order: the
+// uncategorized channels first, then the channels of each category.
+// Complexity: O(n log n) where n = number of channels
 func (r *Repository) ListChannels(ctx context.Context, serverID string) ([]*Channel, error) {
-	query := `SELECT id, server_id, name, type, position, created_at
-		FROM channels WHERE server_id = ? ORDER BY position ASC, created_at ASC`
+	query := `SELECT c.id, c.server_id, c.name, c.type, c.position, c.slow_mode_seconds, c.created_at, COALESCE(c.category_id, '')
+		FROM channels c
+		LEFT JOIN channel_categories cc ON cc.id = c.category_id
+		WHERE c.server_id = ?
+		ORDER BY CASE WHEN c.category_id IS NULL THEN 0 ELSE 1 END, cc.position, cc.id, c.position, c.created_at`
 
 	rows, err := r.db.QueryContext(ctx, query, serverID)
 	if err != nil {
@@ -173,33 +294,96 @@ func (r *Repository) ListChannels(ctx context.Context, serverID string) ([]*Chan
 
 	var channels []*Channel
 	for rows.Next() {
-		var ch Channel
-		if err := rows.Scan(&ch.ID, &ch.ServerID, &ch.Name, &ch.Type, &ch.Position, &ch.CreatedAt); err != nil {
+		ch, err := scanChannel(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan channel: %w", err)
 		}
-		channels = append(channels, &ch)
+		channels = append(channels, ch)
 	}
 	return channels, rows.Err()
 }
 
-// UpdateChannel updates channel name, type, and position.
+// UpdateChannel updates channel name and type.
 // Complexity: O(1)
-func (r *Repository) UpdateChannel(ctx context.Context, id, name, chType string, position int) error {
-	query := `UPDATE channels SET name = ?, type = ?, position = ? WHERE id = ?`
-	_, err := r.db.ExecContext(ctx, query, name, chType, position, id)
+func (r *Repository) UpdateChannel(ctx context.Context, id, name, chType string) error {
+	query := `UPDATE channels SET name = ?, type = ? WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, name, chType, id)
 	if err != nil {
 		return fmt.Errorf("failed to update channel: %w", err)
 	}
 	return nil
 }
 
-// DeleteChannel removes a channel.
+// MoveChannel moves a channel to the end of another category ("" for the
+// uncategorized channels) and closes the gap it leaves behind, atomically.
+// Complexity: O(n) where n = channels of the server
+func (r *Repository) MoveChannel(ctx context.Context, serverID, id, categoryID string) error {
+	return r.tx.InTransaction(ctx, func(q Querier) error {
+		var from string
+		var position int
+		err := q.QueryRowContext(ctx,
+			`SELECT COALESCE(category_id, ''), position FROM channels WHERE id = ? AND server_id = ?`, id, serverID,
+		).Scan(&from, &position)
+		if err == sql.ErrNoRows {
+			return ErrChannelNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get channel: %w", err)
+		}
+		if from == categoryID {
+			return nil
+		}
+
+		next, err := nextChannelPosition(ctx, q, serverID, categoryID)
+		if err != nil {
+			return err
+		}
+		if _, err := q.ExecContext(ctx,
+			`UPDATE channels SET category_id = NULLIF(?, ''), position = ? WHERE id = ?`, categoryID, next, id,
+		); err != nil {
+			return fmt.Errorf("failed to move channel: %w", err)
+		}
+		return closeChannelGap(ctx, q, serverID, from, position)
+	})
+}
+
+// UpdateChannelSlowMode sets the slow mode interval (in seconds) for a channel.
 // Complexity: O(1)
+func (r *Repository) UpdateChannelSlowMode(ctx context.Context, id string, seconds int) error {
+	query := `UPDATE channels SET slow_mode_seconds = ? WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, seconds, id)
+	if err != nil {
+		return fmt.Errorf("failed to update channel slow mode: %w", err)
+	}
+	r.logger.Info().Str("channel_id", id).Int("slow_mode_seconds", seconds).Msg("channel slow mode updated")
+	return nil
+}
+
+// DeleteChannel removes a channel and closes the gap it leaves in its category.
+// Complexity: O(n) where n = channels of the server
 func (r *Repository) DeleteChannel(ctx context.Context, id string) error {
-	query := `DELETE FROM channels WHERE id = ?`
-	_, err := r.db.ExecContext(ctx, query, id)
+	err := r.tx.InTransaction(ctx, func(q Querier) error {
+		var serverID, categoryID string
+		var position int
+		err := q.QueryRowContext(ctx,
+			`SELECT server_id, COALESCE(category_id, ''), position FROM channels WHERE id = ?`, id,
+		).Scan(&serverID, &categoryID, &position)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get channel: %w", err)
+		}
+		if _, err := q.ExecContext(ctx, `DELETE FROM channel_overwrites WHERE channel_id = ?`, id); err != nil {
+			return fmt.Errorf("failed to delete channel overwrites: %w", err)
+		}
+		if _, err := q.ExecContext(ctx, `DELETE FROM channels WHERE id = ?`, id); err != nil {
+			return fmt.Errorf("failed to delete channel: %w", err)
+		}
+		return closeChannelGap(ctx, q, serverID, categoryID, position)
+	})
 	if err != nil {
-		return fmt.Errorf("failed to delete channel: %w", err)
+		return err
 	}
 	r.logger.Info().Str("channel_id", id).Msg("channel deleted")
 	return nil
@@ -208,64 +392,355 @@ func (r *Repository) DeleteChannel(ctx context.Context, id string) error {
 // GetChannel retrieves a channel by ID.
 // Complexity: O(1)
 func (r *Repository) GetChannel(ctx context.Context, id string) (*Channel, error) {
-	query := `SELECT id, server_id, name, type, position, created_at FROM channels WHERE id = ?`
-
-	var ch Channel
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&ch.ID, &ch.ServerID, &ch.Name, &ch.Type, &ch.Position, &ch.CreatedAt,
-	)
+	ch, err := scanChannel(r.db.QueryRowContext(ctx, `SELECT `+channelColumns+` FROM channels WHERE id = ?`, id))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get channel: %w", err)
 	}
-	return &ch, nil
+	return ch, nil
+}
+
+// nextChannelPosition returns the position after the last channel of a category
+// ("" for the uncategorized channels).
+func nextChannelPosition(ctx context.Context, q Querier, serverID, categoryID string) (int, error) {
+	var next int
+	err := q.QueryRowContext(ctx,
+		`SELECT COALESCE(MAX(position) + 1, 0) FROM channels WHERE server_id = ? AND COALESCE(category_id, '') = ?`,
+		serverID, categoryID,
+	).Scan(&next)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get next channel position: %w", err)
+	}
+	return next, nil
+}
+
+// closeChannelGap shifts the channels after position in a category up by one.
+// Positions are unique per category and checked row by row, so the channels are
+// parked at negative positions first.
+func closeChannelGap(ctx context.Context, q Querier, serverID, categoryID string, position int) error {
+	if _, err := q.ExecContext(ctx,
+		`UPDATE channels SET position = -position
+		WHERE server_id = ? AND COALESCE(category_id, '') = ? AND position > ?`,
+		serverID, categoryID, position,
+	); err != nil {
+		return fmt.Errorf("failed to update channel positions: %w", err)
+	}
+	if _, err := q.ExecContext(ctx,
+		`UPDATE channels SET position = -position - 1
+		WHERE server_id = ? AND COALESCE(category_id, '') = ? AND position < 0`,
+		serverID, categoryID,
+	); err != nil {
+		return fmt.Errorf("failed to update channel positions: %w", err)
+	}
+	return nil
+}
+
+// --- Channel Categories ---
+
+// CreateCategory inserts a category after the last category of its server and
+// sets its Position.
+// Complexity: O(k) where k = categories of the server
+func (r *Repository) CreateCategory(ctx context.Context, c *Category) error {
+	err := r.tx.InTransaction(ctx, func(q Querier) error {
+		if err := q.QueryRowContext(ctx,
+			`SELECT COALESCE(MAX(position) + 1, 0) FROM channel_categories WHERE server_id = ?`, c.ServerID,
+		).Scan(&c.Position); err != nil {
+			return fmt.Errorf("failed to get next category position: %w", err)
+		}
+		_, err := q.ExecContext(ctx,
+			`INSERT INTO channel_categories (id, server_id, name, position, created_at)
+			VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP)`,
+			c.ID, c.ServerID, c.Name, c.Position,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create category: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	r.logger.Info().Str("category_id", c.ID).Str("server_id", c.ServerID).Msg("category created")
+	return nil
+}
+
+// GetCategory retrieves a category by ID.
+// Complexity: O(1)
+func (r *Repository) GetCategory(ctx context.Context, id string) (*Category, error) {
+	query := `SELECT id, server_id, name, position, created_at FROM channel_categories WHERE id = ?`
+
+	var c Category
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&c.ID, &c.ServerID, &c.Name, &c.Position, &c.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category: %w", err)
+	}
+	return &c, nil
+}
+
+// ListCategories retrieves the categories of a server, ordered by position.
+// Complexity: O(k) where k = categories of the server
+func (r *Repository) ListCategories(ctx context.Context, serverID string) ([]*Category, error) {
+	query := `SELECT id, server_id, name, position, created_at
+		FROM channel_categories WHERE server_id = ? ORDER BY position, id`
+
+	rows, err := r.db.QueryContext(ctx, query, serverID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list categories: %w", err)
+	}
+	defer rows.Close()
+
+	var categories []*Category
+	for rows.Next() {
+		var c Category
+		if err := rows.Scan(&c.ID, &c.ServerID, &c.Name, &c.Position, &c.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan category: %w", err)
+		}
+		categories = append(categories, &c)
+	}
+	return categories, rows.Err()
+}
+
+// RenameCategory sets the name of a category.
+// Complexity: O(1)
+func (r *Repository) RenameCategory(ctx context.Context, id, name string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE channel_categories SET name = ? WHERE id = ?`, name, id)
+	if err != nil {
+		return fmt.Errorf("failed to rename category: %w", err)
+	}
+	return nil
+}
+
+// DeleteCategory removes a category, appending its channels (in order) to the
+// uncategorized channels and closing the gap among the remaining categories.
+// Complexity: O(n) where n = channels of the server
+func (r *Repository) DeleteCategory(ctx context.Context, serverID, id string) error {
+	err := r.tx.InTransaction(ctx, func(q Querier) error {
+		var position int
+		err := q.QueryRowContext(ctx,
+			`SELECT position FROM channel_categories WHERE id = ? AND server_id = ?`, id, serverID,
+		).Scan(&position)
+		if err == sql.ErrNoRows {
+			return ErrCategoryNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get category: %w", err)
+		}
+
+		next, err := nextChannelPosition(ctx, q, serverID, "")
+		if err != nil {
+			return err
+		}
+		if _, err := q.ExecContext(ctx,
+			`UPDATE channels SET category_id = NULL, position = position + ? WHERE category_id = ?`, next, id,
+		); err != nil {
+			return fmt.Errorf("failed to uncategorize channels: %w", err)
+		}
+		if _, err := q.ExecContext(ctx, `DELETE FROM channel_categories WHERE id = ?`, id); err != nil {
+			return fmt.Errorf("failed to delete category: %w", err)
+		}
+		if _, err := q.ExecContext(ctx,
+			`UPDATE channel_categories SET position = position - 1 WHERE server_id = ? AND position > ?`, serverID, position,
+		); err != nil {
+			return fmt.Errorf("failed to update category positions: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	r.logger.Info().Str("category_id", id).Str("server_id", serverID).Msg("category deleted")
+	return nil
+}
+
+// ApplyLayout rewrites the positions of every category and channel of a server,
+// and the category of every channel, in one transaction. The layout must list
+// each category and channel of the server exactly once; otherwise nothing is
+// changed and ErrLayoutMismatch is returned.
+// Complexity: O(n + k) where n = channels, k = categories of the server
+func (r *Repository) ApplyLayout(ctx context.Context, serverID string, layout ChannelLayout) error {
+	return r.tx.InTransaction(ctx, func(q Querier) error {
+		channels, err := idSet(ctx, q, `SELECT id FROM channels WHERE server_id = ?`, serverID)
+		if err != nil {
+			return fmt.Errorf("failed to list channels: %w", err)
+		}
+		categories, err := idSet(ctx, q, `SELECT id FROM channel_categories WHERE server_id = ?`, serverID)
+		if err != nil {
+			return fmt.Errorf("failed to list categories: %w", err)
+		}
+		if !layout.covers(channels, categories) {
+			return ErrLayoutMismatch
+		}
+
+		// Park every channel at a negative position so the new positions, unique
+		// per category, never clash with old ones while being written.
+		if _, err := q.ExecContext(ctx,
+			`UPDATE channels SET position = -1 - position WHERE server_id = ?`, serverID,
+		); err != nil {
+			return fmt.Errorf("failed to update channel positions: %w", err)
+		}
+
+		for i, id := range layout.Uncategorized {
+			if _, err := q.ExecContext(ctx,
+				`UPDATE channels SET category_id = NULL, position = ? WHERE id = ?`, i, id,
+			); err != nil {
+				return fmt.Errorf("failed to update channel position: %w", err)
+			}
+		}
+		for i, cat := range layout.Categories {
+			if _, err := q.ExecContext(ctx,
+				`UPDATE channel_categories SET position = ? WHERE id = ?`, i, cat.ID,
+			); err != nil {
+				return fmt.Errorf("failed to update category position: %w", err)
+			}
+			for j, id := range cat.Channels {
+				if _, err := q.ExecContext(ctx,
+					`UPDATE channels SET category_id = ?, position = ? WHERE id = ?`, cat.ID, j, id,
+				); err != nil {
+					return fmt.Errorf("failed to update channel position: %w", err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// idSet runs a query returning one ID per row and collects the IDs.
+func idSet(ctx context.Context, q Querier, query string, args ...interface{}) (map[string]bool, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
 }
 
 // --- Member Management ---
 
-// AddMember adds a user as a member of a server.
+// memberColumns reads a member with its legacy role name, derived from ownership
+// and the preset roles; memberJoins provides the sm, u and s aliases.
+const memberColumns = `sm.server_id, sm.user_id, u.username, COALESCE(u.avatar_url, ''),
+	CASE
+		WHEN s.owner_id = sm.user_id THEN 'owner'
+		WHEN EXISTS (SELECT 1 FROM server_member_roles mr
+			WHERE mr.server_id = sm.server_id AND mr.user_id = sm.user_id AND mr.role_id = sm.server_id || ':admin') THEN 'admin'
+		WHEN EXISTS (SELECT 1 FROM server_member_roles mr
+			WHERE mr.server_id = sm.server_id AND mr.user_id = sm.user_id AND mr.role_id = sm.server_id || ':moderator') THEN 'moderator'
+		ELSE 'member'
+	END,
+	sm.joined_at, sm.timeout_until`
+
+const memberJoins = `FROM server_members sm
+	INNER JOIN users u ON sm.user_id = u.id
+	INNER JOIN servers s ON s.id = sm.server_id`
+
+// scanMember reads a member; timeouts that have already ended are left out.
+func scanMember(row scanner) (*Member, error) {
+	m := Member{Roles: []string{}}
+	var timeoutUntil sql.NullTime
+	if err := row.Scan(&m.ServerID, &m.UserID, &m.Username, &m.Avatar, &m.Role, &m.JoinedAt, &timeoutUntil); err != nil {
+		return nil, err
+	}
+	if timeoutUntil.Valid && timeoutUntil.Time.After(time.Now()) {
+		t := timeoutUntil.Time.UTC()
+		m.TimeoutUntil = &t
+	}
+	return &m, nil
+}
+
+// AddMember adds a user as a member of a server, holding only the default role.
 // Complexity: O(1)
-func (r *Repository) AddMember(ctx context.Context, serverID, userID string, role Role) error {
-	query := `INSERT INTO server_members (server_id, user_id, role, joined_at)
-		VALUES (?, ?, ?, CURRENT_TIMESTAMP)`
+func (r *Repository) AddMember(ctx context.Context, serverID, userID string) error {
+	query := `INSERT INTO server_members (server_id, user_id, joined_at)
+		VALUES (?, ?, CURRENT_TIMESTAMP)`
 
-	_, err := r.db.ExecContext(ctx, query, serverID, userID, string(role))
+	_, err := r.db.ExecContext(ctx, query, serverID, userID)
 	if err != nil {
 		return fmt.Errorf("failed to add member: %w", err)
 	}
 
-	r.logger.Info().Str("server_id", serverID).Str("user_id", userID).Str("role", string(role)).Msg("member added")
+	r.logger.Info().Str("server_id", serverID).Str("user_id", userID).Msg("member added")
 	return nil
 }
 
-// RemoveMember removes a user from a server.
-// Complexity: O(1)
+// RemoveMember removes a user from a server together with their roles and
+// channel overwrites.
+// Complexity: O(r + o) where r = roles, o = channel overwrites of the member
 func (r *Repository) RemoveMember(ctx context.Context, serverID, userID string) error {
-	query := `DELETE FROM server_members WHERE server_id = ? AND user_id = ?`
-	_, err := r.db.ExecContext(ctx, query, serverID, userID)
+	err := r.tx.InTransaction(ctx, func(q Querier) error {
+		return deleteMember(ctx, q, serverID, userID)
+	})
 	if err != nil {
-		return fmt.Errorf("failed to remove member: %w", err)
+		return err
 	}
 	r.logger.Info().Str("server_id", serverID).Str("user_id", userID).Msg("member removed")
 	return nil
 }
 
-// ListMembers retrieves all members of a server with their user info.
-// Complexity: O(n) where n = number of members
+// deleteMember removes a membership with its roles and channel overwrites using q.
+func deleteMember(ctx context.Context, q Querier, serverID, userID string) error {
+	if _, err := q.ExecContext(ctx,
+		`DELETE FROM server_member_roles WHERE server_id = ? AND user_id = ?`, serverID, userID,
+	); err != nil {
+		return fmt.Errorf("failed to remove member roles: %w", err)
+	}
+	if _, err := q.ExecContext(ctx,
+		`DELETE FROM channel_overwrites WHERE target_type = ? AND target_id = ?
+		AND channel_id IN (SELECT id FROM channels WHERE server_id = ?)`, OverwriteMember, userID, serverID,
+	); err != nil {
+		return fmt.Errorf("failed to remove member overwrites: %w", err)
+	}
+	if _, err := q.ExecContext(ctx,
+		`DELETE FROM server_members WHERE server_id = ? AND user_id = ?`, serverID, userID,
+	); err != nil {
+		return fmt.Errorf("failed to remove member: %w", err)
+	}
+	return nil
+}
+
+// SetMemberTimeout times a member out until the given time, or ends their timeout
+// when until is nil.
+// Complexity: O(1)
+func (r *Repository) SetMemberTimeout(ctx context.Context, serverID, userID string, until *time.Time) error {
+	var value any
+	if until != nil {
+		value = until.UTC()
+	}
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE server_members SET timeout_until = ? WHERE server_id = ? AND user_id = ?`,
+		value, serverID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set member timeout: %w", err)
+	}
+	r.logger.Info().Str("server_id", serverID).Str("user_id", userID).Bool("timed_out", until != nil).Msg("member timeout updated")
+	return nil
+}
+
+// ListMembers retrieves all members of a server with their user info and roles,
+// the owner first, then by highest role.
+// Complexity: O(n + a) where n = number of members, a = role assignments
 func (r *Repository) ListMembers(ctx context.Context, serverID string) ([]*Member, error) {
-	query := `SELECT sm.server_id, sm.user_id, u.username, COALESCE(u.avatar_url, ''), sm.role, sm.joined_at
-		FROM server_members sm
-		INNER JOIN users u ON sm.user_id = u.id
+	query := `SELECT ` + memberColumns + ` ` + memberJoins + `
 		WHERE sm.server_id = ?
 		ORDER BY
-			CASE sm.role
-				WHEN 'owner' THEN 0
-				WHEN 'admin' THEN 1
-				WHEN 'moderator' THEN 2
-				ELSE 3
-			END,
+			CASE WHEN s.owner_id = sm.user_id THEN 0 ELSE 1 END,
+			COALESCE((SELECT MAX(sr.position) FROM server_member_roles mr
+				INNER JOIN server_roles sr ON sr.id = mr.role_id
+				WHERE mr.server_id = sm.server_id AND mr.user_id = sm.user_id), 0) DESC,
 			sm.joined_at ASC`
 
 	rows, err := r.db.QueryContext(ctx, query, serverID)
@@ -275,49 +750,128 @@ func (r *Repository) ListMembers(ctx context.Context, serverID string) ([]*Membe
 	defer rows.Close()
 
 	var members []*Member
+	byUser := make(map[string]*Member)
 	for rows.Next() {
-		var m Member
-		if err := rows.Scan(&m.ServerID, &m.UserID, &m.Username, &m.Avatar, &m.Role, &m.JoinedAt); err != nil {
+		m, err := scanMember(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan member: %w", err)
 		}
-		members = append(members, &m)
+		members = append(members, m)
+		byUser[m.UserID] = m
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	err = r.eachMemberRole(ctx, `mr.server_id = ?`, []interface{}{serverID}, func(userID, roleID string) {
+		if m := byUser[userID]; m != nil {
+			m.Roles = append(m.Roles, roleID)
+		}
+	})
+	if err != nil {
+		return nil, err
 	}
-	return members, rows.Err()
+	return members, nil
 }
 
-// GetMember retrieves a specific member's role in a server.
-// Complexity: O(1)
+// GetMember retrieves a specific member of a server with their roles.
+// Complexity: O(r) where r = roles of the member
 func (r *Repository) GetMember(ctx context.Context, serverID, userID string) (*Member, error) {
-	query := `SELECT sm.server_id, sm.user_id, u.username, COALESCE(u.avatar_url, ''), sm.role, sm.joined_at
-		FROM server_members sm
-		INNER JOIN users u ON sm.user_id = u.id
-		WHERE sm.server_id = ? AND sm.user_id = ?`
-
-	var m Member
-	err := r.db.QueryRowContext(ctx, query, serverID, userID).Scan(
-		&m.ServerID, &m.UserID, &m.Username, &m.Avatar, &m.Role, &m.JoinedAt,
-	)
+	m, err := scanMember(r.db.QueryRowContext(ctx,
+		`SELECT `+memberColumns+` `+memberJoins+` WHERE sm.server_id = ? AND sm.user_id = ?`, serverID, userID))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get member: %w", err)
 	}
-	return &m, nil
+
+	err = r.eachMemberRole(ctx, `mr.server_id = ? AND mr.user_id = ?`, []interface{}{serverID, userID}, func(_, roleID string) {
+		m.Roles = append(m.Roles, roleID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// eachMemberRole calls fn for the role assignments matching where, highest role first.
+func (r *Repository) eachMemberRole(ctx context.Context, where string, args []interface{}, fn func(userID, roleID string)) error {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT mr.user_id, mr.role_id FROM server_member_roles mr
+		INNER JOIN server_roles sr ON sr.id = mr.role_id
+		WHERE `+where+` ORDER BY sr.position DESC`, args...)
+	if err != nil {
+		return fmt.Errorf("failed to list member roles: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID, roleID string
+		if err := rows.Scan(&userID, &roleID); err != nil {
+			return fmt.Errorf("failed to scan member role: %w", err)
+		}
+		fn(userID, roleID)
+	}
+	return rows.Err()
+}
+
+// AddMemberRole assigns a role to a member. Assigning a held role is a no-op.
+// Complexity: O(1)
+func (r *Repository) AddMemberRole(ctx context.Context, serverID, userID, roleID string) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO server_member_roles (server_id, user_id, role_id) VALUES (?, ?, ?)
+		ON CONFLICT (server_id, user_id, role_id) DO NOTHING`,
+		serverID, userID, roleID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to add member role: %w", err)
+	}
+	r.logger.Info().Str("server_id", serverID).Str("user_id", userID).Str("role_id", roleID).Msg("member role added")
+	return nil
 }
 
-// UpdateMemberRole changes a member's role.
+// RemoveMemberRole unassigns a role from a member.
 // Complexity: O(1)
-func (r *Repository) UpdateMemberRole(ctx context.Context, serverID, userID string, role Role) error {
-	query := `UPDATE server_members SET role = ? WHERE server_id = ? AND user_id = ?`
-	_, err := r.db.ExecContext(ctx, query, string(role), serverID, userID)
+func (r *Repository) RemoveMemberRole(ctx context.Context, serverID, userID, roleID string) error {
+	_, err := r.db.ExecContext(ctx,
+		`DELETE FROM server_member_roles WHERE server_id = ? AND user_id = ? AND role_id = ?`,
+		serverID, userID, roleID,
+	)
 	if err != nil {
-		return fmt.Errorf("failed to update member role: %w", err)
+		return fmt.Errorf("failed to remove member role: %w", err)
 	}
-	r.logger.Info().Str("server_id", serverID).Str("user_id", userID).Str("role", string(role)).Msg("member role updated")
+	r.logger.Info().Str("server_id", serverID).Str("user_id", userID).Str("role_id", roleID).Msg("member role removed")
 	return nil
 }
 
+// SwapMemberRoles unassigns the remove roles from a member and assigns add
+// (unless empty), atomically.
+// Complexity: O(len(remove))
+func (r *Repository) SwapMemberRoles(ctx context.Context, serverID, userID string, remove []string, add string) error {
+	return r.tx.InTransaction(ctx, func(q Querier) error {
+		for _, roleID := range remove {
+			if _, err := q.ExecContext(ctx,
+				`DELETE FROM server_member_roles WHERE server_id = ? AND user_id = ? AND role_id = ?`,
+				serverID, userID, roleID,
+			); err != nil {
+				return fmt.Errorf("failed to remove member role: %w", err)
+			}
+		}
+		if add == "" {
+			return nil
+		}
+		if _, err := q.ExecContext(ctx,
+			`INSERT INTO server_member_roles (server_id, user_id, role_id) VALUES (?, ?, ?)
+			ON CONFLICT (server_id, user_id, role_id) DO NOTHING`,
+			serverID, userID, add,
+		); err != nil {
+			return fmt.Errorf("failed to add member role: %w", err)
+		}
+		return nil
+	})
+}
+
 // CountMembers returns the number of members in a server.
 // Complexity: O(1)
 func (r *Repository) CountMembers(ctx context.Context, serverID string) (int, error) {
@@ -329,3 +883,904 @@ func (r *Repository) CountMembers(ctx context.Context, serverID string) (int, er
 	}
 	return count, nil
 }
+
+// --- Roles ---
+
+const roleColumns = `id, server_id, name, color, position, permissions, created_at`
+
+func scanRole(row scanner) (*Role, error) {
+	var role Role
+	var perms int64
+	if err := row.Scan(&role.ID, &role.ServerID, &role.Name, &role.Color, &role.Position, &perms, &role.CreatedAt); err != nil {
+		return nil, err
+	}
+	role.Permissions = Permission(uint64(perms))
+	role.Default = role.ID == DefaultRoleID(role.ServerID)
+	return &role, nil
+}
+
+// CreateRole inserts a role at role.Position, moving the roles at or above that
+// position of the same server up by one.
+// Complexity: O(k) where k = roles of the server
+func (r *Repository) CreateRole(ctx context.Context, role *Role) error {
+	err := r.tx.InTransaction(ctx, func(q Querier) error {
+		if _, err := q.ExecContext(ctx,
+			`UPDATE server_roles SET position = position + 1 WHERE server_id = ? AND position >= ?`,
+			role.ServerID, role.Position,
+		); err != nil {
+			return fmt.Errorf("failed to update role positions: %w", err)
+		}
+		if _, err := q.ExecContext(ctx,
+			`INSERT INTO server_roles (id, server_id, name, color, position, permissions, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)`,
+			role.ID, role.ServerID, role.Name, role.Color, role.Position, int64(role.Permissions),
+		); err != nil {
+			return fmt.Errorf("failed to create role: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	r.logger.Info().Str("role_id", role.ID).Str("server_id", role.ServerID).Msg("role created")
+	return nil
+}
+
+// GetRole retrieves a role by ID.
+// Complexity: O(1)
+func (r *Repository) GetRole(ctx context.Context, id string) (*Role, error) {
+	role, err := scanRole(r.db.QueryRowContext(ctx, `SELECT `+roleColumns+` FROM server_roles WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+	return role, nil
+}
+
+// ListRoles retrieves the roles of a server, highest first.
+// Complexity: O(k) where k = roles of the server
+func (r *Repository) ListRoles(ctx context.Context, serverID string) ([]*Role, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+roleColumns+` FROM server_roles WHERE server_id = ? ORDER BY position DESC, id`, serverID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+	defer rows.Close()
+
+	var roles []*Role
+	for rows.Next() {
+		role, err := scanRole(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan role: %w", err)
+		}
+		roles = append(roles, role)
+	}
+	return roles, rows.Err()
+}
+
+// UpdateRole sets the name, color and permissions of a role.
+// Complexity: O(1)
+func (r *Repository) UpdateRole(ctx context.Context, role *Role) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE server_roles SET name = ?, color = ?, permissions = ? WHERE id = ?`,
+		role.Name, role.Color, int64(role.Permissions), role.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update role: %w", err)
+	}
+	return nil
+}
+
+// DeleteRole removes a role, unassigns it from every member, drops its channel
+// overwrites and moves the roles above it down by one.
+// Complexity: O(k + a + o) where k = roles of the server, a = members holding the role,
+// o = channel overwrites of the role
+func (r *Repository) DeleteRole(ctx context.Context, serverID, id string) error {
+	err := r.tx.InTransaction(ctx, func(q Querier) error {
+		var position int
+		err := q.QueryRowContext(ctx,
+			`SELECT position FROM server_roles WHERE id = ? AND server_id = ?`, id, serverID,
+		).Scan(&position)
+		if err == sql.ErrNoRows {
+			return ErrRoleNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get role: %w", err)
+		}
+		if _, err := q.ExecContext(ctx, `DELETE FROM server_member_roles WHERE role_id = ?`, id); err != nil {
+			return fmt.Errorf("failed to unassign role: %w", err)
+		}
+		if _, err := q.ExecContext(ctx,
+			`DELETE FROM channel_overwrites WHERE target_type = ? AND target_id = ?`, OverwriteRole, id,
+		); err != nil {
+			return fmt.Errorf("failed to delete role overwrites: %w", err)
+		}
+		if _, err := q.ExecContext(ctx, `DELETE FROM server_roles WHERE id = ?`, id); err != nil {
+			return fmt.Errorf("failed to delete role: %w", err)
+		}
+		if _, err := q.ExecContext(ctx,
+			`UPDATE server_roles SET position = position - 1 WHERE server_id = ? AND position > ?`, serverID, position,
+		); err != nil {
+			return fmt.Errorf("failed to update role positions: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	r.logger.Info().Str("role_id", id).Str("server_id", serverID).Msg("role deleted")
+	return nil
+}
+
+// SetRolePositions gives the roles of a server, listed highest first and without
+// the default role, the positions len(ids)..1 in one transaction. The list must
+// contain each non-default role of the server exactly once; otherwise nothing is
+// changed and ErrRoleOrderMismatch is returned.
+// Complexity: O(k) where k = roles of the server
+func (r *Repository) SetRolePositions(ctx context.Context, serverID string, ids []string) error {
+	return r.tx.InTransaction(ctx, func(q Querier) error {
+		current, err := idSet(ctx, q, `SELECT id FROM server_roles WHERE server_id = ? AND id <> ?`, serverID, DefaultRoleID(serverID))
+		if err != nil {
+			return fmt.Errorf("failed to list roles: %w", err)
+		}
+		if len(ids) != len(current) {
+			return ErrRoleOrderMismatch
+		}
+		seen := make(map[string]bool, len(ids))
+		for _, id := range ids {
+			if !current[id] || seen[id] {
+				return ErrRoleOrderMismatch
+			}
+			seen[id] = true
+		}
+
+		for i, id := range ids {
+			if _, err := q.ExecContext(ctx,
+				`UPDATE server_roles SET position = ? WHERE id = ?`, len(ids)-i, id,
+			); err != nil {
+				return fmt.Errorf("failed to update role position: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// --- Channel Overwrites ---
+
+const overwriteColumns = `o.channel_id, o.target_type, o.target_id, o.allow, o.deny`
+
+func scanOverwrite(row scanner) (*Overwrite, error) {
+	var o Overwrite
+	var allow, deny int64
+	if err := row.Scan(&o.ChannelID, &o.TargetType, &o.TargetID, &allow, &deny); err != nil {
+		return nil, err
+	}
+	o.Allow = Permission(uint64(allow))
+	o.Deny = Permission(uint64(deny))
+	return &o, nil
+}
+
+// SetOverwrite creates or replaces the overwrite of a channel for a role or member.
+// Complexity: O(1)
+func (r *Repository) SetOverwrite(ctx context.Context, o *Overwrite) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO channel_overwrites (channel_id, target_type, target_id, allow, deny)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (channel_id, target_type, target_id) DO UPDATE SET allow = excluded.allow, deny = excluded.deny`,
+		o.ChannelID, o.TargetType, o.TargetID, int64(o.Allow), int64(o.Deny),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set channel overwrite: %w", err)
+	}
+	return nil
+}
+
+// DeleteOverwrite removes the overwrite of a channel for a role or member.
+// Complexity: O(1)
+func (r *Repository) DeleteOverwrite(ctx context.Context, channelID, targetType, targetID string) error {
+	_, err := r.db.ExecContext(ctx,
+		`DELETE FROM channel_overwrites WHERE channel_id = ? AND target_type = ? AND target_id = ?`,
+		channelID, targetType, targetID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete channel overwrite: %w", err)
+	}
+	return nil
+}
+
+// ListServerOverwrites retrieves the channel overwrites of every channel of a server.
+// Complexity: O(o) where o = overwrites of the server
+func (r *Repository) ListServerOverwrites(ctx context.Context, serverID string) ([]*Overwrite, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+overwriteColumns+` FROM channel_overwrites o
+		JOIN channels c ON c.id = o.channel_id
+		WHERE c.server_id = ?
+		ORDER BY o.channel_id, o.target_type DESC, o.target_id`, serverID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list channel overwrites: %w", err)
+	}
+	defer rows.Close()
+
+	var overwrites []*Overwrite
+	for rows.Next() {
+		o, err := scanOverwrite(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan channel overwrite: %w", err)
+		}
+		overwrites = append(overwrites, o)
+	}
+	return overwrites, rows.Err()
+}
+
+// --- Audit Log ---
+
+const auditColumns = `a.id, a.server_id, a.actor_id, COALESCE(u.username, ''), a.action,
+	a.target_type, a.target_id, COALESCE(a.details, ''), a.created_at`
+
+func scanAuditEntry(row scanner) (*AuditEntry, error) {
+	var e AuditEntry
+	var details string
+	if err := row.Scan(&e.ID, &e.ServerID, &e.ActorID, &e.ActorUsername, &e.Action,
+		&e.TargetType, &e.TargetID, &details, &e.CreatedAt); err != nil {
+		return nil, err
+	}
+	if details != "" {
+		e.Details = json.RawMessage(details)
+	}
+	return &e, nil
+}
+
+// CreateAuditEntry appends an entry to the audit log of e.ServerID.
+// Complexity: O(1)
+func (r *Repository) CreateAuditEntry(ctx context.Context, e *AuditEntry) error {
+	var details interface{}
+	if len(e.Details) > 0 {
+		details = string(e.Details)
+	}
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO audit_log (server_id, actor_id, action, target_type, target_id, details)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		e.ServerID, e.ActorID, e.Action, e.TargetType, e.TargetID, details,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create audit entry: %w", err)
+	}
+	return nil
+}
+
+// ListAuditLog retrieves the audit log of a server matching q, newest first.
+// Complexity: O(log n + l) where l = limit
+func (r *Repository) ListAuditLog(ctx context.Context, serverID string, q AuditQuery) ([]*AuditEntry, error) {
+	limit := q.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+
+	query := `SELECT ` + auditColumns + ` FROM audit_log a
+		LEFT JOIN users u ON u.id = a.actor_id
+		WHERE a.server_id = ?`
+	args := []interface{}{serverID}
+	for _, f := range []struct{ column, value string }{
+		{"a.actor_id", q.ActorID},
+		{"a.action", q.Action},
+		{"a.target_type", q.TargetType},
+		{"a.target_id", q.TargetID},
+	} {
+		if f.value != "" {
+			query += ` AND ` + f.column + ` = ?`
+			args = append(args, f.value)
+		}
+	}
+	if q.Before > 0 {
+		query += ` AND a.id < ?`
+		args = append(args, q.Before)
+	}
+	query += ` ORDER BY a.id DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit log: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*AuditEntry
+	for rows.Next() {
+		e, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// --- Webhooks ---
+
+// CreateWebhook inserts a new incoming webhook. TokenHash must be set.
+// Complexity: O(1)
+func (r *Repository) CreateWebhook(ctx context.Context, wh *Webhook) error {
+	query := `INSERT INTO webhooks (id, server_id, channel_id, name, avatar_url, token_hash, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)`
+
+	_, err := r.db.ExecContext(ctx, query, wh.ID, wh.ServerID, wh.ChannelID, wh.Name, wh.AvatarURL, wh.TokenHash, wh.CreatedBy)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	r.logger.Info().Str("webhook_id", wh.ID).Str("channel_id", wh.ChannelID).Msg("webhook created")
+	return nil
+}
+
+// GetWebhook retrieves a webhook, including its token hash, by ID.
+// Complexity: O(1)
+func (r *Repository) GetWebhook(ctx context.Context, id string) (*Webhook, error) {
+	query := `SELECT id, server_id, channel_id, name, avatar_url, token_hash, created_by, created_at
+		FROM webhooks WHERE id = ?`
+
+	var wh Webhook
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&wh.ID, &wh.ServerID, &wh.ChannelID, &wh.Name, &wh.AvatarURL, &wh.TokenHash, &wh.CreatedBy, &wh.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+	return &wh, nil
+}
+
+// ListWebhooks retrieves the webhooks of a server, oldest first.
+// Complexity: O(n) where n = number of webhooks in the server
+func (r *Repository) ListWebhooks(ctx context.Context, serverID string) ([]*Webhook, error) {
+	query := `SELECT id, server_id, channel_id, name, avatar_url, created_by, created_at
+		FROM webhooks WHERE server_id = ? ORDER BY created_at, id`
+
+	rows, err := r.db.QueryContext(ctx, query, serverID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	var webhooks []*Webhook
+	for rows.Next() {
+		var wh Webhook
+		if err := rows.Scan(&wh.ID, &wh.ServerID, &wh.ChannelID, &wh.Name, &wh.AvatarURL, &wh.CreatedBy, &wh.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook: %w", err)
+		}
+		webhooks = append(webhooks, &wh)
+	}
+	return webhooks, rows.Err()
+}
+
+// UpdateWebhook changes the name, avatar and target channel of a webhook.
+// Complexity: O(1)
+func (r *Repository) UpdateWebhook(ctx context.Context, wh *Webhook) error {
+	query := `UPDATE webhooks SET name = ?, avatar_url = ?, channel_id = ? WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, wh.Name, wh.AvatarURL, wh.ChannelID, wh.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook: %w", err)
+	}
+	return nil
+}
+
+// UpdateWebhookToken replaces the token hash of a webhook, invalidating the old URL.
+// Complexity: O(1)
+func (r *Repository) UpdateWebhookToken(ctx context.Context, id, tokenHash string) error {
+	query := `UPDATE webhooks SET token_hash = ? WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, tokenHash, id)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook token: %w", err)
+	}
+	r.logger.Info().Str("webhook_id", id).Msg("webhook token regenerated")
+	return nil
+}
+
+// DeleteWebhook removes a webhook. Messages it posted are kept.
+// Complexity: O(1)
+func (r *Repository) DeleteWebhook(ctx context.Context, id string) error {
+	query := `DELETE FROM webhooks WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	r.logger.Info().Str("webhook_id", id).Msg("webhook deleted")
+	return nil
+}
+
+// --- Invites ---
+
+// inviteColumns are the columns read by scanInvite.
+const inviteColumns = `code, server_id, creator_id, max_uses, uses, expires_at, vanity, created_at`
+
+func scanInvite(row scanner) (*Invite, error) {
+	var inv Invite
+	var expiresAt sql.NullTime
+	if err := row.Scan(
+		&inv.Code, &inv.ServerID, &inv.CreatorID, &inv.MaxUses, &inv.Uses, &expiresAt, &inv.Vanity, &inv.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if expiresAt.Valid {
+		t := expiresAt.Time.UTC()
+		inv.ExpiresAt = &t
+	}
+	return &inv, nil
+}
+
+// CreateInvite inserts an invite.
+// Complexity: O(1)
+func (r *Repository) CreateInvite(ctx context.Context, inv *Invite) error {
+	var expiresAt any
+	if inv.ExpiresAt != nil {
+		expiresAt = inv.ExpiresAt.UTC()
+	}
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO server_invites (code, server_id, creator_id, max_uses, uses, expires_at, vanity, created_at)
+		VALUES (?, ?, ?, ?, 0, ?, ?, CURRENT_TIMESTAMP)`,
+		inv.Code, inv.ServerID, inv.CreatorID, inv.MaxUses, expiresAt, inv.Vanity,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create invite: %w", err)
+	}
+	r.logger.Info().Str("server_id", inv.ServerID).Str("code", inv.Code).Msg("invite created")
+	return nil
+}
+
+// GetInvite retrieves an invite by code, whether or not it is still usable.
+// Complexity: O(1)
+func (r *Repository) GetInvite(ctx context.Context, code string) (*Invite, error) {
+	inv, err := scanInvite(r.db.QueryRowContext(ctx,
+		`SELECT `+inviteColumns+` FROM server_invites WHERE code = ?`, code,
+	))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invite: %w", err)
+	}
+	return inv, nil
+}
+
+// ListInvites retrieves the invites of a server that are still usable at now,
+// newest first.
+// Complexity: O(i) where i = invites of the server
+func (r *Repository) ListInvites(ctx context.Context, serverID string, now time.Time) ([]*Invite, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+inviteColumns+` FROM server_invites
+		WHERE server_id = ? AND (max_uses = 0 OR uses < max_uses) AND (expires_at IS NULL OR expires_at > ?)
+		ORDER BY created_at DESC, code ASC`,
+		serverID, now.UTC(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invites: %w", err)
+	}
+	defer rows.Close()
+
+	var invites []*Invite
+	for rows.Next() {
+		inv, err := scanInvite(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan invite: %w", err)
+		}
+		invites = append(invites, inv)
+	}
+	return invites, rows.Err()
+}
+
+// DeleteInvite removes an invite. Revoking the server's initial invite also clears
+// the server's invite code.
+// Complexity: O(1)
+func (r *Repository) DeleteInvite(ctx context.Context, code string) error {
+	err := r.tx.InTransaction(ctx, func(q Querier) error {
+		if _, err := q.ExecContext(ctx, `DELETE FROM server_invites WHERE code = ?`, code); err != nil {
+			return fmt.Errorf("failed to delete invite: %w", err)
+		}
+		if _, err := q.ExecContext(ctx, `UPDATE servers SET invite_code = NULL WHERE invite_code = ?`, code); err != nil {
+			return fmt.Errorf("failed to clear invite code: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	r.logger.Info().Str("code", code).Msg("invite deleted")
+	return nil
+}
+
+// UseInvite counts a use of an invite and adds userID to its server in one
+// transaction, assigning roleID as well when it is not empty. The use is only
+// counted while the invite is usable at now, so concurrent redemptions cannot
+// exceed its use limit; it returns false, adding no member, otherwise. Users
+// banned at now are turned away with ErrBanned by the same transaction.
+// Complexity: O(1)
+func (r *Repository) UseInvite(ctx context.Context, code, serverID, userID, roleID string, now time.Time) (bool, error) {
+	used := false
+	err := r.tx.InTransaction(ctx, func(q Querier) error {
+		res, err := q.ExecContext(ctx,
+			`UPDATE server_invites SET uses = uses + 1
+			WHERE code = ? AND server_id = ? AND (max_uses = 0 OR uses < max_uses) AND (expires_at IS NULL OR expires_at > ?)`,
+			code, serverID, now.UTC(),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to use invite: %w", err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to use invite: %w", err)
+		}
+		if n == 0 {
+			return nil
+		}
+		res, err = q.ExecContext(ctx,
+			`INSERT INTO server_members (server_id, user_id, joined_at)
+			SELECT id, ?, CURRENT_TIMESTAMP FROM servers
+			WHERE id = ? AND NOT EXISTS (
+				SELECT 1 FROM server_bans
+				WHERE server_id = ? AND user_id = ? AND (expires_at IS NULL OR expires_at > ?)
+			)`,
+			userID, serverID, serverID, userID, now.UTC(),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to add member: %w", err)
+		}
+		if n, err = res.RowsAffected(); err != nil {
+			return fmt.Errorf("failed to add member: %w", err)
+		}
+		if n == 0 {
+			return ErrBanned // rolls back the use
+		}
+		if roleID != "" {
+			if _, err := q.ExecContext(ctx,
+				`INSERT INTO server_member_roles (server_id, user_id, role_id) VALUES (?, ?, ?)`,
+				serverID, userID, roleID,
+			); err != nil {
+				return fmt.Errorf("failed to add member role: %w", err)
+			}
+		}
+		used = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	if used {
+		r.logger.Info().Str("server_id", serverID).Str("user_id", userID).Str("code", code).Msg("member added via invite")
+	}
+	return used, nil
+}
+
+// --- Bans ---
+
+// banColumns are the columns read by scanBan; they need the b and u aliases.
+const banColumns = `b.server_id, b.user_id, COALESCE(u.username, ''), b.actor_id, b.reason, b.expires_at, b.created_at`
+
+func scanBan(row scanner) (*Ban, error) {
+	var b Ban
+	var expiresAt sql.NullTime
+	if err := row.Scan(&b.ServerID, &b.UserID, &b.Username, &b.ActorID, &b.Reason, &expiresAt, &b.CreatedAt); err != nil {
+		return nil, err
+	}
+	if expiresAt.Valid {
+		t := expiresAt.Time.UTC()
+		b.ExpiresAt = &t
+	}
+	return &b, nil
+}
+
+// BanMember bans a user from a server, replacing any previous ban, and removes
+// their membership with its roles and channel overwrites in one transaction.
+// Complexity: O(r + o) where r = roles, o = channel overwrites of the member
+func (r *Repository) BanMember(ctx context.Context, ban *Ban) error {
+	var expiresAt any
+	if ban.ExpiresAt != nil {
+		expiresAt = ban.ExpiresAt.UTC()
+	}
+	err := r.tx.InTransaction(ctx, func(q Querier) error {
+		if _, err := q.ExecContext(ctx,
+			`INSERT INTO server_bans (server_id, user_id, actor_id, reason, expires_at, created_at)
+			VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+			ON CONFLICT (server_id, user_id) DO UPDATE SET
+				actor_id = excluded.actor_id, reason = excluded.reason,
+				expires_at = excluded.expires_at, created_at = excluded.created_at`,
+			ban.ServerID, ban.UserID, ban.ActorID, ban.Reason, expiresAt,
+		); err != nil {
+			return fmt.Errorf("failed to ban member: %w", err)
+		}
+		return deleteMember(ctx, q, ban.ServerID, ban.UserID)
+	})
+	if err != nil {
+		return err
+	}
+	r.logger.Info().Str("server_id", ban.ServerID).Str("user_id", ban.UserID).Msg("member banned")
+	return nil
+}
+
+// GetBan retrieves the ban of a user from a server if it is still in effect at now.
+// Complexity: O(1)
+func (r *Repository) GetBan(ctx context.Context, serverID, userID string, now time.Time) (*Ban, error) {
+	b, err := scanBan(r.db.QueryRowContext(ctx,
+		`SELECT `+banColumns+` FROM server_bans b LEFT JOIN users u ON u.id = b.user_id
+		WHERE b.server_id = ? AND b.user_id = ? AND (b.expires_at IS NULL OR b.expires_at > ?)`,
+		serverID, userID, now.UTC(),
+	))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ban: %w", err)
+	}
+	return b, nil
+}
+
+// ListBans retrieves the bans of a server still in effect at now, newest first.
+// Complexity: O(b) where b = bans of the server
+func (r *Repository) ListBans(ctx context.Context, serverID string, now time.Time) ([]*Ban, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+banColumns+` FROM server_bans b LEFT JOIN users u ON u.id = b.user_id
+		WHERE b.server_id = ? AND (b.expires_at IS NULL OR b.expires_at > ?)
+		ORDER BY b.created_at DESC, b.user_id ASC`,
+		serverID, now.UTC(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bans: %w", err)
+	}
+	defer rows.Close()
+
+	var bans []*Ban
+	for rows.Next() {
+		b, err := scanBan(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan ban: %w", err)
+		}
+		bans = append(bans, b)
+	}
+	return bans, rows.Err()
+}
+
+// DeleteBan lifts the ban of a user from a server.
+// Complexity: O(1)
+func (r *Repository) DeleteBan(ctx context.Context, serverID, userID string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM server_bans WHERE server_id = ? AND user_id = ?`, serverID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete ban: %w", err)
+	}
+	r.logger.Info().Str("server_id", serverID).Str("user_id", userID).Msg("ban lifted")
+	return nil
+}
+
+// --- Ownership Transfers ---
+
+// errStaleTransfer rolls back CompleteTransfer when the server changed hands or the
+// new owner left after the transfer was requested.
+var errStaleTransfer = errors.New("stale ownership transfer")
+
+// transferColumns are the columns read by scanTransfer.
+const transferColumns = `server_id, from_id, to_id, expires_at, created_at`
+
+func scanTransfer(row scanner) (*OwnershipTransfer, error) {
+	var t OwnershipTransfer
+	if err := row.Scan(&t.ServerID, &t.FromID, &t.ToID, &t.ExpiresAt, &t.CreatedAt); err != nil {
+		return nil, err
+	}
+	t.ExpiresAt = t.ExpiresAt.UTC()
+	return &t, nil
+}
+
+// SaveTransfer stores the pending ownership transfer of a server, replacing any
+// previous one.
+// Complexity: O(1)
+func (r *Repository) SaveTransfer(ctx context.Context, t *OwnershipTransfer) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO server_ownership_transfers (server_id, from_id, to_id, expires_at, created_at)
+		VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT (server_id) DO UPDATE SET
+			from_id = excluded.from_id, to_id = excluded.to_id,
+			expires_at = excluded.expires_at, created_at = excluded.created_at`,
+		t.ServerID, t.FromID, t.ToID, t.ExpiresAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save ownership transfer: %w", err)
+	}
+	r.logger.Info().Str("server_id", t.ServerID).Str("to_id", t.ToID).Msg("ownership transfer requested")
+	return nil
+}
+
+// GetTransfer retrieves the pending ownership transfer of a server if it has not
+// expired at now.
+// Complexity: O(1)
+func (r *Repository) GetTransfer(ctx context.Context, serverID string, now time.Time) (*OwnershipTransfer, error) {
+	t, err := scanTransfer(r.db.QueryRowContext(ctx,
+		`SELECT `+transferColumns+` FROM server_ownership_transfers WHERE server_id = ? AND expires_at > ?`,
+		serverID, now.UTC(),
+	))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ownership transfer: %w", err)
+	}
+	return t, nil
+}
+
+// DeleteTransfer discards the pending ownership transfer of a server.
+// Complexity: O(1)
+func (r *Repository) DeleteTransfer(ctx context.Context, serverID string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM server_ownership_transfers WHERE server_id = ?`, serverID)
+	if err != nil {
+		return fmt.Errorf("failed to delete ownership transfer: %w", err)
+	}
+	return nil
+}
+
+// CompleteTransfer hands a server from t.FromID to t.ToID in one transaction: it
+// consumes the pending transfer, changes the owner, gives the previous owner the
+// preset Admin role if the server still has it, and ends any timeout of the new
+// owner. It returns false, changing nothing, unless the transfer is still pending
+// and unexpired at now, t.FromID still owns the server and t.ToID is still a member.
+// Complexity: O(1)
+func (r *Repository) CompleteTransfer(ctx context.Context, t *OwnershipTransfer, now time.Time) (bool, error) {
+	done := false
+	err := r.tx.InTransaction(ctx, func(q Querier) error {
+		res, err := q.ExecContext(ctx,
+			`DELETE FROM server_ownership_transfers
+			WHERE server_id = ? AND from_id = ? AND to_id = ? AND expires_at > ?`,
+			t.ServerID, t.FromID, t.ToID, now.UTC(),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to consume ownership transfer: %w", err)
+		}
+		if n, err := res.RowsAffected(); err != nil || n == 0 {
+			return err
+		}
+
+		res, err = q.ExecContext(ctx,
+			`UPDATE servers SET owner_id = ? WHERE id = ? AND owner_id = ?
+			AND EXISTS (SELECT 1 FROM server_members WHERE server_id = ? AND user_id = ?)`,
+			t.ToID, t.ServerID, t.FromID, t.ServerID, t.ToID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to change owner: %w", err)
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return errStaleTransfer
+		}
+
+		if _, err := q.ExecContext(ctx,
+			`INSERT INTO server_member_roles (server_id, user_id, role_id)
+			SELECT r.server_id, sm.user_id, r.id FROM server_roles r
+			INNER JOIN server_members sm ON sm.server_id = r.server_id
+			WHERE r.id = ? AND sm.user_id = ?
+			ON CONFLICT (server_id, user_id, role_id) DO NOTHING`,
+			presetRoleID(t.ServerID, RoleAdmin), t.FromID,
+		); err != nil {
+			return fmt.Errorf("failed to demote previous owner: %w", err)
+		}
+		if _, err := q.ExecContext(ctx,
+			`UPDATE server_members SET timeout_until = NULL WHERE server_id = ? AND user_id = ?`,
+			t.ServerID, t.ToID,
+		); err != nil {
+			return fmt.Errorf("failed to clear timeout of new owner: %w", err)
+		}
+		done = true
+		return nil
+	})
+	if errors.Is(err, errStaleTransfer) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if done {
+		r.logger.Info().
+			Str("server_id", t.ServerID).
+			Str("from_id", t.FromID).
+			Str("to_id", t.ToID).
+			Msg("server ownership transferred")
+	}
+	return done, nil
+}
+
+// --- Templates ---
+
+const templateColumns = `code, server_id, creator_id, name, description, version, snapshot, created_at, updated_at`
+
+func scanTemplate(row scanner) (*Template, error) {
+	var t Template
+	var snapshot string
+	if err := row.Scan(&t.Code, &t.ServerID, &t.CreatorID, &t.Name, &t.Description, &t.Version,
+		&snapshot, &t.CreatedAt, &t.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(snapshot), &t.Snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode template snapshot: %w", err)
+	}
+	return &t, nil
+}
+
+// CreateTemplate inserts a template at version 1.
+// Complexity: O(1)
+func (r *Repository) CreateTemplate(ctx context.Context, t *Template) error {
+	snapshot, err := json.Marshal(t.Snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode template snapshot: %w", err)
+	}
+	_, err = r.db.ExecContext(ctx,
+		`INSERT INTO server_templates (code, server_id, creator_id, name, description, version, snapshot, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, 1, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
+		t.Code, t.ServerID, t.CreatorID, t.Name, t.Description, string(snapshot),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create template: %w", err)
+	}
+	r.logger.Info().Str("code", t.Code).Str("server_id", t.ServerID).Msg("template created")
+	return nil
+}
+
+// GetTemplate retrieves a template by code.
+// Complexity: O(1)
+func (r *Repository) GetTemplate(ctx context.Context, code string) (*Template, error) {
+	t, err := scanTemplate(r.db.QueryRowContext(ctx,
+		`SELECT `+templateColumns+` FROM server_templates WHERE code = ?`, code))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template: %w", err)
+	}
+	return t, nil
+}
+
+// ListTemplates retrieves the templates captured from a server, oldest first.
+// Complexity: O(t) where t = templates of the server
+func (r *Repository) ListTemplates(ctx context.Context, serverID string) ([]*Template, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+templateColumns+` FROM server_templates WHERE server_id = ? ORDER BY created_at, code`, serverID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []*Template
+	for rows.Next() {
+		t, err := scanTemplate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan template: %w", err)
+		}
+		templates = append(templates, t)
+	}
+	return templates, rows.Err()
+}
+
+// SyncTemplate replaces the snapshot of a template and increments its version.
+// Complexity: O(1)
+func (r *Repository) SyncTemplate(ctx context.Context, code string, snapshot *TemplateSnapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode template snapshot: %w", err)
+	}
+	_, err = r.db.ExecContext(ctx,
+		`UPDATE server_templates SET snapshot = ?, version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE code = ?`,
+		string(data), code,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to sync template: %w", err)
+	}
+	return nil
+}
+
+// DeleteTemplate removes a template.
+// Complexity: O(1)
+func (r *Repository) DeleteTemplate(ctx context.Context, code string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM server_templates WHERE code = ?`, code)
+	if err != nil {
+		return fmt.Errorf("failed to delete template: %w", err)
+	}
+	return nil
+}
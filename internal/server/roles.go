@@ -0,0 +1,376 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	maxRolesPerServer = 100
+	maxRoleName       = 100
+	maxRoleColor      = 0xFFFFFF
+)
+
+var (
+	// ErrRoleNotFound is returned for roles that do not exist or belong to another server.
+	ErrRoleNotFound = errors.New("role not found")
+	// ErrRoleOrderMismatch is returned when a role order does not list every role of the
+	// server except the default role exactly once, e.g. because it is stale.
+	ErrRoleOrderMismatch = errors.New("role order does not match the server's roles")
+)
+
+// DefaultRoleID returns the ID of the default role of a server, which every member
+// holds without it being assigned.
+func DefaultRoleID(serverID string) string {
+	return serverID + ":everyone"
+}
+
+// presetRoleID returns the ID of the preset role of a server matching a legacy
+// role name (RoleAdmin or RoleModerator).
+func presetRoleID(serverID, legacy string) string {
+	return serverID + ":" + legacy
+}
+
+// PresetRoles returns the roles a new server starts with: the default role and the
+// Moderator and Admin roles matching the legacy role names, lowest first.
+func PresetRoles(serverID string) []*Role {
+	return []*Role{
+		{ID: DefaultRoleID(serverID), ServerID: serverID, Name: "@everyone", Position: 0, Permissions: DefaultPermissions, Default: true},
+		{ID: presetRoleID(serverID, RoleModerator), ServerID: serverID, Name: "Moderator", Color: 0x3498DB, Position: 1, Permissions: ModeratorPermissions},
+		{ID: presetRoleID(serverID, RoleAdmin), ServerID: serverID, Name: "Admin", Color: 0xE74C3C, Position: 2, Permissions: AdminPermissions},
+	}
+}
+
+// standing is a member's effective permissions and rank in a server.
+type standing struct {
+	member *Member
+	perms  Permission
+	rank   int // position of the member's highest role; the owner outranks every role
+}
+
+// outranks reports whether the member ranks above a role or member at position rank.
+func (st *standing) outranks(rank int) bool {
+	return st.rank > rank
+}
+
+// ListRoles returns the roles of a server, highest first.
+func (s *Service) ListRoles(ctx context.Context, serverID string) ([]*Role, error) {
+	cacheKey := "roles:server:" + serverID
+	if val, ok := s.cache.Get(cacheKey); ok {
+		return val.([]*Role), nil
+	}
+	roles, err := s.repo.ListRoles(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.Set(cacheKey, roles, cacheTTL)
+	return roles, nil
+}
+
+// MemberPermissions returns the effective permissions of userID in serverID:
+// every permission for the owner, otherwise the union of the default role and the
+// member's roles. Returns 0 for non-members.
+func (s *Service) MemberPermissions(ctx context.Context, serverID, userID string) (Permission, error) {
+	st, err := s.standing(ctx, serverID, userID)
+	if err != nil || st == nil {
+		return 0, err
+	}
+	return st.perms, nil
+}
+
+// CreateRole creates a role just above the default role. The role cannot grant
+// permissions the caller lacks. Requires PermManageRoles.
+func (s *Service) CreateRole(ctx context.Context, serverID, userID, name string, color int, perms Permission) (*Role, error) {
+	actor, err := s.requireStanding(ctx, serverID, userID, PermManageRoles)
+	if err != nil {
+		return nil, err
+	}
+	role := &Role{
+		ID:          uuid.New().String(),
+		ServerID:    serverID,
+		Position:    1,
+		Color:       color,
+		Permissions: perms,
+	}
+	if role.Name, err = validateRoleName(name); err != nil {
+		return nil, err
+	}
+	if err := validateRole(role, actor, 0); err != nil {
+		return nil, err
+	}
+	roles, err := s.ListRoles(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+	if len(roles) >= maxRolesPerServer {
+		return nil, fmt.Errorf("a server can have at most %d roles", maxRolesPerServer)
+	}
+
+	if err := s.repo.CreateRole(ctx, role); err != nil {
+		return nil, err
+	}
+	s.cache.Delete("roles:server:" + serverID)
+	s.audit(ctx, serverID, userID, AuditRoleCreate, "role", role.ID, map[string]any{
+		"name":        role.Name,
+		"permissions": role.Permissions,
+	})
+	return s.repo.GetRole(ctx, role.ID)
+}
+
+// UpdateRole renames a role or changes its color or permissions. Only roles below
+// the caller's highest role can be changed, and they cannot gain permissions the
+// caller lacks; the default role cannot be renamed. Requires PermManageRoles.
+func (s *Service) UpdateRole(ctx context.Context, serverID, userID, roleID string, update RoleUpdate) (*Role, error) {
+	actor, role, err := s.managedRole(ctx, serverID, userID, roleID)
+	if err != nil {
+		return nil, err
+	}
+	previous := role.Permissions
+	if update.Name != nil {
+		if role.Default {
+			return nil, fmt.Errorf("the default role cannot be renamed")
+		}
+		if role.Name, err = validateRoleName(*update.Name); err != nil {
+			return nil, err
+		}
+	}
+	if update.Color != nil {
+		role.Color = *update.Color
+	}
+	if update.Permissions != nil {
+		role.Permissions = *update.Permissions
+	}
+	if err := validateRole(role, actor, previous); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateRole(ctx, role); err != nil {
+		return nil, err
+	}
+	s.cache.Delete("roles:server:" + serverID)
+	s.audit(ctx, serverID, userID, AuditRoleUpdate, "role", roleID, map[string]any{
+		"name":            role.Name,
+		"color":           role.Color,
+		"permissions":     role.Permissions,
+		"old_permissions": previous,
+	})
+	return role, nil
+}
+
+// DeleteRole removes a role below the caller's highest role and unassigns it from
+// every member. The default role cannot be deleted. Requires PermManageRoles.
+func (s *Service) DeleteRole(ctx context.Context, serverID, userID, roleID string) error {
+	_, role, err := s.managedRole(ctx, serverID, userID, roleID)
+	if err != nil {
+		return err
+	}
+	if role.Default {
+		return fmt.Errorf("the default role cannot be deleted")
+	}
+	if err := s.repo.DeleteRole(ctx, serverID, roleID); err != nil {
+		return err
+	}
+	s.cache.Delete("roles:server:" + serverID)
+	s.cache.Delete("members:server:" + serverID)
+	s.cache.Delete("overwrites:server:" + serverID)
+	s.audit(ctx, serverID, userID, AuditRoleDelete, "role", roleID, map[string]any{
+		"name": role.Name,
+	})
+	return nil
+}
+
+// ReorderRoles sets the order of every role of a server except the default role,
+// given highest first. Roles at or above the caller's highest role must keep their
+// positions. Requires PermManageRoles.
+func (s *Service) ReorderRoles(ctx context.Context, serverID, userID string, roleIDs []string) error {
+	actor, err := s.requireStanding(ctx, serverID, userID, PermManageRoles)
+	if err != nil {
+		return err
+	}
+	roles, err := s.repo.ListRoles(ctx, serverID)
+	if err != nil {
+		return err
+	}
+	positions := make(map[string]int, len(roles))
+	for _, role := range roles {
+		positions[role.ID] = role.Position
+	}
+	for i, id := range roleIDs {
+		current, ok := positions[id]
+		if ok && !actor.outranks(current) && current != len(roleIDs)-i {
+			return fmt.Errorf("cannot move a role at or above your highest role")
+		}
+	}
+
+	if err := s.repo.SetRolePositions(ctx, serverID, roleIDs); err != nil {
+		return err
+	}
+	s.cache.Delete("roles:server:" + serverID)
+	s.cache.Delete("members:server:" + serverID)
+	s.audit(ctx, serverID, userID, AuditRoleReorder, "server", serverID, map[string]any{
+		"roles": roleIDs,
+	})
+	return nil
+}
+
+// AddMemberRole assigns a role below the caller's highest role to a member ranked
+// below the caller (or to the caller). Requires PermManageRoles.
+func (s *Service) AddMemberRole(ctx context.Context, serverID, actorID, targetID, roleID string) error {
+	if _, err := s.assignableRole(ctx, serverID, actorID, targetID, roleID); err != nil {
+		return err
+	}
+	if err := s.repo.AddMemberRole(ctx, serverID, targetID, roleID); err != nil {
+		return err
+	}
+	s.cache.Delete("members:server:" + serverID)
+	s.audit(ctx, serverID, actorID, AuditMemberRoleAdd, "member", targetID, map[string]any{
+		"role_id": roleID,
+	})
+	return nil
+}
+
+// RemoveMemberRole unassigns a role below the caller's highest role from a member
+// ranked below the caller (or from the caller). Requires PermManageRoles.
+func (s *Service) RemoveMemberRole(ctx context.Context, serverID, actorID, targetID, roleID string) error {
+	if _, err := s.assignableRole(ctx, serverID, actorID, targetID, roleID); err != nil {
+		return err
+	}
+	if err := s.repo.RemoveMemberRole(ctx, serverID, targetID, roleID); err != nil {
+		return err
+	}
+	s.cache.Delete("members:server:" + serverID)
+	s.audit(ctx, serverID, actorID, AuditMemberRoleRemove, "member", targetID, map[string]any{
+		"role_id": roleID,
+	})
+	return nil
+}
+
+// standing computes the permissions and rank of userID in serverID, or nil for
+// non-members.
+func (s *Service) standing(ctx context.Context, serverID, userID string) (*standing, error) {
+	member, err := s.repo.GetMember(ctx, serverID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check permissions: %w", err)
+	}
+	if member == nil {
+		return nil, nil
+	}
+	if member.Role == RoleOwner {
+		return &standing{member: member, perms: AllPermissions, rank: math.MaxInt}, nil
+	}
+
+	roles, err := s.ListRoles(ctx, serverID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check permissions: %w", err)
+	}
+	held := make(map[string]bool, len(member.Roles))
+	for _, id := range member.Roles {
+		held[id] = true
+	}
+	st := &standing{member: member, perms: DefaultPermissions}
+	for _, role := range roles {
+		switch {
+		case role.Default:
+			st.perms = st.perms&^DefaultPermissions | role.Permissions
+		case held[role.ID]:
+			st.perms |= role.Permissions
+			st.rank = max(st.rank, role.Position)
+		}
+	}
+	if st.perms.Has(PermAdministrator) {
+		st.perms = AllPermissions
+	} else if member.TimeoutUntil != nil {
+		st.perms &^= TimeoutPermissions
+	}
+	return st, nil
+}
+
+// requireStanding returns the standing of a member of serverID holding perm.
+func (s *Service) requireStanding(ctx context.Context, serverID, userID string, perm Permission) (*standing, error) {
+	st, err := s.standing(ctx, serverID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if st == nil {
+		return nil, fmt.Errorf("not a member of this server")
+	}
+	if !st.perms.Has(perm) {
+		return nil, fmt.Errorf("insufficient permissions")
+	}
+	return st, nil
+}
+
+// managedRole loads a role of serverID below the highest role of userID, who must
+// hold PermManageRoles.
+func (s *Service) managedRole(ctx context.Context, serverID, userID, roleID string) (*standing, *Role, error) {
+	actor, err := s.requireStanding(ctx, serverID, userID, PermManageRoles)
+	if err != nil {
+		return nil, nil, err
+	}
+	role, err := s.repo.GetRole(ctx, roleID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if role == nil || role.ServerID != serverID {
+		return nil, nil, ErrRoleNotFound
+	}
+	if !actor.outranks(role.Position) {
+		return nil, nil, fmt.Errorf("cannot manage a role at or above your highest role")
+	}
+	return actor, role, nil
+}
+
+// assignableRole checks that actorID may assign or unassign roleID for targetID.
+func (s *Service) assignableRole(ctx context.Context, serverID, actorID, targetID, roleID string) (*Role, error) {
+	actor, role, err := s.managedRole(ctx, serverID, actorID, roleID)
+	if err != nil {
+		return nil, err
+	}
+	if role.Default {
+		return nil, fmt.Errorf("the default role cannot be assigned")
+	}
+	if targetID != actorID {
+		target, err := s.standing(ctx, serverID, targetID)
+		if err != nil {
+			return nil, err
+		}
+		if target == nil {
+			return nil, fmt.Errorf("target member not found")
+		}
+		if !actor.outranks(target.rank) {
+			return nil, fmt.Errorf("cannot modify a member with equal or higher role")
+		}
+	}
+	return role, nil
+}
+
+// validateRole checks the color of a role and that the permissions it gains over
+// previous are held by actor.
+func validateRole(role *Role, actor *standing, previous Permission) error {
+	if role.Color < 0 || role.Color > maxRoleColor {
+		return fmt.Errorf("role color must be between 0 and #FFFFFF")
+	}
+	if role.Permissions&^AllPermissions != 0 {
+		return fmt.Errorf("unknown permissions")
+	}
+	if gained := role.Permissions &^ previous; gained&^actor.perms != 0 {
+		return fmt.Errorf("cannot grant permissions you do not have")
+	}
+	return nil
+}
+
+func validateRoleName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("role name cannot be empty")
+	}
+	if len(name) > maxRoleName {
+		return "", fmt.Errorf("role name cannot exceed %d characters", maxRoleName)
+	}
+	return name, nil
+}
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// roleNames returns the names of the roles of srv-1, highest first.
+func roleNames(t *testing.T, svc *Service) []string {
+	t.Helper()
+	roles, err := svc.ListRoles(context.Background(), "srv-1")
+	require.NoError(t, err)
+	var names []string
+	for _, role := range roles {
+		names = append(names, role.Name)
+	}
+	return names
+}
+
+func TestMemberPermissions(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	perms, err := svc.MemberPermissions(ctx, "srv-1", "owner")
+	require.NoError(t, err)
+	assert.Equal(t, AllPermissions, perms)
+	perms, err = svc.MemberPermissions(ctx, "srv-1", "admin")
+	require.NoError(t, err)
+	assert.Equal(t, AdminPermissions, perms)
+	perms, err = svc.MemberPermissions(ctx, "srv-1", "member")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultPermissions, perms)
+	perms, err = svc.MemberPermissions(ctx, "srv-2", "member")
+	require.NoError(t, err)
+	assert.Zero(t, perms, "non-members have no permissions")
+
+	// Permissions are the union of every role held
+	emoji, err := svc.CreateRole(ctx, "srv-1", "owner", "Emoji", 0, PermManageEmoji)
+	require.NoError(t, err)
+	require.NoError(t, svc.AddMemberRole(ctx, "srv-1", "owner", "member", emoji.ID))
+	require.NoError(t, svc.UpdateMemberRole(ctx, "srv-1", "owner", "member", RoleModerator))
+	perms, err = svc.MemberPermissions(ctx, "srv-1", "member")
+	require.NoError(t, err)
+	assert.Equal(t, ModeratorPermissions|PermManageEmoji, perms)
+
+	m, err := svc.repo.GetMember(ctx, "srv-1", "member")
+	require.NoError(t, err)
+	assert.Equal(t, RoleModerator, m.Role)
+	assert.Equal(t, []string{presetRoleID("srv-1", RoleModerator), emoji.ID}, m.Roles, "highest first")
+
+	// The default role applies to everyone; Administrator grants everything
+	everyone := PermViewChannel | PermSendMessages
+	_, err = svc.UpdateRole(ctx, "srv-1", "owner", DefaultRoleID("srv-1"), RoleUpdate{Permissions: &everyone})
+	require.NoError(t, err)
+	ok, err := svc.CanSendMessages(ctx, "ch-1", "member")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	admin := PermAdministrator
+	_, err = svc.UpdateRole(ctx, "srv-1", "owner", emoji.ID, RoleUpdate{Permissions: &admin})
+	require.NoError(t, err)
+	perms, err = svc.MemberPermissions(ctx, "srv-1", "member")
+	require.NoError(t, err)
+	assert.Equal(t, AllPermissions, perms)
+}
+
+func TestRoles_Lifecycle(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	_, err := svc.CreateRole(ctx, "srv-1", "member", "Helpers", 0, PermSendMessages)
+	assert.Error(t, err, "members cannot manage roles")
+	_, err = svc.CreateRole(ctx, "srv-1", "admin", " ", 0, PermSendMessages)
+	assert.Error(t, err)
+	_, err = svc.CreateRole(ctx, "srv-1", "admin", "Helpers", 0x1000000, PermSendMessages)
+	assert.Error(t, err)
+	_, err = svc.CreateRole(ctx, "srv-1", "admin", "Helpers", 0, PermManageServer)
+	assert.Error(t, err, "cannot grant permissions the caller lacks")
+
+	helpers, err := svc.CreateRole(ctx, "srv-1", "admin", " Helpers ", 0x2ECC71, PermManageMessages)
+	require.NoError(t, err)
+	assert.Equal(t, "Helpers", helpers.Name)
+	assert.Equal(t, 1, helpers.Position)
+	assert.Equal(t, []string{"Admin", "Moderator", "Helpers", "@everyone"}, roleNames(t, svc))
+
+	name := "Support"
+	updated, err := svc.UpdateRole(ctx, "srv-1", "admin", helpers.ID, RoleUpdate{Name: &name})
+	require.NoError(t, err)
+	assert.Equal(t, "Support", updated.Name)
+	assert.Equal(t, PermManageMessages, updated.Permissions)
+	_, err = svc.UpdateRole(ctx, "srv-1", "admin", DefaultRoleID("srv-1"), RoleUpdate{Name: &name})
+	assert.Error(t, err, "the default role cannot be renamed")
+	_, err = svc.UpdateRole(ctx, "srv-1", "admin", presetRoleID("srv-1", RoleAdmin), RoleUpdate{Name: &name})
+	assert.Error(t, err, "cannot manage the caller's own highest role")
+	_, err = svc.UpdateRole(ctx, "srv-1", "admin", "nope", RoleUpdate{Name: &name})
+	assert.ErrorIs(t, err, ErrRoleNotFound)
+
+	require.NoError(t, svc.AddMemberRole(ctx, "srv-1", "admin", "member", helpers.ID))
+	assert.Error(t, svc.AddMemberRole(ctx, "srv-1", "admin", "member", DefaultRoleID("srv-1")))
+	assert.Error(t, svc.DeleteRole(ctx, "srv-1", "admin", DefaultRoleID("srv-1")))
+	require.NoError(t, svc.DeleteRole(ctx, "srv-1", "admin", helpers.ID))
+	assert.Equal(t, []string{"Admin", "Moderator", "@everyone"}, roleNames(t, svc))
+	members, err := svc.ListMembers(ctx, "srv-1")
+	require.NoError(t, err)
+	for _, m := range members {
+		assert.NotContains(t, m.Roles, helpers.ID, "deleted roles are unassigned")
+	}
+	assert.ErrorIs(t, svc.DeleteRole(ctx, "srv-1", "admin", helpers.ID), ErrRoleNotFound)
+}
+
+func TestRoles_Hierarchy(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+	admin := presetRoleID("srv-1", RoleAdmin)
+	moderator := presetRoleID("srv-1", RoleModerator)
+
+	assert.Error(t, svc.AddMemberRole(ctx, "srv-1", "admin", "member", admin), "cannot assign the caller's highest role")
+	require.NoError(t, svc.AddMemberRole(ctx, "srv-1", "admin", "member", moderator))
+	assert.Error(t, svc.KickMember(ctx, "srv-1", "member", "admin"))
+	assert.Error(t, svc.KickMember(ctx, "srv-1", "admin", "owner"))
+	assert.Error(t, svc.UpdateMemberRole(ctx, "srv-1", "admin", "member", RoleAdmin))
+
+	// An admin moved below the moderator role loses rank over moderators
+	assert.Error(t, svc.ReorderRoles(ctx, "srv-1", "admin", []string{moderator, admin}), "cannot move its own highest role")
+	require.NoError(t, svc.ReorderRoles(ctx, "srv-1", "owner", []string{moderator, admin}))
+	assert.Equal(t, []string{"Moderator", "Admin", "@everyone"}, roleNames(t, svc))
+	assert.Error(t, svc.RemoveMemberRole(ctx, "srv-1", "admin", "member", moderator))
+	require.NoError(t, svc.RemoveMemberRole(ctx, "srv-1", "owner", "member", moderator))
+
+	for name, bad := range map[string][]string{
+		"missing role":   {admin},
+		"duplicate role": {admin, admin},
+		"default role":   {admin, moderator, DefaultRoleID("srv-1")},
+		"unknown role":   {admin, "nope"},
+	} {
+		assert.ErrorIs(t, svc.ReorderRoles(ctx, "srv-1", "owner", bad), ErrRoleOrderMismatch, name)
+	}
+}
@@ -2,8 +2,6 @@ package server
 
 import (
 	"context"
-	"crypto/rand"
-	"encoding/base32"
 	"fmt"
 	"strings"
 	"time"
@@ -16,15 +14,32 @@ import (
 
 const (
 	cacheTTL = 5 * time.Minute
+
+	// MaxSlowModeSeconds caps the per-channel slow mode interval (6 hours).
+	MaxSlowModeSeconds = 6 * 60 * 60
 )
 
 // Service orchestrates server management operations.
 type Service struct {
 	repo   *Repository
 	cache  *cache.LRU
+	events EventSink     // optional, notified after membership changes
+	voice  VoiceSessions // optional, disconnects kicked, banned and timed out members
 	logger zerolog.Logger
 }
 
+// EventSink receives server events, e.g. to forward them to outgoing webhooks.
+// Calls are synchronous and must not block for long.
+type EventSink interface {
+	MemberJoined(ctx context.Context, serverID, userID string)
+}
+
+// VoiceSessions ends the voice connections of members who lose access to a server.
+type VoiceSessions interface {
+	// DisconnectUser drops every voice connection of userID in the channels of serverID.
+	DisconnectUser(serverID, userID string) int
+}
+
 // NewService creates a new server management service.
 func NewService(repo *Repository, cache *cache.LRU, logger zerolog.Logger) *Service {
 	return &Service{
@@ -34,9 +49,26 @@ func NewService(repo *Repository, cache *cache.LRU, logger zerolog.Logger) *Serv
 	}
 }
 
-// CreateServer creates a new server with a default #general channel.
-// The creator becomes the owner.
+// SetEventSink registers the receiver of server events.
+func (s *Service) SetEventSink(sink EventSink) {
+	s.events = sink
+}
+
+// SetVoiceSessions registers the voice server to disconnect members from when they
+// are kicked, banned or timed out.
+func (s *Service) SetVoiceSessions(v VoiceSessions) {
+	s.voice = v
+}
+
+// CreateServer creates a new server with a default #general channel and the
+// preset roles. The creator becomes the owner.
 func (s *Service) CreateServer(ctx context.Context, name, ownerID string) (*Server, error) {
+	return s.createServer(ctx, name, ownerID, defaultSnapshot())
+}
+
+// createServer creates a server with the roles, categories and channels of snap.
+// The creator becomes the owner.
+func (s *Service) createServer(ctx context.Context, name, ownerID string, snap *TemplateSnapshot) (*Server, error) {
 	if strings.TrimSpace(name) == "" {
 		return nil, fmt.Errorf("server name cannot be empty")
 	}
@@ -44,7 +76,7 @@ func (s *Service) CreateServer(ctx context.Context, name, ownerID string) (*Serv
 		return nil, fmt.Errorf("server name cannot exceed 100 characters")
 	}
 
-	inviteCode, err := generateInviteCode()
+	inviteCode, err := GenerateInviteCode()
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate invite code: %w", err)
 	}
@@ -52,43 +84,16 @@ func (s *Service) CreateServer(ctx context.Context, name, ownerID string) (*Serv
 	srv := &Server{
 		ID:         uuid.New().String(),
 		Name:       strings.TrimSpace(name),
+		IconURL:    snap.IconURL,
 		OwnerID:    ownerID,
 		InviteCode: inviteCode,
 	}
 
-	if err := s.repo.CreateServer(ctx, srv); err != nil {
+	roles, categories, channels, overwrites := buildStructure(srv.ID, snap)
+	if err := s.repo.CreateServer(ctx, srv, roles, categories, channels, overwrites); err != nil {
 		return nil, fmt.Errorf("failed to create server: %w", err)
 	}
 
-	// Add creator as owner
-	if err := s.repo.AddMember(ctx, srv.ID, ownerID, RoleOwner); err != nil {
-		return nil, fmt.Errorf("failed to add owner: %w", err)
-	}
-
-	// Create default #general text channel
-	generalCh := &Channel{
-		ID:       uuid.New().String(),
-		ServerID: srv.ID,
-		Name:     "general",
-		Type:     "text",
-		Position: 0,
-	}
-	if err := s.repo.CreateChannel(ctx, generalCh); err != nil {
-		return nil, fmt.Errorf("failed to create default channel: %w", err)
-	}
-
-	// Create default General voice channel
-	voiceCh := &Channel{
-		ID:       uuid.New().String(),
-		ServerID: srv.ID,
-		Name:     "General",
-		Type:     "voice",
-		Position: 1,
-	}
-	if err := s.repo.CreateChannel(ctx, voiceCh); err != nil {
-		return nil, fmt.Errorf("failed to create default voice channel: %w", err)
-	}
-
 	// Invalidate user servers cache
 	s.cache.DeletePrefix("servers:user:" + ownerID)
 
@@ -96,7 +101,8 @@ func (s *Service) CreateServer(ctx context.Context, name, ownerID string) (*Serv
 		Str("server_id", srv.ID).
 		Str("name", srv.Name).
 		Str("owner_id", ownerID).
-		Msg("server created with default channels")
+		Int("channels", len(channels)).
+		Msg("server created")
 
 	return srv, nil
 }
@@ -140,6 +146,13 @@ func (s *Service) UpdateServer(ctx context.Context, serverID, userID, name, icon
 	if strings.TrimSpace(name) == "" {
 		return fmt.Errorf("server name cannot be empty")
 	}
+	srv, err := s.repo.GetServer(ctx, serverID)
+	if err != nil {
+		return err
+	}
+	if srv == nil {
+		return fmt.Errorf("server not found")
+	}
 
 	if err := s.repo.UpdateServer(ctx, serverID, strings.TrimSpace(name), iconURL); err != nil {
 		return err
@@ -147,6 +160,11 @@ func (s *Service) UpdateServer(ctx context.Context, serverID, userID, name, icon
 
 	s.cache.Delete("server:" + serverID)
 	s.cache.DeletePrefix("servers:user:")
+	s.audit(ctx, serverID, userID, AuditServerUpdate, "server", serverID, map[string]any{
+		"old_name": srv.Name,
+		"name":     strings.TrimSpace(name),
+		"icon_url": iconURL,
+	})
 	return nil
 }
 
@@ -171,12 +189,15 @@ func (s *Service) DeleteServer(ctx context.Context, serverID, userID string) err
 	s.cache.DeletePrefix("servers:user:")
 	s.cache.DeletePrefix("channels:server:" + serverID)
 	s.cache.DeletePrefix("members:server:" + serverID)
+	s.cache.Delete("roles:server:" + serverID)
+	s.cache.Delete("overwrites:server:" + serverID)
 	return nil
 }
 
 // --- Channels ---
 
-// CreateChannel creates a new channel. Requires PermManageChannels.
+// CreateChannel creates a new channel after the last uncategorized channel.
+// Requires PermManageChannels.
 func (s *Service) CreateChannel(ctx context.Context, serverID, userID, name, chType string) (*Channel, error) {
 	if err := s.requirePermission(ctx, serverID, userID, PermManageChannels); err != nil {
 		return nil, err
@@ -201,6 +222,10 @@ func (s *Service) CreateChannel(ctx context.Context, serverID, userID, name, chT
 	}
 
 	s.cache.Delete("channels:server:" + serverID)
+	s.audit(ctx, serverID, userID, AuditChannelCreate, "channel", ch.ID, map[string]any{
+		"name": ch.Name,
+		"type": ch.Type,
+	})
 	return ch, nil
 }
 
@@ -218,12 +243,70 @@ func (s *Service) ListChannels(ctx context.Context, serverID string) ([]*Channel
 	return channels, nil
 }
 
-// UpdateChannel updates a channel. Requires PermManageChannels.
-func (s *Service) UpdateChannel(ctx context.Context, serverID, userID, channelID, name, chType string, position int) error {
+// UpdateChannel renames a channel, changes its type or moves it to the end of
+// another category ("" for the uncategorized channels). Requires PermManageChannels.
+func (s *Service) UpdateChannel(ctx context.Context, serverID, userID, channelID string, update ChannelUpdate) (*Channel, error) {
 	if err := s.requirePermission(ctx, serverID, userID, PermManageChannels); err != nil {
-		return err
+		return nil, err
+	}
+	ch, err := s.repo.GetChannel(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	if ch == nil || ch.ServerID != serverID {
+		return nil, ErrChannelNotFound
+	}
+
+	name, chType := ch.Name, ch.Type
+	if update.Name != nil {
+		name = strings.TrimSpace(*update.Name)
+		if name == "" {
+			return nil, fmt.Errorf("channel name cannot be empty")
+		}
+	}
+	if update.Type != nil {
+		chType = *update.Type
+		if chType != "text" && chType != "voice" {
+			return nil, fmt.Errorf("channel type must be 'text' or 'voice'")
+		}
+	}
+	if update.CategoryID != nil && *update.CategoryID != "" {
+		c, err := s.repo.GetCategory(ctx, *update.CategoryID)
+		if err != nil {
+			return nil, err
+		}
+		if c == nil || c.ServerID != serverID {
+			return nil, ErrCategoryNotFound
+		}
+	}
+
+	if name != ch.Name || chType != ch.Type {
+		if err := s.repo.UpdateChannel(ctx, channelID, name, chType); err != nil {
+			return nil, err
+		}
+	}
+	if update.CategoryID != nil && *update.CategoryID != ch.CategoryID {
+		if err := s.repo.MoveChannel(ctx, serverID, channelID, *update.CategoryID); err != nil {
+			return nil, err
+		}
 	}
-	return s.repo.UpdateChannel(ctx, channelID, name, chType, position)
+	s.cache.Delete("channels:server:" + serverID)
+	s.cache.Delete("channel:" + channelID)
+
+	updated, err := s.repo.GetChannel(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, ErrChannelNotFound
+	}
+	s.audit(ctx, serverID, userID, AuditChannelUpdate, "channel", channelID, map[string]any{
+		"old_name":    ch.Name,
+		"name":        updated.Name,
+		"type":        updated.Type,
+		"category_id": updated.CategoryID,
+	})
+	return updated, nil
 }
 
 // DeleteChannel removes a channel. Requires PermManageChannels.
@@ -231,13 +314,167 @@ func (s *Service) DeleteChannel(ctx context.Context, serverID, userID, channelID
 	if err := s.requirePermission(ctx, serverID, userID, PermManageChannels); err != nil {
 		return err
 	}
+	ch, err := s.repo.GetChannel(ctx, channelID)
+	if err != nil {
+		return err
+	}
+	if ch == nil || ch.ServerID != serverID {
+		return ErrChannelNotFound
+	}
 	if err := s.repo.DeleteChannel(ctx, channelID); err != nil {
 		return err
 	}
 	s.cache.Delete("channels:server:" + serverID)
+	s.cache.Delete("channel:" + channelID)
+	s.cache.Delete("overwrites:server:" + serverID)
+	s.audit(ctx, serverID, userID, AuditChannelDelete, "channel", channelID, map[string]any{
+		"name": ch.Name,
+		"type": ch.Type,
+	})
 	return nil
 }
 
+// SetSlowMode configures the minimum interval between messages per user in a channel.
+// Zero disables slow mode. Requires PermManageChannels.
+func (s *Service) SetSlowMode(ctx context.Context, serverID, userID, channelID string, seconds int) error {
+	if err := s.requirePermission(ctx, serverID, userID, PermManageChannels); err != nil {
+		return err
+	}
+	if seconds < 0 || seconds > MaxSlowModeSeconds {
+		return fmt.Errorf("slow mode must be between 0 and %d seconds", MaxSlowModeSeconds)
+	}
+
+	ch, err := s.repo.GetChannel(ctx, channelID)
+	if err != nil {
+		return err
+	}
+	if ch == nil || ch.ServerID != serverID {
+		return fmt.Errorf("channel not found")
+	}
+
+	if err := s.repo.UpdateChannelSlowMode(ctx, channelID, seconds); err != nil {
+		return err
+	}
+	s.cache.Delete("channels:server:" + serverID)
+	s.cache.Delete("channel:" + channelID)
+	s.audit(ctx, serverID, userID, AuditChannelUpdate, "channel", channelID, map[string]any{
+		"slow_mode": seconds,
+	})
+	return nil
+}
+
+// SlowModeFor returns the slow mode interval of a channel and whether the user
+// may bypass it. Members with PermManageMessages (moderators and above) bypass slow mode.
+// Implements chat.SlowModePolicy.
+func (s *Service) SlowModeFor(ctx context.Context, channelID, userID string) (time.Duration, bool, error) {
+	ch, err := s.getChannel(ctx, channelID)
+	if err != nil {
+		return 0, false, err
+	}
+	if ch == nil || ch.SlowMode <= 0 {
+		return 0, false, nil
+	}
+
+	perms, err := s.MemberPermissions(ctx, ch.ServerID, userID)
+	if err != nil {
+		return 0, false, err
+	}
+	bypass := perms.Has(PermManageMessages)
+	return time.Duration(ch.SlowMode) * time.Second, bypass, nil
+}
+
+// CanSendMessages reports whether userID can see the channel and holds
+// PermSendMessages in it. Unknown channels are never writable.
+// Implements chat.SendPolicy.
+func (s *Service) CanSendMessages(ctx context.Context, channelID, userID string) (bool, error) {
+	ch, err := s.getChannel(ctx, channelID)
+	if err != nil {
+		return false, err
+	}
+	if ch == nil || ch.Type != "text" {
+		return false, nil
+	}
+
+	perms, err := s.ChannelPermissions(ctx, channelID, userID)
+	if err != nil {
+		return false, err
+	}
+	return perms.Has(PermViewChannel | PermSendMessages), nil
+}
+
+// CanViewChannel reports whether userID can see channelID, after overwrites.
+// Implements chat.SendPolicy.
+func (s *Service) CanViewChannel(ctx context.Context, channelID, userID string) (bool, error) {
+	perms, err := s.ChannelPermissions(ctx, channelID, userID)
+	if err != nil {
+		return false, err
+	}
+	return perms.Has(PermViewChannel), nil
+}
+
+// ReadableChannels returns ID -> name of the text channels userID can see in serverID,
+// or in every server the user belongs to when serverID is empty.
+// Implements chat.ChannelAccess.
+func (s *Service) ReadableChannels(ctx context.Context, userID, serverID string) (map[string]string, error) {
+	var serverIDs []string
+	if serverID != "" {
+		serverIDs = []string{serverID}
+	} else {
+		servers, err := s.ListUserServers(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		for _, srv := range servers {
+			serverIDs = append(serverIDs, srv.ID)
+		}
+	}
+
+	readable := make(map[string]string)
+	for _, id := range serverIDs {
+		st, err := s.standing(ctx, id, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check membership: %w", err)
+		}
+		if st == nil {
+			if serverID != "" {
+				return nil, fmt.Errorf("not a member of this server")
+			}
+			continue
+		}
+		channels, err := s.visibleChannels(ctx, id, st)
+		if err != nil {
+			return nil, err
+		}
+		for _, ch := range channels {
+			if ch.Type == "text" {
+				readable[ch.ID] = ch.Name
+			}
+		}
+	}
+	return readable, nil
+}
+
+// GetChannel retrieves a channel by ID. Returns nil if not found.
+func (s *Service) GetChannel(ctx context.Context, channelID string) (*Channel, error) {
+	return s.getChannel(ctx, channelID)
+}
+
+// getChannel retrieves a channel by ID through the cache.
+func (s *Service) getChannel(ctx context.Context, channelID string) (*Channel, error) {
+	cacheKey := "channel:" + channelID
+	if val, ok := s.cache.Get(cacheKey); ok {
+		return val.(*Channel), nil
+	}
+	ch, err := s.repo.GetChannel(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	if ch != nil {
+		s.cache.Set(cacheKey, ch, cacheTTL)
+	}
+	return ch, nil
+}
+
 // --- Members ---
 
 // ListMembers returns all members of a server.
@@ -254,24 +491,29 @@ func (s *Service) ListMembers(ctx context.Context, serverID string) ([]*Member,
 	return members, nil
 }
 
+// IsMember reports whether userID belongs to serverID.
+func (s *Service) IsMember(ctx context.Context, serverID, userID string) (bool, error) {
+	member, err := s.repo.GetMember(ctx, serverID, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check membership: %w", err)
+	}
+	return member != nil, nil
+}
+
 // KickMember removes a member from a server. Requires PermManageMembers.
-// Cannot kick someone with a higher or equal role.
+// Cannot kick someone whose highest role is equal to or above the caller's.
 func (s *Service) KickMember(ctx context.Context, serverID, actorID, targetID string) error {
-	if err := s.requirePermission(ctx, serverID, actorID, PermManageMembers); err != nil {
+	actor, err := s.requireStanding(ctx, serverID, actorID, PermManageMembers)
+	if err != nil {
 		return err
 	}
 
-	actor, err := s.repo.GetMember(ctx, serverID, actorID)
-	if err != nil || actor == nil {
-		return fmt.Errorf("actor not found")
-	}
-
-	target, err := s.repo.GetMember(ctx, serverID, targetID)
+	target, err := s.standing(ctx, serverID, targetID)
 	if err != nil || target == nil {
 		return fmt.Errorf("target member not found")
 	}
 
-	if RoleHierarchy(actor.Role) <= RoleHierarchy(target.Role) {
+	if !actor.outranks(target.rank) {
 		return fmt.Errorf("cannot kick a member with equal or higher role")
 	}
 
@@ -279,148 +521,83 @@ func (s *Service) KickMember(ctx context.Context, serverID, actorID, targetID st
 		return err
 	}
 	s.cache.Delete("members:server:" + serverID)
+	s.cache.Delete("overwrites:server:" + serverID)
 	s.cache.DeletePrefix("servers:user:" + targetID)
+	s.disconnectVoice(serverID, targetID)
+	s.audit(ctx, serverID, actorID, AuditMemberKick, "member", targetID, nil)
 	return nil
 }
 
-// UpdateMemberRole changes a member's role. Requires PermManageMembers.
-// Cannot promote above your own role or demote someone with higher role.
-func (s *Service) UpdateMemberRole(ctx context.Context, serverID, actorID, targetID string, newRole Role) error {
-	if err := s.requirePermission(ctx, serverID, actorID, PermManageMembers); err != nil {
-		return err
-	}
-
-	if newRole == RoleOwner {
-		return fmt.Errorf("cannot assign owner role directly")
+// UpdateMemberRole sets a member's legacy role: RoleAdmin and RoleModerator assign
+// the matching preset role and RoleMember removes both presets; other roles are kept.
+// Requires PermManageRoles. Cannot modify a member ranked at or above the caller,
+// or assign a preset role at or above the caller's highest role.
+func (s *Service) UpdateMemberRole(ctx context.Context, serverID, actorID, targetID, newRole string) error {
+	switch newRole {
+	case RoleOwner:
+		return fmt.Errorf("cannot assign owner role directly; transfer ownership instead")
+	case RoleAdmin, RoleModerator, RoleMember:
+	default:
+		return fmt.Errorf("invalid role: %q", newRole)
 	}
 
-	actor, err := s.repo.GetMember(ctx, serverID, actorID)
-	if err != nil || actor == nil {
-		return fmt.Errorf("actor not found")
+	actor, err := s.requireStanding(ctx, serverID, actorID, PermManageRoles)
+	if err != nil {
+		return err
 	}
 
-	target, err := s.repo.GetMember(ctx, serverID, targetID)
+	target, err := s.standing(ctx, serverID, targetID)
 	if err != nil || target == nil {
 		return fmt.Errorf("target member not found")
 	}
 
-	if RoleHierarchy(actor.Role) <= RoleHierarchy(target.Role) {
+	if !actor.outranks(target.rank) {
 		return fmt.Errorf("cannot modify a member with equal or higher role")
 	}
 
-	if RoleHierarchy(newRole) >= RoleHierarchy(actor.Role) {
-		return fmt.Errorf("cannot promote a member to your role or above")
+	add, err := s.presetRole(ctx, serverID, newRole, actor)
+	if err != nil {
+		return err
 	}
-
-	if err := s.repo.UpdateMemberRole(ctx, serverID, targetID, newRole); err != nil {
+	presets := []string{presetRoleID(serverID, RoleAdmin), presetRoleID(serverID, RoleModerator)}
+	if err := s.repo.SwapMemberRoles(ctx, serverID, targetID, presets, add); err != nil {
 		return err
 	}
 	s.cache.Delete("members:server:" + serverID)
+	s.audit(ctx, serverID, actorID, AuditMemberRoleUpdate, "member", targetID, map[string]any{
+		"role": newRole,
+	})
 	return nil
 }
 
-// --- Invites ---
-
-// GenerateInvite creates a new invite code for a server. Requires PermCreateInvite.
-func (s *Service) GenerateInvite(ctx context.Context, serverID, userID string) (string, error) {
-	if err := s.requirePermission(ctx, serverID, userID, PermCreateInvite); err != nil {
-		return "", err
+// presetRole returns the ID of the preset role for a legacy role name, or "" for
+// RoleMember, checking that it ranks below actor.
+func (s *Service) presetRole(ctx context.Context, serverID, legacy string, actor *standing) (string, error) {
+	if legacy == RoleMember {
+		return "", nil
 	}
-
-	code, err := generateInviteCode()
+	role, err := s.repo.GetRole(ctx, presetRoleID(serverID, legacy))
 	if err != nil {
-		return "", fmt.Errorf("failed to generate invite: %w", err)
-	}
-
-	if err := s.repo.UpdateInviteCode(ctx, serverID, code); err != nil {
 		return "", err
 	}
-
-	s.logger.Info().Str("server_id", serverID).Str("code", code).Msg("invite code generated")
-	return code, nil
-}
-
-// RedeemInvite adds a user to a server via invite code.
-func (s *Service) RedeemInvite(ctx context.Context, code, userID string) (*Server, error) {
-	srv, err := s.repo.GetServerByInvite(ctx, code)
-	if err != nil {
-		return nil, err
-	}
-	if srv == nil {
-		return nil, fmt.Errorf("invalid invite code")
-	}
-
-	// Check if already a member
-	existing, err := s.repo.GetMember(ctx, srv.ID, userID)
-	if err != nil {
-		return nil, err
+	if role == nil {
+		return "", ErrRoleNotFound
 	}
-	if existing != nil {
-		return srv, nil // Already a member, return server
+	if !actor.outranks(role.Position) {
+		return "", fmt.Errorf("cannot promote a member to your role or above")
 	}
-
-	if err := s.repo.AddMember(ctx, srv.ID, userID, RoleMember); err != nil {
-		return nil, fmt.Errorf("failed to join server: %w", err)
-	}
-
-	// Invalidate caches for member list and user server list
-	s.cache.Delete("members:server:" + srv.ID)
-	s.cache.DeletePrefix("servers:user:" + userID)
-
-	s.logger.Info().
-		Str("server_id", srv.ID).
-		Str("user_id", userID).
-		Msg("user joined server via invite")
-
-	return srv, nil
+	return role.ID, nil
 }
 
-// GetInviteInfo returns info about a server from an invite code.
-func (s *Service) GetInviteInfo(ctx context.Context, code string) (*InviteInfo, error) {
-	srv, err := s.repo.GetServerByInvite(ctx, code)
-	if err != nil {
-		return nil, err
-	}
-	if srv == nil {
-		return nil, fmt.Errorf("invalid invite code")
-	}
-
-	count, err := s.repo.CountMembers(ctx, srv.ID)
-	if err != nil {
-		return nil, err
-	}
+// --- Helpers ---
 
-	return &InviteInfo{
-		ServerID:    srv.ID,
-		ServerName:  srv.Name,
-		InviteCode:  code,
-		MemberCount: count,
-	}, nil
+// CheckPermission returns an error unless userID is a member of serverID holding perm.
+func (s *Service) CheckPermission(ctx context.Context, serverID, userID string, perm Permission) error {
+	return s.requirePermission(ctx, serverID, userID, perm)
 }
 
-// --- Helpers ---
-
 // requirePermission checks that a user has a specific permission in a server.
 func (s *Service) requirePermission(ctx context.Context, serverID, userID string, perm Permission) error {
-	member, err := s.repo.GetMember(ctx, serverID, userID)
-	if err != nil {
-		return fmt.Errorf("failed to check permissions: %w", err)
-	}
-	if member == nil {
-		return fmt.Errorf("not a member of this server")
-	}
-	if !HasPermission(member.Role, perm) {
-		return fmt.Errorf("insufficient permissions")
-	}
-	return nil
-}
-
-// generateInviteCode generates a random 8-character invite code.
-// Complexity: O(1)
-func generateInviteCode() (string, error) {
-	b := make([]byte, 5) // 5 bytes = 8 base32 chars
-	if _, err := rand.Read(b); err != nil {
-		return "", err
-	}
-	return strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b)), nil
+	_, err := s.requireStanding(ctx, serverID, userID, perm)
+	return err
 }
@@ -1,71 +1,80 @@
 package server
 
 import (
+	"encoding/json"
 	"testing"
 )
 
-func TestHasPermission_Owner(t *testing.T) {
-	perms := []Permission{PermManageServer, PermManageChannels, PermManageMembers, PermCreateInvite, PermSendMessages, PermManageMessages}
+func TestPermissionHas_All(t *testing.T) {
+	perms := []Permission{PermManageServer, PermManageChannels, PermManageMembers, PermCreateInvite, PermSendMessages, PermManageMessages, PermManageEmoji, PermManageRoles}
 	for _, p := range perms {
-		if !HasPermission(RoleOwner, p) {
-			t.Errorf("owner should have permission %d", p)
+		if !AllPermissions.Has(p) {
+			t.Errorf("AllPermissions should include %d", p)
 		}
 	}
 }
 
-func TestHasPermission_Member(t *testing.T) {
-	if !HasPermission(RoleMember, PermSendMessages) {
-		t.Error("member should have PermSendMessages")
+func TestPermissionHas_Default(t *testing.T) {
+	if !DefaultPermissions.Has(PermSendMessages) {
+		t.Error("default should have PermSendMessages")
 	}
-	if !HasPermission(RoleMember, PermCreateInvite) {
-		t.Error("member should have PermCreateInvite")
+	if !DefaultPermissions.Has(PermCreateInvite) {
+		t.Error("default should have PermCreateInvite")
 	}
-	if HasPermission(RoleMember, PermManageServer) {
-		t.Error("member should NOT have PermManageServer")
+	if DefaultPermissions.Has(PermManageServer) {
+		t.Error("default should NOT have PermManageServer")
 	}
-	if HasPermission(RoleMember, PermManageChannels) {
-		t.Error("member should NOT have PermManageChannels")
+	if DefaultPermissions.Has(PermManageChannels) {
+		t.Error("default should NOT have PermManageChannels")
 	}
-	if HasPermission(RoleMember, PermManageMembers) {
-		t.Error("member should NOT have PermManageMembers")
+	if DefaultPermissions.Has(PermManageMembers) {
+		t.Error("default should NOT have PermManageMembers")
 	}
 }
 
-func TestHasPermission_Admin(t *testing.T) {
-	if HasPermission(RoleAdmin, PermManageServer) {
+func TestPermissionHas_Admin(t *testing.T) {
+	if AdminPermissions.Has(PermManageServer) {
 		t.Error("admin should NOT have PermManageServer")
 	}
-	if !HasPermission(RoleAdmin, PermManageChannels) {
+	if !AdminPermissions.Has(PermManageChannels) {
 		t.Error("admin should have PermManageChannels")
 	}
-	if !HasPermission(RoleAdmin, PermManageMembers) {
-		t.Error("admin should have PermManageMembers")
+	if !AdminPermissions.Has(PermManageMembers | PermManageRoles) {
+		t.Error("admin should have PermManageMembers and PermManageRoles")
 	}
 }
 
-func TestHasPermission_InvalidRole(t *testing.T) {
-	if HasPermission(Role("unknown"), PermSendMessages) {
-		t.Error("unknown role should have no permissions")
+func TestPermissionHas_Administrator(t *testing.T) {
+	if !PermAdministrator.Has(PermManageServer | PermManageRoles) {
+		t.Error("administrator should include every permission")
+	}
+	if Permission(0).Has(PermSendMessages) {
+		t.Error("empty set should have no permissions")
 	}
 }
 
-func TestRoleHierarchy(t *testing.T) {
-	if RoleHierarchy(RoleOwner) <= RoleHierarchy(RoleAdmin) {
-		t.Error("owner should outrank admin")
+func TestPermissionJSON(t *testing.T) {
+	data, err := json.Marshal(Permission(1 << 63))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
-	if RoleHierarchy(RoleAdmin) <= RoleHierarchy(RoleModerator) {
-		t.Error("admin should outrank moderator")
+	if string(data) != `"9223372036854775808"` {
+		t.Errorf("expected decimal string, got %s", data)
 	}
-	if RoleHierarchy(RoleModerator) <= RoleHierarchy(RoleMember) {
-		t.Error("moderator should outrank member")
+
+	var p Permission
+	for _, in := range []string{`"24"`, `24`} {
+		if err := json.Unmarshal([]byte(in), &p); err != nil || p != 24 {
+			t.Errorf("unmarshal %s: got %d, %v", in, p, err)
+		}
 	}
-	if RoleHierarchy(RoleMember) <= RoleHierarchy(Role("unknown")) {
-		t.Error("member should outrank unknown")
+	if err := json.Unmarshal([]byte(`"-1"`), &p); err == nil {
+		t.Error("negative permissions should be rejected")
 	}
 }
 
 func TestGenerateInviteCode(t *testing.T) {
-	code1, err := generateInviteCode()
+	code1, err := GenerateInviteCode()
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -73,7 +82,7 @@ func TestGenerateInviteCode(t *testing.T) {
 		t.Errorf("expected 8-char code, got %d: %s", len(code1), code1)
 	}
 
-	code2, err := generateInviteCode()
+	code2, err := GenerateInviteCode()
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -0,0 +1,455 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// templateFormat is the version of the TemplateSnapshot format written by this
+	// package. Snapshots in another format are rejected.
+	templateFormat = 1
+
+	maxTemplatesPerServer  = 10
+	maxTemplateName        = 100
+	maxTemplateDescription = 120
+	maxTemplateChannels    = 500
+	maxTemplateIconURL     = 2048
+	maxChannelName         = 100
+)
+
+var (
+	// ErrTemplateNotFound is returned for template codes that do not exist or belong
+	// to another server.
+	ErrTemplateNotFound = errors.New("template not found")
+	// ErrInvalidTemplate is returned when creating a server from a malformed snapshot.
+	ErrInvalidTemplate = errors.New("invalid template")
+)
+
+// CreateTemplate captures the current structure of a server in a new template.
+// Requires PermManageServer.
+func (s *Service) CreateTemplate(ctx context.Context, serverID, userID, name, description string) (*Template, error) {
+	if err := s.requirePermission(ctx, serverID, userID, PermManageServer); err != nil {
+		return nil, err
+	}
+	name, description, err := validateTemplateInfo(name, description)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.repo.ListTemplates(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= maxTemplatesPerServer {
+		return nil, fmt.Errorf("a server can have at most %d templates", maxTemplatesPerServer)
+	}
+
+	snap, err := s.snapshotServer(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+	code, err := GenerateInviteCode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate template code: %w", err)
+	}
+	t := &Template{
+		Code:        code,
+		ServerID:    serverID,
+		CreatorID:   userID,
+		Name:        name,
+		Description: description,
+		Snapshot:    *snap,
+	}
+	if err := s.repo.CreateTemplate(ctx, t); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, serverID, userID, AuditTemplateCreate, "template", code, map[string]any{
+		"name": name,
+	})
+	return s.repo.GetTemplate(ctx, code)
+}
+
+// GetTemplate returns a template by code. The code is the credential: anyone who
+// has it can read the template and create servers from it.
+func (s *Service) GetTemplate(ctx context.Context, code string) (*Template, error) {
+	t, err := s.repo.GetTemplate(ctx, strings.TrimSpace(code))
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, ErrTemplateNotFound
+	}
+	return t, nil
+}
+
+// ListTemplates returns the templates captured from a server, oldest first, with
+// Dirty set on those the server has changed since. Requires PermManageServer.
+func (s *Service) ListTemplates(ctx context.Context, serverID, userID string) ([]*Template, error) {
+	if err := s.requirePermission(ctx, serverID, userID, PermManageServer); err != nil {
+		return nil, err
+	}
+	templates, err := s.repo.ListTemplates(ctx, serverID)
+	if err != nil || len(templates) == 0 {
+		return templates, err
+	}
+	snap, err := s.snapshotServer(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+	current, err := json.Marshal(snap)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range templates {
+		stored, err := json.Marshal(t.Snapshot)
+		if err != nil {
+			return nil, err
+		}
+		t.Dirty = string(stored) != string(current)
+	}
+	return templates, nil
+}
+
+// SyncTemplate re-captures the structure of a server in one of its templates and
+// increments the template's version. Requires PermManageServer.
+func (s *Service) SyncTemplate(ctx context.Context, serverID, userID, code string) (*Template, error) {
+	t, err := s.managedTemplate(ctx, serverID, userID, code)
+	if err != nil {
+		return nil, err
+	}
+	snap, err := s.snapshotServer(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SyncTemplate(ctx, t.Code, snap); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, serverID, userID, AuditTemplateSync, "template", t.Code, map[string]any{
+		"version": t.Version + 1,
+	})
+	return s.repo.GetTemplate(ctx, t.Code)
+}
+
+// DeleteTemplate deletes a template of a server. Servers already created from it
+// are not affected. Requires PermManageServer.
+func (s *Service) DeleteTemplate(ctx context.Context, serverID, userID, code string) error {
+	t, err := s.managedTemplate(ctx, serverID, userID, code)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteTemplate(ctx, t.Code); err != nil {
+		return err
+	}
+	s.audit(ctx, serverID, userID, AuditTemplateDelete, "template", t.Code, map[string]any{
+		"name": t.Name,
+	})
+	return nil
+}
+
+// CreateServerFromTemplate creates a server with the structure of a template. The
+// server is named after the template's source server when name is empty. The
+// creator becomes the owner.
+func (s *Service) CreateServerFromTemplate(ctx context.Context, code, name, ownerID string) (*Server, error) {
+	t, err := s.GetTemplate(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	return s.CreateServerFromSnapshot(ctx, &t.Snapshot, name, ownerID)
+}
+
+// CreateServerFromSnapshot creates a server with the structure of a snapshot, such
+// as a template exported as JSON. The server is named after the snapshot when name
+// is empty. The creator becomes the owner.
+func (s *Service) CreateServerFromSnapshot(ctx context.Context, snap *TemplateSnapshot, name, ownerID string) (*Server, error) {
+	if snap == nil {
+		return nil, fmt.Errorf("%w: snapshot is required", ErrInvalidTemplate)
+	}
+	if err := validateSnapshot(snap); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	if strings.TrimSpace(name) == "" {
+		name = snap.Name
+	}
+	if strings.TrimSpace(name) == "" {
+		return nil, fmt.Errorf("%w: server name is required", ErrInvalidTemplate)
+	}
+	return s.createServer(ctx, name, ownerID, snap)
+}
+
+// managedTemplate returns the template with code captured from serverID, checking
+// that userID holds PermManageServer.
+func (s *Service) managedTemplate(ctx context.Context, serverID, userID, code string) (*Template, error) {
+	if err := s.requirePermission(ctx, serverID, userID, PermManageServer); err != nil {
+		return nil, err
+	}
+	t, err := s.repo.GetTemplate(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil || t.ServerID != serverID {
+		return nil, ErrTemplateNotFound
+	}
+	return t, nil
+}
+
+// snapshotServer captures the structure of a server as it is stored, bypassing
+// the cache.
+func (s *Service) snapshotServer(ctx context.Context, serverID string) (*TemplateSnapshot, error) {
+	srv, err := s.repo.GetServer(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+	if srv == nil {
+		return nil, fmt.Errorf("server not found")
+	}
+	roles, err := s.repo.ListRoles(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.repo.ListCategories(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+	channels, err := s.repo.ListChannels(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+	overwrites, err := s.repo.ListServerOverwrites(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	snap := &TemplateSnapshot{Format: templateFormat, Name: srv.Name, IconURL: srv.IconURL}
+	roleIndex := make(map[string]int, len(roles))
+	for i, role := range roles {
+		roleIndex[role.ID] = i
+		snap.Roles = append(snap.Roles, TemplateRole{
+			Name:        role.Name,
+			Color:       role.Color,
+			Permissions: role.Permissions,
+			Preset:      rolePreset(role),
+			Default:     role.Default,
+		})
+	}
+	categoryIndex := make(map[string]int, len(categories))
+	for i, c := range categories {
+		categoryIndex[c.ID] = i
+		snap.Categories = append(snap.Categories, TemplateCategory{Name: c.Name})
+	}
+	byChannel := make(map[string][]TemplateOverwrite)
+	for _, o := range overwrites {
+		i, ok := roleIndex[o.TargetID]
+		if o.TargetType != OverwriteRole || !ok {
+			continue
+		}
+		byChannel[o.ChannelID] = append(byChannel[o.ChannelID], TemplateOverwrite{Role: i, Allow: o.Allow, Deny: o.Deny})
+	}
+	for _, ch := range channels {
+		tc := TemplateChannel{Name: ch.Name, Type: ch.Type, SlowMode: ch.SlowMode, Overwrites: byChannel[ch.ID]}
+		if i, ok := categoryIndex[ch.CategoryID]; ok {
+			tc.Category = &i
+		}
+		sort.Slice(tc.Overwrites, func(a, b int) bool { return tc.Overwrites[a].Role < tc.Overwrites[b].Role })
+		snap.Channels = append(snap.Channels, tc)
+	}
+	return snap, nil
+}
+
+// defaultSnapshot is the structure of servers created without a template: the
+// preset roles, a #general text channel and a General voice channel.
+func defaultSnapshot() *TemplateSnapshot {
+	snap := &TemplateSnapshot{
+		Format: templateFormat,
+		Channels: []TemplateChannel{
+			{Name: "general", Type: "text"},
+			{Name: "General", Type: "voice"},
+		},
+	}
+	presets := PresetRoles("")
+	for i := len(presets) - 1; i >= 0; i-- {
+		role := presets[i]
+		snap.Roles = append(snap.Roles, TemplateRole{
+			Name:        role.Name,
+			Color:       role.Color,
+			Permissions: role.Permissions,
+			Preset:      rolePreset(role),
+			Default:     role.Default,
+		})
+	}
+	return snap
+}
+
+// rolePreset returns the legacy role name of a preset role, or "" for other roles.
+func rolePreset(role *Role) string {
+	for _, legacy := range []string{RoleAdmin, RoleModerator} {
+		if role.ID == presetRoleID(role.ServerID, legacy) {
+			return legacy
+		}
+	}
+	return ""
+}
+
+// buildStructure turns a validated snapshot into the rows of server serverID,
+// with new IDs except for the default and preset roles.
+func buildStructure(serverID string, snap *TemplateSnapshot) ([]*Role, []*Category, []*Channel, []*Overwrite) {
+	roles := make([]*Role, len(snap.Roles))
+	for i, tr := range snap.Roles {
+		role := &Role{
+			ID:          uuid.New().String(),
+			ServerID:    serverID,
+			Name:        tr.Name,
+			Color:       tr.Color,
+			Position:    len(snap.Roles) - 1 - i,
+			Permissions: tr.Permissions,
+			Default:     tr.Default,
+		}
+		switch {
+		case tr.Default:
+			role.ID = DefaultRoleID(serverID)
+		case tr.Preset != "":
+			role.ID = presetRoleID(serverID, tr.Preset)
+		}
+		roles[i] = role
+	}
+
+	categories := make([]*Category, len(snap.Categories))
+	for i, tc := range snap.Categories {
+		categories[i] = &Category{ID: uuid.New().String(), ServerID: serverID, Name: tc.Name, Position: i}
+	}
+
+	channels := make([]*Channel, len(snap.Channels))
+	var overwrites []*Overwrite
+	positions := make(map[string]int) // next position per category ID
+	for i, tc := range snap.Channels {
+		ch := &Channel{
+			ID:       uuid.New().String(),
+			ServerID: serverID,
+			Name:     tc.Name,
+			Type:     tc.Type,
+			SlowMode: tc.SlowMode,
+		}
+		if tc.Category != nil {
+			ch.CategoryID = categories[*tc.Category].ID
+		}
+		ch.Position = positions[ch.CategoryID]
+		positions[ch.CategoryID]++
+		channels[i] = ch
+
+		for _, to := range tc.Overwrites {
+			overwrites = append(overwrites, &Overwrite{
+				ChannelID:  ch.ID,
+				TargetType: OverwriteRole,
+				TargetID:   roles[to.Role].ID,
+				Allow:      to.Allow,
+				Deny:       to.Deny,
+			})
+		}
+	}
+	return roles, categories, channels, overwrites
+}
+
+// validateSnapshot checks that a snapshot, possibly written by hand, describes a
+// server this package could have captured.
+func validateSnapshot(snap *TemplateSnapshot) error {
+	if snap.Format != templateFormat {
+		return fmt.Errorf("unsupported format %d", snap.Format)
+	}
+	if len(snap.Name) > 100 {
+		return fmt.Errorf("server name cannot exceed 100 characters")
+	}
+	if len(snap.IconURL) > maxTemplateIconURL {
+		return fmt.Errorf("icon URL cannot exceed %d characters", maxTemplateIconURL)
+	}
+
+	if len(snap.Roles) == 0 || len(snap.Roles) > maxRolesPerServer {
+		return fmt.Errorf("a template must have between 1 and %d roles", maxRolesPerServer)
+	}
+	presets := make(map[string]bool)
+	for i, role := range snap.Roles {
+		if _, err := validateRoleName(role.Name); err != nil {
+			return err
+		}
+		if role.Color < 0 || role.Color > maxRoleColor {
+			return fmt.Errorf("role color must be between 0 and #FFFFFF")
+		}
+		if role.Permissions&^AllPermissions != 0 {
+			return fmt.Errorf("unknown permissions")
+		}
+		if role.Default != (i == len(snap.Roles)-1) {
+			return fmt.Errorf("the default role must be the last role")
+		}
+		switch role.Preset {
+		case "":
+		case RoleAdmin, RoleModerator:
+			if role.Default || presets[role.Preset] {
+				return fmt.Errorf("invalid preset role %q", role.Preset)
+			}
+			presets[role.Preset] = true
+		default:
+			return fmt.Errorf("invalid preset role %q", role.Preset)
+		}
+	}
+
+	if len(snap.Categories) > maxCategoriesPerServer {
+		return fmt.Errorf("a server can have at most %d categories", maxCategoriesPerServer)
+	}
+	for _, c := range snap.Categories {
+		if _, err := validateCategoryName(c.Name); err != nil {
+			return err
+		}
+	}
+
+	if len(snap.Channels) > maxTemplateChannels {
+		return fmt.Errorf("a template can have at most %d channels", maxTemplateChannels)
+	}
+	for _, ch := range snap.Channels {
+		if strings.TrimSpace(ch.Name) == "" || len(ch.Name) > maxChannelName {
+			return fmt.Errorf("channel names must be between 1 and %d characters", maxChannelName)
+		}
+		if ch.Type != "text" && ch.Type != "voice" {
+			return fmt.Errorf("channel type must be 'text' or 'voice'")
+		}
+		if ch.SlowMode < 0 || ch.SlowMode > MaxSlowModeSeconds {
+			return fmt.Errorf("slow mode must be between 0 and %d seconds", MaxSlowModeSeconds)
+		}
+		if ch.Category != nil && (*ch.Category < 0 || *ch.Category >= len(snap.Categories)) {
+			return fmt.Errorf("channel %q refers to an unknown category", ch.Name)
+		}
+		seen := make(map[int]bool, len(ch.Overwrites))
+		for _, o := range ch.Overwrites {
+			if o.Role < 0 || o.Role >= len(snap.Roles) || seen[o.Role] {
+				return fmt.Errorf("channel %q has an invalid role overwrite", ch.Name)
+			}
+			seen[o.Role] = true
+			if (o.Allow|o.Deny)&^OverwritablePermissions != 0 {
+				return fmt.Errorf("overwrites can only allow or deny view, send, connect and speak")
+			}
+			if o.Allow&o.Deny != 0 {
+				return fmt.Errorf("a permission cannot be both allowed and denied")
+			}
+		}
+	}
+	return nil
+}
+
+// validateTemplateInfo trims and checks the name and description of a template.
+func validateTemplateInfo(name, description string) (string, string, error) {
+	name = strings.TrimSpace(name)
+	description = strings.TrimSpace(description)
+	if name == "" {
+		return "", "", fmt.Errorf("template name cannot be empty")
+	}
+	if len(name) > maxTemplateName {
+		return "", "", fmt.Errorf("template name cannot exceed %d characters", maxTemplateName)
+	}
+	if len(description) > maxTemplateDescription {
+		return "", "", fmt.Errorf("template description cannot exceed %d characters", maxTemplateDescription)
+	}
+	return name, description, nil
+}
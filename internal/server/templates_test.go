@@ -0,0 +1,199 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplates_CreateServer(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	cat, err := svc.CreateCategory(ctx, "srv-1", "owner", "Staff")
+	require.NoError(t, err)
+	require.NoError(t, svc.SetSlowMode(ctx, "srv-1", "owner", "ch-2", 30))
+	_, err = svc.UpdateChannel(ctx, "srv-1", "owner", "ch-2", ChannelUpdate{CategoryID: &cat.ID})
+	require.NoError(t, err)
+	role, err := svc.CreateRole(ctx, "srv-1", "owner", "Helper", 0x00FF00, PermManageMessages)
+	require.NoError(t, err)
+	_, err = svc.SetChannelOverwrite(ctx, "srv-1", "owner", Overwrite{
+		ChannelID: "ch-2", TargetType: OverwriteRole, TargetID: DefaultRoleID("srv-1"), Deny: PermViewChannel,
+	})
+	require.NoError(t, err)
+	_, err = svc.SetChannelOverwrite(ctx, "srv-1", "owner", Overwrite{
+		ChannelID: "ch-2", TargetType: OverwriteRole, TargetID: role.ID, Allow: PermViewChannel,
+	})
+	require.NoError(t, err)
+	_, err = svc.SetChannelOverwrite(ctx, "srv-1", "owner", Overwrite{
+		ChannelID: "ch-2", TargetType: OverwriteMember, TargetID: "member", Allow: PermViewChannel,
+	})
+	require.NoError(t, err)
+
+	_, err = svc.CreateTemplate(ctx, "srv-1", "admin", "Community", "")
+	assert.Error(t, err, "templates require PermManageServer")
+	_, err = svc.CreateTemplate(ctx, "srv-1", "owner", " ", "")
+	assert.Error(t, err)
+
+	tmpl, err := svc.CreateTemplate(ctx, "srv-1", "owner", " Community ", "Our usual layout")
+	require.NoError(t, err)
+	assert.Equal(t, "Community", tmpl.Name)
+	assert.Equal(t, 1, tmpl.Version)
+	assert.Equal(t, "Test", tmpl.Snapshot.Name)
+	require.Len(t, tmpl.Snapshot.Categories, 1)
+	for _, ch := range tmpl.Snapshot.Channels {
+		if ch.Name == "alerts" {
+			require.NotNil(t, ch.Category)
+			assert.Equal(t, 30, ch.SlowMode)
+			assert.Len(t, ch.Overwrites, 2, "member overwrites are left out")
+		}
+	}
+
+	// Anyone with the code can create a server from it
+	srv, err := svc.CreateServerFromTemplate(ctx, tmpl.Code, "", "member")
+	require.NoError(t, err)
+	assert.Equal(t, "Test", srv.Name)
+	assert.Equal(t, "member", srv.OwnerID)
+
+	roles, err := svc.ListRoles(ctx, srv.ID)
+	require.NoError(t, err)
+	require.Len(t, roles, 4)
+	assert.Equal(t, presetRoleID(srv.ID, RoleAdmin), roles[0].ID, "preset roles keep their meaning")
+	assert.Equal(t, "Helper", roles[2].Name)
+	assert.Equal(t, PermManageMessages, roles[2].Permissions)
+	assert.Equal(t, DefaultRoleID(srv.ID), roles[3].ID)
+
+	channels, err := svc.ListChannels(ctx, srv.ID)
+	require.NoError(t, err)
+	var alerts *Channel
+	for _, ch := range channels {
+		if ch.Name == "alerts" {
+			alerts = ch
+		}
+	}
+	require.NotNil(t, alerts)
+	assert.NotEmpty(t, alerts.CategoryID)
+	assert.Equal(t, 30, alerts.SlowMode)
+	overwrites, err := svc.repo.ListServerOverwrites(ctx, srv.ID)
+	require.NoError(t, err)
+	assert.Len(t, overwrites, 2)
+
+	_, err = svc.CreateServerFromTemplate(ctx, "missing", "", "member")
+	assert.ErrorIs(t, err, ErrTemplateNotFound)
+}
+
+func TestTemplates_Sync(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	tmpl, err := svc.CreateTemplate(ctx, "srv-1", "owner", "Community", "")
+	require.NoError(t, err)
+	templates, err := svc.ListTemplates(ctx, "srv-1", "owner")
+	require.NoError(t, err)
+	require.Len(t, templates, 1)
+	assert.False(t, templates[0].Dirty)
+
+	_, err = svc.CreateChannel(ctx, "srv-1", "owner", "announcements", "text")
+	require.NoError(t, err)
+	templates, err = svc.ListTemplates(ctx, "srv-1", "owner")
+	require.NoError(t, err)
+	assert.True(t, templates[0].Dirty)
+
+	other, err := svc.CreateServer(ctx, "Other", "owner")
+	require.NoError(t, err)
+	_, err = svc.SyncTemplate(ctx, other.ID, "owner", tmpl.Code)
+	assert.ErrorIs(t, err, ErrTemplateNotFound, "templates are synced from their own server")
+	synced, err := svc.SyncTemplate(ctx, "srv-1", "owner", tmpl.Code)
+	require.NoError(t, err)
+	assert.Equal(t, 2, synced.Version)
+	assert.Len(t, synced.Snapshot.Channels, len(tmpl.Snapshot.Channels)+1)
+	templates, err = svc.ListTemplates(ctx, "srv-1", "owner")
+	require.NoError(t, err)
+	assert.False(t, templates[0].Dirty)
+
+	_, err = svc.ListTemplates(ctx, "srv-1", "member")
+	assert.Error(t, err)
+	require.NoError(t, svc.DeleteTemplate(ctx, "srv-1", "owner", tmpl.Code))
+	_, err = svc.GetTemplate(ctx, tmpl.Code)
+	assert.ErrorIs(t, err, ErrTemplateNotFound)
+
+	assert.Equal(t, []string{AuditTemplateDelete, AuditTemplateSync, AuditTemplateCreate},
+		auditActions(t, svc, AuditQuery{TargetType: "template"}))
+}
+
+func TestTemplates_Snapshot(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	var snap TemplateSnapshot
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"format": 1,
+		"name": "Guild",
+		"roles": [
+			{"name": "Officer", "permissions": "8"},
+			{"name": "@everyone", "permissions": "0", "default": true}
+		],
+		"categories": [{"name": "Raids"}],
+		"channels": [
+			{"name": "lobby", "type": "text"},
+			{"name": "planning", "type": "text", "category": 0}
+		]
+	}`), &snap))
+	snap.Channels[1].Overwrites = []TemplateOverwrite{
+		{Role: 1, Deny: PermViewChannel},
+		{Role: 0, Allow: PermViewChannel},
+	}
+
+	srv, err := svc.CreateServerFromSnapshot(ctx, &snap, "My Guild", "admin")
+	require.NoError(t, err)
+	assert.Equal(t, "My Guild", srv.Name)
+	roles, err := svc.ListRoles(ctx, srv.ID)
+	require.NoError(t, err)
+	require.Len(t, roles, 2)
+	assert.Equal(t, "Officer", roles[0].Name)
+	assert.True(t, roles[1].Default)
+
+	overwrites, err := svc.repo.ListServerOverwrites(ctx, srv.ID)
+	require.NoError(t, err)
+	assert.Len(t, overwrites, 2)
+
+	for name, mutate := range map[string]func(*TemplateSnapshot){
+		"format":          func(s *TemplateSnapshot) { s.Format = 2 },
+		"no default role": func(s *TemplateSnapshot) { s.Roles[1].Default = false },
+		"unknown preset":  func(s *TemplateSnapshot) { s.Roles[0].Preset = "owner" },
+		"channel type":    func(s *TemplateSnapshot) { s.Channels[0].Type = "forum" },
+		"category index":  func(s *TemplateSnapshot) { two := 2; s.Channels[0].Category = &two },
+		"overwrite role":  func(s *TemplateSnapshot) { s.Channels[1].Overwrites[0].Role = 5 },
+		"overwrite perms": func(s *TemplateSnapshot) { s.Channels[1].Overwrites[0].Allow = PermManageServer },
+	} {
+		bad := snap
+		bad.Roles = append([]TemplateRole(nil), snap.Roles...)
+		bad.Channels = append([]TemplateChannel(nil), snap.Channels...)
+		bad.Channels[1].Overwrites = append([]TemplateOverwrite(nil), snap.Channels[1].Overwrites...)
+		mutate(&bad)
+		_, err := svc.CreateServerFromSnapshot(ctx, &bad, "", "admin")
+		assert.ErrorIs(t, err, ErrInvalidTemplate, name)
+	}
+}
+
+func TestCreateServer_DefaultStructure(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	srv, err := svc.CreateServer(ctx, "Plain", "owner")
+	require.NoError(t, err)
+	roles, err := svc.ListRoles(ctx, srv.ID)
+	require.NoError(t, err)
+	assert.Equal(t, PresetRoles(srv.ID)[2].ID, roles[0].ID)
+	assert.Len(t, roles, 3)
+	channels, err := svc.ListChannels(ctx, srv.ID)
+	require.NoError(t, err)
+	require.Len(t, channels, 2)
+	assert.Equal(t, "general", channels[0].Name)
+	assert.Equal(t, 0, channels[0].Position)
+	assert.Equal(t, "voice", channels[1].Type)
+	assert.Equal(t, 1, channels[1].Position)
+}
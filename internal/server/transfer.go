@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// transferTTL is how long the target of an ownership transfer has to accept it.
+const transferTTL = 24 * time.Hour
+
+// ErrTransferNotFound is returned when no ownership transfer to accept or cancel
+// is pending.
+var ErrTransferNotFound = errors.New("no pending ownership transfer")
+
+// RequestOwnershipTransfer offers a server to targetID, replacing any pending
+// offer. The transfer takes effect once targetID accepts it within transferTTL.
+// Only the owner can request a transfer, and the target must be a member.
+func (s *Service) RequestOwnershipTransfer(ctx context.Context, serverID, ownerID, targetID string) (*OwnershipTransfer, error) {
+	srv, err := s.GetServer(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+	if srv == nil {
+		return nil, fmt.Errorf("server not found")
+	}
+	if srv.OwnerID != ownerID {
+		return nil, fmt.Errorf("only the server owner can transfer ownership")
+	}
+	if targetID == ownerID {
+		return nil, fmt.Errorf("cannot transfer ownership to yourself")
+	}
+	isMember, err := s.IsMember(ctx, serverID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, fmt.Errorf("target member not found")
+	}
+
+	now := time.Now()
+	t := &OwnershipTransfer{
+		ServerID:  serverID,
+		FromID:    ownerID,
+		ToID:      targetID,
+		ExpiresAt: now.Add(transferTTL).UTC(),
+	}
+	if err := s.repo.SaveTransfer(ctx, t); err != nil {
+		return nil, err
+	}
+	return s.repo.GetTransfer(ctx, serverID, now)
+}
+
+// PendingOwnershipTransfer returns the pending ownership transfer of a server, or
+// nil if there is none. Only the owner and the target can see it.
+func (s *Service) PendingOwnershipTransfer(ctx context.Context, serverID, userID string) (*OwnershipTransfer, error) {
+	t, err := s.repo.GetTransfer(ctx, serverID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if t == nil || (t.FromID != userID && t.ToID != userID) {
+		return nil, nil
+	}
+	return t, nil
+}
+
+// AcceptOwnershipTransfer makes userID the owner of a server if a transfer to them
+// is pending. The previous owner stays a member with the preset Admin role.
+func (s *Service) AcceptOwnershipTransfer(ctx context.Context, serverID, userID string) (*Server, error) {
+	now := time.Now()
+	t, err := s.repo.GetTransfer(ctx, serverID, now)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil || t.ToID != userID {
+		return nil, ErrTransferNotFound
+	}
+
+	done, err := s.repo.CompleteTransfer(ctx, t, now)
+	if err != nil {
+		return nil, err
+	}
+	if !done {
+		// The owner changed or the target left since the request: drop the offer.
+		if err := s.repo.DeleteTransfer(ctx, serverID); err != nil {
+			return nil, err
+		}
+		return nil, ErrTransferNotFound
+	}
+
+	s.cache.Delete("server:" + serverID)
+	s.cache.DeletePrefix("servers:user:" + t.FromID)
+	s.cache.DeletePrefix("servers:user:" + t.ToID)
+	s.cache.Delete("members:server:" + serverID)
+	s.audit(ctx, serverID, userID, AuditServerTransfer, "server", serverID, map[string]any{
+		"previous_owner_id": t.FromID,
+	})
+	return s.repo.GetServer(ctx, serverID)
+}
+
+// CancelOwnershipTransfer withdraws or declines the pending ownership transfer of
+// a server. Either the owner who requested it or its target can cancel it.
+func (s *Service) CancelOwnershipTransfer(ctx context.Context, serverID, userID string) error {
+	t, err := s.PendingOwnershipTransfer(ctx, serverID, userID)
+	if err != nil {
+		return err
+	}
+	if t == nil {
+		return ErrTransferNotFound
+	}
+	return s.repo.DeleteTransfer(ctx, serverID)
+}
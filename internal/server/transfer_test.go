@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOwnershipTransfer(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	_, err := svc.RequestOwnershipTransfer(ctx, "srv-1", "admin", "member")
+	assert.Error(t, err, "only the owner can transfer ownership")
+	_, err = svc.RequestOwnershipTransfer(ctx, "srv-1", "owner", "owner")
+	assert.Error(t, err)
+	_, err = svc.RequestOwnershipTransfer(ctx, "srv-1", "owner", "bot")
+	assert.Error(t, err, "the target must be a member")
+
+	_, err = svc.TimeoutMember(ctx, "srv-1", "admin", "member", 600)
+	require.NoError(t, err)
+	tr, err := svc.RequestOwnershipTransfer(ctx, "srv-1", "owner", "member")
+	require.NoError(t, err)
+	assert.Equal(t, "owner", tr.FromID)
+	assert.Equal(t, "member", tr.ToID)
+	assert.WithinDuration(t, time.Now().Add(transferTTL), tr.ExpiresAt, time.Minute)
+
+	pending, err := svc.PendingOwnershipTransfer(ctx, "srv-1", "admin")
+	require.NoError(t, err)
+	assert.Nil(t, pending, "only the owner and target see the transfer")
+	pending, err = svc.PendingOwnershipTransfer(ctx, "srv-1", "member")
+	require.NoError(t, err)
+	require.NotNil(t, pending)
+
+	_, err = svc.AcceptOwnershipTransfer(ctx, "srv-1", "admin")
+	assert.ErrorIs(t, err, ErrTransferNotFound)
+	srv, err := svc.AcceptOwnershipTransfer(ctx, "srv-1", "member")
+	require.NoError(t, err)
+	assert.Equal(t, "member", srv.OwnerID)
+
+	owner, err := svc.repo.GetMember(ctx, "srv-1", "member")
+	require.NoError(t, err)
+	assert.Equal(t, RoleOwner, owner.Role)
+	assert.Nil(t, owner.TimeoutUntil, "the new owner's timeout ends")
+	previous, err := svc.repo.GetMember(ctx, "srv-1", "owner")
+	require.NoError(t, err)
+	assert.Equal(t, RoleAdmin, previous.Role)
+	assert.Error(t, svc.DeleteServer(ctx, "srv-1", "owner"), "the previous owner can no longer delete the server")
+
+	_, err = svc.AcceptOwnershipTransfer(ctx, "srv-1", "member")
+	assert.ErrorIs(t, err, ErrTransferNotFound, "transfers are used once")
+
+	entries, err := svc.AuditLog(ctx, "srv-1", "member", AuditQuery{Action: AuditServerTransfer})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "member", entries[0].ActorID)
+	assert.JSONEq(t, `{"previous_owner_id":"owner"}`, string(entries[0].Details))
+}
+
+func TestOwnershipTransfer_CancelAndStale(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	_, err := svc.RequestOwnershipTransfer(ctx, "srv-1", "owner", "admin")
+	require.NoError(t, err)
+	assert.ErrorIs(t, svc.CancelOwnershipTransfer(ctx, "srv-1", "member"), ErrTransferNotFound)
+	require.NoError(t, svc.CancelOwnershipTransfer(ctx, "srv-1", "admin"), "the target can decline")
+	_, err = svc.AcceptOwnershipTransfer(ctx, "srv-1", "admin")
+	assert.ErrorIs(t, err, ErrTransferNotFound)
+
+	// Expired transfers cannot be completed
+	tr, err := svc.RequestOwnershipTransfer(ctx, "srv-1", "owner", "admin")
+	require.NoError(t, err)
+	done, err := svc.repo.CompleteTransfer(ctx, tr, time.Now().Add(transferTTL+time.Minute))
+	require.NoError(t, err)
+	assert.False(t, done)
+
+	// Nor can transfers to members who left since
+	require.NoError(t, svc.KickMember(ctx, "srv-1", "owner", "admin"))
+	_, err = svc.AcceptOwnershipTransfer(ctx, "srv-1", "admin")
+	assert.ErrorIs(t, err, ErrTransferNotFound)
+	srv, err := svc.GetServer(ctx, "srv-1")
+	require.NoError(t, err)
+	assert.Equal(t, "owner", srv.OwnerID)
+	pending, err := svc.repo.GetTransfer(ctx, "srv-1", time.Now())
+	require.NoError(t, err)
+	assert.Nil(t, pending, "stale transfers are dropped")
+}
@@ -0,0 +1,284 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	maxWebhookName      = 80
+	maxWebhookAvatarURL = 2048
+
+	// webhookChannelPerms are needed in a webhook's channel to manage it, so
+	// webhooks cannot post into channels their managers cannot post in.
+	webhookChannelPerms = PermViewChannel | PermSendMessages
+)
+
+// ErrInvalidWebhook is returned when a webhook ID and token do not match.
+// Unknown IDs and wrong tokens are deliberately indistinguishable.
+var ErrInvalidWebhook = errors.New("invalid webhook")
+
+// CreateWebhook creates an incoming webhook posting into a text channel of the server.
+// The returned webhook carries its token, which is not retrievable afterwards.
+// Requires PermManageChannels, and view channel and send messages in the channel.
+func (s *Service) CreateWebhook(ctx context.Context, serverID, userID, channelID, name, avatarURL string) (*Webhook, error) {
+	if err := s.requirePermission(ctx, serverID, userID, PermManageChannels); err != nil {
+		return nil, err
+	}
+	name, avatarURL, err := validateWebhook(name, avatarURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requireWebhookChannel(ctx, serverID, userID, channelID); err != nil {
+		return nil, err
+	}
+
+	token, hash, err := newWebhookToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate webhook token: %w", err)
+	}
+	wh := &Webhook{
+		ID:        uuid.New().String(),
+		ServerID:  serverID,
+		ChannelID: channelID,
+		Name:      name,
+		AvatarURL: avatarURL,
+		CreatedBy: userID,
+		TokenHash: hash,
+	}
+	if err := s.repo.CreateWebhook(ctx, wh); err != nil {
+		return nil, err
+	}
+
+	created, err := s.repo.GetWebhook(ctx, wh.ID)
+	if err != nil {
+		return nil, err
+	}
+	created.Token = token
+	s.audit(ctx, serverID, userID, AuditWebhookCreate, "webhook", wh.ID, map[string]any{
+		"name":       wh.Name,
+		"channel_id": channelID,
+	})
+	return created, nil
+}
+
+// ListWebhooks returns the webhooks of a server in channels where userID can
+// view and send messages. Requires PermManageChannels.
+func (s *Service) ListWebhooks(ctx context.Context, serverID, userID string) ([]*Webhook, error) {
+	st, err := s.requireStanding(ctx, serverID, userID, PermManageChannels)
+	if err != nil {
+		return nil, err
+	}
+	webhooks, err := s.repo.ListWebhooks(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+	overwrites, err := s.serverOverwrites(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+	managed := make([]*Webhook, 0, len(webhooks))
+	for _, wh := range webhooks {
+		if st.inChannel(serverID, overwrites[wh.ChannelID]).Has(webhookChannelPerms) {
+			managed = append(managed, wh)
+		}
+	}
+	return managed, nil
+}
+
+// UpdateWebhook renames a webhook, changes its default avatar or moves it to another
+// text channel. Requires PermManageChannels, and view channel and send messages in
+// both channels.
+func (s *Service) UpdateWebhook(ctx context.Context, serverID, userID, webhookID, name, avatarURL, channelID string) (*Webhook, error) {
+	wh, err := s.managedWebhook(ctx, serverID, userID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	oldName, oldChannelID := wh.Name, wh.ChannelID
+	if wh.Name, wh.AvatarURL, err = validateWebhook(name, avatarURL); err != nil {
+		return nil, err
+	}
+	if channelID != "" && channelID != wh.ChannelID {
+		if err := s.requireWebhookChannel(ctx, serverID, userID, channelID); err != nil {
+			return nil, err
+		}
+		wh.ChannelID = channelID
+	}
+
+	if err := s.repo.UpdateWebhook(ctx, wh); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, serverID, userID, AuditWebhookUpdate, "webhook", wh.ID, map[string]any{
+		"old_name":       oldName,
+		"name":           wh.Name,
+		"old_channel_id": oldChannelID,
+		"channel_id":     wh.ChannelID,
+	})
+	return wh, nil
+}
+
+// RegenerateWebhookToken issues a new token, invalidating the previous URL.
+// Requires PermManageChannels.
+func (s *Service) RegenerateWebhookToken(ctx context.Context, serverID, userID, webhookID string) (*Webhook, error) {
+	wh, err := s.managedWebhook(ctx, serverID, userID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, hash, err := newWebhookToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate webhook token: %w", err)
+	}
+	if err := s.repo.UpdateWebhookToken(ctx, wh.ID, hash); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, serverID, userID, AuditWebhookToken, "webhook", wh.ID, map[string]any{
+		"name": wh.Name,
+	})
+	wh.Token = token
+	return wh, nil
+}
+
+// DeleteWebhook removes a webhook; messages it posted stay. Requires PermManageChannels.
+func (s *Service) DeleteWebhook(ctx context.Context, serverID, userID, webhookID string) error {
+	wh, err := s.managedWebhook(ctx, serverID, userID, webhookID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteWebhook(ctx, webhookID); err != nil {
+		return err
+	}
+	s.audit(ctx, serverID, userID, AuditWebhookDelete, "webhook", webhookID, map[string]any{
+		"name": wh.Name,
+	})
+	return nil
+}
+
+// AuthenticateWebhook returns the webhook identified by webhookID if token matches.
+// Complexity: O(1)
+func (s *Service) AuthenticateWebhook(ctx context.Context, webhookID, token string) (*Webhook, error) {
+	if webhookID == "" || token == "" {
+		return nil, ErrInvalidWebhook
+	}
+	wh, err := s.repo.GetWebhook(ctx, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	if wh == nil || subtle.ConstantTimeCompare([]byte(hashWebhookToken(token)), []byte(wh.TokenHash)) != 1 {
+		return nil, ErrInvalidWebhook
+	}
+	return wh, nil
+}
+
+// ValidateWebhookOverride checks the per-message name and avatar a webhook post may set.
+// Empty values keep the webhook's own.
+func ValidateWebhookOverride(name, avatarURL string) (string, string, error) {
+	name = strings.TrimSpace(name)
+	if len(name) > maxWebhookName {
+		return "", "", fmt.Errorf("webhook name cannot exceed %d characters", maxWebhookName)
+	}
+	avatarURL, err := validateAvatarURL(avatarURL)
+	if err != nil {
+		return "", "", err
+	}
+	return name, avatarURL, nil
+}
+
+// managedWebhook loads a webhook of serverID after checking PermManageChannels and
+// webhookChannelPerms in its channel. Webhooks in channels userID cannot manage are
+// reported as not found, as ListWebhooks leaves them out.
+func (s *Service) managedWebhook(ctx context.Context, serverID, userID, webhookID string) (*Webhook, error) {
+	if err := s.requirePermission(ctx, serverID, userID, PermManageChannels); err != nil {
+		return nil, err
+	}
+	wh, err := s.repo.GetWebhook(ctx, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	if wh == nil || wh.ServerID != serverID {
+		return nil, fmt.Errorf("webhook not found")
+	}
+	ok, err := s.canUseWebhookChannel(ctx, wh.ChannelID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("webhook not found")
+	}
+	return wh, nil
+}
+
+// canUseWebhookChannel reports whether userID holds webhookChannelPerms in channelID.
+func (s *Service) canUseWebhookChannel(ctx context.Context, channelID, userID string) (bool, error) {
+	perms, err := s.ChannelPermissions(ctx, channelID, userID)
+	if err != nil {
+		return false, err
+	}
+	return perms.Has(webhookChannelPerms), nil
+}
+
+// requireWebhookChannel checks that channelID is a text channel of serverID in
+// which userID holds webhookChannelPerms.
+func (s *Service) requireWebhookChannel(ctx context.Context, serverID, userID, channelID string) error {
+	ch, err := s.getChannel(ctx, channelID)
+	if err != nil {
+		return err
+	}
+	if ch == nil || ch.ServerID != serverID {
+		return fmt.Errorf("channel not found")
+	}
+	if ch.Type != "text" {
+		return fmt.Errorf("webhooks can only post into text channels")
+	}
+	_, err = s.requireChannelPermission(ctx, ch, userID, webhookChannelPerms)
+	return err
+}
+
+func validateWebhook(name, avatarURL string) (string, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", "", fmt.Errorf("webhook name cannot be empty")
+	}
+	return ValidateWebhookOverride(name, avatarURL)
+}
+
+func validateAvatarURL(avatarURL string) (string, error) {
+	avatarURL = strings.TrimSpace(avatarURL)
+	if avatarURL == "" {
+		return "", nil
+	}
+	if len(avatarURL) > maxWebhookAvatarURL {
+		return "", fmt.Errorf("avatar URL cannot exceed %d characters", maxWebhookAvatarURL)
+	}
+	u, err := url.Parse(avatarURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return "", fmt.Errorf("avatar URL must be an http(s) URL")
+	}
+	return avatarURL, nil
+}
+
+// newWebhookToken returns a random URL-safe token and the hash stored for it.
+// Complexity: O(1)
+func newWebhookToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, hashWebhookToken(token), nil
+}
+
+func hashWebhookToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,198 @@
+package server
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/concord-chat/concord/internal/cache"
+	"github.com/concord-chat/concord/internal/store/sqlite"
+)
+
+// setupService returns a Service on a migrated SQLite database with two servers:
+// srv-1 (invite code "join-1") with an owner, an admin and a member, and srv-2.
+func setupService(t *testing.T) *Service {
+	t.Helper()
+	logger := zerolog.Nop()
+	db, err := sqlite.New(sqlite.Config{
+		Path:         filepath.Join(t.TempDir(), "test.db"),
+		MaxOpenConns: 1,
+		ForeignKeys:  true,
+		BusyTimeout:  5 * time.Second,
+	}, logger)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	ctx := context.Background()
+	require.NoError(t, sqlite.NewMigrator(db, logger).Migrate(ctx))
+
+	for _, stmt := range []string{
+		`INSERT INTO users (id, username) VALUES ('owner', 'owner'), ('admin', 'admin'), ('member', 'member')`,
+		`INSERT INTO users (id, username, is_bot, bot_owner_id) VALUES ('bot', 'bot', 1, 'member')`,
+		`INSERT INTO servers (id, name, owner_id, invite_code) VALUES ('srv-1', 'Test', 'owner', 'join-1'), ('srv-2', 'Other', 'owner', 'join-2')`,
+		`INSERT INTO server_invites (code, server_id, creator_id) VALUES ('join-1', 'srv-1', 'owner'), ('join-2', 'srv-2', 'owner')`,
+		`INSERT INTO server_members (server_id, user_id) VALUES ('srv-1', 'owner'), ('srv-1', 'admin'), ('srv-1', 'member')`,
+		`INSERT INTO channels (id, server_id, name, type, position) VALUES ('ch-1', 'srv-1', 'ci', 'text', 0), ('ch-2', 'srv-1', 'alerts', 'text', 1),
+			('vc-1', 'srv-1', 'Voice', 'voice', 2), ('ch-x', 'srv-2', 'elsewhere', 'text', 0)`,
+	} {
+		_, err := db.ExecContext(ctx, stmt)
+		require.NoError(t, err)
+	}
+	repo := NewRepository(db, NewStdlibTransactor(db.Conn()), logger)
+	for _, role := range PresetRoles("srv-1") {
+		require.NoError(t, repo.CreateRole(ctx, role))
+	}
+	require.NoError(t, repo.AddMemberRole(ctx, "srv-1", "admin", presetRoleID("srv-1", RoleAdmin)))
+	return NewService(repo, cache.NewLRU(100), logger)
+}
+
+func TestWebhooks_Lifecycle(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	wh, err := svc.CreateWebhook(ctx, "srv-1", "owner", "ch-1", " CI ", "https://example.com/ci.png")
+	require.NoError(t, err)
+	assert.Equal(t, "CI", wh.Name)
+	assert.NotEmpty(t, wh.Token)
+	assert.NotEqual(t, wh.Token, wh.TokenHash, "only a hash is stored")
+
+	got, err := svc.AuthenticateWebhook(ctx, wh.ID, wh.Token)
+	require.NoError(t, err)
+	assert.Equal(t, "ch-1", got.ChannelID)
+	assert.Equal(t, "owner", got.CreatedBy)
+
+	_, err = svc.AuthenticateWebhook(ctx, wh.ID, "wrong")
+	assert.ErrorIs(t, err, ErrInvalidWebhook)
+	_, err = svc.AuthenticateWebhook(ctx, "missing", wh.Token)
+	assert.ErrorIs(t, err, ErrInvalidWebhook)
+
+	list, err := svc.ListWebhooks(ctx, "srv-1", "owner")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Empty(t, list[0].Token)
+
+	updated, err := svc.UpdateWebhook(ctx, "srv-1", "owner", wh.ID, "Deploys", "", "ch-2")
+	require.NoError(t, err)
+	assert.Equal(t, "Deploys", updated.Name)
+	assert.Equal(t, "ch-2", updated.ChannelID)
+
+	regenerated, err := svc.RegenerateWebhookToken(ctx, "srv-1", "owner", wh.ID)
+	require.NoError(t, err)
+	_, err = svc.AuthenticateWebhook(ctx, wh.ID, wh.Token)
+	assert.ErrorIs(t, err, ErrInvalidWebhook, "the old URL stops working")
+	_, err = svc.AuthenticateWebhook(ctx, wh.ID, regenerated.Token)
+	assert.NoError(t, err)
+
+	require.NoError(t, svc.DeleteWebhook(ctx, "srv-1", "owner", wh.ID))
+	_, err = svc.AuthenticateWebhook(ctx, wh.ID, regenerated.Token)
+	assert.ErrorIs(t, err, ErrInvalidWebhook)
+
+	assert.Equal(t, []string{AuditWebhookDelete, AuditWebhookToken, AuditWebhookUpdate, AuditWebhookCreate},
+		auditActions(t, svc, AuditQuery{TargetType: "webhook"}))
+	entries, err := svc.AuditLog(ctx, "srv-1", "owner", AuditQuery{Action: AuditWebhookUpdate})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.JSONEq(t, `{"old_name":"CI","name":"Deploys","old_channel_id":"ch-1","channel_id":"ch-2"}`, string(entries[0].Details))
+}
+
+func TestWebhooks_RequireManageChannels(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	_, err := svc.CreateWebhook(ctx, "srv-1", "member", "ch-1", "CI", "")
+	assert.Error(t, err)
+	_, err = svc.ListWebhooks(ctx, "srv-1", "member")
+	assert.Error(t, err)
+
+	wh, err := svc.CreateWebhook(ctx, "srv-1", "owner", "ch-1", "CI", "")
+	require.NoError(t, err)
+	assert.Error(t, svc.DeleteWebhook(ctx, "srv-1", "member", wh.ID))
+	_, err = svc.RegenerateWebhookToken(ctx, "srv-1", "member", wh.ID)
+	assert.Error(t, err)
+}
+
+func TestWebhooks_ChannelPermissions(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+	_, err := svc.SetChannelOverwrite(ctx, "srv-1", "owner", Overwrite{ChannelID: "ch-2", TargetType: OverwriteRole, TargetID: DefaultRoleID("srv-1"), Deny: PermSendMessages})
+	require.NoError(t, err)
+
+	_, err = svc.CreateWebhook(ctx, "srv-1", "admin", "ch-2", "CI", "")
+	assert.Error(t, err, "managers cannot post through webhooks where they cannot send")
+	wh, err := svc.CreateWebhook(ctx, "srv-1", "admin", "ch-1", "CI", "")
+	require.NoError(t, err)
+	_, err = svc.UpdateWebhook(ctx, "srv-1", "admin", wh.ID, "CI", "", "ch-2")
+	assert.Error(t, err, "nor move webhooks there")
+
+	hidden, err := svc.CreateWebhook(ctx, "srv-1", "owner", "ch-2", "Alerts", "")
+	require.NoError(t, err)
+	list, err := svc.ListWebhooks(ctx, "srv-1", "admin")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, wh.ID, list[0].ID)
+	_, err = svc.RegenerateWebhookToken(ctx, "srv-1", "admin", hidden.ID)
+	assert.Error(t, err)
+	assert.Error(t, svc.DeleteWebhook(ctx, "srv-1", "admin", hidden.ID))
+
+	list, err = svc.ListWebhooks(ctx, "srv-1", "owner")
+	require.NoError(t, err)
+	assert.Len(t, list, 2)
+}
+
+func TestWebhooks_Validation(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	for name, args := range map[string][3]string{
+		"empty name":          {"ch-1", " ", ""},
+		"voice channel":       {"vc-1", "CI", ""},
+		"other server":        {"ch-x", "CI", ""},
+		"non-http avatar":     {"ch-1", "CI", "javascript:alert(1)"},
+		"relative avatar":     {"ch-1", "CI", "/img.png"},
+		"unknown channel":     {"nope", "CI", ""},
+		"name over the limit": {"ch-1", strings.Repeat("a", maxWebhookName+1), ""},
+	} {
+		_, err := svc.CreateWebhook(ctx, "srv-1", "owner", args[0], args[1], args[2])
+		assert.Error(t, err, name)
+	}
+
+	wh, err := svc.CreateWebhook(ctx, "srv-1", "owner", "ch-1", "CI", "")
+	require.NoError(t, err)
+	_, err = svc.UpdateWebhook(ctx, "srv-2", "owner", wh.ID, "CI", "", "")
+	assert.Error(t, err, "webhooks are scoped to their server")
+}
+
+func TestAddBotViaInvite_Roles(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	_, err := svc.AddBotViaInvite(ctx, "nope", "member", "bot", RoleMember)
+	assert.Error(t, err, "unknown invite")
+	_, err = svc.AddBotViaInvite(ctx, "join-1", "member", "bot", RoleModerator)
+	assert.Error(t, err, "moderator requires PermManageMembers")
+	_, err = svc.AddBotViaInvite(ctx, "join-1", "admin", "bot", RoleAdmin)
+	assert.Error(t, err, "cannot grant the actor's own role")
+	_, err = svc.AddBotViaInvite(ctx, "join-1", "owner", "bot", RoleOwner)
+	assert.Error(t, err, "bots cannot own servers")
+
+	srv, err := svc.AddBotViaInvite(ctx, "join-1", "admin", "bot", RoleModerator)
+	require.NoError(t, err)
+	assert.Equal(t, "srv-1", srv.ID)
+
+	_, err = svc.AddBotViaInvite(ctx, "join-1", "member", "bot", RoleMember)
+	require.NoError(t, err)
+	m, err := svc.repo.GetMember(ctx, "srv-1", "bot")
+	require.NoError(t, err)
+	assert.Equal(t, RoleModerator, m.Role, "an existing member keeps its role")
+
+	_, err = svc.AddBotViaInvite(ctx, "join-2", "member", "bot", RoleMember)
+	require.NoError(t, err)
+	ok, err := svc.IsMember(ctx, "srv-2", "bot")
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
@@ -24,31 +24,54 @@ func NewChatSearcher(db *DB, logger zerolog.Logger) *ChatSearcher {
 	}
 }
 
-// Search performs full-text search across messages in a channel using PostgreSQL tsvector.
-// Uses plainto_tsquery for safe user input (no special syntax needed).
-// ts_headline generates snippets with <mark> highlighting.
+// Search runs a parsed query over the given channels using PostgreSQL tsvector.
+// The text part is compiled with SearchQuery.TSQuery and evaluated by to_tsquery;
+// ts_headline generates snippets with <mark> highlighting and ts_rank orders results.
+// Filter-only queries return newest first.
 // Complexity: O(log n) -- GIN index lookup
-func (s *ChatSearcher) Search(ctx context.Context, channelID, query string, limit int) ([]*chat.SearchResult, error) {
+func (s *ChatSearcher) Search(ctx context.Context, q *chat.SearchQuery, channelIDs []string, limit int) ([]*chat.SearchResult, error) {
 	if limit <= 0 || limit > 50 {
 		limit = 20
 	}
+	if len(channelIDs) == 0 {
+		return nil, nil
+	}
+
+	filter, filterArgs := q.FilterSQL(channelIDs)
 
-	// plainto_tsquery safely converts user input into a tsquery (no special operators needed).
-	// ts_headline produces HTML-highlighted snippets matching the query.
-	// search_vector @@ plainto_tsquery uses the GIN index for fast lookup.
-	// ts_rank orders results by relevance.
-	sqlQuery := `
-		SELECT m.id, m.channel_id, m.author_id, m.content, m.type, m.edited_at, m.created_at,
-			u.username, COALESCE(u.avatar_url, ''),
-			ts_headline('english', m.content, plainto_tsquery('english', $1),
+	var sqlQuery string
+	var args []interface{}
+	if q.HasText() {
+		tsq := q.TSQuery()
+		sqlQuery = `
+		SELECT m.id, m.channel_id, c.server_id, m.author_id, m.content, m.type, m.edited_at, m.created_at,
+			COALESCE(m.override_name, u.username), COALESCE(m.override_avatar, u.avatar_url, ''),
+			ts_headline('english', m.content, to_tsquery('english', ?),
 				'StartSel=<mark>, StopSel=</mark>, MaxFragments=1, MaxWords=32') AS snippet
 		FROM messages m
 		INNER JOIN users u ON m.author_id = u.id
-		WHERE m.channel_id = $2 AND m.search_vector @@ plainto_tsquery('english', $3)
-		ORDER BY ts_rank(m.search_vector, plainto_tsquery('english', $4)) DESC
-		LIMIT $5`
+		INNER JOIN channels c ON m.channel_id = c.id
+		WHERE m.search_vector @@ to_tsquery('english', ?) AND ` + filter + `
+		ORDER BY ts_rank(m.search_vector, to_tsquery('english', ?)) DESC
+		LIMIT ?`
+		args = append(args, tsq, tsq)
+		args = append(args, filterArgs...)
+		args = append(args, tsq, limit)
+	} else {
+		sqlQuery = `
+		SELECT m.id, m.channel_id, c.server_id, m.author_id, m.content, m.type, m.edited_at, m.created_at,
+			COALESCE(m.override_name, u.username), COALESCE(m.override_avatar, u.avatar_url, ''), LEFT(m.content, 200) AS snippet
+		FROM messages m
+		INNER JOIN users u ON m.author_id = u.id
+		INNER JOIN channels c ON m.channel_id = c.id
+		WHERE ` + filter + `
+		ORDER BY m.created_at DESC
+		LIMIT ?`
+		args = append(args, filterArgs...)
+		args = append(args, limit)
+	}
 
-	rows, err := s.db.pool.Query(ctx, sqlQuery, query, channelID, query, query, limit)
+	rows, err := s.db.pool.Query(ctx, replacePlaceholders(sqlQuery), args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search messages: %w", err)
 	}
@@ -61,7 +84,7 @@ func (s *ChatSearcher) Search(ctx context.Context, channelID, query string, limi
 		var createdAt time.Time
 
 		if err := rows.Scan(
-			&sr.ID, &sr.ChannelID, &sr.AuthorID, &sr.Content, &sr.Type,
+			&sr.ID, &sr.ChannelID, &sr.ServerID, &sr.AuthorID, &sr.Content, &sr.Type,
 			&editedAt, &createdAt, &sr.AuthorName, &sr.AuthorAvatar,
 			&sr.Snippet,
 		); err != nil {
@@ -82,8 +105,8 @@ func (s *ChatSearcher) Search(ctx context.Context, channelID, query string, limi
 	}
 
 	s.logger.Info().
-		Str("channel_id", channelID).
-		Str("query", query).
+		Int("channels", len(channelIDs)).
+		Str("tsquery", q.TSQuery()).
 		Int("results", len(results)).
 		Msg("pg message search completed")
 
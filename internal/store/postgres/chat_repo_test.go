@@ -0,0 +1,23 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/concord-chat/concord/internal/chat/searchtest"
+	"github.com/concord-chat/concord/internal/observability"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChatSearcher_Contract(t *testing.T) {
+	skipIfNoPostgres(t)
+
+	logger := observability.NewNopLogger()
+	db, err := New(getTestPostgresConfig(), logger)
+	require.NoError(t, err)
+	defer db.Close()
+
+	require.NoError(t, NewMigrator(db, logger).Run(context.Background()))
+
+	searchtest.Run(t, NewAdapter(db.StdlibDB()), NewChatSearcher(db, logger))
+}
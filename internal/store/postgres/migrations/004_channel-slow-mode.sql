@@ -0,0 +1,2 @@
+-- Per-channel slow mode: minimum seconds between messages from the same user
+ALTER TABLE channels ADD COLUMN IF NOT EXISTS slow_mode_seconds INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,6 @@
+-- Delivery and read markers for direct messages
+ALTER TABLE friend_messages ADD COLUMN IF NOT EXISTS delivered_at TIMESTAMPTZ;
+ALTER TABLE friend_messages ADD COLUMN IF NOT EXISTS read_at TIMESTAMPTZ;
+
+-- Read receipts are symmetric: users who turn them off neither send nor see them
+ALTER TABLE user_preferences ADD COLUMN IF NOT EXISTS read_receipts BOOLEAN NOT NULL DEFAULT TRUE;
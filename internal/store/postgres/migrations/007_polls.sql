@@ -0,0 +1,30 @@
+-- Polls as a message type
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS messages_type_check;
+ALTER TABLE messages ADD CONSTRAINT messages_type_check CHECK (type IN ('text', 'system', 'file', 'poll'));
+
+-- Poll settings; the question is also the message content so it stays searchable
+CREATE TABLE IF NOT EXISTS polls (
+    message_id      TEXT PRIMARY KEY REFERENCES messages(id) ON DELETE CASCADE,
+    multiple_choice BOOLEAN NOT NULL DEFAULT FALSE,
+    anonymous       BOOLEAN NOT NULL DEFAULT FALSE,
+    closes_at       TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS poll_options (
+    id          TEXT PRIMARY KEY,
+    message_id  TEXT NOT NULL REFERENCES polls(message_id) ON DELETE CASCADE,
+    position    INTEGER NOT NULL,
+    label       TEXT NOT NULL,
+    UNIQUE (message_id, position)
+);
+
+CREATE TABLE IF NOT EXISTS poll_votes (
+    message_id  TEXT NOT NULL REFERENCES polls(message_id) ON DELETE CASCADE,
+    option_id   TEXT NOT NULL REFERENCES poll_options(id) ON DELETE CASCADE,
+    user_id     TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (message_id, user_id, option_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_poll_votes_option ON poll_votes(option_id);
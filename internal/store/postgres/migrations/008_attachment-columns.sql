@@ -0,0 +1,3 @@
+-- Align attachment columns with the SQLite schema so repositories can share queries
+ALTER TABLE attachments RENAME COLUMN size TO size_bytes;
+ALTER TABLE attachments RENAME COLUMN storage_path TO local_path;
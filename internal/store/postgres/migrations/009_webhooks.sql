@@ -0,0 +1,20 @@
+-- Incoming webhooks: a secret token URL that posts into one channel
+CREATE TABLE IF NOT EXISTS webhooks (
+    id TEXT PRIMARY KEY,
+    server_id TEXT NOT NULL REFERENCES servers(id) ON DELETE CASCADE,
+    channel_id TEXT NOT NULL REFERENCES channels(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    avatar_url TEXT NOT NULL DEFAULT '',
+    token_hash TEXT NOT NULL,
+    created_by TEXT NOT NULL REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_webhooks_server ON webhooks(server_id);
+CREATE INDEX IF NOT EXISTS idx_webhooks_channel ON webhooks(channel_id);
+
+-- Webhook posts keep the creator as author_id and carry their own display name/avatar.
+-- webhook_id has no foreign key so the history survives deleting the webhook.
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS author_type TEXT NOT NULL DEFAULT 'user';
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS webhook_id TEXT;
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS override_name TEXT;
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS override_avatar TEXT;
@@ -0,0 +1,22 @@
+-- Channel categories: named groups of channels with their own position in the server
+CREATE TABLE IF NOT EXISTS channel_categories (
+    id TEXT PRIMARY KEY,
+    server_id TEXT NOT NULL REFERENCES servers(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_channel_categories_server ON channel_categories(server_id);
+
+-- Channel positions are relative to their category (NULL = uncategorized)
+ALTER TABLE channels ADD COLUMN IF NOT EXISTS category_id TEXT REFERENCES channel_categories(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_channels_category ON channels(category_id);
+
+-- Renumber existing channels 0..n-1 per server, keeping their current order
+UPDATE channels SET position = (
+    SELECT COUNT(*) FROM channels c
+    WHERE c.server_id = channels.server_id
+      AND (COALESCE(c.position, 0) < COALESCE(channels.position, 0)
+        OR (COALESCE(c.position, 0) = COALESCE(channels.position, 0)
+          AND (c.created_at < channels.created_at OR (c.created_at = channels.created_at AND c.id < channels.id))))
+);
@@ -0,0 +1,8 @@
+-- Scope audit log entries to a server. Details are stored as JSON text like the
+-- other JSON columns, so the portable repository queries can scan them into strings.
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS server_id TEXT NOT NULL REFERENCES servers(id) ON DELETE CASCADE;
+ALTER TABLE audit_log ALTER COLUMN details TYPE TEXT;
+CREATE INDEX IF NOT EXISTS idx_audit_server ON audit_log(server_id, id DESC);
+
+-- View audit log (4096) is granted to the preset Admin role
+UPDATE server_roles SET permissions = permissions | 4096 WHERE id = server_id || ':admin';
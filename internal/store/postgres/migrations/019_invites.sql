@@ -0,0 +1,9 @@
+-- server_invites now backs every invite of a server. Vanity invites carry a custom
+-- code chosen by the server's managers.
+ALTER TABLE server_invites ADD COLUMN IF NOT EXISTS vanity BOOLEAN NOT NULL DEFAULT FALSE;
+CREATE INDEX IF NOT EXISTS idx_server_invites_server ON server_invites(server_id);
+
+-- Carry over the single invite code every server had so far
+INSERT INTO server_invites (code, server_id, creator_id)
+SELECT invite_code, id, owner_id FROM servers WHERE invite_code IS NOT NULL AND invite_code <> ''
+ON CONFLICT (code) DO NOTHING;
@@ -0,0 +1,14 @@
+-- Users banned from a server cannot join it again until the ban is lifted or
+-- expires. A NULL expires_at never expires.
+CREATE TABLE IF NOT EXISTS server_bans (
+    server_id TEXT NOT NULL REFERENCES servers(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    actor_id TEXT NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    expires_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (server_id, user_id)
+);
+
+-- Timed out members cannot send messages or speak until timeout_until
+ALTER TABLE server_members ADD COLUMN IF NOT EXISTS timeout_until TIMESTAMPTZ;
@@ -0,0 +1,9 @@
+-- Pending ownership transfers: the owner offers the server to a member, who must
+-- accept before expires_at. At most one transfer per server is pending.
+CREATE TABLE IF NOT EXISTS server_ownership_transfers (
+    server_id TEXT PRIMARY KEY REFERENCES servers(id) ON DELETE CASCADE,
+    from_id TEXT NOT NULL,
+    to_id TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,16 @@
+-- Server templates: a JSON snapshot of a server's structure (settings, roles,
+-- categories, channels and role overwrites) that new servers can be created
+-- from. Syncing a template re-captures its source server and bumps version.
+CREATE TABLE IF NOT EXISTS server_templates (
+    code TEXT PRIMARY KEY,
+    server_id TEXT NOT NULL REFERENCES servers(id) ON DELETE CASCADE,
+    creator_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    version INTEGER NOT NULL DEFAULT 1,
+    snapshot TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_server_templates_server ON server_templates(server_id);
@@ -0,0 +1,14 @@
+-- Channel positions are unique within their category (NULL = uncategorized).
+-- Renumber channels 0..n-1 per category first, keeping their current order, in
+-- case concurrent channel creations left duplicate positions behind.
+UPDATE channels SET position = ranked.position
+FROM (
+    SELECT id, ROW_NUMBER() OVER (
+        PARTITION BY server_id, COALESCE(category_id, '')
+        ORDER BY position, created_at, id
+    ) - 1 AS position
+    FROM channels
+) ranked
+WHERE ranked.id = channels.id AND ranked.position <> channels.position;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_channels_position ON channels(server_id, COALESCE(category_id, ''), position);
@@ -0,0 +1,107 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/concord-chat/concord/internal/chat"
+)
+
+// ChatSearcher provides SQLite-specific full-text search for messages.
+// It uses the messages_fts FTS5 table kept in sync by triggers.
+type ChatSearcher struct {
+	db     *DB
+	logger zerolog.Logger
+}
+
+// NewChatSearcher creates a new SQLite chat searcher.
+func NewChatSearcher(db *DB, logger zerolog.Logger) *ChatSearcher {
+	return &ChatSearcher{
+		db:     db,
+		logger: logger.With().Str("component", "sqlite_chat_search").Logger(),
+	}
+}
+
+// Search runs a parsed query over the given channels using FTS5.
+// The text part is compiled with SearchQuery.FTS5; snippet() highlights matches with
+// <mark> tags and FTS5 rank (bm25) orders results. Filter-only queries return newest first.
+// Complexity: O(log n) — FTS5 inverted index lookup, or the (channel_id, created_at) index
+func (s *ChatSearcher) Search(ctx context.Context, q *chat.SearchQuery, channelIDs []string, limit int) ([]*chat.SearchResult, error) {
+	if limit <= 0 || limit > 50 {
+		limit = 20
+	}
+	if len(channelIDs) == 0 {
+		return nil, nil
+	}
+
+	filter, args := q.FilterSQL(channelIDs)
+
+	var sqlQuery string
+	if q.HasText() {
+		sqlQuery = `SELECT m.id, m.channel_id, c.server_id, m.author_id, m.content, m.type, m.edited_at, m.created_at,
+				COALESCE(m.override_name, u.username), COALESCE(m.override_avatar, u.avatar_url, ''),
+				snippet(messages_fts, 0, '<mark>', '</mark>', '...', 32) AS snippet
+			FROM messages_fts
+			INNER JOIN messages m ON messages_fts.rowid = m.rowid
+			INNER JOIN users u ON m.author_id = u.id
+			INNER JOIN channels c ON m.channel_id = c.id
+			WHERE messages_fts MATCH ? AND ` + filter + `
+			ORDER BY rank
+			LIMIT ?`
+		args = append([]interface{}{q.FTS5()}, args...)
+	} else {
+		sqlQuery = `SELECT m.id, m.channel_id, c.server_id, m.author_id, m.content, m.type, m.edited_at, m.created_at,
+				COALESCE(m.override_name, u.username), COALESCE(m.override_avatar, u.avatar_url, ''), SUBSTR(m.content, 1, 200) AS snippet
+			FROM messages m
+			INNER JOIN users u ON m.author_id = u.id
+			INNER JOIN channels c ON m.channel_id = c.id
+			WHERE ` + filter + `
+			ORDER BY m.created_at DESC
+			LIMIT ?`
+	}
+	args = append(args, limit)
+
+	rows, err := s.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search messages: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*chat.SearchResult
+	for rows.Next() {
+		var sr chat.SearchResult
+		var editedAt sql.NullTime
+		var createdAt time.Time
+		if err := rows.Scan(
+			&sr.ID, &sr.ChannelID, &sr.ServerID, &sr.AuthorID, &sr.Content, &sr.Type,
+			&editedAt, &createdAt, &sr.AuthorName, &sr.AuthorAvatar,
+			&sr.Snippet,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		}
+
+		sr.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+		if editedAt.Valid {
+			t := editedAt.Time.UTC().Format(time.RFC3339)
+			sr.EditedAt = &t
+		}
+
+		results = append(results, &sr)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating search results: %w", err)
+	}
+
+	s.logger.Info().
+		Int("channels", len(channelIDs)).
+		Str("match", q.FTS5()).
+		Int("results", len(results)).
+		Msg("sqlite message search completed")
+
+	return results, nil
+}
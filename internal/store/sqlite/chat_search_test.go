@@ -0,0 +1,20 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/concord-chat/concord/internal/chat/searchtest"
+	"github.com/concord-chat/concord/internal/observability"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChatSearcher_Contract(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	logger := observability.NewNopLogger()
+	require.NoError(t, NewMigrator(db, logger).Migrate(context.Background()))
+
+	searchtest.Run(t, db, NewChatSearcher(db, logger))
+}
@@ -257,6 +257,14 @@ func (m *Migrator) filterPendingMigrations(all, applied []Migration) []Migration
 	return pending
 }
 
+// foreignKeysOffMarker opts a migration out of foreign key enforcement. SQLite can only
+// change a table's constraints by rebuilding it, and dropping a parent table with
+// enforcement on would cascade-delete its children. PRAGMA foreign_keys is a no-op inside
+// a transaction, so marked migrations run on a dedicated connection with enforcement
+// turned off around the transaction and PRAGMA foreign_key_check run before commit.
+// See https://www.sqlite.org/lang_altertable.html#otheralter
+const foreignKeysOffMarker = "-- migrate:foreign_keys=off"
+
 // applyMigration applies a single migration within a transaction
 func (m *Migrator) applyMigration(ctx context.Context, migration Migration) error {
 	m.logger.Info().
@@ -266,20 +274,14 @@ func (m *Migrator) applyMigration(ctx context.Context, migration Migration) erro
 
 	start := time.Now()
 
-	err := m.db.InTransaction(ctx, func(tx *sql.Tx) error {
-		// Execute migration SQL
-		if _, err := tx.ExecContext(ctx, migration.SQL); err != nil {
-			return fmt.Errorf("failed to execute migration SQL: %w", err)
-		}
-
-		// Record migration in schema_migrations table
-		insertQuery := "INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)"
-		if _, err := tx.ExecContext(ctx, insertQuery, migration.Version, migration.Name, time.Now()); err != nil {
-			return fmt.Errorf("failed to record migration: %w", err)
-		}
-
-		return nil
-	})
+	var err error
+	if strings.HasPrefix(strings.TrimSpace(migration.SQL), foreignKeysOffMarker) {
+		err = m.applyWithoutForeignKeys(ctx, migration)
+	} else {
+		err = m.db.InTransaction(ctx, func(tx *sql.Tx) error {
+			return m.execMigration(ctx, tx, migration)
+		})
+	}
 
 	duration := time.Since(start)
 
@@ -302,6 +304,78 @@ func (m *Migrator) applyMigration(ctx context.Context, migration Migration) erro
 	return nil
 }
 
+// execMigration runs the migration SQL and records it in schema_migrations.
+func (m *Migrator) execMigration(ctx context.Context, tx *sql.Tx, migration Migration) error {
+	// Execute migration SQL
+	if _, err := tx.ExecContext(ctx, migration.SQL); err != nil {
+		return fmt.Errorf("failed to execute migration SQL: %w", err)
+	}
+
+	// Record migration in schema_migrations table
+	insertQuery := "INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)"
+	if _, err := tx.ExecContext(ctx, insertQuery, migration.Version, migration.Name, time.Now()); err != nil {
+		return fmt.Errorf("failed to record migration: %w", err)
+	}
+
+	return nil
+}
+
+// applyWithoutForeignKeys runs a migration marked with foreignKeysOffMarker.
+// Complexity: O(m + n) where m is the migration cost and n the rows checked by foreign_key_check
+func (m *Migrator) applyWithoutForeignKeys(ctx context.Context, migration Migration) (err error) {
+	conn, err := m.db.Conn().Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Close()
+
+	var enabled bool
+	if err := conn.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&enabled); err != nil {
+		return fmt.Errorf("failed to read foreign_keys pragma: %w", err)
+	}
+	if enabled {
+		if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys=OFF"); err != nil {
+			return fmt.Errorf("failed to disable foreign keys: %w", err)
+		}
+		defer func() {
+			if _, reErr := conn.ExecContext(context.Background(), "PRAGMA foreign_keys=ON"); reErr != nil && err == nil {
+				err = fmt.Errorf("failed to re-enable foreign keys: %w", reErr)
+			}
+		}()
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if err := m.execMigration(ctx, tx, migration); err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx, "PRAGMA foreign_key_check")
+	if err != nil {
+		return fmt.Errorf("failed to check foreign keys: %w", err)
+	}
+	violation := rows.Next()
+	if cerr := rows.Close(); cerr != nil {
+		return fmt.Errorf("failed to check foreign keys: %w", cerr)
+	}
+	if violation {
+		return fmt.Errorf("migration left foreign key violations")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
 // Reset drops all tables and resets the database (DANGEROUS)
 func (m *Migrator) Reset(ctx context.Context) error {
 	m.logger.Warn().Msg("resetting database - this will drop all tables")
@@ -0,0 +1,20 @@
+-- Link previews (OpenGraph/oEmbed) attached to messages after sending
+CREATE TABLE IF NOT EXISTS message_embeds (
+    message_id  TEXT NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    position    INTEGER NOT NULL,
+    url         TEXT NOT NULL,
+    title       TEXT NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    image_url   TEXT NOT NULL DEFAULT '',
+    site_name   TEXT NOT NULL DEFAULT '',
+    provider    TEXT NOT NULL DEFAULT 'opengraph',
+    created_at  DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (message_id, position)
+);
+
+-- Per-user privacy preferences
+CREATE TABLE IF NOT EXISTS user_preferences (
+    user_id       TEXT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    link_previews INTEGER NOT NULL DEFAULT 1,
+    updated_at    DATETIME DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,16 @@
+-- Durable outbox for messages that could not be delivered yet (P2P peers offline,
+-- central server unreachable). Times are fixed-width UTC text so they compare lexically.
+CREATE TABLE IF NOT EXISTS outbox (
+    id              TEXT PRIMARY KEY,
+    target          TEXT NOT NULL CHECK(target IN ('p2p','server')),
+    recipient       TEXT NOT NULL,
+    content         TEXT NOT NULL,
+    state           TEXT NOT NULL DEFAULT 'queued' CHECK(state IN ('queued','sent','delivered','failed')),
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    last_error      TEXT NOT NULL DEFAULT '',
+    next_attempt_at TEXT NOT NULL,
+    created_at      TEXT NOT NULL,
+    updated_at      TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_outbox_due ON outbox(state, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_outbox_recipient ON outbox(target, recipient, created_at);
@@ -0,0 +1,6 @@
+-- Delivery and read markers for direct messages
+ALTER TABLE friend_messages ADD COLUMN delivered_at DATETIME;
+ALTER TABLE friend_messages ADD COLUMN read_at DATETIME;
+
+-- Read receipts are symmetric: users who turn them off neither send nor see them
+ALTER TABLE user_preferences ADD COLUMN read_receipts INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,61 @@
+-- migrate:foreign_keys=off
+-- Polls as a message type. SQLite cannot alter a CHECK constraint, so the messages
+-- table is rebuilt (rowids are kept so the external-content FTS index stays valid).
+CREATE TABLE messages_new (
+    id          TEXT PRIMARY KEY,
+    channel_id  TEXT NOT NULL REFERENCES channels(id) ON DELETE CASCADE,
+    author_id   TEXT NOT NULL REFERENCES users(id),
+    content     TEXT NOT NULL,
+    type        TEXT DEFAULT 'text' CHECK(type IN ('text', 'file', 'system', 'poll')),
+    edited_at   DATETIME,
+    created_at  DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO messages_new (rowid, id, channel_id, author_id, content, type, edited_at, created_at)
+    SELECT rowid, id, channel_id, author_id, content, type, edited_at, created_at FROM messages;
+
+DROP TABLE messages;
+ALTER TABLE messages_new RENAME TO messages;
+
+CREATE INDEX IF NOT EXISTS idx_messages_channel_created ON messages(channel_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_messages_author ON messages(author_id);
+
+CREATE TRIGGER IF NOT EXISTS messages_fts_insert AFTER INSERT ON messages BEGIN
+    INSERT INTO messages_fts(rowid, content) VALUES (new.rowid, new.content);
+END;
+
+CREATE TRIGGER IF NOT EXISTS messages_fts_delete AFTER DELETE ON messages BEGIN
+    INSERT INTO messages_fts(messages_fts, rowid, content) VALUES ('delete', old.rowid, old.content);
+END;
+
+CREATE TRIGGER IF NOT EXISTS messages_fts_update AFTER UPDATE OF content ON messages BEGIN
+    INSERT INTO messages_fts(messages_fts, rowid, content) VALUES ('delete', old.rowid, old.content);
+    INSERT INTO messages_fts(rowid, content) VALUES (new.rowid, new.content);
+END;
+
+-- Poll settings; the question is also the message content so it stays searchable
+CREATE TABLE IF NOT EXISTS polls (
+    message_id      TEXT PRIMARY KEY REFERENCES messages(id) ON DELETE CASCADE,
+    multiple_choice INTEGER NOT NULL DEFAULT 0,
+    anonymous       INTEGER NOT NULL DEFAULT 0,
+    closes_at       DATETIME,
+    created_at      DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS poll_options (
+    id          TEXT PRIMARY KEY,
+    message_id  TEXT NOT NULL REFERENCES polls(message_id) ON DELETE CASCADE,
+    position    INTEGER NOT NULL,
+    label       TEXT NOT NULL,
+    UNIQUE (message_id, position)
+);
+
+CREATE TABLE IF NOT EXISTS poll_votes (
+    message_id  TEXT NOT NULL REFERENCES polls(message_id) ON DELETE CASCADE,
+    option_id   TEXT NOT NULL REFERENCES poll_options(id) ON DELETE CASCADE,
+    user_id     TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at  DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (message_id, user_id, option_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_poll_votes_option ON poll_votes(option_id);
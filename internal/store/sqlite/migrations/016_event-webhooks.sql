@@ -0,0 +1,34 @@
+-- Outgoing webhooks: external endpoints subscribed to server events
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id                   TEXT PRIMARY KEY,
+    server_id            TEXT NOT NULL REFERENCES servers(id) ON DELETE CASCADE,
+    url                  TEXT NOT NULL,
+    events               TEXT NOT NULL, -- comma-separated event types
+    secret               TEXT NOT NULL,
+    enabled              INTEGER NOT NULL DEFAULT 1,
+    consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    disabled_reason      TEXT NOT NULL DEFAULT '',
+    created_by           TEXT NOT NULL REFERENCES users(id),
+    created_at           DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_subscriptions_server ON webhook_subscriptions(server_id);
+
+-- Delivery queue and log: one row per event and subscription, retried with backoff
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id              TEXT PRIMARY KEY,
+    subscription_id TEXT NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    event_id        TEXT NOT NULL,
+    event_type      TEXT NOT NULL,
+    payload         TEXT NOT NULL,
+    status          TEXT NOT NULL DEFAULT 'pending' CHECK(status IN ('pending','delivered','failed')),
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME NOT NULL,
+    last_status     INTEGER NOT NULL DEFAULT 0,
+    last_error      TEXT NOT NULL DEFAULT '',
+    created_at      DATETIME NOT NULL,
+    delivered_at    DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription ON webhook_deliveries(subscription_id, created_at);
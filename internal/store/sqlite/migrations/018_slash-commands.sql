@@ -0,0 +1,38 @@
+-- Slash commands registered by bots in the servers they belong to
+CREATE TABLE IF NOT EXISTS slash_commands (
+    id           TEXT PRIMARY KEY,
+    server_id    TEXT NOT NULL REFERENCES servers(id) ON DELETE CASCADE,
+    bot_id       TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name         TEXT NOT NULL,
+    description  TEXT NOT NULL DEFAULT '',
+    options      TEXT NOT NULL DEFAULT '[]', -- JSON array of typed options
+    callback_url TEXT NOT NULL DEFAULT '',
+    secret       TEXT NOT NULL,
+    created_at   DATETIME NOT NULL,
+    updated_at   DATETIME NOT NULL,
+    UNIQUE(server_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_slash_commands_bot ON slash_commands(bot_id);
+
+-- Command invocations awaiting (or holding) the bot's response
+CREATE TABLE IF NOT EXISTS command_interactions (
+    id           TEXT PRIMARY KEY,
+    command_id   TEXT NOT NULL REFERENCES slash_commands(id) ON DELETE CASCADE,
+    command_name TEXT NOT NULL,
+    server_id    TEXT NOT NULL REFERENCES servers(id) ON DELETE CASCADE,
+    channel_id   TEXT NOT NULL REFERENCES channels(id) ON DELETE CASCADE,
+    user_id      TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    bot_id       TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    options      TEXT NOT NULL DEFAULT '{}', -- JSON object of option values
+    status       TEXT NOT NULL DEFAULT 'pending' CHECK(status IN ('pending','responded')),
+    response     TEXT NOT NULL DEFAULT '',
+    ephemeral    INTEGER NOT NULL DEFAULT 0,
+    message_id   TEXT NOT NULL DEFAULT '',
+    created_at   DATETIME NOT NULL,
+    expires_at   DATETIME NOT NULL,
+    responded_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_command_interactions_bot ON command_interactions(bot_id, status, expires_at);
+CREATE INDEX IF NOT EXISTS idx_command_interactions_created ON command_interactions(created_at);
@@ -0,0 +1,24 @@
+-- Custom emoji uploaded to a server; the image is kept in file storage
+CREATE TABLE IF NOT EXISTS server_emoji (
+    id         TEXT PRIMARY KEY,
+    server_id  TEXT NOT NULL REFERENCES servers(id) ON DELETE CASCADE,
+    name       TEXT NOT NULL,
+    mime_type  TEXT NOT NULL,
+    size_bytes INTEGER NOT NULL,
+    local_path TEXT NOT NULL,
+    created_by TEXT NOT NULL REFERENCES users(id),
+    created_at DATETIME NOT NULL,
+    UNIQUE(server_id, name)
+);
+
+-- Message reactions. emoji is the Unicode emoji, or the custom emoji ID when emoji_id is set.
+CREATE TABLE IF NOT EXISTS message_reactions (
+    message_id TEXT NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    user_id    TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    emoji      TEXT NOT NULL,
+    emoji_id   TEXT REFERENCES server_emoji(id) ON DELETE CASCADE,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (message_id, user_id, emoji)
+);
+
+CREATE INDEX IF NOT EXISTS idx_message_reactions_emoji ON message_reactions(emoji_id);
@@ -0,0 +1,21 @@
+-- Personal bookmarks of channel messages and direct messages. There is no foreign key
+-- to the message: the snapshot columns keep a bookmark readable after the message is deleted.
+CREATE TABLE IF NOT EXISTS bookmarks (
+    id                 TEXT PRIMARY KEY,
+    user_id            TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind               TEXT NOT NULL CHECK (kind IN ('channel', 'dm')),
+    message_id         TEXT NOT NULL,
+    channel_id         TEXT NOT NULL DEFAULT '',
+    peer_id            TEXT NOT NULL DEFAULT '',
+    author_id          TEXT NOT NULL,
+    author_name        TEXT NOT NULL DEFAULT '',
+    content            TEXT NOT NULL,
+    message_created_at TEXT NOT NULL,
+    note               TEXT NOT NULL DEFAULT '',
+    folder             TEXT NOT NULL DEFAULT '',
+    created_at         DATETIME NOT NULL,
+    updated_at         DATETIME NOT NULL,
+    UNIQUE(user_id, message_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bookmarks_user_folder ON bookmarks(user_id, folder, created_at DESC);
@@ -0,0 +1,48 @@
+-- Server-defined roles with a 64-bit permission bitfield. Higher positions outrank
+-- lower ones; the default role "{server_id}:everyone" sits at position 0 and applies
+-- to every member without being assigned. The server owner outranks every role.
+CREATE TABLE IF NOT EXISTS server_roles (
+    id          TEXT PRIMARY KEY,
+    server_id   TEXT NOT NULL REFERENCES servers(id) ON DELETE CASCADE,
+    name        TEXT NOT NULL,
+    color       INTEGER NOT NULL DEFAULT 0,
+    position    INTEGER NOT NULL DEFAULT 0,
+    permissions INTEGER NOT NULL DEFAULT 0,
+    created_at  DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_server_roles_server ON server_roles(server_id, position);
+
+CREATE TABLE IF NOT EXISTS server_member_roles (
+    server_id TEXT NOT NULL,
+    user_id   TEXT NOT NULL,
+    role_id   TEXT NOT NULL REFERENCES server_roles(id) ON DELETE CASCADE,
+    PRIMARY KEY (server_id, user_id, role_id),
+    FOREIGN KEY (server_id, user_id) REFERENCES server_members(server_id, user_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_server_member_roles_role ON server_member_roles(role_id);
+
+-- Map the fixed owner/admin/moderator/member roles to preset roles of every server.
+-- Permission bits: manage server 1, manage channels 2, manage members 4, create invite 8,
+-- send messages 16, manage messages 32, manage emoji 64, manage roles 128.
+INSERT INTO server_roles (id, server_id, name, color, position, permissions)
+SELECT id || ':everyone', id, '@everyone', 0, 0, 24 FROM servers;
+
+INSERT INTO server_roles (id, server_id, name, color, position, permissions)
+SELECT id || ':moderator', id, 'Moderator', 3447003, 1, 56 FROM servers;
+
+INSERT INTO server_roles (id, server_id, name, color, position, permissions)
+SELECT id || ':admin', id, 'Admin', 15158332, 2, 254 FROM servers;
+
+-- Owners are identified by servers.owner_id; an "owner" row of anyone else becomes Admin
+INSERT INTO server_member_roles (server_id, user_id, role_id)
+SELECT sm.server_id, sm.user_id, sm.server_id || ':admin'
+FROM server_members sm
+JOIN servers s ON s.id = sm.server_id
+WHERE sm.role = 'admin' OR (sm.role = 'owner' AND sm.user_id <> s.owner_id);
+
+INSERT INTO server_member_roles (server_id, user_id, role_id)
+SELECT server_id, user_id, server_id || ':moderator' FROM server_members WHERE role = 'moderator';
+
+ALTER TABLE server_members DROP COLUMN role;
@@ -0,0 +1,16 @@
+-- Per-channel permission overwrites for a role or a member. Denied bits are removed
+-- from the target's server-wide permissions and allowed bits are added, applying
+-- the default role first, then the member's roles, then the member.
+CREATE TABLE IF NOT EXISTS channel_overwrites (
+    channel_id  TEXT NOT NULL REFERENCES channels(id) ON DELETE CASCADE,
+    target_type TEXT NOT NULL CHECK (target_type IN ('role', 'member')),
+    target_id   TEXT NOT NULL,
+    allow       INTEGER NOT NULL DEFAULT 0,
+    deny        INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (channel_id, target_type, target_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_channel_overwrites_target ON channel_overwrites(target_type, target_id);
+
+-- View channel (512), connect (1024) and speak (2048) are granted to everyone by default
+UPDATE server_roles SET permissions = permissions | 3584 WHERE id = server_id || ':everyone';
@@ -0,0 +1,17 @@
+-- Audit log of administrative actions in a server. details is a JSON object.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    server_id   TEXT NOT NULL REFERENCES servers(id) ON DELETE CASCADE,
+    actor_id    TEXT NOT NULL,
+    action      TEXT NOT NULL,
+    target_type TEXT NOT NULL,
+    target_id   TEXT NOT NULL,
+    details     TEXT,
+    created_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_server ON audit_log(server_id, id DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_actor ON audit_log(actor_id);
+
+-- View audit log (4096) is granted to the preset Admin role
+UPDATE server_roles SET permissions = permissions | 4096 WHERE id = server_id || ':admin';
@@ -0,0 +1,18 @@
+-- Invites of a server: several can exist at once, each with an optional expiry and
+-- use limit. max_uses = 0 means unlimited; a NULL expires_at never expires.
+CREATE TABLE IF NOT EXISTS server_invites (
+    code       TEXT PRIMARY KEY,
+    server_id  TEXT NOT NULL REFERENCES servers(id) ON DELETE CASCADE,
+    creator_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    max_uses   INTEGER NOT NULL DEFAULT 0,
+    uses       INTEGER NOT NULL DEFAULT 0,
+    expires_at DATETIME,
+    vanity     INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_server_invites_server ON server_invites(server_id);
+
+-- Carry over the single invite code every server had so far
+INSERT OR IGNORE INTO server_invites (code, server_id, creator_id)
+SELECT invite_code, id, owner_id FROM servers WHERE invite_code IS NOT NULL AND invite_code <> '';
@@ -0,0 +1,16 @@
+-- Channel positions are unique within their category (NULL = uncategorized).
+-- Renumber channels 0..n-1 per category first, keeping their current order, in
+-- case concurrent channel creations left duplicate positions behind. The ranks are
+-- computed into a temporary table because the UPDATE would see its own writes.
+CREATE TEMP TABLE channel_positions AS
+SELECT id, ROW_NUMBER() OVER (
+    PARTITION BY server_id, COALESCE(category_id, '')
+    ORDER BY position, created_at, id
+) - 1 AS position
+FROM channels;
+
+UPDATE channels SET position = (SELECT p.position FROM channel_positions p WHERE p.id = channels.id);
+
+DROP TABLE channel_positions;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_channels_position ON channels(server_id, COALESCE(category_id, ''), position);
@@ -192,3 +192,43 @@ func TestMigrator_EnsureMigrationsTable(t *testing.T) {
 	require.NoError(t, err)
 }
 
+
+func TestMigrator_ForeignKeysOffMigration(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	migrator := NewMigrator(db, observability.NewNopLogger())
+	require.NoError(t, migrator.ensureMigrationsTable(ctx))
+
+	for _, stmt := range []string{
+		`CREATE TABLE parent (id TEXT PRIMARY KEY, kind TEXT CHECK(kind IN ('a')))`,
+		`CREATE TABLE child (parent_id TEXT NOT NULL REFERENCES parent(id) ON DELETE CASCADE)`,
+		`INSERT INTO parent (id, kind) VALUES ('p1', 'a')`,
+		`INSERT INTO child (parent_id) VALUES ('p1')`,
+	} {
+		_, err := db.ExecContext(ctx, stmt)
+		require.NoError(t, err)
+	}
+
+	// Rebuilding the parent would cascade-delete the child with enforcement on.
+	rebuild := Migration{Version: 900, Name: "rebuild", SQL: foreignKeysOffMarker + `
+		CREATE TABLE parent_new (id TEXT PRIMARY KEY, kind TEXT CHECK(kind IN ('a', 'b')));
+		INSERT INTO parent_new SELECT * FROM parent;
+		DROP TABLE parent;
+		ALTER TABLE parent_new RENAME TO parent;`}
+	require.NoError(t, migrator.applyMigration(ctx, rebuild))
+
+	var children int
+	require.NoError(t, db.QueryRowContext(ctx, `SELECT COUNT(*) FROM child`).Scan(&children))
+	assert.Equal(t, 1, children)
+	_, err := db.ExecContext(ctx, `INSERT INTO parent (id, kind) VALUES ('p2', 'b')`)
+	require.NoError(t, err)
+
+	// Violations abort the migration.
+	broken := Migration{Version: 901, Name: "broken", SQL: foreignKeysOffMarker + `
+		INSERT INTO child (parent_id) VALUES ('missing');`}
+	assert.Error(t, migrator.applyMigration(ctx, broken))
+	require.NoError(t, db.QueryRowContext(ctx, `SELECT COUNT(*) FROM child`).Scan(&children))
+	assert.Equal(t, 1, children)
+}
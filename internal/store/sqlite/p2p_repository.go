@@ -12,6 +12,7 @@ type P2PMessage struct {
 	Direction string `json:"direction"` // "sent" | "received"
 	Content   string `json:"content"`
 	SentAt    string `json:"sent_at"`
+	Status    string `json:"status,omitempty"` // estado no outbox para mensagens enviadas
 }
 
 // P2PRepo implementa persistência de mensagens P2P.
@@ -25,11 +26,13 @@ func NewP2PRepo(db *DB) *P2PRepo {
 }
 
 // SaveMessage persiste uma mensagem P2P.
+// Idempotente por ID: reenvios do mesmo peer não duplicam o histórico.
 // Complexity: O(1).
 func (r *P2PRepo) SaveMessage(ctx context.Context, msg P2PMessage) error {
 	_, err := r.db.ExecContext(ctx,
 		`INSERT INTO p2p_messages (id, peer_id, direction, content, sent_at)
-		 VALUES (?, ?, ?, ?, ?)`,
+		 VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT(id) DO NOTHING`,
 		msg.ID, msg.PeerID, msg.Direction, msg.Content, msg.SentAt,
 	)
 	if err != nil {
@@ -39,13 +42,15 @@ func (r *P2PRepo) SaveMessage(ctx context.Context, msg P2PMessage) error {
 }
 
 // GetMessages retorna mensagens com um peer ordenadas por sent_at ASC.
+// Mensagens enviadas incluem o estado de entrega registrado no outbox.
 // Complexity: O(n) onde n = limit.
 func (r *P2PRepo) GetMessages(ctx context.Context, peerID string, limit int) ([]P2PMessage, error) {
 	rows, err := r.db.QueryContext(ctx,
-		`SELECT id, peer_id, direction, content, sent_at
-		 FROM p2p_messages
-		 WHERE peer_id = ?
-		 ORDER BY sent_at ASC
+		`SELECT p.id, p.peer_id, p.direction, p.content, p.sent_at, COALESCE(o.state, '')
+		 FROM p2p_messages p
+		 LEFT JOIN outbox o ON o.id = p.id AND p.direction = 'sent'
+		 WHERE p.peer_id = ?
+		 ORDER BY p.sent_at ASC
 		 LIMIT ?`,
 		peerID, limit,
 	)
@@ -57,7 +62,7 @@ func (r *P2PRepo) GetMessages(ctx context.Context, peerID string, limit int) ([]
 	var msgs []P2PMessage
 	for rows.Next() {
 		var m P2PMessage
-		if err := rows.Scan(&m.ID, &m.PeerID, &m.Direction, &m.Content, &m.SentAt); err != nil {
+		if err := rows.Scan(&m.ID, &m.PeerID, &m.Direction, &m.Content, &m.SentAt, &m.Status); err != nil {
 			return nil, fmt.Errorf("p2p_repo: scan: %w", err)
 		}
 		msgs = append(msgs, m)
@@ -97,3 +97,31 @@ func TestP2PRepo_LimitResults(t *testing.T) {
 	require.NoError(t, err)
 	assert.Len(t, result, 3)
 }
+
+func TestP2PRepo_SaveIsIdempotentAndJoinsOutbox(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	migrator := NewMigrator(db, db.logger)
+	require.NoError(t, migrator.Migrate(ctx))
+
+	repo := NewP2PRepo(db)
+
+	msg := P2PMessage{
+		ID: "dup-1", PeerID: "peer-abc",
+		Direction: "sent", Content: "hello", SentAt: "2026-02-21T10:00:00Z",
+	}
+	require.NoError(t, repo.SaveMessage(ctx, msg))
+	require.NoError(t, repo.SaveMessage(ctx, msg))
+
+	_, err := db.ExecContext(ctx,
+		`INSERT INTO outbox (id, target, recipient, content, state, next_attempt_at, created_at, updated_at)
+		 VALUES ('dup-1', 'p2p', 'peer-abc', 'hello', 'delivered', '', '', '')`)
+	require.NoError(t, err)
+
+	msgs, err := repo.GetMessages(ctx, "peer-abc", 10)
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	assert.Equal(t, "delivered", msgs[0].Status)
+}
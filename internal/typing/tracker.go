@@ -0,0 +1,255 @@
+// Package typing tracks who is typing in a channel or direct conversation.
+// Typing state expires on its own when clients stop refreshing it, and change
+// notifications are throttled so a user typing continuously produces one
+// "started" event per throttle window instead of one per keystroke.
+package typing
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultTTL is how long a typing indicator lives without a refresh.
+	DefaultTTL = 8 * time.Second
+	// DefaultThrottle is the minimum interval between repeated "started" notifications
+	// for the same user and scope. Clients should refresh at about this rate.
+	DefaultThrottle = 3 * time.Second
+)
+
+// ChannelScope returns the scope key for a server text channel.
+func ChannelScope(channelID string) string {
+	return "channel:" + channelID
+}
+
+// DMScope returns the scope key for the conversation between two users.
+// The key is the same regardless of argument order.
+func DMScope(userA, userB string) string {
+	if userA > userB {
+		userA, userB = userB, userA
+	}
+	return "dm:" + userA + ":" + userB
+}
+
+// PeerScope returns the scope key for the P2P conversation with a peer.
+func PeerScope(peerID string) string {
+	return "peer:" + peerID
+}
+
+// Event is emitted when a user starts (or keeps) typing, stops, or expires.
+type Event struct {
+	Scope     string `json:"scope"`
+	UserID    string `json:"user_id"`
+	Typing    bool   `json:"typing"`
+	ExpiresAt string `json:"expires_at,omitempty"` // RFC 3339, set while typing
+}
+
+// Typer is a user currently typing in a scope.
+type Typer struct {
+	UserID    string `json:"user_id"`
+	ExpiresAt string `json:"expires_at"` // RFC 3339
+}
+
+// Listener is notified of typing changes. It is called without locks held.
+type Listener func(Event)
+
+type entry struct {
+	startedAt time.Time
+	expiresAt time.Time
+	notified  time.Time
+}
+
+// Tracker keeps in-memory typing state keyed by scope and user.
+// A background reaper expires stale entries and emits stop events for them.
+type Tracker struct {
+	mu        sync.Mutex
+	scopes    map[string]map[string]*entry
+	listeners []Listener
+	ttl       time.Duration
+	throttle  time.Duration
+	now       func() time.Time
+	stop      chan struct{}
+}
+
+// NewTracker creates a tracker and starts its reaper. Non-positive durations
+// fall back to DefaultTTL and DefaultThrottle.
+func NewTracker(ttl, throttle time.Duration) *Tracker {
+	t := newTracker(ttl, throttle)
+	go t.reapLoop()
+	return t
+}
+
+func newTracker(ttl, throttle time.Duration) *Tracker {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	if throttle <= 0 {
+		throttle = DefaultThrottle
+	}
+	return &Tracker{
+		scopes:   make(map[string]map[string]*entry),
+		ttl:      ttl,
+		throttle: throttle,
+		now:      time.Now,
+		stop:     make(chan struct{}),
+	}
+}
+
+// OnChange registers a listener for typing events.
+func (t *Tracker) OnChange(l Listener) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.listeners = append(t.listeners, l)
+}
+
+// Start marks userID as typing in scope and extends the expiry. It returns true
+// when listeners were notified: the first call, or the first call after the
+// throttle window. Repeated calls inside the window only refresh the expiry.
+// Complexity: O(1)
+func (t *Tracker) Start(scope, userID string) bool {
+	now := t.now()
+
+	t.mu.Lock()
+	users := t.scopes[scope]
+	if users == nil {
+		users = make(map[string]*entry)
+		t.scopes[scope] = users
+	}
+	e := users[userID]
+	if e == nil {
+		e = &entry{startedAt: now}
+		users[userID] = e
+	}
+	e.expiresAt = now.Add(t.ttl)
+	notify := e.notified.IsZero() || now.Sub(e.notified) >= t.throttle
+	if notify {
+		e.notified = now
+	}
+	expiresAt := e.expiresAt
+	t.mu.Unlock()
+
+	if notify {
+		t.emit(Event{Scope: scope, UserID: userID, Typing: true, ExpiresAt: formatTime(expiresAt)})
+	}
+	return notify
+}
+
+// Stop clears userID's typing state in scope, e.g. when a message is sent.
+// It returns true if the user was typing.
+// Complexity: O(1)
+func (t *Tracker) Stop(scope, userID string) bool {
+	t.mu.Lock()
+	users := t.scopes[scope]
+	_, ok := users[userID]
+	if ok {
+		delete(users, userID)
+		if len(users) == 0 {
+			delete(t.scopes, scope)
+		}
+	}
+	t.mu.Unlock()
+
+	if ok {
+		t.emit(Event{Scope: scope, UserID: userID, Typing: false})
+	}
+	return ok
+}
+
+// Active returns the users typing in scope, oldest first, excluding exclude
+// (usually the caller). Expired entries are never returned.
+// Complexity: O(k log k) where k = typers in scope
+func (t *Tracker) Active(scope, exclude string) []Typer {
+	now := t.now()
+
+	t.mu.Lock()
+	type typer struct {
+		Typer
+		startedAt time.Time
+	}
+	found := make([]typer, 0, len(t.scopes[scope]))
+	for userID, e := range t.scopes[scope] {
+		if userID == exclude || !e.expiresAt.After(now) {
+			continue
+		}
+		found = append(found, typer{Typer{UserID: userID, ExpiresAt: formatTime(e.expiresAt)}, e.startedAt})
+	}
+	t.mu.Unlock()
+
+	sort.Slice(found, func(i, j int) bool {
+		if !found[i].startedAt.Equal(found[j].startedAt) {
+			return found[i].startedAt.Before(found[j].startedAt)
+		}
+		return found[i].UserID < found[j].UserID
+	})
+	typers := make([]Typer, len(found))
+	for i, f := range found {
+		typers[i] = f.Typer
+	}
+	return typers
+}
+
+// Close stops the reaper. Safe to call multiple times.
+func (t *Tracker) Close() {
+	select {
+	case <-t.stop:
+	default:
+		close(t.stop)
+	}
+}
+
+func (t *Tracker) reapLoop() {
+	interval := t.ttl / 4
+	if interval < 250*time.Millisecond {
+		interval = 250 * time.Millisecond
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			t.expire()
+		case <-t.stop:
+			return
+		}
+	}
+}
+
+// expire removes entries past their expiry and emits a stop event for each.
+// Complexity: O(n) over all typing entries
+func (t *Tracker) expire() {
+	now := t.now()
+
+	var expired []Event
+	t.mu.Lock()
+	for scope, users := range t.scopes {
+		for userID, e := range users {
+			if !e.expiresAt.After(now) {
+				delete(users, userID)
+				expired = append(expired, Event{Scope: scope, UserID: userID, Typing: false})
+			}
+		}
+		if len(users) == 0 {
+			delete(t.scopes, scope)
+		}
+	}
+	t.mu.Unlock()
+
+	for _, ev := range expired {
+		t.emit(ev)
+	}
+}
+
+func (t *Tracker) emit(ev Event) {
+	t.mu.Lock()
+	listeners := t.listeners
+	t.mu.Unlock()
+	for _, l := range listeners {
+		l(ev)
+	}
+}
+
+func formatTime(ts time.Time) string {
+	return ts.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,104 @@
+package typing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func setupTracker(t *testing.T) (*Tracker, *fakeClock, *[]Event) {
+	t.Helper()
+	clock := &fakeClock{t: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+	tr := newTracker(8*time.Second, 3*time.Second)
+	tr.now = clock.now
+	var events []Event
+	tr.OnChange(func(ev Event) { events = append(events, ev) })
+	return tr, clock, &events
+}
+
+func TestTracker_StartIsThrottled(t *testing.T) {
+	tr, clock, events := setupTracker(t)
+	scope := ChannelScope("ch-1")
+
+	assert.True(t, tr.Start(scope, "alice"))
+	clock.advance(time.Second)
+	assert.False(t, tr.Start(scope, "alice"), "refresh inside the throttle window is silent")
+	clock.advance(2 * time.Second)
+	assert.True(t, tr.Start(scope, "alice"))
+
+	require.Len(t, *events, 2)
+	assert.True(t, (*events)[0].Typing)
+	assert.Equal(t, "2026-03-01T12:00:08Z", (*events)[0].ExpiresAt)
+}
+
+func TestTracker_RefreshExtendsExpiry(t *testing.T) {
+	tr, clock, _ := setupTracker(t)
+	scope := ChannelScope("ch-1")
+
+	tr.Start(scope, "alice")
+	clock.advance(6 * time.Second)
+	tr.Start(scope, "alice")
+	clock.advance(6 * time.Second)
+
+	typers := tr.Active(scope, "")
+	require.Len(t, typers, 1)
+	assert.Equal(t, "alice", typers[0].UserID)
+}
+
+func TestTracker_ExpiryEmitsStop(t *testing.T) {
+	tr, clock, events := setupTracker(t)
+	scope := ChannelScope("ch-1")
+
+	tr.Start(scope, "alice")
+	clock.advance(8 * time.Second)
+	assert.Empty(t, tr.Active(scope, ""), "expired entries are hidden before the reaper runs")
+
+	tr.expire()
+	require.Len(t, *events, 2)
+	assert.Equal(t, Event{Scope: scope, UserID: "alice", Typing: false}, (*events)[1])
+
+	tr.expire()
+	assert.Len(t, *events, 2, "expired entries are reaped once")
+}
+
+func TestTracker_Stop(t *testing.T) {
+	tr, _, events := setupTracker(t)
+	scope := ChannelScope("ch-1")
+
+	assert.False(t, tr.Stop(scope, "alice"))
+	tr.Start(scope, "alice")
+	assert.True(t, tr.Stop(scope, "alice"))
+	assert.Empty(t, tr.Active(scope, ""))
+	assert.Len(t, *events, 2)
+
+	assert.True(t, tr.Start(scope, "alice"), "starting again after a stop notifies immediately")
+}
+
+func TestTracker_ActiveOrderAndExclude(t *testing.T) {
+	tr, clock, _ := setupTracker(t)
+	scope := ChannelScope("ch-1")
+
+	tr.Start(scope, "carol")
+	clock.advance(time.Second)
+	tr.Start(scope, "alice")
+	tr.Start(scope, "bob")
+	tr.Start(ChannelScope("ch-2"), "dave")
+
+	var ids []string
+	for _, ty := range tr.Active(scope, "bob") {
+		ids = append(ids, ty.UserID)
+	}
+	assert.Equal(t, []string{"carol", "alice"}, ids)
+}
+
+func TestDMScope_IsSymmetric(t *testing.T) {
+	assert.Equal(t, DMScope("alice", "bob"), DMScope("bob", "alice"))
+	assert.NotEqual(t, DMScope("alice", "bob"), ChannelScope("alice:bob"))
+}
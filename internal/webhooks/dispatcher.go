@@ -0,0 +1,292 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/concord-chat/concord/pkg/version"
+)
+
+// Headers sent with every delivery.
+const (
+	HeaderSignature = "X-Concord-Signature" // t=<unix seconds>,v1=<hex HMAC-SHA256>
+	HeaderEvent     = "X-Concord-Event"
+	HeaderDelivery  = "X-Concord-Delivery"
+)
+
+const maxErrorExcerpt = 512
+
+// Sign returns the hex HMAC-SHA256 of "<timestamp>.<body>" under secret. Receivers
+// recompute it from the t= value and the raw body and compare in constant time;
+// rejecting old timestamps protects them against replays.
+// Complexity: O(len(body))
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Run delivers due events every interval, and as soon as new ones are queued,
+// pruning old finished deliveries once an hour, until ctx is done.
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	lastPrune := time.Time{}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+		if _, err := s.DeliverDue(ctx); err != nil {
+			s.logger.Warn().Err(err).Msg("webhook delivery failed")
+		}
+		if now := s.now(); now.Sub(lastPrune) > time.Hour {
+			lastPrune = now
+			if _, err := s.repo.DeleteFinishedBefore(ctx, now.Add(-finishedTTL)); err != nil {
+				s.logger.Warn().Err(err).Msg("webhook delivery prune failed")
+			}
+		}
+	}
+}
+
+// DeliverDue attempts every delivery whose backoff has elapsed, in batches. Each
+// subscription's deliveries are attempted in order by one of deliveryWorkers
+// workers, so a slow endpoint only holds up its own subscription. Subscriptions
+// being delivered to by a concurrent call are skipped. Returns how many attempts
+// were made.
+// Complexity: O(k) HTTP requests where k = number of due deliveries
+func (s *Service) DeliverDue(ctx context.Context) (int, error) {
+	var (
+		mu       sync.Mutex
+		total    int
+		firstErr error
+	)
+	for {
+		due, err := s.repo.DueDeliveries(ctx, s.now(), batchSize)
+		if err != nil {
+			return total, err
+		}
+
+		var order []string
+		bySubscription := make(map[string][]*pendingDelivery)
+		for _, d := range due {
+			if _, ok := bySubscription[d.SubscriptionID]; !ok {
+				order = append(order, d.SubscriptionID)
+			}
+			bySubscription[d.SubscriptionID] = append(bySubscription[d.SubscriptionID], d)
+		}
+
+		var wg sync.WaitGroup
+		workers := make(chan struct{}, deliveryWorkers)
+		claimed := 0
+		for _, subID := range order {
+			if !s.claim(subID) {
+				continue
+			}
+			claimed++
+			workers <- struct{}{}
+			wg.Add(1)
+			go func(subID string, deliveries []*pendingDelivery) {
+				defer wg.Done()
+				defer func() { <-workers }()
+				defer s.release(subID)
+
+				n, err := s.deliverSubscription(ctx, deliveries)
+				mu.Lock()
+				defer mu.Unlock()
+				total += n
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+			}(subID, bySubscription[subID])
+		}
+		wg.Wait()
+
+		if firstErr != nil {
+			return total, firstErr
+		}
+		if claimed == 0 || len(due) < batchSize {
+			return total, nil
+		}
+	}
+}
+
+// deliverSubscription attempts the due deliveries of one subscription in order,
+// stopping when the subscription gets disabled. Returns how many attempts were made.
+func (s *Service) deliverSubscription(ctx context.Context, deliveries []*pendingDelivery) (int, error) {
+	n := 0
+	for _, d := range deliveries {
+		if ctx.Err() != nil {
+			return n, ctx.Err()
+		}
+		attempted, off, err := s.attempt(ctx, d)
+		if err != nil {
+			return n, err
+		}
+		if attempted {
+			n++
+		}
+		if off {
+			return n, nil
+		}
+	}
+	return n, nil
+}
+
+// claim marks a subscription in flight, reporting false when it already is.
+func (s *Service) claim(subscriptionID string) bool {
+	s.deliverMu.Lock()
+	defer s.deliverMu.Unlock()
+	if _, busy := s.inFlight[subscriptionID]; busy {
+		return false
+	}
+	s.inFlight[subscriptionID] = struct{}{}
+	return true
+}
+
+// release ends a claim taken by claim.
+func (s *Service) release(subscriptionID string) {
+	s.deliverMu.Lock()
+	defer s.deliverMu.Unlock()
+	delete(s.inFlight, subscriptionID)
+}
+
+// attempt claims one delivery, posts it and records the outcome. It reports whether
+// the delivery was posted and whether the subscription got disabled; deliveries
+// settled or claimed since they were loaded are skipped. Only storage errors are
+// returned.
+func (s *Service) attempt(ctx context.Context, d *pendingDelivery) (attempted, disabled bool, err error) {
+	now := s.now()
+	claimed, err := s.repo.ClaimDelivery(ctx, d.ID, d.Attempts, now, now.Add(deliveryLease))
+	if err != nil || !claimed {
+		return false, false, err
+	}
+	statusCode, sendErr := s.send(ctx, d)
+	disabled, err = s.record(ctx, d, statusCode, sendErr)
+	return true, disabled, err
+}
+
+// record stores the outcome of a delivery attempt, reporting whether the
+// subscription got disabled.
+func (s *Service) record(ctx context.Context, d *pendingDelivery, statusCode int, sendErr error) (bool, error) {
+	attempts := d.Attempts + 1
+	now := s.now()
+
+	if sendErr == nil {
+		if err := s.repo.RecordAttempt(ctx, d.ID, StatusDelivered, attempts, statusCode, "", now, &now); err != nil {
+			return false, err
+		}
+		return false, s.repo.ResetFailures(ctx, d.SubscriptionID)
+	}
+
+	status := StatusPending
+	if attempts >= MaxAttempts {
+		status = StatusFailed
+	}
+	lastError := sendErr.Error()
+	if err := s.repo.RecordAttempt(ctx, d.ID, status, attempts, statusCode, lastError, now.Add(s.backoff(attempts)), nil); err != nil {
+		return false, err
+	}
+	s.logger.Debug().
+		Str("delivery_id", d.ID).
+		Str("subscription_id", d.SubscriptionID).
+		Int("attempts", attempts).
+		Err(sendErr).
+		Msg("webhook delivery attempt failed")
+
+	failures, err := s.repo.RecordFailure(ctx, d.SubscriptionID)
+	if err != nil || failures < DisableAfter {
+		return false, err
+	}
+	reason := fmt.Sprintf("disabled after %d consecutive failed deliveries (last: %s)", failures, lastError)
+	if err := s.repo.Disable(ctx, d.SubscriptionID, reason); err != nil {
+		return false, err
+	}
+	s.logger.Warn().
+		Str("subscription_id", d.SubscriptionID).
+		Int("failures", failures).
+		Msg("webhook subscription disabled")
+	return true, nil
+}
+
+// send posts the payload and returns the response status. Any non-2xx answer is an error.
+func (s *Service) send(ctx context.Context, d *pendingDelivery) (int, error) {
+	if err := s.checkURL(d.URL); err != nil {
+		return 0, err
+	}
+	body := []byte(d.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	ts := s.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Concord-Webhooks/"+version.Version)
+	req.Header.Set(HeaderEvent, d.EventType)
+	req.Header.Set(HeaderDelivery, d.ID)
+	req.Header.Set(HeaderSignature, fmt.Sprintf("t=%d,v1=%s", ts, Sign(d.Secret, ts, body)))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	excerpt, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorExcerpt))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg := fmt.Sprintf("endpoint answered %d", resp.StatusCode)
+		if text := strings.TrimSpace(string(excerpt)); text != "" {
+			msg += ": " + text
+		}
+		return resp.StatusCode, errors.New(msg)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff returns the delay before the next attempt: baseDelay doubled per
+// failed attempt, capped at maxDelay.
+func (s *Service) backoff(attempts int) time.Duration {
+	delay := s.baseDelay
+	for i := 1; i < attempts && delay < s.maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > s.maxDelay {
+		delay = s.maxDelay
+	}
+	return delay
+}
+
+// validateURL checks a subscription URL and returns it trimmed.
+func (s *Service) validateURL(rawURL string) (string, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return "", fmt.Errorf("webhook URL is required")
+	}
+	if err := s.checkURL(rawURL); err != nil {
+		return "", err
+	}
+	return rawURL, nil
+}
+
+func (s *Service) checkURL(rawURL string) error {
+	if err := s.outbound.CheckURL(rawURL); err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+// Package webhooks delivers server events to external HTTP endpoints (outgoing
+// webhooks). Events are queued per subscription, signed with HMAC-SHA256 and
+// retried with exponential backoff; subscriptions that keep failing are disabled.
+package webhooks
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Event types a subscription can listen to.
+const (
+	EventMessageCreated       = "message.created"
+	EventMemberJoined         = "member.joined"
+	EventVoiceChannelOccupied = "voice.channel_occupied"
+)
+
+// EventTypes lists every supported event type.
+var EventTypes = []string{EventMessageCreated, EventMemberJoined, EventVoiceChannelOccupied}
+
+// Status is the state of a delivery.
+type Status string
+
+const (
+	StatusPending   Status = "pending"   // waiting for the first or next attempt
+	StatusDelivered Status = "delivered" // the endpoint answered 2xx
+	StatusFailed    Status = "failed"    // gave up after MaxAttempts or the subscription was disabled
+)
+
+// Subscription is an external endpoint receiving the events of one server.
+type Subscription struct {
+	ID                  string   `json:"id"`
+	ServerID            string   `json:"server_id"`
+	URL                 string   `json:"url"`
+	Events              []string `json:"events"`
+	Enabled             bool     `json:"enabled"`
+	ConsecutiveFailures int      `json:"consecutive_failures"`
+	DisabledReason      string   `json:"disabled_reason,omitempty"`
+	CreatedBy           string   `json:"created_by"`
+	CreatedAt           string   `json:"created_at"` // ISO 8601
+	// Secret signs the payloads. Only set when the subscription is created or the
+	// secret is rotated.
+	Secret string `json:"secret,omitempty"`
+}
+
+// Wants reports whether the subscription listens to eventType.
+func (s *Subscription) Wants(eventType string) bool {
+	for _, e := range s.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Delivery is one event queued for (or delivered to) one subscription.
+type Delivery struct {
+	ID             string  `json:"id"`
+	SubscriptionID string  `json:"subscription_id"`
+	EventID        string  `json:"event_id"`
+	EventType      string  `json:"event_type"`
+	Payload        string  `json:"payload"`
+	Status         Status  `json:"status"`
+	Attempts       int     `json:"attempts"`
+	NextAttemptAt  string  `json:"next_attempt_at"`        // ISO 8601
+	LastStatus     int     `json:"last_status,omitempty"`  // HTTP status of the last attempt
+	LastError      string  `json:"last_error,omitempty"`   // transport error or response excerpt
+	CreatedAt      string  `json:"created_at"`             // ISO 8601
+	DeliveredAt    *string `json:"delivered_at,omitempty"` // ISO 8601
+}
+
+// Event is the JSON body posted to subscribers.
+type Event struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	ServerID  string          `json:"server_id"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// MemberJoinedData is the data of a member.joined event.
+type MemberJoinedData struct {
+	UserID string `json:"user_id"`
+}
+
+// VoiceChannelOccupiedData is the data of a voice.channel_occupied event.
+type VoiceChannelOccupiedData struct {
+	ChannelID   string `json:"channel_id"`
+	ChannelName string `json:"channel_name"`
+	UserID      string `json:"user_id"` // first user to join
+}
+
+// pendingDelivery is a due delivery joined with what is needed to send it.
+type pendingDelivery struct {
+	Delivery
+	URL    string
+	Secret string
+}
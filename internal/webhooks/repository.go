@@ -0,0 +1,343 @@
+package webhooks
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// Repository handles outgoing webhook persistence.
+type Repository struct {
+	db     querier
+	logger zerolog.Logger
+}
+
+// NewRepository creates a new outgoing webhook repository.
+func NewRepository(db querier, logger zerolog.Logger) *Repository {
+	return &Repository{
+		db:     db,
+		logger: logger.With().Str("component", "webhooks_repo").Logger(),
+	}
+}
+
+// scanner is implemented by *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// --- Subscriptions ---
+
+const subscriptionColumns = `id, server_id, url, events, enabled, consecutive_failures, disabled_reason, created_by, created_at`
+
+func scanSubscription(row scanner) (*Subscription, error) {
+	var sub Subscription
+	var events string
+	var createdAt time.Time
+	if err := row.Scan(&sub.ID, &sub.ServerID, &sub.URL, &events, &sub.Enabled,
+		&sub.ConsecutiveFailures, &sub.DisabledReason, &sub.CreatedBy, &createdAt); err != nil {
+		return nil, err
+	}
+	sub.Events = strings.Split(events, ",")
+	sub.CreatedAt = formatTime(createdAt)
+	return &sub, nil
+}
+
+// CreateSubscription inserts a subscription. Secret must be set.
+// Complexity: O(1)
+func (r *Repository) CreateSubscription(ctx context.Context, sub *Subscription, createdAt time.Time) error {
+	query := `INSERT INTO webhook_subscriptions (` + subscriptionColumns + `, secret)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := r.db.ExecContext(ctx, query,
+		sub.ID, sub.ServerID, sub.URL, strings.Join(sub.Events, ","), sub.Enabled,
+		sub.ConsecutiveFailures, sub.DisabledReason, sub.CreatedBy, createdAt.UTC(), sub.Secret,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+	return nil
+}
+
+// GetSubscription returns a subscription without its secret, or nil if not found.
+// Complexity: O(1)
+func (r *Repository) GetSubscription(ctx context.Context, id string) (*Subscription, error) {
+	row := r.db.QueryRowContext(ctx,
+		`SELECT `+subscriptionColumns+` FROM webhook_subscriptions WHERE id = ?`, id)
+	sub, err := scanSubscription(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook subscription: %w", err)
+	}
+	return sub, nil
+}
+
+// ListSubscriptions returns the subscriptions of a server without their secrets, oldest first.
+// Complexity: O(n) where n = number of subscriptions of the server
+func (r *Repository) ListSubscriptions(ctx context.Context, serverID string) ([]*Subscription, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+subscriptionColumns+` FROM webhook_subscriptions WHERE server_id = ? ORDER BY created_at, id`,
+		serverID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subs []*Subscription
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook subscription: %w", err)
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+// UpdateSubscription saves the URL, events and enabled state of a subscription,
+// along with its failure count and disabled reason.
+// Complexity: O(1)
+func (r *Repository) UpdateSubscription(ctx context.Context, sub *Subscription) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE webhook_subscriptions
+		SET url = ?, events = ?, enabled = ?, consecutive_failures = ?, disabled_reason = ?
+		WHERE id = ?`,
+		sub.URL, strings.Join(sub.Events, ","), sub.Enabled, sub.ConsecutiveFailures, sub.DisabledReason, sub.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook subscription: %w", err)
+	}
+	return nil
+}
+
+// UpdateSecret replaces the signing secret of a subscription.
+// Complexity: O(1)
+func (r *Repository) UpdateSecret(ctx context.Context, id, secret string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE webhook_subscriptions SET secret = ? WHERE id = ?`, secret, id)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook secret: %w", err)
+	}
+	return nil
+}
+
+// DeleteSubscription removes a subscription and its deliveries.
+// Complexity: O(d) where d = number of deliveries of the subscription
+func (r *Repository) DeleteSubscription(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+	return nil
+}
+
+// RecordFailure increments the consecutive failure count of a subscription and returns it.
+// Complexity: O(1)
+func (r *Repository) RecordFailure(ctx context.Context, id string) (int, error) {
+	if _, err := r.db.ExecContext(ctx,
+		`UPDATE webhook_subscriptions SET consecutive_failures = consecutive_failures + 1 WHERE id = ?`, id,
+	); err != nil {
+		return 0, fmt.Errorf("failed to record webhook failure: %w", err)
+	}
+	var failures int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT consecutive_failures FROM webhook_subscriptions WHERE id = ?`, id,
+	).Scan(&failures)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read webhook failures: %w", err)
+	}
+	return failures, nil
+}
+
+// ResetFailures clears the consecutive failure count after a successful delivery.
+// Complexity: O(1)
+func (r *Repository) ResetFailures(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE webhook_subscriptions SET consecutive_failures = 0 WHERE id = ? AND consecutive_failures <> 0`, id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to reset webhook failures: %w", err)
+	}
+	return nil
+}
+
+// Disable turns a subscription off and fails its pending deliveries.
+// Complexity: O(p) where p = number of pending deliveries of the subscription
+func (r *Repository) Disable(ctx context.Context, id, reason string) error {
+	if _, err := r.db.ExecContext(ctx,
+		`UPDATE webhook_subscriptions SET enabled = ?, disabled_reason = ? WHERE id = ?`, false, reason, id,
+	); err != nil {
+		return fmt.Errorf("failed to disable webhook subscription: %w", err)
+	}
+	if _, err := r.db.ExecContext(ctx,
+		`UPDATE webhook_deliveries SET status = ?, last_error = ? WHERE subscription_id = ? AND status = ?`,
+		StatusFailed, reason, id, StatusPending,
+	); err != nil {
+		return fmt.Errorf("failed to fail pending webhook deliveries: %w", err)
+	}
+	return nil
+}
+
+// --- Deliveries ---
+
+const deliveryColumns = `d.id, d.subscription_id, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+	d.next_attempt_at, d.last_status, d.last_error, d.created_at, d.delivered_at`
+
+func scanDelivery(row scanner, extra ...interface{}) (*Delivery, error) {
+	var d Delivery
+	var nextAttemptAt, createdAt time.Time
+	var deliveredAt sql.NullTime
+	dest := []interface{}{&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Payload, &d.Status,
+		&d.Attempts, &nextAttemptAt, &d.LastStatus, &d.LastError, &createdAt, &deliveredAt}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	d.NextAttemptAt = formatTime(nextAttemptAt)
+	d.CreatedAt = formatTime(createdAt)
+	if deliveredAt.Valid {
+		s := formatTime(deliveredAt.Time)
+		d.DeliveredAt = &s
+	}
+	return &d, nil
+}
+
+// InsertDelivery queues a delivery, due immediately.
+// Complexity: O(1)
+func (r *Repository) InsertDelivery(ctx context.Context, d *Delivery, createdAt time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO webhook_deliveries
+		(id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, 0, ?, ?)`,
+		d.ID, d.SubscriptionID, d.EventID, d.EventType, d.Payload, StatusPending, createdAt.UTC(), createdAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to queue webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// DueDeliveries returns up to limit pending deliveries of enabled subscriptions whose
+// next attempt is at or before now, oldest first.
+// Complexity: O(limit) using idx_webhook_deliveries_due
+func (r *Repository) DueDeliveries(ctx context.Context, now time.Time, limit int) ([]*pendingDelivery, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+deliveryColumns+`, s.url, s.secret
+		FROM webhook_deliveries d
+		INNER JOIN webhook_subscriptions s ON s.id = d.subscription_id
+		WHERE d.status = ? AND d.next_attempt_at <= ? AND s.enabled = ?
+		ORDER BY d.next_attempt_at, d.id
+		LIMIT ?`,
+		StatusPending, now.UTC(), true, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var due []*pendingDelivery
+	for rows.Next() {
+		var p pendingDelivery
+		d, err := scanDelivery(rows, &p.URL, &p.Secret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		p.Delivery = *d
+		due = append(due, &p)
+	}
+	return due, rows.Err()
+}
+
+// ClaimDelivery pushes the next attempt of a due pending delivery to until while it
+// is in flight, so other passes skip it. It reports false when the delivery is no
+// longer due or was attempted since it was loaded.
+// Complexity: O(1)
+func (r *Repository) ClaimDelivery(ctx context.Context, id string, attempts int, now, until time.Time) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE webhook_deliveries SET next_attempt_at = ?
+		WHERE id = ? AND status = ? AND attempts = ? AND next_attempt_at <= ?`,
+		until.UTC(), id, StatusPending, attempts, now.UTC(),
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim webhook delivery: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim webhook delivery: %w", err)
+	}
+	return n == 1, nil
+}
+
+// RecordAttempt stores the outcome of a delivery attempt. deliveredAt is set on success.
+// Complexity: O(1)
+func (r *Repository) RecordAttempt(ctx context.Context, id string, status Status, attempts, lastStatus int,
+	lastError string, nextAttemptAt time.Time, deliveredAt *time.Time) error {
+	var delivered interface{}
+	if deliveredAt != nil {
+		delivered = deliveredAt.UTC()
+	}
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE webhook_deliveries
+		SET status = ?, attempts = ?, last_status = ?, last_error = ?, next_attempt_at = ?, delivered_at = ?
+		WHERE id = ?`,
+		status, attempts, lastStatus, lastError, nextAttemptAt.UTC(), delivered, id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook delivery attempt: %w", err)
+	}
+	return nil
+}
+
+// ListDeliveries returns the most recent deliveries of a subscription, newest first.
+// Complexity: O(limit) using idx_webhook_deliveries_subscription
+func (r *Repository) ListDeliveries(ctx context.Context, subscriptionID string, limit int) ([]*Delivery, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+deliveryColumns+` FROM webhook_deliveries d
+		WHERE d.subscription_id = ?
+		ORDER BY d.created_at DESC, d.id DESC
+		LIMIT ?`,
+		subscriptionID, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*Delivery
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+// DeleteFinishedBefore prunes delivered and failed deliveries created before cutoff.
+// Complexity: O(k) where k = number of pruned deliveries
+func (r *Repository) DeleteFinishedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx,
+		`DELETE FROM webhook_deliveries WHERE status <> ? AND created_at < ?`, StatusPending, cutoff.UTC(),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune webhook deliveries: %w", err)
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,369 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+
+	"github.com/concord-chat/concord/internal/chat"
+	"github.com/concord-chat/concord/internal/security"
+	"github.com/concord-chat/concord/internal/server"
+)
+
+const (
+	// MaxAttempts is how many times a delivery is tried before it is marked failed.
+	MaxAttempts = 8
+	// DisableAfter is how many consecutive failed attempts disable a subscription.
+	DisableAfter = 20
+
+	maxSubscriptionsPerServer = 10
+	maxDeliveryLog            = 100
+	defaultBaseDelay          = 30 * time.Second
+	defaultMaxDelay           = 1 * time.Hour
+	deliveryTimeout           = 10 * time.Second
+	deliveryLease             = 3 * deliveryTimeout // a claimed delivery is retried after this if its pass dies
+	finishedTTL               = 7 * 24 * time.Hour
+	batchSize                 = 50
+	deliveryWorkers           = 8 // subscriptions delivered to in parallel
+)
+
+// Servers is the part of server.Service used to authorize and resolve events.
+type Servers interface {
+	CheckPermission(ctx context.Context, serverID, userID string, perm server.Permission) error
+	GetChannel(ctx context.Context, channelID string) (*server.Channel, error)
+	IsMember(ctx context.Context, serverID, userID string) (bool, error)
+}
+
+// Service manages webhook subscriptions and delivers events to them.
+// It implements chat.EventSink and server.EventSink.
+type Service struct {
+	repo     *Repository
+	servers  Servers
+	client   *http.Client
+	outbound *security.Outbound
+
+	wake      chan struct{}       // nudges Run after an event is queued
+	deliverMu sync.Mutex          // guards inFlight
+	inFlight  map[string]struct{} // subscriptions with a worker, which keeps their deliveries in order
+
+	baseDelay time.Duration
+	maxDelay  time.Duration
+	now       func() time.Time
+	logger    zerolog.Logger
+}
+
+// NewService creates a new outgoing webhook service. Call Run to start delivering.
+func NewService(repo *Repository, servers Servers, logger zerolog.Logger) *Service {
+	outbound := security.NewOutbound()
+	return &Service{
+		repo:      repo,
+		servers:   servers,
+		client:    outbound.Client(deliveryTimeout, 0), // the configured URL is the only destination
+		outbound:  outbound,
+		wake:      make(chan struct{}, 1),
+		inFlight:  make(map[string]struct{}),
+		baseDelay: defaultBaseDelay,
+		maxDelay:  defaultMaxDelay,
+		now:       time.Now,
+		logger:    logger.With().Str("component", "webhooks_service").Logger(),
+	}
+}
+
+// --- Subscriptions ---
+
+// CreateSubscription subscribes rawURL to events of a server. The returned
+// subscription carries its signing secret, which is not retrievable afterwards.
+// Requires PermManageServer.
+func (s *Service) CreateSubscription(ctx context.Context, serverID, userID, rawURL string, events []string) (*Subscription, error) {
+	if err := s.servers.CheckPermission(ctx, serverID, userID, server.PermManageServer); err != nil {
+		return nil, err
+	}
+	rawURL, err := s.validateURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if events, err = normalizeEvents(events); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.ListSubscriptions(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= maxSubscriptionsPerServer {
+		return nil, fmt.Errorf("a server can have at most %d webhook subscriptions", maxSubscriptionsPerServer)
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	sub := &Subscription{
+		ID:        uuid.New().String(),
+		ServerID:  serverID,
+		URL:       rawURL,
+		Events:    events,
+		Enabled:   true,
+		CreatedBy: userID,
+		Secret:    secret,
+	}
+	if err := s.repo.CreateSubscription(ctx, sub, s.now()); err != nil {
+		return nil, err
+	}
+
+	created, err := s.repo.GetSubscription(ctx, sub.ID)
+	if err != nil {
+		return nil, err
+	}
+	created.Secret = secret
+
+	s.logger.Info().
+		Str("subscription_id", sub.ID).
+		Str("server_id", serverID).
+		Strs("events", events).
+		Msg("webhook subscription created")
+	return created, nil
+}
+
+// ListSubscriptions returns the subscriptions of a server. Requires PermManageServer.
+func (s *Service) ListSubscriptions(ctx context.Context, serverID, userID string) ([]*Subscription, error) {
+	if err := s.servers.CheckPermission(ctx, serverID, userID, server.PermManageServer); err != nil {
+		return nil, err
+	}
+	return s.repo.ListSubscriptions(ctx, serverID)
+}
+
+// UpdateSubscription changes the URL, events or enabled state of a subscription.
+// Empty rawURL or events and a nil enabled keep the current values. Re-enabling a
+// disabled subscription clears its failure count. Requires PermManageServer.
+func (s *Service) UpdateSubscription(ctx context.Context, serverID, userID, subscriptionID, rawURL string, events []string, enabled *bool) (*Subscription, error) {
+	sub, err := s.managedSubscription(ctx, serverID, userID, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if rawURL != "" {
+		if sub.URL, err = s.validateURL(rawURL); err != nil {
+			return nil, err
+		}
+	}
+	if len(events) > 0 {
+		if sub.Events, err = normalizeEvents(events); err != nil {
+			return nil, err
+		}
+	}
+	if enabled != nil {
+		if *enabled && !sub.Enabled {
+			sub.ConsecutiveFailures = 0
+			sub.DisabledReason = ""
+		}
+		sub.Enabled = *enabled
+	}
+
+	if err := s.repo.UpdateSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// RotateSecret issues a new signing secret; payloads are signed with it from now on.
+// Requires PermManageServer.
+func (s *Service) RotateSecret(ctx context.Context, serverID, userID, subscriptionID string) (*Subscription, error) {
+	sub, err := s.managedSubscription(ctx, serverID, userID, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := newSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	if err := s.repo.UpdateSecret(ctx, sub.ID, secret); err != nil {
+		return nil, err
+	}
+	sub.Secret = secret
+	return sub, nil
+}
+
+// DeleteSubscription removes a subscription and its delivery log. Requires PermManageServer.
+func (s *Service) DeleteSubscription(ctx context.Context, serverID, userID, subscriptionID string) error {
+	if _, err := s.managedSubscription(ctx, serverID, userID, subscriptionID); err != nil {
+		return err
+	}
+	return s.repo.DeleteSubscription(ctx, subscriptionID)
+}
+
+// ListDeliveries returns the most recent deliveries of a subscription, newest first.
+// Requires PermManageServer.
+func (s *Service) ListDeliveries(ctx context.Context, serverID, userID, subscriptionID string) ([]*Delivery, error) {
+	if _, err := s.managedSubscription(ctx, serverID, userID, subscriptionID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListDeliveries(ctx, subscriptionID, maxDeliveryLog)
+}
+
+// managedSubscription loads a subscription of serverID after checking PermManageServer.
+func (s *Service) managedSubscription(ctx context.Context, serverID, userID, subscriptionID string) (*Subscription, error) {
+	if err := s.servers.CheckPermission(ctx, serverID, userID, server.PermManageServer); err != nil {
+		return nil, err
+	}
+	sub, err := s.repo.GetSubscription(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil || sub.ServerID != serverID {
+		return nil, fmt.Errorf("webhook subscription not found")
+	}
+	return sub, nil
+}
+
+// --- Events ---
+
+// MessageCreated queues a message.created event. Implements chat.EventSink.
+func (s *Service) MessageCreated(ctx context.Context, msg *chat.Message) {
+	ch, err := s.servers.GetChannel(ctx, msg.ChannelID)
+	if err != nil || ch == nil {
+		s.logger.Warn().Err(err).Str("channel_id", msg.ChannelID).Msg("cannot resolve server of message event")
+		return
+	}
+	data := *msg
+	data.Markup = nil
+	data.Embeds = nil
+	s.Publish(ctx, ch.ServerID, EventMessageCreated, &data)
+}
+
+// MemberJoined queues a member.joined event. Implements server.EventSink.
+func (s *Service) MemberJoined(ctx context.Context, serverID, userID string) {
+	s.Publish(ctx, serverID, EventMemberJoined, MemberJoinedData{UserID: userID})
+}
+
+// ChannelOccupied queues a voice.channel_occupied event when a member joins an
+// empty voice channel. The IDs come from the signaling client, so events for
+// unknown channels or non-members are dropped.
+func (s *Service) ChannelOccupied(ctx context.Context, serverID, channelID, userID string) {
+	ch, err := s.servers.GetChannel(ctx, channelID)
+	if err != nil || ch == nil || ch.ServerID != serverID || ch.Type != "voice" {
+		return
+	}
+	if ok, err := s.servers.IsMember(ctx, serverID, userID); err != nil || !ok {
+		return
+	}
+	s.Publish(ctx, serverID, EventVoiceChannelOccupied, VoiceChannelOccupiedData{
+		ChannelID:   channelID,
+		ChannelName: ch.Name,
+		UserID:      userID,
+	})
+}
+
+// Publish queues an event for every enabled subscription of serverID listening to
+// eventType. Failures are logged, never returned: events must not break the
+// mutation that produced them.
+// Complexity: O(n) where n = number of subscriptions of the server
+func (s *Service) Publish(ctx context.Context, serverID, eventType string, data interface{}) {
+	subs, err := s.repo.ListSubscriptions(ctx, serverID)
+	if err != nil {
+		s.logger.Error().Err(err).Str("server_id", serverID).Msg("failed to load webhook subscriptions")
+		return
+	}
+
+	var eventID string
+	var payload []byte
+	queued := 0
+	for _, sub := range subs {
+		if !sub.Enabled || !sub.Wants(eventType) {
+			continue
+		}
+		if payload == nil {
+			if eventID, payload, err = s.encodeEvent(serverID, eventType, data); err != nil {
+				s.logger.Error().Err(err).Str("event", eventType).Msg("failed to encode webhook event")
+				return
+			}
+		}
+		d := &Delivery{
+			ID:             uuid.New().String(),
+			SubscriptionID: sub.ID,
+			EventID:        eventID,
+			EventType:      eventType,
+			Payload:        string(payload),
+		}
+		if err := s.repo.InsertDelivery(ctx, d, s.now()); err != nil {
+			s.logger.Error().Err(err).Str("subscription_id", sub.ID).Msg("failed to queue webhook delivery")
+			continue
+		}
+		queued++
+	}
+
+	if queued > 0 {
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// encodeEvent wraps data in an Event and returns its ID and JSON body. Every
+// subscription receives the same body, so receivers can deduplicate on the ID.
+func (s *Service) encodeEvent(serverID, eventType string, data interface{}) (string, []byte, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return "", nil, err
+	}
+	e := &Event{
+		ID:        uuid.New().String(),
+		Type:      eventType,
+		ServerID:  serverID,
+		CreatedAt: s.now().UTC(),
+		Data:      raw,
+	}
+	body, err := json.Marshal(e)
+	if err != nil {
+		return "", nil, err
+	}
+	return e.ID, body, nil
+}
+
+// --- Helpers ---
+
+func normalizeEvents(events []string) ([]string, error) {
+	seen := make(map[string]bool, len(events))
+	var out []string
+	for _, e := range events {
+		e = strings.TrimSpace(e)
+		if e == "" || seen[e] {
+			continue
+		}
+		known := false
+		for _, t := range EventTypes {
+			if e == t {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown event type %q", e)
+		}
+		seen[e] = true
+		out = append(out, e)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("at least one event type is required")
+	}
+	return out, nil
+}
+
+// newSecret returns a random signing secret.
+// Complexity: O(1)
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + base64.RawURLEncoding.EncodeToString(b), nil
+}
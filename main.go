@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"os"
@@ -17,18 +18,25 @@ import (
 	"time"
 
 	"github.com/concord-chat/concord/internal/auth"
+	"github.com/concord-chat/concord/internal/bookmarks"
 	"github.com/concord-chat/concord/internal/cache"
 	"github.com/concord-chat/concord/internal/chat"
 	"github.com/concord-chat/concord/internal/config"
+	"github.com/concord-chat/concord/internal/emoji"
+	"github.com/concord-chat/concord/internal/export"
 	"github.com/concord-chat/concord/internal/files"
 	"github.com/concord-chat/concord/internal/friends"
+	"github.com/concord-chat/concord/internal/linkpreview"
 	"github.com/concord-chat/concord/internal/network/p2p"
 	"github.com/concord-chat/concord/internal/network/signaling"
 	"github.com/concord-chat/concord/internal/observability"
+	"github.com/concord-chat/concord/internal/outbox"
+	"github.com/concord-chat/concord/internal/preferences"
 	"github.com/concord-chat/concord/internal/security"
 	"github.com/concord-chat/concord/internal/server"
 	"github.com/concord-chat/concord/internal/store/sqlite"
 	"github.com/concord-chat/concord/internal/translation"
+	"github.com/concord-chat/concord/internal/typing"
 	"github.com/concord-chat/concord/internal/updater"
 	"github.com/concord-chat/concord/internal/voice"
 	"github.com/concord-chat/concord/pkg/version"
@@ -42,6 +50,9 @@ import (
 //go:embed all:frontend/dist
 var assets embed.FS
 
+// outboxRetryInterval is how often queued messages whose backoff elapsed are retried.
+const outboxRetryInterval = 5 * time.Second
+
 // App struct holds the application state
 type App struct {
 	ctx                context.Context
@@ -54,6 +65,11 @@ type App struct {
 	serverService      *server.Service
 	chatService        *chat.Service
 	friendService      *friends.Service
+	prefsService       *preferences.Service
+	outboxService      *outbox.Service
+	outboxCancel       context.CancelFunc
+	typingTracker      *typing.Tracker // typing state received from peers
+	typingSent         *typing.Tracker // throttles our own typing notifications
 	voiceEngine        *voice.Engine
 	voiceOrch          *voice.Orchestrator
 	voiceTranslator    *voice.VoiceTranslator
@@ -61,7 +77,10 @@ type App struct {
 	sigServer          *signaling.Server
 	sigListener        net.Listener
 	fileService        *files.Service
+	emojiService       *emoji.Service
+	bookmarkService    *bookmarks.Service
 	translationService *translation.Service
+	exportService      *export.Service
 	p2pHost            *p2p.Host
 	p2pRepo            *sqlite.P2PRepo
 	p2pPeerNames       sync.Map // peerID(string) → p2p.ProfilePayload
@@ -165,7 +184,7 @@ func (a *App) startup(ctx context.Context) {
 	a.logger.Info().Int("max_entries", cfg.Cache.LRU.MaxEntries).Msg("LRU cache initialized")
 
 	// Initialize server service
-	serverRepo := server.NewRepository(a.db, a.logger)
+	serverRepo := server.NewRepository(a.db, server.NewStdlibTransactor(a.db.Conn()), a.logger)
 	a.serverService = server.NewService(serverRepo, srvCache, a.logger)
 	a.logger.Info().Msg("server service initialized")
 
@@ -177,8 +196,45 @@ func (a *App) startup(ctx context.Context) {
 	// Initialize chat service
 	chatRepo := chat.NewRepository(a.db, a.logger)
 	a.chatService = chat.NewService(chatRepo, a.logger)
+	messageLimit := 0
+	if cfg.Security.RateLimitEnabled {
+		messageLimit = cfg.Security.RateLimitMessages
+	}
+	a.chatService.SetSendLimiter(chat.NewSendLimiter(messageLimit, a.serverService))
+	a.chatService.SetChannelAccess(a.serverService)
+	a.chatService.SetSendPolicy(a.serverService)
+	a.chatService.SetModeration(a.serverService)
+	a.chatService.SetSearcher(sqlite.NewChatSearcher(a.db, a.logger))
+	a.exportService = export.NewService(chatRepo, friendRepo, a.logger)
+	a.prefsService = preferences.NewService(preferences.NewRepository(a.db, a.logger), srvCache, a.logger)
+	a.chatService.SetUnfurler(linkpreview.NewFetcher(cfg.Cache.LRU.MaxEntries, a.logger), a.prefsService)
+	a.friendService.SetReceiptPolicy(a.prefsService)
+	a.chatService.OnEmbeds(func(messageID, channelID string, embeds []*linkpreview.Preview) {
+		runtime.EventsEmit(a.ctx, "chat:embeds", map[string]interface{}{
+			"message_id": messageID,
+			"channel_id": channelID,
+			"embeds":     embeds,
+		})
+	})
 	a.logger.Info().Msg("chat service initialized")
 
+	// Initialize outbox (offline delivery for P2P peers and the central server)
+	a.outboxService = outbox.NewService(outbox.NewRepository(a.db, a.logger), a.logger)
+	a.outboxService.OnChange(func(e *outbox.Entry) {
+		runtime.EventsEmit(a.ctx, "outbox:update", e)
+	})
+	outboxCtx, outboxCancel := context.WithCancel(context.Background())
+	a.outboxCancel = outboxCancel
+	go a.outboxService.Run(outboxCtx, outboxRetryInterval)
+	a.logger.Info().Msg("outbox initialized")
+
+	// Initialize typing indicators (expiry + throttling for P2P conversations)
+	a.typingTracker = typing.NewTracker(typing.DefaultTTL, typing.DefaultThrottle)
+	a.typingTracker.OnChange(func(ev typing.Event) {
+		runtime.EventsEmit(a.ctx, "typing:update", ev)
+	})
+	a.typingSent = typing.NewTracker(typing.DefaultTTL, typing.DefaultThrottle)
+
 	// Initialize file service
 	storageDir := filepath.Join(filepath.Dir(cfg.Database.SQLite.Path), "files")
 	fileStorage, err := files.NewLocalStorage(storageDir, a.logger)
@@ -187,8 +243,23 @@ func (a *App) startup(ctx context.Context) {
 	}
 	fileRepo := files.NewRepository(a.db, a.logger)
 	a.fileService = files.NewService(fileRepo, fileStorage, a.logger)
+	if cfg.Security.RateLimitEnabled {
+		a.fileService.SetUploadLimiter(cfg.Security.RateLimitFiles)
+	}
 	a.logger.Info().Str("storage_dir", storageDir).Msg("file service initialized")
 
+	// Initialize custom emoji (images kept next to the attachments)
+	emojiStorage, err := files.NewLocalStorage(filepath.Join(filepath.Dir(cfg.Database.SQLite.Path), "emoji"), a.logger)
+	if err != nil {
+		a.logger.Fatal().Err(err).Msg("failed to initialize emoji storage")
+	}
+	a.emojiService = emoji.NewService(emoji.NewRepository(a.db, a.logger), emojiStorage, a.serverService, a.logger)
+	a.chatService.SetEmojiResolver(a.emojiService)
+
+	// Initialize personal bookmarks
+	a.bookmarkService = bookmarks.NewService(bookmarks.NewRepository(a.db, a.logger),
+		a.chatService, a.serverService, a.friendService, a.logger)
+
 	// Local signaling server + voice engine are only needed in P2P mode.
 	// In server mode, voice is handled entirely by the browser via WebRTC
 	// connecting directly to the central signaling server. This avoids
@@ -259,6 +330,17 @@ func (a *App) shutdown(ctx context.Context) {
 		}
 	}
 
+	// Stop outbox retries
+	if a.outboxCancel != nil {
+		a.outboxCancel()
+	}
+
+	// Stop typing indicator reapers
+	if a.typingTracker != nil {
+		a.typingTracker.Close()
+		a.typingSent.Close()
+	}
+
 	// Stop local signaling server
 	if a.sigListener != nil {
 		_ = a.sigListener.Close()
@@ -343,6 +425,18 @@ func (a *App) CreateServer(name, ownerID string) (*server.Server, error) {
 	return a.serverService.CreateServer(a.ctx, name, ownerID)
 }
 
+// CreateServerFromTemplate creates a server with the structure of a template. An
+// empty name keeps the template's.
+func (a *App) CreateServerFromTemplate(code, name, ownerID string) (*server.Server, error) {
+	return a.serverService.CreateServerFromTemplate(a.ctx, code, name, ownerID)
+}
+
+// CreateServerFromSnapshot creates a server from a template snapshot document. An
+// empty name keeps the snapshot's.
+func (a *App) CreateServerFromSnapshot(snapshot server.TemplateSnapshot, name, ownerID string) (*server.Server, error) {
+	return a.serverService.CreateServerFromSnapshot(a.ctx, &snapshot, name, ownerID)
+}
+
 // GetServer retrieves a server by ID.
 func (a *App) GetServer(serverID string) (*server.Server, error) {
 	return a.serverService.GetServer(a.ctx, serverID)
@@ -373,11 +467,61 @@ func (a *App) ListChannels(serverID string) ([]*server.Channel, error) {
 	return a.serverService.ListChannels(a.ctx, serverID)
 }
 
+// UpdateChannel renames a channel, changes its type or moves it to another category.
+func (a *App) UpdateChannel(serverID, userID, channelID string, update server.ChannelUpdate) (*server.Channel, error) {
+	return a.serverService.UpdateChannel(a.ctx, serverID, userID, channelID, update)
+}
+
+// ReorderChannels replaces the order of all channels and categories of a server.
+func (a *App) ReorderChannels(serverID, userID string, layout server.ChannelLayout) error {
+	return a.serverService.ReorderChannels(a.ctx, serverID, userID, layout)
+}
+
+// ListCategories returns the channel categories of a server.
+func (a *App) ListCategories(serverID string) ([]*server.Category, error) {
+	return a.serverService.ListCategories(a.ctx, serverID)
+}
+
+// CreateCategory creates a channel category within a server.
+func (a *App) CreateCategory(serverID, userID, name string) (*server.Category, error) {
+	return a.serverService.CreateCategory(a.ctx, serverID, userID, name)
+}
+
+// RenameCategory renames a channel category.
+func (a *App) RenameCategory(serverID, userID, categoryID, name string) (*server.Category, error) {
+	return a.serverService.RenameCategory(a.ctx, serverID, userID, categoryID, name)
+}
+
+// DeleteCategory removes a channel category; its channels become uncategorized.
+func (a *App) DeleteCategory(serverID, userID, categoryID string) error {
+	return a.serverService.DeleteCategory(a.ctx, serverID, userID, categoryID)
+}
+
 // DeleteChannel removes a channel from a server.
 func (a *App) DeleteChannel(serverID, userID, channelID string) error {
 	return a.serverService.DeleteChannel(a.ctx, serverID, userID, channelID)
 }
 
+// SetChannelSlowMode sets the per-user message interval for a channel (0 disables it).
+func (a *App) SetChannelSlowMode(serverID, userID, channelID string, seconds int) error {
+	return a.serverService.SetSlowMode(a.ctx, serverID, userID, channelID, seconds)
+}
+
+// ListChannelOverwrites returns the permission overwrites of a channel.
+func (a *App) ListChannelOverwrites(serverID, userID, channelID string) ([]*server.Overwrite, error) {
+	return a.serverService.ChannelOverwrites(a.ctx, serverID, userID, channelID)
+}
+
+// SetChannelOverwrite allows and denies permissions in a channel for a role or a member.
+func (a *App) SetChannelOverwrite(serverID, userID string, overwrite server.Overwrite) (*server.Overwrite, error) {
+	return a.serverService.SetChannelOverwrite(a.ctx, serverID, userID, overwrite)
+}
+
+// DeleteChannelOverwrite removes the overwrite of a channel for a role or a member.
+func (a *App) DeleteChannelOverwrite(serverID, userID, channelID, targetType, targetID string) error {
+	return a.serverService.DeleteChannelOverwrite(a.ctx, serverID, userID, channelID, targetType, targetID)
+}
+
 // ListMembers returns all members of a server.
 func (a *App) ListMembers(serverID string) ([]*server.Member, error) {
 	return a.serverService.ListMembers(a.ctx, serverID)
@@ -388,9 +532,102 @@ func (a *App) KickMember(serverID, actorID, targetID string) error {
 	return a.serverService.KickMember(a.ctx, serverID, actorID, targetID)
 }
 
+// BanMember bans a user from a server for the given number of seconds, or
+// permanently when seconds is 0.
+func (a *App) BanMember(serverID, actorID, targetID, reason string, seconds int) (*server.Ban, error) {
+	return a.serverService.BanMember(a.ctx, serverID, actorID, targetID, reason, seconds)
+}
+
+// UnbanMember lifts the ban of a user from a server.
+func (a *App) UnbanMember(serverID, actorID, targetID string) error {
+	return a.serverService.UnbanMember(a.ctx, serverID, actorID, targetID)
+}
+
+// ListBans returns the bans of a server still in effect.
+func (a *App) ListBans(serverID, userID string) ([]*server.Ban, error) {
+	return a.serverService.ListBans(a.ctx, serverID, userID)
+}
+
+// TimeoutMember keeps a member from sending messages and speaking for the given
+// number of seconds.
+func (a *App) TimeoutMember(serverID, actorID, targetID string, seconds int) (*server.Member, error) {
+	return a.serverService.TimeoutMember(a.ctx, serverID, actorID, targetID, seconds)
+}
+
+// RemoveTimeout ends the timeout of a member early.
+func (a *App) RemoveTimeout(serverID, actorID, targetID string) error {
+	return a.serverService.RemoveTimeout(a.ctx, serverID, actorID, targetID)
+}
+
+// ListTimeouts returns the members of a server that are currently timed out.
+func (a *App) ListTimeouts(serverID, userID string) ([]*server.Member, error) {
+	return a.serverService.ListTimeouts(a.ctx, serverID, userID)
+}
+
+// RequestOwnershipTransfer offers a server to a member, who must accept it.
+func (a *App) RequestOwnershipTransfer(serverID, ownerID, targetID string) (*server.OwnershipTransfer, error) {
+	return a.serverService.RequestOwnershipTransfer(a.ctx, serverID, ownerID, targetID)
+}
+
+// PendingOwnershipTransfer returns the pending ownership transfer of a server, if
+// the user is its owner or target.
+func (a *App) PendingOwnershipTransfer(serverID, userID string) (*server.OwnershipTransfer, error) {
+	return a.serverService.PendingOwnershipTransfer(a.ctx, serverID, userID)
+}
+
+// AcceptOwnershipTransfer makes the user the owner of a server offered to them.
+func (a *App) AcceptOwnershipTransfer(serverID, userID string) (*server.Server, error) {
+	return a.serverService.AcceptOwnershipTransfer(a.ctx, serverID, userID)
+}
+
+// CancelOwnershipTransfer withdraws or declines the pending ownership transfer of a server.
+func (a *App) CancelOwnershipTransfer(serverID, userID string) error {
+	return a.serverService.CancelOwnershipTransfer(a.ctx, serverID, userID)
+}
+
 // UpdateMemberRole changes a member's role in a server.
 func (a *App) UpdateMemberRole(serverID, actorID, targetID string, role string) error {
-	return a.serverService.UpdateMemberRole(a.ctx, serverID, actorID, targetID, server.Role(role))
+	return a.serverService.UpdateMemberRole(a.ctx, serverID, actorID, targetID, role)
+}
+
+// ListAuditLog returns entries of the audit log of a server, newest first.
+func (a *App) ListAuditLog(serverID, userID string, query server.AuditQuery) ([]*server.AuditEntry, error) {
+	return a.serverService.AuditLog(a.ctx, serverID, userID, query)
+}
+
+// ListRoles returns the roles of a server, highest first.
+func (a *App) ListRoles(serverID string) ([]*server.Role, error) {
+	return a.serverService.ListRoles(a.ctx, serverID)
+}
+
+// CreateRole creates a role within a server, just above the default role.
+func (a *App) CreateRole(serverID, userID, name string, color int, permissions server.Permission) (*server.Role, error) {
+	return a.serverService.CreateRole(a.ctx, serverID, userID, name, color, permissions)
+}
+
+// UpdateRole renames a role or changes its color or permissions.
+func (a *App) UpdateRole(serverID, userID, roleID string, update server.RoleUpdate) (*server.Role, error) {
+	return a.serverService.UpdateRole(a.ctx, serverID, userID, roleID, update)
+}
+
+// DeleteRole removes a role from a server and from every member holding it.
+func (a *App) DeleteRole(serverID, userID, roleID string) error {
+	return a.serverService.DeleteRole(a.ctx, serverID, userID, roleID)
+}
+
+// ReorderRoles sets the order of the roles of a server, highest first.
+func (a *App) ReorderRoles(serverID, userID string, roleIDs []string) error {
+	return a.serverService.ReorderRoles(a.ctx, serverID, userID, roleIDs)
+}
+
+// AddMemberRole assigns a role to a member of a server.
+func (a *App) AddMemberRole(serverID, actorID, targetID, roleID string) error {
+	return a.serverService.AddMemberRole(a.ctx, serverID, actorID, targetID, roleID)
+}
+
+// RemoveMemberRole unassigns a role from a member of a server.
+func (a *App) RemoveMemberRole(serverID, actorID, targetID, roleID string) error {
+	return a.serverService.RemoveMemberRole(a.ctx, serverID, actorID, targetID, roleID)
 }
 
 // GenerateInvite creates a new invite code for a server.
@@ -398,6 +635,47 @@ func (a *App) GenerateInvite(serverID, userID string) (string, error) {
 	return a.serverService.GenerateInvite(a.ctx, serverID, userID)
 }
 
+// CreateInvite creates an invite to a server with an optional expiry, use limit
+// and vanity code.
+func (a *App) CreateInvite(serverID, userID string, opts server.InviteOptions) (*server.Invite, error) {
+	return a.serverService.CreateInvite(a.ctx, serverID, userID, opts)
+}
+
+// ListInvites returns the invites of a server that can still be used.
+func (a *App) ListInvites(serverID, userID string) ([]*server.Invite, error) {
+	return a.serverService.ListInvites(a.ctx, serverID, userID)
+}
+
+// RevokeInvite deletes an invite of a server.
+func (a *App) RevokeInvite(serverID, userID, code string) error {
+	return a.serverService.RevokeInvite(a.ctx, serverID, userID, code)
+}
+
+// CreateTemplate captures the structure of a server in a new template.
+func (a *App) CreateTemplate(serverID, userID, name, description string) (*server.Template, error) {
+	return a.serverService.CreateTemplate(a.ctx, serverID, userID, name, description)
+}
+
+// GetTemplate returns a template by code.
+func (a *App) GetTemplate(code string) (*server.Template, error) {
+	return a.serverService.GetTemplate(a.ctx, code)
+}
+
+// ListTemplates returns the templates of a server.
+func (a *App) ListTemplates(serverID, userID string) ([]*server.Template, error) {
+	return a.serverService.ListTemplates(a.ctx, serverID, userID)
+}
+
+// SyncTemplate re-captures the structure of a server in one of its templates.
+func (a *App) SyncTemplate(serverID, userID, code string) (*server.Template, error) {
+	return a.serverService.SyncTemplate(a.ctx, serverID, userID, code)
+}
+
+// DeleteTemplate deletes a template of a server.
+func (a *App) DeleteTemplate(serverID, userID, code string) error {
+	return a.serverService.DeleteTemplate(a.ctx, serverID, userID, code)
+}
+
 // RedeemInvite joins a server using an invite code.
 func (a *App) RedeemInvite(code, userID string) (*server.Server, error) {
 	return a.serverService.RedeemInvite(a.ctx, code, userID)
@@ -476,11 +754,124 @@ func (a *App) DeleteMessage(messageID, actorID string, isManager bool) error {
 	return a.chatService.DeleteMessage(a.ctx, messageID, actorID, isManager)
 }
 
+// CreatePoll posts a poll message in a channel.
+func (a *App) CreatePoll(channelID, authorID string, input chat.PollInput) (*chat.Message, error) {
+	return a.chatService.CreatePoll(a.ctx, channelID, authorID, input)
+}
+
+// VotePoll replaces the user's votes on a poll. An empty list retracts the vote.
+func (a *App) VotePoll(messageID, userID string, optionIDs []string) (*chat.Message, error) {
+	return a.chatService.VotePoll(a.ctx, messageID, userID, optionIDs)
+}
+
+// ClosePoll ends a poll before its close time.
+func (a *App) ClosePoll(messageID, actorID string, isManager bool) (*chat.Message, error) {
+	return a.chatService.ClosePoll(a.ctx, messageID, actorID, isManager)
+}
+
+// AddReaction reacts to a message with a Unicode emoji or a custom emoji given as name:id.
+func (a *App) AddReaction(messageID, userID, emoji string) (*chat.Message, error) {
+	return a.chatService.AddReaction(a.ctx, messageID, userID, emoji)
+}
+
+// RemoveReaction removes the user's reaction from a message.
+func (a *App) RemoveReaction(messageID, userID, emoji string) (*chat.Message, error) {
+	return a.chatService.RemoveReaction(a.ctx, messageID, userID, emoji)
+}
+
+// ExportChannelHistory asks where to save and writes the full history of a channel
+// the user can read. Returns the saved path, or "" when the dialog is cancelled.
+func (a *App) ExportChannelHistory(userID, serverID, channelID, format string) (string, error) {
+	f, err := export.ParseFormat(format)
+	if err != nil {
+		return "", err
+	}
+	readable, err := a.serverService.ReadableChannels(a.ctx, userID, serverID)
+	if err != nil {
+		return "", err
+	}
+	name, ok := readable[channelID]
+	if !ok {
+		return "", fmt.Errorf("channel not found")
+	}
+
+	meta := export.Meta{ID: channelID, Name: name, ServerID: serverID, ExportedBy: userID}
+	if srv, err := a.serverService.GetServer(a.ctx, serverID); err == nil && srv != nil {
+		meta.ServerName = srv.Name
+	}
+	return a.saveExport(f, meta, func(w io.Writer, meta export.Meta) (int, error) {
+		return a.exportService.ExportChannel(a.ctx, w, f, meta)
+	})
+}
+
+// ExportDirectMessages asks where to save and writes the user's conversation with friendID.
+// Returns the saved path, or "" when the dialog is cancelled.
+func (a *App) ExportDirectMessages(userID, friendID, format string) (string, error) {
+	f, err := export.ParseFormat(format)
+	if err != nil {
+		return "", err
+	}
+	meta := export.Meta{ID: friendID, ExportedBy: userID}
+	return a.saveExport(f, meta, func(w io.Writer, meta export.Meta) (int, error) {
+		return a.exportService.ExportDM(a.ctx, w, f, userID, meta)
+	})
+}
+
+// saveExport shows the save dialog and streams the export into the chosen file.
+func (a *App) saveExport(f export.Format, meta export.Meta, run func(io.Writer, export.Meta) (int, error)) (string, error) {
+	meta.ExportedAt = time.Now().UTC().Format(time.RFC3339)
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Export history",
+		DefaultFilename: meta.Filename(f),
+		Filters: []runtime.FileFilter{
+			{DisplayName: strings.ToUpper(f.Extension()), Pattern: "*." + f.Extension()},
+		},
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create export file: %w", err)
+	}
+	if _, err := run(file, meta); err != nil {
+		file.Close()
+		os.Remove(path)
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("failed to write export file: %w", err)
+	}
+	return path, nil
+}
+
+// GetPreferences returns the user's privacy preferences.
+func (a *App) GetPreferences(userID string) (*preferences.Preferences, error) {
+	return a.prefsService.Get(a.ctx, userID)
+}
+
+// SetLinkPreviews enables or disables link previews for the user.
+func (a *App) SetLinkPreviews(userID string, enabled bool) (*preferences.Preferences, error) {
+	return a.prefsService.Update(a.ctx, userID, preferences.Update{LinkPreviews: &enabled})
+}
+
+// SetReadReceipts enables or disables read receipts for the user's direct messages.
+func (a *App) SetReadReceipts(userID string, enabled bool) (*preferences.Preferences, error) {
+	return a.prefsService.Update(a.ctx, userID, preferences.Update{ReadReceipts: &enabled})
+}
+
 // SearchMessages performs full-text search in a channel.
 func (a *App) SearchMessages(channelID, query string, limit int) ([]*chat.SearchResult, error) {
 	return a.chatService.SearchMessages(a.ctx, channelID, query, limit)
 }
 
+// SearchMessagesScoped searches the channels userID can read: one channel, one server,
+// or every joined server when both IDs are empty.
+func (a *App) SearchMessagesScoped(userID, serverID, channelID, query string, limit int) ([]*chat.SearchResult, error) {
+	return a.chatService.Search(a.ctx, userID, chat.SearchScope{ServerID: serverID, ChannelID: channelID}, query, limit)
+}
+
 // --- Voice Bindings (P2P mode only) ---
 // In server mode, voice is handled entirely by the browser's VoiceRTCClient
 // connecting to the central signaling server. These Go bindings are only
@@ -625,7 +1016,14 @@ func (a *App) GetVoiceTranslationStatus() voice.VoiceTranslationStatus {
 
 // UploadFile validates and stores a file attached to a message.
 func (a *App) UploadFile(messageID, filename string, data []byte) (*files.Attachment, error) {
-	return a.fileService.Upload(a.ctx, messageID, filename, data)
+	msg, err := a.chatService.GetMessage(a.ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if msg == nil {
+		return nil, fmt.Errorf("message not found")
+	}
+	return a.fileService.UploadAs(a.ctx, msg.AuthorID, messageID, filename, data)
 }
 
 // DownloadFile retrieves file data for an attachment.
@@ -644,6 +1042,70 @@ func (a *App) DeleteAttachment(attachmentID string) error {
 	return a.fileService.DeleteAttachment(a.ctx, attachmentID)
 }
 
+// --- Custom Emoji Bindings ---
+
+// ListServerEmoji returns the custom emoji of a server.
+func (a *App) ListServerEmoji(serverID, userID string) ([]*emoji.Emoji, error) {
+	return a.emojiService.List(a.ctx, serverID, userID)
+}
+
+// UploadEmoji adds a custom emoji (PNG, GIF or WebP) to a server.
+func (a *App) UploadEmoji(serverID, userID, name, filename string, data []byte) (*emoji.Emoji, error) {
+	return a.emojiService.Upload(a.ctx, serverID, userID, name, filename, data)
+}
+
+// RenameEmoji changes the name of a custom emoji.
+func (a *App) RenameEmoji(serverID, userID, emojiID, name string) (*emoji.Emoji, error) {
+	return a.emojiService.Rename(a.ctx, serverID, userID, emojiID, name)
+}
+
+// DeleteEmoji removes a custom emoji and the reactions that used it.
+func (a *App) DeleteEmoji(serverID, userID, emojiID string) error {
+	return a.emojiService.Delete(a.ctx, serverID, userID, emojiID)
+}
+
+// GetEmojiImage returns the image data of a custom emoji.
+func (a *App) GetEmojiImage(emojiID string) ([]byte, error) {
+	_, rc, err := a.emojiService.Image(a.ctx, emojiID)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// --- Bookmark Bindings ---
+
+// AddBookmark bookmarks a channel message ("channel") or direct message ("dm") the user can read.
+func (a *App) AddBookmark(userID, kind, messageID, note, folder string) (*bookmarks.Bookmark, error) {
+	return a.bookmarkService.Add(a.ctx, userID, kind, messageID, note, folder)
+}
+
+// ListBookmarks returns the user's bookmarks, newest first. folder filters by folder
+// when filterFolder is set ("" for unfiled); query searches notes and content.
+func (a *App) ListBookmarks(userID string, filterFolder bool, folder, query, before string, limit int) ([]*bookmarks.Bookmark, error) {
+	opts := bookmarks.ListOpts{Query: query, Before: before, Limit: limit}
+	if filterFolder {
+		opts.Folder = &folder
+	}
+	return a.bookmarkService.List(a.ctx, userID, opts)
+}
+
+// ListBookmarkFolders returns the user's bookmark folders with their sizes.
+func (a *App) ListBookmarkFolders(userID string) ([]*bookmarks.Folder, error) {
+	return a.bookmarkService.Folders(a.ctx, userID)
+}
+
+// UpdateBookmark sets the note and folder of one of the user's bookmarks.
+func (a *App) UpdateBookmark(userID, bookmarkID, note, folder string) (*bookmarks.Bookmark, error) {
+	return a.bookmarkService.Update(a.ctx, userID, bookmarkID, &note, &folder)
+}
+
+// DeleteBookmark removes one of the user's bookmarks.
+func (a *App) DeleteBookmark(userID, bookmarkID string) error {
+	return a.bookmarkService.Delete(a.ctx, userID, bookmarkID)
+}
+
 // --- Translation Bindings ---
 
 // EnableTranslation activates text translation between two languages.
@@ -764,6 +1226,16 @@ func (a *App) InitP2PHost() error {
 	}
 	a.p2pHost = host
 	a.p2pRepo = sqlite.NewP2PRepo(a.db)
+	a.outboxService.SetDeliverer(outbox.TargetP2P, a.deliverP2P)
+
+	// Reenviar mensagens pendentes assim que o peer volta a ficar acessível
+	host.OnPeerConnected(func(peerID string) {
+		if n, err := a.outboxService.Flush(a.ctx, outbox.TargetP2P, peerID); err != nil {
+			a.logger.Warn().Err(err).Str("peer", peerID).Msg("p2p: flush outbox")
+		} else if n > 0 {
+			a.logger.Info().Str("peer", peerID).Int("count", n).Msg("p2p: pending messages resent")
+		}
+	})
 
 	// Registrar handler de mensagens recebidas
 	host.OnMessage(func(peerID string, data []byte) {
@@ -784,8 +1256,12 @@ func (a *App) InitP2PHost() error {
 		case p2p.TypeChat:
 			var chat p2p.ChatPayload
 			if err := json.Unmarshal(env.Payload, &chat); err == nil {
+				id := chat.ID
+				if id == "" {
+					id = fmt.Sprintf("%s-%s", peerID, chat.SentAt)
+				}
 				msg := sqlite.P2PMessage{
-					ID:        fmt.Sprintf("%s-%s", peerID, chat.SentAt),
+					ID:        id,
 					PeerID:    peerID,
 					Direction: "received",
 					Content:   chat.Content,
@@ -793,8 +1269,31 @@ func (a *App) InitP2PHost() error {
 				}
 				if err := a.p2pRepo.SaveMessage(a.ctx, msg); err != nil {
 					a.logger.Warn().Err(err).Msg("p2p: save received message")
+					return // sem ack: o remetente reenviará
 				}
+				a.typingTracker.Stop(typing.PeerScope(peerID), peerID)
 				runtime.EventsEmit(a.ctx, "p2p:message", msg)
+				if chat.ID != "" {
+					a.sendP2PAck(peerID, chat.ID)
+				}
+			}
+
+		case p2p.TypeAck:
+			var ack p2p.AckPayload
+			if err := json.Unmarshal(env.Payload, &ack); err == nil && ack.MessageID != "" {
+				if err := a.outboxService.Ack(a.ctx, ack.MessageID); err != nil {
+					a.logger.Warn().Err(err).Str("peer", peerID).Msg("p2p: record ack")
+				}
+			}
+
+		case p2p.TypeTyping:
+			var t p2p.TypingPayload
+			if err := json.Unmarshal(env.Payload, &t); err == nil {
+				if t.Typing {
+					a.typingTracker.Start(typing.PeerScope(peerID), peerID)
+				} else {
+					a.typingTracker.Stop(typing.PeerScope(peerID), peerID)
+				}
 			}
 		}
 	})
@@ -827,14 +1326,16 @@ func (a *App) sendProfileHandshake(peerID string, prof p2p.ProfilePayload) {
 	}
 }
 
-// SendP2PMessage envia uma mensagem de chat para um peer e persiste localmente.
+// SendP2PMessage persiste uma mensagem para um peer e a entrega via outbox.
+// Se o peer estiver offline a mensagem fica "queued" e é reenviada com backoff
+// ou quando o peer reconectar; vira "delivered" quando o peer confirma.
 // Complexity: O(1).
-func (a *App) SendP2PMessage(peerID, content string) error {
+func (a *App) SendP2PMessage(peerID, content string) (*P2PMessage, error) {
 	if a.p2pHost == nil {
-		return fmt.Errorf("p2p host not initialized")
+		return nil, fmt.Errorf("p2p host not initialized")
 	}
 	if a.p2pRepo == nil {
-		return fmt.Errorf("p2p repository not initialized")
+		return nil, fmt.Errorf("p2p repository not initialized")
 	}
 
 	now := time.Now().UTC().Format(time.RFC3339Nano)
@@ -847,18 +1348,83 @@ func (a *App) SendP2PMessage(peerID, content string) error {
 	}
 
 	if err := a.p2pRepo.SaveMessage(a.ctx, msg); err != nil {
-		return fmt.Errorf("save sent message: %w", err)
+		return nil, fmt.Errorf("save sent message: %w", err)
 	}
+	// O destinatário limpa o indicador ao receber a mensagem
+	a.typingSent.Stop(typing.PeerScope(peerID), a.p2pHost.ID())
 
-	payload := p2p.ChatPayload{Content: content, SentAt: now}
+	entry, err := a.outboxService.Enqueue(a.ctx, outbox.TargetP2P, peerID, msg.ID, content)
+	if err != nil {
+		return nil, fmt.Errorf("queue message: %w", err)
+	}
+	msg.Status = string(entry.State)
+	return &msg, nil
+}
+
+// deliverP2P é o Deliverer do outbox para peers P2P.
+// O SentAt original é preservado via ID ("<host>-<sent_at>").
+func (a *App) deliverP2P(ctx context.Context, e *outbox.Entry) error {
+	sentAt := strings.TrimPrefix(e.ID, a.p2pHost.ID()+"-")
+	payload := p2p.ChatPayload{ID: e.ID, Content: e.Content, SentAt: sentAt}
 	data, err := p2p.EncodeEnvelope(p2p.TypeChat, a.p2pHost.ID(), payload)
 	if err != nil {
 		return fmt.Errorf("encode chat: %w", err)
 	}
 
-	ctx, cancel := context.WithTimeout(a.ctx, 10*time.Second)
+	sendCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
-	return a.p2pHost.SendData(ctx, peerID, data)
+	return a.p2pHost.SendData(sendCtx, e.Recipient, data)
+}
+
+func (a *App) sendP2PAck(peerID, messageID string) {
+	data, err := p2p.EncodeEnvelope(p2p.TypeAck, a.p2pHost.ID(), p2p.AckPayload{MessageID: messageID})
+	if err != nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(a.ctx, 5*time.Second)
+		defer cancel()
+		if err := a.p2pHost.SendData(ctx, peerID, data); err != nil {
+			a.logger.Debug().Err(err).Str("peer", peerID).Msg("p2p: ack failed")
+		}
+	}()
+}
+
+// SendP2PTyping informa a um peer que o usuário começou ou parou de digitar.
+// Chamadas repetidas são limitadas a uma notificação por typing.DefaultThrottle;
+// o peer expira o indicador após typing.DefaultTTL sem renovação.
+func (a *App) SendP2PTyping(peerID string, isTyping bool) error {
+	if a.p2pHost == nil {
+		return fmt.Errorf("p2p host not initialized")
+	}
+	scope := typing.PeerScope(peerID)
+	var notify bool
+	if isTyping {
+		notify = a.typingSent.Start(scope, a.p2pHost.ID())
+	} else {
+		notify = a.typingSent.Stop(scope, a.p2pHost.ID())
+	}
+	if !notify {
+		return nil
+	}
+
+	data, err := p2p.EncodeEnvelope(p2p.TypeTyping, a.p2pHost.ID(), p2p.TypingPayload{Typing: isTyping})
+	if err != nil {
+		return fmt.Errorf("encode typing: %w", err)
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(a.ctx, 5*time.Second)
+		defer cancel()
+		if err := a.p2pHost.SendData(ctx, peerID, data); err != nil {
+			a.logger.Debug().Err(err).Str("peer", peerID).Msg("p2p: typing notification failed")
+		}
+	}()
+	return nil
+}
+
+// GetP2PTyping retorna os peers digitando na conversa com peerID.
+func (a *App) GetP2PTyping(peerID string) []typing.Typer {
+	return a.typingTracker.Active(typing.PeerScope(peerID), "")
 }
 
 // GetP2PMessages retorna o histórico de mensagens com um peer.
@@ -870,6 +1436,33 @@ func (a *App) GetP2PMessages(peerID string, limit int) ([]sqlite.P2PMessage, err
 	return a.p2pRepo.GetMessages(a.ctx, peerID, limit)
 }
 
+// --- Outbox Bindings ---
+
+// QueueServerMessage guarda uma mensagem para o servidor central quando ele está
+// inacessível. O frontend a entrega depois via ClaimServerOutbox/ReportServerOutbox.
+func (a *App) QueueServerMessage(channelID, content string) (*outbox.Entry, error) {
+	return a.outboxService.Enqueue(a.ctx, outbox.TargetServer, channelID, "", content)
+}
+
+// ClaimServerOutbox retorna as mensagens para o servidor cujo backoff expirou.
+func (a *App) ClaimServerOutbox(limit int) ([]*outbox.Entry, error) {
+	return a.outboxService.Claim(a.ctx, outbox.TargetServer, limit)
+}
+
+// ReportServerOutbox registra o resultado da entrega: errMsg vazio confirma a entrega.
+func (a *App) ReportServerOutbox(id, errMsg string) error {
+	var deliveryErr error
+	if errMsg != "" {
+		deliveryErr = errors.New(errMsg)
+	}
+	return a.outboxService.Report(a.ctx, id, deliveryErr)
+}
+
+// GetOutbox lista as mensagens do outbox para um destinatário (peer ou canal).
+func (a *App) GetOutbox(target, recipient string) ([]*outbox.Entry, error) {
+	return a.outboxService.List(a.ctx, target, recipient)
+}
+
 // GetP2PPeerName retorna o nome do perfil recebido de um peer.
 func (a *App) GetP2PPeerName(peerID string) string {
 	if v, ok := a.p2pPeerNames.Load(peerID); ok {